@@ -0,0 +1,195 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"nofx/auth"
+	"nofx/logger"
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiTokenTouchInterval minimum interval between last-used updates of a token
+const apiTokenTouchInterval = time.Minute
+
+// authenticateAPIToken validates a personal API token, enforces its scope and rate limit
+// and stores the user in the context, returns false when the request was aborted
+func (s *Server) authenticateAPIToken(c *gin.Context, tokenString string) bool {
+	token, err := s.store.APIToken().GetByHash(auth.HashAPIToken(tokenString))
+	now := time.Now().UTC()
+	if err != nil || !token.Active(now) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or revoked API token"})
+		c.Abort()
+		return false
+	}
+
+	required := requiredAPITokenScope(c.Request.Method, c.FullPath())
+	if !auth.HasScope(token.ScopeList(), required) {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("API token lacks the '%s' scope", required)})
+		c.Abort()
+		return false
+	}
+
+	if ok, retryAfter := auth.AllowAPITokenRequest(token.ID, token.RateLimitPerMinute, now); !ok {
+		c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "API token rate limit exceeded"})
+		c.Abort()
+		return false
+	}
+
+	user, err := s.store.User().GetByID(token.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid, expired or revoked API token"})
+		c.Abort()
+		return false
+	}
+
+	// Last-used tracking is throttled to avoid a write per request
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= apiTokenTouchInterval || token.LastUsedIP != c.ClientIP() {
+		tokenID, ip := token.ID, c.ClientIP()
+		go func() {
+			if err := s.store.APIToken().TouchLastUsed(tokenID, ip, now); err != nil {
+				logger.Warnf("[Auth] Failed to update API token last use: %v", err)
+			}
+		}()
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("email", user.Email)
+	c.Set("api_token_id", token.ID)
+	return true
+}
+
+// requiredAPITokenScope returns the scope an API token needs for a route
+func requiredAPITokenScope(method, path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	switch {
+	case strings.HasPrefix(path, "/api-tokens") || strings.HasPrefix(path, "/encryption") || strings.HasPrefix(path, "/backups"):
+		return auth.ScopeAdmin
+	case (strings.HasPrefix(path, "/models") || strings.HasPrefix(path, "/exchanges")) && method != http.MethodGet:
+		// Credential changes
+		return auth.ScopeAdmin
+	case strings.HasPrefix(path, "/workspaces") && method != http.MethodGet:
+		// Workspace and membership changes grant access to other users
+		return auth.ScopeAdmin
+	case strings.HasPrefix(path, "/backtest"):
+		if method == http.MethodGet {
+			return auth.ScopeRead
+		}
+		return auth.ScopeBacktest
+	case method == http.MethodGet:
+		return auth.ScopeRead
+	}
+	return auth.ScopeTrade
+}
+
+// apiTokenResponse API token as returned to its owner
+type apiTokenResponse struct {
+	*store.APIToken
+	Scopes []string `json:"scopes"`
+	Active bool     `json:"active"`
+}
+
+// handleListAPITokens List API tokens of the current user
+func (s *Server) handleListAPITokens(c *gin.Context) {
+	userID := c.GetString("user_id")
+	tokens, err := s.store.APIToken().List(userID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get API tokens", err)
+		return
+	}
+
+	now := time.Now().UTC()
+	result := make([]apiTokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, apiTokenResponse{APIToken: t, Scopes: t.ScopeList(), Active: t.Active(now)})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// handleCreateAPIToken Create an API token, the plaintext token is only returned once
+func (s *Server) handleCreateAPIToken(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	// Tokens are created from a login session only, so a leaked token can not mint new ones
+	if c.GetString("api_token_id") != "" {
+		SafeForbidden(c, "API tokens can only be created from a login session")
+		return
+	}
+
+	var req struct {
+		Name               string   `json:"name" binding:"required"`
+		Scopes             []string `json:"scopes" binding:"required"`
+		RateLimitPerMinute int      `json:"rate_limit_per_minute"`
+		ExpiresInDays      int      `json:"expires_in_days"` // 0 = never expires
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	scopes, err := auth.NormalizeScopes(req.Scopes)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	if req.RateLimitPerMinute < 0 || req.ExpiresInDays < 0 {
+		SafeBadRequest(c, "rate_limit_per_minute and expires_in_days must not be negative")
+		return
+	}
+
+	plaintext, hash, err := auth.GenerateAPIToken()
+	if err != nil {
+		SafeInternalError(c, "Generate API token", err)
+		return
+	}
+
+	token := &store.APIToken{
+		ID:                 uuid.New().String(),
+		UserID:             userID,
+		Name:               req.Name,
+		TokenHash:          hash,
+		TokenPrefix:        plaintext[:len(auth.APITokenPrefix)+6],
+		Scopes:             strings.Join(scopes, ","),
+		RateLimitPerMinute: req.RateLimitPerMinute,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().UTC().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+	if err := s.store.APIToken().Create(token); err != nil {
+		SafeInternalError(c, "Failed to create API token", err)
+		return
+	}
+
+	setAuditTarget(c, token.ID)
+	setAuditChange(c, nil, gin.H{"name": token.Name, "scopes": scopes, "rate_limit_per_minute": token.RateLimitPerMinute, "expires_at": token.ExpiresAt})
+	logger.Infof("🔑 API token created: %s (user: %s, scopes: %s)", token.Name, userID, token.Scopes)
+	c.JSON(http.StatusOK, gin.H{
+		"token":   plaintext,
+		"info":    apiTokenResponse{APIToken: token, Scopes: scopes, Active: true},
+		"message": "Store this token now, it will not be shown again",
+	})
+}
+
+// handleRevokeAPIToken Revoke an API token of the current user
+func (s *Server) handleRevokeAPIToken(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if err := s.store.APIToken().Revoke(userID, c.Param("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			SafeNotFound(c, "API token")
+			return
+		}
+		SafeInternalError(c, "Failed to revoke API token", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked"})
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"nofx/auth"
+)
+
+// TestRequiredAPITokenScope Test scope required by routes
+func TestRequiredAPITokenScope(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{"GET", "/api/my-traders", auth.ScopeRead},
+		{"POST", "/api/traders/:id/start", auth.ScopeTrade},
+		{"POST", "/api/traders/:id/close-position", auth.ScopeTrade},
+		{"GET", "/api/backtest/runs", auth.ScopeRead},
+		{"POST", "/api/backtest/start", auth.ScopeBacktest},
+		{"GET", "/api/exchanges", auth.ScopeRead},
+		{"PUT", "/api/exchanges", auth.ScopeAdmin},
+		{"PUT", "/api/models", auth.ScopeAdmin},
+		{"GET", "/api/api-tokens", auth.ScopeAdmin},
+		{"GET", "/api/workspaces", auth.ScopeRead},
+		{"GET", "/api/workspaces/:id/members", auth.ScopeRead},
+		{"POST", "/api/workspaces", auth.ScopeAdmin},
+		{"POST", "/api/workspaces/:id/members", auth.ScopeAdmin},
+		{"PUT", "/api/workspaces/:id/members/:userId", auth.ScopeAdmin},
+		{"DELETE", "/api/workspaces/:id/members/:userId", auth.ScopeAdmin},
+	}
+	for _, tt := range tests {
+		if got := requiredAPITokenScope(tt.method, tt.path); got != tt.want {
+			t.Errorf("%s %s: expected %s, got %s", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
+
+// TestAPITokenScopes Test scope checks and validation
+func TestAPITokenScopes(t *testing.T) {
+	if !auth.HasScope([]string{auth.ScopeBacktest}, auth.ScopeRead) {
+		t.Error("any scope should allow read")
+	}
+	if auth.HasScope([]string{auth.ScopeRead}, auth.ScopeTrade) {
+		t.Error("read should not allow trade")
+	}
+	if auth.HasScope([]string{auth.ScopeTrade}, auth.ScopeBacktest) {
+		t.Error("trade should not allow backtest")
+	}
+	if !auth.HasScope([]string{auth.ScopeAdmin}, auth.ScopeBacktest) {
+		t.Error("admin should allow everything")
+	}
+
+	if _, err := auth.NormalizeScopes([]string{"read", "root"}); err == nil {
+		t.Error("unknown scope should be rejected")
+	}
+	if scopes, err := auth.NormalizeScopes([]string{" Trade", "trade", "read"}); err != nil || len(scopes) != 2 {
+		t.Errorf("expected deduplicated scopes, got %v %v", scopes, err)
+	}
+}
+
+// TestAPITokenGeneration Test token format and hashing
+func TestAPITokenGeneration(t *testing.T) {
+	token, hash, err := auth.GenerateAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !auth.IsAPIToken(token) {
+		t.Errorf("token should carry the API token prefix: %s", token)
+	}
+	if hash != auth.HashAPIToken(token) || hash == token {
+		t.Error("hash should be deterministic and differ from the token")
+	}
+	other, _, _ := auth.GenerateAPIToken()
+	if other == token {
+		t.Error("tokens should be unique")
+	}
+}
+
+// TestAPITokenRateLimit Test per-token rate limit window
+func TestAPITokenRateLimit(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		if ok, _ := auth.AllowAPITokenRequest("rate-test", 3, now); !ok {
+			t.Fatalf("request %d should be allowed", i+1)
+		}
+	}
+	ok, retryAfter := auth.AllowAPITokenRequest("rate-test", 3, now.Add(20*time.Second))
+	if ok || retryAfter != 40*time.Second {
+		t.Errorf("expected limit with 40s retry, got %v %v", ok, retryAfter)
+	}
+	if ok, _ := auth.AllowAPITokenRequest("rate-test", 3, now.Add(time.Minute)); !ok {
+		t.Error("window should reset after a minute")
+	}
+}
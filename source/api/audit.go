@@ -0,0 +1,331 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nofx/logger"
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditExport maximum number of entries in one export
+const maxAuditExport = 50000
+
+// auditActions action names of routes, other state-changing routes are named after their path
+var auditActions = map[string]string{
+	"POST /traders":                          "trader.create",
+	"PUT /traders/:id":                       "trader.update",
+	"DELETE /traders/:id":                    "trader.delete",
+	"POST /traders/:id/start":                "trader.start",
+	"POST /traders/:id/stop":                 "trader.stop",
+	"PUT /traders/:id/prompt":                "trader.update_prompt",
+	"PUT /traders/:id/competition":           "trader.toggle_competition",
+	"POST /traders/:id/close-position":       "position.close",
+	"POST /traders/:id/modify-tp-sl":         "position.modify_tpsl",
+	"PUT /models":                            "model.update",
+	"POST /exchanges":                        "exchange.create",
+	"PUT /exchanges":                         "exchange.update",
+	"DELETE /exchanges/:id":                  "exchange.delete",
+	"POST /strategies":                       "strategy.create",
+	"PUT /strategies/:id":                    "strategy.update",
+	"DELETE /strategies/:id":                 "strategy.delete",
+	"POST /strategies/:id/activate":          "strategy.activate",
+	"POST /strategies/:id/duplicate":         "strategy.duplicate",
+	"POST /api-tokens":                       "api_token.create",
+	"DELETE /api-tokens/:id":                 "api_token.revoke",
+	"POST /workspaces":                       "workspace.create",
+	"POST /workspaces/:id/members":           "workspace.add_member",
+	"PUT /workspaces/:id/members/:userId":    "workspace.update_member",
+	"DELETE /workspaces/:id/members/:userId": "workspace.remove_member",
+	"POST /backups":                          "backup.create",
+	"POST /logout":                           "user.logout",
+	"POST /clear-errors":                     "errors.clear",
+}
+
+// auditTargetTypes target type of the first path segment
+var auditTargetTypes = map[string]string{
+	"traders":           "trader",
+	"strategies":        "strategy",
+	"exchanges":         "exchange",
+	"models":            "model",
+	"debates":           "debate",
+	"api-tokens":        "api_token",
+	"workspaces":        "workspace",
+	"adaptive-stoploss": "trader",
+	"backups":           "backup",
+	"prompt-templates":  "prompt_template",
+	"experiments":       "experiment",
+}
+
+// auditAction returns the action name of a route
+func auditAction(method, path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	if action, ok := auditActions[method+" "+path]; ok {
+		return action
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	resource := auditTargetType(path)
+	last := segments[len(segments)-1]
+	if len(segments) > 1 && !strings.HasPrefix(last, ":") {
+		return resource + "." + strings.ReplaceAll(last, "-", "_")
+	}
+	switch method {
+	case http.MethodPost:
+		return resource + ".create"
+	case http.MethodDelete:
+		return resource + ".delete"
+	}
+	return resource + ".update"
+}
+
+// auditTargetType returns the target type of a route
+func auditTargetType(path string) string {
+	segment := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api"), "/"), "/")[0]
+	if t, ok := auditTargetTypes[segment]; ok {
+		return t
+	}
+	return strings.ReplaceAll(segment, "-", "_")
+}
+
+// setAuditChange attaches before/after summaries to the audit entry of the request
+// Summaries must not contain credentials
+func setAuditChange(c *gin.Context, before, after interface{}) {
+	c.Set("audit_before", before)
+	c.Set("audit_after", after)
+}
+
+// setAuditTarget overrides the target derived from the route (e.g. the ID of a created resource)
+func setAuditTarget(c *gin.Context, targetID string) {
+	c.Set("audit_target_id", targetID)
+}
+
+// setAuditOwner records the entry under a workspace instead of the user; only call it after the user
+// was authorized for the workspace
+func setAuditOwner(c *gin.Context, workspaceID string) {
+	c.Set("audit_owner_id", workspaceID)
+}
+
+// auditMiddleware writes an audit entry for every state-changing request
+func (s *Server) auditMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		path := strings.TrimPrefix(c.FullPath(), "/api")
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodOptions || readOnlyPostRoutes[path] {
+			return
+		}
+
+		entry := &store.AuditEntry{
+			OwnerID:    c.GetString("user_id"),
+			Actor:      c.GetString("actor_user_id"),
+			ActorType:  store.AuditActorUser,
+			APITokenID: c.GetString("api_token_id"),
+			Action:     auditAction(c.Request.Method, path),
+			TargetType: auditTargetType(path),
+			IP:         c.ClientIP(),
+			Status:     c.Writer.Status(),
+		}
+		if entry.Actor == "" {
+			entry.Actor = entry.OwnerID
+		}
+		if entry.APITokenID != "" {
+			entry.ActorType = store.AuditActorAPIToken
+		}
+		if owner := c.GetString("audit_owner_id"); owner != "" {
+			// Membership changes belong to the workspace, once the member was authorized for it
+			entry.OwnerID = owner
+		}
+		if len(c.Params) > 0 {
+			// The innermost resource, e.g. the member in /workspaces/:id/members/:userId
+			entry.TargetID = c.Params[len(c.Params)-1].Value
+		}
+		if id := c.GetString("audit_target_id"); id != "" {
+			entry.TargetID = id
+		}
+		if before, ok := c.Get("audit_before"); ok {
+			entry.Before = store.AuditSummary(before)
+		}
+		if after, ok := c.Get("audit_after"); ok {
+			entry.After = store.AuditSummary(after)
+		}
+
+		if err := s.store.Audit().Append(entry); err != nil {
+			logger.Errorf("❌ Failed to write audit entry %s by %s: %v", entry.Action, entry.Actor, err)
+		}
+	}
+}
+
+// parseAuditQuery builds an audit query for the current user from the request filters
+func parseAuditQuery(c *gin.Context) (store.AuditQuery, error) {
+	q := store.AuditQuery{
+		OwnerID:    c.GetString("user_id"),
+		Actor:      c.Query("actor"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+	}
+	for name, dst := range map[string]**time.Time{"since": &q.Since, "until": &q.Until} {
+		if v := c.Query(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return q, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+			}
+			*dst = &t
+		}
+	}
+	return q, nil
+}
+
+// handleListAuditLogs List audit entries of the current user or workspace, newest first
+func (s *Server) handleListAuditLogs(c *gin.Context) {
+	q, err := parseAuditQuery(c)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if q.Limit <= 0 || q.Limit > 1000 {
+		q.Limit = 100
+	}
+	q.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	entries, err := s.store.Audit().List(q)
+	if err != nil {
+		SafeInternalError(c, "Failed to get audit log", err)
+		return
+	}
+	if entries == nil {
+		entries = []*store.AuditEntry{}
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// handleExportAuditLogs Export audit entries as JSON or CSV (format=csv)
+func (s *Server) handleExportAuditLogs(c *gin.Context) {
+	q, err := parseAuditQuery(c)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	q.Limit = maxAuditExport
+
+	entries, err := s.store.Audit().List(q)
+	if err != nil {
+		SafeInternalError(c, "Failed to export audit log", err)
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s", time.Now().UTC().Format("20060102-150405"))
+	if c.Query("format") != "csv" {
+		if entries == nil {
+			entries = []*store.AuditEntry{}
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			SafeInternalError(c, "Failed to export audit log", err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", filename))
+		c.Data(http.StatusOK, "application/json", data)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.csv", filename))
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"id", "created_at", "owner_id", "actor", "actor_type", "api_token_id", "action", "target_type", "target_id", "before", "after", "ip", "status", "prev_hash", "hash"})
+	for _, e := range entries {
+		w.Write([]string{
+			strconv.FormatInt(e.ID, 10), e.CreatedAt.UTC().Format(time.RFC3339Nano), e.OwnerID, e.Actor, e.ActorType, e.APITokenID,
+			e.Action, e.TargetType, e.TargetID, e.Before, e.After, e.IP, strconv.Itoa(e.Status), e.PrevHash, e.Hash,
+		})
+	}
+	w.Flush()
+}
+
+// handleVerifyAuditLog Verify the hash chain of the whole audit log (administrators only, the chain spans all owners)
+func (s *Server) handleVerifyAuditLog(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	result, err := s.store.Audit().Verify()
+	if err != nil {
+		SafeInternalError(c, "Failed to verify audit log", err)
+		return
+	}
+	if !result.Valid {
+		logger.Errorf("🚨 Audit log hash chain broken at entry %d: %s", result.BrokenAt, result.Reason)
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// traderAuditSummary configuration of a trader as recorded in the audit log
+func traderAuditSummary(t *store.Trader) gin.H {
+	return gin.H{
+		"name":                  t.Name,
+		"ai_model_id":           t.AIModelID,
+		"exchange_id":           t.ExchangeID,
+		"strategy_id":           t.StrategyID,
+		"initial_balance":       t.InitialBalance,
+		"btc_eth_leverage":      t.BTCETHLeverage,
+		"altcoin_leverage":      t.AltcoinLeverage,
+		"trading_symbols":       t.TradingSymbols,
+		"is_cross_margin":       t.IsCrossMargin,
+		"show_in_competition":   t.ShowInCompetition,
+		"scan_interval_minutes": t.ScanIntervalMinutes,
+		"debate_panel_id":       t.DebatePanelID,
+		"fallback_ai_model_ids": t.FallbackAIModelIDs,
+	}
+}
+
+// strategyAuditSummary strategy as recorded in the audit log
+func strategyAuditSummary(st *store.Strategy) gin.H {
+	summary := gin.H{
+		"name":           st.Name,
+		"description":    st.Description,
+		"is_public":      st.IsPublic,
+		"config_visible": st.ConfigVisible,
+	}
+	if json.Valid([]byte(st.Config)) {
+		summary["config"] = json.RawMessage(st.Config)
+	}
+	return summary
+}
+
+// changedSecrets names of credential fields set in an update, their values are never recorded
+func changedSecrets(fields map[string]string) []string {
+	var changed []string
+	for name, value := range fields {
+		if value != "" {
+			changed = append(changed, name)
+		}
+	}
+	// Map iteration order is random, keep summaries stable
+	sort.Strings(changed)
+	return changed
+}
+
+// exchangeAuditSummary non-secret settings of an exchange account as recorded in the audit log
+func exchangeAuditSummary(e *store.Exchange) gin.H {
+	return gin.H{
+		"exchange_type":           e.ExchangeType,
+		"account_name":            e.AccountName,
+		"enabled":                 e.Enabled,
+		"testnet":                 e.Testnet,
+		"hyperliquid_wallet_addr": e.HyperliquidWalletAddr,
+		"aster_user":              e.AsterUser,
+		"aster_signer":            e.AsterSigner,
+		"lighter_wallet_addr":     e.LighterWalletAddr,
+		"lighter_api_key_index":   e.LighterAPIKeyIndex,
+	}
+}
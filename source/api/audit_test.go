@@ -0,0 +1,81 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+// TestAuditAction Test action and target names derived from routes
+func TestAuditAction(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		action string
+		target string
+	}{
+		{"POST", "/api/traders/:id/start", "trader.start", "trader"},
+		{"POST", "/api/traders/:id/close-position", "position.close", "trader"},
+		{"PUT", "/api/exchanges", "exchange.update", "exchange"},
+		{"PUT", "/api/strategies/:id", "strategy.update", "strategy"},
+		{"POST", "/api/debates/:id/start", "debate.start", "debate"},
+		{"POST", "/api/backtest/start", "backtest.start", "backtest"},
+		{"PUT", "/api/adaptive-stoploss/:traderId", "trader.update", "trader"},
+		{"DELETE", "/api/debates/:id", "debate.delete", "debate"},
+	}
+	for _, tt := range tests {
+		if got := auditAction(tt.method, tt.path); got != tt.action {
+			t.Errorf("%s %s: expected action %s, got %s", tt.method, tt.path, tt.action, got)
+		}
+		if got := auditTargetType(tt.path); got != tt.target {
+			t.Errorf("%s %s: expected target %s, got %s", tt.method, tt.path, tt.target, got)
+		}
+	}
+}
+
+// TestAuditHashChain Test that modified, removed and reordered entries break the chain
+func TestAuditHashChain(t *testing.T) {
+	build := func() []*store.AuditEntry {
+		var entries []*store.AuditEntry
+		prev := ""
+		for i, action := range []string{"trader.start", "position.close", "trader.stop"} {
+			e := &store.AuditEntry{
+				ID:        int64(i + 1),
+				OwnerID:   "user-1",
+				Actor:     "user-1",
+				ActorType: store.AuditActorUser,
+				Action:    action,
+				TargetID:  "trader-1",
+				CreatedAt: time.Date(2026, 3, 2, 12, i, 0, 0, time.UTC),
+				PrevHash:  prev,
+			}
+			e.Hash = store.ComputeAuditHash(prev, e)
+			prev = e.Hash
+			entries = append(entries, e)
+		}
+		return entries
+	}
+
+	if brokenAt, reason := store.VerifyAuditChain("", build()); brokenAt != 0 {
+		t.Fatalf("intact chain reported broken at %d: %s", brokenAt, reason)
+	}
+
+	modified := build()
+	modified[1].After = `{"quantity":0}`
+	if brokenAt, _ := store.VerifyAuditChain("", modified); brokenAt != 2 {
+		t.Errorf("modified entry: expected break at 2, got %d", brokenAt)
+	}
+
+	removed := build()
+	removed = append(removed[:1], removed[2:]...)
+	if brokenAt, _ := store.VerifyAuditChain("", removed); brokenAt != 3 {
+		t.Errorf("removed entry: expected break at 3, got %d", brokenAt)
+	}
+
+	reordered := build()
+	reordered[1], reordered[2] = reordered[2], reordered[1]
+	if brokenAt, _ := store.VerifyAuditChain("", reordered); brokenAt != 3 {
+		t.Errorf("reordered entries: expected break at 3, got %d", brokenAt)
+	}
+}
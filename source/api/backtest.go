@@ -13,8 +13,10 @@ import (
 	"time"
 
 	"nofx/backtest"
+	"nofx/debate"
 	"nofx/logger"
 	"nofx/market"
+	"nofx/mcp"
 	"nofx/provider/nofxos"
 	"nofx/store"
 
@@ -110,6 +112,14 @@ func (s *Server) handleBacktestStart(c *gin.Context) {
 		}
 	}
 
+	// Snapshot the prompt template selected for this run (overrides the strategy's)
+	promptTemplate, err := s.resolvePromptTemplate(cfg.UserID, cfg.PromptTemplateRef)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	cfg.PromptTemplateRef = promptTemplate
+
 	if err := s.hydrateBacktestAIConfig(&cfg); err != nil {
 		SafeBadRequest(c, "Failed to configure AI model")
 		return
@@ -782,6 +792,29 @@ func (s *Server) resolveBacktestAIConfig(cfg *backtest.BacktestConfig, userID st
 	return s.hydrateBacktestAIConfig(cfg)
 }
 
+// hydrateDebateParticipant resolves a debate participant's AI model and, for custom personalities, its prompt
+func (s *Server) hydrateDebateParticipant(userID string, p *backtest.DebateParticipantConfig) error {
+	participantCfg := backtest.BacktestConfig{UserID: userID, AIModelID: p.AIModelID, AICfg: p.AICfg}
+	if err := s.hydrateBacktestAIConfig(&participantCfg); err != nil {
+		return err
+	}
+	p.AIModelID = participantCfg.AIModelID
+	p.AICfg = participantCfg.AICfg
+
+	if strings.HasPrefix(string(p.Personality), store.CustomPersonalityPrefix) {
+		cp, err := s.store.Debate().GetCustomPersonality(userID, string(p.Personality))
+		if err != nil {
+			return fmt.Errorf("custom personality %s not found", p.Personality)
+		}
+		p.Prompt = cp.Prompt
+		p.RiskBias = cp.RiskBias
+		if p.Name == "" {
+			p.Name = cp.Name
+		}
+	}
+	return nil
+}
+
 func (s *Server) hydrateBacktestAIConfig(cfg *backtest.BacktestConfig) error {
 	if cfg == nil {
 		return fmt.Errorf("config is nil")
@@ -816,7 +849,7 @@ func (s *Server) hydrateBacktestAIConfig(cfg *backtest.BacktestConfig) error {
 	}
 
 	apiKey := strings.TrimSpace(string(model.APIKey))
-	if apiKey == "" {
+	if apiKey == "" && mcp.ProviderRequiresAPIKey(strings.ToLower(strings.TrimSpace(model.Provider))) {
 		return fmt.Errorf("AI model %s is missing API Key, please configure it in the system first", model.Name)
 	}
 
@@ -857,5 +890,45 @@ func (s *Server) hydrateBacktestAIConfig(cfg *backtest.BacktestConfig) error {
 		}
 	}
 
+	// Fallback models resolve the same way as the main model
+	cfg.FallbackAICfgs = nil
+	for _, id := range cfg.FallbackAIModelIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || id == cfg.AIModelID {
+			continue
+		}
+		fallbackCfg := backtest.BacktestConfig{UserID: cfg.UserID, AIModelID: id}
+		if err := s.hydrateBacktestAIConfig(&fallbackCfg); err != nil {
+			return fmt.Errorf("fallback AI model %s: %w", id, err)
+		}
+		cfg.FallbackAICfgs = append(cfg.FallbackAICfgs, fallbackCfg.AICfg)
+	}
+
+	// Debate participants resolve their models the same way as the main model
+	if cfg.Debate != nil {
+		for i := range cfg.Debate.Participants {
+			p := &cfg.Debate.Participants[i]
+			if err := s.hydrateDebateParticipant(cfg.UserID, p); err != nil {
+				return fmt.Errorf("debate participant %d: %w", i+1, err)
+			}
+		}
+		if cfg.Debate.Moderator != nil {
+			if err := s.hydrateDebateParticipant(cfg.UserID, cfg.Debate.Moderator); err != nil {
+				return fmt.Errorf("debate moderator: %w", err)
+			}
+		}
+		if cfg.Debate.WeightByAccuracy && cfg.Debate.Weights == nil {
+			panel := make([]*store.DebateParticipant, len(cfg.Debate.Participants))
+			for i, p := range cfg.Debate.Participants {
+				panel[i] = &store.DebateParticipant{AIModelID: p.AIModelID, Personality: p.Personality}
+			}
+			weights, err := debate.AccuracyWeights(s.store.Debate(), cfg.UserID, panel)
+			if err != nil {
+				return fmt.Errorf("failed to load debate scorecards: %w", err)
+			}
+			cfg.Debate.Weights = weights
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"path/filepath"
+
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetBackupScheduler sets the scheduler used by the backup endpoints
+func (s *Server) SetBackupScheduler(bs *store.BackupScheduler) {
+	s.backupScheduler = bs
+}
+
+// handleListBackups List backup archives and the scheduler status
+func (s *Server) handleListBackups(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	if s.backupScheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Backups are not configured"})
+		return
+	}
+
+	files, err := store.ListBackupFiles(s.backupScheduler.Dir())
+	if err != nil {
+		SafeInternalError(c, "Failed to list backups", err)
+		return
+	}
+	if files == nil {
+		files = []store.BackupFile{}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"backups": files,
+		"status":  s.backupScheduler.Status(),
+	})
+}
+
+// handleCreateBackup Write a point-in-time backup archive now
+func (s *Server) handleCreateBackup(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	if s.backupScheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Backups are not configured"})
+		return
+	}
+
+	path, meta, err := s.backupScheduler.RunNow()
+	if err != nil {
+		SafeInternalError(c, "Failed to create backup", err)
+		return
+	}
+	name := filepath.Base(path)
+	setAuditTarget(c, name)
+	setAuditChange(c, nil, gin.H{"schema_version": meta.SchemaVersion, "tables": len(meta.Tables)})
+	c.JSON(http.StatusOK, gin.H{
+		"name":     name,
+		"metadata": meta,
+	})
+}
+
+// handleDownloadBackup Download a backup archive
+func (s *Server) handleDownloadBackup(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	if s.backupScheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Backups are not configured"})
+		return
+	}
+
+	name := c.Param("name")
+	if !store.IsBackupFileName(name) {
+		SafeBadRequest(c, "Invalid backup name")
+		return
+	}
+	files, err := store.ListBackupFiles(s.backupScheduler.Dir())
+	if err != nil {
+		SafeInternalError(c, "Failed to list backups", err)
+		return
+	}
+	for _, f := range files {
+		if f.Name == name {
+			c.FileAttachment(filepath.Join(s.backupScheduler.Dir(), name), name)
+			return
+		}
+	}
+	SafeNotFound(c, "Backup")
+}
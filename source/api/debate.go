@@ -4,7 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"nofx/debate"
 	"nofx/logger"
@@ -66,6 +71,10 @@ type CreateDebateRequest struct {
 	AutoExecute     bool                `json:"auto_execute"`
 	TraderID        string              `json:"trader_id"`
 	Participants    []ParticipantConfig `json:"participants" binding:"required,min=2"`
+	// Consensus options
+	ConsensusMethod    string `json:"consensus_method"`      // vote (default) or moderator
+	ModeratorAIModelID string `json:"moderator_ai_model_id"` // Required for the moderator method
+	WeightByAccuracy   bool   `json:"weight_by_accuracy"`    // Weight votes by each participant's scored accuracy
 	// OI Ranking data options
 	EnableOIRanking bool   `json:"enable_oi_ranking"` // Whether to include OI ranking data
 	OIRankingLimit  int    `json:"oi_ranking_limit"`  // Number of OI ranking entries (default 10)
@@ -75,7 +84,45 @@ type CreateDebateRequest struct {
 // ParticipantConfig represents a participant configuration
 type ParticipantConfig struct {
 	AIModelID   string `json:"ai_model_id" binding:"required"`
-	Personality string `json:"personality" binding:"required"`
+	Personality string `json:"personality" binding:"required"` // Built-in personality or the ID of a custom one
+}
+
+// CustomPersonalityRequest represents a request to create or update a custom personality
+type CustomPersonalityRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Prompt      string `json:"prompt" binding:"required"`
+	RiskBias    string `json:"risk_bias"` // aggressive/neutral/conservative (default neutral)
+	Color       string `json:"color"`
+	Emoji       string `json:"emoji"`
+}
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validate normalises the request, returning a user-facing error message when it is invalid
+func (r *CustomPersonalityRequest) validate() string {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Prompt = strings.TrimSpace(r.Prompt)
+	if r.Name == "" || r.Prompt == "" {
+		return "name and prompt are required"
+	}
+	if len(r.Name) > 50 || len(r.Description) > 200 || len(r.Prompt) > 4000 {
+		return "name, description or prompt too long"
+	}
+	switch r.RiskBias {
+	case "":
+		r.RiskBias = store.RiskBiasNeutral
+	case store.RiskBiasAggressive, store.RiskBiasNeutral, store.RiskBiasConservative:
+	default:
+		return "risk_bias must be aggressive, neutral or conservative"
+	}
+	if r.Color != "" && !hexColorPattern.MatchString(r.Color) {
+		return "color must be a hex colour such as #22C55E"
+	}
+	if utf8.RuneCountInString(r.Emoji) > 8 {
+		return "emoji too long"
+	}
+	return ""
 }
 
 // HandleListDebates lists all debates for a user
@@ -199,6 +246,33 @@ func (h *DebateHandler) HandleCreateDebate(c *gin.Context) {
 	if req.PromptVariant == "" {
 		req.PromptVariant = "balanced"
 	}
+	if req.ConsensusMethod != store.ConsensusModerator {
+		req.ConsensusMethod = store.ConsensusVote
+		req.ModeratorAIModelID = ""
+	} else {
+		moderatorModel, err := h.aiModelStore.GetByID(req.ModeratorAIModelID)
+		if err != nil || moderatorModel.UserID != userID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "moderator AI model not found"})
+			return
+		}
+	}
+
+	// Validate personalities before anything is stored: built-in, or one of the user's custom
+	// personalities (snapshotted into the participant)
+	customs := make(map[string]*store.CustomPersonality)
+	for _, p := range req.Participants {
+		if strings.HasPrefix(p.Personality, store.CustomPersonalityPrefix) {
+			custom, err := h.debateStore.GetCustomPersonality(userID, p.Personality)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("custom personality %s not found", p.Personality)})
+				return
+			}
+			customs[p.Personality] = custom
+		} else if !store.IsBuiltinPersonality(store.DebatePersonality(p.Personality)) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown personality %s", p.Personality)})
+			return
+		}
+	}
 
 	// Create session
 	session := &store.DebateSession{
@@ -214,6 +288,10 @@ func (h *DebateHandler) HandleCreateDebate(c *gin.Context) {
 		EnableOIRanking: req.EnableOIRanking,
 		OIRankingLimit:  req.OIRankingLimit,
 		OIDuration:      req.OIDuration,
+
+		ConsensusMethod:    req.ConsensusMethod,
+		ModeratorAIModelID: req.ModeratorAIModelID,
+		WeightByAccuracy:   req.WeightByAccuracy,
 	}
 
 	if err := h.debateStore.CreateSession(session); err != nil {
@@ -234,11 +312,8 @@ func (h *DebateHandler) HandleCreateDebate(c *gin.Context) {
 			continue
 		}
 
-		// Validate personality
 		personality := store.DebatePersonality(p.Personality)
-		if _, ok := store.PersonalityColors[personality]; !ok {
-			personality = store.PersonalityAnalyst
-		}
+		custom := customs[p.Personality]
 
 		participant := &store.DebateParticipant{
 			SessionID:   session.ID,
@@ -249,6 +324,9 @@ func (h *DebateHandler) HandleCreateDebate(c *gin.Context) {
 			Color:       store.PersonalityColors[personality],
 			SpeakOrder:  i,
 		}
+		if custom != nil {
+			participant.ApplyCustomPersonality(custom)
+		}
 
 		if err := h.debateStore.AddParticipant(participant); err != nil {
 			logger.Errorf("Failed to add participant: %v", err)
@@ -499,7 +577,7 @@ func (h *DebateHandler) HandleExecuteDebate(c *gin.Context) {
 	}
 
 	// Execute consensus
-	if err := h.engine.ExecuteConsensus(debateID, executor); err != nil {
+	if err := h.engine.ExecuteConsensus(debateID, req.TraderID, executor); err != nil {
 		SafeInternalError(c, "Execute consensus", err)
 		return
 	}
@@ -513,6 +591,36 @@ func (h *DebateHandler) HandleExecuteDebate(c *gin.Context) {
 	})
 }
 
+// HandleGetScorecards returns the user's debate scorecards: accuracy, confidence calibration and PnL
+// contribution of every personality, model or participant (model + personality), from scored vote outcomes
+func (h *DebateHandler) HandleGetScorecards(c *gin.Context) {
+	userID := c.GetString("user_id")
+	groupBy := c.DefaultQuery("group_by", debate.GroupByPersonality)
+	switch groupBy {
+	case debate.GroupByPersonality, debate.GroupByModel, debate.GroupByParticipant:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be personality, model or participant"})
+		return
+	}
+
+	var since time.Time
+	if days, err := strconv.Atoi(c.Query("days")); err == nil && days > 0 {
+		since = time.Now().AddDate(0, 0, -days)
+	}
+
+	outcomes, err := h.debateStore.GetScoredOutcomes(userID, since)
+	if err != nil {
+		logger.Errorf("Failed to get debate outcomes for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get scorecards"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"group_by":   groupBy,
+		"scorecards": debate.BuildScorecards(outcomes, groupBy),
+	})
+}
+
 // GetPersonalities returns available AI personalities
 func (h *DebateHandler) HandleGetPersonalities(c *gin.Context) {
 	personalities := []map[string]interface{}{
@@ -552,9 +660,109 @@ func (h *DebateHandler) HandleGetPersonalities(c *gin.Context) {
 			"description": "Focuses on position sizing, stop losses, and risk control",
 		},
 	}
+
+	// Append the user's own personalities
+	if custom, err := h.debateStore.ListCustomPersonalities(c.GetString("user_id")); err == nil {
+		for _, cp := range custom {
+			personalities = append(personalities, map[string]interface{}{
+				"id":          cp.ID,
+				"name":        cp.Name,
+				"emoji":       cp.Emoji,
+				"color":       cp.Color,
+				"description": cp.Description,
+				"prompt":      cp.Prompt,
+				"risk_bias":   cp.RiskBias,
+				"custom":      true,
+			})
+		}
+	}
 	c.JSON(http.StatusOK, personalities)
 }
 
+// HandleCreatePersonality creates a custom personality for the user
+func (h *DebateHandler) HandleCreatePersonality(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req CustomPersonalityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	cp := &store.CustomPersonality{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Prompt:      req.Prompt,
+		RiskBias:    req.RiskBias,
+		Color:       req.Color,
+		Emoji:       req.Emoji,
+	}
+	if err := h.debateStore.CreateCustomPersonality(cp); err != nil {
+		SafeInternalError(c, "Create personality", err)
+		return
+	}
+	c.JSON(http.StatusCreated, cp)
+}
+
+// HandleUpdatePersonality updates one of the user's custom personalities
+func (h *DebateHandler) HandleUpdatePersonality(c *gin.Context) {
+	userID := c.GetString("user_id")
+	cp, err := h.debateStore.GetCustomPersonality(userID, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "personality not found"})
+		return
+	}
+
+	var req CustomPersonalityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	cp.Name = req.Name
+	cp.Description = req.Description
+	cp.Prompt = req.Prompt
+	cp.RiskBias = req.RiskBias
+	if req.Color != "" {
+		cp.Color = req.Color
+	}
+	if req.Emoji != "" {
+		cp.Emoji = req.Emoji
+	}
+	if err := h.debateStore.UpdateCustomPersonality(cp); err != nil {
+		SafeInternalError(c, "Update personality", err)
+		return
+	}
+	c.JSON(http.StatusOK, cp)
+}
+
+// HandleDeletePersonality deletes one of the user's custom personalities; existing debates keep their snapshot
+func (h *DebateHandler) HandleDeletePersonality(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if _, err := h.debateStore.GetCustomPersonality(userID, c.Param("id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "personality not found"})
+		return
+	}
+	if err := h.debateStore.DeleteCustomPersonality(userID, c.Param("id")); err != nil {
+		SafeInternalError(c, "Delete personality", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "personality deleted"})
+}
+
 // SSE broadcast helpers
 func (h *DebateHandler) addSubscriber(sessionID string, ch chan []byte) {
 	h.subscribersMu.Lock()
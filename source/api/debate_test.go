@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestCreateDebateRejectsUnknownPersonality Test that unknown personalities are rejected before a session is stored
+func TestCreateDebateRejectsUnknownPersonality(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	st, err := store.New(filepath.Join(t.TempDir(), "nofx.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer st.Close()
+
+	if err := st.Strategy().Create(&store.Strategy{ID: "s1", UserID: "u1", Name: "test", Config: `{"coin_source":{"source_type":"static","static_coins":["BTCUSDT"]}}`}); err != nil {
+		t.Fatalf("create strategy: %v", err)
+	}
+
+	h := &DebateHandler{debateStore: st.Debate(), strategyStore: st.Strategy(), aiModelStore: st.AIModel()}
+	router := gin.New()
+	router.POST("/debates", func(c *gin.Context) {
+		c.Set("user_id", "u1")
+		h.HandleCreateDebate(c)
+	})
+
+	tests := []struct {
+		personality string
+		message     string
+	}{
+		{store.CustomPersonalityPrefix + "missing", "custom personality " + store.CustomPersonalityPrefix + "missing not found"},
+		{"pirate", "unknown personality pirate"},
+	}
+	for _, tt := range tests {
+		body := `{"name":"d","strategy_id":"s1","symbol":"BTCUSDT","participants":[` +
+			`{"ai_model_id":"m1","personality":"bull"},{"ai_model_id":"m2","personality":"` + tt.personality + `"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/debates", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.message) {
+			t.Errorf("%s: expected 400 with %q, got %d %s", tt.personality, tt.message, w.Code, w.Body.String())
+		}
+	}
+
+	sessions, err := st.Debate().GetSessionsByUser("u1")
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("expected no stored sessions, got %d (%v)", len(sessions), err)
+	}
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"nofx/config"
+	"nofx/logger"
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keyRotationStatus progress of the last data key rotation
+type keyRotationStatus struct {
+	Running    bool                     `json:"running"`
+	StartedAt  *time.Time               `json:"started_at,omitempty"`
+	FinishedAt *time.Time               `json:"finished_at,omitempty"`
+	Result     *store.KeyRotationResult `json:"result,omitempty"`
+	Error      string                   `json:"error,omitempty"`
+}
+
+var (
+	keyRotationMu sync.Mutex
+	keyRotation   keyRotationStatus
+)
+
+// requireAdmin allows instance administrators (ADMIN_EMAILS) acting from a login session
+func requireAdmin(c *gin.Context) bool {
+	if c.GetString("api_token_id") != "" {
+		SafeForbidden(c, "This operation is only available from a login session")
+		return false
+	}
+	if !config.Get().IsAdminEmail(c.GetString("email")) {
+		SafeForbidden(c, "Administrator privileges required")
+		return false
+	}
+	return true
+}
+
+// handleGetEncryptionKeys Get loaded data key IDs and the status of the last rotation
+func (s *Server) handleGetEncryptionKeys(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+	cs := s.cryptoHandler.cryptoService
+
+	keyRotationMu.Lock()
+	status := keyRotation
+	keyRotationMu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"provider":      cs.KeyProviderName(),
+		"active_key_id": cs.ActiveKeyID(),
+		"key_ids":       cs.KeyIDs(),
+		"rotation":      status,
+	})
+}
+
+// handleRotateEncryptionKeys Reload data keys from the key provider and re-encrypt all stored secrets
+// with the active key in the background
+func (s *Server) handleRotateEncryptionKeys(c *gin.Context) {
+	if !requireAdmin(c) {
+		return
+	}
+
+	var req struct {
+		BatchSize int `json:"batch_size"`
+	}
+	_ = c.ShouldBindJSON(&req)
+
+	keyRotationMu.Lock()
+	if keyRotation.Running {
+		keyRotationMu.Unlock()
+		SafeBadRequest(c, "A key rotation is already running")
+		return
+	}
+
+	cs := s.cryptoHandler.cryptoService
+	previousKID := cs.ActiveKeyID()
+	if err := cs.ReloadDataKeys(); err != nil {
+		keyRotationMu.Unlock()
+		SafeInternalError(c, "Failed to reload data keys", err)
+		return
+	}
+	now := time.Now().UTC()
+	keyRotation = keyRotationStatus{Running: true, StartedAt: &now}
+	keyRotationMu.Unlock()
+
+	activeKID := cs.ActiveKeyID()
+	actor := c.GetString("actor_user_id")
+	logger.Infof("🔐 Data key rotation started by %s: %s -> %s", actor, previousKID, activeKID)
+
+	go func() {
+		result, err := s.store.RotateEncryptionKeys(cs, req.BatchSize)
+		finished := time.Now().UTC()
+
+		keyRotationMu.Lock()
+		keyRotation.Running = false
+		keyRotation.FinishedAt = &finished
+		keyRotation.Result = result
+		if err != nil {
+			keyRotation.Error = err.Error()
+		}
+		keyRotationMu.Unlock()
+
+		if err != nil {
+			logger.Errorf("❌ Data key rotation failed: %v", err)
+		} else {
+			logger.Infof("✅ Data key rotation finished: %d rows scanned, %d values re-encrypted, %d failed",
+				result.Scanned, result.Reencrypted, result.Failed)
+		}
+		if auditErr := s.store.Audit().Record(actor, "key_rotation", "encryption.rotate_finished", "data_key", activeKID, nil, result); auditErr != nil {
+			logger.Warnf("⚠️ Failed to write audit entry: %v", auditErr)
+		}
+	}()
+
+	setAuditTarget(c, activeKID)
+	setAuditChange(c, gin.H{"active_key_id": previousKID}, gin.H{"active_key_id": activeKID})
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":       "Key rotation started",
+		"active_key_id": activeKID,
+		"key_ids":       cs.KeyIDs(),
+	})
+}
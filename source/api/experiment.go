@@ -0,0 +1,235 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"nofx/experiment"
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxExperimentShadows shadow variants per experiment; each one costs an extra AI call per cycle
+const maxExperimentShadows = 4
+
+// ExperimentVariantRequest a shadow variant: a saved strategy or an inline strategy config
+type ExperimentVariantRequest struct {
+	Name          string                `json:"name"`
+	StrategyID    string                `json:"strategy_id"`
+	Config        *store.StrategyConfig `json:"config"` // Used when strategy_id is empty
+	PromptVariant string                `json:"prompt_variant"`
+}
+
+// ExperimentRequest request to start an experiment on a trader
+type ExperimentRequest struct {
+	Name        string                     `json:"name" binding:"required"`
+	Description string                     `json:"description"`
+	TraderID    string                     `json:"trader_id" binding:"required"`
+	LiveName    string                     `json:"live_name"` // Name of the live variant, default "live"
+	FeeBps      float64                    `json:"fee_bps"`   // Fee on hypothetical fills, default 5
+	Variants    []ExperimentVariantRequest `json:"variants" binding:"required"`
+}
+
+// handleListExperiments List the user's experiments
+func (s *Server) handleListExperiments(c *gin.Context) {
+	experiments, err := s.store.Experiment().List(c.GetString("user_id"))
+	if err != nil {
+		SafeInternalError(c, "Failed to get experiments", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"experiments": experiments})
+}
+
+// handleCreateExperiment Start an experiment: the trader's own strategy is the live variant
+func (s *Server) handleCreateExperiment(c *gin.Context) {
+	userID := c.GetString("user_id")
+	var req ExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		SafeBadRequest(c, "name is required (max 100 characters)")
+		return
+	}
+	if len(req.Variants) == 0 || len(req.Variants) > maxExperimentShadows {
+		SafeBadRequest(c, fmt.Sprintf("an experiment needs 1 to %d shadow variants", maxExperimentShadows))
+		return
+	}
+
+	trader, err := s.store.Trader().GetByID(req.TraderID)
+	if err != nil || trader.UserID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Trader not found"})
+		return
+	}
+	running, err := s.store.Experiment().GetRunningForTrader(trader.ID)
+	if err != nil {
+		SafeInternalError(c, "Failed to check running experiments", err)
+		return
+	}
+	if running != nil {
+		SafeBadRequest(c, fmt.Sprintf("trader already runs experiment %s, stop it first", running.Name))
+		return
+	}
+
+	liveName := strings.TrimSpace(req.LiveName)
+	if liveName == "" {
+		liveName = "live"
+	}
+	variants := []*store.ExperimentVariant{{
+		Name:          liveName,
+		IsLive:        true,
+		StrategyID:    trader.StrategyID,
+		PromptVariant: "balanced",
+	}}
+	for i, v := range req.Variants {
+		variant, err := s.buildShadowVariant(userID, v)
+		if err != nil {
+			SafeBadRequest(c, fmt.Sprintf("variant %d: %v", i+1, err))
+			return
+		}
+		if variant.Name == "" {
+			variant.Name = fmt.Sprintf("variant %c", 'B'+i)
+		}
+		variants = append(variants, variant)
+	}
+
+	feeBps := req.FeeBps
+	if feeBps <= 0 {
+		feeBps = 5
+	}
+	exp := &store.Experiment{
+		UserID:      userID,
+		TraderID:    trader.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		FeeBps:      feeBps,
+	}
+	if err := s.store.Experiment().Create(exp, variants); err != nil {
+		SafeInternalError(c, "Failed to create experiment", err)
+		return
+	}
+	setAuditTarget(c, exp.ID)
+	c.JSON(http.StatusOK, gin.H{"experiment": exp, "variants": variants})
+}
+
+// buildShadowVariant snapshots a shadow variant's strategy config
+func (s *Server) buildShadowVariant(userID string, req ExperimentVariantRequest) (*store.ExperimentVariant, error) {
+	switch req.PromptVariant {
+	case "", "balanced", "aggressive", "conservative", "scalping":
+	default:
+		return nil, fmt.Errorf("unknown prompt variant %q", req.PromptVariant)
+	}
+
+	config := req.Config
+	if req.StrategyID != "" {
+		strategy, err := s.store.Strategy().Get(userID, req.StrategyID)
+		if err != nil {
+			return nil, fmt.Errorf("strategy not found")
+		}
+		if config, err = strategy.ParseConfig(); err != nil {
+			return nil, fmt.Errorf("failed to parse strategy config")
+		}
+	}
+	if config == nil {
+		return nil, fmt.Errorf("strategy_id or config is required")
+	}
+	promptTemplate, err := s.resolvePromptTemplate(userID, config.PromptTemplate)
+	if err != nil {
+		return nil, err
+	}
+	config.PromptTemplate = promptTemplate
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	return &store.ExperimentVariant{
+		Name:          strings.TrimSpace(req.Name),
+		StrategyID:    req.StrategyID,
+		PromptVariant: req.PromptVariant,
+		Config:        string(configJSON),
+	}, nil
+}
+
+// handleGetExperiment Get an experiment with the comparison of its variants
+func (s *Server) handleGetExperiment(c *gin.Context) {
+	exp, err := s.store.Experiment().Get(c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+		return
+	}
+	variants, err := s.store.Experiment().GetVariants(exp.ID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get experiment variants", err)
+		return
+	}
+	decisions, err := s.store.Experiment().GetDecisions(exp.ID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get experiment decisions", err)
+		return
+	}
+	points, err := s.store.Experiment().GetEquityPoints(exp.ID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get experiment equity", err)
+		return
+	}
+	c.JSON(http.StatusOK, experiment.Compare(exp, variants, decisions, points))
+}
+
+// handleGetExperimentDecisions Get every variant's decisions, optionally for one cycle (?cycle=)
+func (s *Server) handleGetExperimentDecisions(c *gin.Context) {
+	exp, err := s.store.Experiment().Get(c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+		return
+	}
+	decisions, err := s.store.Experiment().GetDecisions(exp.ID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get experiment decisions", err)
+		return
+	}
+	if cycle, err := strconv.Atoi(c.Query("cycle")); err == nil {
+		filtered := make([]*store.ExperimentDecision, 0)
+		for _, d := range decisions {
+			if d.Cycle == cycle {
+				filtered = append(filtered, d)
+			}
+		}
+		decisions = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"decisions": decisions})
+}
+
+// handleStopExperiment Stop a running experiment
+func (s *Server) handleStopExperiment(c *gin.Context) {
+	if err := s.store.Experiment().Stop(c.GetString("user_id"), c.Param("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Running experiment not found"})
+			return
+		}
+		SafeInternalError(c, "Failed to stop experiment", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Experiment stopped"})
+}
+
+// handleDeleteExperiment Delete an experiment and its records
+func (s *Server) handleDeleteExperiment(c *gin.Context) {
+	if err := s.store.Experiment().Delete(c.GetString("user_id"), c.Param("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Experiment not found"})
+			return
+		}
+		SafeInternalError(c, "Failed to delete experiment", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Experiment deleted"})
+}
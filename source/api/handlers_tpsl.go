@@ -56,6 +56,13 @@ func (s *Server) handleModifyTPSL(c *gin.Context) {
 		return
 	}
 
+	auditAfter := gin.H{"position_id": req.PositionID, "symbol": position.Symbol, "side": position.Side, "take_profit": req.NewTP, "stop_loss": req.NewSL}
+	if tpslRecord != nil {
+		setAuditChange(c, gin.H{"position_id": req.PositionID, "symbol": position.Symbol, "side": position.Side, "take_profit": tpslRecord.CurrentTP, "stop_loss": tpslRecord.CurrentSL}, auditAfter)
+	} else {
+		setAuditChange(c, nil, auditAfter)
+	}
+
 	// 如果没有 TP/SL 记录，创建新的
 	if tpslRecord == nil {
 		tpslRecord = &store.TPSLRecord{
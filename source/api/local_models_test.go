@@ -0,0 +1,63 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestListLocalModelsUsesSavedURL Test that local models are only listed from the caller's saved model URL
+func TestListLocalModelsUsesSavedURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	st, err := store.New(filepath.Join(t.TempDir(), "nofx.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer st.Close()
+
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"models":[{"name":"llama3.1:8b"}]}`))
+	}))
+	defer ollama.Close()
+	if err := st.AIModel().Create("u1", "u1_ollama", "Ollama", "ollama", true, "", ollama.URL); err != nil {
+		t.Fatalf("create model: %v", err)
+	}
+
+	s := &Server{store: st}
+	router := gin.New()
+	router.GET("/models/local", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+		s.handleListLocalModels(c)
+	})
+
+	tests := []struct {
+		name   string
+		user   string
+		query  string
+		status int
+	}{
+		{"own saved model", "u1", "model_id=u1_ollama&base_url=http://169.254.169.254", http.StatusOK},
+		{"other user's model", "u2", "model_id=u1_ollama", http.StatusNotFound},
+		{"no model or provider", "u1", "base_url=" + ollama.URL, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/models/local?"+tt.query, nil)
+			req.Header.Set("X-User", tt.user)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusOK && !strings.Contains(w.Body.String(), "llama3.1:8b") {
+				t.Errorf("expected the saved server's models, got %s", w.Body.String())
+			}
+		})
+	}
+}
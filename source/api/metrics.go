@@ -0,0 +1,28 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"nofx/config"
+	"nofx/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleMetrics Prometheus/OpenMetrics scrape endpoint, requires METRICS_TOKEN as a bearer token
+// The endpoint is disabled (404) while METRICS_TOKEN is not set, so trader IDs and balances are never public
+func (s *Server) handleMetrics(c *gin.Context) {
+	token := config.Get().MetricsToken
+	if token == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Metrics endpoint is disabled, set METRICS_TOKEN to enable it"})
+		return
+	}
+	got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid metrics token"})
+		return
+	}
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
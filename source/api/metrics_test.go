@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"nofx/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestHandleMetricsRequiresToken Test that /metrics is disabled without METRICS_TOKEN and checks the bearer token
+func TestHandleMetricsRequiresToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := &Server{}
+	router := gin.New()
+	router.GET("/metrics", s.handleMetrics)
+
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		status int
+	}{
+		{"no token configured", "", "", http.StatusNotFound},
+		{"no token configured with header", "", "Bearer anything", http.StatusNotFound},
+		{"missing header", "secret", "", http.StatusUnauthorized},
+		{"wrong token", "secret", "Bearer wrong", http.StatusUnauthorized},
+		{"valid token", "secret", "Bearer secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("METRICS_TOKEN", tt.token)
+			config.Init()
+
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+}
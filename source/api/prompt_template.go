@@ -0,0 +1,179 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"nofx/kernel"
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PromptTemplateRequest request to create or update a prompt template
+type PromptTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	System      string `json:"system"` // Go template over the system prompt sections, e.g. {{.RiskControl}}
+	User        string `json:"user"`   // Go template over the user prompt sections, e.g. {{.Positions}}
+	IsPublic    bool   `json:"is_public"`
+}
+
+// validate normalises the request, returning a user-facing error message when it is invalid
+func (r *PromptTemplateRequest) validate() string {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" || len(r.Name) > 100 || len(r.Description) > 500 {
+		return "name is required (max 100 characters), description max 500 characters"
+	}
+	if strings.TrimSpace(r.System) == "" && strings.TrimSpace(r.User) == "" {
+		return "a template needs a system or a user part"
+	}
+	if len(r.System) > 50000 || len(r.User) > 50000 {
+		return "template too long"
+	}
+	if err := kernel.ValidatePromptTemplate(r.System, r.User); err != nil {
+		return "invalid template: " + err.Error()
+	}
+	return ""
+}
+
+// handleListPromptTemplates List the user's prompt templates
+func (s *Server) handleListPromptTemplates(c *gin.Context) {
+	templates, err := s.store.PromptTemplate().List(c.GetString("user_id"))
+	if err != nil {
+		SafeInternalError(c, "Failed to get prompt templates", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": templates})
+}
+
+// handlePublicPromptTemplates Get prompt templates shared to the strategy market (no auth required)
+func (s *Server) handlePublicPromptTemplates(c *gin.Context) {
+	templates, err := s.store.PromptTemplate().ListPublic()
+	if err != nil {
+		SafeInternalError(c, "Failed to get public prompt templates", err)
+		return
+	}
+	result := make([]gin.H, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, gin.H{
+			"id":          t.ID,
+			"name":        t.Name,
+			"description": t.Description,
+			"system":      t.System,
+			"user":        t.User,
+			"version":     t.Version,
+			"updated_at":  t.UpdatedAt,
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{"templates": result})
+}
+
+// handleGetPromptTemplate Get a prompt template (own or public) with its version history
+func (s *Server) handleGetPromptTemplate(c *gin.Context) {
+	t, err := s.store.PromptTemplate().Get(c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Prompt template not found"})
+		return
+	}
+	versions, err := s.store.PromptTemplate().ListVersions(t.ID)
+	if err != nil {
+		SafeInternalError(c, "Failed to get prompt template versions", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"template": t, "versions": versions})
+}
+
+// handleCreatePromptTemplate Create a prompt template
+func (s *Server) handleCreatePromptTemplate(c *gin.Context) {
+	var req PromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		SafeBadRequest(c, msg)
+		return
+	}
+
+	t := &store.PromptTemplate{
+		UserID:      c.GetString("user_id"),
+		Name:        req.Name,
+		Description: req.Description,
+		System:      req.System,
+		User:        req.User,
+		IsPublic:    req.IsPublic,
+	}
+	if err := s.store.PromptTemplate().Create(t); err != nil {
+		SafeInternalError(c, "Failed to create prompt template", err)
+		return
+	}
+	setAuditTarget(c, t.ID)
+	c.JSON(http.StatusOK, t)
+}
+
+// handleUpdatePromptTemplate Update a prompt template; changed content becomes a new version
+func (s *Server) handleUpdatePromptTemplate(c *gin.Context) {
+	var req PromptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		SafeBadRequest(c, msg)
+		return
+	}
+
+	t := &store.PromptTemplate{
+		ID:          c.Param("id"),
+		UserID:      c.GetString("user_id"),
+		Name:        req.Name,
+		Description: req.Description,
+		System:      req.System,
+		User:        req.User,
+		IsPublic:    req.IsPublic,
+	}
+	if err := s.store.PromptTemplate().Update(t); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Prompt template not found"})
+			return
+		}
+		SafeInternalError(c, "Failed to update prompt template", err)
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// handleDeletePromptTemplate Delete a prompt template; strategies keep their snapshot of it
+func (s *Server) handleDeletePromptTemplate(c *gin.Context) {
+	if err := s.store.PromptTemplate().Delete(c.GetString("user_id"), c.Param("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Prompt template not found"})
+			return
+		}
+		SafeInternalError(c, "Failed to delete prompt template", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Prompt template deleted"})
+}
+
+// resolvePromptTemplate snapshots the selected template version for a strategy or backtest. A reference
+// without an ID is an inline template and is only validated; an empty reference resolves to nil.
+func (s *Server) resolvePromptTemplate(userID string, ref *store.PromptTemplateRef) (*store.PromptTemplateRef, error) {
+	if ref == nil {
+		return nil, nil
+	}
+	if ref.ID != "" {
+		if err := s.store.PromptTemplate().Resolve(userID, ref); err != nil {
+			return nil, err
+		}
+	} else if ref.System == "" && ref.User == "" {
+		return nil, nil
+	}
+	if err := kernel.ValidatePromptTemplate(ref.System, ref.User); err != nil {
+		return nil, err
+	}
+	return ref, nil
+}
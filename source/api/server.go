@@ -10,9 +10,12 @@ import (
 	"nofx/backtest"
 	"nofx/config"
 	"nofx/crypto"
+	"nofx/debate"
 	"nofx/logger"
 	"nofx/manager"
 	"nofx/market"
+	"nofx/mcp"
+	"nofx/metrics"
 	"nofx/provider/alpaca"
 	"nofx/provider/coinank/coinank_api"
 	"nofx/provider/coinank/coinank_enum"
@@ -54,6 +57,7 @@ type Server struct {
 	httpServer          *http.Server
 	port                int
 	klineCache          *KlineCache
+	backupScheduler     *store.BackupScheduler
 }
 
 // NewServer Creates API server
@@ -69,11 +73,8 @@ func NewServer(traderManager *manager.TraderManager, st *store.Store, cryptoServ
 	// Create crypto handler
 	cryptoHandler := NewCryptoHandler(cryptoService)
 
-	// Create debate store and handler
+	// Create debate store and handler (tables are created by store migrations)
 	debateStore := store.NewDebateStore(st.GormDB())
-	if err := debateStore.InitSchema(); err != nil {
-		logger.Errorf("Failed to initialize debate schema: %v", err)
-	}
 	debateHandler := NewDebateHandler(debateStore, st.Strategy(), st.AIModel())
 	debateHandler.SetTraderManager(traderManager)
 
@@ -92,6 +93,11 @@ func NewServer(traderManager *manager.TraderManager, st *store.Store, cryptoServ
 		},
 	}
 
+	// Resumed backtests have their API keys stripped from the stored config, resolve them from the model IDs again
+	if backtestManager != nil {
+		backtestManager.SetAIResolver(s.hydrateBacktestAIConfig)
+	}
+
 	// Setup routes
 	s.setupRoutes()
 
@@ -108,7 +114,7 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Workspace-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusOK)
@@ -121,6 +127,9 @@ func corsMiddleware() gin.HandlerFunc {
 
 // setupRoutes Setup routes
 func (s *Server) setupRoutes() {
+	// Prometheus/OpenMetrics scrape endpoint (outside /api so scrapers use the conventional path)
+	s.router.GET("/metrics", s.handleMetrics)
+
 	// API route group
 	api := s.router.Group("/api")
 	{
@@ -136,6 +145,14 @@ func (s *Server) setupRoutes() {
 			})
 		})
 
+		// Market data provider health check (public, no authentication)
+		api.GET("/health/market-data", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{
+				"success": true,
+				"data":    market.KlineProviderHealth(),
+			})
+		})
+
 		// Admin login (used in admin mode, public)
 
 		// System supported models and exchanges (no authentication required)
@@ -164,6 +181,7 @@ func (s *Server) setupRoutes() {
 
 		// Public strategy market (no authentication required)
 		api.GET("/strategies/public", s.handlePublicStrategies)
+		api.GET("/prompt-templates/public", s.handlePublicPromptTemplates)
 
 		// Authentication related routes (no authentication required)
 		api.POST("/register", s.handleRegister)
@@ -172,7 +190,7 @@ func (s *Server) setupRoutes() {
 		api.POST("/complete-registration", s.handleCompleteRegistration)
 
 		// Routes requiring authentication
-		protected := api.Group("/", s.authMiddleware())
+		protected := api.Group("/", s.authMiddleware(), s.workspaceMiddleware(), s.auditMiddleware())
 		{
 			// Logout (add to blacklist)
 			protected.POST("/logout", s.handleLogout)
@@ -199,6 +217,7 @@ func (s *Server) setupRoutes() {
 			// AI model configuration
 			protected.GET("/models", s.handleGetModelConfigs)
 			protected.PUT("/models", s.handleUpdateModelConfigs)
+			protected.GET("/models/local", s.handleListLocalModels)
 
 			// Exchange configuration
 			protected.GET("/exchanges", s.handleGetExchangeConfigs)
@@ -219,9 +238,28 @@ func (s *Server) setupRoutes() {
 			protected.POST("/strategies/:id/activate", s.handleActivateStrategy)
 			protected.POST("/strategies/:id/duplicate", s.handleDuplicateStrategy)
 
+			// Prompt template library
+			protected.GET("/prompt-templates", s.handleListPromptTemplates)
+			protected.GET("/prompt-templates/:id", s.handleGetPromptTemplate)
+			protected.POST("/prompt-templates", s.handleCreatePromptTemplate)
+			protected.PUT("/prompt-templates/:id", s.handleUpdatePromptTemplate)
+			protected.DELETE("/prompt-templates/:id", s.handleDeletePromptTemplate)
+
+			// Prompt/strategy A/B experiments on live traders
+			protected.GET("/experiments", s.handleListExperiments)
+			protected.POST("/experiments", s.handleCreateExperiment)
+			protected.GET("/experiments/:id", s.handleGetExperiment)
+			protected.GET("/experiments/:id/decisions", s.handleGetExperimentDecisions)
+			protected.POST("/experiments/:id/stop", s.handleStopExperiment)
+			protected.DELETE("/experiments/:id", s.handleDeleteExperiment)
+
 			// Debate Arena
 			protected.GET("/debates", s.debateHandler.HandleListDebates)
 			protected.GET("/debates/personalities", s.debateHandler.HandleGetPersonalities)
+			protected.GET("/debates/scorecards", s.debateHandler.HandleGetScorecards)
+			protected.POST("/debates/personalities", s.debateHandler.HandleCreatePersonality)
+			protected.PUT("/debates/personalities/:id", s.debateHandler.HandleUpdatePersonality)
+			protected.DELETE("/debates/personalities/:id", s.debateHandler.HandleDeletePersonality)
 			protected.GET("/debates/:id", s.debateHandler.HandleGetDebate)
 			protected.POST("/debates", s.debateHandler.HandleCreateDebate)
 			protected.POST("/debates/:id/start", s.debateHandler.HandleStartDebate)
@@ -238,11 +276,13 @@ func (s *Server) setupRoutes() {
 			protected.GET("/positions", s.handlePositions)
 			protected.GET("/positions/history", s.handlePositionHistory)
 			protected.GET("/trades", s.handleTrades)
-			protected.GET("/orders", s.handleOrders)                           // Order list (all orders)
-			protected.GET("/orders/:id/fills", s.handleOrderFills)             // Order fill details
-			protected.GET("/open-orders", s.handleOpenOrders)                  // Open orders from exchange (pending SL/TP)
-			protected.GET("/pending-orders", s.handlePendingOrders)            // Pending orders from delay execution
-			protected.GET("/traders/:id/tpsl-records", s.handleGetTPSLRecords) // TP/SL tracking records
+			protected.GET("/orders", s.handleOrders)                            // Order list (all orders)
+			protected.GET("/orders/:id/fills", s.handleOrderFills)              // Order fill details
+			protected.GET("/open-orders", s.handleOpenOrders)                   // Open orders from exchange (pending SL/TP)
+			protected.GET("/pending-orders", s.handlePendingOrders)             // Pending orders from delay execution
+			protected.GET("/traders/:id/tpsl-records", s.handleGetTPSLRecords)  // TP/SL tracking records
+			protected.GET("/traders/:id/logs", s.handleGetTraderLogs)           // Recent structured log lines
+			protected.GET("/traders/:id/logs/stream", s.handleStreamTraderLogs) // Live log stream (SSE)
 			protected.GET("/decisions", s.handleDecisions)
 			protected.GET("/decisions/latest", s.handleLatestDecisions)
 			protected.GET("/statistics", s.handleStatistics)
@@ -285,6 +325,33 @@ func (s *Server) setupRoutes() {
 			// Adaptive Stop Loss routes
 			protected.GET("/adaptive-stoploss/:traderId", s.handleGetAdaptiveStopLoss)
 			protected.PUT("/adaptive-stoploss/:traderId", s.handleUpdateAdaptiveStopLoss)
+
+			// Personal API tokens
+			protected.GET("/api-tokens", s.handleListAPITokens)
+			protected.POST("/api-tokens", s.handleCreateAPIToken)
+			protected.DELETE("/api-tokens/:id", s.handleRevokeAPIToken)
+
+			// Team workspaces (select one with the X-Workspace-ID header)
+			protected.GET("/workspaces", s.handleListWorkspaces)
+			protected.POST("/workspaces", s.handleCreateWorkspace)
+			protected.GET("/workspaces/:id/members", s.handleListWorkspaceMembers)
+			protected.POST("/workspaces/:id/members", s.handleAddWorkspaceMember)
+			protected.PUT("/workspaces/:id/members/:userId", s.handleUpdateWorkspaceMember)
+			protected.DELETE("/workspaces/:id/members/:userId", s.handleRemoveWorkspaceMember)
+
+			// Audit log (state-changing requests are recorded by auditMiddleware)
+			protected.GET("/audit-logs", s.handleListAuditLogs)
+			protected.GET("/audit-logs/export", s.handleExportAuditLogs)
+			protected.GET("/audit-logs/verify", s.handleVerifyAuditLog)
+
+			// Data encryption keys (instance administrators only)
+			protected.GET("/encryption/keys", s.handleGetEncryptionKeys)
+			protected.POST("/encryption/rotate", s.handleRotateEncryptionKeys)
+
+			// Backups (instance administrators only)
+			protected.GET("/backups", s.handleListBackups)
+			protected.POST("/backups", s.handleCreateBackup)
+			protected.GET("/backups/:name", s.handleDownloadBackup)
 		}
 	}
 }
@@ -592,21 +659,23 @@ func (s *Server) getTraderFromQuery(c *gin.Context) (*manager.TraderManager, str
 
 // AI trader management related structures
 type CreateTraderRequest struct {
-	Name                string  `json:"name" binding:"required"`
-	AIModelID           string  `json:"ai_model_id" binding:"required"`
-	ExchangeID          string  `json:"exchange_id" binding:"required"`
-	StrategyID          string  `json:"strategy_id"` // Strategy ID (new version)
-	InitialBalance      float64 `json:"initial_balance"`
-	ScanIntervalMinutes int     `json:"scan_interval_minutes"`
-	IsCrossMargin       *bool   `json:"is_cross_margin"`     // Pointer type, nil means use default value true
-	ShowInCompetition   *bool   `json:"show_in_competition"` // Pointer type, nil means use default value true
+	Name                string   `json:"name" binding:"required"`
+	AIModelID           string   `json:"ai_model_id" binding:"required"`
+	ExchangeID          string   `json:"exchange_id" binding:"required"`
+	StrategyID          string   `json:"strategy_id"` // Strategy ID (new version)
+	InitialBalance      float64  `json:"initial_balance"`
+	ScanIntervalMinutes int      `json:"scan_interval_minutes"`
+	IsCrossMargin       *bool    `json:"is_cross_margin"`       // Pointer type, nil means use default value true
+	ShowInCompetition   *bool    `json:"show_in_competition"`   // Pointer type, nil means use default value true
+	DebatePanelID       string   `json:"debate_panel_id"`       // Debate session whose panel decides each cycle (empty = single AI model)
+	FallbackAIModelIDs  []string `json:"fallback_ai_model_ids"` // AI models tried in order when the primary fails or is rate limited
 	// The following fields are kept for backward compatibility, new version uses strategy config
 	BTCETHLeverage       int    `json:"btc_eth_leverage"`
 	AltcoinLeverage      int    `json:"altcoin_leverage"`
 	TradingSymbols       string `json:"trading_symbols"`
 	CustomPrompt         string `json:"custom_prompt"`
 	OverrideBasePrompt   bool   `json:"override_base_prompt"`
-	SystemPromptTemplate string `json:"system_prompt_template"` // System prompt template name
+	SystemPromptTemplate string `json:"system_prompt_template"` // "default" or a prompt template library ID
 	UseAI500             bool   `json:"use_ai500"`
 	UseOITop             bool   `json:"use_oi_top"`
 }
@@ -713,6 +782,21 @@ func (s *Server) handleCreateTrader(c *gin.Context) {
 		}
 	}
 
+	// Validate debate panel
+	if req.DebatePanelID != "" {
+		if err := debate.ValidatePanel(s.store.Debate(), req.DebatePanelID, userID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	// Validate failover chain
+	fallbackAIModelIDs, err := s.validateFallbackAIModels(userID, req.AIModelID, req.FallbackAIModelIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Generate trader ID (use short UUID prefix for readability)
 	exchangeIDShort := req.ExchangeID
 	if len(exchangeIDShort) > 8 {
@@ -875,6 +959,8 @@ func (s *Server) handleCreateTrader(c *gin.Context) {
 		IsCrossMargin:        isCrossMargin,
 		ShowInCompetition:    showInCompetition,
 		ScanIntervalMinutes:  scanIntervalMinutes,
+		DebatePanelID:        req.DebatePanelID,
+		FallbackAIModelIDs:   fallbackAIModelIDs,
 		IsRunning:            false,
 	}
 
@@ -902,6 +988,8 @@ func (s *Server) handleCreateTrader(c *gin.Context) {
 		s.reflectionScheduler.RegisterTrader(traderID)
 	}
 
+	setAuditTarget(c, traderID)
+	setAuditChange(c, nil, traderAuditSummary(traderRecord))
 	logger.Infof("✓ Trader created successfully: %s (model: %s, exchange: %s)", req.Name, req.AIModelID, req.ExchangeID)
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -912,16 +1000,44 @@ func (s *Server) handleCreateTrader(c *gin.Context) {
 	})
 }
 
+// validateFallbackAIModels checks a failover chain (the user's enabled models, distinct from the primary)
+// and returns it in the stored comma-separated form
+func (s *Server) validateFallbackAIModels(userID, primaryID string, ids []string) (string, error) {
+	seen := map[string]bool{primaryID: true}
+	var chain []string
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if seen[id] {
+			return "", fmt.Errorf("fallback AI model %s is already in the chain", id)
+		}
+		seen[id] = true
+		model, err := s.store.AIModel().Get(userID, id)
+		if err != nil {
+			return "", fmt.Errorf("fallback AI model %s not found", id)
+		}
+		if !model.Enabled {
+			return "", fmt.Errorf("fallback AI model %s is not enabled", model.Name)
+		}
+		chain = append(chain, id)
+	}
+	return strings.Join(chain, ","), nil
+}
+
 // UpdateTraderRequest Update trader request
 type UpdateTraderRequest struct {
-	Name                string  `json:"name" binding:"required"`
-	AIModelID           string  `json:"ai_model_id" binding:"required"`
-	ExchangeID          string  `json:"exchange_id" binding:"required"`
-	StrategyID          string  `json:"strategy_id"` // Strategy ID (new version)
-	InitialBalance      float64 `json:"initial_balance"`
-	ScanIntervalMinutes int     `json:"scan_interval_minutes"`
-	IsCrossMargin       *bool   `json:"is_cross_margin"`
-	ShowInCompetition   *bool   `json:"show_in_competition"`
+	Name                string    `json:"name" binding:"required"`
+	AIModelID           string    `json:"ai_model_id" binding:"required"`
+	ExchangeID          string    `json:"exchange_id" binding:"required"`
+	StrategyID          string    `json:"strategy_id"` // Strategy ID (new version)
+	InitialBalance      float64   `json:"initial_balance"`
+	ScanIntervalMinutes int       `json:"scan_interval_minutes"`
+	IsCrossMargin       *bool     `json:"is_cross_margin"`
+	ShowInCompetition   *bool     `json:"show_in_competition"`
+	DebatePanelID       *string   `json:"debate_panel_id"`       // nil keeps the current panel, "" switches back to the single AI model
+	FallbackAIModelIDs  *[]string `json:"fallback_ai_model_ids"` // nil keeps the current failover chain, [] removes it
 	// The following fields are kept for backward compatibility, new version uses strategy config
 	BTCETHLeverage       int    `json:"btc_eth_leverage"`
 	AltcoinLeverage      int    `json:"altcoin_leverage"`
@@ -1005,6 +1121,29 @@ func (s *Server) handleUpdateTrader(c *gin.Context) {
 		strategyID = existingTrader.StrategyID
 	}
 
+	// Handle debate panel (if not provided, keep original value)
+	debatePanelID := existingTrader.DebatePanelID
+	if req.DebatePanelID != nil {
+		debatePanelID = *req.DebatePanelID
+		if debatePanelID != "" {
+			if err := debate.ValidatePanel(s.store.Debate(), debatePanelID, userID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+
+	// Handle failover chain (if not provided, keep original value)
+	fallbackIDs := existingTrader.FallbackModelIDs()
+	if req.FallbackAIModelIDs != nil {
+		fallbackIDs = *req.FallbackAIModelIDs
+	}
+	fallbackAIModelIDs, err := s.validateFallbackAIModels(userID, req.AIModelID, fallbackIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Update trader configuration
 	traderRecord := &store.Trader{
 		ID:                   traderID,
@@ -1023,6 +1162,8 @@ func (s *Server) handleUpdateTrader(c *gin.Context) {
 		IsCrossMargin:        isCrossMargin,
 		ShowInCompetition:    showInCompetition,
 		ScanIntervalMinutes:  scanIntervalMinutes,
+		DebatePanelID:        debatePanelID,
+		FallbackAIModelIDs:   fallbackAIModelIDs,
 		IsRunning:            existingTrader.IsRunning, // Keep original value
 	}
 
@@ -1044,6 +1185,7 @@ func (s *Server) handleUpdateTrader(c *gin.Context) {
 		SafeInternalError(c, "Failed to update trader", err)
 		return
 	}
+	setAuditChange(c, traderAuditSummary(existingTrader), traderAuditSummary(traderRecord))
 
 	// Remove old trader from memory first (this also stops if running)
 	s.traderManager.RemoveTrader(traderID)
@@ -1100,6 +1242,9 @@ func (s *Server) handleDeleteTrader(c *gin.Context) {
 	// Remove trader from memory
 	s.traderManager.RemoveTrader(traderID)
 
+	// Drop the trader's metric series so dashboards stop showing it
+	metrics.RemoveTrader(traderID)
+
 	// Unregister trader from reflection scheduling to avoid processing deleted traders
 	if s.reflectionScheduler != nil {
 		s.reflectionScheduler.UnregisterTrader(traderID)
@@ -1194,6 +1339,7 @@ func (s *Server) handleStartTrader(c *gin.Context) {
 		logger.Infof("⚠️  Failed to update trader status: %v", err)
 	}
 
+	setAuditChange(c, gin.H{"is_running": false}, gin.H{"is_running": true})
 	logger.Infof("✓ Trader %s started", trader.GetName())
 	c.JSON(http.StatusOK, gin.H{"message": "Trader started"})
 }
@@ -1232,6 +1378,7 @@ func (s *Server) handleStopTrader(c *gin.Context) {
 		logger.Infof("⚠️  Failed to update trader status: %v", err)
 	}
 
+	setAuditChange(c, gin.H{"is_running": true}, gin.H{"is_running": false})
 	logger.Infof("⏹  Trader %s stopped", trader.GetName())
 	c.JSON(http.StatusOK, gin.H{"message": "Trader stopped"})
 }
@@ -1668,6 +1815,9 @@ func (s *Server) handleClosePosition(c *gin.Context) {
 	}
 
 	logger.Infof("✅ Position closed successfully: symbol=%s, side=%s, qty=%.6f, result=%v", req.Symbol, req.Side, posQty, result)
+	setAuditChange(c,
+		gin.H{"symbol": req.Symbol, "side": req.Side, "quantity": posQty, "entry_price": entryPrice},
+		gin.H{"symbol": req.Symbol, "side": req.Side, "quantity": 0, "order_id": result["orderId"], "status": result["status"]})
 
 	// Record order to database (for chart markers and history)
 	s.recordClosePositionOrder(traderID, exchangeCfg.ID, exchangeCfg.ExchangeType, req.Symbol, req.Side, posQty, entryPrice, result)
@@ -1923,6 +2073,8 @@ func (s *Server) handleGetModelConfigs(c *gin.Context) {
 			{ID: "gemini", Name: "Gemini AI", Provider: "gemini", Enabled: false},
 			{ID: "grok", Name: "Grok AI", Provider: "grok", Enabled: false},
 			{ID: "kimi", Name: "Kimi AI", Provider: "kimi", Enabled: false},
+			{ID: "ollama", Name: "Ollama (Local)", Provider: "ollama", Enabled: false},
+			{ID: "local", Name: "Local OpenAI-Compatible", Provider: "local", Enabled: false},
 		}
 		c.JSON(http.StatusOK, defaultModels)
 		return
@@ -2006,14 +2158,32 @@ func (s *Server) handleUpdateModelConfigs(c *gin.Context) {
 		logger.Infof("🔓 Decrypted model config data (UserID: %s)", userID)
 	}
 
+	// Audit summaries name changed credentials but never record them
+	auditBefore, auditAfter := gin.H{}, gin.H{}
+	if existing, err := s.store.AIModel().List(userID); err == nil {
+		for _, m := range existing {
+			if _, ok := req.Models[m.ID]; ok {
+				auditBefore[m.ID] = gin.H{"enabled": m.Enabled, "custom_api_url": m.CustomAPIURL, "custom_model_name": m.CustomModelName}
+			}
+		}
+	}
+
 	// Update each model's configuration
 	for modelID, modelData := range req.Models {
 		err := s.store.AIModel().Update(userID, modelID, modelData.Enabled, modelData.APIKey, modelData.CustomAPIURL, modelData.CustomModelName)
 		if err != nil {
+			setAuditChange(c, auditBefore, auditAfter)
 			SafeInternalError(c, fmt.Sprintf("Update model %s", modelID), err)
 			return
 		}
+		auditAfter[modelID] = gin.H{
+			"enabled":             modelData.Enabled,
+			"custom_api_url":      modelData.CustomAPIURL,
+			"custom_model_name":   modelData.CustomModelName,
+			"updated_credentials": changedSecrets(map[string]string{"api_key": modelData.APIKey}),
+		}
 	}
+	setAuditChange(c, auditBefore, auditAfter)
 
 	// Reload all traders for this user to make new config take effect immediately
 	err = s.traderManager.LoadUserTradersFromStore(s.store, userID)
@@ -2126,14 +2296,43 @@ func (s *Server) handleUpdateExchangeConfigs(c *gin.Context) {
 		logger.Infof("🔓 Decrypted exchange config data (UserID: %s)", userID)
 	}
 
+	// Audit summaries name changed credentials but never record them
+	auditBefore, auditAfter := gin.H{}, gin.H{}
+	if existing, err := s.store.Exchange().List(userID); err == nil {
+		for _, e := range existing {
+			if _, ok := req.Exchanges[e.ID]; ok {
+				auditBefore[e.ID] = exchangeAuditSummary(e)
+			}
+		}
+	}
+
 	// Update each exchange's configuration
 	for exchangeID, exchangeData := range req.Exchanges {
 		err := s.store.Exchange().Update(userID, exchangeID, exchangeData.Enabled, exchangeData.APIKey, exchangeData.SecretKey, exchangeData.Passphrase, exchangeData.Testnet, exchangeData.HyperliquidWalletAddr, exchangeData.AsterUser, exchangeData.AsterSigner, exchangeData.AsterPrivateKey, exchangeData.LighterWalletAddr, exchangeData.LighterPrivateKey, exchangeData.LighterAPIKeyPrivateKey, exchangeData.LighterAPIKeyIndex)
 		if err != nil {
+			setAuditChange(c, auditBefore, auditAfter)
 			SafeInternalError(c, fmt.Sprintf("Update exchange %s", exchangeID), err)
 			return
 		}
+		auditAfter[exchangeID] = gin.H{
+			"enabled":                 exchangeData.Enabled,
+			"testnet":                 exchangeData.Testnet,
+			"hyperliquid_wallet_addr": exchangeData.HyperliquidWalletAddr,
+			"aster_user":              exchangeData.AsterUser,
+			"aster_signer":            exchangeData.AsterSigner,
+			"lighter_wallet_addr":     exchangeData.LighterWalletAddr,
+			"lighter_api_key_index":   exchangeData.LighterAPIKeyIndex,
+			"updated_credentials": changedSecrets(map[string]string{
+				"api_key":                     exchangeData.APIKey,
+				"secret_key":                  exchangeData.SecretKey,
+				"passphrase":                  exchangeData.Passphrase,
+				"aster_private_key":           exchangeData.AsterPrivateKey,
+				"lighter_private_key":         exchangeData.LighterPrivateKey,
+				"lighter_api_key_private_key": exchangeData.LighterAPIKeyPrivateKey,
+			}),
+		}
 	}
+	setAuditChange(c, auditBefore, auditAfter)
 
 	// Reload all traders for this user to make new config take effect immediately
 	err = s.traderManager.LoadUserTradersFromStore(s.store, userID)
@@ -2239,6 +2438,8 @@ func (s *Server) handleCreateExchange(c *gin.Context) {
 		return
 	}
 
+	setAuditTarget(c, id)
+	setAuditChange(c, nil, gin.H{"exchange_type": req.ExchangeType, "account_name": req.AccountName, "enabled": req.Enabled, "testnet": req.Testnet})
 	logger.Infof("✓ Created exchange account: type=%s, name=%s, id=%s", req.ExchangeType, req.AccountName, id)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Exchange account created",
@@ -2317,15 +2518,17 @@ func (s *Server) handleTraderList(c *gin.Context) {
 		// Return complete AIModelID (e.g. "admin_deepseek"), don't truncate
 		// Frontend needs complete ID to verify model exists (consistent with handleGetTraderConfig)
 		result = append(result, map[string]interface{}{
-			"trader_id":           trader.ID,
-			"trader_name":         trader.Name,
-			"ai_model":            trader.AIModelID, // Use complete ID
-			"exchange_id":         trader.ExchangeID,
-			"is_running":          isRunning,
-			"show_in_competition": trader.ShowInCompetition,
-			"initial_balance":     trader.InitialBalance,
-			"strategy_id":         trader.StrategyID,
-			"strategy_name":       strategyName,
+			"trader_id":             trader.ID,
+			"trader_name":           trader.Name,
+			"ai_model":              trader.AIModelID, // Use complete ID
+			"exchange_id":           trader.ExchangeID,
+			"is_running":            isRunning,
+			"show_in_competition":   trader.ShowInCompetition,
+			"initial_balance":       trader.InitialBalance,
+			"strategy_id":           trader.StrategyID,
+			"strategy_name":         strategyName,
+			"debate_panel_id":       trader.DebatePanelID,
+			"fallback_ai_model_ids": trader.FallbackModelIDs(),
 		})
 	}
 
@@ -2377,6 +2580,8 @@ func (s *Server) handleGetTraderConfig(c *gin.Context) {
 		"is_cross_margin":       traderConfig.IsCrossMargin,
 		"use_ai500":             traderConfig.UseAI500,
 		"use_oi_top":            traderConfig.UseOITop,
+		"debate_panel_id":       traderConfig.DebatePanelID,
+		"fallback_ai_model_ids": traderConfig.FallbackModelIDs(),
 		"is_running":            isRunning,
 	}
 
@@ -3373,6 +3578,14 @@ func (s *Server) authMiddleware() gin.HandlerFunc {
 
 		tokenString := tokenParts[1]
 
+		// Personal API token (long-lived, scoped)
+		if auth.IsAPIToken(tokenString) {
+			if s.authenticateAPIToken(c, tokenString) {
+				c.Next()
+			}
+			return
+		}
+
 		// Blacklist check
 		if auth.IsTokenBlacklisted(tokenString) {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token expired, please login again"})
@@ -3729,11 +3942,53 @@ func (s *Server) handleGetSupportedModels(c *gin.Context) {
 		{"id": "gemini", "name": "Google Gemini", "provider": "gemini", "defaultModel": "gemini-3-pro-preview"},
 		{"id": "grok", "name": "Grok (xAI)", "provider": "grok", "defaultModel": "grok-3-latest"},
 		{"id": "kimi", "name": "Kimi (Moonshot)", "provider": "kimi", "defaultModel": "moonshot-v1-auto"},
+		{"id": "ollama", "name": "Ollama (Local)", "provider": "ollama", "defaultModel": "llama3.1:8b", "defaultURL": "http://localhost:11434", "apiKeyOptional": true},
+		{"id": "local", "name": "Local OpenAI-Compatible (llama.cpp, vLLM)", "provider": "local", "defaultModel": "", "defaultURL": "http://localhost:8000/v1", "apiKeyOptional": true},
 	}
 
 	c.JSON(http.StatusOK, supportedModels)
 }
 
+// handleListLocalModels List the models served by a local Ollama or OpenAI-compatible server
+// Query: model_id (one of the caller's saved ollama/local models, whose URL is queried) or provider
+// (ollama | local, queries the provider's default local address). Arbitrary URLs are not accepted so the
+// endpoint cannot be used to probe other hosts.
+func (s *Server) handleListLocalModels(c *gin.Context) {
+	userID := c.GetString("user_id")
+	provider := c.Query("provider")
+	baseURL := ""
+	if modelID := strings.TrimSpace(c.Query("model_id")); modelID != "" {
+		model, err := s.store.AIModel().Get(userID, modelID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "AI model not found"})
+			return
+		}
+		provider = model.Provider
+		baseURL = strings.TrimSpace(model.CustomAPIURL)
+	}
+
+	var client *mcp.LocalClient
+	switch provider {
+	case mcp.ProviderOllama:
+		client = mcp.NewOllamaClient().(*mcp.LocalClient)
+	case mcp.ProviderLocal:
+		client = mcp.NewLocalClient().(*mcp.LocalClient)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider must be ollama or local"})
+		return
+	}
+	if baseURL != "" {
+		client.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+
+	models, err := client.ListModels()
+	if err != nil {
+		SafeError(c, http.StatusBadGateway, "Failed to list local models", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"base_url": client.BaseURL, "models": models})
+}
+
 // handleGetSupportedExchanges Get list of exchanges supported by the system
 func (s *Server) handleGetSupportedExchanges(c *gin.Context) {
 	// Return static list of supported exchange types
@@ -3771,6 +4026,7 @@ func (s *Server) Start() error {
 	logger.Infof("  • POST /api/traders/:id/stop  - Stop AI trader")
 	logger.Infof("  • GET  /api/models           - Get AI model config")
 	logger.Infof("  • PUT  /api/models           - Update AI model config")
+	logger.Infof("  • GET  /api/models/local     - List models of a local LLM server")
 	logger.Infof("  • GET  /api/exchanges        - Get exchange config")
 	logger.Infof("  • PUT  /api/exchanges        - Update exchange config")
 	logger.Infof("  • GET  /api/status?trader_id=xxx     - Specified trader's system status")
@@ -26,6 +26,12 @@ func validateStrategyConfig(config *store.StrategyConfig) []string {
 		warnings = append(warnings, "NofxOS API key is not configured. NofxOS data sources may not work properly.")
 	}
 
+	// Validate CoinAnk API key if any derivatives section is enabled
+	if (config.Indicators.EnableLiquidationData || config.Indicators.EnableLongShortRatio ||
+		config.Indicators.EnableOIVsMarketCap) && config.Indicators.CoinankAPIKey == "" {
+		warnings = append(warnings, "CoinAnk API key is not configured. Liquidation, long/short and OI/market cap data will be skipped.")
+	}
+
 	return warnings
 }
 
@@ -155,6 +161,14 @@ func (s *Server) handleCreateStrategy(c *gin.Context) {
 		return
 	}
 
+	// Snapshot the selected prompt template version
+	promptTemplate, err := s.resolvePromptTemplate(userID, req.Config.PromptTemplate)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	req.Config.PromptTemplate = promptTemplate
+
 	// Serialize configuration
 	configJSON, err := json.Marshal(req.Config)
 	if err != nil {
@@ -176,6 +190,8 @@ func (s *Server) handleCreateStrategy(c *gin.Context) {
 		SafeInternalError(c, "Failed to create strategy", err)
 		return
 	}
+	setAuditTarget(c, strategy.ID)
+	setAuditChange(c, nil, strategyAuditSummary(strategy))
 
 	// Validate configuration and collect warnings
 	warnings := validateStrategyConfig(&req.Config)
@@ -251,6 +267,14 @@ func (s *Server) handleUpdateStrategy(c *gin.Context) {
 		logger.Infof("✅ TriggerPriceConfig validation: OK")
 	}
 
+	// Snapshot the selected prompt template version
+	promptTemplate, err := s.resolvePromptTemplate(userID, req.Config.PromptTemplate)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	req.Config.PromptTemplate = promptTemplate
+
 	// Serialize configuration
 	configJSON, err := json.Marshal(req.Config)
 	if err != nil {
@@ -275,6 +299,7 @@ func (s *Server) handleUpdateStrategy(c *gin.Context) {
 		SafeInternalError(c, "Failed to update strategy", err)
 		return
 	}
+	setAuditChange(c, strategyAuditSummary(existing), strategyAuditSummary(strategy))
 
 	logger.Infof("✅ Strategy updated successfully in database")
 
@@ -405,9 +430,11 @@ func (s *Server) handlePreviewPrompt(c *gin.Context) {
 	}
 
 	var req struct {
-		Config        store.StrategyConfig `json:"config" binding:"required"`
-		AccountEquity float64              `json:"account_equity"`
-		PromptVariant string               `json:"prompt_variant"`
+		Config          store.StrategyConfig `json:"config" binding:"required"`
+		AccountEquity   float64              `json:"account_equity"`
+		PromptVariant   string               `json:"prompt_variant"`
+		TemplateID      string               `json:"template_id"`      // Preview a library template instead of the config's
+		TemplateVersion int                  `json:"template_version"` // Version of template_id, 0 for latest
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -422,6 +449,15 @@ func (s *Server) handlePreviewPrompt(c *gin.Context) {
 	if req.PromptVariant == "" {
 		req.PromptVariant = "balanced"
 	}
+	if req.TemplateID != "" {
+		req.Config.PromptTemplate = &store.PromptTemplateRef{ID: req.TemplateID, Version: req.TemplateVersion}
+	}
+	promptTemplate, err := s.resolvePromptTemplate(userID, req.Config.PromptTemplate)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+	req.Config.PromptTemplate = promptTemplate
 
 	// Create strategy engine to build prompt
 	engine := kernel.NewStrategyEngine(&req.Config)
@@ -431,9 +467,12 @@ func (s *Server) handlePreviewPrompt(c *gin.Context) {
 		req.AccountEquity,
 		req.PromptVariant,
 	)
+	// Build user prompt over sample market data
+	userPrompt := engine.BuildUserPrompt(kernel.PreviewContext(req.AccountEquity))
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"system_prompt":  systemPrompt,
+		"user_prompt":    userPrompt,
 		"prompt_variant": req.PromptVariant,
 		"config_summary": gin.H{
 			"coin_source":      req.Config.CoinSource.SourceType,
@@ -442,7 +481,15 @@ func (s *Server) handlePreviewPrompt(c *gin.Context) {
 			"altcoin_leverage": req.Config.RiskControl.AltcoinMaxLeverage,
 			"max_positions":    req.Config.RiskControl.MaxPositions,
 		},
-	})
+	}
+	if promptTemplate != nil {
+		response["prompt_template"] = gin.H{
+			"id":      promptTemplate.ID,
+			"name":    promptTemplate.Name,
+			"version": promptTemplate.Version,
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // handleStrategyTestRun AI test run (does not execute trades, only returns AI analysis results)
@@ -537,6 +584,15 @@ func (s *Server) handleStrategyTestRun(c *gin.Context) {
 	// Fetch Price ranking data (market-wide gainers/losers)
 	priceRankingData := engine.FetchPriceRankingData()
 
+	// Fetch derivatives data (liquidations, long/short ratio, OI vs market cap)
+	derivativesDataMap := engine.FetchDerivativesDataBatch(symbols)
+
+	// Fetch order book depth (spread, ±1% depth, imbalance)
+	orderBookMap := engine.FetchOrderBookBatch(symbols)
+
+	// Trading sessions of stock/forex/commodity symbols
+	marketSessions := engine.BuildMarketSessions(symbols, time.Now())
+
 	// Build real context (for generating User Prompt)
 	testContext := &kernel.Context{
 		CurrentTime:    time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
@@ -560,6 +616,9 @@ func (s *Server) handleStrategyTestRun(c *gin.Context) {
 		OIRankingData:      oiRankingData,
 		NetFlowRankingData: netFlowRankingData,
 		PriceRankingData:   priceRankingData,
+		DerivativesDataMap: derivativesDataMap,
+		OrderBookMap:       orderBookMap,
+		MarketSessions:     marketSessions,
 	}
 
 	// Build System Prompt
@@ -621,7 +680,7 @@ func (s *Server) runRealAITest(userID, modelID, systemPrompt, userPrompt string)
 		return "", fmt.Errorf("AI model %s is not enabled", model.Name)
 	}
 
-	if model.APIKey == "" {
+	if model.APIKey == "" && mcp.ProviderRequiresAPIKey(model.Provider) {
 		return "", fmt.Errorf("AI model %s is missing API Key", model.Name)
 	}
 
@@ -653,6 +712,12 @@ func (s *Server) runRealAITest(userID, modelID, systemPrompt, userPrompt string)
 	case "openai":
 		aiClient = mcp.NewOpenAIClient()
 		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
+	case "ollama":
+		aiClient = mcp.NewOllamaClient()
+		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
+	case "local":
+		aiClient = mcp.NewLocalClient()
+		aiClient.SetAPIKey(apiKey, model.CustomAPIURL, model.CustomModelName)
 	default:
 		// Use generic client
 		aiClient = mcp.NewClient()
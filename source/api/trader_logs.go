@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"nofx/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseLogFilter reads level, symbol, cycle, q, since and limit query parameters
+func parseLogFilter(c *gin.Context) (logger.LogFilter, error) {
+	filter := logger.LogFilter{
+		Level:  c.Query("level"),
+		Symbol: c.Query("symbol"),
+		Query:  c.Query("q"),
+	}
+	if v := c.Query("cycle"); v != "" {
+		cycle, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || cycle < 0 {
+			return filter, fmt.Errorf("invalid cycle")
+		}
+		filter.Cycle = cycle
+	}
+	if v := c.Query("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid since, expected RFC3339")
+		}
+		filter.Since = since
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			return filter, fmt.Errorf("invalid limit")
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+// handleGetTraderLogs Recent log lines of a trader, filtered by level/symbol/cycle/text
+func (s *Server) handleGetTraderLogs(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	// Verify trader belongs to current user
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+
+	lines, err := logger.QueryTraderLogs(traderID, filter)
+	if err != nil {
+		SafeInternalError(c, "Failed to read trader logs", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"trader_id": traderID,
+		"lines":     lines,
+	})
+}
+
+// handleStreamTraderLogs Streams new log lines of a trader as server-sent events;
+// the recent lines matching the filter are sent first
+func (s *Server) handleStreamTraderLogs(c *gin.Context) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	// Verify trader belongs to current user
+	if _, err := s.store.Trader().GetFullConfig(userID, traderID); err != nil {
+		SafeNotFound(c, "Trader")
+		return
+	}
+
+	filter, err := parseLogFilter(c)
+	if err != nil {
+		SafeBadRequest(c, err.Error())
+		return
+	}
+
+	// Subscribe before reading history so no line falls between the two
+	ch, cancel := logger.SubscribeTraderLogs(traderID)
+	defer cancel()
+
+	recent, err := logger.QueryTraderLogs(traderID, filter)
+	if err != nil {
+		SafeInternalError(c, "Failed to read trader logs", err)
+		return
+	}
+
+	// Set SSE headers
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("Transfer-Encoding", "chunked")
+
+	initialData, _ := json.Marshal(recent)
+	c.Writer.Write([]byte(fmt.Sprintf("event: initial\ndata: %s\n\n", initialData)))
+	c.Writer.Flush()
+
+	var last time.Time
+	if len(recent) > 0 {
+		last = recent[len(recent)-1].Time
+	}
+
+	// Stream updates; keep-alive comments stop proxies from closing an idle stream
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	clientGone := c.Request.Context().Done()
+	for {
+		select {
+		case <-clientGone:
+			return
+		case <-keepAlive.C:
+			c.Writer.Write([]byte(": keep-alive\n\n"))
+			c.Writer.Flush()
+		case line := <-ch:
+			// Skip lines already sent with the initial batch
+			if !line.Time.After(last) || !filter.Match(&line) {
+				continue
+			}
+			data, _ := json.Marshal(line)
+			c.Writer.Write([]byte(fmt.Sprintf("event: log\ndata: %s\n\n", data)))
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"nofx/logger"
+	"nofx/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WorkspaceHeader selects the workspace a request acts in, personal resources are used without it
+const WorkspaceHeader = "X-Workspace-ID"
+
+// Workspace permissions
+const (
+	PermView        = "view"        // View traders, positions, decisions, statistics, backtests and debates
+	PermTrade       = "trade"       // Create/start/stop traders, edit strategies, close positions, run debates
+	PermBacktest    = "backtest"    // Run and manage backtests
+	PermCredentials = "credentials" // Change exchange accounts and AI model keys
+	PermMembers     = "members"     // Manage workspace membership
+)
+
+// rolePermissions permissions granted by each workspace role
+var rolePermissions = map[string][]string{
+	store.WorkspaceRoleOwner:   {PermView, PermTrade, PermBacktest, PermCredentials, PermMembers},
+	store.WorkspaceRoleTrader:  {PermView, PermTrade, PermBacktest},
+	store.WorkspaceRoleAnalyst: {PermView, PermBacktest},
+}
+
+// readOnlyPostRoutes POST routes that do not change any state
+var readOnlyPostRoutes = map[string]bool{
+	"/strategies/preview-prompt": true,
+}
+
+// roleAllows checks whether a workspace role grants a permission
+func roleAllows(role, perm string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredWorkspacePermission returns the permission a workspace member needs for a route
+func requiredWorkspacePermission(method, path string) string {
+	path = strings.TrimPrefix(path, "/api")
+	switch {
+	case strings.HasPrefix(path, "/models") || strings.HasPrefix(path, "/exchanges"):
+		if method == http.MethodGet {
+			return PermView
+		}
+		return PermCredentials
+	case strings.HasPrefix(path, "/backtest"):
+		if method == http.MethodGet {
+			return PermView
+		}
+		return PermBacktest
+	case method == http.MethodGet || readOnlyPostRoutes[path]:
+		return PermView
+	}
+	return PermTrade
+}
+
+// workspaceMiddleware switches the request to the selected workspace and enforces the member's role
+// The workspace ID replaces user_id so handlers operate on the workspace's resources,
+// the authenticated user stays available as actor_user_id
+func (s *Server) workspaceMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetString("user_id")
+		c.Set("actor_user_id", userID)
+
+		workspaceID := c.GetHeader(WorkspaceHeader)
+		if workspaceID == "" {
+			// EventSource streams can not set headers
+			workspaceID = c.Query("workspace_id")
+		}
+		path := strings.TrimPrefix(c.FullPath(), "/api")
+		// Workspace, token and instance management always act as the authenticated user
+		if workspaceID == "" || strings.HasPrefix(path, "/workspaces") || strings.HasPrefix(path, "/api-tokens") ||
+			strings.HasPrefix(path, "/encryption") || strings.HasPrefix(path, "/backups") {
+			c.Next()
+			return
+		}
+
+		member, err := s.store.Workspace().GetMember(workspaceID, userID)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Not a member of this workspace"})
+			c.Abort()
+			return
+		}
+
+		required := requiredWorkspacePermission(c.Request.Method, c.FullPath())
+		if !roleAllows(member.Role, required) {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Workspace role '%s' lacks the '%s' permission", member.Role, required)})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", workspaceID)
+		c.Set("workspace_id", workspaceID)
+		c.Set("workspace_role", member.Role)
+		c.Next()
+	}
+}
+
+// requireWorkspaceMember loads the caller's membership of the workspace in the path and checks a permission
+func (s *Server) requireWorkspaceMember(c *gin.Context, perm string) (*store.WorkspaceMember, bool) {
+	workspaceID := c.Param("id")
+	member, err := s.store.Workspace().GetMember(workspaceID, c.GetString("user_id"))
+	if err != nil {
+		SafeNotFound(c, "Workspace")
+		return nil, false
+	}
+	if perm != "" && !roleAllows(member.Role, perm) {
+		SafeForbidden(c, "Only workspace owners can manage members")
+		return nil, false
+	}
+	setAuditOwner(c, workspaceID)
+	return member, true
+}
+
+// handleListWorkspaces List workspaces of the current user
+func (s *Server) handleListWorkspaces(c *gin.Context) {
+	workspaces, err := s.store.Workspace().ListForUser(c.GetString("user_id"))
+	if err != nil {
+		SafeInternalError(c, "Failed to get workspaces", err)
+		return
+	}
+	if workspaces == nil {
+		workspaces = []*store.WorkspaceWithRole{}
+	}
+	c.JSON(http.StatusOK, workspaces)
+}
+
+// handleCreateWorkspace Create a workspace, the creator becomes its owner
+func (s *Server) handleCreateWorkspace(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+
+	ws := &store.Workspace{
+		ID:        "ws_" + uuid.New().String(),
+		Name:      req.Name,
+		CreatedBy: userID,
+	}
+	if err := s.store.Workspace().Create(ws); err != nil {
+		SafeInternalError(c, "Failed to create workspace", err)
+		return
+	}
+
+	setAuditTarget(c, ws.ID)
+	setAuditChange(c, nil, gin.H{"name": ws.Name})
+	logger.Infof("👥 Workspace created: %s (%s) by user %s", ws.Name, ws.ID, userID)
+	c.JSON(http.StatusOK, store.WorkspaceWithRole{Workspace: *ws, Role: store.WorkspaceRoleOwner})
+}
+
+// handleListWorkspaceMembers List members of a workspace
+func (s *Server) handleListWorkspaceMembers(c *gin.Context) {
+	if _, ok := s.requireWorkspaceMember(c, PermView); !ok {
+		return
+	}
+	members, err := s.store.Workspace().ListMembers(c.Param("id"))
+	if err != nil {
+		SafeInternalError(c, "Failed to get workspace members", err)
+		return
+	}
+	for _, m := range members {
+		m.Email = MaskEmail(m.Email)
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// handleAddWorkspaceMember Add a registered user to a workspace by email
+func (s *Server) handleAddWorkspaceMember(c *gin.Context) {
+	if _, ok := s.requireWorkspaceMember(c, PermMembers); !ok {
+		return
+	}
+
+	var req struct {
+		Email string `json:"email" binding:"required"`
+		Role  string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if !store.IsValidWorkspaceRole(req.Role) {
+		SafeBadRequest(c, fmt.Sprintf("Invalid role (valid: %s)", strings.Join(store.WorkspaceRoles, ", ")))
+		return
+	}
+
+	user, err := s.store.User().GetByEmail(req.Email)
+	if err != nil {
+		SafeNotFound(c, "User")
+		return
+	}
+	if _, err := s.store.Workspace().GetMember(c.Param("id"), user.ID); err == nil {
+		SafeBadRequest(c, "User is already a member of this workspace")
+		return
+	}
+	if err := s.store.Workspace().AddMember(c.Param("id"), user.ID, req.Role); err != nil {
+		SafeInternalError(c, "Failed to add workspace member", err)
+		return
+	}
+
+	setAuditTarget(c, user.ID)
+	setAuditChange(c, nil, gin.H{"role": req.Role})
+	logger.Infof("👥 Workspace %s: added user %s as %s", c.Param("id"), user.ID, req.Role)
+	c.JSON(http.StatusOK, gin.H{"message": "Member added", "user_id": user.ID, "role": req.Role})
+}
+
+// handleUpdateWorkspaceMember Change the role of a workspace member
+func (s *Server) handleUpdateWorkspaceMember(c *gin.Context) {
+	if _, ok := s.requireWorkspaceMember(c, PermMembers); !ok {
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SafeBadRequest(c, "Invalid request parameters")
+		return
+	}
+	if !store.IsValidWorkspaceRole(req.Role) {
+		SafeBadRequest(c, fmt.Sprintf("Invalid role (valid: %s)", strings.Join(store.WorkspaceRoles, ", ")))
+		return
+	}
+
+	workspaceID, memberID := c.Param("id"), c.Param("userId")
+	target, err := s.store.Workspace().GetMember(workspaceID, memberID)
+	if err != nil {
+		SafeNotFound(c, "Workspace member")
+		return
+	}
+	if target.Role == store.WorkspaceRoleOwner && req.Role != store.WorkspaceRoleOwner && !s.hasOtherOwner(c, workspaceID) {
+		return
+	}
+	if err := s.store.Workspace().UpdateMemberRole(workspaceID, memberID, req.Role); err != nil {
+		SafeInternalError(c, "Failed to update workspace member", err)
+		return
+	}
+	setAuditChange(c, gin.H{"role": target.Role}, gin.H{"role": req.Role})
+	c.JSON(http.StatusOK, gin.H{"message": "Member role updated", "role": req.Role})
+}
+
+// handleRemoveWorkspaceMember Remove a member from a workspace, members may remove themselves
+func (s *Server) handleRemoveWorkspaceMember(c *gin.Context) {
+	workspaceID, memberID := c.Param("id"), c.Param("userId")
+	perm := PermMembers
+	if memberID == c.GetString("user_id") {
+		perm = ""
+	}
+	if _, ok := s.requireWorkspaceMember(c, perm); !ok {
+		return
+	}
+
+	target, err := s.store.Workspace().GetMember(workspaceID, memberID)
+	if err != nil {
+		SafeNotFound(c, "Workspace member")
+		return
+	}
+	if target.Role == store.WorkspaceRoleOwner && !s.hasOtherOwner(c, workspaceID) {
+		return
+	}
+	if err := s.store.Workspace().RemoveMember(workspaceID, memberID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			SafeNotFound(c, "Workspace member")
+			return
+		}
+		SafeInternalError(c, "Failed to remove workspace member", err)
+		return
+	}
+	setAuditChange(c, gin.H{"role": target.Role}, nil)
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// hasOtherOwner rejects the request when the workspace would be left without an owner
+func (s *Server) hasOtherOwner(c *gin.Context, workspaceID string) bool {
+	owners, err := s.store.Workspace().CountOwners(workspaceID)
+	if err != nil {
+		SafeInternalError(c, "Failed to count workspace owners", err)
+		return false
+	}
+	if owners <= 1 {
+		SafeBadRequest(c, "A workspace must keep at least one owner")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"testing"
+
+	"nofx/store"
+)
+
+// TestWorkspaceRolePermissions Test what each role may do on representative routes
+func TestWorkspaceRolePermissions(t *testing.T) {
+	tests := []struct {
+		method  string
+		path    string
+		owner   bool
+		trader  bool
+		analyst bool
+	}{
+		{"GET", "/api/decisions", true, true, true},
+		{"GET", "/api/exchanges", true, true, true},
+		{"POST", "/api/backtest/start", true, true, true},
+		{"POST", "/api/strategies/preview-prompt", true, true, true},
+		{"POST", "/api/traders/:id/start", true, true, false},
+		{"POST", "/api/debates", true, true, false},
+		{"PUT", "/api/strategies/:id", true, true, false},
+		{"PUT", "/api/exchanges", true, false, false},
+		{"PUT", "/api/models", true, false, false},
+	}
+	for _, tt := range tests {
+		perm := requiredWorkspacePermission(tt.method, tt.path)
+		for role, want := range map[string]bool{
+			store.WorkspaceRoleOwner:   tt.owner,
+			store.WorkspaceRoleTrader:  tt.trader,
+			store.WorkspaceRoleAnalyst: tt.analyst,
+		} {
+			if got := roleAllows(role, perm); got != want {
+				t.Errorf("%s %s as %s: expected %v, got %v (permission %s)", tt.method, tt.path, role, want, got, perm)
+			}
+		}
+	}
+
+	if roleAllows("guest", PermView) {
+		t.Error("unknown role should have no permissions")
+	}
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APITokenPrefix marks personal API tokens so they can be told apart from JWTs
+const APITokenPrefix = "nofx_"
+
+// API token scopes
+const (
+	ScopeRead     = "read"     // Read-only access to traders, positions, decisions and statistics
+	ScopeTrade    = "trade"    // Trade control: create/start/stop traders, strategies, close positions
+	ScopeBacktest = "backtest" // Run and manage backtests
+	ScopeAdmin    = "admin"    // Everything, including model/exchange credentials and token management
+)
+
+// ValidScopes all supported API token scopes
+var ValidScopes = []string{ScopeRead, ScopeTrade, ScopeBacktest, ScopeAdmin}
+
+// DefaultAPITokenRateLimit requests per minute when a token has no explicit limit
+const DefaultAPITokenRateLimit = 60
+
+// GenerateAPIToken generates a new API token, returns the plaintext token (shown once) and its hash
+func GenerateAPIToken() (token string, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken hashes an API token for storage and lookup
+// Tokens are high-entropy random strings, so a plain SHA-256 is sufficient (no salt/bcrypt needed)
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken checks whether a bearer token is a personal API token
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
+
+// NormalizeScopes validates and deduplicates scopes
+func NormalizeScopes(scopes []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || seen[scope] {
+			continue
+		}
+		valid := false
+		for _, v := range ValidScopes {
+			if scope == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("invalid scope: %s (valid: %s)", scope, strings.Join(ValidScopes, ", "))
+		}
+		seen[scope] = true
+		result = append(result, scope)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return result, nil
+}
+
+// HasScope checks whether granted scopes allow the required scope
+// admin allows everything, any scope allows read
+func HasScope(granted []string, required string) bool {
+	for _, scope := range granted {
+		if scope == ScopeAdmin || scope == required || required == ScopeRead {
+			return true
+		}
+	}
+	return false
+}
+
+// apiTokenWindows fixed one-minute request windows per token (memory only)
+var apiTokenWindows = struct {
+	sync.Mutex
+	items map[string]*rateWindow
+}{items: make(map[string]*rateWindow)}
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// AllowAPITokenRequest counts a request of a token against its per-minute limit,
+// returns false and the time until the window resets when the limit is exceeded
+func AllowAPITokenRequest(tokenID string, limitPerMinute int, now time.Time) (bool, time.Duration) {
+	if limitPerMinute <= 0 {
+		limitPerMinute = DefaultAPITokenRateLimit
+	}
+	apiTokenWindows.Lock()
+	defer apiTokenWindows.Unlock()
+
+	w, ok := apiTokenWindows.items[tokenID]
+	if !ok || now.Sub(w.start) >= time.Minute {
+		w = &rateWindow{start: now}
+		apiTokenWindows.items[tokenID] = w
+	}
+	if w.count >= limitPerMinute {
+		return false, w.start.Add(time.Minute).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
@@ -10,6 +10,25 @@ import (
 // configureMCPClient creates/clones an MCP client based on configuration (returns mcp.AIClient interface).
 // Note: mcp.New() returns an interface type; here we convert to concrete implementation before copying to avoid concurrent shared state.
 func configureMCPClient(cfg BacktestConfig, base mcp.AIClient) (mcp.AIClient, error) {
+	client, err := configureProviderClient(cfg, base)
+	if err != nil || len(cfg.FallbackAICfgs) == 0 {
+		return client, err
+	}
+
+	// Fallback models take over when the main model fails or is rate limited
+	chain := []mcp.AIClient{client}
+	for i, aiCfg := range cfg.FallbackAICfgs {
+		fallback, err := configureProviderClient(BacktestConfig{AICfg: aiCfg}, base)
+		if err != nil {
+			return nil, fmt.Errorf("fallback AI model %d: %w", i+1, err)
+		}
+		chain = append(chain, fallback)
+	}
+	return mcp.NewFailoverClient(chain...), nil
+}
+
+// configureProviderClient creates the client of a single AI model.
+func configureProviderClient(cfg BacktestConfig, base mcp.AIClient) (mcp.AIClient, error) {
 	provider := strings.ToLower(strings.TrimSpace(cfg.AICfg.Provider))
 
 	// DeepSeek
@@ -71,6 +90,14 @@ func configureMCPClient(cfg BacktestConfig, base mcp.AIClient) (mcp.AIClient, er
 		oaiC := mcp.NewOpenAIClientWithOptions()
 		oaiC.(*mcp.OpenAIClient).SetAPIKey(cfg.AICfg.APIKey, cfg.AICfg.BaseURL, cfg.AICfg.Model)
 		return oaiC, nil
+	case "ollama":
+		oc := mcp.NewOllamaClientWithOptions()
+		oc.(*mcp.LocalClient).SetAPIKey(cfg.AICfg.APIKey, cfg.AICfg.BaseURL, cfg.AICfg.Model)
+		return oc, nil
+	case "local":
+		lc := mcp.NewLocalClientWithOptions()
+		lc.(*mcp.LocalClient).SetAPIKey(cfg.AICfg.APIKey, cfg.AICfg.BaseURL, cfg.AICfg.Model)
+		return lc, nil
 	case "custom":
 		if cfg.AICfg.BaseURL == "" || cfg.AICfg.APIKey == "" || cfg.AICfg.Model == "" {
 			return nil, fmt.Errorf("custom provider requires base_url, api key and model")
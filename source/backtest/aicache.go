@@ -20,11 +20,18 @@ type cachedDecision struct {
 	Decision      *kernel.FullDecision `json:"decision"`
 }
 
+type cachedTurn struct {
+	Key       string `json:"key"`
+	Timestamp int64  `json:"ts"`
+	Response  string `json:"response"`
+}
+
 // AICache persists AI decisions for repeated backtesting or replay.
 type AICache struct {
 	mu      sync.RWMutex
 	path    string
 	Entries map[string]cachedDecision `json:"entries"`
+	Turns   map[string]cachedTurn     `json:"turns,omitempty"` // Raw debate turns, keyed by computeTurnKey
 }
 
 func LoadAICache(path string) (*AICache, error) {
@@ -39,6 +46,7 @@ func LoadAICache(path string) (*AICache, error) {
 	cache := &AICache{
 		path:    path,
 		Entries: make(map[string]cachedDecision),
+		Turns:   make(map[string]cachedTurn),
 	}
 
 	data, err := os.ReadFile(path)
@@ -57,6 +65,9 @@ func LoadAICache(path string) (*AICache, error) {
 	if cache.Entries == nil {
 		cache.Entries = make(map[string]cachedDecision)
 	}
+	if cache.Turns == nil {
+		cache.Turns = make(map[string]cachedTurn)
+	}
 	return cache, nil
 }
 
@@ -96,6 +107,28 @@ func (c *AICache) Put(key string, variant string, ts int64, decision *kernel.Ful
 	return c.save()
 }
 
+// GetTurn returns the cached response of one debate turn.
+func (c *AICache) GetTurn(key string) (string, bool) {
+	if c == nil || key == "" {
+		return "", false
+	}
+	c.mu.RLock()
+	entry, ok := c.Turns[key]
+	c.mu.RUnlock()
+	return entry.Response, ok
+}
+
+// PutTurn stores the response of one debate turn.
+func (c *AICache) PutTurn(key string, ts int64, response string) error {
+	if c == nil || key == "" {
+		return nil
+	}
+	c.mu.Lock()
+	c.Turns[key] = cachedTurn{Key: key, Timestamp: ts, Response: response}
+	c.mu.Unlock()
+	return c.save()
+}
+
 func (c *AICache) save() error {
 	if c == nil || c.path == "" {
 		return nil
@@ -166,3 +199,28 @@ func computeCacheKey(ctx *kernel.Context, variant string, ts int64) (string, err
 	sum := sha256.Sum256(bytes)
 	return hex.EncodeToString(sum[:]), nil
 }
+
+// computeTurnKey identifies one debate turn. The prompts already embed the bar's market data and the
+// earlier turns of the debate, so a replay hits the cache exactly when the whole debate so far matches.
+func computeTurnKey(participant DebateParticipantConfig, round int, ts int64, systemPrompt, userPrompt string) string {
+	payload := struct {
+		Timestamp    int64  `json:"ts"`
+		AIModelID    string `json:"ai_model_id"`
+		Model        string `json:"model"`
+		Personality  string `json:"personality"`
+		Round        int    `json:"round"`
+		SystemPrompt string `json:"system_prompt"`
+		UserPrompt   string `json:"user_prompt"`
+	}{
+		Timestamp:    ts,
+		AIModelID:    participant.AIModelID,
+		Model:        participant.AICfg.Model,
+		Personality:  string(participant.Personality),
+		Round:        round,
+		SystemPrompt: systemPrompt,
+		UserPrompt:   userPrompt,
+	}
+	bytes, _ := json.Marshal(payload)
+	sum := sha256.Sum256(bytes)
+	return hex.EncodeToString(sum[:])
+}
@@ -19,6 +19,36 @@ type AIConfig struct {
 	Temperature float64 `json:"temperature,omitempty"`
 }
 
+// DebateParticipantConfig one member of a debate panel; AICfg is resolved from AIModelID like the main AI config.
+// Custom personalities carry their prompt and risk bias (filled in from the user's saved personality).
+type DebateParticipantConfig struct {
+	AIModelID   string                  `json:"ai_model_id"`
+	Name        string                  `json:"name,omitempty"`
+	Personality store.DebatePersonality `json:"personality"`
+	Prompt      string                  `json:"prompt,omitempty"`
+	RiskBias    string                  `json:"risk_bias,omitempty"`
+	AICfg       AIConfig                `json:"ai"`
+}
+
+// DebateConfig replaces the single AI call of each decision bar with a multi-participant debate.
+type DebateConfig struct {
+	Participants []DebateParticipantConfig `json:"participants"`
+	Moderator    *DebateParticipantConfig  `json:"moderator,omitempty"` // Optional: decides instead of a participant vote
+	MaxRounds    int                       `json:"max_rounds"`
+	// WeightByAccuracy scales each vote by its participant's live debate accuracy, as debate sessions do.
+	// Weights is resolved once when the run is created, so resumed and replayed runs vote the same way.
+	WeightByAccuracy bool               `json:"weight_by_accuracy,omitempty"`
+	Weights          map[string]float64 `json:"weights,omitempty"`
+}
+
+// speakers returns every participant that needs an AI client: the panel, then the moderator if any.
+func (d *DebateConfig) speakers() []DebateParticipantConfig {
+	if d.Moderator == nil {
+		return d.Participants
+	}
+	return append(append([]DebateParticipantConfig{}, d.Participants...), *d.Moderator)
+}
+
 type LeverageConfig struct {
 	BTCETHLeverage  int `json:"btc_eth_leverage"`
 	AltcoinLeverage int `json:"altcoin_leverage"`
@@ -49,6 +79,12 @@ type BacktestConfig struct {
 
 	AICfg    AIConfig       `json:"ai"`
 	Leverage LeverageConfig `json:"leverage"`
+	Debate   *DebateConfig  `json:"debate,omitempty"` // Optional: decide each bar by debate instead of a single model
+
+	FallbackAIModelIDs []string   `json:"fallback_ai_model_ids,omitempty"` // Optional: AI models tried in order when the main model fails
+	FallbackAICfgs     []AIConfig `json:"fallback_ai,omitempty"`           // Resolved from FallbackAIModelIDs
+
+	PromptTemplateRef *store.PromptTemplateRef `json:"prompt_template_ref,omitempty"` // Optional: template from the prompt library, overrides the strategy's
 
 	SharedAICachePath         string `json:"ai_cache_path,omitempty"`
 	CheckpointIntervalBars    int    `json:"checkpoint_interval_bars,omitempty"`
@@ -153,9 +189,49 @@ func (cfg *BacktestConfig) Validate() error {
 		cfg.Leverage.AltcoinLeverage = 5
 	}
 
+	if cfg.Debate != nil && len(cfg.Debate.Participants) == 0 {
+		cfg.Debate = nil
+	}
+	if cfg.Debate != nil {
+		if len(cfg.Debate.Participants) < 2 {
+			return fmt.Errorf("debate requires at least 2 participants")
+		}
+		if cfg.Debate.MaxRounds <= 0 || cfg.Debate.MaxRounds > 5 {
+			cfg.Debate.MaxRounds = 3
+		}
+		for i := range cfg.Debate.Participants {
+			p := &cfg.Debate.Participants[i]
+			p.AIModelID = strings.TrimSpace(p.AIModelID)
+			if !store.IsBuiltinPersonality(p.Personality) && p.Prompt == "" {
+				return fmt.Errorf("invalid debate personality '%s'", p.Personality)
+			}
+			if p.AICfg.Provider == "" {
+				p.AICfg.Provider = "inherit"
+			}
+			if p.Name == "" {
+				p.Name = fmt.Sprintf("%s #%d", p.Personality, i+1)
+			}
+		}
+		if m := cfg.Debate.Moderator; m != nil {
+			m.AIModelID = strings.TrimSpace(m.AIModelID)
+			m.Personality = store.PersonalityModerator
+			if m.AICfg.Provider == "" {
+				m.AICfg.Provider = "inherit"
+			}
+			if m.Name == "" {
+				m.Name = "Moderator"
+			}
+		}
+	}
+
 	return nil
 }
 
+// DebateEnabled reports whether decision bars are decided by a debate panel.
+func (cfg *BacktestConfig) DebateEnabled() bool {
+	return cfg != nil && cfg.Debate != nil && len(cfg.Debate.Participants) >= 2
+}
+
 // Duration returns the backtest interval duration.
 func (cfg *BacktestConfig) Duration() time.Duration {
 	if cfg == nil {
@@ -225,6 +301,15 @@ func (cfg *BacktestConfig) ToStrategyConfig() *store.StrategyConfig {
 		if cfg.CustomPrompt != "" {
 			result.CustomPrompt = cfg.CustomPrompt
 		}
+		if cfg.PromptTemplateRef != nil {
+			result.PromptTemplate = cfg.PromptTemplateRef
+		}
+		// Data tools fetch live market data, which would leak future prices into the replay
+		result.ToolCalling = nil
+		// Derivatives inputs only exist as live snapshots, for the same reason
+		result.Indicators.EnableLiquidationData = false
+		result.Indicators.EnableLongShortRatio = false
+		result.Indicators.EnableOIVsMarketCap = false
 
 		return &result
 	}
@@ -269,7 +354,8 @@ func (cfg *BacktestConfig) ToStrategyConfig() *store.StrategyConfig {
 			RSIPeriods:        []int{7, 14},
 			ATRPeriods:        []int{14},
 		},
-		CustomPrompt: cfg.CustomPrompt,
+		CustomPrompt:   cfg.CustomPrompt,
+		PromptTemplate: cfg.PromptTemplateRef,
 		RiskControl: store.RiskControlConfig{
 			MaxPositions:                 3,
 			BTCETHMaxLeverage:            cfg.Leverage.BTCETHLeverage,
@@ -280,6 +366,7 @@ func (cfg *BacktestConfig) ToStrategyConfig() *store.StrategyConfig {
 			MinPositionSize:              12,
 			MinRiskRewardRatio:           3.0,
 			MinConfidence:                75,
+			// Portfolio exposure limits only apply when a saved strategy sets them
 		},
 	}
 }
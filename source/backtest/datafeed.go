@@ -5,6 +5,7 @@ import (
 	"sort"
 	"time"
 
+	"nofx/kernel"
 	"nofx/market"
 )
 
@@ -80,6 +81,8 @@ func (df *DataFeed) loadAll() error {
 			if len(klines) == 0 {
 				return fmt.Errorf("no klines for %s %s", symbol, tf)
 			}
+			// Drop bars outside the underlying market's sessions (stocks, forex, commodities)
+			klines = market.FilterSessionKlines(symbol, tf, klines)
 
 			series := &timeframeSeries{
 				klines:     klines,
@@ -204,3 +207,17 @@ func (df *DataFeed) decisionBarSnapshot(symbol string, ts int64) (*market.Kline,
 	}
 	return curr, next
 }
+
+// CorrelationKlines returns up to count klines of symbol closed by ts for correlation checks,
+// using the correlation timeframe when loaded and the decision timeframe otherwise
+func (df *DataFeed) CorrelationKlines(symbol string, ts int64, count int) []market.Kline {
+	tf := df.primaryTF
+	if ss, ok := df.symbolSeries[symbol]; ok && ss.byTF[kernel.CorrelationTimeframe] != nil {
+		tf = kernel.CorrelationTimeframe
+	}
+	klines := df.sliceUpTo(symbol, tf, ts)
+	if len(klines) > count {
+		klines = klines[len(klines)-count:]
+	}
+	return klines
+}
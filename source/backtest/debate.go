@@ -0,0 +1,129 @@
+package backtest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"nofx/debate"
+	"nofx/kernel"
+	"nofx/mcp"
+	"nofx/store"
+)
+
+var errDebateReplayMiss = errors.New("debate turn not found in ai cache")
+
+// newDebateClients creates one AI client per debate participant, in participant order, then the moderator's.
+func newDebateClients(cfg BacktestConfig, base mcp.AIClient) ([]mcp.AIClient, error) {
+	if !cfg.DebateEnabled() {
+		return nil, nil
+	}
+	speakers := cfg.Debate.speakers()
+	clients := make([]mcp.AIClient, 0, len(speakers))
+	for _, p := range speakers {
+		client, err := configureMCPClient(BacktestConfig{AICfg: p.AICfg}, base)
+		if err != nil {
+			return nil, fmt.Errorf("debate participant %s: %w", p.Name, err)
+		}
+		clients = append(clients, client)
+	}
+	return clients, nil
+}
+
+// invokeDebate decides a bar by running the configured debate panel over ctx. Every participant turn is
+// read from and written to the AI cache, so a debate configuration can be replayed on the same bars.
+func (r *Runner) invokeDebate(ctx *kernel.Context, ts int64) (*kernel.FullDecision, *debate.OfflineResult, error) {
+	speakers := r.cfg.Debate.speakers()
+	participants := make([]*store.DebateParticipant, len(speakers))
+	index := make(map[*store.DebateParticipant]int, len(participants))
+	for i, p := range speakers {
+		participants[i] = &store.DebateParticipant{
+			AIModelID:    p.AIModelID,
+			AIModelName:  p.Name,
+			Provider:     p.AICfg.Provider,
+			Personality:  p.Personality,
+			Color:        store.PersonalityColors[p.Personality],
+			SpeakOrder:   i,
+			CustomPrompt: p.Prompt,
+			RiskBias:     p.RiskBias,
+		}
+		if p.Prompt != "" {
+			participants[i].PersonalityName = p.Name
+		}
+		index[participants[i]] = i
+	}
+	var moderator *store.DebateParticipant
+	if r.cfg.Debate.Moderator != nil {
+		moderator = participants[len(participants)-1]
+		participants = participants[:len(participants)-1]
+	}
+
+	replayMiss := false
+	call := func(participant *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error) {
+		i := index[participant]
+		key := computeTurnKey(speakers[i], round, ts, systemPrompt, userPrompt)
+		if response, ok := r.aiCache.GetTurn(key); ok {
+			return response, nil
+		}
+		if r.cfg.ReplayOnly {
+			replayMiss = true
+			return "", errDebateReplayMiss
+		}
+
+		var (
+			response string
+			err      error
+		)
+		for attempt := 0; attempt < aiDecisionMaxRetries; attempt++ {
+			response, err = r.debateClients[i].CallWithMessages(systemPrompt, userPrompt)
+			if err == nil {
+				break
+			}
+			time.Sleep(time.Duration(attempt+1) * 500 * time.Millisecond)
+		}
+		if err != nil {
+			return "", err
+		}
+		if r.cfg.CacheAI {
+			if err := r.aiCache.PutTurn(key, ts, response); err != nil {
+				r.log().Infof("failed to persist debate turn for %s: %v", r.cfg.RunID, err)
+			}
+		}
+		return response, nil
+	}
+
+	start := time.Now()
+	result, err := debate.RunOffline(debate.OfflineRequest{
+		ID:            fmt.Sprintf("%s#%d", r.cfg.RunID, ctx.CallCount),
+		Participants:  participants,
+		Moderator:     moderator,
+		MaxRounds:     r.cfg.Debate.MaxRounds,
+		PromptVariant: r.cfg.PromptVariant,
+		Strategy:      r.strategyEngine,
+		Context:       ctx,
+		Call:          debate.TurnCallerFunc(call),
+		Weights:       r.cfg.Debate.Weights,
+	})
+	if replayMiss {
+		return nil, nil, fmt.Errorf("replay_only enabled but %w at %d", errDebateReplayMiss, ts)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	consensusJSON, _ := json.MarshalIndent(result.Consensus, "", "  ")
+	fd := &kernel.FullDecision{
+		SystemPrompt:        result.SystemPrompt,
+		UserPrompt:          result.UserPrompt,
+		CoTTrace:            result.Transcript(),
+		Decisions:           result.Decisions,
+		RawResponse:         string(consensusJSON),
+		Timestamp:           time.UnixMilli(ts).UTC(),
+		AIRequestDurationMs: time.Since(start).Milliseconds(),
+	}
+	if err := r.strategyEngine.ValidateDecisions(fd.Decisions, ctx.Account.TotalEquity); err != nil {
+		return nil, result, fmt.Errorf("debate consensus validation failed: %w", err)
+	}
+	return fd, result, nil
+}
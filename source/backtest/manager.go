@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"nofx/mcp"
+	"nofx/metrics"
 	"nofx/store"
 )
 
@@ -61,8 +62,7 @@ func (m *Manager) Start(ctx context.Context, cfg BacktestConfig) (*Runner, error
 	}
 	m.mu.Unlock()
 
-	persistCfg := cfg
-	persistCfg.AICfg.APIKey = ""
+	persistCfg := withoutSecrets(&cfg)
 	if err := SaveConfig(cfg.RunID, &persistCfg); err != nil {
 		return nil, err
 	}
@@ -302,6 +302,8 @@ func (m *Manager) Delete(runID string) error {
 	delete(m.runners, runID)
 	delete(m.metadata, runID)
 	m.mu.Unlock()
+	metrics.BacktestProgress.Delete(runID)
+	metrics.BacktestEquity.Delete(runID)
 	if err := removeFromRunIndex(runID); err != nil {
 		return err
 	}
@@ -427,9 +429,7 @@ func (m *Manager) resolveAIConfig(cfg *BacktestConfig) error {
 	if cfg == nil {
 		return fmt.Errorf("ai config missing")
 	}
-	provider := strings.TrimSpace(cfg.AICfg.Provider)
-	apiKey := strings.TrimSpace(cfg.AICfg.APIKey)
-	if provider != "" && !strings.EqualFold(provider, "inherit") && apiKey != "" {
+	if !needsAIResolve(cfg) {
 		return nil
 	}
 
@@ -437,7 +437,7 @@ func (m *Manager) resolveAIConfig(cfg *BacktestConfig) error {
 	resolver := m.aiResolver
 	m.mu.RUnlock()
 	if resolver == nil {
-		if apiKey == "" {
+		if strings.TrimSpace(cfg.AICfg.APIKey) == "" {
 			return fmt.Errorf("AI configuration missing key and no resolver configured")
 		}
 		return nil
@@ -445,6 +445,37 @@ func (m *Manager) resolveAIConfig(cfg *BacktestConfig) error {
 	return resolver(cfg)
 }
 
+// needsAIResolve reports whether the main, fallback or debate AI configs lack a key (persisted configs
+// have them stripped) or the fallbacks no longer match FallbackAIModelIDs
+func needsAIResolve(cfg *BacktestConfig) bool {
+	if !aiConfigReady(cfg.AICfg) || len(cfg.FallbackAICfgs) != len(cfg.FallbackAIModelIDs) {
+		return true
+	}
+	for _, fallback := range cfg.FallbackAICfgs {
+		if !aiConfigReady(fallback) {
+			return true
+		}
+	}
+	if cfg.Debate != nil {
+		for _, p := range cfg.Debate.Participants {
+			if !aiConfigReady(p.AICfg) {
+				return true
+			}
+		}
+		if cfg.Debate.Moderator != nil && !aiConfigReady(cfg.Debate.Moderator.AICfg) {
+			return true
+		}
+	}
+	return false
+}
+
+// aiConfigReady reports whether an AI config can be used as is
+func aiConfigReady(aiCfg AIConfig) bool {
+	provider := strings.TrimSpace(aiCfg.Provider)
+	apiKey := strings.TrimSpace(aiCfg.APIKey)
+	return provider != "" && !strings.EqualFold(provider, "inherit") && (apiKey != "" || !mcp.ProviderRequiresAPIKey(strings.ToLower(provider)))
+}
+
 func (m *Manager) GetTrace(runID string, cycle int) (*store.DecisionRecord, error) {
 	return LoadDecisionTrace(runID, cycle)
 }
@@ -134,6 +134,22 @@ func (re *ReflectionEngine) ApplyRecommendations(reflection *store.ReflectionRec
 		return fmt.Errorf("failed to save adjustment: %w", err)
 	}
 
+	// Record the proposed adjustment in the audit log of the trader's owner
+	if t, err := re.store.Trader().GetByID(reflection.TraderID); err == nil {
+		after := map[string]interface{}{
+			"reflection_id":     reflection.ID,
+			"status":            adjustment.Status,
+			"confidence_level":  adjustment.ConfidenceLevel,
+			"btc_eth_leverage":  adjustment.BTCETHLeverage,
+			"altcoin_leverage":  adjustment.AltcoinLeverage,
+			"max_position_size": adjustment.MaxPositionSize,
+			"max_daily_loss":    adjustment.MaxDailyLoss,
+		}
+		if err := re.store.Audit().Record(t.UserID, "reflection", "reflection.apply", "trader", reflection.TraderID, nil, after); err != nil {
+			logger.Warnf("⚠️  Failed to write audit entry: %v", err)
+		}
+	}
+
 	// 3. 保存 AI 学习记忆
 	for _, adviceJSON := range reflection.AILearningAdvice {
 		var advice store.ReflectionRecommendation
@@ -11,11 +11,16 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
+	"nofx/debate"
 	"nofx/kernel"
 	"nofx/market"
 	"nofx/mcp"
+	"nofx/metrics"
 	"nofx/store"
 )
 
@@ -38,6 +43,7 @@ type Runner struct {
 
 	decisionLogDir string
 	mcpClient      mcp.AIClient
+	debateClients  []mcp.AIClient // One per cfg.Debate participant when debate mode is on
 
 	statusMu sync.RWMutex
 	status   RunState
@@ -63,6 +69,16 @@ type Runner struct {
 	lockInfo     *RunLockInfo
 	lockStop     chan struct{}
 	lockStopOnce sync.Once // Ensures lockStop is closed only once
+
+	logEntry atomic.Pointer[logrus.Entry] // Entry tagged with run_id and the current decision cycle
+}
+
+// log returns the structured log entry of this run (run_id, plus the decision cycle once a step has run)
+func (r *Runner) log() *logrus.Entry {
+	if e := r.logEntry.Load(); e != nil {
+		return e
+	}
+	return logger.ForRun(r.cfg.RunID)
 }
 
 // NewRunner constructs a backtest runner.
@@ -76,6 +92,11 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 		return nil, err
 	}
 
+	debateClients, err := newDebateClients(cfg, mcpClient)
+	if err != nil {
+		return nil, err
+	}
+
 	feed, err := NewDataFeed(cfg)
 	if err != nil {
 		return nil, err
@@ -128,6 +149,7 @@ func NewRunner(cfg BacktestConfig, mcpClient mcp.AIClient) (*Runner, error) {
 		strategyEngine: strategyEngine,
 		decisionLogDir: dLogDir,
 		mcpClient:      client,
+		debateClients:  debateClients,
 		status:         RunStateCreated,
 		state:          state,
 		pauseCh:        make(chan struct{}, 1),
@@ -167,7 +189,7 @@ func (r *Runner) lockHeartbeatLoop() {
 		select {
 		case <-ticker.C:
 			if err := updateRunLockHeartbeat(r.lockInfo); err != nil {
-				logger.Infof("failed to update lock heartbeat for %s: %v", r.cfg.RunID, err)
+				r.log().Infof("failed to update lock heartbeat for %s: %v", r.cfg.RunID, err)
 			}
 		case <-r.lockStop:
 			return
@@ -183,7 +205,7 @@ func (r *Runner) releaseLock() {
 		}
 	})
 	if err := deleteRunLock(r.cfg.RunID); err != nil {
-		logger.Infof("failed to release lock for %s: %v", r.cfg.RunID, err)
+		r.log().Infof("failed to release lock for %s: %v", r.cfg.RunID, err)
 	}
 	r.lockInfo = nil
 }
@@ -285,6 +307,7 @@ func (r *Runner) stepOnce() error {
 	}
 
 	callCount := state.DecisionCycle + 1
+	r.logEntry.Store(logger.ForRun(r.cfg.RunID).WithField(logger.FieldCycle, callCount))
 	shouldDecide := r.shouldTriggerDecision(state.BarIndex)
 
 	var (
@@ -312,10 +335,13 @@ func (r *Runner) stepOnce() error {
 
 		var (
 			fullDecision *kernel.FullDecision
+			debateResult *debate.OfflineResult
 			fromCache    bool
 			cacheKey     string
 		)
-		if r.aiCache != nil {
+		// Debate mode caches individual turns instead of whole decisions (see invokeDebate)
+		debateMode := r.cfg.DebateEnabled()
+		if r.aiCache != nil && !debateMode {
 			if key, err := computeCacheKey(ctx, r.cfg.PromptVariant, ts); err == nil {
 				cacheKey = key
 				if cached, ok := r.aiCache.Get(cacheKey); ok {
@@ -329,12 +355,27 @@ func (r *Runner) stepOnce() error {
 					return decisionErr
 				}
 			} else {
-				logger.Infof("failed to compute ai cache key: %v", err)
+				r.log().Infof("failed to compute ai cache key: %v", err)
 			}
 		}
 
 		if !fromCache {
-			fd, err := r.invokeAIWithRetry(ctx)
+			var fd *kernel.FullDecision
+			if debateMode {
+				fd, debateResult, err = r.invokeDebate(ctx, ts)
+				if errors.Is(err, errDebateReplayMiss) {
+					record.Success = false
+					record.ErrorMessage = fmt.Sprintf("cached debate turn not found for ts=%d", ts)
+					_ = r.logDecision(record)
+					return err
+				}
+			} else {
+				fd, err = r.invokeAIWithRetry(ctx)
+			}
+			if debateResult != nil {
+				record.DebateVotes = debateResult.Votes
+				record.DebateConsensus = debateResult.Consensus
+			}
 			if err != nil {
 				decisionAttempted = true
 				hadError = true
@@ -346,7 +387,7 @@ func (r *Runner) stepOnce() error {
 				fullDecision = fd
 				if r.cfg.CacheAI && r.aiCache != nil && cacheKey != "" {
 					if err := r.aiCache.Put(cacheKey, r.cfg.PromptVariant, ts, fullDecision); err != nil {
-						logger.Infof("failed to persist ai cache for %s: %v", r.cfg.RunID, err)
+						r.log().Infof("failed to persist ai cache for %s: %v", r.cfg.RunID, err)
 					}
 				}
 			}
@@ -457,6 +498,8 @@ func (r *Runner) stepOnce() error {
 	if err := saveProgress(r.cfg.RunID, &snapshot, &r.cfg); err != nil {
 		return err
 	}
+	metrics.BacktestProgress.Set(progressPercent(snapshot, r.cfg)/100, r.cfg.RunID)
+	metrics.BacktestEquity.Set(snapshot.Equity, r.cfg.RunID)
 
 	if err := r.maybeCheckpoint(); err != nil {
 		return err
@@ -498,7 +541,7 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 	positions := r.convertPositions(priceMap)
 
 	// Get candidate coins from strategy engine (includes source info)
-	candidateCoins, err := r.strategyEngine.GetCandidateCoins()
+	candidateCoins, err := r.strategyEngine.GetCandidateCoinsAt(time.UnixMilli(ts))
 	if err != nil {
 		// Fallback to simple list if strategy engine fails
 		candidateCoins = make([]kernel.CandidateCoin, 0, len(r.cfg.Symbols))
@@ -518,6 +561,7 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 		PromptVariant:   r.cfg.PromptVariant,
 		MarketDataMap:   marketData,
 		MultiTFMarket:   multiTF,
+		MarketSessions:  r.strategyEngine.BuildMarketSessions(r.cfg.Symbols, time.UnixMilli(ts)),
 		BTCETHLeverage:  r.cfg.Leverage.BTCETHLeverage,
 		AltcoinLeverage: r.cfg.Leverage.AltcoinLeverage,
 		Timeframes:      r.cfg.Timeframes,
@@ -540,7 +584,7 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 		}
 		ctx.QuantDataMap = r.strategyEngine.FetchQuantDataBatch(symbols)
 		if len(ctx.QuantDataMap) > 0 {
-			logger.Infof("📊 Backtest: fetched quant data for %d symbols", len(ctx.QuantDataMap))
+			r.log().Infof("📊 Backtest: fetched quant data for %d symbols", len(ctx.QuantDataMap))
 		}
 	}
 
@@ -548,7 +592,7 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 	if strategyConfig.Indicators.EnableOIRanking {
 		ctx.OIRankingData = r.strategyEngine.FetchOIRankingData()
 		if ctx.OIRankingData != nil {
-			logger.Infof("📊 Backtest: OI ranking data ready: %d top, %d low positions",
+			r.log().Infof("📊 Backtest: OI ranking data ready: %d top, %d low positions",
 				len(ctx.OIRankingData.TopPositions), len(ctx.OIRankingData.LowPositions))
 		}
 	}
@@ -557,7 +601,7 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 	if strategyConfig.Indicators.EnableNetFlowRanking {
 		ctx.NetFlowRankingData = r.strategyEngine.FetchNetFlowRankingData()
 		if ctx.NetFlowRankingData != nil {
-			logger.Infof("💰 Backtest: NetFlow ranking data ready: inst_in=%d, inst_out=%d",
+			r.log().Infof("💰 Backtest: NetFlow ranking data ready: inst_in=%d, inst_out=%d",
 				len(ctx.NetFlowRankingData.InstitutionFutureTop), len(ctx.NetFlowRankingData.InstitutionFutureLow))
 		}
 	}
@@ -566,7 +610,7 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 	if strategyConfig.Indicators.EnablePriceRanking {
 		ctx.PriceRankingData = r.strategyEngine.FetchPriceRankingData()
 		if ctx.PriceRankingData != nil {
-			logger.Infof("📈 Backtest: Price ranking data ready for %d durations",
+			r.log().Infof("📈 Backtest: Price ranking data ready for %d durations",
 				len(ctx.PriceRankingData.Durations))
 		}
 	}
@@ -593,6 +637,7 @@ func (r *Runner) buildDecisionContext(ts int64, marketData map[string]*market.Da
 func (r *Runner) fillDecisionRecord(record *store.DecisionRecord, full *kernel.FullDecision) {
 	record.InputPrompt = full.UserPrompt
 	record.CoTTrace = full.CoTTrace
+	record.AIModel = full.AIModel
 	if len(full.Decisions) > 0 {
 		if data, err := json.MarshalIndent(full.Decisions, "", "  "); err == nil {
 			record.DecisionJSON = string(data)
@@ -651,6 +696,10 @@ func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float6
 		if qty <= 0 {
 			return actionRecord, nil, "", fmt.Errorf("invalid qty")
 		}
+		qty, err := r.enforcePortfolioExposure(symbol, "long", qty, basePrice, priceMap, ts)
+		if err != nil {
+			return actionRecord, nil, "", err
+		}
 		pos, fee, execPrice, err := r.account.Open(symbol, "long", qty, usedLeverage, fillPrice, ts)
 		if err != nil {
 			return actionRecord, nil, "", err
@@ -680,6 +729,10 @@ func (r *Runner) executeDecision(dec kernel.Decision, priceMap map[string]float6
 		if qty <= 0 {
 			return actionRecord, nil, "", fmt.Errorf("invalid qty")
 		}
+		qty, err := r.enforcePortfolioExposure(symbol, "short", qty, basePrice, priceMap, ts)
+		if err != nil {
+			return actionRecord, nil, "", err
+		}
 		pos, fee, execPrice, err := r.account.Open(symbol, "short", qty, usedLeverage, fillPrice, ts)
 		if err != nil {
 			return actionRecord, nil, "", err
@@ -800,14 +853,14 @@ func (r *Runner) determineQuantity(dec kernel.Decision, price float64) float64 {
 
 	// Cap position size to what we can actually afford
 	if sizeUSD > maxPositionValue {
-		logger.Infof("📊 Backtest: capping position from %.2f to %.2f (available margin: %.2f, leverage: %dx)",
+		r.log().Infof("📊 Backtest: capping position from %.2f to %.2f (available margin: %.2f, leverage: %dx)",
 			sizeUSD, maxPositionValue, maxMarginToUse, leverage)
 		sizeUSD = maxPositionValue
 	}
 
 	// Reject positions below minimum size to avoid dust positions
 	if sizeUSD < MinPositionSizeUSD {
-		logger.Infof("📊 Backtest: rejecting position size %.2f USD (below minimum %.2f USD)",
+		r.log().Infof("📊 Backtest: rejecting position size %.2f USD (below minimum %.2f USD)",
 			sizeUSD, MinPositionSizeUSD)
 		return 0
 	}
@@ -819,6 +872,47 @@ func (r *Runner) determineQuantity(dec kernel.Decision, price float64) float64 {
 	return qty
 }
 
+// enforcePortfolioExposure mirrors the live portfolio exposure limits, returning the quantity that fits
+func (r *Runner) enforcePortfolioExposure(symbol, side string, qty, price float64, priceMap map[string]float64, ts int64) (float64, error) {
+	riskControl := r.strategyEngine.GetConfig().RiskControl
+
+	held := make([]kernel.ExposurePosition, 0)
+	for _, pos := range r.account.Positions() {
+		markPrice := priceMap[pos.Symbol]
+		if markPrice <= 0 {
+			markPrice = pos.EntryPrice
+		}
+		held = append(held, kernel.ExposurePosition{Symbol: pos.Symbol, Side: pos.Side, Notional: pos.Quantity * markPrice})
+	}
+
+	var klines map[string][]market.Kline
+	if kernel.NeedsCorrelationData(riskControl) && len(held) > 0 {
+		count := kernel.CorrelationLookback(riskControl) + 1
+		klines = map[string][]market.Kline{symbol: r.feed.CorrelationKlines(symbol, ts, count)}
+		for _, p := range held {
+			klines[p.Symbol] = r.feed.CorrelationKlines(p.Symbol, ts, count)
+		}
+	}
+
+	equity := r.snapshotState().Equity
+	if equity <= 0 {
+		equity = r.account.InitialBalance()
+	}
+
+	sizeUSD := qty * price
+	allowed, limit, err := kernel.CheckPortfolioExposure(riskControl, held, symbol, side, sizeUSD, equity, klines)
+	if err != nil {
+		return 0, err
+	}
+	if allowed < sizeUSD {
+		if allowed < MinPositionSizeUSD {
+			return 0, fmt.Errorf("position %.2f USD capped by %s limit below minimum %.2f USD", sizeUSD, limit, MinPositionSizeUSD)
+		}
+		r.log().WithField(logger.FieldSymbol, symbol).Infof("📊 Backtest: capping %s %s from %.2f to %.2f USD (%s limit)", symbol, side, sizeUSD, allowed, limit)
+	}
+	return allowed / price, nil
+}
+
 func (r *Runner) determineCloseQuantity(symbol, side string, dec kernel.Decision) float64 {
 	for _, pos := range r.account.Positions() {
 		if pos.Symbol == strings.ToUpper(symbol) && pos.Side == side {
@@ -854,7 +948,7 @@ func (r *Runner) resolveLeverage(requested int, symbol string) int {
 
 	// Enforce max leverage limit
 	if leverage > maxLeverage {
-		logger.Infof("📊 Backtest: capping leverage from %dx to %dx for %s",
+		r.log().WithField(logger.FieldSymbol, symbol).Infof("📊 Backtest: capping leverage from %dx to %dx for %s",
 			leverage, maxLeverage, symbol)
 		leverage = maxLeverage
 	}
@@ -1298,10 +1392,10 @@ func (r *Runner) persistMetadata() {
 	meta := r.buildMetadata(state, r.Status())
 	meta.CreatedAt = r.createdAt
 	if err := SaveRunMetadata(meta); err != nil {
-		logger.Infof("failed to save run metadata for %s: %v", r.cfg.RunID, err)
+		r.log().Infof("failed to save run metadata for %s: %v", r.cfg.RunID, err)
 	} else {
 		if err := updateRunIndex(meta, &r.cfg); err != nil {
-			logger.Infof("failed to update index for %s: %v", r.cfg.RunID, err)
+			r.log().Infof("failed to update index for %s: %v", r.cfg.RunID, err)
 		}
 	}
 }
@@ -1328,14 +1422,14 @@ func (r *Runner) persistMetrics(force bool) {
 	state := r.snapshotState()
 	metrics, err := CalculateMetrics(r.cfg.RunID, &r.cfg, &state)
 	if err != nil {
-		logger.Infof("failed to compute metrics for %s: %v", r.cfg.RunID, err)
+		r.log().Infof("failed to compute metrics for %s: %v", r.cfg.RunID, err)
 		return
 	}
 	if metrics == nil {
 		return
 	}
 	if err := PersistMetrics(r.cfg.RunID, metrics); err != nil {
-		logger.Infof("failed to persist metrics for %s: %v", r.cfg.RunID, err)
+		r.log().Infof("failed to persist metrics for %s: %v", r.cfg.RunID, err)
 		return
 	}
 	r.lastMetricsWrite = time.Now()
@@ -1435,7 +1529,7 @@ func (r *Runner) saveCheckpoint(state BacktestState) error {
 func (r *Runner) forceCheckpoint() {
 	state := r.snapshotState()
 	if err := r.saveCheckpoint(state); err != nil {
-		logger.Infof("failed to save checkpoint for %s: %v", r.cfg.RunID, err)
+		r.log().Infof("failed to save checkpoint for %s: %v", r.cfg.RunID, err)
 	}
 }
 
@@ -247,12 +247,35 @@ func saveProgress(runID string, state *BacktestState, cfg *BacktestConfig) error
 	return writeJSONAtomic(progressPath(runID), payload)
 }
 
+// withoutSecrets copies cfg with every API key cleared; keys are resolved again from the model IDs on resume
+func withoutSecrets(cfg *BacktestConfig) BacktestConfig {
+	persist := *cfg
+	persist.AICfg.APIKey = ""
+	persist.FallbackAICfgs = append([]AIConfig(nil), cfg.FallbackAICfgs...)
+	for i := range persist.FallbackAICfgs {
+		persist.FallbackAICfgs[i].APIKey = ""
+	}
+	if cfg.Debate != nil {
+		debateCfg := *cfg.Debate
+		debateCfg.Participants = append([]DebateParticipantConfig(nil), cfg.Debate.Participants...)
+		for i := range debateCfg.Participants {
+			debateCfg.Participants[i].AICfg.APIKey = ""
+		}
+		if cfg.Debate.Moderator != nil {
+			moderator := *cfg.Debate.Moderator
+			moderator.AICfg.APIKey = ""
+			debateCfg.Moderator = &moderator
+		}
+		persist.Debate = &debateCfg
+	}
+	return persist
+}
+
 func SaveConfig(runID string, cfg *BacktestConfig) error {
 	if cfg == nil {
 		return fmt.Errorf("config is nil")
 	}
-	persist := *cfg
-	persist.AICfg.APIKey = ""
+	persist := withoutSecrets(cfg)
 	if usingDB() {
 		return saveConfigDB(runID, &persist)
 	}
@@ -42,8 +42,7 @@ func loadCheckpointDB(runID string) (*Checkpoint, error) {
 }
 
 func saveConfigDB(runID string, cfg *BacktestConfig) error {
-	persist := *cfg
-	persist.AICfg.APIKey = ""
+	persist := withoutSecrets(cfg)
 	data, err := json.Marshal(&persist)
 	if err != nil {
 		return err
@@ -3,9 +3,11 @@ package config
 import (
 	"nofx/experience"
 	"nofx/mcp"
+	"nofx/metrics"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Global configuration instance
@@ -30,11 +32,22 @@ type Config struct {
 	DBName     string // PostgreSQL database name
 	DBSSLMode  string // PostgreSQL SSL mode
 
+	// MetricsToken bearer token required to scrape /metrics (empty = endpoint disabled)
+	MetricsToken string
+
+	// Backup configuration
+	BackupDir      string        // Directory for backup archives
+	BackupInterval time.Duration // Scheduled backup interval (0 = disabled)
+	BackupKeep     int           // Number of scheduled archives to keep
+
 	// Security configuration
 	// TransportEncryption enables browser-side encryption for API keys
 	// Requires HTTPS or localhost. Set to false for HTTP access via IP.
 	TransportEncryption bool
 
+	// AdminEmails users allowed to run instance-wide operations such as data key rotation
+	AdminEmails []string
+
 	// Experience improvement (anonymous usage statistics)
 	// Helps us understand product usage and improve the experience
 	// Set EXPERIENCE_IMPROVEMENT=false to disable
@@ -61,6 +74,9 @@ func Init() {
 		DBUser:    "postgres",
 		DBName:    "nofx",
 		DBSSLMode: "disable",
+		// Backup defaults
+		BackupDir:  "data/backups",
+		BackupKeep: 7,
 	}
 
 	// Load from environment variables
@@ -93,6 +109,13 @@ func Init() {
 		cfg.TransportEncryption = strings.ToLower(v) == "true"
 	}
 
+	// Instance administrators (comma-separated emails)
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			cfg.AdminEmails = append(cfg.AdminEmails, email)
+		}
+	}
+
 	// Experience improvement: anonymous usage statistics
 	// Default enabled, set EXPERIENCE_IMPROVEMENT=false to disable
 	if v := os.Getenv("EXPERIENCE_IMPROVEMENT"); v != "" {
@@ -132,6 +155,24 @@ func Init() {
 		cfg.DBSSLMode = v
 	}
 
+	// Metrics
+	cfg.MetricsToken = os.Getenv("METRICS_TOKEN")
+
+	// Backups
+	if v := os.Getenv("BACKUP_DIR"); v != "" {
+		cfg.BackupDir = v
+	}
+	if v := os.Getenv("BACKUP_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.BackupInterval = d
+		}
+	}
+	if v := os.Getenv("BACKUP_KEEP"); v != "" {
+		if keep, err := strconv.Atoi(v); err == nil && keep >= 0 {
+			cfg.BackupKeep = keep
+		}
+	}
+
 	global = cfg
 
 	// Initialize experience improvement (installation ID will be set after database init)
@@ -139,6 +180,8 @@ func Init() {
 
 	// Set up AI token usage tracking callback
 	mcp.TokenUsageCallback = func(usage mcp.TokenUsage) {
+		metrics.AITokens.Add(float64(usage.PromptTokens), usage.Provider, "prompt")
+		metrics.AITokens.Add(float64(usage.CompletionTokens), usage.Provider, "completion")
 		experience.TrackAIUsage(experience.AIUsageEvent{
 			ModelProvider: usage.Provider,
 			ModelName:     usage.Model,
@@ -146,6 +189,20 @@ func Init() {
 			OutputTokens:  usage.CompletionTokens,
 		})
 	}
+
+	// Export AI request latency and failures on /metrics
+	mcp.RequestCallback = metrics.ObserveAIRequest
+}
+
+// IsAdminEmail checks whether an email belongs to an instance administrator
+func (c *Config) IsAdminEmail(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	for _, e := range c.AdminEmails {
+		if e == email {
+			return true
+		}
+	}
+	return false
 }
 
 // Get returns the global configuration
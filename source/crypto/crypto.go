@@ -15,19 +15,23 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
 const (
-	storagePrefix    = "ENC:v1:"
+	legacyPrefix     = "ENC:v1:" // ENC:v1:<nonce>:<ciphertext>, written before key IDs existed
+	keyedPrefix      = "ENC:v2:" // ENC:v2:<key id>:<nonce>:<ciphertext>
 	storageDelimiter = ":"
 )
 
 // Environment variable names
 const (
-	EnvDataEncryptionKey = "DATA_ENCRYPTION_KEY" // AES data encryption key (Base64)
-	EnvRSAPrivateKey     = "RSA_PRIVATE_KEY"     // RSA private key (PEM format, use \n for newlines)
+	EnvDataEncryptionKey = "DATA_ENCRYPTION_KEY"           // AES data encryption key (Base64)
+	EnvPreviousDataKeys  = "DATA_ENCRYPTION_PREVIOUS_KEYS" // Comma-separated older data keys, still accepted for decryption
+	EnvRSAPrivateKey     = "RSA_PRIVATE_KEY"               // RSA private key (PEM format, use \n for newlines)
 )
 
 type EncryptedPayload struct {
@@ -47,30 +51,105 @@ type AADData struct {
 }
 
 type CryptoService struct {
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
-	dataKey    []byte
+	privateKey  *rsa.PrivateKey
+	publicKey   *rsa.PublicKey
+	keyProvider KeyProvider
+
+	mu        sync.RWMutex
+	dataKeys  map[string][]byte // key ID -> AES key, active and previous keys
+	activeKID string            // key used for new ciphertexts
 }
 
-// NewCryptoService creates crypto service (loads keys from environment variables)
+// NewCryptoService creates crypto service (RSA key from environment, data keys from the configured key provider)
 func NewCryptoService() (*CryptoService, error) {
+	provider, err := NewKeyProviderFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return NewCryptoServiceWithProvider(provider)
+}
+
+// NewCryptoServiceWithProvider creates crypto service with a specific data key provider
+func NewCryptoServiceWithProvider(provider KeyProvider) (*CryptoService, error) {
 	// 1. Load RSA private key
 	privateKey, err := loadRSAPrivateKeyFromEnv()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load RSA private key: %w", err)
 	}
 
-	// 2. Load AES data encryption key
-	dataKey, err := loadDataKeyFromEnv()
-	if err != nil {
+	cs := &CryptoService{
+		privateKey:  privateKey,
+		publicKey:   &privateKey.PublicKey,
+		keyProvider: provider,
+	}
+
+	// 2. Load AES data encryption keys
+	if err := cs.ReloadDataKeys(); err != nil {
 		return nil, fmt.Errorf("failed to load data encryption key: %w", err)
 	}
+	return cs, nil
+}
+
+// ReloadDataKeys fetches the data keys from the key provider again
+// A new active key takes effect for all following writes, previous keys keep decrypting old values
+func (cs *CryptoService) ReloadDataKeys() error {
+	active, previous, err := cs.keyProvider.DataKeys()
+	if err != nil {
+		return fmt.Errorf("%s key provider: %w", cs.keyProvider.Name(), err)
+	}
+	if strings.TrimSpace(active) == "" {
+		return fmt.Errorf("%s key provider returned no active data key", cs.keyProvider.Name())
+	}
+
+	keys := make(map[string][]byte, len(previous)+1)
+	activeKey := parseDataKey(active)
+	activeKID := KeyID(activeKey)
+	keys[activeKID] = activeKey
+	for _, p := range previous {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+		key := parseDataKey(p)
+		keys[KeyID(key)] = key
+	}
 
-	return &CryptoService{
-		privateKey: privateKey,
-		publicKey:  &privateKey.PublicKey,
-		dataKey:    dataKey,
-	}, nil
+	cs.mu.Lock()
+	cs.dataKeys = keys
+	cs.activeKID = activeKID
+	cs.mu.Unlock()
+	return nil
+}
+
+// ActiveKeyID returns the ID of the key used for new ciphertexts
+func (cs *CryptoService) ActiveKeyID() string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.activeKID
+}
+
+// KeyIDs returns the IDs of all loaded data keys, active key first
+func (cs *CryptoService) KeyIDs() []string {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	ids := []string{cs.activeKID}
+	for id := range cs.dataKeys {
+		if id != cs.activeKID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids[1:])
+	return ids
+}
+
+// KeyProviderName returns the name of the data key provider
+func (cs *CryptoService) KeyProviderName() string {
+	return cs.keyProvider.Name()
+}
+
+// KeyID derives the public ID of a data key (first 12 hex digits of its SHA-256)
+func KeyID(key []byte) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:])[:12]
 }
 
 // loadRSAPrivateKeyFromEnv loads RSA private key from environment variable
@@ -86,23 +165,20 @@ func loadRSAPrivateKeyFromEnv() (*rsa.PrivateKey, error) {
 	return ParseRSAPrivateKeyFromPEM([]byte(keyPEM))
 }
 
-// loadDataKeyFromEnv loads AES data encryption key from environment variable
-func loadDataKeyFromEnv() ([]byte, error) {
-	keyStr := strings.TrimSpace(os.Getenv(EnvDataEncryptionKey))
-	if keyStr == "" {
-		return nil, fmt.Errorf("environment variable %s not set, please configure data encryption key in .env", EnvDataEncryptionKey)
-	}
+// parseDataKey converts a configured data key into an AES key
+func parseDataKey(keyStr string) []byte {
+	keyStr = strings.TrimSpace(keyStr)
 
 	// Try to decode
 	if key, ok := decodePossibleKey(keyStr); ok {
-		return key, nil
+		return key
 	}
 
 	// If decoding fails, use SHA256 hash as key
 	sum := sha256.Sum256([]byte(keyStr))
 	key := make([]byte, len(sum))
 	copy(key, sum[:])
-	return key, nil
+	return key
 }
 
 // ParseRSAPrivateKeyFromPEM parses RSA private key from PEM format
@@ -165,7 +241,7 @@ func normalizeAESKey(raw []byte) ([]byte, bool) {
 }
 
 func (cs *CryptoService) HasDataKey() bool {
-	return len(cs.dataKey) > 0
+	return cs.ActiveKeyID() != ""
 }
 
 func (cs *CryptoService) GetPublicKeyPEM() string {
@@ -193,7 +269,11 @@ func (cs *CryptoService) EncryptForStorage(plaintext string, aadParts ...string)
 		return plaintext, nil
 	}
 
-	block, err := aes.NewCipher(cs.dataKey)
+	cs.mu.RLock()
+	kid, key := cs.activeKID, cs.dataKeys[cs.activeKID]
+	cs.mu.RUnlock()
+
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return "", err
 	}
@@ -211,7 +291,7 @@ func (cs *CryptoService) EncryptForStorage(plaintext string, aadParts ...string)
 	aad := composeAAD(aadParts)
 	ciphertext := gcm.Seal(nil, nonce, []byte(plaintext), aad)
 
-	return storagePrefix +
+	return keyedPrefix + kid + storageDelimiter +
 		base64.StdEncoding.EncodeToString(nonce) + storageDelimiter +
 		base64.StdEncoding.EncodeToString(ciphertext), nil
 }
@@ -227,43 +307,124 @@ func (cs *CryptoService) DecryptFromStorage(value string, aadParts ...string) (s
 		return "", errors.New("data not encrypted")
 	}
 
-	payload := strings.TrimPrefix(value, storagePrefix)
-	parts := strings.SplitN(payload, storageDelimiter, 2)
-	if len(parts) != 2 {
-		return "", errors.New("invalid encrypted data format")
+	kid, nonceStr, ciphertextStr, err := splitStorageValue(value)
+	if err != nil {
+		return "", err
 	}
 
-	nonce, err := base64.StdEncoding.DecodeString(parts[0])
+	nonce, err := base64.StdEncoding.DecodeString(nonceStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode nonce: %w", err)
 	}
 
-	ciphertext, err := base64.StdEncoding.DecodeString(parts[1])
+	ciphertext, err := base64.StdEncoding.DecodeString(ciphertextStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode ciphertext: %w", err)
 	}
 
-	block, err := aes.NewCipher(cs.dataKey)
+	cs.mu.RLock()
+	var candidates [][]byte
+	if kid != "" {
+		key, ok := cs.dataKeys[kid]
+		if !ok {
+			cs.mu.RUnlock()
+			return "", fmt.Errorf("data key %s not loaded", kid)
+		}
+		candidates = [][]byte{key}
+	} else {
+		// Legacy values carry no key ID, try the active key first
+		candidates = append(candidates, cs.dataKeys[cs.activeKID])
+		for id, key := range cs.dataKeys {
+			if id != cs.activeKID {
+				candidates = append(candidates, key)
+			}
+		}
+	}
+	cs.mu.RUnlock()
+
+	aad := composeAAD(aadParts)
+	for _, key := range candidates {
+		plaintext, openErr := openGCM(key, nonce, ciphertext, aad)
+		if openErr == nil {
+			return string(plaintext), nil
+		}
+		err = openErr
+	}
+	return "", fmt.Errorf("decryption failed: %w", err)
+}
+
+// openGCM decrypts an AES-GCM ciphertext
+func openGCM(key, nonce, ciphertext, aad []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if len(nonce) != gcm.NonceSize() {
-		return "", fmt.Errorf("invalid nonce length: expected %d, got %d", gcm.NonceSize(), len(nonce))
+		return nil, fmt.Errorf("invalid nonce length: expected %d, got %d", gcm.NonceSize(), len(nonce))
 	}
 
-	aad := composeAAD(aadParts)
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, aad)
+	return gcm.Open(nil, nonce, ciphertext, aad)
+}
+
+// splitStorageValue splits a stored ciphertext into key ID (empty for legacy values), nonce and ciphertext
+func splitStorageValue(value string) (kid, nonce, ciphertext string, err error) {
+	switch {
+	case strings.HasPrefix(value, keyedPrefix):
+		parts := strings.SplitN(strings.TrimPrefix(value, keyedPrefix), storageDelimiter, 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return "", "", "", errors.New("invalid encrypted data format")
+		}
+		return parts[0], parts[1], parts[2], nil
+	case strings.HasPrefix(value, legacyPrefix):
+		parts := strings.SplitN(strings.TrimPrefix(value, legacyPrefix), storageDelimiter, 2)
+		if len(parts) != 2 {
+			return "", "", "", errors.New("invalid encrypted data format")
+		}
+		return "", parts[0], parts[1], nil
+	}
+	return "", "", "", errors.New("unsupported encrypted data version")
+}
+
+// StorageKeyID returns the key ID embedded in a stored ciphertext, empty for legacy or plaintext values
+func StorageKeyID(value string) string {
+	if !strings.HasPrefix(value, keyedPrefix) {
+		return ""
+	}
+	kid, _, _, err := splitStorageValue(value)
 	if err != nil {
-		return "", fmt.Errorf("decryption failed: %w", err)
+		return ""
 	}
+	return kid
+}
 
-	return string(plaintext), nil
+// NeedsReencryption reports whether a stored value is plaintext or not encrypted with the active key
+func (cs *CryptoService) NeedsReencryption(value string) bool {
+	if value == "" {
+		return false
+	}
+	return StorageKeyID(value) != cs.ActiveKeyID()
+}
+
+// ReencryptForStorage decrypts a stored value with whichever key wrote it and encrypts it with the active key
+// Plaintext values are encrypted
+func (cs *CryptoService) ReencryptForStorage(value string) (string, error) {
+	if !cs.NeedsReencryption(value) {
+		return value, nil
+	}
+	plaintext := value
+	if isEncryptedStorageValue(value) {
+		var err error
+		if plaintext, err = cs.DecryptFromStorage(value); err != nil {
+			return "", err
+		}
+	}
+	return cs.EncryptForStorage(plaintext)
 }
 
 func (cs *CryptoService) IsEncryptedStorageValue(value string) bool {
@@ -278,7 +439,7 @@ func composeAAD(parts []string) []byte {
 }
 
 func isEncryptedStorageValue(value string) bool {
-	return strings.HasPrefix(value, storagePrefix)
+	return strings.HasPrefix(value, keyedPrefix) || strings.HasPrefix(value, legacyPrefix)
 }
 
 func (cs *CryptoService) DecryptPayload(payload *EncryptedPayload) ([]byte, error) {
@@ -0,0 +1,115 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// staticKeyProvider test key provider
+type staticKeyProvider struct {
+	active   string
+	previous []string
+}
+
+func (p *staticKeyProvider) Name() string { return "static" }
+
+func (p *staticKeyProvider) DataKeys() (string, []string, error) {
+	return p.active, p.previous, nil
+}
+
+func newTestCryptoService(t *testing.T, provider KeyProvider) *CryptoService {
+	t.Helper()
+	privPEM, _, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvRSAPrivateKey, privPEM)
+	cs, err := NewCryptoServiceWithProvider(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cs
+}
+
+// TestDataKeyRotation Test key IDs in ciphertexts and decryption with previous keys
+func TestDataKeyRotation(t *testing.T) {
+	oldKey, _ := GenerateDataKey()
+	newKey, _ := GenerateDataKey()
+	provider := &staticKeyProvider{active: oldKey}
+	cs := newTestCryptoService(t, provider)
+	oldKID := cs.ActiveKeyID()
+
+	enc, err := cs.EncryptForStorage("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if StorageKeyID(enc) != oldKID || !strings.HasPrefix(enc, keyedPrefix) {
+		t.Fatalf("ciphertext should carry key ID %s: %s", oldKID, enc)
+	}
+
+	// Rotate: new active key, old key kept for decryption
+	provider.active, provider.previous = newKey, []string{oldKey}
+	if err := cs.ReloadDataKeys(); err != nil {
+		t.Fatal(err)
+	}
+	if cs.ActiveKeyID() == oldKID || len(cs.KeyIDs()) != 2 {
+		t.Fatalf("expected new active key and two loaded keys, got %s %v", cs.ActiveKeyID(), cs.KeyIDs())
+	}
+	if plain, err := cs.DecryptFromStorage(enc); err != nil || plain != "secret" {
+		t.Fatalf("old ciphertext should decrypt with previous key: %q %v", plain, err)
+	}
+	if !cs.NeedsReencryption(enc) || !cs.NeedsReencryption("plaintext") {
+		t.Error("values not written with the active key need re-encryption")
+	}
+
+	rotated, err := cs.ReencryptForStorage(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if StorageKeyID(rotated) != cs.ActiveKeyID() || cs.NeedsReencryption(rotated) {
+		t.Errorf("re-encrypted value should use the active key: %s", rotated)
+	}
+
+	// Dropping the old key makes its ciphertexts unreadable
+	provider.previous = nil
+	if err := cs.ReloadDataKeys(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cs.DecryptFromStorage(enc); err == nil {
+		t.Error("ciphertext of a removed key should not decrypt")
+	}
+	if plain, err := cs.DecryptFromStorage(rotated); err != nil || plain != "secret" {
+		t.Errorf("rotated value should decrypt: %q %v", plain, err)
+	}
+}
+
+// TestLegacyCiphertext Test that values written before key IDs still decrypt
+func TestLegacyCiphertext(t *testing.T) {
+	key, _ := GenerateDataKey()
+	cs := newTestCryptoService(t, &staticKeyProvider{active: key})
+
+	enc, _ := cs.EncryptForStorage("legacy")
+	_, nonce, ciphertext, _ := splitStorageValue(enc)
+	legacy := legacyPrefix + nonce + storageDelimiter + ciphertext
+
+	if plain, err := cs.DecryptFromStorage(legacy); err != nil || plain != "legacy" {
+		t.Fatalf("legacy value should decrypt: %q %v", plain, err)
+	}
+	if StorageKeyID(legacy) != "" || !cs.NeedsReencryption(legacy) {
+		t.Error("legacy values have no key ID and need re-encryption")
+	}
+}
+
+// TestFileKeyProvider Test key file parsing
+func TestFileKeyProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("# data keys\nnew-key\n\nold-key\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	active, previous, err := (&FileKeyProvider{Path: path}).DataKeys()
+	if err != nil || active != "new-key" || len(previous) != 1 || previous[0] != "old-key" {
+		t.Errorf("unexpected keys: %q %v %v", active, previous, err)
+	}
+}
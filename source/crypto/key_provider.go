@@ -0,0 +1,175 @@
+package crypto
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Key provider environment variable names
+const (
+	EnvDataKeyProvider  = "DATA_KEY_PROVIDER"   // env (default), file or vault
+	EnvDataKeyFile      = "DATA_KEY_FILE"       // file provider: active key on the first line, previous keys below
+	EnvVaultAddr        = "VAULT_ADDR"          // vault provider: server address, e.g. https://vault.example.com:8200
+	EnvVaultToken       = "VAULT_TOKEN"         // vault provider: access token
+	EnvVaultDataKeyPath = "VAULT_DATA_KEY_PATH" // vault provider: secret path, e.g. secret/data/nofx
+)
+
+// KeyProvider supplies the data encryption keys, so the master key does not have to live in .env
+type KeyProvider interface {
+	Name() string
+	// DataKeys returns the key for new ciphertexts and older keys still accepted for decryption
+	DataKeys() (active string, previous []string, err error)
+}
+
+// NewKeyProviderFromEnv creates the key provider selected by DATA_KEY_PROVIDER
+func NewKeyProviderFromEnv() (KeyProvider, error) {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvDataKeyProvider))) {
+	case "", "env":
+		return EnvKeyProvider{}, nil
+	case "file":
+		path := os.Getenv(EnvDataKeyFile)
+		if path == "" {
+			return nil, fmt.Errorf("%s=file requires %s", EnvDataKeyProvider, EnvDataKeyFile)
+		}
+		return &FileKeyProvider{Path: path}, nil
+	case "vault":
+		p := &VaultKeyProvider{
+			Addr:  os.Getenv(EnvVaultAddr),
+			Token: os.Getenv(EnvVaultToken),
+			Path:  os.Getenv(EnvVaultDataKeyPath),
+		}
+		if p.Addr == "" || p.Token == "" || p.Path == "" {
+			return nil, fmt.Errorf("%s=vault requires %s, %s and %s", EnvDataKeyProvider, EnvVaultAddr, EnvVaultToken, EnvVaultDataKeyPath)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unknown %s: %s (valid: env, file, vault)", EnvDataKeyProvider, os.Getenv(EnvDataKeyProvider))
+	}
+}
+
+// splitKeyList splits a comma or newline separated key list
+func splitKeyList(s string) []string {
+	var keys []string
+	for _, k := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// EnvKeyProvider reads DATA_ENCRYPTION_KEY and DATA_ENCRYPTION_PREVIOUS_KEYS
+type EnvKeyProvider struct{}
+
+func (EnvKeyProvider) Name() string { return "env" }
+
+func (EnvKeyProvider) DataKeys() (string, []string, error) {
+	active := strings.TrimSpace(os.Getenv(EnvDataEncryptionKey))
+	if active == "" {
+		return "", nil, fmt.Errorf("environment variable %s not set, please configure data encryption key in .env", EnvDataEncryptionKey)
+	}
+	return active, splitKeyList(os.Getenv(EnvPreviousDataKeys)), nil
+}
+
+// FileKeyProvider reads keys from a file, one per line: the active key first, then previous keys
+// Lines starting with # are comments. The file is read again on every reload, so keys can be
+// rotated without a restart
+type FileKeyProvider struct {
+	Path string
+}
+
+func (p *FileKeyProvider) Name() string { return "file" }
+
+func (p *FileKeyProvider) DataKeys() (string, []string, error) {
+	data, err := os.ReadFile(p.Path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("key file %s contains no key", p.Path)
+	}
+	return keys[0], keys[1:], nil
+}
+
+// VaultKeyProvider reads keys from a Vault-compatible KV HTTP API
+// The secret holds "active_key" and optionally "previous_keys" (comma-separated or a list),
+// both KV v1 and KV v2 response layouts are accepted
+type VaultKeyProvider struct {
+	Addr   string
+	Token  string
+	Path   string // e.g. secret/data/nofx (KV v2) or secret/nofx (KV v1)
+	Client *http.Client
+}
+
+func (p *VaultKeyProvider) Name() string { return "vault" }
+
+func (p *VaultKeyProvider) DataKeys() (string, []string, error) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	url := strings.TrimRight(p.Addr, "/") + "/v1/" + strings.TrimLeft(p.Path, "/")
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.Token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", nil, fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("vault returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", nil, fmt.Errorf("failed to parse vault response: %w", err)
+	}
+	fields := result.Data
+	if nested, ok := fields["data"]; ok {
+		// KV v2 wraps the secret in data.data
+		fields = nil
+		if err := json.Unmarshal(nested, &fields); err != nil {
+			return "", nil, fmt.Errorf("failed to parse vault secret: %w", err)
+		}
+	}
+
+	var active string
+	if err := json.Unmarshal(fields["active_key"], &active); err != nil || active == "" {
+		return "", nil, fmt.Errorf("vault secret %s has no active_key", p.Path)
+	}
+
+	var previous []string
+	if raw, ok := fields["previous_keys"]; ok {
+		var list string
+		if json.Unmarshal(raw, &list) == nil {
+			previous = splitKeyList(list)
+		} else if err := json.Unmarshal(raw, &previous); err != nil {
+			return "", nil, fmt.Errorf("vault secret %s: previous_keys must be a string or a list", p.Path)
+		}
+	}
+	return active, previous, nil
+}
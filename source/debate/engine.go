@@ -9,6 +9,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
 	"nofx/kernel"
 	"nofx/logger"
 	"nofx/market"
@@ -22,6 +24,16 @@ type TraderExecutor interface {
 	GetBalance() (map[string]interface{}, error)
 }
 
+// sessionLog returns a log entry tagged with the debate ID, and with the trader ID when the
+// debate auto-executes on a trader so its lines also show up in that trader's log
+func sessionLog(session *store.DebateSessionWithDetails) *logrus.Entry {
+	entry := logger.ForDebate(session.ID)
+	if session.TraderID != "" {
+		entry = entry.WithField(logger.FieldTraderID, session.TraderID)
+	}
+	return entry
+}
+
 // DebateEngine orchestrates AI debates using strategy-based market context
 type DebateEngine struct {
 	debateStore   *store.DebateStore
@@ -97,6 +109,10 @@ func (e *DebateEngine) InitializeClients(participants []*store.DebateParticipant
 			client = mcp.NewGrokClient()
 		case "kimi":
 			client = mcp.NewKimiClient()
+		case "ollama":
+			client = mcp.NewOllamaClient()
+		case "local":
+			client = mcp.NewLocalClient()
 		default:
 			client = mcp.New()
 		}
@@ -126,8 +142,8 @@ func (e *DebateEngine) StartDebate(sessionID string) error {
 		return fmt.Errorf("need at least 2 participants")
 	}
 
-	// Initialize AI clients
-	if err := e.InitializeClients(session.Participants); err != nil {
+	// Initialize AI clients (the moderator's too, when the session has one)
+	if err := e.InitializeClients(sessionSpeakers(session)); err != nil {
 		return fmt.Errorf("failed to initialize clients: %w", err)
 	}
 
@@ -155,9 +171,10 @@ func (e *DebateEngine) StartDebate(sessionID string) error {
 
 // runDebate runs the actual debate rounds
 func (e *DebateEngine) runDebate(session *store.DebateSessionWithDetails, strategyConfig *store.StrategyConfig) {
+	log := sessionLog(session)
 	defer func() {
 		if r := recover(); r != nil {
-			logger.Errorf("Debate panic recovered: %v", r)
+			log.Errorf("Debate panic recovered: %v", r)
 			e.debateStore.UpdateSessionStatus(session.ID, store.DebateStatusCancelled)
 			if e.OnError != nil {
 				e.OnError(session.ID, fmt.Errorf("debate panic: %v", r))
@@ -171,7 +188,7 @@ func (e *DebateEngine) runDebate(session *store.DebateSessionWithDetails, strate
 	// Build market context using strategy config
 	ctx, err := e.buildMarketContext(session, strategyEngine)
 	if err != nil {
-		logger.Errorf("Failed to build market context: %v", err)
+		log.Errorf("Failed to build market context: %v", err)
 		e.debateStore.UpdateSessionStatus(session.ID, store.DebateStatusCancelled)
 		if e.OnError != nil {
 			e.OnError(session.ID, err)
@@ -179,76 +196,52 @@ func (e *DebateEngine) runDebate(session *store.DebateSessionWithDetails, strate
 		return
 	}
 
-	// Build system prompt based on strategy (same as AI Test)
-	baseSystemPrompt := strategyEngine.BuildSystemPrompt(1000.0, session.PromptVariant)
-
-	// Build user prompt with market data (OI ranking data is included via ctx.OIRankingData)
-	userPrompt := strategyEngine.BuildUserPrompt(ctx)
-
-	// Run debate rounds
-	var allMessages []*store.DebateMessage
-	for round := 1; round <= session.MaxRounds; round++ {
-		logger.Infof("Starting debate round %d/%d for session %s", round, session.MaxRounds, session.ID)
-
-		if e.OnRoundStart != nil {
-			e.OnRoundStart(session.ID, round)
-		}
-
-		e.debateStore.UpdateSessionRound(session.ID, round)
-
-		// Get response from each participant
-		for i, participant := range session.Participants {
-			logger.Infof("[Debate] Round %d - Getting response from participant %d/%d: %s (%s)",
-				round, i+1, len(session.Participants), participant.AIModelName, participant.Provider)
-
-			// Build personality-enhanced system prompt
-			systemPrompt := e.buildDebateSystemPrompt(baseSystemPrompt, participant, round, session.MaxRounds)
-
-			// Build debate user prompt with previous messages
-			debateUserPrompt := e.buildDebateUserPrompt(userPrompt, allMessages, participant, round)
-
-			// Get AI response
-			msg, err := e.getParticipantResponse(session, participant, systemPrompt, debateUserPrompt, round)
-			if err != nil {
-				logger.Errorf("[Debate] Failed to get response from %s (%s): %v", participant.AIModelName, participant.Provider, err)
-				// Send error event to frontend
-				if e.OnError != nil {
-					e.OnError(session.ID, fmt.Errorf("%s failed: %v", participant.AIModelName, err))
-				}
-				continue
-			}
+	// Failures are recorded on the session and reported through OnError
+	e.debateRounds(session, strategyEngine, ctx)
+}
 
-			logger.Infof("[Debate] Got response from %s: %d chars, action=%s, confidence=%d%%",
-				participant.AIModelName, len(msg.Content), msg.Decision.Action, msg.Confidence)
+// debateRounds runs the debate rounds and the voting phase of a session over ctx, then stores and
+// returns the consensus (primary decision first for single-symbol sessions, plus every per-coin decision).
+// When no participant voted the session is marked failed and the error returned.
+func (e *DebateEngine) debateRounds(session *store.DebateSessionWithDetails, strategyEngine *kernel.StrategyEngine, ctx *kernel.Context) (*store.DebateDecision, []*store.DebateDecision, error) {
+	log := sessionLog(session)
 
-			// Save message
-			if err := e.debateStore.AddMessage(msg); err != nil {
-				logger.Errorf("Failed to save message: %v", err)
-			}
+	// Build system prompt based on strategy (same as AI Test), sized to the context's equity
+	baseSystemPrompt := strategyEngine.BuildSystemPrompt(ctx.Account.TotalEquity, session.PromptVariant)
 
-			allMessages = append(allMessages, msg)
+	// Build user prompt with market data (OI ranking data is included via ctx.OIRankingData)
+	userPrompt := strategyEngine.BuildUserPrompt(ctx)
 
-			if e.OnMessage != nil {
-				e.OnMessage(session.ID, msg)
-			}
-		}
+	moderator := moderatorParticipant(session)
 
-		if e.OnRoundEnd != nil {
-			e.OnRoundEnd(session.ID, round)
-		}
+	// Optionally trust historically accurate participants more
+	var weights map[string]float64
+	if session.WeightByAccuracy {
+		weights = e.accuracyWeights(session)
 	}
 
-	// Voting phase
-	logger.Infof("Starting voting phase for session %s", session.ID)
-	e.debateStore.UpdateSessionStatus(session.ID, store.DebateStatusVoting)
-
-	votes, err := e.collectVotes(session, strategyEngine, allMessages)
+	live := &liveRun{e: e, session: session}
+	result, err := e.runRounds(roundsRequest{
+		Session:      session,
+		Moderator:    moderator,
+		SystemPrompt: baseSystemPrompt,
+		UserPrompt:   userPrompt,
+		Weights:      weights,
+		Caller:       live,
+		Observer:     live,
+	})
 	if err != nil {
-		logger.Errorf("Failed to collect votes: %v", err)
+		log.Errorf("Failed to collect votes: %v", err)
+		e.debateStore.UpdateSessionStatus(session.ID, store.DebateStatusFailed)
+		if e.OnError != nil {
+			e.OnError(session.ID, err)
+		}
+		return nil, nil, err
 	}
 
-	// Determine multi-coin consensus
-	allDecisions := e.determineMultiCoinConsensus(votes)
+	// Remember what each voter recommended so it can be scored against the market later
+	e.recordOutcomes(session, result.Votes, result.Voters, ctx)
+	allDecisions := result.Consensus
 
 	// For backward compatibility, also set single consensus
 	var primaryConsensus *store.DebateDecision
@@ -284,12 +277,15 @@ func (e *DebateEngine) runDebate(session *store.DebateSessionWithDetails, strate
 		e.OnConsensus(session.ID, primaryConsensus)
 	}
 
-	logger.Infof("Debate %s completed. %d consensus decisions, primary: %s %s (confidence: %d%%)",
+	log.Infof("Debate %s completed. %d consensus decisions, primary: %s %s (confidence: %d%%)",
 		session.ID, len(allDecisions), primaryConsensus.Action, primaryConsensus.Symbol, primaryConsensus.Confidence)
+
+	return primaryConsensus, allDecisions, nil
 }
 
 // buildMarketContext builds the market context using strategy engine
 func (e *DebateEngine) buildMarketContext(session *store.DebateSessionWithDetails, strategyEngine *kernel.StrategyEngine) (*kernel.Context, error) {
+	log := sessionLog(session)
 	config := strategyEngine.GetConfig()
 
 	// Get candidate coins
@@ -315,7 +311,7 @@ func (e *DebateEngine) buildMarketContext(session *store.DebateSessionWithDetail
 	for _, coin := range candidates {
 		data, err := market.GetWithTimeframes(coin.Symbol, timeframes, primaryTimeframe, klineCount)
 		if err != nil {
-			logger.Warnf("Failed to get market data for %s: %v", coin.Symbol, err)
+			log.Warnf("Failed to get market data for %s: %v", coin.Symbol, err)
 			continue
 		}
 		marketDataMap[coin.Symbol] = data
@@ -341,6 +337,15 @@ func (e *DebateEngine) buildMarketContext(session *store.DebateSessionWithDetail
 	// Fetch Price ranking data (market-wide gainers/losers)
 	priceRankingData := strategyEngine.FetchPriceRankingData()
 
+	// Fetch derivatives data (liquidations, long/short ratio, OI vs market cap)
+	derivativesDataMap := strategyEngine.FetchDerivativesDataBatch(symbols)
+
+	// Fetch order book depth (spread, ±1% depth, imbalance)
+	orderBookMap := strategyEngine.FetchOrderBookBatch(symbols)
+
+	// Trading sessions of stock/forex/commodity symbols
+	marketSessions := strategyEngine.BuildMarketSessions(symbols, time.Now())
+
 	// Build context
 	ctx := &kernel.Context{
 		CurrentTime:    time.Now().UTC().Format("2006-01-02 15:04:05 UTC"),
@@ -364,6 +369,9 @@ func (e *DebateEngine) buildMarketContext(session *store.DebateSessionWithDetail
 		OIRankingData:      oiRankingData,
 		NetFlowRankingData: netFlowRankingData,
 		PriceRankingData:   priceRankingData,
+		DerivativesDataMap: derivativesDataMap,
+		OrderBookMap:       orderBookMap,
+		MarketSessions:     marketSessions,
 	}
 
 	return ctx, nil
@@ -371,8 +379,8 @@ func (e *DebateEngine) buildMarketContext(session *store.DebateSessionWithDetail
 
 // buildDebateSystemPrompt enhances the base strategy prompt with debate-specific instructions
 func (e *DebateEngine) buildDebateSystemPrompt(basePrompt string, participant *store.DebateParticipant, round, maxRounds int) string {
-	personality := getPersonalityDescription(participant.Personality)
-	emoji := store.PersonalityEmojis[participant.Personality]
+	personality := participantRole(participant)
+	emoji := participantEmoji(participant)
 
 	debateInstructions := fmt.Sprintf(`
 ## DEBATE MODE - ROUND %d/%d
@@ -428,7 +436,7 @@ Then output your decisions in STRICT JSON ARRAY format (can include multiple coi
 
 ---
 
-`, round, maxRounds, emoji, participant.Personality, personality)
+`, round, maxRounds, emoji, participantLabel(participant), personality)
 
 	return debateInstructions + basePrompt
 }
@@ -463,19 +471,14 @@ func (e *DebateEngine) buildDebateUserPrompt(baseUserPrompt string, previousMess
 	return sb.String()
 }
 
-// getParticipantResponse gets a response from a participant with timeout
-func (e *DebateEngine) getParticipantResponse(
-	session *store.DebateSessionWithDetails,
-	participant *store.DebateParticipant,
-	systemPrompt, userPrompt string,
-	round int,
-) (*store.DebateMessage, error) {
+// callWithTimeout calls a participant's AI client, giving up after 60 seconds
+func (e *DebateEngine) callWithTimeout(participant *store.DebateParticipant, systemPrompt, userPrompt string) (string, error) {
 	e.clientsMu.RLock()
 	client, ok := e.clients[participant.AIModelID]
 	e.clientsMu.RUnlock()
 
 	if !ok {
-		return nil, fmt.Errorf("client not found for %s", participant.AIModelID)
+		return "", fmt.Errorf("client not found for %s", participant.AIModelID)
 	}
 
 	// Use channel-based timeout (60 seconds per AI call)
@@ -497,12 +500,75 @@ func (e *DebateEngine) getParticipantResponse(
 		response = res.response
 		err = res.err
 	case <-time.After(60 * time.Second):
-		return nil, fmt.Errorf("AI call timeout after 60s for %s", participant.AIModelName)
+		return "", fmt.Errorf("AI call timeout after 60s for %s", participant.AIModelName)
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("AI call failed: %w", err)
+		return "", fmt.Errorf("AI call failed: %w", err)
+	}
+	return response, nil
+}
+
+// liveRun calls participants through the engine's AI clients and stores and streams every step of a session
+type liveRun struct {
+	e       *DebateEngine
+	session *store.DebateSessionWithDetails
+}
+
+func (r *liveRun) CallTurn(participant *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error) {
+	return r.e.callWithTimeout(participant, systemPrompt, userPrompt)
+}
+
+func (r *liveRun) roundStarted(round int) {
+	if r.e.OnRoundStart != nil {
+		r.e.OnRoundStart(r.session.ID, round)
+	}
+	r.e.debateStore.UpdateSessionRound(r.session.ID, round)
+}
+
+func (r *liveRun) roundEnded(round int) {
+	if r.e.OnRoundEnd != nil {
+		r.e.OnRoundEnd(r.session.ID, round)
+	}
+}
+
+func (r *liveRun) votingStarted() {
+	r.e.debateStore.UpdateSessionStatus(r.session.ID, store.DebateStatusVoting)
+}
+
+func (r *liveRun) messageAdded(msg *store.DebateMessage) {
+	if err := r.e.debateStore.AddMessage(msg); err != nil {
+		sessionLog(r.session).Errorf("Failed to save message: %v", err)
+	}
+	if r.e.OnMessage != nil {
+		r.e.OnMessage(r.session.ID, msg)
+	}
+}
+
+func (r *liveRun) voteCast(vote *store.DebateVote) {
+	if err := r.e.debateStore.AddVote(vote); err != nil {
+		sessionLog(r.session).Errorf("Failed to save vote: %v", err)
+	}
+	if r.e.OnVote != nil {
+		r.e.OnVote(r.session.ID, vote)
 	}
+}
+
+// turnFailed reports failed debate turns to the frontend; failed votes only show up in the log
+func (r *liveRun) turnFailed(participant *store.DebateParticipant, round int, err error) {
+	if round == 0 || r.e.OnError == nil {
+		return
+	}
+	name := participant.AIModelName
+	if participant.Personality == store.PersonalityModerator {
+		name = "moderator"
+	}
+	r.e.OnError(r.session.ID, fmt.Errorf("%s failed: %v", name, err))
+}
+
+// newDebateMessage parses a participant's debate turn into a message
+func newDebateMessage(session *store.DebateSessionWithDetails, participant *store.DebateParticipant, round int, response string) *store.DebateMessage {
+	log := sessionLog(session)
 
 	// Parse multiple decisions from response
 	decisions, confidence := parseDecisions(response)
@@ -511,7 +577,7 @@ func (e *DebateEngine) getParticipantResponse(
 	if session.Symbol != "" {
 		for _, d := range decisions {
 			if d.Symbol == "" || d.Symbol != session.Symbol {
-				logger.Warnf("[Debate] Fixing invalid symbol in message '%s' -> '%s'", d.Symbol, session.Symbol)
+				log.Warnf("[Debate] Fixing invalid symbol in message '%s' -> '%s'", d.Symbol, session.Symbol)
 				d.Symbol = session.Symbol
 			}
 		}
@@ -529,7 +595,7 @@ func (e *DebateEngine) getParticipantResponse(
 		messageType = "rebuttal"
 	}
 
-	msg := &store.DebateMessage{
+	return &store.DebateMessage{
 		SessionID:   session.ID,
 		Round:       round,
 		AIModelID:   participant.AIModelID,
@@ -542,60 +608,11 @@ func (e *DebateEngine) getParticipantResponse(
 		Decisions:   decisions,
 		Confidence:  confidence,
 	}
-
-	return msg, nil
-}
-
-// collectVotes collects final votes from all participants
-func (e *DebateEngine) collectVotes(session *store.DebateSessionWithDetails, strategyEngine *kernel.StrategyEngine, allMessages []*store.DebateMessage) ([]*store.DebateVote, error) {
-	var votes []*store.DebateVote
-
-	// Build voting context
-	baseSystemPrompt := strategyEngine.BuildSystemPrompt(1000.0, session.PromptVariant)
-
-	for _, participant := range session.Participants {
-		vote, err := e.getParticipantVote(session, participant, baseSystemPrompt, allMessages)
-		if err != nil {
-			logger.Errorf("Failed to get vote from %s: %v", participant.AIModelName, err)
-			continue
-		}
-
-		if err := e.debateStore.AddVote(vote); err != nil {
-			logger.Errorf("Failed to save vote: %v", err)
-		}
-
-		votes = append(votes, vote)
-
-		if e.OnVote != nil {
-			e.OnVote(session.ID, vote)
-		}
-	}
-
-	return votes, nil
 }
 
-// getParticipantVote gets a final vote from a participant (supports multi-coin)
-func (e *DebateEngine) getParticipantVote(
-	session *store.DebateSessionWithDetails,
-	participant *store.DebateParticipant,
-	baseSystemPrompt string,
-	allMessages []*store.DebateMessage,
-) (*store.DebateVote, error) {
-	e.clientsMu.RLock()
-	client, ok := e.clients[participant.AIModelID]
-	e.clientsMu.RUnlock()
-
-	if !ok {
-		return nil, fmt.Errorf("client not found for %s", participant.AIModelID)
-	}
-
-	systemPrompt := e.buildVotingSystemPrompt(baseSystemPrompt, participant)
-	userPrompt := e.buildVotingUserPrompt(allMessages)
-
-	response, err := client.CallWithMessages(systemPrompt, userPrompt)
-	if err != nil {
-		return nil, fmt.Errorf("AI call failed: %w", err)
-	}
+// newDebateVote parses a participant's final vote (supports multi-coin)
+func newDebateVote(session *store.DebateSessionWithDetails, participant *store.DebateParticipant, response string) *store.DebateVote {
+	log := sessionLog(session)
 
 	// Parse multi-coin votes
 	decisions, avgConfidence := parseDecisions(response)
@@ -605,7 +622,7 @@ func (e *DebateEngine) getParticipantVote(
 	if session.Symbol != "" {
 		for _, d := range decisions {
 			if d.Symbol == "" || d.Symbol != session.Symbol {
-				logger.Warnf("[Debate] Fixing invalid symbol '%s' -> '%s'", d.Symbol, session.Symbol)
+				log.Warnf("[Debate] Fixing invalid symbol '%s' -> '%s'", d.Symbol, session.Symbol)
 				d.Symbol = session.Symbol
 			}
 		}
@@ -630,11 +647,13 @@ func (e *DebateEngine) getParticipantVote(
 	}
 
 	vote := &store.DebateVote{
-		SessionID:   session.ID,
-		AIModelID:   participant.AIModelID,
-		AIModelName: participant.AIModelName,
-		Decisions:   decisions,
-		Confidence:  avgConfidence,
+		SessionID:     session.ID,
+		AIModelID:     participant.AIModelID,
+		AIModelName:   participant.AIModelName,
+		ParticipantID: participant.ID,
+		Personality:   participant.Personality,
+		Decisions:     decisions,
+		Confidence:    avgConfidence,
 	}
 
 	// Set backward-compatible fields from primary decision
@@ -649,18 +668,18 @@ func (e *DebateEngine) getParticipantVote(
 		vote.Confidence = primaryDecision.Confidence
 	}
 
-	logger.Infof("[Debate] Vote from %s: %d decisions", participant.AIModelName, len(decisions))
+	log.Infof("[Debate] Vote from %s: %d decisions", participant.AIModelName, len(decisions))
 	for _, d := range decisions {
-		logger.Infof("[Debate]   - %s: %s (confidence: %d%%)", d.Symbol, d.Action, d.Confidence)
+		log.Infof("[Debate]   - %s: %s (confidence: %d%%)", d.Symbol, d.Action, d.Confidence)
 	}
 
-	return vote, nil
+	return vote
 }
 
 // buildVotingSystemPrompt builds the system prompt for voting
 func (e *DebateEngine) buildVotingSystemPrompt(basePrompt string, participant *store.DebateParticipant) string {
-	personality := getPersonalityDescription(participant.Personality)
-	emoji := store.PersonalityEmojis[participant.Personality]
+	personality := participantRole(participant)
+	emoji := participantEmoji(participant)
 
 	return fmt.Sprintf(`## FINAL VOTE
 
@@ -698,7 +717,7 @@ You may vote differently from your earlier position if convinced by others' argu
 ---
 
 %s
-`, emoji, participant.Personality, personality, basePrompt)
+`, emoji, participantLabel(participant), personality, basePrompt)
 }
 
 // buildVotingUserPrompt builds the user prompt for voting
@@ -758,6 +777,12 @@ func (e *DebateEngine) determineConsensus(symbol string, votes []*store.DebateVo
 
 // determineMultiCoinConsensus determines consensus for all coins from votes
 func (e *DebateEngine) determineMultiCoinConsensus(votes []*store.DebateVote) []*store.DebateDecision {
+	return e.weightedMultiCoinConsensus(votes, nil)
+}
+
+// weightedMultiCoinConsensus determines consensus for all coins from votes, multiplying each vote's
+// confidence weight by its participantKey entry in weights (missing entries count as 1)
+func (e *DebateEngine) weightedMultiCoinConsensus(votes []*store.DebateVote, weights map[string]float64) []*store.DebateDecision {
 	if len(votes) == 0 {
 		return nil
 	}
@@ -804,6 +829,9 @@ func (e *DebateEngine) determineMultiCoinConsensus(votes []*store.DebateVote) []
 				if weight < 0.1 {
 					weight = 0.5 // Default weight for low confidence
 				}
+				if w, ok := weights[participantKey(vote.AIModelID, vote.Personality)]; ok {
+					weight *= w
+				}
 				ad.score += weight
 				ad.totalConf += d.Confidence
 				if d.Leverage > 0 {
@@ -839,6 +867,9 @@ func (e *DebateEngine) determineMultiCoinConsensus(votes []*store.DebateVote) []
 			if weight < 0.1 {
 				weight = 0.5 // Default weight for low confidence
 			}
+			if w, ok := weights[participantKey(vote.AIModelID, vote.Personality)]; ok {
+				weight *= w
+			}
 			ad.score += weight
 			ad.totalConf += vote.Confidence
 			if vote.Leverage > 0 {
@@ -937,11 +968,12 @@ func (e *DebateEngine) CancelDebate(sessionID string) error {
 }
 
 // ExecuteConsensus executes the consensus decision from a completed debate
-func (e *DebateEngine) ExecuteConsensus(sessionID string, executor TraderExecutor) error {
+func (e *DebateEngine) ExecuteConsensus(sessionID, traderID string, executor TraderExecutor) error {
 	session, err := e.debateStore.GetSessionWithDetails(sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to get session: %w", err)
 	}
+	log := sessionLog(session)
 
 	if session.Status != store.DebateStatusCompleted {
 		return fmt.Errorf("debate is not completed (status: %s)", session.Status)
@@ -973,20 +1005,20 @@ func (e *DebateEngine) ExecuteConsensus(sessionID string, executor TraderExecuto
 	}
 
 	// Debug log balance keys and values
-	logger.Infof("Debate execution - balance data: %+v", balance)
+	log.Infof("Debate execution - balance data: %+v", balance)
 
 	// Use available_balance for position sizing (not total equity)
 	availableBalance := 0.0
 	if avail, ok := balance["available_balance"].(float64); ok && avail > 0 {
 		availableBalance = avail
-		logger.Infof("Using available_balance: %.2f", availableBalance)
+		log.Infof("Using available_balance: %.2f", availableBalance)
 	} else if eq, ok := balance["total_equity"].(float64); ok && eq > 0 {
 		// Fallback to total_equity if available_balance not found
 		availableBalance = eq
-		logger.Infof("Fallback to total_equity: %.2f", availableBalance)
+		log.Infof("Fallback to total_equity: %.2f", availableBalance)
 	} else if wallet, ok := balance["wallet_balance"].(float64); ok && wallet > 0 {
 		availableBalance = wallet
-		logger.Infof("Fallback to wallet_balance: %.2f", availableBalance)
+		log.Infof("Fallback to wallet_balance: %.2f", availableBalance)
 	}
 
 	if availableBalance <= 0 {
@@ -1028,15 +1060,15 @@ func (e *DebateEngine) ExecuteConsensus(sessionID string, executor TraderExecuto
 		Reasoning:       fmt.Sprintf("Debate consensus: %s", session.FinalDecision.Reasoning),
 	}
 
-	logger.Infof("======== EXECUTING DEBATE CONSENSUS ========")
-	logger.Infof("Session ID: %s", sessionID)
-	logger.Infof("Symbol: %s", session.Symbol)
-	logger.Infof("Action: %s (from FinalDecision.Action: %s)", action, session.FinalDecision.Action)
-	logger.Infof("Position Size: %.2f USD", positionSizeUSD)
-	logger.Infof("Leverage: %dx", tradeDecision.Leverage)
-	logger.Infof("StopLoss: %.4f, TakeProfit: %.4f", stopLossPrice, takeProfitPrice)
-	logger.Infof("=============================================")
-	logger.Infof("Executing debate consensus: %s %s @ %.2f USD, leverage %dx",
+	log.Infof("======== EXECUTING DEBATE CONSENSUS ========")
+	log.Infof("Session ID: %s", sessionID)
+	log.Infof("Symbol: %s", session.Symbol)
+	log.Infof("Action: %s (from FinalDecision.Action: %s)", action, session.FinalDecision.Action)
+	log.Infof("Position Size: %.2f USD", positionSizeUSD)
+	log.Infof("Leverage: %dx", tradeDecision.Leverage)
+	log.Infof("StopLoss: %.4f, TakeProfit: %.4f", stopLossPrice, takeProfitPrice)
+	log.Infof("=============================================")
+	log.Infof("Executing debate consensus: %s %s @ %.2f USD, leverage %dx",
 		action, session.Symbol, positionSizeUSD, tradeDecision.Leverage)
 
 	// Execute
@@ -1056,6 +1088,11 @@ func (e *DebateEngine) ExecuteConsensus(sessionID string, executor TraderExecuto
 		return fmt.Errorf("trade execution failed: %w", err)
 	}
 
+	// Let the scorer attribute the trade's PnL to the participants once the position closes
+	if err := e.debateStore.MarkOutcomesExecuted(sessionID, session.Symbol, traderID, action, session.FinalDecision.ExecutedAt); err != nil {
+		log.Warnf("Failed to mark vote outcomes executed: %v", err)
+	}
+
 	return nil
 }
 
@@ -1078,6 +1115,38 @@ func getPersonalityDescription(personality store.DebatePersonality) string {
 	}
 }
 
+// participantRole describes a participant's debate role: its custom prompt or the built-in description,
+// followed by its risk bias when it has one
+func participantRole(p *store.DebateParticipant) string {
+	role := getPersonalityDescription(p.Personality)
+	if p.CustomPrompt != "" {
+		role = p.CustomPrompt
+	}
+	switch p.RiskBias {
+	case store.RiskBiasAggressive:
+		role += "\nRisk bias: aggressive - favour larger positions and higher leverage when the data supports the trade."
+	case store.RiskBiasConservative:
+		role += "\nRisk bias: conservative - favour smaller positions, lower leverage and tighter stops; prefer waiting over marginal trades."
+	}
+	return role
+}
+
+// participantLabel returns the display name of a participant's personality
+func participantLabel(p *store.DebateParticipant) string {
+	if p.PersonalityName != "" {
+		return p.PersonalityName
+	}
+	return string(p.Personality)
+}
+
+// participantEmoji returns the emoji of a participant's personality
+func participantEmoji(p *store.DebateParticipant) string {
+	if p.Emoji != "" {
+		return p.Emoji
+	}
+	return store.PersonalityEmojis[p.Personality]
+}
+
 // parseDecisions extracts multiple decisions from AI response using strict JSON parsing
 func parseDecisions(response string) ([]*store.DebateDecision, int) {
 	avgConfidence := 50
@@ -0,0 +1,146 @@
+package debate
+
+import (
+	"fmt"
+	"strings"
+
+	"nofx/store"
+)
+
+// moderatorParticipant returns the session's moderator, or nil when the session decides by voting
+func moderatorParticipant(session *store.DebateSessionWithDetails) *store.DebateParticipant {
+	if session.ConsensusMethod != store.ConsensusModerator || session.ModeratorAIModelID == "" {
+		return nil
+	}
+	return &store.DebateParticipant{
+		SessionID:   session.ID,
+		AIModelID:   session.ModeratorAIModelID,
+		AIModelName: "Moderator",
+		Personality: store.PersonalityModerator,
+		Color:       "#64748B",
+		SpeakOrder:  len(session.Participants),
+	}
+}
+
+// sessionSpeakers returns every participant that needs an AI client: the debaters plus the moderator
+func sessionSpeakers(session *store.DebateSessionWithDetails) []*store.DebateParticipant {
+	if moderator := moderatorParticipant(session); moderator != nil {
+		return append(append([]*store.DebateParticipant{}, session.Participants...), moderator)
+	}
+	return session.Participants
+}
+
+// newSummaryMessage wraps the moderator's round summary; it carries no trading decision
+func newSummaryMessage(session *store.DebateSessionWithDetails, moderator *store.DebateParticipant, round int, response string) *store.DebateMessage {
+	return &store.DebateMessage{
+		SessionID:   session.ID,
+		Round:       round,
+		AIModelID:   moderator.AIModelID,
+		AIModelName: moderator.AIModelName,
+		Provider:    moderator.Provider,
+		Personality: moderator.Personality,
+		MessageType: "moderator",
+		Content:     response,
+	}
+}
+
+// newVerdictMessage wraps the moderator's final decision and justification
+func newVerdictMessage(session *store.DebateSessionWithDetails, moderator *store.DebateParticipant, response string) *store.DebateMessage {
+	msg := newDebateMessage(session, moderator, session.MaxRounds, response)
+	msg.MessageType = "verdict"
+	return msg
+}
+
+// buildModeratorSystemPrompt builds the system prompt for the moderator's round summary
+func (e *DebateEngine) buildModeratorSystemPrompt(basePrompt string, round, maxRounds int) string {
+	return fmt.Sprintf(`## DEBATE MODERATOR - ROUND %d/%d SUMMARY
+
+You are %s the neutral moderator of a multi-AI market debate. You do not trade and you do not take sides.
+
+### Your Tasks:
+1. Summarise each participant's position and main arguments in this round, per coin
+2. Challenge every claim that is not backed by a concrete data point from the market data, naming the participant
+3. Point out contradictions between participants and between a participant's reasoning and its decision
+4. List the open questions the participants must answer in the next round
+
+### Output Format:
+<summary>
+- Positions and arguments per coin
+- Unsupported or contradicted claims (who said what, and why it is not supported)
+- Open questions for the next round
+</summary>
+
+Keep it concise and factual. Do not output trading decisions.
+
+---
+
+%s
+`, round, maxRounds, store.PersonalityEmojis[store.PersonalityModerator], basePrompt)
+}
+
+// buildModeratorUserPrompt lists the arguments of the round to be summarised
+func (e *DebateEngine) buildModeratorUserPrompt(allMessages []*store.DebateMessage, round int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## Round %d Arguments\n\n", round))
+	for _, msg := range allMessages {
+		if msg.Round != round || msg.Personality == store.PersonalityModerator {
+			continue
+		}
+		emoji := store.PersonalityEmojis[msg.Personality]
+		sb.WriteString(fmt.Sprintf("### %s %s (%s):\n%s\n\n", emoji, msg.AIModelName, msg.Personality, msg.Content))
+	}
+	sb.WriteString("Summarise this round and challenge unsupported claims.\n")
+	return sb.String()
+}
+
+// buildVerdictSystemPrompt builds the system prompt for the moderator's final decision
+func (e *DebateEngine) buildVerdictSystemPrompt(basePrompt string) string {
+	return fmt.Sprintf(`## DEBATE MODERATOR - FINAL VERDICT
+
+You are %s the moderator. The debate has concluded and you alone decide the final action for EVERY coin discussed.
+
+Weigh the arguments on their evidence, not on how many participants made them or how confident they sounded.
+Discount claims you challenged that were never backed by data. When the evidence is balanced, prefer "wait" or "hold".
+
+### CRITICAL: Output Format (MUST follow exactly)
+
+First justify your verdict:
+<justification>
+- For each coin: the decisive arguments, the rejected arguments and why
+</justification>
+
+Then output your decisions in STRICT JSON ARRAY format (one per coin):
+<decision>
+[
+  {"symbol": "BTCUSDT", "action": "open_long", "confidence": 75, "leverage": 5, "position_pct": 0.3, "stop_loss": 0.02, "take_profit": 0.04, "reasoning": "Why the bull case won"},
+  {"symbol": "ETHUSDT", "action": "wait", "confidence": 60, "reasoning": "Evidence balanced"}
+]
+</decision>
+
+### IMPORTANT: action field MUST be exactly one of:
+- "open_long", "open_short", "close_long", "close_short", "hold", "wait"
+
+stop_loss and take_profit are percentages as decimals (0.02 = 2%%), position_pct is the fraction of equity (0.1-1.0).
+
+---
+
+%s
+`, store.PersonalityEmojis[store.PersonalityModerator], basePrompt)
+}
+
+// buildVerdictUserPrompt gives the moderator the whole debate
+func (e *DebateEngine) buildVerdictUserPrompt(allMessages []*store.DebateMessage) string {
+	var sb strings.Builder
+	sb.WriteString("## Full Debate\n\n")
+	for _, msg := range allMessages {
+		emoji := store.PersonalityEmojis[msg.Personality]
+		sb.WriteString(fmt.Sprintf("### Round %d · %s %s (%s):\n", msg.Round, emoji, msg.AIModelName, msg.Personality))
+		if len(msg.Content) > 1500 {
+			sb.WriteString(msg.Content[:1500] + "...\n\n")
+		} else {
+			sb.WriteString(msg.Content + "\n\n")
+		}
+	}
+	sb.WriteString("Deliver your final verdict.\n")
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package debate
+
+import (
+	"fmt"
+	"strings"
+
+	"nofx/kernel"
+	"nofx/store"
+)
+
+// OfflineRequest a debate run entirely in memory, e.g. once per backtest decision bar
+type OfflineRequest struct {
+	ID            string // Used to tag log lines only
+	Participants  []*store.DebateParticipant
+	Moderator     *store.DebateParticipant // Optional: summarises each round and gives the verdict instead of a vote
+	MaxRounds     int
+	PromptVariant string
+	Strategy      *kernel.StrategyEngine
+	Context       *kernel.Context
+	Call          TurnCaller
+	Weights       map[string]float64 // Vote weight multipliers by model/personality, see AccuracyWeights (nil = unweighted)
+}
+
+// OfflineResult messages, votes and consensus of an in-memory debate
+type OfflineResult struct {
+	SystemPrompt string
+	UserPrompt   string
+	Messages     []*store.DebateMessage
+	Votes        []*store.DebateVote
+	Consensus    []*store.DebateDecision
+	Decisions    []kernel.Decision
+}
+
+// RunOffline runs the same rounds, moderation, vote and consensus as a stored debate session (see runRounds),
+// without touching the database. The run only fails when neither the moderator nor any participant decides.
+func RunOffline(req OfflineRequest) (*OfflineResult, error) {
+	if req.Context == nil || req.Strategy == nil || req.Call == nil {
+		return nil, fmt.Errorf("context, strategy and turn caller are required")
+	}
+	if len(req.Participants) < 2 {
+		return nil, fmt.Errorf("need at least 2 participants")
+	}
+
+	e := &DebateEngine{}
+	session := &store.DebateSessionWithDetails{
+		DebateSession: &store.DebateSession{
+			ID:            req.ID,
+			MaxRounds:     req.MaxRounds,
+			PromptVariant: req.PromptVariant,
+		},
+		Participants: req.Participants,
+	}
+	result := &OfflineResult{
+		SystemPrompt: req.Strategy.BuildSystemPrompt(req.Context.Account.TotalEquity, req.PromptVariant),
+		UserPrompt:   req.Strategy.BuildUserPrompt(req.Context),
+	}
+	rounds, err := e.runRounds(roundsRequest{
+		Session:      session,
+		Moderator:    req.Moderator,
+		SystemPrompt: result.SystemPrompt,
+		UserPrompt:   result.UserPrompt,
+		Weights:      req.Weights,
+		Caller:       req.Call,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Messages = rounds.Messages
+	result.Votes = rounds.Votes
+	result.Consensus = rounds.Consensus
+	result.Decisions = ConsensusToDecisions(result.Consensus, req.Context)
+	return result, nil
+}
+
+// Transcript renders the debate messages and votes as plain text
+func (r *OfflineResult) Transcript() string {
+	var sb strings.Builder
+	for _, msg := range r.Messages {
+		emoji := store.PersonalityEmojis[msg.Personality]
+		sb.WriteString(fmt.Sprintf("### Round %d · %s %s (%s)\n\n%s\n\n", msg.Round, emoji, msg.AIModelName, msg.Personality, msg.Content))
+	}
+	if len(r.Votes) > 0 {
+		sb.WriteString("### Final votes\n\n")
+		for _, vote := range r.Votes {
+			if len(vote.Decisions) == 0 {
+				sb.WriteString(fmt.Sprintf("- %s: %s %s (confidence: %d%%) %s\n",
+					vote.AIModelName, vote.Symbol, vote.Action, vote.Confidence, vote.Reasoning))
+			}
+			for _, d := range vote.Decisions {
+				sb.WriteString(fmt.Sprintf("- %s: %s %s (confidence: %d%%) %s\n",
+					vote.AIModelName, d.Symbol, d.Action, d.Confidence, d.Reasoning))
+			}
+		}
+	}
+	return sb.String()
+}
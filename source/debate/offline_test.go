@@ -0,0 +1,172 @@
+package debate
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+// TestRunOffline Test rounds, votes and consensus of an in-memory debate with scripted turns
+func TestRunOffline(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+	ctx := &kernel.Context{
+		Account:        kernel.AccountInfo{TotalEquity: 1000},
+		CandidateCoins: []kernel.CandidateCoin{{Symbol: "BTCUSDT"}},
+		MarketDataMap:  map[string]*market.Data{"BTCUSDT": {Symbol: "BTCUSDT", CurrentPrice: 100000}},
+	}
+	participants := []*store.DebateParticipant{
+		{AIModelID: "m1", AIModelName: "bull", Personality: store.PersonalityBull},
+		{AIModelID: "m2", AIModelName: "bear", Personality: store.PersonalityBear},
+	}
+
+	var debateTurns, voteTurns int
+	call := func(p *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error) {
+		if round == 0 {
+			voteTurns++
+			if p.Personality == store.PersonalityBear {
+				return "", fmt.Errorf("vote unavailable")
+			}
+			return `<final_vote>[{"symbol":"BTCUSDT","action":"open_long","confidence":80,"leverage":3,"position_pct":0.2,"stop_loss":0.02,"take_profit":0.04,"reasoning":"trend"}]</final_vote>`, nil
+		}
+		debateTurns++
+		return `<reasoning>ok</reasoning><decision>[{"symbol":"BTCUSDT","action":"wait","confidence":60,"reasoning":"wait"}]</decision>`, nil
+	}
+
+	result, err := RunOffline(OfflineRequest{
+		ID:           "test",
+		Participants: participants,
+		MaxRounds:    2,
+		Strategy:     kernel.NewStrategyEngine(&cfg),
+		Context:      ctx,
+		Call:         TurnCallerFunc(call),
+	})
+	if err != nil {
+		t.Fatalf("RunOffline failed: %v", err)
+	}
+	if debateTurns != 4 || voteTurns != 2 {
+		t.Errorf("expected 4 debate turns and 2 vote turns, got %d and %d", debateTurns, voteTurns)
+	}
+	if len(result.Messages) != 4 || len(result.Votes) != 1 {
+		t.Fatalf("expected 4 messages and 1 vote, got %d and %d", len(result.Messages), len(result.Votes))
+	}
+	if len(result.Decisions) != 1 || result.Decisions[0].Action != "open_long" || result.Decisions[0].PositionSizeUSD != 200 {
+		t.Errorf("unexpected decisions: %+v", result.Decisions)
+	}
+
+	call = func(*store.DebateParticipant, int, string, string) (string, error) {
+		return "", fmt.Errorf("provider down")
+	}
+	if _, err := RunOffline(OfflineRequest{Participants: participants, MaxRounds: 1, Strategy: kernel.NewStrategyEngine(&cfg), Context: ctx, Call: TurnCallerFunc(call)}); err == nil {
+		t.Error("expected an error when no participant votes")
+	}
+}
+
+// TestRunOfflineModerator Test that the moderator summarises every round and its verdict replaces the vote
+func TestRunOfflineModerator(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+	ctx := &kernel.Context{
+		Account:        kernel.AccountInfo{TotalEquity: 1000},
+		CandidateCoins: []kernel.CandidateCoin{{Symbol: "ETHUSDT"}},
+		MarketDataMap:  map[string]*market.Data{"ETHUSDT": {Symbol: "ETHUSDT", CurrentPrice: 4000}},
+	}
+	participants := []*store.DebateParticipant{
+		{AIModelID: "m1", AIModelName: "bull", Personality: store.PersonalityBull},
+		{AIModelID: "m2", AIModelName: "skeptic", Personality: "custom_abc", PersonalityName: "Skeptic",
+			CustomPrompt: "Doubt every breakout.", RiskBias: store.RiskBiasConservative},
+	}
+	moderator := &store.DebateParticipant{AIModelID: "m3", AIModelName: "Moderator", Personality: store.PersonalityModerator}
+
+	var participantVotes int
+	call := func(p *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error) {
+		if p == moderator {
+			if round == 0 {
+				return `<justification>bear case unsupported</justification><decision>[{"symbol":"ETHUSDT","action":"open_short","confidence":70,"leverage":2,"position_pct":0.1,"stop_loss":0.03,"take_profit":0.06,"reasoning":"funding flip"}]</decision>`, nil
+			}
+			return "<summary>bull cites no data</summary>", nil
+		}
+		if round == 0 {
+			participantVotes++
+		}
+		if p.PersonalityName == "Skeptic" && (!strings.Contains(systemPrompt, "Doubt every breakout.") || !strings.Contains(systemPrompt, "Risk bias: conservative")) {
+			t.Errorf("custom personality prompt missing from system prompt")
+		}
+		return `<decision>[{"symbol":"ETHUSDT","action":"open_long","confidence":90}]</decision>`, nil
+	}
+
+	result, err := RunOffline(OfflineRequest{
+		Participants: participants,
+		Moderator:    moderator,
+		MaxRounds:    2,
+		Strategy:     kernel.NewStrategyEngine(&cfg),
+		Context:      ctx,
+		Call:         TurnCallerFunc(call),
+	})
+	if err != nil {
+		t.Fatalf("RunOffline failed: %v", err)
+	}
+	if participantVotes != 0 {
+		t.Errorf("participants should not vote when the moderator decides, got %d votes", participantVotes)
+	}
+	var summaries, verdicts int
+	for _, msg := range result.Messages {
+		switch msg.MessageType {
+		case "moderator":
+			summaries++
+		case "verdict":
+			verdicts++
+		}
+	}
+	if summaries != 2 || verdicts != 1 {
+		t.Errorf("expected 2 summaries and 1 verdict, got %d and %d", summaries, verdicts)
+	}
+	if len(result.Decisions) != 1 || result.Decisions[0].Action != "open_short" {
+		t.Errorf("expected the moderator's open_short, got %+v", result.Decisions)
+	}
+}
+
+// TestRunOfflineWeights Test that accuracy weights apply to offline debates as they do to stored sessions
+func TestRunOfflineWeights(t *testing.T) {
+	cfg := store.GetDefaultStrategyConfig("en")
+	ctx := &kernel.Context{
+		Account:        kernel.AccountInfo{TotalEquity: 1000},
+		CandidateCoins: []kernel.CandidateCoin{{Symbol: "BTCUSDT"}},
+		MarketDataMap:  map[string]*market.Data{"BTCUSDT": {Symbol: "BTCUSDT", CurrentPrice: 100000}},
+	}
+	participants := []*store.DebateParticipant{
+		{AIModelID: "m1", AIModelName: "bull", Personality: store.PersonalityBull},
+		{AIModelID: "m2", AIModelName: "bear", Personality: store.PersonalityBear},
+	}
+	call := TurnCallerFunc(func(p *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error) {
+		if p.Personality == store.PersonalityBull {
+			return `<final_vote>[{"symbol":"BTCUSDT","action":"open_long","confidence":80,"leverage":3,"position_pct":0.2}]</final_vote>`, nil
+		}
+		return `<final_vote>[{"symbol":"BTCUSDT","action":"open_short","confidence":60,"leverage":3,"position_pct":0.2}]</final_vote>`, nil
+	})
+
+	for _, tt := range []struct {
+		weights map[string]float64
+		action  string
+	}{
+		{nil, "open_long"},
+		{map[string]float64{"m1/bull": 0.6, "m2/bear": 1.4}, "open_short"},
+	} {
+		result, err := RunOffline(OfflineRequest{
+			Participants: participants,
+			MaxRounds:    1,
+			Strategy:     kernel.NewStrategyEngine(&cfg),
+			Context:      ctx,
+			Call:         call,
+			Weights:      tt.weights,
+		})
+		if err != nil {
+			t.Fatalf("RunOffline failed: %v", err)
+		}
+		if len(result.Consensus) != 1 || result.Consensus[0].Action != tt.action {
+			t.Errorf("weights %v: expected %s, got %+v", tt.weights, tt.action, result.Consensus)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package debate
+
+import (
+	"fmt"
+
+	"nofx/store"
+)
+
+// TurnCaller obtains participant turns. round counts debate rounds from 1; the final vote and the
+// moderator's verdict are requested with round 0. Implementations decide how a turn is obtained
+// (live AI call, cache, replay).
+type TurnCaller interface {
+	CallTurn(participant *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error)
+}
+
+// TurnCallerFunc adapts a function to TurnCaller
+type TurnCallerFunc func(participant *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error)
+
+func (f TurnCallerFunc) CallTurn(participant *store.DebateParticipant, round int, systemPrompt, userPrompt string) (string, error) {
+	return f(participant, round, systemPrompt, userPrompt)
+}
+
+// roundObserver follows the progress of a debate run. The live engine stores and streams every step;
+// offline runs only keep the result.
+type roundObserver interface {
+	roundStarted(round int)
+	roundEnded(round int)
+	votingStarted()
+	messageAdded(msg *store.DebateMessage)
+	voteCast(vote *store.DebateVote)
+	turnFailed(participant *store.DebateParticipant, round int, err error)
+}
+
+// roundsRequest the inputs of one debate run
+type roundsRequest struct {
+	Session      *store.DebateSessionWithDetails
+	Moderator    *store.DebateParticipant // nil when the panel decides by voting
+	SystemPrompt string                   // Base strategy system prompt
+	UserPrompt   string                   // Market data prompt
+	Weights      map[string]float64       // Vote weight multipliers by participantKey (nil = unweighted)
+	Caller       TurnCaller
+	Observer     roundObserver // Optional
+}
+
+// roundsResult messages, votes and consensus of a debate run
+type roundsResult struct {
+	Messages  []*store.DebateMessage
+	Votes     []*store.DebateVote
+	Voters    []*store.DebateParticipant // Who cast Votes: the moderator after a verdict, otherwise the panel
+	Consensus []*store.DebateDecision
+}
+
+// runRounds runs the debate rounds, the moderator's summaries and verdict (falling back to a panel vote
+// when the verdict fails) and the weighted consensus. This is the one orchestration shared by stored
+// sessions, trader cycles and offline (backtest) debates. A participant whose turn fails is skipped for
+// that turn; the returned error is only set when nobody voted, the result is usable either way.
+func (e *DebateEngine) runRounds(req roundsRequest) (*roundsResult, error) {
+	session := req.Session
+	log := sessionLog(session)
+	observer := req.Observer
+	if observer == nil {
+		observer = noopObserver{}
+	}
+
+	result := &roundsResult{}
+	var lastErr error
+	for round := 1; round <= session.MaxRounds; round++ {
+		log.Infof("Starting debate round %d/%d for session %s", round, session.MaxRounds, session.ID)
+		observer.roundStarted(round)
+
+		for i, participant := range session.Participants {
+			log.Infof("[Debate] Round %d - Getting response from participant %d/%d: %s (%s)",
+				round, i+1, len(session.Participants), participant.AIModelName, participant.Provider)
+
+			systemPrompt := e.buildDebateSystemPrompt(req.SystemPrompt, participant, round, session.MaxRounds)
+			userPrompt := e.buildDebateUserPrompt(req.UserPrompt, result.Messages, participant, round)
+			response, err := req.Caller.CallTurn(participant, round, systemPrompt, userPrompt)
+			if err != nil {
+				log.Errorf("[Debate] Failed to get response from %s (%s): %v", participant.AIModelName, participant.Provider, err)
+				observer.turnFailed(participant, round, err)
+				lastErr = err
+				continue
+			}
+
+			msg := newDebateMessage(session, participant, round, response)
+			log.Infof("[Debate] Got response from %s: %d chars, confidence=%d%%",
+				participant.AIModelName, len(msg.Content), msg.Confidence)
+			result.Messages = append(result.Messages, msg)
+			observer.messageAdded(msg)
+		}
+
+		if req.Moderator != nil {
+			response, err := req.Caller.CallTurn(req.Moderator, round,
+				e.buildModeratorSystemPrompt(req.SystemPrompt, round, session.MaxRounds),
+				e.buildModeratorUserPrompt(result.Messages, round))
+			if err != nil {
+				log.Errorf("[Debate] Moderator failed to summarise round %d: %v", round, err)
+				observer.turnFailed(req.Moderator, round, err)
+			} else {
+				msg := newSummaryMessage(session, req.Moderator, round, response)
+				result.Messages = append(result.Messages, msg)
+				observer.messageAdded(msg)
+			}
+		}
+
+		observer.roundEnded(round)
+	}
+
+	log.Infof("Starting voting phase for session %s", session.ID)
+	observer.votingStarted()
+
+	if req.Moderator != nil {
+		// The verdict is kept both as a message (with the full justification) and as the only vote,
+		// so consensus is derived from it like from votes
+		response, err := req.Caller.CallTurn(req.Moderator, 0, e.buildVerdictSystemPrompt(req.SystemPrompt), e.buildVerdictUserPrompt(result.Messages))
+		if err != nil {
+			// Fall back to voting so the session still reaches a consensus
+			log.Errorf("[Debate] Moderator verdict failed, falling back to voting: %v", err)
+			observer.turnFailed(req.Moderator, 0, err)
+			lastErr = err
+		} else {
+			msg := newVerdictMessage(session, req.Moderator, response)
+			result.Messages = append(result.Messages, msg)
+			observer.messageAdded(msg)
+
+			vote := newDebateVote(session, req.Moderator, response)
+			result.Votes = append(result.Votes, vote)
+			result.Voters = []*store.DebateParticipant{req.Moderator}
+			observer.voteCast(vote)
+		}
+	}
+
+	if len(result.Votes) == 0 {
+		votingUserPrompt := e.buildVotingUserPrompt(result.Messages)
+		for _, participant := range session.Participants {
+			response, err := req.Caller.CallTurn(participant, 0, e.buildVotingSystemPrompt(req.SystemPrompt, participant), votingUserPrompt)
+			if err != nil {
+				log.Errorf("Failed to get vote from %s: %v", participant.AIModelName, err)
+				observer.turnFailed(participant, 0, err)
+				lastErr = err
+				continue
+			}
+			vote := newDebateVote(session, participant, response)
+			result.Votes = append(result.Votes, vote)
+			observer.voteCast(vote)
+		}
+		result.Voters = session.Participants
+	}
+
+	result.Consensus = e.weightedMultiCoinConsensus(result.Votes, req.Weights)
+	if len(result.Votes) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no participants")
+		}
+		return result, fmt.Errorf("no participant voted: %w", lastErr)
+	}
+	return result, nil
+}
+
+// noopObserver ignores the progress of a debate run
+type noopObserver struct{}
+
+func (noopObserver) roundStarted(int)                                {}
+func (noopObserver) roundEnded(int)                                  {}
+func (noopObserver) votingStarted()                                  {}
+func (noopObserver) messageAdded(*store.DebateMessage)               {}
+func (noopObserver) voteCast(*store.DebateVote)                      {}
+func (noopObserver) turnFailed(*store.DebateParticipant, int, error) {}
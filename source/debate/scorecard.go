@@ -0,0 +1,456 @@
+package debate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+)
+
+const (
+	neutralBandPct   = 0.5 // hold/wait is right when the price moved less than this, in percent
+	minWeightSamples = 10  // scored outcomes a participant needs before its votes are weighted by accuracy
+	scoreBatchSize   = 200
+	scoreKlineWindow = 30 * time.Minute // how far before DueAt the scorer looks for the last closed 1m kline
+)
+
+// Scorecard grouping
+const (
+	GroupByPersonality = "personality"
+	GroupByModel       = "model"
+	GroupByParticipant = "participant" // model + personality
+)
+
+// participantKey identifies a participant seat by model and personality. Scorecards, vote weights and
+// outcomes are keyed by it, so one model playing two personalities on a panel is tracked separately.
+func participantKey(aiModelID string, personality store.DebatePersonality) string {
+	return aiModelID + "/" + string(personality)
+}
+
+// CalibrationBucket hit rate of the outcomes whose confidence falls in [MinConfidence, MaxConfidence]
+type CalibrationBucket struct {
+	MinConfidence int     `json:"min_confidence"`
+	MaxConfidence int     `json:"max_confidence"`
+	Count         int     `json:"count"`
+	AvgConfidence float64 `json:"avg_confidence"`
+	HitRate       float64 `json:"hit_rate"` // Well calibrated when close to AvgConfidence/100
+}
+
+// ParticipantScorecard realized accuracy of a personality, a model or a model playing a personality
+type ParticipantScorecard struct {
+	Key             string                  `json:"key"`
+	AIModelID       string                  `json:"ai_model_id,omitempty"`
+	AIModelName     string                  `json:"ai_model_name,omitempty"`
+	Personality     store.DebatePersonality `json:"personality,omitempty"`
+	PersonalityName string                  `json:"personality_name,omitempty"`
+	Samples         int                     `json:"samples"`
+	Correct         int                     `json:"correct"`
+	Accuracy        float64                 `json:"accuracy"` // 0-1
+	AvgConfidence   float64                 `json:"avg_confidence"`
+	BrierScore      float64                 `json:"brier_score"`    // Mean squared error of confidence vs outcome, lower is better
+	AvgReturnPct    float64                 `json:"avg_return_pct"` // Average price move in the recommended direction
+	Trades          int                     `json:"trades"`         // Executed trades the participant agreed with
+	PnLContribution float64                 `json:"pnl_contribution"`
+	Calibration     []*CalibrationBucket    `json:"calibration"`
+}
+
+// calibrationEdges lower bounds of the calibration buckets
+var calibrationEdges = []int{0, 50, 60, 70, 80, 90}
+
+// actionDirection returns +1 when an action profits from a rising price, -1 from a falling price, 0 for no view
+func actionDirection(action string) int {
+	switch action {
+	case "open_long", "close_short":
+		return 1
+	case "open_short", "close_long":
+		return -1
+	default:
+		return 0
+	}
+}
+
+// newOutcomes creates one pending outcome per symbol decision in votes, priced at the context's current price
+// and due one debate interval later. voters are the participants that cast the votes.
+func newOutcomes(session *store.DebateSessionWithDetails, votes []*store.DebateVote, voters []*store.DebateParticipant, ctx *kernel.Context, now time.Time) []*store.DebateOutcome {
+	bySeat := make(map[string]*store.DebateParticipant, len(voters))
+	for _, p := range voters {
+		bySeat[participantKey(p.AIModelID, p.Personality)] = p
+	}
+	horizon := time.Duration(session.IntervalMinutes) * time.Minute
+	if horizon <= 0 {
+		horizon = time.Hour
+	}
+
+	var outcomes []*store.DebateOutcome
+	for _, vote := range votes {
+		participant, ok := bySeat[participantKey(vote.AIModelID, vote.Personality)]
+		if !ok {
+			continue
+		}
+		decisions := vote.Decisions
+		if len(decisions) == 0 {
+			decisions = []*store.DebateDecision{{Symbol: vote.Symbol, Action: vote.Action, Confidence: vote.Confidence}}
+		}
+		for _, d := range decisions {
+			symbol := d.Symbol
+			if symbol == "" {
+				symbol = vote.Symbol
+			}
+			data, ok := ctx.MarketDataMap[symbol]
+			if !ok || data == nil || data.CurrentPrice <= 0 || !isValidAction(d.Action) {
+				continue
+			}
+			outcomes = append(outcomes, &store.DebateOutcome{
+				SessionID:       session.ID,
+				UserID:          session.UserID,
+				AIModelID:       participant.AIModelID,
+				AIModelName:     participant.AIModelName,
+				Personality:     participant.Personality,
+				PersonalityName: participant.PersonalityName,
+				Symbol:          symbol,
+				Action:          d.Action,
+				Confidence:      d.Confidence,
+				EntryPrice:      data.CurrentPrice,
+				DueAt:           now.Add(horizon),
+				Status:          store.OutcomePending,
+			})
+		}
+	}
+	return outcomes
+}
+
+// recordOutcomes stores the outcomes of a session's votes so the scorer can grade them later
+func (e *DebateEngine) recordOutcomes(session *store.DebateSessionWithDetails, votes []*store.DebateVote, voters []*store.DebateParticipant, ctx *kernel.Context) {
+	outcomes := newOutcomes(session, votes, voters, ctx, time.Now())
+	if err := e.debateStore.AddOutcomes(outcomes); err != nil {
+		sessionLog(session).Warnf("[Debate] Failed to record vote outcomes: %v", err)
+	}
+}
+
+// scoreOutcome grades an outcome against the price at its horizon
+func scoreOutcome(o *store.DebateOutcome, exitPrice float64, now time.Time) {
+	o.ExitPrice = exitPrice
+	o.ScoredAt = now
+	o.Status = store.OutcomeScored
+	move := (exitPrice - o.EntryPrice) / o.EntryPrice * 100
+	if dir := actionDirection(o.Action); dir != 0 {
+		o.ReturnPct = float64(dir) * move
+		o.Correct = o.ReturnPct > 0
+	} else {
+		o.ReturnPct = 0
+		o.Correct = math.Abs(move) < neutralBandPct
+	}
+}
+
+// attributePnL splits the realized PnL of an executed consensus trade between the outcomes that recommended
+// the executed action, in proportion to their confidence. Dissenting outcomes contribute nothing.
+func attributePnL(outcomes []*store.DebateOutcome, pnl float64) {
+	var total float64
+	for _, o := range outcomes {
+		if o.Action == o.ExecutedAction {
+			total += float64(max(o.Confidence, 1))
+		}
+	}
+	for _, o := range outcomes {
+		o.PnLSettled = true
+		o.PnLContribution = 0
+		if o.Action == o.ExecutedAction && total > 0 {
+			o.PnLContribution = pnl * float64(max(o.Confidence, 1)) / total
+		}
+	}
+}
+
+// BuildScorecards aggregates scored outcomes by personality, model or participant (model + personality),
+// best accuracy first
+func BuildScorecards(outcomes []*store.DebateOutcome, groupBy string) []*ParticipantScorecard {
+	type accumulator struct {
+		card    *ParticipantScorecard
+		conf    float64
+		brier   float64
+		ret     float64
+		buckets []*CalibrationBucket
+	}
+	groups := make(map[string]*accumulator)
+	var order []string
+
+	for _, o := range outcomes {
+		if o.Status != store.OutcomeScored {
+			continue
+		}
+		key := scorecardKey(o, groupBy)
+		acc, ok := groups[key]
+		if !ok {
+			acc = &accumulator{card: &ParticipantScorecard{Key: key}}
+			for i, lo := range calibrationEdges {
+				hi := 100
+				if i+1 < len(calibrationEdges) {
+					hi = calibrationEdges[i+1] - 1
+				}
+				acc.buckets = append(acc.buckets, &CalibrationBucket{MinConfidence: lo, MaxConfidence: hi})
+			}
+			switch groupBy {
+			case GroupByModel:
+				acc.card.AIModelID, acc.card.AIModelName = o.AIModelID, o.AIModelName
+			case GroupByPersonality:
+				acc.card.Personality, acc.card.PersonalityName = o.Personality, o.PersonalityName
+			default:
+				acc.card.AIModelID, acc.card.AIModelName = o.AIModelID, o.AIModelName
+				acc.card.Personality, acc.card.PersonalityName = o.Personality, o.PersonalityName
+			}
+			groups[key] = acc
+			order = append(order, key)
+		}
+
+		hit := 0.0
+		if o.Correct {
+			hit = 1
+			acc.card.Correct++
+		}
+		p := float64(min(max(o.Confidence, 0), 100)) / 100
+		acc.card.Samples++
+		acc.conf += float64(o.Confidence)
+		acc.brier += (p - hit) * (p - hit)
+		acc.ret += o.ReturnPct
+		if o.PnLSettled && o.Action == o.ExecutedAction {
+			acc.card.Trades++
+			acc.card.PnLContribution += o.PnLContribution
+		}
+		for i := len(acc.buckets) - 1; i >= 0; i-- {
+			if o.Confidence >= acc.buckets[i].MinConfidence {
+				b := acc.buckets[i]
+				b.AvgConfidence += float64(o.Confidence)
+				b.HitRate += hit
+				b.Count++
+				break
+			}
+		}
+	}
+
+	cards := make([]*ParticipantScorecard, 0, len(order))
+	for _, key := range order {
+		acc := groups[key]
+		n := float64(acc.card.Samples)
+		acc.card.Accuracy = float64(acc.card.Correct) / n
+		acc.card.AvgConfidence = acc.conf / n
+		acc.card.BrierScore = acc.brier / n
+		acc.card.AvgReturnPct = acc.ret / n
+		for _, b := range acc.buckets {
+			if b.Count > 0 {
+				b.AvgConfidence /= float64(b.Count)
+				b.HitRate /= float64(b.Count)
+				acc.card.Calibration = append(acc.card.Calibration, b)
+			}
+		}
+		cards = append(cards, acc.card)
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		if cards[i].Accuracy != cards[j].Accuracy {
+			return cards[i].Accuracy > cards[j].Accuracy
+		}
+		return cards[i].Samples > cards[j].Samples
+	})
+	return cards
+}
+
+// scorecardKey returns the grouping key of an outcome
+func scorecardKey(o *store.DebateOutcome, groupBy string) string {
+	switch groupBy {
+	case GroupByModel:
+		return o.AIModelID
+	case GroupByPersonality:
+		return string(o.Personality)
+	default:
+		return participantKey(o.AIModelID, o.Personality)
+	}
+}
+
+// AccuracyWeights returns a vote weight multiplier per participantKey, from 0.5 (never right) to 1.5
+// (always right), based on how that model scored playing the same personality in the user's debates.
+// Participants with fewer than minWeightSamples scored outcomes keep a weight of 1.
+func AccuracyWeights(debateStore *store.DebateStore, userID string, participants []*store.DebateParticipant) (map[string]float64, error) {
+	outcomes, err := debateStore.GetScoredOutcomes(userID, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	cards := make(map[string]*ParticipantScorecard)
+	for _, card := range BuildScorecards(outcomes, GroupByParticipant) {
+		cards[card.Key] = card
+	}
+
+	weights := make(map[string]float64, len(participants))
+	for _, p := range participants {
+		key := participantKey(p.AIModelID, p.Personality)
+		card, ok := cards[key]
+		if !ok || card.Samples < minWeightSamples {
+			continue
+		}
+		weights[key] = 0.5 + card.Accuracy
+	}
+	return weights, nil
+}
+
+// accuracyWeights returns the session's AccuracyWeights, or nil (unweighted) when scorecards cannot be loaded
+func (e *DebateEngine) accuracyWeights(session *store.DebateSessionWithDetails) map[string]float64 {
+	weights, err := AccuracyWeights(e.debateStore, session.UserID, session.Participants)
+	if err != nil {
+		sessionLog(session).Warnf("[Debate] Failed to load scorecards, votes are not weighted: %v", err)
+		return nil
+	}
+	return weights
+}
+
+// Scorer periodically grades pending debate outcomes against the market and attributes the PnL of
+// executed consensus trades to the participants that recommended them
+type Scorer struct {
+	debateStore *store.DebateStore
+	interval    time.Duration
+	priceAt     func(symbol string, at time.Time) (float64, error)
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewScorer creates an outcome scorer running every interval
+func NewScorer(debateStore *store.DebateStore, interval time.Duration) *Scorer {
+	return &Scorer{
+		debateStore: debateStore,
+		interval:    interval,
+		priceAt:     closeAt,
+		stopCh:      make(chan struct{}),
+	}
+}
+
+// closeAt returns the close of the last 1m kline that closed at or before at, so an outcome is graded at
+// its horizon however late the scorer runs
+func closeAt(symbol string, at time.Time) (float64, error) {
+	klines, err := market.GetKlinesRange(symbol, "1m", at.Add(-scoreKlineWindow), at)
+	if err != nil {
+		return 0, err
+	}
+	return lastCloseAt(klines, at)
+}
+
+// lastCloseAt picks the close of the last kline that closed at or before at
+func lastCloseAt(klines []market.Kline, at time.Time) (float64, error) {
+	atMs := at.UnixMilli()
+	for i := len(klines) - 1; i >= 0; i-- {
+		if klines[i].CloseTime <= atMs {
+			return klines[i].Close, nil
+		}
+	}
+	return 0, fmt.Errorf("no closed kline before %s", at.Format(time.RFC3339))
+}
+
+// Start starts the scorer loop
+func (s *Scorer) Start() {
+	s.wg.Add(1)
+	go s.loop()
+}
+
+// Stop stops the scorer and waits for a running pass to finish
+func (s *Scorer) Stop() {
+	close(s.stopCh)
+	s.wg.Wait()
+}
+
+func (s *Scorer) loop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			s.RunOnce()
+		}
+	}
+}
+
+// RunOnce scores every due outcome and settles the PnL of closed consensus trades
+func (s *Scorer) RunOnce() {
+	now := time.Now()
+	due, err := s.debateStore.GetDueOutcomes(now, scoreBatchSize)
+	if err != nil {
+		logger.Warnf("[Debate] Failed to load due outcomes: %v", err)
+		return
+	}
+
+	// Outcomes of one session share symbol and DueAt, cache their price per symbol and minute
+	prices := make(map[string]float64)
+	scored := 0
+	for _, o := range due {
+		key := o.Symbol + "@" + o.DueAt.Truncate(time.Minute).Format(time.RFC3339)
+		price, ok := prices[key]
+		if !ok {
+			if price, err = s.priceAt(o.Symbol, o.DueAt); err != nil || price <= 0 {
+				logger.Warnf("[Debate] No price for %s at %s, skipping outcome %s: %v", o.Symbol, o.DueAt.Format(time.RFC3339), o.ID, err)
+				price = 0
+			}
+			prices[key] = price
+		}
+		if price <= 0 || o.EntryPrice <= 0 {
+			o.Status = store.OutcomeSkipped
+		} else {
+			scoreOutcome(o, price, now)
+			scored++
+		}
+		if err := s.debateStore.UpdateOutcome(o); err != nil {
+			logger.Warnf("[Debate] Failed to save outcome %s: %v", o.ID, err)
+		}
+	}
+
+	settled := s.settleExecuted()
+	if scored > 0 || settled > 0 {
+		logger.Infof("[Debate] Scored %d vote outcomes, settled %d executed trades", scored, settled)
+	}
+}
+
+// settleExecuted attributes the realized PnL of executed consensus trades once their position has closed
+func (s *Scorer) settleExecuted() int {
+	outcomes, err := s.debateStore.GetUnsettledExecutedOutcomes()
+	if err != nil {
+		logger.Warnf("[Debate] Failed to load executed outcomes: %v", err)
+		return 0
+	}
+
+	// Group by session and symbol: one executed trade each
+	trades := make(map[string][]*store.DebateOutcome)
+	var order []string
+	for _, o := range outcomes {
+		key := o.SessionID + "/" + o.Symbol
+		if _, ok := trades[key]; !ok {
+			order = append(order, key)
+		}
+		trades[key] = append(trades[key], o)
+	}
+
+	settled := 0
+	for _, key := range order {
+		group := trades[key]
+		first := group[0]
+		side := "LONG"
+		if first.ExecutedAction == "open_short" {
+			side = "SHORT"
+		}
+		pos, err := s.debateStore.FindClosedDebatePosition(first.TraderID, first.Symbol, side, first.ExecutedAt.Add(-time.Minute).UnixMilli())
+		if err != nil || pos == nil {
+			continue // Still open (or not synced yet)
+		}
+		attributePnL(group, pos.RealizedPnL)
+		for _, o := range group {
+			if err := s.debateStore.UpdateOutcome(o); err != nil {
+				logger.Warnf("[Debate] Failed to save outcome %s: %v", o.ID, err)
+			}
+		}
+		settled++
+	}
+	return settled
+}
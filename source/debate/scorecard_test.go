@@ -0,0 +1,144 @@
+package debate
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+// TestScoreOutcome Test directional and neutral grading against the exit price
+func TestScoreOutcome(t *testing.T) {
+	tests := []struct {
+		action    string
+		exit      float64
+		correct   bool
+		returnPct float64
+	}{
+		{"open_long", 102, true, 2},
+		{"open_long", 98, false, -2},
+		{"open_short", 98, true, 2},
+		{"close_long", 101, false, -1},
+		{"wait", 100.3, true, 0},
+		{"hold", 101, false, 0},
+	}
+	for _, tt := range tests {
+		o := &store.DebateOutcome{Action: tt.action, EntryPrice: 100}
+		scoreOutcome(o, tt.exit, time.Now())
+		if o.Status != store.OutcomeScored || o.Correct != tt.correct || math.Abs(o.ReturnPct-tt.returnPct) > 1e-9 {
+			t.Errorf("%s @ %.1f: got correct=%v return=%.2f, want %v %.2f", tt.action, tt.exit, o.Correct, o.ReturnPct, tt.correct, tt.returnPct)
+		}
+	}
+}
+
+// TestLastCloseAt Test that outcomes are priced at the last kline closed by their due time
+func TestLastCloseAt(t *testing.T) {
+	due := time.UnixMilli(1_700_000_090_000)
+	klines := []market.Kline{
+		{CloseTime: 1_700_000_019_999, Close: 100},
+		{CloseTime: 1_700_000_079_999, Close: 101},
+		{CloseTime: 1_700_000_139_999, Close: 102}, // still open at the due time
+	}
+	price, err := lastCloseAt(klines, due)
+	if err != nil || price != 101 {
+		t.Errorf("expected 101, got %v (%v)", price, err)
+	}
+	if _, err := lastCloseAt(klines[2:], due); err == nil {
+		t.Error("expected an error without a closed kline")
+	}
+}
+
+// TestNewOutcomes Test that every coin decision of a known voter becomes a pending outcome
+func TestNewOutcomes(t *testing.T) {
+	session := &store.DebateSessionWithDetails{DebateSession: &store.DebateSession{ID: "s1", UserID: "u1", IntervalMinutes: 15}}
+	voters := []*store.DebateParticipant{
+		{AIModelID: "m1", AIModelName: "bull", Personality: store.PersonalityBull},
+		{AIModelID: "m1", AIModelName: "bear", Personality: store.PersonalityBear},
+	}
+	ctx := &kernel.Context{MarketDataMap: map[string]*market.Data{
+		"BTCUSDT": {CurrentPrice: 100000},
+		"ETHUSDT": {CurrentPrice: 4000},
+	}}
+	votes := []*store.DebateVote{
+		{AIModelID: "m1", Personality: store.PersonalityBull, Decisions: []*store.DebateDecision{
+			{Symbol: "BTCUSDT", Action: "open_long", Confidence: 80},
+			{Symbol: "ETHUSDT", Action: "wait", Confidence: 60},
+			{Symbol: "SOLUSDT", Action: "open_short", Confidence: 70}, // no price
+		}},
+		{AIModelID: "m1", Personality: store.PersonalityBear, Symbol: "BTCUSDT", Action: "open_short", Confidence: 65},
+		{AIModelID: "unknown", Symbol: "BTCUSDT", Action: "open_short"},
+	}
+
+	now := time.Now()
+	outcomes := newOutcomes(session, votes, voters, ctx, now)
+	if len(outcomes) != 3 {
+		t.Fatalf("expected 3 outcomes, got %d", len(outcomes))
+	}
+	if o := outcomes[0]; o.Personality != store.PersonalityBull || o.EntryPrice != 100000 || !o.DueAt.Equal(now.Add(15*time.Minute)) {
+		t.Errorf("unexpected outcome: %+v", o)
+	}
+	if o := outcomes[2]; o.Personality != store.PersonalityBear || o.AIModelName != "bear" || o.Action != "open_short" {
+		t.Errorf("vote of the second seat of m1 attributed to the wrong participant: %+v", o)
+	}
+}
+
+// TestBuildScorecards Test accuracy, calibration and PnL contribution per personality
+func TestBuildScorecards(t *testing.T) {
+	scored := func(personality store.DebatePersonality, confidence int, correct bool) *store.DebateOutcome {
+		return &store.DebateOutcome{AIModelID: "m1", Personality: personality, Action: "open_long",
+			Confidence: confidence, Correct: correct, Status: store.OutcomeScored}
+	}
+	outcomes := []*store.DebateOutcome{
+		scored(store.PersonalityBull, 90, true),
+		scored(store.PersonalityBull, 90, false),
+		scored(store.PersonalityBull, 55, true),
+		scored(store.PersonalityBear, 70, true),
+		{AIModelID: "m1", Personality: store.PersonalityBear, Status: store.OutcomePending},
+	}
+
+	executed := []*store.DebateOutcome{outcomes[0], outcomes[3]}
+	outcomes[3].Confidence = 30
+	for _, o := range executed {
+		o.ExecutedAction = "open_long"
+	}
+	attributePnL(executed, 120)
+
+	cards := BuildScorecards(outcomes, GroupByPersonality)
+	if len(cards) != 2 || cards[0].Personality != store.PersonalityBear {
+		t.Fatalf("expected bear first, got %+v", cards)
+	}
+	bear, bull := cards[0], cards[1]
+	if bear.Samples != 1 || bear.Accuracy != 1 || bear.PnLContribution != 30 || bear.Trades != 1 {
+		t.Errorf("unexpected bear scorecard: %+v", bear)
+	}
+	if bull.Samples != 3 || math.Abs(bull.Accuracy-2.0/3) > 1e-9 || bull.PnLContribution != 90 {
+		t.Errorf("unexpected bull scorecard: %+v", bull)
+	}
+	if len(bull.Calibration) != 2 || bull.Calibration[1].MinConfidence != 90 || bull.Calibration[1].HitRate != 0.5 {
+		t.Errorf("unexpected bull calibration: %+v", bull.Calibration)
+	}
+	// Brier: (0.9-1)^2 + (0.9-0)^2 + (0.55-1)^2 over 3
+	if math.Abs(bull.BrierScore-(0.01+0.81+0.2025)/3) > 1e-9 {
+		t.Errorf("unexpected brier score %.4f", bull.BrierScore)
+	}
+}
+
+// TestWeightedConsensus Test that accuracy weights can overturn a confidence majority
+func TestWeightedConsensus(t *testing.T) {
+	e := &DebateEngine{}
+	votes := []*store.DebateVote{
+		{AIModelID: "m1", Personality: store.PersonalityBull, Symbol: "BTCUSDT", Action: "open_long", Confidence: 80},
+		{AIModelID: "m1", Personality: store.PersonalityBear, Symbol: "BTCUSDT", Action: "open_short", Confidence: 70},
+	}
+	if d := e.determineMultiCoinConsensus(votes); d[0].Action != "open_long" {
+		t.Errorf("unweighted consensus should be open_long, got %s", d[0].Action)
+	}
+	// The same model on two seats is weighted per personality
+	weights := map[string]float64{"m1/bull": 0.6, "m1/bear": 1.4}
+	if d := e.weightedMultiCoinConsensus(votes, weights); d[0].Action != "open_short" {
+		t.Errorf("weighted consensus should be open_short, got %s", d[0].Action)
+	}
+}
@@ -0,0 +1,203 @@
+package debate
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"nofx/kernel"
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// prepareMarketData fills the trader context's market data before the panel debates (replaced in tests)
+var prepareMarketData = func(engine *kernel.StrategyEngine, ctx *kernel.Context) error {
+	return engine.PrepareMarketData(ctx)
+}
+
+// CycleRequest a trader decision cycle to be decided by a debate panel
+type CycleRequest struct {
+	PanelID  string                 // Debate session whose participants, rounds and prompt variant form the panel
+	TraderID string                 // Trader the cycle belongs to
+	UserID   string                 // Owner of the trader, must also own the panel
+	Strategy *kernel.StrategyEngine // The trader's strategy engine (prompts and risk limits)
+	Context  *kernel.Context        // The trader's own context: real equity, positions and market data
+}
+
+// CycleResult consensus of one debate-driven trader cycle
+type CycleResult struct {
+	SessionID    string                  // Session holding the full transcript of this cycle
+	SystemPrompt string                  // Base strategy system prompt shown to the panel
+	UserPrompt   string                  // Market data prompt shown to the panel
+	Transcript   string                  // Every debate message and vote, in order
+	Consensus    []*store.DebateDecision // Per-coin consensus as voted
+	Decisions    []kernel.Decision       // Consensus converted to executable decisions
+	DurationMs   int64
+}
+
+// NewTraderDebateEngine creates a debate engine for a trader's decision cycles. Unlike NewDebateEngine it
+// leaves other sessions alone, since traders create their engine every time they start.
+func NewTraderDebateEngine(st *store.Store) *DebateEngine {
+	return &DebateEngine{
+		debateStore:   st.Debate(),
+		strategyStore: st.Strategy(),
+		aiModelStore:  st.AIModel(),
+		clients:       make(map[string]mcp.AIClient),
+	}
+}
+
+// ValidatePanel checks that a debate session can serve as a trader's decision panel
+func ValidatePanel(debateStore *store.DebateStore, panelID, userID string) error {
+	_, err := loadPanel(debateStore, panelID, userID)
+	return err
+}
+
+func loadPanel(debateStore *store.DebateStore, panelID, userID string) (*store.DebateSessionWithDetails, error) {
+	panel, err := debateStore.GetSessionWithDetails(panelID)
+	if err != nil || panel.UserID != userID {
+		return nil, fmt.Errorf("debate panel %s not found", panelID)
+	}
+	if len(panel.Participants) < 2 {
+		return nil, fmt.Errorf("debate panel %s needs at least 2 participants", panelID)
+	}
+	return panel, nil
+}
+
+// RunTraderCycle runs the panel synchronously over the trader's context. Each cycle gets its own session
+// (copied from the panel) so the transcript of every cycle stays available through the debate API.
+func (e *DebateEngine) RunTraderCycle(req CycleRequest) (result *CycleResult, err error) {
+	if req.Context == nil || req.Strategy == nil {
+		return nil, fmt.Errorf("trader context and strategy are required")
+	}
+	panel, err := loadPanel(e.debateStore, req.PanelID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// The panel sees the same market data as a single model would, and consensus opens are priced from it
+	if err := prepareMarketData(req.Strategy, req.Context); err != nil {
+		return nil, err
+	}
+
+	session := &store.DebateSession{
+		UserID:          panel.UserID,
+		Name:            fmt.Sprintf("%s · %s", panel.Name, time.Now().Format("01-02 15:04")),
+		StrategyID:      panel.StrategyID,
+		Symbol:          panel.Symbol,
+		MaxRounds:       panel.MaxRounds,
+		IntervalMinutes: panel.IntervalMinutes,
+		PromptVariant:   panel.PromptVariant,
+		TraderID:        req.TraderID,
+
+		ConsensusMethod:    panel.ConsensusMethod,
+		ModeratorAIModelID: panel.ModeratorAIModelID,
+		WeightByAccuracy:   panel.WeightByAccuracy,
+	}
+	if err := e.debateStore.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("failed to create debate session: %w", err)
+	}
+	details := &store.DebateSessionWithDetails{DebateSession: session}
+	for _, p := range panel.Participants {
+		participant := &store.DebateParticipant{
+			SessionID:   session.ID,
+			AIModelID:   p.AIModelID,
+			AIModelName: p.AIModelName,
+			Provider:    p.Provider,
+			Personality: p.Personality,
+			Color:       p.Color,
+			SpeakOrder:  p.SpeakOrder,
+
+			PersonalityName: p.PersonalityName,
+			CustomPrompt:    p.CustomPrompt,
+			RiskBias:        p.RiskBias,
+			Emoji:           p.Emoji,
+		}
+		if err := e.debateStore.AddParticipant(participant); err != nil {
+			return nil, fmt.Errorf("failed to add participant: %w", err)
+		}
+		details.Participants = append(details.Participants, participant)
+	}
+
+	if err := e.InitializeClients(sessionSpeakers(details)); err != nil {
+		e.debateStore.UpdateSessionStatus(session.ID, store.DebateStatusCancelled)
+		return nil, fmt.Errorf("failed to initialize clients: %w", err)
+	}
+	if err := e.debateStore.UpdateSessionStatus(session.ID, store.DebateStatusRunning); err != nil {
+		return nil, fmt.Errorf("failed to update status: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			e.debateStore.UpdateSessionStatus(session.ID, store.DebateStatusCancelled)
+			result, err = nil, fmt.Errorf("debate panic: %v", r)
+		}
+	}()
+
+	start := time.Now()
+	_, consensus, err := e.debateRounds(details, req.Strategy, req.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &CycleResult{
+		SessionID:    session.ID,
+		SystemPrompt: req.Strategy.BuildSystemPrompt(req.Context.Account.TotalEquity, session.PromptVariant),
+		UserPrompt:   req.Strategy.BuildUserPrompt(req.Context),
+		Consensus:    consensus,
+		Decisions:    ConsensusToDecisions(consensus, req.Context),
+		DurationMs:   time.Since(start).Milliseconds(),
+	}
+	result.Transcript = e.transcript(session.ID)
+	return result, nil
+}
+
+// ConsensusToDecisions converts per-coin consensus into executable decisions: position_pct is applied to
+// the context's equity, stop_loss/take_profit percentages to the symbol's current price. Opens for symbols
+// without market data in ctx are dropped.
+func ConsensusToDecisions(consensus []*store.DebateDecision, ctx *kernel.Context) []kernel.Decision {
+	decisions := make([]kernel.Decision, 0, len(consensus))
+	for _, c := range consensus {
+		d := kernel.Decision{
+			Symbol:     c.Symbol,
+			Action:     c.Action,
+			Confidence: c.Confidence,
+			Reasoning:  fmt.Sprintf("Debate consensus: %s", c.Reasoning),
+		}
+		if c.Action == "open_long" || c.Action == "open_short" {
+			data, ok := ctx.MarketDataMap[c.Symbol]
+			if !ok || data == nil || data.CurrentPrice <= 0 {
+				continue
+			}
+			price := data.CurrentPrice
+			d.Leverage = c.Leverage
+			d.PositionSizeUSD = ctx.Account.TotalEquity * c.PositionPct
+			if c.Action == "open_long" {
+				d.StopLoss = price * (1 - c.StopLoss)
+				d.TakeProfit = price * (1 + c.TakeProfit)
+			} else {
+				d.StopLoss = price * (1 + c.StopLoss)
+				d.TakeProfit = price * (1 - c.TakeProfit)
+			}
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+// transcript renders a session's messages and votes as plain text
+func (e *DebateEngine) transcript(sessionID string) string {
+	var sb strings.Builder
+	if messages, err := e.debateStore.GetMessages(sessionID); err == nil {
+		for _, msg := range messages {
+			emoji := store.PersonalityEmojis[msg.Personality]
+			sb.WriteString(fmt.Sprintf("### Round %d · %s %s (%s)\n\n%s\n\n", msg.Round, emoji, msg.AIModelName, msg.Personality, msg.Content))
+		}
+	}
+	if votes, err := e.debateStore.GetVotes(sessionID); err == nil && len(votes) > 0 {
+		sb.WriteString("### Final votes\n\n")
+		for _, vote := range votes {
+			sb.WriteString(fmt.Sprintf("- %s: %s %s (confidence: %d%%) %s\n",
+				vote.AIModelName, vote.Symbol, vote.Action, vote.Confidence, vote.Reasoning))
+		}
+	}
+	return sb.String()
+}
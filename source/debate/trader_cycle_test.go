@@ -0,0 +1,148 @@
+package debate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+// TestConsensusToDecisions Test sizing from real equity and SL/TP prices from current price
+func TestConsensusToDecisions(t *testing.T) {
+	ctx := &kernel.Context{
+		Account: kernel.AccountInfo{TotalEquity: 5000},
+		MarketDataMap: map[string]*market.Data{
+			"BTCUSDT": {Symbol: "BTCUSDT", CurrentPrice: 100000},
+			"ETHUSDT": {Symbol: "ETHUSDT", CurrentPrice: 4000},
+		},
+	}
+	consensus := []*store.DebateDecision{
+		{Symbol: "BTCUSDT", Action: "open_long", Confidence: 80, Leverage: 5, PositionPct: 0.2, StopLoss: 0.02, TakeProfit: 0.06},
+		{Symbol: "ETHUSDT", Action: "open_short", Confidence: 70, Leverage: 3, PositionPct: 0.1, StopLoss: 0.03, TakeProfit: 0.09},
+		{Symbol: "SOLUSDT", Action: "open_long", Confidence: 90, Leverage: 5, PositionPct: 0.2, StopLoss: 0.02, TakeProfit: 0.06},
+		{Symbol: "DOGEUSDT", Action: "close_long", Confidence: 60},
+	}
+
+	decisions := ConsensusToDecisions(consensus, ctx)
+	if len(decisions) != 3 {
+		t.Fatalf("expected 3 decisions (open without market data dropped), got %d", len(decisions))
+	}
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	btc := decisions[0]
+	if !near(btc.PositionSizeUSD, 1000) || !near(btc.StopLoss, 98000) || !near(btc.TakeProfit, 106000) || btc.Leverage != 5 {
+		t.Errorf("unexpected BTC decision: %+v", btc)
+	}
+	eth := decisions[1]
+	if !near(eth.PositionSizeUSD, 500) || !near(eth.StopLoss, 4120) || !near(eth.TakeProfit, 3640) {
+		t.Errorf("unexpected ETH decision: %+v", eth)
+	}
+	if decisions[2].Action != "close_long" || decisions[2].PositionSizeUSD != 0 {
+		t.Errorf("unexpected close decision: %+v", decisions[2])
+	}
+}
+
+// TestRunTraderCycle Test that a panel cycle loads market data before debating so its open_long consensus
+// is executable, and that the cycle fails when no participant votes
+func TestRunTraderCycle(t *testing.T) {
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusBadRequest)
+			return
+		}
+		content := `<reasoning>trend</reasoning><decision>[{"symbol":"BTCUSDT","action":"open_long","confidence":80,"reasoning":"trend"}]</decision>` +
+			`<final_vote>[{"symbol":"BTCUSDT","action":"open_long","confidence":80,"leverage":3,"position_pct":0.2,"stop_loss":0.02,"take_profit":0.04,"reasoning":"trend"}]</final_vote>`
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	defer srv.Close()
+
+	st, err := store.New(filepath.Join(t.TempDir(), "nofx.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer st.Close()
+	for _, id := range []string{"m1", "m2"} {
+		if err := st.AIModel().Create("u1", id, id, "custom", true, "key", srv.URL); err != nil {
+			t.Fatalf("create model: %v", err)
+		}
+	}
+	panel := &store.DebateSession{UserID: "u1", Name: "panel", StrategyID: "s1", MaxRounds: 1, IntervalMinutes: 15}
+	if err := st.Debate().CreateSession(panel); err != nil {
+		t.Fatalf("create panel: %v", err)
+	}
+	for i, p := range []store.DebatePersonality{store.PersonalityBull, store.PersonalityBear} {
+		participant := &store.DebateParticipant{SessionID: panel.ID, AIModelID: fmt.Sprintf("m%d", i+1), AIModelName: string(p), Personality: p, SpeakOrder: i}
+		if err := st.Debate().AddParticipant(participant); err != nil {
+			t.Fatalf("add participant: %v", err)
+		}
+	}
+
+	prepared := 0
+	prepareMarketData = func(_ *kernel.StrategyEngine, ctx *kernel.Context) error {
+		prepared++
+		ctx.MarketDataMap = map[string]*market.Data{"BTCUSDT": {Symbol: "BTCUSDT", CurrentPrice: 100000}}
+		ctx.OITopDataMap = map[string]*kernel.OITopData{}
+		return nil
+	}
+	defer func() {
+		prepareMarketData = func(engine *kernel.StrategyEngine, ctx *kernel.Context) error { return engine.PrepareMarketData(ctx) }
+	}()
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	request := func() CycleRequest {
+		return CycleRequest{
+			PanelID:  panel.ID,
+			TraderID: "t1",
+			UserID:   "u1",
+			Strategy: kernel.NewStrategyEngine(&cfg),
+			Context: &kernel.Context{
+				Account:        kernel.AccountInfo{TotalEquity: 1000},
+				CandidateCoins: []kernel.CandidateCoin{{Symbol: "BTCUSDT"}},
+			},
+		}
+	}
+
+	e := NewTraderDebateEngine(st)
+	result, err := e.RunTraderCycle(request())
+	if err != nil {
+		t.Fatalf("RunTraderCycle failed: %v", err)
+	}
+	if prepared != 1 {
+		t.Errorf("expected market data to be prepared once, got %d", prepared)
+	}
+	if len(result.Decisions) != 1 {
+		t.Fatalf("expected the open_long consensus to be executable, got %+v", result.Decisions)
+	}
+	if d := result.Decisions[0]; d.Action != "open_long" || d.PositionSizeUSD != 200 || d.StopLoss <= 0 || d.StopLoss >= 100000 {
+		t.Errorf("unexpected decision: %+v", d)
+	}
+
+	down.Store(true)
+	if _, err := e.RunTraderCycle(request()); err == nil {
+		t.Fatal("expected the cycle to fail when no participant votes")
+	}
+	sessions, err := st.Debate().GetSessionsByUser("u1")
+	if err != nil {
+		t.Fatalf("list sessions: %v", err)
+	}
+	failed := 0
+	for _, s := range sessions {
+		if s.Status == store.DebateStatusFailed {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("expected the failed cycle's session to be marked failed, got %d failed sessions", failed)
+	}
+}
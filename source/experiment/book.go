@@ -0,0 +1,145 @@
+package experiment
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+)
+
+// Book a variant's hypothetical paper account. Decisions fill at the market price of the cycle they were
+// made in, stop-loss/take-profit are checked against the price of each later cycle, and equity is marked
+// to market every cycle.
+type Book struct {
+	Cash      float64                   `json:"cash"`
+	Positions map[string]*PaperPosition `json:"positions"` // By symbol, one position per symbol
+	Trades    int                       `json:"trades"`    // Positions opened so far
+}
+
+// PaperPosition an open hypothetical position
+type PaperPosition struct {
+	Side       string  `json:"side"` // long/short
+	Quantity   float64 `json:"quantity"`
+	EntryPrice float64 `json:"entry_price"`
+	Leverage   int     `json:"leverage"`
+	Margin     float64 `json:"margin"`
+	StopLoss   float64 `json:"stop_loss,omitempty"`
+	TakeProfit float64 `json:"take_profit,omitempty"`
+}
+
+// NewBook creates a paper account holding initialEquity in cash
+func NewBook(initialEquity float64) *Book {
+	return &Book{Cash: initialEquity, Positions: make(map[string]*PaperPosition)}
+}
+
+// loadBook restores a paper account, nil if state is empty or unreadable
+func loadBook(state string) *Book {
+	if state == "" {
+		return nil
+	}
+	var b Book
+	if err := json.Unmarshal([]byte(state), &b); err != nil {
+		return nil
+	}
+	if b.Positions == nil {
+		b.Positions = make(map[string]*PaperPosition)
+	}
+	return &b
+}
+
+func (b *Book) state() string {
+	data, _ := json.Marshal(b)
+	return string(data)
+}
+
+// value margin plus unrealized PnL of a position at price, never below zero (liquidated)
+func (p *PaperPosition) value(price float64) float64 {
+	pnl := (price - p.EntryPrice) * p.Quantity
+	if p.Side == "short" {
+		pnl = -pnl
+	}
+	return math.Max(p.Margin+pnl, 0)
+}
+
+// Equity cash plus the value of every position; positions without a price are valued at entry
+func (b *Book) Equity(prices map[string]float64) float64 {
+	equity := b.Cash
+	for symbol, p := range b.Positions {
+		price := prices[symbol]
+		if price <= 0 {
+			price = p.EntryPrice
+		}
+		equity += p.value(price)
+	}
+	return equity
+}
+
+// CheckExits closes positions whose stop-loss or take-profit was crossed, filling at the trigger price,
+// and positions that lost their whole margin
+func (b *Book) CheckExits(prices map[string]float64, feeRate float64) {
+	for symbol, p := range b.Positions {
+		price := prices[symbol]
+		if price <= 0 {
+			continue
+		}
+		long := p.Side == "long"
+		switch {
+		case p.StopLoss > 0 && ((long && price <= p.StopLoss) || (!long && price >= p.StopLoss)):
+			b.close(symbol, p.StopLoss, feeRate)
+		case p.TakeProfit > 0 && ((long && price >= p.TakeProfit) || (!long && price <= p.TakeProfit)):
+			b.close(symbol, p.TakeProfit, feeRate)
+		case p.value(price) == 0:
+			b.close(symbol, price, feeRate)
+		}
+	}
+}
+
+// Apply fills one decision at price. Opening reverses an opposite position and is ignored while a
+// position on the same side is open; the size is capped by the cash available.
+func (b *Book) Apply(symbol, action string, sizeUSD float64, leverage int, stopLoss, takeProfit, price, feeRate float64) {
+	if price <= 0 {
+		return
+	}
+	symbol = strings.ToUpper(symbol)
+	switch action {
+	case "open_long", "open_short":
+		side := strings.TrimPrefix(action, "open_")
+		if p, ok := b.Positions[symbol]; ok {
+			if p.Side == side {
+				return
+			}
+			b.close(symbol, price, feeRate)
+		}
+		if leverage <= 0 {
+			leverage = 1
+		}
+		notional := sizeUSD
+		if maxNotional := b.Cash / (1/float64(leverage) + feeRate); notional > maxNotional {
+			notional = maxNotional
+		}
+		if notional <= 0 {
+			return
+		}
+		margin := notional / float64(leverage)
+		b.Cash -= margin + notional*feeRate
+		b.Positions[symbol] = &PaperPosition{
+			Side:       side,
+			Quantity:   notional / price,
+			EntryPrice: price,
+			Leverage:   leverage,
+			Margin:     margin,
+			StopLoss:   stopLoss,
+			TakeProfit: takeProfit,
+		}
+		b.Trades++
+	case "close_long", "close_short":
+		if p, ok := b.Positions[symbol]; ok && p.Side == strings.TrimPrefix(action, "close_") {
+			b.close(symbol, price, feeRate)
+		}
+	}
+}
+
+func (b *Book) close(symbol string, price, feeRate float64) {
+	p := b.Positions[symbol]
+	b.Cash += p.value(price) - p.Quantity*price*feeRate
+	delete(b.Positions, symbol)
+}
@@ -0,0 +1,190 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// Runner records experiment cycles for live traders
+type Runner struct {
+	store  *store.ExperimentStore
+	price  func(symbol string) (float64, error) // Price of symbols missing from the cycle context
+	decide func(v *store.ExperimentVariant, client mcp.AIClient, ctx *kernel.Context) ([]kernel.Decision, error)
+}
+
+// NewRunner creates an experiment runner
+func NewRunner(experimentStore *store.ExperimentStore) *Runner {
+	return &Runner{
+		store:  experimentStore,
+		decide: decideShadow,
+		price: func(symbol string) (float64, error) {
+			data, err := market.Get(symbol)
+			if err != nil {
+				return 0, err
+			}
+			return data.CurrentPrice, nil
+		},
+	}
+}
+
+// RunCycle asks every shadow variant of exp to decide on the trader's cycle context, then records the
+// shadow decisions and the live decisions and moves every variant's paper account to this cycle
+func (r *Runner) RunCycle(exp *store.Experiment, client mcp.AIClient, ctx *kernel.Context, live []kernel.Decision) error {
+	variants, err := r.store.GetVariants(exp.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load experiment variants: %w", err)
+	}
+
+	// Shadow variants decide concurrently, each on its own copy of the context
+	type shadowResult struct {
+		decisions []kernel.Decision
+		err       error
+	}
+	results := make([]shadowResult, len(variants))
+	var wg sync.WaitGroup
+	for i, v := range variants {
+		if v.IsLive {
+			results[i].decisions = live
+			continue
+		}
+		wg.Add(1)
+		go func(i int, v *store.ExperimentVariant, ctx *kernel.Context) {
+			defer wg.Done()
+			results[i].decisions, results[i].err = r.decide(v, client, ctx)
+		}(i, v, shadowContext(ctx))
+	}
+	wg.Wait()
+
+	cycle := &store.ExperimentCycle{Cycle: exp.Cycles + 1, InitialEquity: exp.InitialEquity}
+	if cycle.InitialEquity <= 0 {
+		cycle.InitialEquity = ctx.Account.TotalEquity
+	}
+	feeRate := exp.FeeBps / 10000
+	now := time.Now().UTC()
+	prices := r.cyclePrices(ctx, variants)
+
+	for i, v := range variants {
+		book := loadBook(v.Book)
+		if book == nil {
+			book = NewBook(cycle.InitialEquity)
+		}
+		book.CheckExits(prices, feeRate)
+
+		res := results[i]
+		if res.err != nil {
+			logger.Warnf("⚠️ Experiment %s: variant %s failed: %v", exp.Name, v.Name, res.err)
+		}
+		for _, d := range res.decisions {
+			symbol := strings.ToUpper(d.Symbol)
+			price := prices[symbol]
+			book.Apply(symbol, d.Action, d.PositionSizeUSD, d.Leverage, d.StopLoss, d.TakeProfit, price, feeRate)
+			cycle.Decisions = append(cycle.Decisions, &store.ExperimentDecision{
+				ExperimentID:    exp.ID,
+				VariantID:       v.ID,
+				Cycle:           cycle.Cycle,
+				Symbol:          symbol,
+				Action:          d.Action,
+				Confidence:      d.Confidence,
+				Leverage:        d.Leverage,
+				PositionSizeUSD: d.PositionSizeUSD,
+				StopLoss:        d.StopLoss,
+				TakeProfit:      d.TakeProfit,
+				Price:           price,
+				Reasoning:       d.Reasoning,
+			})
+		}
+
+		v.Book = book.state()
+		v.Equity = book.Equity(prices)
+		cycle.Variants = append(cycle.Variants, v)
+		cycle.Points = append(cycle.Points, &store.ExperimentEquityPoint{
+			ExperimentID: exp.ID,
+			VariantID:    v.ID,
+			Cycle:        cycle.Cycle,
+			Equity:       v.Equity,
+			Failed:       res.err != nil,
+			Timestamp:    now,
+		})
+	}
+
+	if err := r.store.RecordCycle(exp.ID, cycle); err != nil {
+		return fmt.Errorf("failed to record experiment cycle: %w", err)
+	}
+	exp.Cycles, exp.InitialEquity = cycle.Cycle, cycle.InitialEquity
+	return nil
+}
+
+// shadowContext copies ctx with its own market data and OI maps, which a shadow variant's strategy fills
+// when they are missing
+func shadowContext(ctx *kernel.Context) *kernel.Context {
+	shadow := *ctx
+	if ctx.MarketDataMap != nil {
+		shadow.MarketDataMap = make(map[string]*market.Data, len(ctx.MarketDataMap))
+		for symbol, data := range ctx.MarketDataMap {
+			shadow.MarketDataMap[symbol] = data
+		}
+	}
+	if ctx.OITopDataMap != nil {
+		shadow.OITopDataMap = make(map[string]*kernel.OITopData, len(ctx.OITopDataMap))
+		for symbol, data := range ctx.OITopDataMap {
+			shadow.OITopDataMap[symbol] = data
+		}
+	}
+	return &shadow
+}
+
+// decideShadow runs one shadow variant's strategy over the cycle context
+func decideShadow(v *store.ExperimentVariant, client mcp.AIClient, ctx *kernel.Context) ([]kernel.Decision, error) {
+	var config store.StrategyConfig
+	if err := json.Unmarshal([]byte(v.Config), &config); err != nil {
+		return nil, fmt.Errorf("invalid variant config: %w", err)
+	}
+	promptVariant := v.PromptVariant
+	if promptVariant == "" {
+		promptVariant = "balanced"
+	}
+	decision, err := kernel.GetFullDecisionWithStrategy(ctx, client, kernel.NewStrategyEngine(&config), promptVariant)
+	if err != nil {
+		return nil, err
+	}
+	return decision.Decisions, nil
+}
+
+// cyclePrices current prices of the context's symbols and of every symbol a paper account holds
+func (r *Runner) cyclePrices(ctx *kernel.Context, variants []*store.ExperimentVariant) map[string]float64 {
+	prices := make(map[string]float64)
+	for symbol, data := range ctx.MarketDataMap {
+		if data != nil && data.CurrentPrice > 0 {
+			prices[strings.ToUpper(symbol)] = data.CurrentPrice
+		}
+	}
+	for _, p := range ctx.Positions {
+		if _, ok := prices[p.Symbol]; !ok && p.MarkPrice > 0 {
+			prices[p.Symbol] = p.MarkPrice
+		}
+	}
+	for _, v := range variants {
+		book := loadBook(v.Book)
+		if book == nil {
+			continue
+		}
+		for symbol := range book.Positions {
+			if _, ok := prices[symbol]; ok {
+				continue
+			}
+			if price, err := r.price(symbol); err == nil && price > 0 {
+				prices[symbol] = price
+			}
+		}
+	}
+	return prices
+}
@@ -0,0 +1,54 @@
+package experiment
+
+import (
+	"path/filepath"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// TestRunCycleShadowContexts Test that concurrent shadow variants fill their own market data maps and
+// leave the trader's context alone (run with -race)
+func TestRunCycleShadowContexts(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "nofx.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer st.Close()
+	exp := &store.Experiment{UserID: "u1", TraderID: "t1", Name: "exp"}
+	variants := []*store.ExperimentVariant{
+		{Name: "live", IsLive: true},
+		{Name: "a", Config: "{}"},
+		{Name: "b", Config: "{}"},
+	}
+	if err := st.Experiment().Create(exp, variants); err != nil {
+		t.Fatalf("create experiment: %v", err)
+	}
+
+	r := NewRunner(st.Experiment())
+	r.price = func(string) (float64, error) { return 100, nil }
+	// Fills the maps the way kernel.GetFullDecisionWithStrategy does
+	r.decide = func(v *store.ExperimentVariant, _ mcp.AIClient, ctx *kernel.Context) ([]kernel.Decision, error) {
+		ctx.MarketDataMap["ETHUSDT"] = &market.Data{Symbol: "ETHUSDT", CurrentPrice: 4000}
+		ctx.OITopDataMap = map[string]*kernel.OITopData{"ETHUSDT": {Rank: 1}}
+		return []kernel.Decision{{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 100, Leverage: 2}}, nil
+	}
+
+	ctx := &kernel.Context{
+		Account:       kernel.AccountInfo{TotalEquity: 1000},
+		MarketDataMap: map[string]*market.Data{"BTCUSDT": {Symbol: "BTCUSDT", CurrentPrice: 100}},
+	}
+	if err := r.RunCycle(exp, nil, ctx, nil); err != nil {
+		t.Fatalf("RunCycle failed: %v", err)
+	}
+	if len(ctx.MarketDataMap) != 1 || ctx.OITopDataMap != nil {
+		t.Errorf("shadow variants modified the trader's context: %v %v", ctx.MarketDataMap, ctx.OITopDataMap)
+	}
+	decisions, err := st.Experiment().GetDecisions(exp.ID)
+	if err != nil || len(decisions) != 2 {
+		t.Errorf("expected one decision per shadow variant, got %d (%v)", len(decisions), err)
+	}
+}
@@ -0,0 +1,316 @@
+package experiment
+
+import (
+	"math"
+
+	"nofx/store"
+)
+
+// significanceLevel p-value below which a variant's difference from the live variant is significant
+const significanceLevel = 0.05
+
+// minSignificanceSamples cycles needed before a difference can be called significant
+const minSignificanceSamples = 5
+
+// Comparison experiment results: every variant compared with the live variant
+type Comparison struct {
+	Experiment *store.Experiment `json:"experiment"`
+	Variants   []*VariantResult  `json:"variants"`
+}
+
+// VariantResult one variant's hypothetical performance
+type VariantResult struct {
+	VariantID      string                         `json:"variant_id"`
+	Name           string                         `json:"name"`
+	IsLive         bool                           `json:"is_live"`
+	StrategyID     string                         `json:"strategy_id,omitempty"`
+	PromptVariant  string                         `json:"prompt_variant,omitempty"`
+	Decisions      int                            `json:"decisions"` // Decisions other than hold/wait
+	Trades         int                            `json:"trades"`    // Hypothetical positions opened
+	FailedCycles   int                            `json:"failed_cycles"`
+	Equity         float64                        `json:"equity"`
+	ReturnPct      float64                        `json:"return_pct"`
+	MaxDrawdownPct float64                        `json:"max_drawdown_pct"`
+	EquityCurve    []*store.ExperimentEquityPoint `json:"equity_curve"`
+	Divergence     *Divergence                    `json:"divergence,omitempty"`   // Against the live variant
+	Significance   *Significance                  `json:"significance,omitempty"` // Against the live variant
+}
+
+// Divergence how often a variant decided differently from the live variant on the same context
+type Divergence struct {
+	Cycles          int     `json:"cycles"`           // Cycles both variants decided in
+	DivergentCycles int     `json:"divergent_cycles"` // Cycles with at least one different action
+	Decisions       int     `json:"decisions"`        // Symbol decisions compared (missing counts as wait)
+	Divergent       int     `json:"divergent"`        // Symbol decisions with a different action
+	Rate            float64 `json:"rate"`             // Divergent / Decisions
+}
+
+// Significance paired t-test of per-cycle hypothetical returns against the live variant
+type Significance struct {
+	Samples     int     `json:"samples"`
+	MeanDiffPct float64 `json:"mean_diff_pct"` // Mean per-cycle return difference (variant - live), in percent
+	TStat       float64 `json:"t_stat"`
+	PValue      float64 `json:"p_value"` // Two-sided
+	Significant bool    `json:"significant"`
+}
+
+// Compare builds the comparison of an experiment's variants from what was recorded
+func Compare(exp *store.Experiment, variants []*store.ExperimentVariant, decisions []*store.ExperimentDecision, points []*store.ExperimentEquityPoint) *Comparison {
+	// cycle -> symbol -> action, per variant
+	actions := make(map[string]map[int]map[string]string)
+	decisionCount := make(map[string]int)
+	for _, d := range decisions {
+		byCycle := actions[d.VariantID]
+		if byCycle == nil {
+			byCycle = make(map[int]map[string]string)
+			actions[d.VariantID] = byCycle
+		}
+		if byCycle[d.Cycle] == nil {
+			byCycle[d.Cycle] = make(map[string]string)
+		}
+		action := normalizeAction(d.Action)
+		byCycle[d.Cycle][d.Symbol] = action
+		if action != "wait" {
+			decisionCount[d.VariantID]++
+		}
+	}
+	curves := make(map[string][]*store.ExperimentEquityPoint)
+	for _, p := range points {
+		curves[p.VariantID] = append(curves[p.VariantID], p)
+	}
+
+	var live *store.ExperimentVariant
+	for _, v := range variants {
+		if v.IsLive {
+			live = v
+			break
+		}
+	}
+
+	result := &Comparison{Experiment: exp}
+	for _, v := range variants {
+		curve := curves[v.ID]
+		if curve == nil {
+			curve = []*store.ExperimentEquityPoint{}
+		}
+		r := &VariantResult{
+			VariantID:     v.ID,
+			Name:          v.Name,
+			IsLive:        v.IsLive,
+			StrategyID:    v.StrategyID,
+			PromptVariant: v.PromptVariant,
+			Decisions:     decisionCount[v.ID],
+			Equity:        v.Equity,
+			EquityCurve:   curve,
+		}
+		if book := loadBook(v.Book); book != nil {
+			r.Trades = book.Trades
+		}
+		for _, p := range curve {
+			if p.Failed {
+				r.FailedCycles++
+			}
+		}
+		if exp.InitialEquity > 0 {
+			r.ReturnPct = (v.Equity/exp.InitialEquity - 1) * 100
+			r.MaxDrawdownPct = maxDrawdownPct(exp.InitialEquity, curve)
+		}
+		if live != nil && !v.IsLive {
+			r.Divergence = divergence(actions[live.ID], actions[v.ID], curves[live.ID], curve)
+			r.Significance = pairedTTest(cycleReturns(curve), cycleReturns(curves[live.ID]))
+		}
+		result.Variants = append(result.Variants, r)
+	}
+	return result
+}
+
+// normalizeAction treats hold and wait as the same "no change" decision
+func normalizeAction(action string) string {
+	if action == "hold" || action == "" {
+		return "wait"
+	}
+	return action
+}
+
+// divergence compares the actions of two variants in every cycle where both decided
+func divergence(live, variant map[int]map[string]string, livePoints, variantPoints []*store.ExperimentEquityPoint) *Divergence {
+	decided := func(points []*store.ExperimentEquityPoint) map[int]bool {
+		cycles := make(map[int]bool, len(points))
+		for _, p := range points {
+			cycles[p.Cycle] = !p.Failed
+		}
+		return cycles
+	}
+	liveCycles, variantCycles := decided(livePoints), decided(variantPoints)
+
+	d := &Divergence{}
+	for cycle, ok := range variantCycles {
+		if !ok || !liveCycles[cycle] {
+			continue
+		}
+		d.Cycles++
+		symbols := make(map[string]bool)
+		for s := range live[cycle] {
+			symbols[s] = true
+		}
+		for s := range variant[cycle] {
+			symbols[s] = true
+		}
+		differs := false
+		for s := range symbols {
+			d.Decisions++
+			if actionOf(live[cycle], s) != actionOf(variant[cycle], s) {
+				d.Divergent++
+				differs = true
+			}
+		}
+		if differs {
+			d.DivergentCycles++
+		}
+	}
+	if d.Decisions > 0 {
+		d.Rate = float64(d.Divergent) / float64(d.Decisions)
+	}
+	return d
+}
+
+func actionOf(actions map[string]string, symbol string) string {
+	if a, ok := actions[symbol]; ok {
+		return a
+	}
+	return "wait"
+}
+
+// cycleReturns per-cycle returns keyed by the cycle they end in
+func cycleReturns(points []*store.ExperimentEquityPoint) map[int]float64 {
+	returns := make(map[int]float64, len(points))
+	for i := 1; i < len(points); i++ {
+		if prev := points[i-1].Equity; prev > 0 {
+			returns[points[i].Cycle] = points[i].Equity/prev - 1
+		}
+	}
+	return returns
+}
+
+// pairedTTest tests whether the mean per-cycle return difference between a variant and the live variant is zero
+func pairedTTest(variant, live map[int]float64) *Significance {
+	var diffs []float64
+	for cycle, r := range variant {
+		if l, ok := live[cycle]; ok {
+			diffs = append(diffs, r-l)
+		}
+	}
+	s := &Significance{Samples: len(diffs), PValue: 1}
+	if len(diffs) < 2 {
+		return s
+	}
+
+	n := float64(len(diffs))
+	var mean float64
+	for _, d := range diffs {
+		mean += d
+	}
+	mean /= n
+	var variance float64
+	for _, d := range diffs {
+		variance += (d - mean) * (d - mean)
+	}
+	variance /= n - 1
+	s.MeanDiffPct = mean * 100
+
+	stdErr := math.Sqrt(variance / n)
+	switch {
+	case stdErr > 0:
+		s.TStat = mean / stdErr
+		s.PValue = studentTwoSidedP(s.TStat, n-1)
+	case mean != 0:
+		s.TStat = math.Copysign(math.Inf(1), mean)
+		s.PValue = 0
+	}
+	s.Significant = s.Samples >= minSignificanceSamples && s.PValue < significanceLevel
+	return s
+}
+
+// studentTwoSidedP two-sided p-value of Student's t distribution with df degrees of freedom
+func studentTwoSidedP(t, df float64) float64 {
+	return regularizedIncompleteBeta(df/(df+t*t), df/2, 0.5)
+}
+
+// regularizedIncompleteBeta I_x(a, b), evaluated with Lentz's continued fraction
+func regularizedIncompleteBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	lga, _ := math.Lgamma(a)
+	lgb, _ := math.Lgamma(b)
+	lgab, _ := math.Lgamma(a + b)
+	front := math.Exp(lgab - lga - lgb + a*math.Log(x) + b*math.Log(1-x))
+	// The continued fraction converges quickly only below the mean; use the symmetry relation above it
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaContinuedFraction(1-x, b, a)/b
+	}
+	return front * betaContinuedFraction(x, a, b) / a
+}
+
+func betaContinuedFraction(x, a, b float64) float64 {
+	const (
+		maxIterations = 200
+		eps           = 1e-14
+		tiny          = 1e-300
+	)
+	c, d := 1.0, 1-(a+b)*x/(a+1)
+	if math.Abs(d) < tiny {
+		d = tiny
+	}
+	d = 1 / d
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		// Even step
+		num := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		h *= d * c
+		// Odd step
+		num = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 + num*d
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = 1 + num/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < eps {
+			break
+		}
+	}
+	return h
+}
+
+// maxDrawdownPct largest peak-to-trough equity drop, in percent
+func maxDrawdownPct(initialEquity float64, points []*store.ExperimentEquityPoint) float64 {
+	peak, maxDD := initialEquity, 0.0
+	for _, p := range points {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if peak > 0 {
+			maxDD = math.Max(maxDD, (peak-p.Equity)/peak*100)
+		}
+	}
+	return maxDD
+}
@@ -0,0 +1,102 @@
+package experiment
+
+import (
+	"math"
+	"testing"
+
+	"nofx/store"
+)
+
+// TestBookApply Test hypothetical fills, reversal, stop-loss exits and equity marking
+func TestBookApply(t *testing.T) {
+	b := NewBook(1000)
+	b.Apply("btcusdt", "open_long", 500, 5, 95, 120, 100, 0)
+	if b.Cash != 900 || b.Trades != 1 {
+		t.Fatalf("expected 100 margin taken, got cash %.2f trades %d", b.Cash, b.Trades)
+	}
+	if eq := b.Equity(map[string]float64{"BTCUSDT": 110}); math.Abs(eq-1050) > 1e-9 {
+		t.Errorf("expected equity 1050 at 110, got %.2f", eq)
+	}
+
+	// Same side is ignored, opposite side reverses
+	b.Apply("BTCUSDT", "open_long", 500, 5, 0, 0, 110, 0)
+	if b.Trades != 1 {
+		t.Errorf("stacking the same side should be ignored")
+	}
+	b.Apply("BTCUSDT", "open_short", 200, 2, 120, 0, 110, 0)
+	if p := b.Positions["BTCUSDT"]; p == nil || p.Side != "short" || math.Abs(b.Cash-950) > 1e-9 {
+		t.Fatalf("expected reversal to a short with cash 950, got %+v cash %.2f", p, b.Cash)
+	}
+
+	// Stop-loss fills at the stop price
+	b.CheckExits(map[string]float64{"BTCUSDT": 125}, 0)
+	if len(b.Positions) != 0 {
+		t.Fatal("stop-loss should have closed the short")
+	}
+	// Short 200/110 = 1.818 BTC from 110 to 120: -18.18 on 100 margin
+	if math.Abs(b.Cash-(1050-200.0/110*10)) > 1e-9 {
+		t.Errorf("unexpected cash after stop-loss: %.4f", b.Cash)
+	}
+
+	restored := loadBook(b.state())
+	if restored == nil || restored.Cash != b.Cash || restored.Trades != 2 {
+		t.Errorf("book did not round-trip: %+v", restored)
+	}
+}
+
+// TestStudentPValue Test the t distribution against table values
+func TestStudentPValue(t *testing.T) {
+	tests := []struct {
+		t, df, want float64
+	}{
+		{2.262, 9, 0.05},
+		{1.96, 10000, 0.05},
+		{0, 5, 1},
+		{4.032, 5, 0.01},
+	}
+	for _, tt := range tests {
+		if got := studentTwoSidedP(tt.t, tt.df); math.Abs(got-tt.want) > 5e-4 {
+			t.Errorf("p(t=%.3f, df=%.0f) = %.5f, want %.3f", tt.t, tt.df, got, tt.want)
+		}
+	}
+}
+
+// TestCompare Test divergence against the live variant and significance of return differences
+func TestCompare(t *testing.T) {
+	exp := &store.Experiment{ID: "e1", InitialEquity: 1000}
+	variants := []*store.ExperimentVariant{
+		{ID: "live", Name: "live", IsLive: true, Equity: 1000},
+		{ID: "b", Name: "B", Equity: 1060},
+	}
+	decisions := []*store.ExperimentDecision{
+		{VariantID: "live", Cycle: 1, Symbol: "BTCUSDT", Action: "wait"},
+		{VariantID: "b", Cycle: 1, Symbol: "BTCUSDT", Action: "hold"},
+		{VariantID: "live", Cycle: 2, Symbol: "BTCUSDT", Action: "open_long"},
+		{VariantID: "b", Cycle: 2, Symbol: "BTCUSDT", Action: "open_long"},
+		{VariantID: "b", Cycle: 2, Symbol: "ETHUSDT", Action: "open_short"},
+		{VariantID: "b", Cycle: 3, Symbol: "BTCUSDT", Action: "close_long"},
+	}
+	var points []*store.ExperimentEquityPoint
+	for cycle := 1; cycle <= 8; cycle++ {
+		points = append(points,
+			&store.ExperimentEquityPoint{VariantID: "live", Cycle: cycle, Equity: 1000},
+			&store.ExperimentEquityPoint{VariantID: "b", Cycle: cycle, Equity: 1000 + float64(cycle*cycle), Failed: cycle == 4},
+		)
+	}
+
+	cmp := Compare(exp, variants, decisions, points)
+	if len(cmp.Variants) != 2 || cmp.Variants[0].Divergence != nil {
+		t.Fatalf("live variant should not be compared with itself: %+v", cmp.Variants)
+	}
+	b := cmp.Variants[1]
+	// Cycle 1 agrees (hold = wait), cycle 2 diverges on ETH, cycle 3 diverges on BTC, cycle 4 failed
+	if d := b.Divergence; d.Cycles != 7 || d.DivergentCycles != 2 || d.Decisions != 4 || d.Divergent != 2 {
+		t.Errorf("unexpected divergence: %+v", d)
+	}
+	if b.Decisions != 3 || b.FailedCycles != 1 || math.Abs(b.ReturnPct-6) > 1e-9 {
+		t.Errorf("unexpected result: %+v", b)
+	}
+	if s := b.Significance; s.Samples != 7 || s.MeanDiffPct <= 0 || !s.Significant {
+		t.Errorf("steadily outperforming variant should be significant: %+v", s)
+	}
+}
@@ -0,0 +1,242 @@
+package kernel
+
+import (
+	"context"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/provider/coinank"
+	"nofx/provider/coinank/coinank_enum"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Derivatives Data (CoinAnk liquidations, long/short ratio, OI vs market cap)
+// ============================================================================
+
+const (
+	derivativesCacheTTL       = 5 * time.Minute
+	derivativesRequestTimeout = 15 * time.Second
+	liquidationHistoryBars    = 24
+	liquidationOrderMinAmount = 10000 // only orders above 10K USD are used for clusters
+	liquidationClusterPct     = 0.5   // cluster band width (% of price)
+	liquidationMaxClusters    = 3
+	longShortRankSize         = 100
+)
+
+// derivativesCacheEntry cached CoinAnk response
+type derivativesCacheEntry struct {
+	Value     interface{}
+	UpdatedAt time.Time
+}
+
+// derivativesCache map[cacheKey]*derivativesCacheEntry, shared by all strategy engines
+var derivativesCache sync.Map
+
+func getCachedDerivatives(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if cached, ok := derivativesCache.Load(key); ok {
+		entry := cached.(*derivativesCacheEntry)
+		if time.Since(entry.UpdatedAt) < derivativesCacheTTL {
+			return entry.Value, nil
+		}
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	derivativesCache.Store(key, &derivativesCacheEntry{Value: value, UpdatedAt: time.Now()})
+	return value, nil
+}
+
+// derivativesEnabled reports whether any CoinAnk derivatives section is enabled
+func (e *StrategyEngine) derivativesEnabled() bool {
+	indicators := e.config.Indicators
+	return indicators.EnableLiquidationData || indicators.EnableLongShortRatio || indicators.EnableOIVsMarketCap
+}
+
+// FetchDerivativesDataBatch batch fetches liquidation, long/short ratio and OI/market cap data
+func (e *StrategyEngine) FetchDerivativesDataBatch(symbols []string) map[string]*coinank.SymbolSignals {
+	result := make(map[string]*coinank.SymbolSignals)
+
+	if !e.derivativesEnabled() {
+		return result
+	}
+	if e.coinankClient == nil {
+		logger.Warnf("⚠️  CoinAnk API key is not configured, skipping derivatives data")
+		return result
+	}
+
+	for _, symbol := range symbols {
+		data := e.FetchDerivativesData(symbol)
+		if !data.IsEmpty() {
+			result[symbol] = data
+		}
+	}
+
+	return result
+}
+
+// FetchDerivativesData fetches enabled derivatives sections for a single coin (failed sections are omitted)
+func (e *StrategyEngine) FetchDerivativesData(symbol string) *coinank.SymbolSignals {
+	if e.coinankClient == nil || market.ClassifySymbol(symbol) != market.AssetCrypto {
+		return nil
+	}
+
+	symbol = market.Normalize(symbol)
+	indicators := e.config.Indicators
+	data := &coinank.SymbolSignals{
+		Symbol:    symbol,
+		BaseCoin:  strings.TrimSuffix(symbol, "USDT"),
+		FetchedAt: time.Now(),
+	}
+
+	if indicators.EnableLiquidationData {
+		liq, err := e.fetchLiquidationSummary(symbol, data.BaseCoin)
+		if err != nil {
+			logger.Infof("⚠️  Failed to fetch liquidation data for %s: %v", symbol, err)
+		} else {
+			data.Liquidation = liq
+		}
+	}
+
+	if indicators.EnableLongShortRatio {
+		ls, err := e.fetchLongShortSummary(symbol, data.BaseCoin)
+		if err != nil {
+			logger.Infof("⚠️  Failed to fetch long/short data for %s: %v", symbol, err)
+		} else {
+			data.LongShort = ls
+		}
+	}
+
+	if indicators.EnableOIVsMarketCap {
+		oi, err := e.fetchOIVsMarketCap(data.BaseCoin)
+		if err != nil {
+			logger.Infof("⚠️  Failed to fetch OI/market cap data for %s: %v", symbol, err)
+		} else {
+			data.OIVsMC = oi
+		}
+	}
+
+	return data
+}
+
+func (e *StrategyEngine) fetchLiquidationSummary(symbol, baseCoin string) (*coinank.LiquidationSummary, error) {
+	interval := e.config.Indicators.LiquidationInterval
+	if interval == "" {
+		interval = "1h"
+	}
+
+	history, err := getCachedDerivatives("liq_history:"+symbol+":"+interval, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), derivativesRequestTimeout)
+		defer cancel()
+		return e.coinankClient.LiquidationHistory(ctx, coinank_enum.Binance, symbol,
+			coinank_enum.Interval(interval), time.Now().UnixMilli(), liquidationHistoryBars)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Large orders are only used for clusters, so a failure here keeps the history totals
+	var orderList []coinank.LiquidationOrdersResponse
+	orders, err := getCachedDerivatives("liq_orders:"+baseCoin, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), derivativesRequestTimeout)
+		defer cancel()
+		return e.coinankClient.LiquidationOrders(ctx, baseCoin, "", "", liquidationOrderMinAmount, 0)
+	})
+	if err != nil {
+		logger.Infof("⚠️  Failed to fetch liquidation orders for %s: %v", baseCoin, err)
+	} else {
+		orderList = orders.([]coinank.LiquidationOrdersResponse)
+	}
+
+	return coinank.BuildLiquidationSummary(interval, history.([]coinank.LiquidationSymbol), orderList,
+		liquidationClusterPct, liquidationMaxClusters), nil
+}
+
+func (e *StrategyEngine) fetchLongShortSummary(symbol, baseCoin string) (*coinank.LongShortSummary, error) {
+	// The ranking covers the whole market in one request, so it is cached once for all symbols
+	rank, err := getCachedDerivatives("ls_rank", func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), derivativesRequestTimeout)
+		defer cancel()
+		return e.coinankClient.LongShortRank(ctx, coinank_enum.OpenInterest, coinank_enum.Desc, 1, longShortRankSize)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var summary *coinank.LongShortSummary
+	for _, item := range rank.([]coinank.LongShortRankResponse) {
+		if strings.EqualFold(item.BaseCoin, baseCoin) {
+			summary = &coinank.LongShortSummary{
+				Ratio: item.LongShortPerson,
+				Chg1H: item.LsPersonChg1H,
+				Chg4H: item.LsPersonChg4H,
+			}
+			break
+		}
+	}
+
+	netPositions, err := getCachedDerivatives("net_positions:"+symbol, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), derivativesRequestTimeout)
+		defer cancel()
+		return e.coinankClient.NetPositions(ctx, coinank_enum.Binance, symbol, coinank_enum.Hour1, time.Now().UnixMilli(), 2)
+	})
+	if err != nil {
+		logger.Infof("⚠️  Failed to fetch net positions for %s: %v", symbol, err)
+	} else if list := netPositions.([]coinank.NetPositionsResponse); len(list) > 0 {
+		if summary == nil {
+			summary = &coinank.LongShortSummary{}
+		}
+		latest := list[len(list)-1]
+		summary.HasNetPos = true
+		summary.NetLongs = latest.NetLongsClose
+		summary.NetShorts = latest.NetShortsClose
+		if len(list) > 1 {
+			prev := list[len(list)-2]
+			summary.NetLongsChg = latest.NetLongsClose - prev.NetLongsClose
+			summary.NetShortChg = latest.NetShortsClose - prev.NetShortsClose
+		}
+	}
+
+	return summary, nil
+}
+
+func (e *StrategyEngine) fetchOIVsMarketCap(baseCoin string) (*coinank.OIVsMarketCapSummary, error) {
+	history, err := getCachedDerivatives("oi_vs_mc:"+baseCoin, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), derivativesRequestTimeout)
+		defer cancel()
+		return e.coinankClient.InstrumentsOiVsMc(ctx, baseCoin, coinank_enum.Hour1, time.Now().UnixMilli(), 24)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := history.([]coinank.InstrumentsOiVsMcResponse)
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	oldest, latest := list[0], list[len(list)-1]
+	if oldest.Ts > latest.Ts {
+		oldest, latest = latest, oldest
+	}
+	summary := &coinank.OIVsMarketCapSummary{
+		OIVsMarketCap:  latest.OiVsMar,
+		VolVsMarketCap: latest.VolVsMar,
+		OIVsVolume:     latest.OiVsVol,
+	}
+	if oldest.OiVsMar > 0 {
+		summary.OIVsMarketCapCh = (latest.OiVsMar - oldest.OiVsMar) / oldest.OiVsMar * 100
+	}
+	return summary, nil
+}
+
+// coinankLanguage maps the engine language to the coinank formatter language
+func (e *StrategyEngine) coinankLanguage() coinank.Language {
+	if e.GetLanguage() == LangChinese {
+		return coinank.LangChinese
+	}
+	return coinank.LangEnglish
+}
@@ -0,0 +1,60 @@
+package kernel
+
+import (
+	"nofx/market"
+	"nofx/provider/coinank"
+	"nofx/store"
+	"strings"
+	"testing"
+)
+
+// TestBuildUserPromptDerivativesData 测试候选币种中的衍生品数据段
+func TestBuildUserPromptDerivativesData(t *testing.T) {
+	ctx := &Context{
+		Account:        AccountInfo{TotalEquity: 1000, AvailableBalance: 1000},
+		CandidateCoins: []CandidateCoin{{Symbol: "SOLUSDT", Sources: []string{"static"}}},
+		MarketDataMap: map[string]*market.Data{
+			"SOLUSDT": {Symbol: "SOLUSDT", CurrentPrice: 150},
+		},
+		DerivativesDataMap: map[string]*coinank.SymbolSignals{
+			"SOLUSDT": {
+				Symbol: "SOLUSDT",
+				Liquidation: &coinank.LiquidationSummary{
+					Interval: "1h", Bars: 24, LastLongTurnover: 1.5e6, TotalShortTurnover: 2e6,
+				},
+				LongShort: &coinank.LongShortSummary{Ratio: 2.35, Chg1H: 1.2},
+				OIVsMC:    &coinank.OIVsMarketCapSummary{OIVsMarketCap: 0.085},
+			},
+		},
+	}
+
+	t.Run("English", func(t *testing.T) {
+		config := store.GetDefaultStrategyConfig("en")
+		prompt := NewStrategyEngine(&config).BuildUserPrompt(ctx)
+		for _, keyword := range []string{"SOLUSDT Derivatives Positioning", "$1.50M", "L/S ratio: 2.35", "OI/MarketCap: 0.0850"} {
+			if !strings.Contains(prompt, keyword) {
+				t.Errorf("user prompt should contain '%s'", keyword)
+			}
+		}
+	})
+
+	t.Run("Chinese", func(t *testing.T) {
+		config := store.GetDefaultStrategyConfig("zh")
+		prompt := NewStrategyEngine(&config).BuildUserPrompt(ctx)
+		for _, keyword := range []string{"衍生品持仓数据", "大户多空比: 2.35", "持仓/市值"} {
+			if !strings.Contains(prompt, keyword) {
+				t.Errorf("user prompt should contain '%s'", keyword)
+			}
+		}
+	})
+
+	t.Run("NoDataNoSection", func(t *testing.T) {
+		config := store.GetDefaultStrategyConfig("en")
+		empty := *ctx
+		empty.DerivativesDataMap = nil
+		prompt := NewStrategyEngine(&config).BuildUserPrompt(&empty)
+		if strings.Contains(prompt, "Derivatives Positioning") {
+			t.Error("derivatives section should be omitted without data")
+		}
+	})
+}
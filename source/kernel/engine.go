@@ -8,6 +8,8 @@ import (
 	"nofx/logger"
 	"nofx/market"
 	"nofx/mcp"
+	"nofx/provider/coinank"
+	"nofx/provider/coinank/coinank_enum"
 	"nofx/provider/nofxos"
 	"nofx/security"
 	"nofx/store"
@@ -106,25 +108,30 @@ type RecentOrder struct {
 
 // Context trading context (complete information passed to AI)
 type Context struct {
-	CurrentTime        string                             `json:"current_time"`
-	RuntimeMinutes     int                                `json:"runtime_minutes"`
-	CallCount          int                                `json:"call_count"`
-	Account            AccountInfo                        `json:"account"`
-	Positions          []PositionInfo                     `json:"positions"`
-	CandidateCoins     []CandidateCoin                    `json:"candidate_coins"`
-	PromptVariant      string                             `json:"prompt_variant,omitempty"`
-	TradingStats       *TradingStats                      `json:"trading_stats,omitempty"`
-	RecentOrders       []RecentOrder                      `json:"recent_orders,omitempty"`
-	MarketDataMap      map[string]*market.Data            `json:"-"`
-	MultiTFMarket      map[string]map[string]*market.Data `json:"-"`
-	OITopDataMap       map[string]*OITopData              `json:"-"`
-	QuantDataMap       map[string]*QuantData              `json:"-"`
-	OIRankingData      *nofxos.OIRankingData              `json:"-"` // Market-wide OI ranking data
-	NetFlowRankingData *nofxos.NetFlowRankingData         `json:"-"` // Market-wide fund flow ranking data
-	PriceRankingData   *nofxos.PriceRankingData           `json:"-"` // Market-wide price gainers/losers
-	BTCETHLeverage     int                                `json:"-"`
-	AltcoinLeverage    int                                `json:"-"`
-	Timeframes         []string                           `json:"-"`
+	CurrentTime        string                               `json:"current_time"`
+	RuntimeMinutes     int                                  `json:"runtime_minutes"`
+	CallCount          int                                  `json:"call_count"`
+	Account            AccountInfo                          `json:"account"`
+	Positions          []PositionInfo                       `json:"positions"`
+	CandidateCoins     []CandidateCoin                      `json:"candidate_coins"`
+	PromptVariant      string                               `json:"prompt_variant,omitempty"`
+	TradingStats       *TradingStats                        `json:"trading_stats,omitempty"`
+	RecentOrders       []RecentOrder                        `json:"recent_orders,omitempty"`
+	MarketDataMap      map[string]*market.Data              `json:"-"`
+	MultiTFMarket      map[string]map[string]*market.Data   `json:"-"`
+	OITopDataMap       map[string]*OITopData                `json:"-"`
+	QuantDataMap       map[string]*QuantData                `json:"-"`
+	OIRankingData      *nofxos.OIRankingData                `json:"-"` // Market-wide OI ranking data
+	NetFlowRankingData *nofxos.NetFlowRankingData           `json:"-"` // Market-wide fund flow ranking data
+	PriceRankingData   *nofxos.PriceRankingData             `json:"-"` // Market-wide price gainers/losers
+	DerivativesDataMap map[string]*coinank.SymbolSignals    `json:"-"` // Per-coin liquidations, long/short ratio, OI/market cap
+	OrderBookMap       map[string]*market.OrderBookFeatures `json:"-"` // Per-coin order book spread/depth/imbalance
+	MarketSessions     map[string]*market.MarketStatus      `json:"-"` // Trading session of stock/forex/commodity symbols
+	TriggerEvent       *store.MarketEvent                   `json:"-"` // Market event that launched this cycle (nil for scheduled cycles)
+	PositionHistory    PositionHistoryFunc                  `json:"-"` // Closed trades per symbol for the get_position_history tool (nil = unavailable)
+	BTCETHLeverage     int                                  `json:"-"`
+	AltcoinLeverage    int                                  `json:"-"`
+	Timeframes         []string                             `json:"-"`
 }
 
 // Decision AI trading decision
@@ -153,6 +160,11 @@ type FullDecision struct {
 	RawResponse         string     `json:"raw_response"`
 	Timestamp           time.Time  `json:"timestamp"`
 	AIRequestDurationMs int64      `json:"ai_request_duration_ms,omitempty"`
+
+	ToolCalls        []ToolCallRecord `json:"tool_calls,omitempty"`        // Data tools the AI called, in order
+	StructuredOutput bool             `json:"structured_output,omitempty"` // The provider enforced the decision schema
+	RepairError      string           `json:"repair_error,omitempty"`      // Validation error of the first answer when a repair was asked for
+	AIModel          string           `json:"ai_model,omitempty"`          // Provider/model that answered (the fallback that did, for failover chains)
 }
 
 // QuantData quantitative data structure (fund flow, position changes, price changes)
@@ -191,8 +203,9 @@ type OIDeltaData struct {
 
 // StrategyEngine strategy execution engine
 type StrategyEngine struct {
-	config       *store.StrategyConfig
-	nofxosClient *nofxos.Client
+	config        *store.StrategyConfig
+	nofxosClient  *nofxos.Client
+	coinankClient *coinank.CoinankClient // nil when no CoinAnk API key is configured
 }
 
 // NewStrategyEngine creates strategy execution engine
@@ -204,10 +217,17 @@ func NewStrategyEngine(config *store.StrategyConfig) *StrategyEngine {
 	}
 	client := nofxos.NewClient(nofxos.DefaultBaseURL, apiKey)
 
-	return &StrategyEngine{
+	engine := &StrategyEngine{
 		config:       config,
 		nofxosClient: client,
 	}
+
+	// CoinAnk OpenAPI requires a key, derivatives sections are skipped without one
+	if config.Indicators.CoinankAPIKey != "" {
+		engine.coinankClient = coinank.NewCoinankClient(coinank_enum.MainUrl, config.Indicators.CoinankAPIKey)
+	}
+
+	return engine
 }
 
 // GetRiskControlConfig gets risk control configuration
@@ -245,27 +265,20 @@ func GetFullDecision(ctx *Context, mcpClient mcp.AIClient) (*FullDecision, error
 	return GetFullDecisionWithStrategy(ctx, mcpClient, engine, "")
 }
 
-// GetFullDecisionWithStrategy uses StrategyEngine to get AI decision (unified prompt generation)
-func GetFullDecisionWithStrategy(ctx *Context, mcpClient mcp.AIClient, engine *StrategyEngine, variant string) (*FullDecision, error) {
-	if ctx == nil {
-		return nil, fmt.Errorf("context is nil")
-	}
-	if engine == nil {
-		defaultConfig := store.GetDefaultStrategyConfig("en")
-		engine = NewStrategyEngine(&defaultConfig)
-	}
-
-	// 1. Fetch market data using strategy config
+// PrepareMarketData fetches the market data of ctx's candidates and positions (when MarketDataMap is
+// empty) and the OI ranking (when OITopDataMap is nil) from the strategy's sources. Decision paths that
+// build prompts themselves (debate panels, shadow variants) call it before sharing ctx.
+func (e *StrategyEngine) PrepareMarketData(ctx *Context) error {
 	if len(ctx.MarketDataMap) == 0 {
-		if err := fetchMarketDataWithStrategy(ctx, engine); err != nil {
-			return nil, fmt.Errorf("failed to fetch market data: %w", err)
+		if err := fetchMarketDataWithStrategy(ctx, e); err != nil {
+			return fmt.Errorf("failed to fetch market data: %w", err)
 		}
 	}
 
 	// Ensure OITopDataMap is initialized
 	if ctx.OITopDataMap == nil {
 		ctx.OITopDataMap = make(map[string]*OITopData)
-		oiPositions, err := engine.nofxosClient.GetOITopPositions()
+		oiPositions, err := e.nofxosClient.GetOITopPositions()
 		if err == nil {
 			for _, pos := range oiPositions {
 				ctx.OITopDataMap[pos.Symbol] = &OITopData{
@@ -277,31 +290,60 @@ func GetFullDecisionWithStrategy(ctx *Context, mcpClient mcp.AIClient, engine *S
 			}
 		}
 	}
+	return nil
+}
+
+// GetFullDecisionWithStrategy uses StrategyEngine to get AI decision (unified prompt generation)
+func GetFullDecisionWithStrategy(ctx *Context, mcpClient mcp.AIClient, engine *StrategyEngine, variant string) (*FullDecision, error) {
+	if ctx == nil {
+		return nil, fmt.Errorf("context is nil")
+	}
+	if engine == nil {
+		defaultConfig := store.GetDefaultStrategyConfig("en")
+		engine = NewStrategyEngine(&defaultConfig)
+	}
+
+	// 1. Fetch market data using strategy config
+	if err := engine.PrepareMarketData(ctx); err != nil {
+		return nil, err
+	}
 
 	// 2. Build System Prompt using strategy engine
-	riskConfig := engine.GetRiskControlConfig()
 	systemPrompt := engine.BuildSystemPrompt(ctx.Account.TotalEquity, variant)
 
 	// 3. Build User Prompt using strategy engine
 	userPrompt := engine.BuildUserPrompt(ctx)
 
-	// 4. Call AI API
+	// 4. Call AI API (with the data tools and the decision schema when the strategy enables them)
 	aiCallStart := time.Now()
-	aiResponse, err := mcpClient.CallWithMessages(systemPrompt, userPrompt)
-	aiCallDuration := time.Since(aiCallStart)
+	// A failover chain is shared by concurrent cycles, this cycle's calls get their own scope to know
+	// which model answered them
+	if scoper, ok := mcpClient.(mcp.CallScoper); ok {
+		mcpClient = scoper.NewCall()
+	}
+	call := engine.newDecisionCall(ctx, mcpClient, systemPrompt, userPrompt)
+	aiResponse, err := call.ask()
 	if err != nil {
 		return nil, fmt.Errorf("AI API call failed: %w", err)
 	}
 
 	// 5. Parse AI response
-	decision, err := parseFullDecisionResponse(
-		aiResponse,
-		ctx.Account.TotalEquity,
-		riskConfig.BTCETHMaxLeverage,
-		riskConfig.AltcoinMaxLeverage,
-		riskConfig.BTCETHMaxPositionValueRatio,
-		riskConfig.AltcoinMaxPositionValueRatio,
-	)
+	decision, err := engine.parseDecisionResponse(aiResponse, ctx.Account.TotalEquity)
+
+	// 6. Repair: send the validation error back once per cycle
+	var repairError string
+	if err != nil && engine.repairOnInvalid() {
+		repairError = err.Error()
+		logger.Warnf("⚠️ AI decisions invalid, asking for a repair: %v", err)
+		repaired, repairErr := call.repair(aiResponse, err)
+		if repairErr != nil {
+			logger.Warnf("⚠️ Repair request failed: %v", repairErr)
+		} else {
+			aiResponse = repaired
+			decision, err = engine.parseDecisionResponse(aiResponse, ctx.Account.TotalEquity)
+		}
+	}
+	aiCallDuration := time.Since(aiCallStart)
 
 	if decision != nil {
 		decision.Timestamp = time.Now()
@@ -309,6 +351,12 @@ func GetFullDecisionWithStrategy(ctx *Context, mcpClient mcp.AIClient, engine *S
 		decision.UserPrompt = userPrompt
 		decision.AIRequestDurationMs = aiCallDuration.Milliseconds()
 		decision.RawResponse = aiResponse
+		decision.ToolCalls = call.toolCalls
+		decision.StructuredOutput = call.structured
+		decision.RepairError = repairError
+		if id, ok := mcpClient.(mcp.ModelIdentifier); ok {
+			decision.AIModel = id.AnsweredBy()
+		}
 	}
 
 	if err != nil {
@@ -380,10 +428,10 @@ func fetchMarketDataWithStrategy(ctx *Context, engine *StrategyEngine) error {
 			continue
 		}
 
-		// Liquidity filter (skip for xyz dex assets - they don't have OI data from Binance)
+		// Liquidity filter (crypto only - xyz dex assets, stocks and forex don't have OI data from Binance)
 		isExistingPosition := positionSymbols[coin.Symbol]
-		isXyzAsset := market.IsXyzDexAsset(coin.Symbol)
-		if !isExistingPosition && !isXyzAsset && data.OpenInterest != nil && data.CurrentPrice > 0 {
+		hasBinanceOI := market.ClassifySymbol(coin.Symbol) == market.AssetCrypto
+		if !isExistingPosition && hasBinanceOI && data.OpenInterest != nil && data.CurrentPrice > 0 {
 			oiValue := data.OpenInterest.Latest * data.CurrentPrice
 			oiValueInMillions := oiValue / 1_000_000
 			if oiValueInMillions < minOIThresholdMillions {
@@ -406,6 +454,12 @@ func fetchMarketDataWithStrategy(ctx *Context, engine *StrategyEngine) error {
 
 // GetCandidateCoins gets candidate coins based on strategy configuration
 func (e *StrategyEngine) GetCandidateCoins() ([]CandidateCoin, error) {
+	return e.GetCandidateCoinsAt(time.Now())
+}
+
+// GetCandidateCoinsAt gets candidate coins, consulting trading calendars at the given time
+// (backtests pass the bar time so closed-market filtering matches the simulated clock)
+func (e *StrategyEngine) GetCandidateCoinsAt(at time.Time) ([]CandidateCoin, error) {
 	var candidates []CandidateCoin
 	symbolSources := make(map[string][]string)
 
@@ -421,7 +475,7 @@ func (e *StrategyEngine) GetCandidateCoins() ([]CandidateCoin, error) {
 			})
 		}
 
-		return e.filterExcludedCoins(candidates), nil
+		return e.filterCandidates(candidates, at), nil
 
 	case "ai500":
 		// 检查 use_ai500 标志，如果为 false 则回退到静态币种
@@ -434,13 +488,13 @@ func (e *StrategyEngine) GetCandidateCoins() ([]CandidateCoin, error) {
 					Sources: []string{"static"},
 				})
 			}
-			return e.filterExcludedCoins(candidates), nil
+			return e.filterCandidates(candidates, at), nil
 		}
 		coins, err := e.getAI500Coins(coinSource.AI500Limit)
 		if err != nil {
 			return nil, err
 		}
-		return e.filterExcludedCoins(coins), nil
+		return e.filterCandidates(coins, at), nil
 
 	case "oi_top":
 		// 检查 use_oi_top 标志，如果为 false 则回退到静态币种
@@ -453,13 +507,13 @@ func (e *StrategyEngine) GetCandidateCoins() ([]CandidateCoin, error) {
 					Sources: []string{"static"},
 				})
 			}
-			return e.filterExcludedCoins(candidates), nil
+			return e.filterCandidates(candidates, at), nil
 		}
 		coins, err := e.getOITopCoins(coinSource.OITopLimit)
 		if err != nil {
 			return nil, err
 		}
-		return e.filterExcludedCoins(coins), nil
+		return e.filterCandidates(coins, at), nil
 
 	case "mixed":
 		if coinSource.UseAI500 {
@@ -499,13 +553,18 @@ func (e *StrategyEngine) GetCandidateCoins() ([]CandidateCoin, error) {
 				Sources: sources,
 			})
 		}
-		return e.filterExcludedCoins(candidates), nil
+		return e.filterCandidates(candidates, at), nil
 
 	default:
 		return nil, fmt.Errorf("unknown coin source type: %s", coinSource.SourceType)
 	}
 }
 
+// filterCandidates applies the excluded coin list and the closed market filter
+func (e *StrategyEngine) filterCandidates(candidates []CandidateCoin, at time.Time) []CandidateCoin {
+	return e.filterClosedMarkets(e.filterExcludedCoins(candidates), at)
+}
+
 // filterExcludedCoins removes excluded coins from the candidates list
 func (e *StrategyEngine) filterExcludedCoins(candidates []CandidateCoin) []CandidateCoin {
 	if len(e.config.CoinSource.ExcludedCoins) == 0 {
@@ -854,36 +913,53 @@ func (e *StrategyEngine) FetchPriceRankingData() *nofxos.PriceRankingData {
 // Prompt Building - System Prompt
 // ============================================================================
 
-// BuildSystemPrompt builds System Prompt according to strategy configuration
+// BuildSystemPrompt builds System Prompt according to strategy configuration. When the strategy uses a
+// prompt template with a system part, the template is rendered over the prompt sections instead.
 func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string) string {
+	data := e.systemPromptData(accountEquity, variant)
+	if tpl := e.config.PromptTemplate; tpl != nil && tpl.System != "" {
+		prompt, err := RenderPromptTemplate(tpl.System, data)
+		if err == nil {
+			return prompt
+		}
+		logger.Warnf("⚠️ Prompt template %s v%d (system) failed, using the built-in prompt: %v", tpl.Name, tpl.Version, err)
+	}
+	return data.Schema + data.Role + data.Mode + data.RiskControl + data.PositionSizing +
+		data.TradingFrequency + data.EntryStandards + data.DecisionProcess + data.Tools + data.OutputFormat + data.CustomPrompt
+}
+
+// systemPromptData builds every section of the system prompt
+func (e *StrategyEngine) systemPromptData(accountEquity float64, variant string) *SystemPromptData {
 	var sb strings.Builder
 	riskControl := e.config.RiskControl
 	promptSections := e.config.PromptSections
+	lang := e.GetLanguage()
+	data := &SystemPromptData{
+		Equity:        accountEquity,
+		Variant:       variant,
+		Language:      string(lang),
+		MinConfidence: riskControl.MinConfidence,
+	}
 
 	// 0. Data Dictionary & Schema (ensure AI understands all fields)
-	lang := e.GetLanguage()
-	schemaPrompt := GetSchemaPrompt(lang)
-	sb.WriteString(schemaPrompt)
-	sb.WriteString("\n\n")
-	sb.WriteString("---\n\n")
+	data.Schema = GetSchemaPrompt(lang) + "\n\n---\n\n"
 
 	// 1. Role definition (editable)
 	if promptSections.RoleDefinition != "" {
-		sb.WriteString(promptSections.RoleDefinition)
-		sb.WriteString("\n\n")
+		data.Role = promptSections.RoleDefinition + "\n\n"
 	} else {
-		sb.WriteString("# You are a professional cryptocurrency trading AI\n\n")
-		sb.WriteString("Your task is to make trading decisions based on provided market data.\n\n")
+		data.Role = "# You are a professional cryptocurrency trading AI\n\n" +
+			"Your task is to make trading decisions based on provided market data.\n\n"
 	}
 
 	// 2. Trading mode variant
 	switch strings.ToLower(strings.TrimSpace(variant)) {
 	case "aggressive":
-		sb.WriteString("## Mode: Aggressive\n- Prioritize capturing trend breakouts, can build positions in batches when confidence ≥ 70\n- Allow higher positions, but must strictly set stop-loss and explain risk-reward ratio\n\n")
+		data.Mode = "## Mode: Aggressive\n- Prioritize capturing trend breakouts, can build positions in batches when confidence ≥ 70\n- Allow higher positions, but must strictly set stop-loss and explain risk-reward ratio\n\n"
 	case "conservative":
-		sb.WriteString("## Mode: Conservative\n- Only open positions when multiple signals resonate\n- Prioritize cash preservation, must pause for multiple periods after consecutive losses\n\n")
+		data.Mode = "## Mode: Conservative\n- Only open positions when multiple signals resonate\n- Prioritize cash preservation, must pause for multiple periods after consecutive losses\n\n"
 	case "scalping":
-		sb.WriteString("## Mode: Scalping\n- Focus on short-term momentum, smaller profit targets but require quick action\n- If price doesn't move as expected within two bars, immediately reduce position or stop-loss\n\n")
+		data.Mode = "## Mode: Scalping\n- Focus on short-term momentum, smaller profit targets but require quick action\n- If price doesn't move as expected within two bars, immediately reduce position or stop-loss\n\n"
 	}
 
 	// 3. Hard constraints (risk control)
@@ -904,6 +980,22 @@ func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string
 	sb.WriteString(fmt.Sprintf("- Position Value Limit (BTC/ETH): max %.0f USDT (= equity %.0f × %.1fx)\n",
 		accountEquity*btcEthPosValueRatio, accountEquity, btcEthPosValueRatio))
 	sb.WriteString(fmt.Sprintf("- Max Margin Usage: ≤%.0f%%\n", riskControl.MaxMarginUsage*100))
+	if riskControl.MaxNetExposureRatio > 0 || riskControl.MaxGrossExposureRatio > 0 {
+		sb.WriteString(fmt.Sprintf("- Portfolio Exposure: net |long - short| ≤ %.1fx equity, gross long + short ≤ %.1fx equity (0 = unlimited)\n",
+			riskControl.MaxNetExposureRatio, riskControl.MaxGrossExposureRatio))
+	}
+	if riskControl.MaxClusterExposureRatio > 0 {
+		threshold := riskControl.CorrelationThreshold
+		if threshold <= 0 {
+			threshold = defaultCorrelationThreshold
+		}
+		sb.WriteString(fmt.Sprintf("- Correlated Exposure: symbols with return correlation ≥ %.2f count as one cluster, net cluster exposure ≤ %.1fx equity\n",
+			threshold, riskControl.MaxClusterExposureRatio))
+	}
+	if riskControl.MaxSameDirectionPositions > 0 {
+		sb.WriteString(fmt.Sprintf("- Max Same-Direction Positions: %d longs / %d shorts\n",
+			riskControl.MaxSameDirectionPositions, riskControl.MaxSameDirectionPositions))
+	}
 	sb.WriteString(fmt.Sprintf("- Min Position Size: ≥%.0f USDT\n\n", riskControl.MinPositionSize))
 
 	sb.WriteString("## AI GUIDED (Recommended, you should follow):\n")
@@ -911,8 +1003,10 @@ func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string
 		riskControl.AltcoinMaxLeverage, riskControl.BTCETHMaxLeverage))
 	sb.WriteString(fmt.Sprintf("- Risk-Reward Ratio: ≥1:%.1f (take_profit / stop_loss)\n", riskControl.MinRiskRewardRatio))
 	sb.WriteString(fmt.Sprintf("- Min Confidence: ≥%d to open position\n\n", riskControl.MinConfidence))
+	data.RiskControl = sb.String()
 
 	// Position sizing guidance
+	sb.Reset()
 	sb.WriteString("## Position Sizing Guidance\n")
 	sb.WriteString("Calculate `position_size_usd` based on your confidence and the Position Value Limits above:\n")
 	sb.WriteString("- High confidence (≥85): Use 80-100%% of max position value limit\n")
@@ -921,44 +1015,65 @@ func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string
 	sb.WriteString(fmt.Sprintf("- Example: With equity %.0f and BTC/ETH ratio %.1fx, max is %.0f USDT\n",
 		accountEquity, btcEthPosValueRatio, accountEquity*btcEthPosValueRatio))
 	sb.WriteString("- **DO NOT** just use available_balance as position_size_usd. Use the Position Value Limits!\n\n")
+	data.PositionSizing = sb.String()
 
 	// 4. Trading frequency (editable)
 	if promptSections.TradingFrequency != "" {
-		sb.WriteString(promptSections.TradingFrequency)
-		sb.WriteString("\n\n")
+		data.TradingFrequency = promptSections.TradingFrequency + "\n\n"
 	} else {
-		sb.WriteString("# ⏱️ Trading Frequency Awareness\n\n")
-		sb.WriteString("- Excellent traders: 2-4 trades/day ≈ 0.1-0.2 trades/hour\n")
-		sb.WriteString("- >2 trades/hour = Overtrading\n")
-		sb.WriteString("- Single position hold time ≥ 30-60 minutes\n")
-		sb.WriteString("If you find yourself trading every period → standards too low; if closing positions < 30 minutes → too impatient.\n\n")
+		data.TradingFrequency = "# ⏱️ Trading Frequency Awareness\n\n" +
+			"- Excellent traders: 2-4 trades/day ≈ 0.1-0.2 trades/hour\n" +
+			"- >2 trades/hour = Overtrading\n" +
+			"- Single position hold time ≥ 30-60 minutes\n" +
+			"If you find yourself trading every period → standards too low; if closing positions < 30 minutes → too impatient.\n\n"
 	}
 
 	// 5. Entry standards (editable)
+	sb.Reset()
+	e.writeAvailableIndicators(&sb)
+	data.Indicators = sb.String()
 	if promptSections.EntryStandards != "" {
-		sb.WriteString(promptSections.EntryStandards)
-		sb.WriteString("\n\nYou have the following indicator data:\n")
-		e.writeAvailableIndicators(&sb)
-		sb.WriteString(fmt.Sprintf("\n**Confidence ≥ %d** required to open positions.\n\n", riskControl.MinConfidence))
+		data.EntryStandards = promptSections.EntryStandards + "\n\nYou have the following indicator data:\n" + data.Indicators +
+			fmt.Sprintf("\n**Confidence ≥ %d** required to open positions.\n\n", riskControl.MinConfidence)
 	} else {
-		sb.WriteString("# 🎯 Entry Standards (Strict)\n\n")
-		sb.WriteString("Only open positions when multiple signals resonate. You have:\n")
-		e.writeAvailableIndicators(&sb)
-		sb.WriteString(fmt.Sprintf("\nFeel free to use any effective analysis method, but **confidence ≥ %d** required to open positions; avoid low-quality behaviors such as single indicators, contradictory signals, sideways consolidation, reopening immediately after closing, etc.\n\n", riskControl.MinConfidence))
+		data.EntryStandards = "# 🎯 Entry Standards (Strict)\n\n" +
+			"Only open positions when multiple signals resonate. You have:\n" + data.Indicators +
+			fmt.Sprintf("\nFeel free to use any effective analysis method, but **confidence ≥ %d** required to open positions; avoid low-quality behaviors such as single indicators, contradictory signals, sideways consolidation, reopening immediately after closing, etc.\n\n", riskControl.MinConfidence)
 	}
 
 	// 6. Decision process (editable)
 	if promptSections.DecisionProcess != "" {
-		sb.WriteString(promptSections.DecisionProcess)
-		sb.WriteString("\n\n")
+		data.DecisionProcess = promptSections.DecisionProcess + "\n\n"
+	} else {
+		data.DecisionProcess = "# 📋 Decision Process\n\n" +
+			"1. Check positions → Should we take profit/stop-loss\n" +
+			"2. Scan candidate coins + multi-timeframe → Are there strong signals\n" +
+			"3. Write chain of thought first, then output structured JSON\n\n"
+	}
+
+	// 7. Data tools (only when tool calling is enabled)
+	data.Tools = e.formatToolsSection()
+
+	// 8. Output format (use the actual configured position value ratio for BTC/ETH in the example)
+	examplePositionSize := accountEquity * btcEthPosValueRatio
+	if e.structuredOutput() {
+		data.OutputFormat = formatStructuredOutputFormat(riskControl, examplePositionSize)
 	} else {
-		sb.WriteString("# 📋 Decision Process\n\n")
-		sb.WriteString("1. Check positions → Should we take profit/stop-loss\n")
-		sb.WriteString("2. Scan candidate coins + multi-timeframe → Are there strong signals\n")
-		sb.WriteString("3. Write chain of thought first, then output structured JSON\n\n")
+		data.OutputFormat = formatTaggedOutputFormat(riskControl, examplePositionSize)
 	}
 
-	// 7. Output format
+	// 9. Custom Prompt
+	if e.config.CustomPrompt != "" {
+		data.CustomPrompt = "# 📌 Personalized Trading Strategy\n\n" + e.config.CustomPrompt + "\n\n" +
+			"Note: The above personalized strategy is a supplement to the basic rules and cannot violate the basic risk control principles.\n"
+	}
+
+	return data
+}
+
+// formatTaggedOutputFormat output format section asking for <reasoning> and <decision> tags
+func formatTaggedOutputFormat(riskControl store.RiskControlConfig, examplePositionSize float64) string {
+	var sb strings.Builder
 	sb.WriteString("# Output Format (Strictly Follow)\n\n")
 	sb.WriteString("**Must use XML tags <reasoning> and <decision> to separate chain of thought and decision JSON, avoiding parsing errors**\n\n")
 	sb.WriteString("## Format Requirements\n\n")
@@ -969,8 +1084,6 @@ func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string
 	sb.WriteString("<decision>\n")
 	sb.WriteString("Step 2: JSON decision array\n\n")
 	sb.WriteString("```json\n[\n")
-	// Use the actual configured position value ratio for BTC/ETH in the example
-	examplePositionSize := accountEquity * btcEthPosValueRatio
 	sb.WriteString(fmt.Sprintf("  {\"symbol\": \"BTCUSDT\", \"action\": \"open_short\", \"leverage\": %d, \"position_size_usd\": %.0f, \"stop_loss\": 97000, \"take_profit\": 91000, \"confidence\": 85, \"risk_usd\": 300},\n",
 		riskControl.BTCETHMaxLeverage, examplePositionSize))
 	sb.WriteString("  {\"symbol\": \"ETHUSDT\", \"action\": \"close_long\"}\n")
@@ -981,15 +1094,6 @@ func (e *StrategyEngine) BuildSystemPrompt(accountEquity float64, variant string
 	sb.WriteString(fmt.Sprintf("- `confidence`: 0-100 (opening recommended ≥ %d)\n", riskControl.MinConfidence))
 	sb.WriteString("- Required when opening: leverage, position_size_usd, stop_loss, take_profit, confidence, risk_usd\n")
 	sb.WriteString("- **IMPORTANT**: All numeric values must be calculated numbers, NOT formulas/expressions (e.g., use `27.76` not `3000 * 0.01`)\n\n")
-
-	// 8. Custom Prompt
-	if e.config.CustomPrompt != "" {
-		sb.WriteString("# 📌 Personalized Trading Strategy\n\n")
-		sb.WriteString(e.config.CustomPrompt)
-		sb.WriteString("\n\n")
-		sb.WriteString("Note: The above personalized strategy is a supplement to the basic rules and cannot violate the basic risk control principles.\n")
-	}
-
 	return sb.String()
 }
 
@@ -1059,28 +1163,73 @@ func (e *StrategyEngine) writeAvailableIndicators(sb *strings.Builder) {
 	if indicators.EnableQuantData {
 		sb.WriteString("- Quantitative data (institutional/retail fund flow, position changes, multi-period price changes)\n")
 	}
+
+	if indicators.EnableLiquidationData {
+		sb.WriteString("- Recent liquidations (long/short totals and large liquidation price clusters)\n")
+	}
+
+	if indicators.EnableLongShortRatio {
+		sb.WriteString("- Top-trader long/short ratio and net long/short positions\n")
+	}
+
+	if indicators.EnableOIVsMarketCap {
+		sb.WriteString("- Open interest vs market cap ratio (leverage crowding)\n")
+	}
+
+	if indicators.EnableOrderBook {
+		sb.WriteString("- Order book (spread, ±1% depth, bid/ask imbalance, top levels)\n")
+	}
 }
 
 // ============================================================================
 // Prompt Building - User Prompt
 // ============================================================================
 
-// BuildUserPrompt builds User Prompt based on strategy configuration
+// BuildUserPrompt builds User Prompt based on strategy configuration. When the strategy uses a prompt
+// template with a user part, the template is rendered over the prompt sections instead.
 func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
+	data := e.userPromptData(ctx)
+	if tpl := e.config.PromptTemplate; tpl != nil && tpl.User != "" {
+		prompt, err := RenderPromptTemplate(tpl.User, data)
+		if err == nil {
+			return prompt
+		}
+		logger.Warnf("⚠️ Prompt template %s v%d (user) failed, using the built-in prompt: %v", tpl.Name, tpl.Version, err)
+	}
+	return data.Header + data.Account + data.RecentTrades + data.TradingStats + data.Positions +
+		data.Candidates + data.Rankings + data.Footer
+}
+
+// userPromptData builds every section of the user prompt from ctx
+func (e *StrategyEngine) userPromptData(ctx *Context) *UserPromptData {
 	var sb strings.Builder
+	data := &UserPromptData{
+		Time:           ctx.CurrentTime,
+		CallCount:      ctx.CallCount,
+		Equity:         ctx.Account.TotalEquity,
+		PositionCount:  len(ctx.Positions),
+		CandidateCount: len(ctx.CandidateCoins),
+	}
 
 	// System status
 	sb.WriteString(fmt.Sprintf("Time: %s | Period: #%d | Runtime: %d minutes\n\n",
 		ctx.CurrentTime, ctx.CallCount, ctx.RuntimeMinutes))
 
+	// Market event that launched this out-of-band cycle
+	if ctx.TriggerEvent != nil {
+		sb.WriteString(e.formatTriggerEvent(ctx.TriggerEvent))
+	}
+
 	// BTC market
 	if btcData, hasBTC := ctx.MarketDataMap["BTCUSDT"]; hasBTC {
 		sb.WriteString(fmt.Sprintf("BTC: %.2f (1h: %+.2f%%, 4h: %+.2f%%) | MACD: %.4f | RSI: %.2f\n\n",
 			btcData.CurrentPrice, btcData.PriceChange1h, btcData.PriceChange4h,
 			btcData.CurrentMACD, btcData.CurrentRSI7))
 	}
+	data.Header = sb.String()
 
 	// Account information
+	sb.Reset()
 	sb.WriteString(fmt.Sprintf("Account: Equity %.2f | Balance %.2f (%.1f%%) | PnL %+.2f%% | Margin %.1f%% | Positions %d\n\n",
 		ctx.Account.TotalEquity,
 		ctx.Account.AvailableBalance,
@@ -1088,8 +1237,10 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 		ctx.Account.TotalPnLPct,
 		ctx.Account.MarginUsedPct,
 		ctx.Account.PositionCount))
+	data.Account = sb.String()
 
 	// Recently completed orders (placed before positions to ensure visibility)
+	sb.Reset()
 	if len(ctx.RecentOrders) > 0 {
 		sb.WriteString("## Recent Completed Trades\n")
 		for i, order := range ctx.RecentOrders {
@@ -1105,8 +1256,10 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 		}
 		sb.WriteString("\n")
 	}
+	data.RecentTrades = sb.String()
 
 	// Historical trading statistics (helps AI understand past performance)
+	sb.Reset()
 	if ctx.TradingStats != nil && ctx.TradingStats.TotalTrades > 0 {
 		// Get language from strategy config
 		lang := e.GetLanguage()
@@ -1166,8 +1319,10 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 		}
 		sb.WriteString("\n")
 	}
+	data.TradingStats = sb.String()
 
 	// Position information
+	sb.Reset()
 	if len(ctx.Positions) > 0 {
 		sb.WriteString("## Current Positions\n")
 		for i, pos := range ctx.Positions {
@@ -1176,8 +1331,10 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 	} else {
 		sb.WriteString("Current Positions: None\n\n")
 	}
+	data.Positions = sb.String()
 
 	// Candidate coins (exclude coins already in positions to avoid duplicate data)
+	sb.Reset()
 	positionSymbols := make(map[string]bool)
 	for _, pos := range ctx.Positions {
 		// Normalize symbol to handle both "ETH" and "ETHUSDT" formats
@@ -1201,7 +1358,13 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 		displayedCount++
 
 		sourceTags := e.formatCoinSourceTag(coin.Sources)
+		// Compact prompt: one summary line, the AI pulls detail with the data tools
+		if e.compactPrompt() {
+			sb.WriteString(formatCompactCandidate(displayedCount, coin.Symbol+sourceTags, marketData))
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("### %d. %s%s\n\n", displayedCount, coin.Symbol, sourceTags))
+		sb.WriteString(e.formatMarketSession(ctx.MarketSessions[coin.Symbol]))
 		sb.WriteString(e.formatMarketData(marketData))
 
 		if ctx.QuantDataMap != nil {
@@ -1209,11 +1372,19 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 				sb.WriteString(e.formatQuantData(quantData))
 			}
 		}
+		if derivData, hasDeriv := ctx.DerivativesDataMap[coin.Symbol]; hasDeriv {
+			sb.WriteString(coinank.FormatSignalsForAI(derivData, e.coinankLanguage()))
+		}
+		if bookData, hasBook := ctx.OrderBookMap[coin.Symbol]; hasBook {
+			sb.WriteString(e.formatOrderBookData(bookData))
+		}
 		sb.WriteString("\n")
 	}
 	sb.WriteString("\n")
+	data.Candidates = sb.String()
 
 	// Get language for market data formatting
+	sb.Reset()
 	nofxosLang := nofxos.LangEnglish
 	if e.GetLanguage() == LangChinese {
 		nofxosLang = nofxos.LangChinese
@@ -1233,11 +1404,10 @@ func (e *StrategyEngine) BuildUserPrompt(ctx *Context) string {
 	if ctx.PriceRankingData != nil {
 		sb.WriteString(nofxos.FormatPriceRankingForAI(ctx.PriceRankingData, nofxosLang))
 	}
+	data.Rankings = sb.String()
 
-	sb.WriteString("---\n\n")
-	sb.WriteString("Now please analyze and output your decision (Chain of Thought + JSON)\n")
-
-	return sb.String()
+	data.Footer = "---\n\nNow please analyze and output your decision (Chain of Thought + JSON)\n"
+	return data
 }
 
 func (e *StrategyEngine) formatPositionInfo(index int, pos PositionInfo, ctx *Context) string {
@@ -1266,6 +1436,8 @@ func (e *StrategyEngine) formatPositionInfo(index int, pos PositionInfo, ctx *Co
 		pos.EntryPrice, pos.MarkPrice, pos.Quantity, positionValue, pos.UnrealizedPnLPct, pos.UnrealizedPnL, pos.PeakPnLPct,
 		pos.Leverage, pos.MarginUsed, pos.LiquidationPrice, holdingDuration))
 
+	sb.WriteString(e.formatMarketSession(ctx.MarketSessions[pos.Symbol]))
+
 	if marketData, ok := ctx.MarketDataMap[pos.Symbol]; ok {
 		sb.WriteString(e.formatMarketData(marketData))
 
@@ -1274,6 +1446,12 @@ func (e *StrategyEngine) formatPositionInfo(index int, pos PositionInfo, ctx *Co
 				sb.WriteString(e.formatQuantData(quantData))
 			}
 		}
+		if derivData, hasDeriv := ctx.DerivativesDataMap[pos.Symbol]; hasDeriv {
+			sb.WriteString(coinank.FormatSignalsForAI(derivData, e.coinankLanguage()))
+		}
+		if bookData, hasBook := ctx.OrderBookMap[pos.Symbol]; hasBook {
+			sb.WriteString(e.formatOrderBookData(bookData))
+		}
 		sb.WriteString("\n")
 	}
 
@@ -1759,6 +1937,18 @@ func compactArrayOpen(s string) string {
 // Decision Validation
 // ============================================================================
 
+// ValidateDecisions applies the strategy's leverage, position size and risk/reward limits to decisions
+// that did not come from GetFullDecisionWithStrategy (e.g. a debate consensus)
+func (e *StrategyEngine) ValidateDecisions(decisions []Decision, accountEquity float64) error {
+	riskConfig := e.GetRiskControlConfig()
+	return validateDecisions(decisions, accountEquity,
+		riskConfig.BTCETHMaxLeverage,
+		riskConfig.AltcoinMaxLeverage,
+		riskConfig.BTCETHMaxPositionValueRatio,
+		riskConfig.AltcoinMaxPositionValueRatio,
+	)
+}
+
 func validateDecisions(decisions []Decision, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64) error {
 	for i, decision := range decisions {
 		if err := validateDecision(&decision, accountEquity, btcEthLeverage, altcoinLeverage, btcEthPosRatio, altcoinPosRatio); err != nil {
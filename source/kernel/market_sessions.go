@@ -0,0 +1,83 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/logger"
+	"nofx/market"
+	"time"
+)
+
+// ============================================================================
+// Market Sessions (stock/forex/commodity trading calendars)
+// ============================================================================
+
+// filterClosedMarkets drops candidates whose underlying market is closed when SkipClosedMarkets is enabled
+func (e *StrategyEngine) filterClosedMarkets(candidates []CandidateCoin, at time.Time) []CandidateCoin {
+	if !e.config.CoinSource.SkipClosedMarkets {
+		return candidates
+	}
+
+	filtered := make([]CandidateCoin, 0, len(candidates))
+	for _, c := range candidates {
+		status := market.GetMarketStatus(c.Symbol, at)
+		if !status.Open {
+			logger.Infof("🕒 Skipping %s: %s market closed (%s)", c.Symbol, status.Calendar, status.Reason)
+			continue
+		}
+		filtered = append(filtered, c)
+	}
+	return filtered
+}
+
+// BuildMarketSessions returns the session status of symbols that don't trade around the clock
+func (e *StrategyEngine) BuildMarketSessions(symbols []string, at time.Time) map[string]*market.MarketStatus {
+	result := make(map[string]*market.MarketStatus)
+	for _, symbol := range symbols {
+		status := market.GetMarketStatus(symbol, at)
+		if status.AlwaysOpen() {
+			continue
+		}
+		result[symbol] = status
+	}
+	return result
+}
+
+// formatMarketSession describes the trading session of a symbol and its upcoming open/close
+func (e *StrategyEngine) formatMarketSession(status *market.MarketStatus) string {
+	if status == nil || status.AlwaysOpen() {
+		return ""
+	}
+
+	if e.GetLanguage() == LangChinese {
+		if status.Open {
+			return fmt.Sprintf("🕒 交易时段 (%s): 开盘中，%s 后收盘 (%s)\n",
+				status.Calendar, formatSessionDuration(status.UntilNextClose()), status.NextClose.UTC().Format("01-02 15:04 UTC"))
+		}
+		return fmt.Sprintf("🕒 交易时段 (%s): 休市 (%s)，%s 后开盘 (%s)，休市期间价格可能跳空\n",
+			status.Calendar, status.Reason, formatSessionDuration(status.UntilNextOpen()), status.NextOpen.UTC().Format("01-02 15:04 UTC"))
+	}
+
+	if status.Open {
+		return fmt.Sprintf("🕒 Session (%s): OPEN, closes in %s (%s)\n",
+			status.Calendar, formatSessionDuration(status.UntilNextClose()), status.NextClose.UTC().Format("01-02 15:04 UTC"))
+	}
+	return fmt.Sprintf("🕒 Session (%s): CLOSED (%s), opens in %s (%s), expect a price gap at the open\n",
+		status.Calendar, status.Reason, formatSessionDuration(status.UntilNextOpen()), status.NextOpen.UTC().Format("01-02 15:04 UTC"))
+}
+
+func formatSessionDuration(d time.Duration) string {
+	if d <= 0 {
+		return "n/a"
+	}
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh", days, hours)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
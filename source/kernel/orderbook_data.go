@@ -0,0 +1,73 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/logger"
+	"nofx/market"
+	"strings"
+)
+
+// ============================================================================
+// Order Book Data (spread, depth, imbalance)
+// ============================================================================
+
+const defaultOrderBookLevels = 5
+
+// FetchOrderBookBatch batch fetches order book features for the given symbols
+func (e *StrategyEngine) FetchOrderBookBatch(symbols []string) map[string]*market.OrderBookFeatures {
+	result := make(map[string]*market.OrderBookFeatures)
+
+	if !e.config.Indicators.EnableOrderBook {
+		return result
+	}
+
+	levels := e.config.Indicators.OrderBookLevels
+	if levels <= 0 {
+		levels = defaultOrderBookLevels
+	}
+
+	for _, symbol := range symbols {
+		book, err := market.GetOrderBook(symbol)
+		if err != nil {
+			logger.Infof("⚠️  Failed to fetch order book for %s: %v", symbol, err)
+			continue
+		}
+		if features := book.Features(levels); features != nil {
+			result[symbol] = features
+		}
+	}
+
+	return result
+}
+
+func (e *StrategyEngine) formatOrderBookData(data *market.OrderBookFeatures) string {
+	if data == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	if e.GetLanguage() == LangChinese {
+		sb.WriteString(fmt.Sprintf("📖 %s 订单簿:\n", data.Symbol))
+		sb.WriteString(fmt.Sprintf("买一 %.4f | 卖一 %.4f | 价差 %.2f bps\n", data.BestBid, data.BestAsk, data.SpreadBps))
+		sb.WriteString(fmt.Sprintf("±1%%深度: 买盘 %s | 卖盘 %s | 失衡 %+.2f (正值=买盘更厚)\n",
+			formatFlowValue(data.BidDepth1Pct), formatFlowValue(data.AskDepth1Pct), data.Imbalance))
+	} else {
+		sb.WriteString(fmt.Sprintf("📖 %s Order Book:\n", data.Symbol))
+		sb.WriteString(fmt.Sprintf("Best Bid %.4f | Best Ask %.4f | Spread %.2f bps\n", data.BestBid, data.BestAsk, data.SpreadBps))
+		sb.WriteString(fmt.Sprintf("Depth ±1%%: Bids %s | Asks %s | Imbalance %+.2f (positive = bid heavy)\n",
+			formatFlowValue(data.BidDepth1Pct), formatFlowValue(data.AskDepth1Pct), data.Imbalance))
+	}
+
+	sb.WriteString("Bids: " + formatOrderBookLevels(data.TopBids) + "\n")
+	sb.WriteString("Asks: " + formatOrderBookLevels(data.TopAsks) + "\n")
+
+	return sb.String()
+}
+
+func formatOrderBookLevels(levels []market.OrderBookLevel) string {
+	parts := make([]string, 0, len(levels))
+	for _, lvl := range levels {
+		parts = append(parts, fmt.Sprintf("%.4f×%.4g", lvl.Price, lvl.Quantity))
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,272 @@
+package kernel
+
+import (
+	"fmt"
+	"math"
+	"nofx/market"
+	"nofx/store"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// Portfolio Risk (net/gross exposure, correlation clusters, same-direction count)
+// ============================================================================
+
+const (
+	// CorrelationTimeframe kline interval used for rolling return correlations
+	CorrelationTimeframe = "1h"
+
+	defaultCorrelationThreshold = 0.8
+	defaultCorrelationLookback  = 72
+	minCorrelationSamples       = 20 // fewer overlapping returns are treated as uncorrelated
+)
+
+// ExposurePosition open position as seen by portfolio risk checks
+type ExposurePosition struct {
+	Symbol   string
+	Side     string  // "long" | "short"
+	Notional float64 // position value in USDT
+}
+
+// PortfolioExposure aggregate directional exposure of open positions
+type PortfolioExposure struct {
+	Long       float64
+	Short      float64
+	LongCount  int
+	ShortCount int
+}
+
+// Net long notional minus short notional
+func (p PortfolioExposure) Net() float64 {
+	return p.Long - p.Short
+}
+
+// Gross long notional plus short notional
+func (p PortfolioExposure) Gross() float64 {
+	return p.Long + p.Short
+}
+
+// SummarizeExposure aggregates long/short notional and counts
+func SummarizeExposure(positions []ExposurePosition) PortfolioExposure {
+	var exp PortfolioExposure
+	for _, p := range positions {
+		if p.Side == "short" {
+			exp.Short += math.Abs(p.Notional)
+			exp.ShortCount++
+		} else {
+			exp.Long += math.Abs(p.Notional)
+			exp.LongCount++
+		}
+	}
+	return exp
+}
+
+// CorrelationLookback returns the number of bars used for rolling correlations
+func CorrelationLookback(rc store.RiskControlConfig) int {
+	if rc.CorrelationLookback > 0 {
+		return rc.CorrelationLookback
+	}
+	return defaultCorrelationLookback
+}
+
+// NeedsCorrelationData reports whether the cluster exposure limit is enabled
+func NeedsCorrelationData(rc store.RiskControlConfig) bool {
+	return rc.MaxClusterExposureRatio > 0
+}
+
+// CheckPortfolioExposure returns the part of a new sizeUSD order on symbol/side that keeps the portfolio
+// within the net, gross and correlation-cluster exposure limits, and the limit that capped it (empty if none).
+// An error is returned when there is no room at all or the same-direction position limit is reached.
+// klines holds recent CorrelationTimeframe klines of the new and held symbols, nil when the cluster limit is disabled.
+func CheckPortfolioExposure(rc store.RiskControlConfig, positions []ExposurePosition, symbol, side string,
+	sizeUSD, equity float64, klines map[string][]market.Kline) (float64, string, error) {
+	exp := SummarizeExposure(positions)
+
+	if rc.MaxSameDirectionPositions > 0 {
+		count := exp.LongCount
+		if side == "short" {
+			count = exp.ShortCount
+		}
+		if count >= rc.MaxSameDirectionPositions {
+			return 0, "", fmt.Errorf("❌ [RISK CONTROL] Already at max %s positions (%d/%d)", side, count, rc.MaxSameDirectionPositions)
+		}
+	}
+
+	if equity <= 0 || sizeUSD <= 0 {
+		return sizeUSD, "", nil
+	}
+
+	sign := 1.0
+	if side == "short" {
+		sign = -1.0
+	}
+
+	allowed := sizeUSD
+	limit := ""
+	capTo := func(room float64, name string) {
+		if room < allowed {
+			allowed = room
+			limit = name
+		}
+	}
+
+	if rc.MaxGrossExposureRatio > 0 {
+		capTo(equity*rc.MaxGrossExposureRatio-exp.Gross(), fmt.Sprintf("gross exposure %.1fx equity", rc.MaxGrossExposureRatio))
+	}
+	if rc.MaxNetExposureRatio > 0 {
+		capTo(equity*rc.MaxNetExposureRatio-sign*exp.Net(), fmt.Sprintf("net exposure %.1fx equity", rc.MaxNetExposureRatio))
+	}
+	if rc.MaxClusterExposureRatio > 0 && len(klines) > 0 {
+		members := clusterOf(symbol, positions, klines, rc.CorrelationThreshold)
+		if len(members) > 1 {
+			clusterNet := 0.0
+			for _, p := range positions {
+				if members[p.Symbol] {
+					clusterNet += signedNotional(p)
+				}
+			}
+			capTo(equity*rc.MaxClusterExposureRatio-sign*clusterNet, fmt.Sprintf("correlation cluster [%s] exposure %.1fx equity",
+				strings.Join(sortedKeys(members), ", "), rc.MaxClusterExposureRatio))
+		}
+	}
+
+	if allowed <= 0 {
+		return 0, limit, fmt.Errorf("❌ [RISK CONTROL] No room for %s %s: %s limit reached", symbol, side, limit)
+	}
+	return allowed, limit, nil
+}
+
+// clusterOf returns the correlation cluster containing symbol (including symbol itself)
+func clusterOf(symbol string, positions []ExposurePosition, klines map[string][]market.Kline, threshold float64) map[string]bool {
+	series := map[string][]market.Kline{symbol: klines[symbol]}
+	for _, p := range positions {
+		series[p.Symbol] = klines[p.Symbol]
+	}
+	for _, cluster := range CorrelationClusters(series, threshold) {
+		for _, s := range cluster {
+			if s == symbol {
+				members := make(map[string]bool, len(cluster))
+				for _, m := range cluster {
+					members[m] = true
+				}
+				return members
+			}
+		}
+	}
+	return map[string]bool{symbol: true}
+}
+
+// CorrelationClusters groups symbols whose return correlation reaches threshold (single linkage),
+// clusters and their members are sorted by name
+func CorrelationClusters(klines map[string][]market.Kline, threshold float64) [][]string {
+	if threshold <= 0 {
+		threshold = defaultCorrelationThreshold
+	}
+
+	symbols := sortedKeys(klines)
+	returns := make(map[string]map[int64]float64, len(symbols))
+	for _, s := range symbols {
+		returns[s] = logReturns(klines[s])
+	}
+
+	parent := make(map[string]string, len(symbols))
+	for _, s := range symbols {
+		parent[s] = s
+	}
+	var find func(string) string
+	find = func(s string) string {
+		if parent[s] != s {
+			parent[s] = find(parent[s])
+		}
+		return parent[s]
+	}
+
+	for i := 0; i < len(symbols); i++ {
+		for j := i + 1; j < len(symbols); j++ {
+			if corr, ok := correlation(returns[symbols[i]], returns[symbols[j]]); ok && corr >= threshold {
+				parent[find(symbols[j])] = find(symbols[i])
+			}
+		}
+	}
+
+	groups := make(map[string][]string)
+	for _, s := range symbols {
+		root := find(s)
+		groups[root] = append(groups[root], s)
+	}
+	clusters := make([][]string, 0, len(groups))
+	for _, g := range groups {
+		clusters = append(clusters, g)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i][0] < clusters[j][0] })
+	return clusters
+}
+
+// ReturnCorrelation Pearson correlation of the log returns of two kline series aligned by open time,
+// ok is false when they overlap on fewer than minCorrelationSamples bars
+func ReturnCorrelation(a, b []market.Kline) (float64, bool) {
+	return correlation(logReturns(a), logReturns(b))
+}
+
+// logReturns log return of each kline close versus the previous close, keyed by open time
+func logReturns(klines []market.Kline) map[int64]float64 {
+	result := make(map[int64]float64, len(klines))
+	for i := 1; i < len(klines); i++ {
+		prev, cur := klines[i-1].Close, klines[i].Close
+		if prev <= 0 || cur <= 0 {
+			continue
+		}
+		result[klines[i].OpenTime] = math.Log(cur / prev)
+	}
+	return result
+}
+
+func correlation(a, b map[int64]float64) (float64, bool) {
+	var xs, ys []float64
+	for t, x := range a {
+		if y, ok := b[t]; ok {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+	n := float64(len(xs))
+	if len(xs) < minCorrelationSamples {
+		return 0, false
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
+
+func signedNotional(p ExposurePosition) float64 {
+	if p.Side == "short" {
+		return -math.Abs(p.Notional)
+	}
+	return math.Abs(p.Notional)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,117 @@
+package kernel
+
+import (
+	"math"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"testing"
+)
+
+// makeKlines builds hourly klines whose closes follow the given per-bar returns
+func makeKlines(returns []float64) []market.Kline {
+	klines := make([]market.Kline, 0, len(returns)+1)
+	price := 100.0
+	klines = append(klines, market.Kline{OpenTime: 0, Close: price})
+	for i, r := range returns {
+		price *= math.Exp(r)
+		klines = append(klines, market.Kline{OpenTime: int64(i+1) * 3600_000, Close: price})
+	}
+	return klines
+}
+
+func testReturns(n int, phase float64) []float64 {
+	returns := make([]float64, n)
+	for i := range returns {
+		returns[i] = 0.01 * math.Sin(float64(i)*0.7+phase)
+	}
+	return returns
+}
+
+// TestCorrelationClusters 测试相关性聚类
+func TestCorrelationClusters(t *testing.T) {
+	base := testReturns(60, 0)
+	klines := map[string][]market.Kline{
+		"SOLUSDT":  makeKlines(base),
+		"AVAXUSDT": makeKlines(base),
+		"DOGEUSDT": makeKlines(testReturns(60, math.Pi/2)),
+	}
+
+	clusters := CorrelationClusters(klines, 0.8)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %v", clusters)
+	}
+	if strings.Join(clusters[0], ",") != "AVAXUSDT,SOLUSDT" {
+		t.Errorf("expected AVAX and SOL clustered, got %v", clusters[0])
+	}
+
+	if _, ok := ReturnCorrelation(klines["SOLUSDT"][:5], klines["AVAXUSDT"][:5]); ok {
+		t.Error("too few overlapping bars should not produce a correlation")
+	}
+}
+
+// TestCheckPortfolioExposure 测试组合敞口限制
+func TestCheckPortfolioExposure(t *testing.T) {
+	rc := store.RiskControlConfig{
+		MaxNetExposureRatio:       2,
+		MaxGrossExposureRatio:     3,
+		MaxClusterExposureRatio:   1,
+		CorrelationThreshold:      0.8,
+		MaxSameDirectionPositions: 2,
+	}
+	equity := 1000.0
+
+	t.Run("same direction count", func(t *testing.T) {
+		positions := []ExposurePosition{
+			{Symbol: "BTCUSDT", Side: "long", Notional: 100},
+			{Symbol: "ETHUSDT", Side: "long", Notional: 100},
+		}
+		if _, _, err := CheckPortfolioExposure(rc, positions, "SOLUSDT", "long", 100, equity, nil); err == nil {
+			t.Error("third long should be rejected")
+		}
+		if allowed, _, err := CheckPortfolioExposure(rc, positions, "SOLUSDT", "short", 100, equity, nil); err != nil || allowed != 100 {
+			t.Errorf("short should pass unchanged, got %.2f %v", allowed, err)
+		}
+	})
+
+	t.Run("net exposure caps", func(t *testing.T) {
+		positions := []ExposurePosition{{Symbol: "BTCUSDT", Side: "long", Notional: 1500}}
+		allowed, limit, err := CheckPortfolioExposure(rc, positions, "ETHUSDT", "long", 1000, equity, nil)
+		if err != nil || allowed != 500 || !strings.Contains(limit, "net") {
+			t.Errorf("expected net cap to 500, got %.2f %q %v", allowed, limit, err)
+		}
+	})
+
+	t.Run("gross exposure rejects", func(t *testing.T) {
+		positions := []ExposurePosition{
+			{Symbol: "BTCUSDT", Side: "long", Notional: 1500},
+			{Symbol: "ETHUSDT", Side: "short", Notional: 1500},
+		}
+		if _, _, err := CheckPortfolioExposure(rc, positions, "SOLUSDT", "short", 100, equity, nil); err == nil {
+			t.Error("order beyond gross limit should be rejected")
+		}
+	})
+
+	t.Run("correlation cluster caps", func(t *testing.T) {
+		base := testReturns(60, 0)
+		klines := map[string][]market.Kline{
+			"SOLUSDT":  makeKlines(base),
+			"AVAXUSDT": makeKlines(base),
+			"BTCUSDT":  makeKlines(testReturns(60, math.Pi/2)),
+		}
+		positions := []ExposurePosition{
+			{Symbol: "AVAXUSDT", Side: "long", Notional: 800},
+			{Symbol: "BTCUSDT", Side: "short", Notional: 100},
+		}
+		allowed, limit, err := CheckPortfolioExposure(rc, positions, "SOLUSDT", "long", 500, equity, klines)
+		if err != nil || allowed != 200 || !strings.Contains(limit, "AVAXUSDT, SOLUSDT") {
+			t.Errorf("expected cluster cap to 200, got %.2f %q %v", allowed, limit, err)
+		}
+
+		// Uncorrelated symbols are not capped by the cluster limit
+		allowed, _, err = CheckPortfolioExposure(rc, positions[:1], "BTCUSDT", "long", 500, equity, klines)
+		if err != nil || allowed != 500 {
+			t.Errorf("uncorrelated long should pass unchanged, got %.2f %v", allowed, err)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package kernel
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"nofx/market"
+)
+
+// SystemPromptData sections of the system prompt, available to a prompt template's system part as
+// {{.Schema}}, {{.RiskControl}}, ... Every section ends with a blank line, so a template can list them
+// one after another. The built-in system prompt is all sections in field order (Indicators excepted).
+type SystemPromptData struct {
+	Schema           string // Data dictionary of every field in the user prompt
+	Role             string
+	Mode             string // Trading mode of the prompt variant, empty for balanced
+	RiskControl      string // Code-enforced and AI-guided risk limits
+	PositionSizing   string
+	TradingFrequency string
+	EntryStandards   string // Entry standards, including the indicator list
+	Indicators       string // Indicator list only
+	DecisionProcess  string
+	Tools            string // Data tools the AI can call, empty when tool calling is disabled
+	OutputFormat     string // Required output format (tagged text, or a JSON object in structured mode); a template must keep it for decisions to parse
+	CustomPrompt     string
+
+	Equity        float64
+	Variant       string
+	Language      string
+	MinConfidence int
+}
+
+// UserPromptData sections of the user prompt, available to a prompt template's user part as
+// {{.Account}}, {{.Positions}}, ... The built-in user prompt is all sections in field order.
+type UserPromptData struct {
+	Header       string // Time, period, triggering market event and BTC overview
+	Account      string
+	RecentTrades string
+	TradingStats string
+	Positions    string // Open positions with their market data and indicators
+	Candidates   string // Candidate coins with their market data, indicators, derivatives and order book data
+	Rankings     string // Market-wide OI, net flow and price rankings
+	Footer       string // Closing instruction to analyse and decide
+
+	Time           string
+	CallCount      int
+	Equity         float64
+	PositionCount  int
+	CandidateCount int
+}
+
+// RenderPromptTemplate renders a prompt template over data; referencing an unknown section is an error
+func RenderPromptTemplate(text string, data interface{}) (string, error) {
+	tpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// outputFormatProbe stands in for the output format section while validating a system template
+const outputFormatProbe = "\x00output-format\x00"
+
+// ValidatePromptTemplate checks that both parts of a template parse and only reference known sections,
+// and that a system template renders {{.OutputFormat}}, without which decisions cannot be parsed
+func ValidatePromptTemplate(system, user string) error {
+	if system != "" {
+		rendered, err := RenderPromptTemplate(system, &SystemPromptData{OutputFormat: outputFormatProbe})
+		if err != nil {
+			return fmt.Errorf("system template: %w", err)
+		}
+		if !strings.Contains(rendered, outputFormatProbe) {
+			return fmt.Errorf("system template must include {{.OutputFormat}}")
+		}
+	}
+	if user != "" {
+		if _, err := RenderPromptTemplate(user, &UserPromptData{}); err != nil {
+			return fmt.Errorf("user template: %w", err)
+		}
+	}
+	return nil
+}
+
+// PreviewContext returns a small synthetic context for previewing user prompts without fetching market data
+func PreviewContext(accountEquity float64) *Context {
+	return &Context{
+		CurrentTime: time.Now().UTC().Format("2006-01-02 15:04:05"),
+		CallCount:   1,
+		Account: AccountInfo{
+			TotalEquity:      accountEquity,
+			AvailableBalance: accountEquity * 0.8,
+			MarginUsedPct:    20,
+			PositionCount:    1,
+		},
+		Positions: []PositionInfo{{
+			Symbol: "ETHUSDT", Side: "long", EntryPrice: 3000, MarkPrice: 3060, Quantity: 0.5,
+			Leverage: 5, UnrealizedPnL: 30, UnrealizedPnLPct: 10, MarginUsed: 300, LiquidationPrice: 2450,
+		}},
+		CandidateCoins: []CandidateCoin{{Symbol: "BTCUSDT", Sources: []string{"static"}}},
+		MarketDataMap: map[string]*market.Data{
+			"BTCUSDT": {Symbol: "BTCUSDT", CurrentPrice: 100000, PriceChange1h: 0.5, PriceChange4h: 1.2, CurrentEMA20: 99500, CurrentMACD: 120, CurrentRSI7: 58},
+			"ETHUSDT": {Symbol: "ETHUSDT", CurrentPrice: 3060, PriceChange1h: 0.8, PriceChange4h: 2.1, CurrentEMA20: 3020, CurrentMACD: 6, CurrentRSI7: 63},
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package kernel
+
+import (
+	"strings"
+	"testing"
+
+	"nofx/store"
+)
+
+// TestValidatePromptTemplate Test that templates may only reference known prompt sections
+func TestValidatePromptTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		system  string
+		user    string
+		wantErr bool
+	}{
+		{"sections", "{{.Role}}{{.RiskControl}}{{.OutputFormat}}", "{{.Account}}{{.Positions}}{{.Candidates}}", false},
+		{"conditionals", "{{if gt .Equity 1000.0}}{{.PositionSizing}}{{end}}{{.OutputFormat}}", "{{if .PositionCount}}{{.Positions}}{{end}}", false},
+		{"user part only", "", "{{.Account}}", false},
+		{"missing output format", "{{.Role}}{{.RiskControl}}", "", true},
+		{"output format never rendered", "{{if .CustomPrompt}}{{.OutputFormat}}{{end}}", "", true},
+		{"unknown system section", "{{.Portfolio}}{{.OutputFormat}}", "", true},
+		{"user section in system part", "{{.Candidates}}{{.OutputFormat}}", "", true},
+		{"parse error", "", "{{.Account", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePromptTemplate(tt.system, tt.user)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidatePromptTemplate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestPromptTemplateRendering Test that a strategy's template replaces the built-in prompts and falls back on errors
+func TestPromptTemplateRendering(t *testing.T) {
+	config := store.GetDefaultStrategyConfig("en")
+	engine := NewStrategyEngine(&config)
+	ctx := PreviewContext(1000)
+	builtinUser := engine.BuildUserPrompt(ctx)
+
+	config.PromptTemplate = &store.PromptTemplateRef{
+		Name:   "minimal",
+		System: "Equity {{.Equity}} ({{.Variant}})\n{{.RiskControl}}{{.OutputFormat}}",
+		User:   "{{.PositionCount}} position(s)\n{{.Positions}}",
+	}
+	system := engine.BuildSystemPrompt(1000, "aggressive")
+	if !strings.HasPrefix(system, "Equity 1000 (aggressive)\n# Hard Constraints") {
+		t.Errorf("system template not rendered: %.80q", system)
+	}
+	if strings.Contains(system, "Mode: Aggressive") {
+		t.Error("system template should drop sections it does not reference")
+	}
+	user := engine.BuildUserPrompt(ctx)
+	if !strings.HasPrefix(user, "1 position(s)\n") || !strings.Contains(user, "ETHUSDT") || strings.Contains(user, "BTCUSDT") {
+		t.Errorf("user template not rendered: %.80q", user)
+	}
+
+	config.PromptTemplate.User = "{{.Missing}}"
+	if got := engine.BuildUserPrompt(ctx); got != builtinUser {
+		t.Error("a failing template should fall back to the built-in user prompt")
+	}
+}
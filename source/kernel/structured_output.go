@@ -0,0 +1,260 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"nofx/logger"
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// decisionSchemaName name of the decision schema sent to providers (also Claude's forced tool name)
+const decisionSchemaName = "trading_decisions"
+
+// maxRepairErrorLen longest validation error sent back to the AI in a repair re-ask
+const maxRepairErrorLen = 600
+
+// decisionActions every action a decision may take
+var decisionActions = []string{"open_long", "open_short", "close_long", "close_short", "hold", "wait"}
+
+// decisionFieldDescriptions descriptions of the Decision fields in the decision schema
+var decisionFieldDescriptions = map[string]string{
+	"symbol":            "Symbol, e.g. BTCUSDT",
+	"action":            "Action for the symbol",
+	"leverage":          "Leverage when opening, 0 otherwise",
+	"position_size_usd": "Position value in USDT when opening, 0 otherwise",
+	"stop_loss":         "Stop-loss price when opening, 0 otherwise",
+	"take_profit":       "Take-profit price when opening, 0 otherwise",
+	"confidence":        "Confidence 0-100",
+	"risk_usd":          "Maximum loss in USDT when opening, 0 otherwise",
+	"reasoning":         "Short reason for this decision",
+}
+
+// structuredDecisionResponse answer following the decision schema
+type structuredDecisionResponse struct {
+	Reasoning string     `json:"reasoning"`
+	Decisions []Decision `json:"decisions"`
+}
+
+// DecisionSchema JSON schema of a structured decision answer, generated from Decision: an object holding
+// the chain of thought and the decision array. Every field is required so providers can enforce the schema
+// strictly; fields that do not apply to an action are 0.
+func DecisionSchema() map[string]any {
+	t := reflect.TypeOf(Decision{})
+	properties := make(map[string]any)
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		prop := map[string]any{"type": jsonSchemaType(t.Field(i).Type.Kind())}
+		if desc, ok := decisionFieldDescriptions[name]; ok {
+			prop["description"] = desc
+		}
+		if name == "action" {
+			prop["enum"] = decisionActions
+		}
+		properties[name] = prop
+		required = append(required, name)
+	}
+
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"reasoning": map[string]any{"type": "string", "description": "Chain of thought analysis"},
+			"decisions": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"properties":           properties,
+					"required":             required,
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"reasoning", "decisions"},
+		"additionalProperties": false,
+	}
+}
+
+func jsonSchemaType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	}
+	return "string"
+}
+
+// structuredOutput reports whether the strategy asks for decisions as a JSON object
+func (e *StrategyEngine) structuredOutput() bool {
+	return e.config.DecisionOutput != nil && e.config.DecisionOutput.Structured
+}
+
+// repairOnInvalid reports whether invalid decisions are sent back to the AI once per cycle
+func (e *StrategyEngine) repairOnInvalid() bool {
+	return e.config.DecisionOutput != nil && e.config.DecisionOutput.RepairOnInvalid
+}
+
+// formatStructuredOutputFormat output format section used in structured output mode
+func formatStructuredOutputFormat(riskControl store.RiskControlConfig, examplePositionSize float64) string {
+	var sb strings.Builder
+	sb.WriteString("# Output Format (Strictly Follow)\n\n")
+	sb.WriteString("**Answer with a single JSON object and nothing else**: `reasoning` holds your chain of thought, `decisions` the decision array.\n\n")
+	sb.WriteString("```json\n{\n")
+	sb.WriteString("  \"reasoning\": \"Your chain of thought analysis...\",\n")
+	sb.WriteString("  \"decisions\": [\n")
+	sb.WriteString(fmt.Sprintf("    {\"symbol\": \"BTCUSDT\", \"action\": \"open_short\", \"leverage\": %d, \"position_size_usd\": %.0f, \"stop_loss\": 97000, \"take_profit\": 91000, \"confidence\": 85, \"risk_usd\": 300, \"reasoning\": \"...\"},\n",
+		riskControl.BTCETHMaxLeverage, examplePositionSize))
+	sb.WriteString("    {\"symbol\": \"ETHUSDT\", \"action\": \"close_long\", \"leverage\": 0, \"position_size_usd\": 0, \"stop_loss\": 0, \"take_profit\": 0, \"confidence\": 80, \"risk_usd\": 0, \"reasoning\": \"...\"}\n")
+	sb.WriteString("  ]\n}\n```\n\n")
+	sb.WriteString("## Field Description\n\n")
+	sb.WriteString("- `action`: open_long | open_short | close_long | close_short | hold | wait\n")
+	sb.WriteString(fmt.Sprintf("- `confidence`: 0-100 (opening recommended ≥ %d)\n", riskControl.MinConfidence))
+	sb.WriteString("- Every field is required: when opening, set leverage, position_size_usd, stop_loss, take_profit and risk_usd; otherwise set them to 0\n")
+	sb.WriteString("- **IMPORTANT**: All numeric values must be calculated numbers, NOT formulas/expressions (e.g., use `27.76` not `3000 * 0.01`)\n\n")
+	return sb.String()
+}
+
+// parseDecisionResponse parses and validates an answer in the strategy's output format
+func (e *StrategyEngine) parseDecisionResponse(aiResponse string, accountEquity float64) (*FullDecision, error) {
+	riskConfig := e.GetRiskControlConfig()
+	if e.structuredOutput() {
+		return parseStructuredDecisionResponse(aiResponse, accountEquity,
+			riskConfig.BTCETHMaxLeverage,
+			riskConfig.AltcoinMaxLeverage,
+			riskConfig.BTCETHMaxPositionValueRatio,
+			riskConfig.AltcoinMaxPositionValueRatio,
+		)
+	}
+	return parseFullDecisionResponse(aiResponse, accountEquity,
+		riskConfig.BTCETHMaxLeverage,
+		riskConfig.AltcoinMaxLeverage,
+		riskConfig.BTCETHMaxPositionValueRatio,
+		riskConfig.AltcoinMaxPositionValueRatio,
+	)
+}
+
+// parseStructuredDecisionResponse parses an answer following the decision schema, falling back to text
+// parsing when the answer is not a JSON object with a decisions array
+func parseStructuredDecisionResponse(aiResponse string, accountEquity float64, btcEthLeverage, altcoinLeverage int, btcEthPosRatio, altcoinPosRatio float64) (*FullDecision, error) {
+	var resp structuredDecisionResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(aiResponse)), &resp); err != nil || resp.Decisions == nil {
+		logger.Infof("⚠️  Answer does not follow the decision schema, falling back to text parsing")
+		return parseFullDecisionResponse(aiResponse, accountEquity, btcEthLeverage, altcoinLeverage, btcEthPosRatio, altcoinPosRatio)
+	}
+	logger.Infof("✓ Parsed structured decision answer (%d decisions)", len(resp.Decisions))
+
+	decision := &FullDecision{
+		CoTTrace:  strings.TrimSpace(resp.Reasoning),
+		Decisions: resp.Decisions,
+	}
+	if err := validateDecisions(resp.Decisions, accountEquity, btcEthLeverage, altcoinLeverage, btcEthPosRatio, altcoinPosRatio); err != nil {
+		return decision, fmt.Errorf("decision validation failed: %w", err)
+	}
+	return decision, nil
+}
+
+// extractJSONObject the outermost JSON object of a response, which may be wrapped in a code fence
+func extractJSONObject(response string) string {
+	s := strings.TrimSpace(removeInvisibleRunes(response))
+	start, end := strings.Index(s, "{"), strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return s
+	}
+	return s[start : end+1]
+}
+
+// decisionCall one cycle's conversation with the AI: the first answer and at most one repair re-ask
+type decisionCall struct {
+	engine   *StrategyEngine
+	ctx      *Context
+	client   mcp.AIClient
+	messages []mcp.Message
+	schema   *mcp.ResponseSchema // Set when the provider enforces the decision schema natively
+
+	toolCalls  []ToolCallRecord
+	structured bool // The latest answer was held to the schema (not the case with tools on some providers)
+}
+
+func (e *StrategyEngine) newDecisionCall(ctx *Context, client mcp.AIClient, systemPrompt, userPrompt string) *decisionCall {
+	call := &decisionCall{
+		engine:   e,
+		ctx:      ctx,
+		client:   client,
+		messages: []mcp.Message{mcp.NewSystemMessage(systemPrompt), mcp.NewUserMessage(userPrompt)},
+	}
+	if sc, ok := client.(mcp.StructuredOutputCaller); ok && e.structuredOutput() && sc.SupportsStructuredOutput() {
+		call.schema = &mcp.ResponseSchema{
+			Name:        decisionSchemaName,
+			Description: "Chain of thought and trading decisions for this cycle",
+			Schema:      DecisionSchema(),
+		}
+	}
+	return call
+}
+
+func (c *decisionCall) request() *mcp.Request {
+	return &mcp.Request{
+		Messages:       append([]mcp.Message{}, c.messages...),
+		ResponseSchema: c.schema,
+	}
+}
+
+// ask gets the first answer, with the data tools when the strategy enables them
+func (c *decisionCall) ask() (string, error) {
+	if toolCaller, ok := c.client.(mcp.ToolCaller); ok && c.engine.toolCallingEnabled() {
+		req := c.request()
+		response, records, err := c.engine.callWithTools(c.ctx, toolCaller, req)
+		c.toolCalls = append(c.toolCalls, records...)
+		c.structured = err == nil && c.enforced(req)
+		return response, err
+	}
+	if c.schema != nil {
+		req := c.request()
+		response, err := c.client.CallWithRequest(req)
+		c.structured = err == nil && c.enforced(req)
+		return response, err
+	}
+	return c.client.CallWithMessages(c.messages[0].Content, c.messages[1].Content)
+}
+
+// enforced whether the answer to req was held to the decision schema; clients that cannot tell are
+// trusted on SupportsStructuredOutput
+func (c *decisionCall) enforced(req *mcp.Request) bool {
+	if c.schema == nil {
+		return false
+	}
+	if se, ok := c.client.(mcp.SchemaEnforcer); ok {
+		return se.EnforcesSchema(req)
+	}
+	return true
+}
+
+// repair sends the answer's validation error back and asks for corrected decisions (no tools: the
+// cycle's tool budget is already spent)
+func (c *decisionCall) repair(answer string, validationErr error) (string, error) {
+	reason := validationErr.Error()
+	if len(reason) > maxRepairErrorLen {
+		reason = reason[:maxRepairErrorLen] + "..."
+	}
+	c.messages = append(c.messages,
+		mcp.NewAssistantMessage(answer),
+		mcp.NewUserMessage("Your answer could not be used: "+reason+"\n\n"+
+			"Fix the decisions and answer again in exactly the same output format. Keep the decisions that were valid, "+
+			"and use wait for any coin you cannot fix within the rules."),
+	)
+	req := c.request()
+	response, err := c.client.CallWithRequest(req)
+	if err == nil {
+		c.structured = c.enforced(req)
+	}
+	return response, err
+}
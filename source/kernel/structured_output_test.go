@@ -0,0 +1,157 @@
+package kernel
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// scriptedClient AI client answering with a fixed list of responses and recording the requests
+type scriptedClient struct {
+	responses  []string
+	requests   []*mcp.Request
+	structured bool
+}
+
+func (c *scriptedClient) SetAPIKey(apiKey, customURL, customModel string) {}
+func (c *scriptedClient) SetTimeout(timeout time.Duration)                {}
+func (c *scriptedClient) SupportsStructuredOutput() bool                  { return c.structured }
+
+func (c *scriptedClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return c.CallWithRequest(&mcp.Request{Messages: []mcp.Message{mcp.NewSystemMessage(systemPrompt), mcp.NewUserMessage(userPrompt)}})
+}
+
+func (c *scriptedClient) CallWithRequest(req *mcp.Request) (string, error) {
+	c.requests = append(c.requests, req)
+	response := c.responses[0]
+	c.responses = c.responses[1:]
+	return response, nil
+}
+
+// TestDecisionSchema Test that the schema covers every Decision field and can be enforced strictly
+func TestDecisionSchema(t *testing.T) {
+	schema := DecisionSchema()
+	items := schema["properties"].(map[string]any)["decisions"].(map[string]any)["items"].(map[string]any)
+	properties := items["properties"].(map[string]any)
+	required := items["required"].([]string)
+	if len(properties) != 9 || len(required) != len(properties) || items["additionalProperties"] != false {
+		t.Fatalf("every decision field should be required: %v", required)
+	}
+	if properties["leverage"].(map[string]any)["type"] != "integer" || properties["stop_loss"].(map[string]any)["type"] != "number" {
+		t.Errorf("unexpected field types: %v", properties)
+	}
+	if actions := properties["action"].(map[string]any)["enum"].([]string); len(actions) != 6 {
+		t.Errorf("unexpected actions: %v", actions)
+	}
+}
+
+// TestParseStructuredDecisionResponse Test structured answers, fenced answers and the text fallback
+func TestParseStructuredDecisionResponse(t *testing.T) {
+	parse := func(response string) (*FullDecision, error) {
+		return parseStructuredDecisionResponse(response, 1000, 10, 5, 5, 1)
+	}
+
+	decision, err := parse("```json\n{\"reasoning\": \"flat market\", \"decisions\": [{\"symbol\": \"BTCUSDT\", \"action\": \"wait\", \"leverage\": 0, \"position_size_usd\": 0, \"stop_loss\": 0, \"take_profit\": 0, \"confidence\": 60, \"risk_usd\": 0, \"reasoning\": \"no setup\"}]}\n```")
+	if err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+	if decision.CoTTrace != "flat market" || len(decision.Decisions) != 1 || decision.Decisions[0].Action != "wait" {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+
+	decision, err = parse("<reasoning>tagged</reasoning><decision>[{\"symbol\": \"ETHUSDT\", \"action\": \"close_long\"}]</decision>")
+	if err != nil || decision.CoTTrace != "tagged" || decision.Decisions[0].Action != "close_long" {
+		t.Errorf("tagged answers should fall back to text parsing: %+v, %v", decision, err)
+	}
+
+	_, err = parse(`{"reasoning": "", "decisions": [{"symbol": "SOLUSDT", "action": "open_long", "leverage": 3, "position_size_usd": 100, "stop_loss": 110, "take_profit": 100, "confidence": 80, "risk_usd": 10, "reasoning": ""}]}`)
+	if err == nil || !strings.Contains(err.Error(), "validation failed") {
+		t.Errorf("invalid decisions should fail validation, got %v", err)
+	}
+}
+
+// TestDecisionRepair Test that an invalid answer is sent back once with its validation error
+func TestDecisionRepair(t *testing.T) {
+	config := store.GetDefaultStrategyConfig("en")
+	config.DecisionOutput = &store.DecisionOutputConfig{Structured: true, RepairOnInvalid: true}
+	engine := NewStrategyEngine(&config)
+	ctx := PreviewContext(1000)
+	ctx.OITopDataMap = map[string]*OITopData{}
+
+	invalid := `{"reasoning": "r", "decisions": [{"symbol": "SOLUSDT", "action": "open_long", "leverage": 3, "position_size_usd": 100, "stop_loss": 110, "take_profit": 100, "confidence": 80, "risk_usd": 10, "reasoning": ""}]}`
+	valid := `{"reasoning": "fixed", "decisions": [{"symbol": "SOLUSDT", "action": "wait", "leverage": 0, "position_size_usd": 0, "stop_loss": 0, "take_profit": 0, "confidence": 50, "risk_usd": 0, "reasoning": ""}]}`
+	client := &scriptedClient{responses: []string{invalid, valid}, structured: true}
+
+	decision, err := GetFullDecisionWithStrategy(ctx, client, engine, "balanced")
+	if err != nil {
+		t.Fatalf("repaired answer should be accepted: %v", err)
+	}
+	if decision.CoTTrace != "fixed" || !decision.StructuredOutput || decision.RepairError == "" {
+		t.Errorf("unexpected decision: %+v", decision)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("expected one repair request, got %d requests", len(client.requests))
+	}
+	if schema := client.requests[0].ResponseSchema; schema == nil || schema.Name != decisionSchemaName {
+		t.Error("the decision schema should be sent to providers that enforce it")
+	}
+	repair := client.requests[1].Messages
+	if len(repair) != 4 || repair[2].Content != invalid || !strings.Contains(repair[3].Content, "stop loss price must be less") {
+		t.Errorf("repair request should carry the answer and its validation error: %+v", repair)
+	}
+	if !strings.Contains(decision.SystemPrompt, "single JSON object") {
+		t.Error("structured mode should ask for a JSON object")
+	}
+}
+
+// toolScriptedClient scripted client with tool calling that, like Claude, only enforces the schema on
+// requests without tools
+type toolScriptedClient struct {
+	scriptedClient
+}
+
+func (c *toolScriptedClient) CallWithTools(req *mcp.Request, execute mcp.ToolExecutor, budget int) (string, error) {
+	return c.CallWithRequest(req)
+}
+
+func (c *toolScriptedClient) EnforcesSchema(req *mcp.Request) bool {
+	return req.ResponseSchema != nil && len(req.Tools) == 0
+}
+
+// TestStructuredOutputWithTools Test that answers are only recorded as structured when the provider
+// enforced the schema on the request that produced them
+func TestStructuredOutputWithTools(t *testing.T) {
+	config := store.GetDefaultStrategyConfig("en")
+	config.DecisionOutput = &store.DecisionOutputConfig{Structured: true, RepairOnInvalid: true}
+	config.ToolCalling = &store.ToolCallingConfig{Enabled: true}
+	engine := NewStrategyEngine(&config)
+
+	invalid := `{"reasoning": "r", "decisions": [{"symbol": "SOLUSDT", "action": "open_long", "leverage": 3, "position_size_usd": 100, "stop_loss": 110, "take_profit": 100, "confidence": 80, "risk_usd": 10, "reasoning": ""}]}`
+	valid := `{"reasoning": "fixed", "decisions": [{"symbol": "SOLUSDT", "action": "wait", "leverage": 0, "position_size_usd": 0, "stop_loss": 0, "take_profit": 0, "confidence": 50, "risk_usd": 0, "reasoning": ""}]}`
+
+	ctx := PreviewContext(1000)
+	ctx.OITopDataMap = map[string]*OITopData{}
+	client := &toolScriptedClient{scriptedClient{responses: []string{valid}, structured: true}}
+	decision, err := GetFullDecisionWithStrategy(ctx, client, engine, "balanced")
+	if err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+	if len(client.requests[0].Tools) == 0 || decision.StructuredOutput {
+		t.Errorf("an answer to a request with tools was not held to the schema: %+v", decision)
+	}
+
+	// The repair is sent without tools, so its answer is
+	ctx = PreviewContext(1000)
+	ctx.OITopDataMap = map[string]*OITopData{}
+	client = &toolScriptedClient{scriptedClient{responses: []string{invalid, valid}, structured: true}}
+	decision, err = GetFullDecisionWithStrategy(ctx, client, engine, "balanced")
+	if err != nil {
+		t.Fatalf("repaired answer should be accepted: %v", err)
+	}
+	if decision.RepairError == "" || !decision.StructuredOutput {
+		t.Errorf("the repaired answer was held to the schema: %+v", decision)
+	}
+}
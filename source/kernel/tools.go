@@ -0,0 +1,294 @@
+package kernel
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"nofx/market"
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// Data tools the AI can call during a decision cycle
+const (
+	ToolGetKlines          = "get_klines"
+	ToolGetOrderBook       = "get_orderbook"
+	ToolGetFundingHistory  = "get_funding_history"
+	ToolGetPositionHistory = "get_position_history"
+)
+
+const (
+	defaultToolBudget = 8  // Tool calls per cycle when the strategy does not set one
+	maxToolBudget     = 30 // Upper bound on the per-cycle budget
+	maxToolResultLen  = 2000
+)
+
+// ToolCallRecord a tool the AI called during a decision, kept with the decision for review
+type ToolCallRecord struct {
+	Name       string `json:"name"`
+	Arguments  string `json:"arguments"`
+	Result     string `json:"result,omitempty"` // Truncated
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+// PositionHistoryFunc closed trades of one symbol, newest first
+type PositionHistoryFunc func(symbol string, limit int) ([]store.RecentTrade, error)
+
+// toolDefinitions every data tool, in the order they are offered
+var toolDefinitions = []mcp.Tool{
+	{Type: "function", Function: mcp.FunctionDef{
+		Name:        ToolGetKlines,
+		Description: "Get recent OHLCV candles of a symbol, oldest first. Each candle is [open_time_ms, open, high, low, close, volume].",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"symbol":    map[string]any{"type": "string", "description": "Symbol, e.g. BTCUSDT"},
+				"timeframe": map[string]any{"type": "string", "description": "Candle timeframe, e.g. 5m, 15m, 1h, 4h, 1d (default: the strategy's primary timeframe)"},
+				"limit":     map[string]any{"type": "integer", "description": "Number of candles, 1-200 (default 30)"},
+			},
+			"required": []string{"symbol"},
+		},
+	}},
+	{Type: "function", Function: mcp.FunctionDef{
+		Name:        ToolGetOrderBook,
+		Description: "Get the order book of a symbol: best bid/ask, spread, depth within 1% of mid, imbalance and the top levels.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"symbol": map[string]any{"type": "string", "description": "Symbol, e.g. BTCUSDT"},
+				"levels": map[string]any{"type": "integer", "description": "Top levels per side, 1-50 (default 10)"},
+			},
+			"required": []string{"symbol"},
+		},
+	}},
+	{Type: "function", Function: mcp.FunctionDef{
+		Name:        ToolGetFundingHistory,
+		Description: "Get the latest settled funding rates of a crypto perpetual, oldest first.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"symbol": map[string]any{"type": "string", "description": "Symbol, e.g. BTCUSDT"},
+				"limit":  map[string]any{"type": "integer", "description": "Number of funding periods, 1-100 (default 10)"},
+			},
+			"required": []string{"symbol"},
+		},
+	}},
+	{Type: "function", Function: mcp.FunctionDef{
+		Name:        ToolGetPositionHistory,
+		Description: "Get this trader's latest closed positions of a symbol, newest first, with entry/exit prices and realized PnL.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"symbol": map[string]any{"type": "string", "description": "Symbol, e.g. BTCUSDT"},
+				"limit":  map[string]any{"type": "integer", "description": "Number of trades, 1-50 (default 10)"},
+			},
+			"required": []string{"symbol"},
+		},
+	}},
+}
+
+// toolArgs arguments accepted by the data tools
+type toolArgs struct {
+	Symbol    string `json:"symbol"`
+	Timeframe string `json:"timeframe"`
+	Limit     int    `json:"limit"`
+	Levels    int    `json:"levels"`
+}
+
+// toolCallingEnabled reports whether the strategy lets the AI call data tools
+func (e *StrategyEngine) toolCallingEnabled() bool {
+	return e.config.ToolCalling != nil && e.config.ToolCalling.Enabled && len(e.tools()) > 0
+}
+
+// compactPrompt reports whether candidates are summarized in one line each, leaving detail to the tools
+func (e *StrategyEngine) compactPrompt() bool {
+	return e.toolCallingEnabled() && e.config.ToolCalling.CompactPrompt
+}
+
+// toolBudget max tool calls per decision cycle
+func (e *StrategyEngine) toolBudget() int {
+	budget := e.config.ToolCalling.MaxCalls
+	if budget <= 0 {
+		budget = defaultToolBudget
+	}
+	if budget > maxToolBudget {
+		budget = maxToolBudget
+	}
+	return budget
+}
+
+// tools the data tools enabled by the strategy (all of them when none are listed)
+func (e *StrategyEngine) tools() []mcp.Tool {
+	if e.config.ToolCalling == nil || len(e.config.ToolCalling.Tools) == 0 {
+		return toolDefinitions
+	}
+	enabled := make(map[string]bool, len(e.config.ToolCalling.Tools))
+	for _, name := range e.config.ToolCalling.Tools {
+		enabled[name] = true
+	}
+	var tools []mcp.Tool
+	for _, tool := range toolDefinitions {
+		if enabled[tool.Function.Name] {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// formatToolsSection system prompt section describing the enabled tools, empty when tool calling is off
+func (e *StrategyEngine) formatToolsSection() string {
+	if !e.toolCallingEnabled() {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("# 🔧 Data Tools\n\n")
+	sb.WriteString(fmt.Sprintf("You can call these tools to fetch more data before deciding (at most %d calls this cycle):\n", e.toolBudget()))
+	for _, tool := range e.tools() {
+		sb.WriteString(fmt.Sprintf("- `%s`: %s\n", tool.Function.Name, tool.Function.Description))
+	}
+	sb.WriteString("\nOnly call tools for the coins where the data above is not enough to decide. ")
+	sb.WriteString("Once you have what you need, answer in the output format below.\n\n")
+	return sb.String()
+}
+
+// callWithTools asks the AI for a decision, executing the data tools it calls within the cycle's budget
+func (e *StrategyEngine) callWithTools(ctx *Context, client mcp.ToolCaller, request *mcp.Request) (string, []ToolCallRecord, error) {
+	request.Tools = e.tools()
+	request.ToolChoice = "auto"
+
+	var records []ToolCallRecord
+	response, err := client.CallWithTools(request, func(call mcp.ToolCall) (string, error) {
+		start := time.Now()
+		result, err := e.executeTool(ctx, call)
+		record := ToolCallRecord{
+			Name:       call.Function.Name,
+			Arguments:  call.Function.Arguments,
+			Result:     truncateToolResult(result),
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		records = append(records, record)
+		return result, err
+	}, e.toolBudget())
+	return response, records, err
+}
+
+// executeTool runs one data tool and returns its result as JSON
+func (e *StrategyEngine) executeTool(ctx *Context, call mcp.ToolCall) (string, error) {
+	enabled := false
+	for _, tool := range e.tools() {
+		if tool.Function.Name == call.Function.Name {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		return "", fmt.Errorf("unknown tool %q", call.Function.Name)
+	}
+
+	var args toolArgs
+	if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(args.Symbol) == "" {
+		return "", fmt.Errorf("symbol is required")
+	}
+	symbol := market.Normalize(args.Symbol)
+
+	var result interface{}
+	var err error
+	switch call.Function.Name {
+	case ToolGetKlines:
+		result, err = e.toolKlines(symbol, args.Timeframe, clampToolLimit(args.Limit, 30, 200))
+	case ToolGetOrderBook:
+		result, err = toolOrderBook(symbol, clampToolLimit(args.Levels, 10, 50))
+	case ToolGetFundingHistory:
+		result, err = toolFundingHistory(symbol, clampToolLimit(args.Limit, 10, 100))
+	case ToolGetPositionHistory:
+		if ctx.PositionHistory == nil {
+			return "", fmt.Errorf("position history is not available")
+		}
+		result, err = ctx.PositionHistory(symbol, clampToolLimit(args.Limit, 10, 50))
+	}
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	if string(data) == "null" {
+		return "[]", nil
+	}
+	return string(data), nil
+}
+
+func (e *StrategyEngine) toolKlines(symbol, timeframe string, limit int) ([][6]float64, error) {
+	if timeframe == "" {
+		timeframe = e.config.Indicators.Klines.PrimaryTimeframe
+	}
+	if timeframe == "" {
+		timeframe = "5m"
+	}
+	timeframe, err := market.NormalizeTimeframe(timeframe)
+	if err != nil {
+		return nil, err
+	}
+	klines, err := market.GetKlines(symbol, timeframe, limit)
+	if err != nil {
+		return nil, err
+	}
+	candles := make([][6]float64, 0, len(klines))
+	for _, k := range klines {
+		candles = append(candles, [6]float64{float64(k.OpenTime), k.Open, k.High, k.Low, k.Close, k.Volume})
+	}
+	return candles, nil
+}
+
+func toolOrderBook(symbol string, levels int) (*market.OrderBookFeatures, error) {
+	book, err := market.GetOrderBook(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return book.Features(levels), nil
+}
+
+func toolFundingHistory(symbol string, limit int) ([]market.FundingRate, error) {
+	if market.ClassifySymbol(symbol) != market.AssetCrypto {
+		return nil, fmt.Errorf("funding history is only available for crypto perpetuals")
+	}
+	return market.NewAPIClient().GetFundingRateHistory(symbol, limit)
+}
+
+func clampToolLimit(v, def, max int) int {
+	if v <= 0 {
+		return def
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+func truncateToolResult(result string) string {
+	if len(result) <= maxToolResultLen {
+		return result
+	}
+	return result[:maxToolResultLen] + "...(truncated)"
+}
+
+// formatCompactCandidate one-line candidate summary used by the compact prompt
+func formatCompactCandidate(index int, label string, data *market.Data) string {
+	line := fmt.Sprintf("%d. %s | Price %.4f | 1h %+.2f%% | 4h %+.2f%% | EMA20 %.3f | MACD %.3f | RSI7 %.2f",
+		index, label, data.CurrentPrice, data.PriceChange1h, data.PriceChange4h,
+		data.CurrentEMA20, data.CurrentMACD, data.CurrentRSI7)
+	if data.FundingRate != 0 {
+		line += fmt.Sprintf(" | Funding %.4f%%", data.FundingRate*100)
+	}
+	return line + "\n"
+}
@@ -0,0 +1,70 @@
+package kernel
+
+import (
+	"strings"
+	"testing"
+
+	"nofx/mcp"
+	"nofx/store"
+)
+
+// TestToolCallingPrompts Test that the tools section and compact candidates only appear when tool calling is enabled
+func TestToolCallingPrompts(t *testing.T) {
+	config := store.GetDefaultStrategyConfig("en")
+	engine := NewStrategyEngine(&config)
+	ctx := PreviewContext(1000)
+	builtinSystem := engine.BuildSystemPrompt(1000, "balanced")
+	builtinUser := engine.BuildUserPrompt(ctx)
+	if strings.Contains(builtinSystem, ToolGetKlines) {
+		t.Fatal("tools should not be described while tool calling is disabled")
+	}
+
+	config.ToolCalling = &store.ToolCallingConfig{
+		Enabled:       true,
+		MaxCalls:      3,
+		CompactPrompt: true,
+		Tools:         []string{ToolGetKlines, ToolGetPositionHistory},
+	}
+	system := engine.BuildSystemPrompt(1000, "balanced")
+	if !strings.Contains(system, "at most 3 calls") || !strings.Contains(system, ToolGetPositionHistory) || strings.Contains(system, ToolGetOrderBook) {
+		t.Errorf("system prompt should describe the enabled tools and budget:\n%s", system)
+	}
+	user := engine.BuildUserPrompt(ctx)
+	if len(user) >= len(builtinUser) || !strings.Contains(user, "1. BTCUSDT") || strings.Contains(user, "=== BTCUSDT Market Data ===") {
+		t.Errorf("compact prompt should summarize candidates in one line:\n%s", user)
+	}
+}
+
+// TestExecuteTool Test argument handling, disabled tools and the position history tool
+func TestExecuteTool(t *testing.T) {
+	config := store.GetDefaultStrategyConfig("en")
+	config.ToolCalling = &store.ToolCallingConfig{Enabled: true, Tools: []string{ToolGetPositionHistory}}
+	engine := NewStrategyEngine(&config)
+
+	var gotSymbol string
+	var gotLimit int
+	ctx := &Context{PositionHistory: func(symbol string, limit int) ([]store.RecentTrade, error) {
+		gotSymbol, gotLimit = symbol, limit
+		return nil, nil
+	}}
+	call := func(name, args string) (string, error) {
+		return engine.executeTool(ctx, mcp.ToolCall{ID: "1", Type: "function", Function: mcp.ToolCallFunction{Name: name, Arguments: args}})
+	}
+
+	result, err := call(ToolGetPositionHistory, `{"symbol":"eth","limit":500}`)
+	if err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+	if result != "[]" || gotSymbol != "ETHUSDT" || gotLimit != 50 {
+		t.Errorf("unexpected call: result %s symbol %s limit %d", result, gotSymbol, gotLimit)
+	}
+	if _, err := call(ToolGetKlines, `{"symbol":"BTCUSDT"}`); err == nil {
+		t.Error("disabled tools should be rejected")
+	}
+	if _, err := call(ToolGetPositionHistory, `{}`); err == nil {
+		t.Error("a symbol is required")
+	}
+	if _, err := call(ToolGetPositionHistory, `not json`); err == nil {
+		t.Error("invalid arguments should be rejected")
+	}
+}
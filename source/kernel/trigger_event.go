@@ -0,0 +1,16 @@
+package kernel
+
+import (
+	"fmt"
+	"nofx/store"
+)
+
+// formatTriggerEvent describes the market event that launched an out-of-band decision cycle
+func (e *StrategyEngine) formatTriggerEvent(event *store.MarketEvent) string {
+	if e.GetLanguage() == LangChinese {
+		return fmt.Sprintf("⚡ 事件触发周期 (%s): %s\n本次分析由该市场事件在定时扫描之外触发，请优先评估其对持仓和候选币种的影响。\n\n",
+			event.Type, event.Message)
+	}
+	return fmt.Sprintf("⚡ Event-triggered cycle (%s): %s\nThis analysis was launched between scheduled scans by the event above; assess its impact on positions and candidates first.\n\n",
+		event.Type, event.Message)
+}
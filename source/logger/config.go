@@ -1,8 +1,17 @@
 package logger
 
+import (
+	"os"
+	"strconv"
+)
+
 // Config is the logger configuration (simplified version)
 type Config struct {
-	Level string `json:"level"` // Log level: debug, info, warn, error (default: info)
+	Level      string `json:"level"`       // Log level: debug, info, warn, error (default: info)
+	Format     string `json:"format"`      // Output format: text or json (default: text)
+	Dir        string `json:"dir"`         // Log directory (default: data)
+	MaxSizeMB  int    `json:"max_size_mb"` // Per-trader log file size before rotation (default: 10)
+	MaxBackups int    `json:"max_backups"` // Rotated per-trader log files to keep (default: 5)
 }
 
 // SetDefaults sets default values
@@ -10,4 +19,33 @@ func (c *Config) SetDefaults() {
 	if c.Level == "" {
 		c.Level = "info"
 	}
+	if c.Format != FormatJSON {
+		c.Format = FormatText
+	}
+	if c.Dir == "" {
+		c.Dir = "data"
+	}
+	if c.MaxSizeMB <= 0 {
+		c.MaxSizeMB = 10
+	}
+	if c.MaxBackups <= 0 {
+		c.MaxBackups = 5
+	}
+}
+
+// ConfigFromEnv builds the logger configuration from LOG_* environment variables
+func ConfigFromEnv() *Config {
+	cfg := &Config{
+		Level:  os.Getenv("LOG_LEVEL"),
+		Format: os.Getenv("LOG_FORMAT"),
+		Dir:    os.Getenv("LOG_DIR"),
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_MAX_SIZE_MB")); err == nil {
+		cfg.MaxSizeMB = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOG_MAX_BACKUPS")); err == nil {
+		cfg.MaxBackups = v
+	}
+	cfg.SetDefaults()
+	return cfg
 }
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Structured field names used to correlate log lines
+const (
+	FieldTraderID = "trader_id"
+	FieldCycle    = "cycle"
+	FieldSymbol   = "symbol"
+	FieldOrderID  = "order_id"
+	FieldRunID    = "run_id"
+	FieldDebateID = "debate_id"
+	FieldCaller   = "caller"
+)
+
+// fieldOrder is the display order of well-known fields in text output
+var fieldOrder = []string{FieldTraderID, FieldRunID, FieldDebateID, FieldCycle, FieldSymbol, FieldOrderID}
+
+// fieldLabels are the short labels used in text output
+var fieldLabels = map[string]string{
+	FieldTraderID: "trader",
+	FieldRunID:    "run",
+	FieldDebateID: "debate",
+	FieldCycle:    "cycle",
+	FieldSymbol:   "symbol",
+	FieldOrderID:  "order",
+}
+
+// ForTrader returns an entry tagged with trader_id; lines logged through it
+// also go to the trader's own log file
+func ForTrader(traderID string) *logrus.Entry {
+	return Log.WithField(FieldTraderID, traderID)
+}
+
+// ForRun returns an entry tagged with a backtest run_id
+func ForRun(runID string) *logrus.Entry {
+	return Log.WithField(FieldRunID, runID)
+}
+
+// ForDebate returns an entry tagged with debate_id
+func ForDebate(debateID string) *logrus.Entry {
+	return Log.WithField(FieldDebateID, debateID)
+}
+
+// formatFields renders entry fields as "trader=x cycle=3 symbol=BTCUSDT",
+// well-known fields first, then the rest sorted by key
+func formatFields(data logrus.Fields) string {
+	if len(data) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(data))
+	seen := make(map[string]bool, len(fieldOrder))
+	for _, k := range fieldOrder {
+		v, ok := data[k]
+		if !ok || isEmptyField(v) {
+			continue
+		}
+		seen[k] = true
+		parts = append(parts, fmt.Sprintf("%s=%v", fieldLabels[k], v))
+	}
+	var rest []string
+	for k := range data {
+		if !seen[k] && k != FieldCaller && !isEmptyField(data[k]) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		parts = append(parts, fmt.Sprintf("%s=%v", k, data[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func isEmptyField(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	s, ok := v.(string)
+	return ok && s == ""
+}
@@ -19,6 +19,30 @@ var (
 	logFile *os.File
 )
 
+// Output formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// callerOf finds the first frame outside logrus and this package,
+// formatted as "pkg/file.go:line" (e.g., "nofx/manager/trader_manager.go" -> "manager/trader_manager.go")
+func callerOf() string {
+	for i := 3; i < 16; i++ {
+		_, file, line, ok := runtime.Caller(i)
+		if !ok {
+			break
+		}
+		// Skip logrus internal and our logger package
+		if !strings.Contains(file, "logrus") && filepath.Base(filepath.Dir(file)) != "logger" {
+			dir := filepath.Dir(file)
+			pkg := filepath.Base(dir)
+			return fmt.Sprintf("%s/%s:%d", pkg, filepath.Base(file), line)
+		}
+	}
+	return ""
+}
+
 // compactFormatter is a custom formatter for cleaner log output
 type compactFormatter struct {
 	logrus.TextFormatter
@@ -28,27 +52,31 @@ func (f *compactFormatter) Format(entry *logrus.Entry) ([]byte, error) {
 	level := strings.ToUpper(entry.Level.String())[0:4]
 	timestamp := entry.Time.Format("01-02 15:04:05")
 
-	// Skip frames to find actual caller (skip logrus + our wrapper functions)
-	caller := ""
-	for i := 3; i < 10; i++ {
-		_, file, line, ok := runtime.Caller(i)
-		if !ok {
-			break
-		}
-		// Skip logrus internal and our logger.go
-		if !strings.Contains(file, "logrus") && !strings.HasSuffix(file, "logger/logger.go") {
-			// Get package name from path (e.g., "nofx/manager/trader_manager.go" -> "manager")
-			dir := filepath.Dir(file)
-			pkg := filepath.Base(dir)
-			caller = fmt.Sprintf("%s/%s:%d", pkg, filepath.Base(file), line)
-			break
-		}
+	caller := callerOf()
+	if fields := formatFields(entry.Data); fields != "" {
+		caller += " [" + fields + "]"
 	}
 
 	msg := fmt.Sprintf("%s [%s] %s %s\n", timestamp, level, caller, entry.Message)
 	return []byte(msg), nil
 }
 
+// jsonFormatter writes one JSON object per line, with the caller as a field
+type jsonFormatter struct {
+	logrus.JSONFormatter
+}
+
+func (f *jsonFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data := make(logrus.Fields, len(entry.Data)+1)
+	for k, v := range entry.Data {
+		data[k] = v
+	}
+	data[FieldCaller] = callerOf()
+	clone := *entry
+	clone.Data = data
+	return f.JSONFormatter.Format(&clone)
+}
+
 func init() {
 	// Auto-initialize default logger to ensure it works before Init is called
 	Log = logrus.New()
@@ -64,8 +92,6 @@ func init() {
 // Init initializes the global logger
 // If config is nil, uses default configuration (console output, info level)
 func Init(cfg *Config) error {
-	Log = logrus.New()
-
 	// Use default values if no config provided
 	if cfg == nil {
 		cfg = &Config{Level: "info"}
@@ -74,6 +100,9 @@ func Init(cfg *Config) error {
 	// Set default values
 	cfg.SetDefaults()
 
+	// Reconfigure the existing logger so entries created before Init keep working
+	Log.ReplaceHooks(make(logrus.LevelHooks))
+
 	// Set log level
 	level, err := logrus.ParseLevel(cfg.Level)
 	if err != nil {
@@ -81,11 +110,15 @@ func Init(cfg *Config) error {
 	}
 	Log.SetLevel(level)
 
-	// Set compact formatter
-	Log.SetFormatter(&compactFormatter{})
+	// Set formatter
+	if cfg.Format == FormatJSON {
+		Log.SetFormatter(&jsonFormatter{logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano, DisableHTMLEscape: true}})
+	} else {
+		Log.SetFormatter(&compactFormatter{})
+	}
 
 	// Setup log file output (write to both stdout and file)
-	logDir := "data"
+	logDir := cfg.Dir
 	if err := os.MkdirAll(logDir, 0755); err == nil {
 		logFileName := filepath.Join(logDir, fmt.Sprintf("nofx_%s.log", time.Now().Format("2006-01-02")))
 		f, err := os.OpenFile(logFileName, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
@@ -100,7 +133,9 @@ func Init(cfg *Config) error {
 		Log.SetOutput(os.Stdout)
 	}
 
-	Log.SetReportCaller(true)
+	// Per-trader rotating log files (data/traders/<trader_id>.log)
+	sinks = newTraderSinks(filepath.Join(logDir, "traders"), int64(cfg.MaxSizeMB)<<20, cfg.MaxBackups)
+	Log.AddHook(sinks)
 
 	return nil
 }
@@ -113,6 +148,9 @@ func InitWithSimpleConfig(level string) error {
 
 // Shutdown gracefully shuts down the logger
 func Shutdown() {
+	if sinks != nil {
+		sinks.Close()
+	}
 	if logFile != nil {
 		logFile.Close()
 		logFile = nil
@@ -0,0 +1,349 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// sinks is the per-trader log sink installed by Init (nil until then)
+var sinks *traderSinks
+
+// LogLine is one structured log line of a trader
+type LogLine struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Caller  string                 `json:"caller,omitempty"`
+	Cycle   int64                  `json:"cycle,omitempty"`
+	Symbol  string                 `json:"symbol,omitempty"`
+	OrderID string                 `json:"order_id,omitempty"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+// LogFilter selects trader log lines; zero values match everything
+type LogFilter struct {
+	Level  string    // minimum level (debug, info, warn, error)
+	Symbol string    // exact symbol
+	Cycle  int64     // exact cycle number
+	Query  string    // case-insensitive substring of the message
+	Since  time.Time // only lines at or after this time
+	Limit  int       // most recent N matching lines (default 200, max 2000)
+}
+
+const (
+	defaultLogLimit = 200
+	maxLogLimit     = 2000
+)
+
+// Match reports whether the line passes the filter
+func (f *LogFilter) Match(l *LogLine) bool {
+	if f.Level != "" {
+		min, err := logrus.ParseLevel(f.Level)
+		lvl, err2 := logrus.ParseLevel(l.Level)
+		// logrus levels are ordered from most to least severe
+		if err == nil && err2 == nil && lvl > min {
+			return false
+		}
+	}
+	if f.Symbol != "" && !strings.EqualFold(f.Symbol, l.Symbol) {
+		return false
+	}
+	if f.Cycle > 0 && f.Cycle != l.Cycle {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(l.Message), strings.ToLower(f.Query)) {
+		return false
+	}
+	if !f.Since.IsZero() && l.Time.Before(f.Since) {
+		return false
+	}
+	return true
+}
+
+func (f *LogFilter) limit() int {
+	if f.Limit <= 0 {
+		return defaultLogLimit
+	}
+	if f.Limit > maxLogLimit {
+		return maxLogLimit
+	}
+	return f.Limit
+}
+
+// QueryTraderLogs returns the most recent log lines of a trader matching the filter, oldest first
+func QueryTraderLogs(traderID string, filter LogFilter) ([]LogLine, error) {
+	if sinks == nil {
+		return []LogLine{}, nil
+	}
+	return sinks.query(traderID, filter)
+}
+
+// SubscribeTraderLogs streams new log lines of a trader; call cancel to unsubscribe.
+// Lines are dropped for slow subscribers rather than blocking the logger.
+func SubscribeTraderLogs(traderID string) (<-chan LogLine, func()) {
+	if sinks == nil {
+		ch := make(chan LogLine)
+		return ch, func() {}
+	}
+	return sinks.subscribe(traderID)
+}
+
+// traderSinks is a logrus hook that writes every line carrying trader_id to a
+// rotating JSON-lines file per trader and fans it out to live subscribers
+type traderSinks struct {
+	dir        string
+	maxSize    int64
+	maxBackups int
+
+	mu    sync.Mutex
+	files map[string]*rotatingFile
+	subs  map[string]map[chan LogLine]struct{}
+}
+
+func newTraderSinks(dir string, maxSize int64, maxBackups int) *traderSinks {
+	return &traderSinks{
+		dir:        dir,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		files:      make(map[string]*rotatingFile),
+		subs:       make(map[string]map[chan LogLine]struct{}),
+	}
+}
+
+// Levels implements logrus.Hook
+func (s *traderSinks) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (s *traderSinks) Fire(entry *logrus.Entry) error {
+	traderID, _ := entry.Data[FieldTraderID].(string)
+	if traderID == "" {
+		return nil
+	}
+	line := newLogLine(entry)
+	data, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subs[traderID] {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+
+	f := s.files[traderID]
+	if f == nil {
+		if err := os.MkdirAll(s.dir, 0755); err != nil {
+			return err
+		}
+		f = &rotatingFile{path: s.path(traderID), maxSize: s.maxSize, maxBackups: s.maxBackups}
+		s.files[traderID] = f
+	}
+	return f.write(data)
+}
+
+// Close closes all open trader log files
+func (s *traderSinks) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, f := range s.files {
+		f.close()
+		delete(s.files, id)
+	}
+}
+
+func (s *traderSinks) subscribe(traderID string) (<-chan LogLine, func()) {
+	ch := make(chan LogLine, 256)
+	s.mu.Lock()
+	if s.subs[traderID] == nil {
+		s.subs[traderID] = make(map[chan LogLine]struct{})
+	}
+	s.subs[traderID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs[traderID], ch)
+			if len(s.subs[traderID]) == 0 {
+				delete(s.subs, traderID)
+			}
+			s.mu.Unlock()
+		})
+	}
+}
+
+// query reads the current file and then older rotations until enough lines match.
+// Files are read without the sink lock; a rotation during a query at worst skips a few lines.
+func (s *traderSinks) query(traderID string, filter LogFilter) ([]LogLine, error) {
+	limit := filter.limit()
+	path := s.path(traderID)
+
+	var result []LogLine
+	for i := 0; i <= s.maxBackups && len(result) < limit; i++ {
+		name := path
+		if i > 0 {
+			name = fmt.Sprintf("%s.%d", path, i)
+		}
+		lines, err := readLogFile(name, &filter)
+		if os.IsNotExist(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(lines, result...)
+		if len(lines) > 0 && !filter.Since.IsZero() && lines[0].Time.Before(filter.Since) {
+			break
+		}
+	}
+	if len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	if result == nil {
+		result = []LogLine{}
+	}
+	return result, nil
+}
+
+func (s *traderSinks) path(traderID string) string {
+	return filepath.Join(s.dir, sanitizeFileName(traderID)+".log")
+}
+
+func readLogFile(name string, filter *LogFilter) ([]LogLine, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []LogLine
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var l LogLine
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			continue
+		}
+		if filter.Match(&l) {
+			lines = append(lines, l)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func newLogLine(entry *logrus.Entry) LogLine {
+	line := LogLine{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Caller:  callerOf(),
+	}
+	for k, v := range entry.Data {
+		switch k {
+		case FieldTraderID:
+		case FieldCycle:
+			line.Cycle, _ = strconv.ParseInt(fmt.Sprint(v), 10, 64)
+		case FieldSymbol:
+			line.Symbol = fmt.Sprint(v)
+		case FieldOrderID:
+			line.OrderID = fmt.Sprint(v)
+		default:
+			if line.Fields == nil {
+				line.Fields = make(map[string]interface{})
+			}
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+			line.Fields[k] = v
+		}
+	}
+	return line
+}
+
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// rotatingFile is an append-only file rotated to name.1 … name.N once it exceeds maxSize
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	f    *os.File
+	size int64
+}
+
+func (r *rotatingFile) write(p []byte) error {
+	if r.f == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return err
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+func (r *rotatingFile) rotate() error {
+	r.close()
+	for i := r.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.maxBackups > 0 {
+		if err := os.Rename(r.path, r.path+".1"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else if err := os.Remove(r.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return r.open()
+}
+
+func (r *rotatingFile) close() {
+	if r.f != nil {
+		r.f.Close()
+		r.f = nil
+	}
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestTraderSinksRotateAndQuery Test per-trader files, rotation and filtered queries
+func TestTraderSinksRotateAndQuery(t *testing.T) {
+	dir := t.TempDir()
+	s := newTraderSinks(dir, 2048, 2)
+	defer s.Close()
+
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	l.AddHook(s)
+
+	ch, cancel := s.subscribe("t1")
+	defer cancel()
+
+	for i := 1; i <= 40; i++ {
+		l.WithFields(logrus.Fields{FieldTraderID: "t1", FieldCycle: i, FieldSymbol: "BTCUSDT"}).Infof("cycle %d", i)
+	}
+	l.WithFields(logrus.Fields{FieldTraderID: "t1", FieldCycle: 41, FieldSymbol: "ETHUSDT"}).Warn("margin low")
+	l.WithField(FieldTraderID, "t2").Info("other trader")
+	l.Info("no trader")
+
+	if line := <-ch; line.Message != "cycle 1" || line.Cycle != 1 || line.Symbol != "BTCUSDT" {
+		t.Errorf("unexpected first streamed line: %+v", line)
+	}
+
+	for _, name := range []string{"t1.log", "t1.log.1", "t1.log.2", "t2.log"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "t1.log.3")); !os.IsNotExist(err) {
+		t.Error("rotation should keep at most 2 backups")
+	}
+
+	lines, err := s.query("t1", LogFilter{Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 5 || lines[4].Message != "margin low" || lines[3].Message != "cycle 40" {
+		t.Errorf("expected the 5 most recent lines oldest first, got %+v", lines)
+	}
+
+	lines, _ = s.query("t1", LogFilter{Level: "warn"})
+	if len(lines) != 1 || lines[0].Symbol != "ETHUSDT" {
+		t.Errorf("level filter: got %+v", lines)
+	}
+	lines, _ = s.query("t1", LogFilter{Cycle: 40, Symbol: "btcusdt"})
+	if len(lines) != 1 || lines[0].Message != "cycle 40" {
+		t.Errorf("cycle/symbol filter: got %+v", lines)
+	}
+	lines, _ = s.query("missing", LogFilter{})
+	if len(lines) != 0 {
+		t.Errorf("unknown trader should have no lines, got %d", len(lines))
+	}
+}
+
+// TestSanitizeFileName Test trader IDs can't escape the log directory
+func TestSanitizeFileName(t *testing.T) {
+	if got := sanitizeFileName("../etc/passwd"); got != "___etc_passwd" {
+		t.Errorf("got %s", got)
+	}
+	if got := sanitizeFileName("binance_abc-123"); got != "binance_abc-123" {
+		t.Errorf("got %s", got)
+	}
+}
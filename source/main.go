@@ -1,11 +1,13 @@
 package main
 
 import (
+	"flag"
 	"nofx/api"
 	"nofx/auth"
 	"nofx/backtest"
 	"nofx/config"
 	"nofx/crypto"
+	"nofx/debate"
 	"nofx/experience"
 	"nofx/logger"
 	"nofx/manager"
@@ -14,7 +16,9 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/joho/godotenv"
@@ -25,7 +29,7 @@ func main() {
 	_ = godotenv.Load()
 
 	// Initialize logger
-	logger.Init(nil)
+	logger.Init(logger.ConfigFromEnv())
 
 	logger.Info("╔════════════════════════════════════════════════════════════╗")
 	logger.Info("║           🚀 NOFX - AI-Powered Trading System              ║")
@@ -45,9 +49,22 @@ func main() {
 	crypto.SetGlobalCryptoService(cryptoService)
 	logger.Info("✅ Encryption service initialized successfully")
 
+	// Subcommands:
+	//   rotate-keys  re-encrypts stored secrets with the active data key and exits
+	//   migrate      shows, applies or reverts schema migrations and exits
+	//   backup       writes a backup archive of the whole database and exits
+	//   restore      loads a backup archive into the configured database and exits
+	var command string
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "rotate-keys", "migrate", "backup", "restore":
+			command = os.Args[1]
+		}
+	}
+
 	// Initialize database from configuration
 	// For backward compatibility: command line arg overrides config (SQLite only)
-	if len(os.Args) > 1 {
+	if len(os.Args) > 1 && command == "" {
 		cfg.DBPath = os.Args[1]
 	}
 	// Ensure data directory exists (for SQLite)
@@ -64,7 +81,7 @@ func main() {
 	if cfg.DBType == "postgres" {
 		dbType = store.DBTypePostgres
 	}
-	st, err := store.NewWithConfig(store.DBConfig{
+	dbConfig := store.DBConfig{
 		Type:     dbType,
 		Path:     cfg.DBPath,
 		Host:     cfg.DBHost,
@@ -73,11 +90,28 @@ func main() {
 		Password: cfg.DBPassword,
 		DBName:   cfg.DBName,
 		SSLMode:  cfg.DBSSLMode,
-	})
+	}
+	if command == "migrate" {
+		runMigrateCommand(dbConfig, os.Args[2:])
+		return
+	}
+	st, err := store.NewWithConfig(dbConfig)
 	if err != nil {
 		logger.Fatalf("❌ Failed to initialize database: %v", err)
 	}
 	defer st.Close()
+
+	switch command {
+	case "rotate-keys":
+		runKeyRotation(st, cryptoService)
+		return
+	case "backup":
+		runBackupCommand(st, cfg.BackupDir, os.Args[2:])
+		return
+	case "restore":
+		runRestoreCommand(st, cryptoService, os.Args[2:])
+		return
+	}
 	backtest.UseDatabaseWithType(st.DB(), st.DBType() == store.DBTypePostgres)
 
 	// Initialize installation ID for experience improvement (anonymous statistics)
@@ -139,8 +173,17 @@ func main() {
 		}
 	}
 
+	// Start backup scheduler (BACKUP_INTERVAL)
+	backupScheduler := store.NewBackupScheduler(st, cfg.BackupDir, cfg.BackupInterval, cfg.BackupKeep)
+	backupScheduler.Start()
+
+	// Start debate outcome scorer (grades votes against later prices for the scorecards)
+	debateScorer := debate.NewScorer(st.Debate(), time.Minute)
+	debateScorer.Start()
+
 	// Start API server
 	server := api.NewServer(traderManager, st, cryptoService, backtestManager, reflectionScheduler, cfg.APIServerPort)
+	server.SetBackupScheduler(backupScheduler)
 
 	// Register reflection API routes (need to add GetRouter method to Server)
 	reflectionHandlers := api.NewReflectionHandlers(reflectionScheduler, st)
@@ -175,11 +218,196 @@ func main() {
 	reflectionScheduler.Stop()
 	logger.Info("✅ Reflection scheduler stopped")
 
+	// Stop backup scheduler (waits for a running backup)
+	backupScheduler.Stop()
+
+	// Stop debate outcome scorer
+	debateScorer.Stop()
+
 	// Stop all traders
 	traderManager.StopAll()
 	logger.Info("✅ System shut down safely")
 }
 
+// runKeyRotation re-encrypts all stored secrets with the active data key
+// Rotation: make the new key active in the key provider and keep the old one as a previous key,
+// run `nofx rotate-keys` (or POST /api/encryption/rotate on a running server), then drop the old key
+func runKeyRotation(st *store.Store, cs *crypto.CryptoService) {
+	logger.Infof("🔐 Rotating stored secrets to data key %s (provider: %s, loaded keys: %v)",
+		cs.ActiveKeyID(), cs.KeyProviderName(), cs.KeyIDs())
+	result, err := st.RotateEncryptionKeys(cs, store.DefaultKeyRotationBatchSize)
+	if err != nil {
+		logger.Fatalf("❌ Key rotation failed: %v", err)
+	}
+	for _, e := range result.Errors {
+		logger.Warnf("  ⚠️ %s", e)
+	}
+	logger.Infof("✅ Key rotation finished: %d rows scanned, %d values re-encrypted, %d failed",
+		result.Scanned, result.Reencrypted, result.Failed)
+	if result.Failed > 0 {
+		logger.Warn("⚠️ Some values could not be decrypted with the loaded keys, keep the previous keys configured")
+	}
+}
+
+// runBackupCommand handles `nofx backup [-o file]`
+// Without -o the archive is written into BACKUP_DIR with a timestamped name
+func runBackupCommand(st *store.Store, backupDir string, args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	output := fs.String("o", "", "archive path (default: timestamped file in BACKUP_DIR)")
+	_ = fs.Parse(args)
+
+	var path string
+	var meta *store.BackupMetadata
+	var err error
+	if *output == "" {
+		path, meta, err = st.WriteBackupFile(backupDir)
+	} else {
+		path = *output
+		var f *os.File
+		if f, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600); err == nil {
+			meta, err = st.ExportArchive(f)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	if err != nil {
+		logger.Fatalf("❌ Backup failed: %v", err)
+	}
+	for _, t := range meta.Tables {
+		logger.Infof("  %-32s %d rows", t.Name, t.Rows)
+	}
+	logger.Infof("✅ Backup written to %s (%s, schema version %d, data keys %v)", path, meta.SourceDB, meta.SchemaVersion, meta.DataKeyIDs)
+}
+
+// runRestoreCommand handles `nofx restore <archive> [-replace] [-dry-run]`
+// Stop the server first. To move from SQLite to PostgreSQL, run `nofx backup` with DB_TYPE=sqlite,
+// then `nofx restore` with the PostgreSQL settings and the same data encryption keys
+func runRestoreCommand(st *store.Store, cs *crypto.CryptoService, args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	replace := fs.Bool("replace", false, "delete existing data before restoring")
+	dryRun := fs.Bool("dry-run", false, "restore inside a transaction and roll it back")
+	var path string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		path, args = args[0], args[1:]
+	}
+	_ = fs.Parse(args)
+	if path == "" && fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		logger.Fatalf("❌ Usage: nofx restore <archive> [-replace] [-dry-run]")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		logger.Fatalf("❌ Failed to open backup: %v", err)
+	}
+	defer f.Close()
+
+	meta, err := st.ImportArchive(f, store.RestoreOptions{Replace: *replace, DryRun: *dryRun})
+	if err != nil {
+		logger.Fatalf("❌ Restore failed: %v", err)
+	}
+	for _, t := range meta.Tables {
+		logger.Infof("  %-32s %d rows", t.Name, t.Rows)
+	}
+
+	loaded := make(map[string]bool)
+	for _, kid := range cs.KeyIDs() {
+		loaded[kid] = true
+	}
+	for _, kid := range meta.DataKeyIDs {
+		if !loaded[kid] {
+			logger.Warnf("⚠️ Secrets in this backup were encrypted with data key %s, which is not loaded; configure it as a previous key to read them", kid)
+		}
+	}
+	if *dryRun {
+		logger.Infof("✅ Dry run: backup from %s (%s) can be restored, nothing was written", meta.CreatedAt.Format(time.RFC3339), meta.SourceDB)
+		return
+	}
+	logger.Infof("✅ Restored backup from %s (%s, schema version %d) into %s", meta.CreatedAt.Format(time.RFC3339), meta.SourceDB, meta.SchemaVersion, st.DBType())
+}
+
+// runMigrateCommand handles `nofx migrate [status|up|down] [-to N] [-dry-run]`
+// up applies pending migrations (up to -to if given), down reverts the newest applied migration
+// (or everything above -to). The server applies pending migrations on startup as well
+func runMigrateCommand(dbConfig store.DBConfig, args []string) {
+	action := "status"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	target := fs.Int("to", -1, "target schema version")
+	dryRun := fs.Bool("dry-run", false, "only print the migrations that would run")
+	_ = fs.Parse(args)
+
+	st, err := store.OpenWithConfig(dbConfig)
+	if err != nil {
+		logger.Fatalf("❌ Failed to open database: %v", err)
+	}
+	defer st.Close()
+
+	var steps []store.MigrationStep
+	switch action {
+	case "status":
+		states, err := st.MigrationStatus()
+		if err != nil {
+			logger.Fatalf("❌ Failed to read migration status: %v", err)
+		}
+		for _, m := range states {
+			status := "pending"
+			if m.Applied {
+				status = "applied " + time.UnixMilli(m.AppliedAt).UTC().Format(time.RFC3339)
+			}
+			reversible := ""
+			if !m.Reversible {
+				reversible = " (irreversible)"
+			}
+			logger.Infof("  %4d  %-40s %s%s", m.Version, m.Name, status, reversible)
+		}
+		return
+	case "up":
+		opts := store.MigrateOptions{DryRun: *dryRun}
+		if *target > 0 {
+			opts.Target = *target
+		}
+		steps, err = st.Migrate(opts)
+	case "down":
+		opts := store.MigrateOptions{Target: *target, DryRun: *dryRun}
+		if *target < 0 {
+			version, verr := st.SchemaVersion()
+			if verr != nil {
+				logger.Fatalf("❌ Failed to read schema version: %v", verr)
+			}
+			opts.Target = version - 1
+			if opts.Target < 0 {
+				opts.Target = 0
+			}
+		}
+		steps, err = st.Rollback(opts)
+	default:
+		logger.Fatalf("❌ Unknown migrate action %q (valid: status, up, down)", action)
+	}
+
+	verb := "Ran"
+	if *dryRun {
+		verb = "Would run"
+	}
+	for _, step := range steps {
+		logger.Infof("  %s %s %d %s", verb, step.Direction, step.Version, step.Name)
+	}
+	if err != nil {
+		logger.Fatalf("❌ Migration failed: %v", err)
+	}
+	version, _ := st.SchemaVersion()
+	if *dryRun {
+		logger.Infof("🗄️ Dry run: %d migration(s) would run, schema version stays %d", len(steps), version)
+	} else {
+		logger.Infof("✅ %d migration(s) done, schema version %d (latest %d)", len(steps), version, store.LatestMigrationVersion())
+	}
+}
+
 // newSharedMCPClient creates a shared MCP AI client (for backtesting)
 func newSharedMCPClient() mcp.AIClient {
 	apiKey := os.Getenv("DEEPSEEK_API_KEY")
@@ -583,7 +583,14 @@ func (tm *TraderManager) LoadTradersFromStore(st *store.Store) error {
 		return fmt.Errorf("failed to get user list: %w", err)
 	}
 
-	logger.Infof("📋 Found %d users, loading all trader configurations...", len(userIDs))
+	// Workspaces own shared traders the same way users own personal ones
+	if workspaceIDs, err := st.Workspace().ListIDs(); err != nil {
+		logger.Warnf("⚠️ Failed to get workspace list: %v", err)
+	} else {
+		userIDs = append(userIDs, workspaceIDs...)
+	}
+
+	logger.Infof("📋 Found %d users/workspaces, loading all trader configurations...", len(userIDs))
 
 	var allTraders []*store.Trader
 	for _, userID := range userIDs {
@@ -709,6 +716,17 @@ func (tm *TraderManager) addTraderFromStore(traderCfg *store.Trader, aiModelCfg
 		return fmt.Errorf("trader %s has no strategy configured", traderCfg.Name)
 	}
 
+	// A trader's system prompt template may name a library template; the strategy's own selection wins
+	if strategyConfig.PromptTemplate == nil && traderCfg.SystemPromptTemplate != "" && traderCfg.SystemPromptTemplate != "default" {
+		ref := &store.PromptTemplateRef{ID: traderCfg.SystemPromptTemplate}
+		if err := st.PromptTemplate().Resolve(traderCfg.UserID, ref); err != nil {
+			logger.Warnf("⚠️ Trader %s: %v, using built-in prompt", traderCfg.Name, err)
+		} else {
+			strategyConfig.PromptTemplate = ref
+			logger.Infof("✓ Trader %s using prompt template %s (v%d)", traderCfg.Name, ref.Name, ref.Version)
+		}
+	}
+
 	// Build AutoTraderConfig (ai500APIURL/oiTopAPIURL obtained from strategy config, used in StrategyEngine)
 	traderConfig := trader.AutoTraderConfig{
 		ID:                    traderCfg.ID,
@@ -730,6 +748,7 @@ func (tm *TraderManager) addTraderFromStore(traderCfg *store.Trader, aiModelCfg
 		IsCrossMargin:         traderCfg.IsCrossMargin,
 		ShowInCompetition:     traderCfg.ShowInCompetition,
 		StrategyConfig:        strategyConfig,
+		DebatePanelID:         traderCfg.DebatePanelID,
 	}
 
 	logger.Infof("📊 Loading trader %s: ScanIntervalMinutes=%d (from DB), ScanInterval=%v",
@@ -777,6 +796,21 @@ func (tm *TraderManager) addTraderFromStore(traderCfg *store.Trader, aiModelCfg
 		traderConfig.CustomAPIKey = string(aiModelCfg.APIKey)
 	}
 
+	// Fallback AI models, tried in order when the primary fails
+	for _, modelID := range traderCfg.FallbackModelIDs() {
+		fallback, err := st.AIModel().Get(traderCfg.UserID, modelID)
+		if err != nil || !fallback.Enabled {
+			logger.Warnf("⚠️ Trader %s: fallback AI model %s is missing or disabled, skipped", traderCfg.Name, modelID)
+			continue
+		}
+		traderConfig.FallbackAIModels = append(traderConfig.FallbackAIModels, trader.FallbackAIModel{
+			Provider: fallback.Provider,
+			APIKey:   string(fallback.APIKey),
+			BaseURL:  fallback.CustomAPIURL,
+			Model:    fallback.CustomModelName,
+		})
+	}
+
 	// Create trader instance
 	at, err := trader.NewAutoTrader(traderConfig, st, traderCfg.UserID)
 	if err != nil {
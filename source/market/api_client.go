@@ -158,3 +158,89 @@ func (c *APIClient) GetCurrentPrice(symbol string) (float64, error) {
 
 	return price, nil
 }
+
+// GetDepth fetches the order book for a symbol (limit: 5, 10, 20, 50, 100, 500, 1000)
+func (c *APIClient) GetDepth(symbol string, limit int) (*DepthResponse, error) {
+	url := fmt.Sprintf("%s/fapi/v1/depth", baseURL)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("symbol", symbol)
+	q.Add("limit", strconv.Itoa(limit))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("binance depth API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var depth DepthResponse
+	if err := json.Unmarshal(body, &depth); err != nil {
+		return nil, err
+	}
+
+	return &depth, nil
+}
+
+// GetFundingRateHistory fetches the latest settled funding rates of a symbol, oldest first (limit: 1-1000)
+func (c *APIClient) GetFundingRateHistory(symbol string, limit int) ([]FundingRate, error) {
+	url := fmt.Sprintf("%s/fapi/v1/fundingRate", baseURL)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Add("symbol", symbol)
+	q.Add("limit", strconv.Itoa(limit))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("binance funding rate API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var raw []FundingRateResponse
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+
+	rates := make([]FundingRate, 0, len(raw))
+	for _, r := range raw {
+		rate, err := strconv.ParseFloat(r.FundingRate, 64)
+		if err != nil {
+			continue
+		}
+		markPrice, _ := strconv.ParseFloat(r.MarkPrice, 64)
+		rates = append(rates, FundingRate{
+			Symbol:      r.Symbol,
+			FundingRate: rate,
+			FundingTime: r.FundingTime,
+			MarkPrice:   markPrice,
+		})
+	}
+	return rates, nil
+}
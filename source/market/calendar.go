@@ -0,0 +1,443 @@
+package market
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // exchange timezones must resolve even without system tzdata
+)
+
+// ============================================================================
+// Trading Calendar (exchange sessions, holidays, weekend gaps)
+// ============================================================================
+
+// Calendar names
+const (
+	CalendarCrypto   = "crypto_24x7"
+	CalendarUSEquity = "us_equity"
+	CalendarForex    = "forex"
+	CalendarMetals   = "metals"
+)
+
+// Market closed reasons
+const (
+	ClosedWeekend      = "weekend"
+	ClosedHoliday      = "holiday"
+	ClosedOutsideHours = "outside_hours"
+)
+
+const (
+	calendarLookAhead   = 10 // days searched for the next open/close
+	calendarDateLayout  = "2006-01-02"
+	minutesPerDay       = 24 * 60
+	sessionOverlapSlack = time.Minute
+)
+
+// clockRange trading window within a local day, in minutes from midnight (Close may be 1440)
+type clockRange struct {
+	Open  int
+	Close int
+}
+
+// SessionWindow a continuous trading window
+type SessionWindow struct {
+	Open  time.Time `json:"open"`
+	Close time.Time `json:"close"`
+}
+
+// TradingCalendar weekly session template plus holidays and early closes, in the exchange timezone
+type TradingCalendar struct {
+	Name       string
+	Location   *time.Location
+	AlwaysOpen bool
+	Weekly     map[time.Weekday][]clockRange
+	Holidays   func(year int) *HolidaySchedule // Computed by rule, nil when the market has no holidays
+
+	schedules sync.Map // year -> *HolidaySchedule
+}
+
+// HolidaySchedule full-day closures and early closes of one year
+type HolidaySchedule struct {
+	Closed      map[string]bool // "2006-01-02" in exchange timezone
+	EarlyCloses map[string]int  // "2006-01-02" -> close minute of day
+}
+
+// MarketStatus trading session status of a symbol at a point in time
+type MarketStatus struct {
+	Symbol    string    `json:"symbol"`
+	Calendar  string    `json:"calendar"`
+	Open      bool      `json:"open"`
+	Reason    string    `json:"reason,omitempty"` // set when closed: weekend, holiday, outside_hours
+	NextOpen  time.Time `json:"next_open,omitempty"`
+	NextClose time.Time `json:"next_close,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// AlwaysOpen reports whether the symbol trades around the clock
+func (s *MarketStatus) AlwaysOpen() bool {
+	return s.Calendar == CalendarCrypto
+}
+
+// UntilNextOpen time remaining until the market opens (0 when open or unknown)
+func (s *MarketStatus) UntilNextOpen() time.Duration {
+	if s.Open || s.NextOpen.IsZero() {
+		return 0
+	}
+	return s.NextOpen.Sub(s.At)
+}
+
+// UntilNextClose time remaining until the current session closes (0 when closed or unknown)
+func (s *MarketStatus) UntilNextClose() time.Duration {
+	if !s.Open || s.NextClose.IsZero() {
+		return 0
+	}
+	return s.NextClose.Sub(s.At)
+}
+
+var newYork = loadLocation("America/New_York")
+
+func loadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// usEquitySpecialClosures unscheduled NYSE closures (national days of mourning etc.), on top of the rules
+var usEquitySpecialClosures = map[string]bool{
+	"2025-01-09": true,
+}
+
+// usEquityHolidays NYSE holidays and 13:00 early closes of a year, following the exchange's holiday rules
+func usEquityHolidays(year int) *HolidaySchedule {
+	h := &HolidaySchedule{Closed: make(map[string]bool), EarlyCloses: make(map[string]int)}
+	closed := func(d time.Time) { h.Closed[d.Format(calendarDateLayout)] = true }
+
+	// New Year's Day moves to Monday when on Sunday, but is not observed on the Friday before when on Saturday
+	if newYear := calendarDate(year, time.January, 1); newYear.Weekday() == time.Sunday {
+		closed(newYear.AddDate(0, 0, 1))
+	} else if newYear.Weekday() != time.Saturday {
+		closed(newYear)
+	}
+	closed(nthWeekday(year, time.January, time.Monday, 3))  // Martin Luther King Jr. Day
+	closed(nthWeekday(year, time.February, time.Monday, 3)) // Washington's Birthday
+	closed(easterSunday(year).AddDate(0, 0, -2))            // Good Friday
+	closed(lastWeekday(year, time.May, time.Monday))        // Memorial Day
+	if year >= 2022 {
+		closed(observed(calendarDate(year, time.June, 19))) // Juneteenth
+	}
+	closed(observed(calendarDate(year, time.July, 4)))       // Independence Day
+	closed(nthWeekday(year, time.September, time.Monday, 1)) // Labor Day
+	thanksgiving := nthWeekday(year, time.November, time.Thursday, 4)
+	closed(thanksgiving)
+	closed(observed(calendarDate(year, time.December, 25))) // Christmas
+	for day := range usEquitySpecialClosures {
+		if strings.HasPrefix(day, strconv.Itoa(year)+"-") {
+			h.Closed[day] = true
+		}
+	}
+
+	// Early closes: the day before Independence Day, the day after Thanksgiving and Christmas Eve,
+	// when those are regular weekdays
+	earlyClose := func(d time.Time) {
+		key := d.Format(calendarDateLayout)
+		if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday && !h.Closed[key] {
+			h.EarlyCloses[key] = 13 * 60
+		}
+	}
+	earlyClose(calendarDate(year, time.July, 3))
+	earlyClose(thanksgiving.AddDate(0, 0, 1))
+	earlyClose(calendarDate(year, time.December, 24))
+	return h
+}
+
+// globalFXHolidays forex and metals close for Christmas and New Year's Day
+func globalFXHolidays(year int) *HolidaySchedule {
+	return &HolidaySchedule{Closed: map[string]bool{
+		calendarDate(year, time.January, 1).Format(calendarDateLayout):   true,
+		calendarDate(year, time.December, 25).Format(calendarDateLayout): true,
+	}}
+}
+
+func calendarDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday the n-th given weekday of a month
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	first := calendarDate(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+// lastWeekday the last given weekday of a month
+func lastWeekday(year int, month time.Month, weekday time.Weekday) time.Time {
+	last := calendarDate(year, month+1, 1).AddDate(0, 0, -1)
+	offset := (int(last.Weekday()) - int(weekday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// observed a fixed-date holiday moved to Friday when on Saturday and to Monday when on Sunday
+func observed(d time.Time) time.Time {
+	switch d.Weekday() {
+	case time.Saturday:
+		return d.AddDate(0, 0, -1)
+	case time.Sunday:
+		return d.AddDate(0, 0, 1)
+	}
+	return d
+}
+
+// easterSunday Gregorian Easter date (anonymous Gregorian algorithm)
+func easterSunday(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return calendarDate(year, time.Month(month), day)
+}
+
+var (
+	cryptoCalendar = &TradingCalendar{Name: CalendarCrypto, Location: time.UTC, AlwaysOpen: true}
+
+	// US equities regular session 09:30-16:00 ET
+	usEquityCalendar = &TradingCalendar{
+		Name:     CalendarUSEquity,
+		Location: newYork,
+		Weekly: map[time.Weekday][]clockRange{
+			time.Monday:    {{9*60 + 30, 16 * 60}},
+			time.Tuesday:   {{9*60 + 30, 16 * 60}},
+			time.Wednesday: {{9*60 + 30, 16 * 60}},
+			time.Thursday:  {{9*60 + 30, 16 * 60}},
+			time.Friday:    {{9*60 + 30, 16 * 60}},
+		},
+		Holidays: usEquityHolidays,
+	}
+
+	// Forex trades Sunday 17:00 ET to Friday 17:00 ET
+	forexCalendar = &TradingCalendar{
+		Name:     CalendarForex,
+		Location: newYork,
+		Weekly: map[time.Weekday][]clockRange{
+			time.Sunday:    {{17 * 60, minutesPerDay}},
+			time.Monday:    {{0, minutesPerDay}},
+			time.Tuesday:   {{0, minutesPerDay}},
+			time.Wednesday: {{0, minutesPerDay}},
+			time.Thursday:  {{0, minutesPerDay}},
+			time.Friday:    {{0, 17 * 60}},
+		},
+		Holidays: globalFXHolidays,
+	}
+
+	// Gold/silver (CME Globex) trade Sunday 18:00 ET to Friday 17:00 ET with a daily 17:00-18:00 break
+	metalsCalendar = &TradingCalendar{
+		Name:     CalendarMetals,
+		Location: newYork,
+		Weekly: map[time.Weekday][]clockRange{
+			time.Sunday:    {{18 * 60, minutesPerDay}},
+			time.Monday:    {{0, 17 * 60}, {18 * 60, minutesPerDay}},
+			time.Tuesday:   {{0, 17 * 60}, {18 * 60, minutesPerDay}},
+			time.Wednesday: {{0, 17 * 60}, {18 * 60, minutesPerDay}},
+			time.Thursday:  {{0, 17 * 60}, {18 * 60, minutesPerDay}},
+			time.Friday:    {{0, 17 * 60}},
+		},
+		Holidays: globalFXHolidays,
+	}
+)
+
+// xyzDexCalendars calendars of non-equity xyz dex assets (everything else follows US equity hours)
+var xyzDexCalendars = map[string]*TradingCalendar{
+	"EUR":    forexCalendar,
+	"JPY":    forexCalendar,
+	"GOLD":   metalsCalendar,
+	"SILVER": metalsCalendar,
+}
+
+// CalendarFor returns the trading calendar of a symbol
+func CalendarFor(symbol string) *TradingCalendar {
+	symbol = Normalize(symbol)
+	switch ClassifySymbol(symbol) {
+	case AssetStock:
+		return usEquityCalendar
+	case AssetForex:
+		return forexCalendar
+	case AssetXyzDex:
+		if cal, ok := xyzDexCalendars[strings.TrimPrefix(symbol, "xyz:")]; ok {
+			return cal
+		}
+		return usEquityCalendar
+	default:
+		return cryptoCalendar
+	}
+}
+
+// GetMarketStatus returns whether the symbol's underlying market is open at t and its next open/close
+func GetMarketStatus(symbol string, t time.Time) *MarketStatus {
+	cal := CalendarFor(symbol)
+	status := cal.Status(t)
+	status.Symbol = Normalize(symbol)
+	return status
+}
+
+// Status returns the session status at t
+func (c *TradingCalendar) Status(t time.Time) *MarketStatus {
+	status := &MarketStatus{Calendar: c.Name, At: t}
+	if c.AlwaysOpen {
+		status.Open = true
+		return status
+	}
+
+	windows := c.Windows(t.AddDate(0, 0, -1), t.AddDate(0, 0, calendarLookAhead))
+	for _, w := range windows {
+		if !t.Before(w.Open) && t.Before(w.Close) {
+			status.Open = true
+			status.NextClose = w.Close
+			continue
+		}
+		if w.Open.After(t) && status.NextOpen.IsZero() {
+			status.NextOpen = w.Open
+		}
+	}
+
+	if !status.Open {
+		local := t.In(c.Location)
+		switch {
+		case c.isHoliday(local):
+			status.Reason = ClosedHoliday
+		case len(c.Weekly[local.Weekday()]) == 0 || c.isWeekendGap(t, status.NextOpen):
+			status.Reason = ClosedWeekend
+		default:
+			status.Reason = ClosedOutsideHours
+		}
+	}
+	return status
+}
+
+// isWeekendGap reports whether the closed period around t spans a weekend day
+func (c *TradingCalendar) isWeekendGap(t, nextOpen time.Time) bool {
+	if nextOpen.IsZero() {
+		return false
+	}
+	for d := t.In(c.Location); d.Before(nextOpen); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			return true
+		}
+	}
+	return false
+}
+
+// schedule the holidays of a year, computed once per calendar
+func (c *TradingCalendar) schedule(year int) *HolidaySchedule {
+	if c.Holidays == nil {
+		return nil
+	}
+	if h, ok := c.schedules.Load(year); ok {
+		return h.(*HolidaySchedule)
+	}
+	h, _ := c.schedules.LoadOrStore(year, c.Holidays(year))
+	return h.(*HolidaySchedule)
+}
+
+// isHoliday reports whether the local day is a full-day closure
+func (c *TradingCalendar) isHoliday(local time.Time) bool {
+	h := c.schedule(local.Year())
+	return h != nil && h.Closed[local.Format(calendarDateLayout)]
+}
+
+// earlyClose close minute of the local day when the session ends early
+func (c *TradingCalendar) earlyClose(local time.Time) (int, bool) {
+	h := c.schedule(local.Year())
+	if h == nil {
+		return 0, false
+	}
+	minute, ok := h.EarlyCloses[local.Format(calendarDateLayout)]
+	return minute, ok
+}
+
+// IsOpen reports whether the market is open at t
+func (c *TradingCalendar) IsOpen(t time.Time) bool {
+	return c.Status(t).Open
+}
+
+// Windows returns merged trading windows overlapping [from, to], sorted by open time
+func (c *TradingCalendar) Windows(from, to time.Time) []SessionWindow {
+	if c.AlwaysOpen {
+		return []SessionWindow{{Open: from, Close: to}}
+	}
+
+	var windows []SessionWindow
+	start := from.In(c.Location)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, c.Location)
+	for ; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if c.isHoliday(day) {
+			continue
+		}
+		ranges := c.Weekly[day.Weekday()]
+		for i, r := range ranges {
+			closeMin := r.Close
+			if early, ok := c.earlyClose(day); ok && i == len(ranges)-1 && early < closeMin {
+				closeMin = early
+			}
+			w := SessionWindow{
+				Open:  time.Date(day.Year(), day.Month(), day.Day(), 0, r.Open, 0, 0, c.Location),
+				Close: time.Date(day.Year(), day.Month(), day.Day(), 0, closeMin, 0, 0, c.Location),
+			}
+			if w.Close.Before(from) || w.Open.After(to) {
+				continue
+			}
+			// Merge windows continuing across midnight
+			if n := len(windows); n > 0 && !w.Open.After(windows[n-1].Close) {
+				if w.Close.After(windows[n-1].Close) {
+					windows[n-1].Close = w.Close
+				}
+				continue
+			}
+			windows = append(windows, w)
+		}
+	}
+	return windows
+}
+
+// FilterSessionKlines drops intraday klines that lie entirely outside trading sessions, so indicators
+// are computed over traded bars only and frozen off-hours prices don't flatten them.
+// Daily and longer klines, 24/7 markets, and series that would end up empty are returned unchanged.
+func FilterSessionKlines(symbol, interval string, klines []Kline) []Kline {
+	cal := CalendarFor(symbol)
+	minutes := parseTimeframeToMinutes(interval)
+	if cal.AlwaysOpen || len(klines) == 0 || minutes <= 0 || minutes >= minutesPerDay {
+		return klines
+	}
+
+	first := time.UnixMilli(klines[0].OpenTime)
+	last := time.UnixMilli(klines[len(klines)-1].CloseTime)
+	windows := cal.Windows(first.AddDate(0, 0, -1), last.AddDate(0, 0, 1))
+	if len(windows) == 0 {
+		return klines
+	}
+
+	filtered := make([]Kline, 0, len(klines))
+	for _, k := range klines {
+		open := time.UnixMilli(k.OpenTime)
+		closeTime := time.UnixMilli(k.CloseTime).Add(sessionOverlapSlack)
+		// First window closing after the bar opens
+		idx := sort.Search(len(windows), func(i int) bool { return windows[i].Close.After(open) })
+		if idx < len(windows) && windows[idx].Open.Before(closeTime) {
+			filtered = append(filtered, k)
+		}
+	}
+
+	if len(filtered) == 0 {
+		return klines
+	}
+	return filtered
+}
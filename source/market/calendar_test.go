@@ -0,0 +1,141 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+func nyTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	ts, err := time.ParseInLocation("2006-01-02 15:04", value, newYork)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts
+}
+
+// TestMarketStatus tests session status for the built-in calendars
+func TestMarketStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		symbol    string
+		at        string
+		open      bool
+		reason    string
+		nextEvent string // next close when open, next open when closed
+	}{
+		{"crypto always open", "BTCUSDT", "2026-04-04 12:00", true, "", ""},
+		{"stock regular session", "xyz:TSLA", "2026-03-10 10:00", true, "", "2026-03-10 16:00"},
+		{"stock pre-market", "stock:AAPL", "2026-03-10 08:00", false, ClosedOutsideHours, "2026-03-10 09:30"},
+		{"stock weekend", "TSLA", "2026-03-14 12:00", false, ClosedWeekend, "2026-03-16 09:30"},
+		{"stock friday evening", "TSLA", "2026-03-13 18:00", false, ClosedWeekend, "2026-03-16 09:30"},
+		{"stock holiday", "TSLA", "2026-04-03 11:00", false, ClosedHoliday, "2026-04-06 09:30"},
+		{"stock early close", "TSLA", "2026-11-27 12:00", true, "", "2026-11-27 13:00"},
+		{"forex midweek", "fx:EURUSD", "2026-03-11 03:00", true, "", "2026-03-13 17:00"},
+		{"forex weekend", "xyz:EUR", "2026-03-14 12:00", false, ClosedWeekend, "2026-03-15 17:00"},
+		{"metals daily break", "xyz:GOLD", "2026-03-11 17:30", false, ClosedOutsideHours, "2026-03-11 18:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := GetMarketStatus(tt.symbol, nyTime(t, tt.at))
+			if status.Open != tt.open {
+				t.Fatalf("open = %v, want %v (%+v)", status.Open, tt.open, status)
+			}
+			if status.Reason != tt.reason {
+				t.Errorf("reason = %q, want %q", status.Reason, tt.reason)
+			}
+			if tt.nextEvent == "" {
+				return
+			}
+			want := nyTime(t, tt.nextEvent)
+			got := status.NextOpen
+			if status.Open {
+				got = status.NextClose
+			}
+			if !got.Equal(want) {
+				t.Errorf("next event = %s, want %s", got.In(newYork), want)
+			}
+		})
+	}
+}
+
+// TestFilterSessionKlines tests dropping off-session bars across the weekend gap
+func TestFilterSessionKlines(t *testing.T) {
+	// Hourly bars from Friday 14:00 ET to Monday 11:00 ET
+	start := nyTime(t, "2026-03-13 14:00")
+	var klines []Kline
+	for ts := start; ts.Before(nyTime(t, "2026-03-16 11:00")); ts = ts.Add(time.Hour) {
+		klines = append(klines, Kline{OpenTime: ts.UnixMilli(), CloseTime: ts.Add(time.Hour).UnixMilli() - 1, Close: 100})
+	}
+
+	filtered := FilterSessionKlines("xyz:TSLA", "1h", klines)
+	// Friday 14:00, 15:00 and Monday 09:00 (overlaps 09:30 open), 10:00
+	if len(filtered) != 4 {
+		t.Fatalf("expected 4 in-session bars, got %d", len(filtered))
+	}
+	if got := time.UnixMilli(filtered[2].OpenTime).In(newYork).Format("Mon 15:04"); got != "Mon 09:00" {
+		t.Errorf("third bar = %s, want Mon 09:00", got)
+	}
+
+	if got := FilterSessionKlines("BTCUSDT", "1h", klines); len(got) != len(klines) {
+		t.Error("crypto klines should not be filtered")
+	}
+	if got := FilterSessionKlines("xyz:TSLA", "1d", klines); len(got) != len(klines) {
+		t.Error("daily klines should not be filtered")
+	}
+}
+
+// TestUSEquityHolidayRules tests that the holiday rules reproduce the published NYSE calendars
+func TestUSEquityHolidayRules(t *testing.T) {
+	published := map[int]struct {
+		closed      []string
+		earlyCloses []string
+	}{
+		2025: {
+			closed: []string{"2025-01-01", "2025-01-09", "2025-01-20", "2025-02-17", "2025-04-18", "2025-05-26",
+				"2025-06-19", "2025-07-04", "2025-09-01", "2025-11-27", "2025-12-25"},
+			earlyCloses: []string{"2025-07-03", "2025-11-28", "2025-12-24"},
+		},
+		2026: {
+			closed: []string{"2026-01-01", "2026-01-19", "2026-02-16", "2026-04-03", "2026-05-25", "2026-06-19",
+				"2026-07-03", "2026-09-07", "2026-11-26", "2026-12-25"},
+			earlyCloses: []string{"2026-11-27", "2026-12-24"},
+		},
+		2027: {
+			closed: []string{"2027-01-01", "2027-01-18", "2027-02-15", "2027-03-26", "2027-05-31", "2027-06-18",
+				"2027-07-05", "2027-09-06", "2027-11-25", "2027-12-24"},
+			earlyCloses: []string{"2027-11-26"},
+		},
+		2028: {
+			closed: []string{"2028-01-17", "2028-02-21", "2028-04-14", "2028-05-29", "2028-06-19", "2028-07-04",
+				"2028-09-04", "2028-11-23", "2028-12-25"},
+			earlyCloses: []string{"2028-07-03", "2028-11-24"},
+		},
+	}
+
+	for year, want := range published {
+		h := usEquityHolidays(year)
+		if len(h.Closed) != len(want.closed) {
+			t.Errorf("%d: got %d closures %v, want %v", year, len(h.Closed), h.Closed, want.closed)
+		}
+		for _, day := range want.closed {
+			if !h.Closed[day] {
+				t.Errorf("%d: %s should be a holiday", year, day)
+			}
+		}
+		if len(h.EarlyCloses) != len(want.earlyCloses) {
+			t.Errorf("%d: got early closes %v, want %v", year, h.EarlyCloses, want.earlyCloses)
+		}
+		for _, day := range want.earlyCloses {
+			if h.EarlyCloses[day] != 13*60 {
+				t.Errorf("%d: %s should close at 13:00", year, day)
+			}
+		}
+	}
+
+	// Beyond any hard-coded table: Thanksgiving 2031 is closed
+	if status := GetMarketStatus("TSLA", nyTime(t, "2031-11-27 11:00")); status.Open || status.Reason != ClosedHoliday {
+		t.Errorf("Thanksgiving 2031 should be a holiday, got open=%v reason=%s", status.Open, status.Reason)
+	}
+}
@@ -112,26 +112,7 @@ func getKlinesFromHyperliquid(symbol, interval string, limit int) ([]Kline, erro
 	}
 
 	// Convert to market.Kline format
-	klines := make([]Kline, len(candles))
-	for i, c := range candles {
-		open, _ := strconv.ParseFloat(c.Open, 64)
-		high, _ := strconv.ParseFloat(c.High, 64)
-		low, _ := strconv.ParseFloat(c.Low, 64)
-		closePrice, _ := strconv.ParseFloat(c.Close, 64)
-		volume, _ := strconv.ParseFloat(c.Volume, 64)
-
-		klines[i] = Kline{
-			OpenTime:  c.OpenTime,
-			Open:      open,
-			High:      high,
-			Low:       low,
-			Close:     closePrice,
-			Volume:    volume,
-			CloseTime: c.CloseTime,
-		}
-	}
-
-	return klines, nil
+	return convertHyperliquidCandles(candles), nil
 }
 
 // Get retrieves market data for the specified token
@@ -140,43 +121,34 @@ func Get(symbol string) (*Data, error) {
 	var err error
 	// Normalize symbol
 	symbol = Normalize(symbol)
+	class := ClassifySymbol(symbol)
 
-	// Check if this is an xyz dex asset (use Hyperliquid API)
-	isXyzAsset := IsXyzDexAsset(symbol)
-
-	// Get 3-minute K-line data (or 5-minute for xyz assets as 3m may not be available)
-	if isXyzAsset {
-		// Use Hyperliquid API for xyz dex assets (use 5m since 3m may not be available)
-		klines3m, err = getKlinesFromHyperliquid(symbol, "5m", 100)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to get 5-minute K-line from Hyperliquid: %v", err)
-		}
-	} else {
-		// Use CoinAnk for regular crypto assets
-		klines3m, err = getKlinesFromCoinAnk(symbol, "3m", 100)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to get 3-minute K-line from CoinAnk: %v", err)
-		}
+	// Get 3-minute K-line data (5-minute for non-crypto assets as 3m may not be available)
+	// Providers are chosen by asset class with fallbacks, see KlineRegistry
+	shortInterval := "3m"
+	if class != AssetCrypto {
+		shortInterval = "5m"
+	}
+	klines3m, err = GetKlines(symbol, shortInterval, 100)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get %s K-line: %v", shortInterval, err)
 	}
+	// Drop bars outside the underlying market's sessions (stocks, forex, commodities)
+	klines3m = FilterSessionKlines(symbol, shortInterval, klines3m)
 
 	// Data staleness detection: Prevent DOGEUSDT-style price freeze issues
-	if isStaleData(klines3m, symbol) {
+	// Frozen prices are expected while the underlying market is closed
+	if GetMarketStatus(symbol, time.Now()).Open && isStaleData(klines3m, symbol) {
 		logger.Infof("⚠️  WARNING: %s detected stale data (consecutive price freeze), skipping symbol", symbol)
 		return nil, fmt.Errorf("%s data is stale, possible cache failure", symbol)
 	}
 
 	// Get 4-hour K-line data
-	if isXyzAsset {
-		klines4h, err = getKlinesFromHyperliquid(symbol, "4h", 100)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to get 4-hour K-line from Hyperliquid: %v", err)
-		}
-	} else {
-		klines4h, err = getKlinesFromCoinAnk(symbol, "4h", 100)
-		if err != nil {
-			return nil, fmt.Errorf("Failed to get 4-hour K-line from CoinAnk: %v", err)
-		}
+	klines4h, err = GetKlines(symbol, "4h", 100)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get 4-hour K-line: %v", err)
 	}
+	klines4h = FilterSessionKlines(symbol, "4h", klines4h)
 
 	// Check if data is empty
 	if len(klines3m) == 0 {
@@ -211,16 +183,17 @@ func Get(symbol string) (*Data, error) {
 		}
 	}
 
-	// Get OI data
-	oiData, err := getOpenInterestData(symbol)
-	if err != nil {
+	// Get OI data and funding rate (perpetuals only)
+	oiData := &OIData{Latest: 0, Average: 0}
+	fundingRate := 0.0
+	if class.HasPerpetualData() {
+		if oi, err := getOpenInterestData(symbol); err == nil {
+			oiData = oi
+		}
 		// OI failure doesn't affect overall result, use default values
-		oiData = &OIData{Latest: 0, Average: 0}
+		fundingRate, _ = getFundingRate(symbol)
 	}
 
-	// Get Funding Rate
-	fundingRate, _ := getFundingRate(symbol)
-
 	// Calculate intraday series data
 	intradayData := calculateIntradaySeries(klines3m)
 
@@ -274,29 +247,17 @@ func GetWithTimeframes(symbol string, timeframes []string, primaryTimeframe stri
 	timeframeData := make(map[string]*TimeframeSeriesData)
 	var primaryKlines []Kline
 
-	// Check if this is an xyz dex asset (use Hyperliquid API)
-	isXyzAsset := IsXyzDexAsset(symbol)
+	class := ClassifySymbol(symbol)
 
-	// Get K-line data for each timeframe
+	// Get K-line data for each timeframe (providers chosen by asset class, see KlineRegistry)
 	for _, tf := range timeframes {
-		var klines []Kline
-		var err error
-
-		if isXyzAsset {
-			// Use Hyperliquid API for xyz dex assets
-			klines, err = getKlinesFromHyperliquid(symbol, tf, 200)
-			if err != nil {
-				logger.Infof("⚠️ Failed to get %s %s K-line from Hyperliquid: %v", symbol, tf, err)
-				continue
-			}
-		} else {
-			// Use CoinAnk for regular crypto assets
-			klines, err = getKlinesFromCoinAnk(symbol, tf, 200)
-			if err != nil {
-				logger.Infof("⚠️ Failed to get %s %s K-line from CoinAnk: %v", symbol, tf, err)
-				continue
-			}
+		klines, err := GetKlines(symbol, tf, 200)
+		if err != nil {
+			logger.Infof("⚠️ Failed to get %s %s K-line: %v", symbol, tf, err)
+			continue
 		}
+		// Drop bars outside the underlying market's sessions (stocks, forex, commodities)
+		klines = FilterSessionKlines(symbol, tf, klines)
 
 		if len(klines) == 0 {
 			logger.Infof("⚠️ %s %s K-line data is empty", symbol, tf)
@@ -318,8 +279,8 @@ func GetWithTimeframes(symbol string, timeframes []string, primaryTimeframe stri
 		return nil, fmt.Errorf("Primary timeframe %s K-line data is empty", primaryTimeframe)
 	}
 
-	// Data staleness detection
-	if isStaleData(primaryKlines, symbol) {
+	// Data staleness detection (frozen prices are expected while the underlying market is closed)
+	if GetMarketStatus(symbol, time.Now()).Open && isStaleData(primaryKlines, symbol) {
 		logger.Infof("⚠️  WARNING: %s detected stale data (consecutive price freeze), skipping symbol", symbol)
 		return nil, fmt.Errorf("%s data is stale, possible cache failure", symbol)
 	}
@@ -334,15 +295,16 @@ func GetWithTimeframes(symbol string, timeframes []string, primaryTimeframe stri
 	priceChange1h := calculatePriceChangeByBars(primaryKlines, primaryTimeframe, 60) // 1 hour
 	priceChange4h := calculatePriceChangeByBars(primaryKlines, primaryTimeframe, 240) // 4 hours
 
-	// Get OI data
-	oiData, err := getOpenInterestData(symbol)
-	if err != nil {
-		oiData = &OIData{Latest: 0, Average: 0}
+	// Get OI data and funding rate (perpetuals only)
+	oiData := &OIData{Latest: 0, Average: 0}
+	fundingRate := 0.0
+	if class.HasPerpetualData() {
+		if oi, err := getOpenInterestData(symbol); err == nil {
+			oiData = oi
+		}
+		fundingRate, _ = getFundingRate(symbol)
 	}
 
-	// Get Funding Rate
-	fundingRate, _ := getFundingRate(symbol)
-
 	return &Data{
 		Symbol:        symbol,
 		CurrentPrice:  currentPrice,
@@ -1049,6 +1011,11 @@ func IsXyzDexAsset(symbol string) bool {
 // For crypto: ensures it's a USDT trading pair
 // For xyz dex assets (stocks, forex, commodities): uses xyz: prefix without USDT suffix
 func Normalize(symbol string) string {
+	// Stocks and forex keep their asset class prefix (stock:AAPL, fx:EUR/USD)
+	if normalized, ok := normalizePrefixedSymbol(symbol); ok {
+		return normalized
+	}
+
 	symbol = strings.ToUpper(symbol)
 
 	// Check if this is an xyz dex asset
@@ -0,0 +1,337 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"nofx/store"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Event Triggers (market conditions that launch an out-of-band decision cycle)
+// ============================================================================
+
+// Market event types
+const (
+	EventPriceMove          = "price_move"
+	EventATRSpike           = "atr_spike"
+	EventFundingFlip        = "funding_flip"
+	EventOISurge            = "oi_surge"
+	EventLiquidationCascade = "liquidation_cascade"
+	EventPositionPnL        = "position_pnl"
+)
+
+const (
+	defaultEventWindow     = 15 * time.Minute
+	defaultEventDebounce   = 5 * time.Minute
+	defaultEventMaxPerHour = 4
+	atrSpikeBars           = 14 // completed 1m bars averaged for the ATR spike baseline
+	atrSpikeBarDuration    = time.Minute
+	eventBudgetWindow      = time.Hour
+)
+
+type pricePoint struct {
+	At    time.Time
+	Value float64
+}
+
+// symbolEventState rolling per-symbol state of the event detector
+type symbolEventState struct {
+	prices []pricePoint
+
+	// current 1m bar and ranges of completed bars
+	barStart   time.Time
+	barHigh    float64
+	barLow     float64
+	ranges     []float64
+	spikeFired bool
+
+	funding    float64
+	hasFunding bool
+
+	openInterest []pricePoint
+	lastLiqUSD   float64
+}
+
+// EventDetector detects market events from streamed prices and polled derivatives data
+type EventDetector struct {
+	mu         sync.Mutex
+	cfg        store.EventTriggerConfig
+	states     map[string]*symbolEventState
+	pnlOutside map[string]bool // symbol_side -> PnL currently beyond threshold
+}
+
+// NewEventDetector creates an event detector for the given trigger configuration
+func NewEventDetector(cfg store.EventTriggerConfig) *EventDetector {
+	return &EventDetector{
+		cfg:        cfg,
+		states:     make(map[string]*symbolEventState),
+		pnlOutside: make(map[string]bool),
+	}
+}
+
+func (d *EventDetector) window() time.Duration {
+	if d.cfg.WindowMinutes > 0 {
+		return time.Duration(d.cfg.WindowMinutes) * time.Minute
+	}
+	return defaultEventWindow
+}
+
+func (d *EventDetector) state(symbol string) *symbolEventState {
+	st, ok := d.states[symbol]
+	if !ok {
+		st = &symbolEventState{}
+		d.states[symbol] = st
+	}
+	return st
+}
+
+// OnPrice feeds a streamed price and returns price move / ATR spike events
+func (d *EventDetector) OnPrice(symbol string, price float64, at time.Time) []store.MarketEvent {
+	if price <= 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.state(symbol)
+	var events []store.MarketEvent
+
+	// Price move: distance from the window's low/high
+	st.prices = append(trimPoints(st.prices, at.Add(-d.window())), pricePoint{At: at, Value: price})
+	if d.cfg.PriceMovePct > 0 {
+		low, high := price, price
+		for _, p := range st.prices {
+			low = math.Min(low, p.Value)
+			high = math.Max(high, p.Value)
+		}
+		up := (price - low) / low * 100
+		down := (price - high) / high * 100
+		move := up
+		if -down > up {
+			move = down
+		}
+		if math.Abs(move) >= d.cfg.PriceMovePct {
+			events = append(events, store.MarketEvent{
+				Type:       EventPriceMove,
+				Symbol:     symbol,
+				Message:    fmt.Sprintf("%s moved %+.2f%% within %v (now %.4f)", symbol, move, d.window(), price),
+				Value:      move,
+				Threshold:  d.cfg.PriceMovePct,
+				DetectedAt: at,
+			})
+			st.prices = []pricePoint{{At: at, Value: price}}
+		}
+	}
+
+	// ATR spike: current 1m range vs average range of completed 1m bars
+	barStart := at.Truncate(atrSpikeBarDuration)
+	if !barStart.Equal(st.barStart) {
+		if !st.barStart.IsZero() {
+			st.ranges = append(st.ranges, st.barHigh-st.barLow)
+			if len(st.ranges) > atrSpikeBars {
+				st.ranges = st.ranges[len(st.ranges)-atrSpikeBars:]
+			}
+		}
+		st.barStart, st.barHigh, st.barLow, st.spikeFired = barStart, price, price, false
+	}
+	st.barHigh = math.Max(st.barHigh, price)
+	st.barLow = math.Min(st.barLow, price)
+	if d.cfg.ATRSpikeMultiplier > 0 && !st.spikeFired && len(st.ranges) >= atrSpikeBars {
+		avg := 0.0
+		for _, r := range st.ranges {
+			avg += r
+		}
+		avg /= float64(len(st.ranges))
+		if avg > 0 {
+			ratio := (st.barHigh - st.barLow) / avg
+			if ratio >= d.cfg.ATRSpikeMultiplier {
+				st.spikeFired = true
+				events = append(events, store.MarketEvent{
+					Type:       EventATRSpike,
+					Symbol:     symbol,
+					Message:    fmt.Sprintf("%s 1m range %.4f is %.1fx the %d-bar average", symbol, st.barHigh-st.barLow, ratio, atrSpikeBars),
+					Value:      ratio,
+					Threshold:  d.cfg.ATRSpikeMultiplier,
+					DetectedAt: at,
+				})
+			}
+		}
+	}
+
+	return events
+}
+
+// OnDerivatives feeds polled funding rate, open interest and latest-bar liquidation value (0 = unknown)
+// and returns funding flip / OI surge / liquidation cascade events
+func (d *EventDetector) OnDerivatives(symbol string, fundingRate, openInterest, liquidationUSD float64, at time.Time) []store.MarketEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.state(symbol)
+	var events []store.MarketEvent
+
+	if fundingRate != 0 {
+		if d.cfg.FundingFlip && st.hasFunding && (st.funding > 0) != (fundingRate > 0) {
+			events = append(events, store.MarketEvent{
+				Type:       EventFundingFlip,
+				Symbol:     symbol,
+				Message:    fmt.Sprintf("%s funding rate flipped %+.4f%% → %+.4f%%", symbol, st.funding*100, fundingRate*100),
+				Value:      fundingRate,
+				DetectedAt: at,
+			})
+		}
+		st.funding, st.hasFunding = fundingRate, true
+	}
+
+	if openInterest > 0 {
+		st.openInterest = append(trimPoints(st.openInterest, at.Add(-d.window())), pricePoint{At: at, Value: openInterest})
+		if base := st.openInterest[0].Value; d.cfg.OISurgePct > 0 && base > 0 {
+			change := (openInterest - base) / base * 100
+			if math.Abs(change) >= d.cfg.OISurgePct {
+				events = append(events, store.MarketEvent{
+					Type:       EventOISurge,
+					Symbol:     symbol,
+					Message:    fmt.Sprintf("%s open interest changed %+.2f%% within %v", symbol, change, d.window()),
+					Value:      change,
+					Threshold:  d.cfg.OISurgePct,
+					DetectedAt: at,
+				})
+				st.openInterest = []pricePoint{{At: at, Value: openInterest}}
+			}
+		}
+	}
+
+	// The same liquidation bar is polled repeatedly, only a new value can fire again
+	if liquidationUSD > 0 && liquidationUSD != st.lastLiqUSD {
+		if d.cfg.LiquidationCascadeUSD > 0 && liquidationUSD >= d.cfg.LiquidationCascadeUSD {
+			events = append(events, store.MarketEvent{
+				Type:       EventLiquidationCascade,
+				Symbol:     symbol,
+				Message:    fmt.Sprintf("%s liquidated %.0f USD in the latest bar", symbol, liquidationUSD),
+				Value:      liquidationUSD,
+				Threshold:  d.cfg.LiquidationCascadeUSD,
+				DetectedAt: at,
+			})
+		}
+		st.lastLiqUSD = liquidationUSD
+	}
+
+	return events
+}
+
+// OnPositionPnL feeds the unrealized PnL (% of margin) of an open position and returns an event
+// when it crosses beyond ±PositionPnLPct
+func (d *EventDetector) OnPositionPnL(symbol, side string, pnlPct float64, at time.Time) *store.MarketEvent {
+	if d.cfg.PositionPnLPct <= 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := symbol + "_" + side
+	outside := math.Abs(pnlPct) >= d.cfg.PositionPnLPct
+	crossed := outside && !d.pnlOutside[key]
+	d.pnlOutside[key] = outside
+	if !crossed {
+		return nil
+	}
+	return &store.MarketEvent{
+		Type:       EventPositionPnL,
+		Symbol:     symbol,
+		Message:    fmt.Sprintf("%s %s position PnL %+.2f%% crossed ±%.1f%%", symbol, side, pnlPct, d.cfg.PositionPnLPct),
+		Value:      pnlPct,
+		Threshold:  d.cfg.PositionPnLPct,
+		DetectedAt: at,
+	}
+}
+
+// PrunePositions forgets PnL state of positions that are no longer open (keys are symbol_side)
+func (d *EventDetector) PrunePositions(open map[string]bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for key := range d.pnlOutside {
+		if !open[key] {
+			delete(d.pnlOutside, key)
+		}
+	}
+}
+
+// trimPoints drops points older than cutoff
+func trimPoints(points []pricePoint, cutoff time.Time) []pricePoint {
+	i := 0
+	for i < len(points) && points[i].At.Before(cutoff) {
+		i++
+	}
+	return points[i:]
+}
+
+// EventBudget debounce and hourly budget of event-triggered decision cycles
+type EventBudget struct {
+	mu         sync.Mutex
+	debounce   time.Duration
+	maxPerHour int
+	lastCycle  time.Time
+	fired      []time.Time
+}
+
+// NewEventBudget creates the cycle budget for the given trigger configuration
+func NewEventBudget(cfg store.EventTriggerConfig) *EventBudget {
+	b := &EventBudget{debounce: defaultEventDebounce, maxPerHour: defaultEventMaxPerHour}
+	if cfg.DebounceSeconds > 0 {
+		b.debounce = time.Duration(cfg.DebounceSeconds) * time.Second
+	}
+	if cfg.MaxCallsPerHour > 0 {
+		b.maxPerHour = cfg.MaxCallsPerHour
+	}
+	return b
+}
+
+// Allow reports whether an event-triggered cycle may run at t and reserves it, otherwise returns the reason
+func (b *EventBudget) Allow(t time.Time) (bool, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.lastCycle.IsZero() && t.Sub(b.lastCycle) < b.debounce {
+		return false, fmt.Sprintf("debounced, last cycle %v ago", t.Sub(b.lastCycle).Round(time.Second))
+	}
+
+	cutoff := t.Add(-eventBudgetWindow)
+	i := 0
+	for i < len(b.fired) && b.fired[i].Before(cutoff) {
+		i++
+	}
+	b.fired = b.fired[i:]
+	if len(b.fired) >= b.maxPerHour {
+		return false, fmt.Sprintf("hourly budget exhausted (%d/%d)", len(b.fired), b.maxPerHour)
+	}
+
+	b.fired = append(b.fired, t)
+	b.lastCycle = t
+	return true, ""
+}
+
+// MarkCycle records a scheduled cycle so events right after it are debounced without using the budget
+func (b *EventBudget) MarkCycle(t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastCycle = t
+}
+
+// GetPerpetualStats returns the funding rate and open interest of a USDT perpetual without fetching klines
+func GetPerpetualStats(symbol string) (fundingRate, openInterest float64, err error) {
+	symbol = Normalize(symbol)
+	if ClassifySymbol(symbol) != AssetCrypto {
+		return 0, 0, fmt.Errorf("no perpetual stats for %s", symbol)
+	}
+	fundingRate, err = getFundingRate(symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	oi, err := getOpenInterestData(symbol)
+	if err != nil {
+		return fundingRate, 0, err
+	}
+	return fundingRate, oi.Latest, nil
+}
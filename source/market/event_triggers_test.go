@@ -0,0 +1,125 @@
+package market
+
+import (
+	"nofx/store"
+	"testing"
+	"time"
+)
+
+func TestEventDetectorPriceMove(t *testing.T) {
+	d := NewEventDetector(store.EventTriggerConfig{PriceMovePct: 3, WindowMinutes: 10})
+	start := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	if events := d.OnPrice("BTCUSDT", 100, start); len(events) != 0 {
+		t.Fatalf("first price should not fire, got %v", events)
+	}
+	if events := d.OnPrice("BTCUSDT", 102, start.Add(2*time.Minute)); len(events) != 0 {
+		t.Fatalf("2%% move should not fire, got %v", events)
+	}
+	events := d.OnPrice("BTCUSDT", 96.5, start.Add(4*time.Minute))
+	if len(events) != 1 || events[0].Type != EventPriceMove || events[0].Value > -3 {
+		t.Fatalf("expected a downward price move event, got %v", events)
+	}
+	// History is reset after firing
+	if events := d.OnPrice("BTCUSDT", 96, start.Add(5*time.Minute)); len(events) != 0 {
+		t.Errorf("move should not fire twice, got %v", events)
+	}
+	// Prices outside the window are forgotten
+	if events := d.OnPrice("BTCUSDT", 98, start.Add(30*time.Minute)); len(events) != 0 {
+		t.Errorf("stale prices should be dropped, got %v", events)
+	}
+}
+
+func TestEventDetectorATRSpike(t *testing.T) {
+	d := NewEventDetector(store.EventTriggerConfig{ATRSpikeMultiplier: 3})
+	start := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	// 15 quiet minutes with a 1.0 range each
+	for i := 0; i <= atrSpikeBars; i++ {
+		at := start.Add(time.Duration(i) * time.Minute)
+		d.OnPrice("ETHUSDT", 100, at)
+		if events := d.OnPrice("ETHUSDT", 101, at.Add(30*time.Second)); len(events) != 0 {
+			t.Fatalf("quiet minute %d should not fire, got %v", i, events)
+		}
+	}
+
+	at := start.Add(time.Duration(atrSpikeBars+1) * time.Minute)
+	d.OnPrice("ETHUSDT", 100, at)
+	events := d.OnPrice("ETHUSDT", 104, at.Add(10*time.Second))
+	if len(events) != 1 || events[0].Type != EventATRSpike {
+		t.Fatalf("expected ATR spike, got %v", events)
+	}
+	if events := d.OnPrice("ETHUSDT", 105, at.Add(20*time.Second)); len(events) != 0 {
+		t.Errorf("spike should fire once per bar, got %v", events)
+	}
+}
+
+func TestEventDetectorDerivatives(t *testing.T) {
+	d := NewEventDetector(store.EventTriggerConfig{FundingFlip: true, OISurgePct: 5, LiquidationCascadeUSD: 1e6})
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	if events := d.OnDerivatives("SOLUSDT", 0.0001, 1000, 2e6, now); len(events) != 1 || events[0].Type != EventLiquidationCascade {
+		t.Fatalf("expected liquidation cascade only, got %v", events)
+	}
+	// Same liquidation bar polled again does not fire
+	if events := d.OnDerivatives("SOLUSDT", 0.0001, 1020, 2e6, now.Add(time.Minute)); len(events) != 0 {
+		t.Fatalf("expected no events, got %v", events)
+	}
+
+	events := d.OnDerivatives("SOLUSDT", -0.0002, 1060, 0, now.Add(2*time.Minute))
+	types := map[string]bool{}
+	for _, e := range events {
+		types[e.Type] = true
+	}
+	if !types[EventFundingFlip] || !types[EventOISurge] || len(events) != 2 {
+		t.Errorf("expected funding flip and OI surge, got %v", events)
+	}
+}
+
+func TestEventDetectorPositionPnL(t *testing.T) {
+	d := NewEventDetector(store.EventTriggerConfig{PositionPnLPct: 10})
+	now := time.Now()
+
+	if e := d.OnPositionPnL("BTCUSDT", "long", 5, now); e != nil {
+		t.Fatalf("5%% should not fire, got %v", e)
+	}
+	if e := d.OnPositionPnL("BTCUSDT", "long", -12, now); e == nil || e.Type != EventPositionPnL {
+		t.Fatalf("crossing -10%% should fire, got %v", e)
+	}
+	if e := d.OnPositionPnL("BTCUSDT", "long", -15, now); e != nil {
+		t.Errorf("staying beyond the threshold should not fire again, got %v", e)
+	}
+
+	d.PrunePositions(map[string]bool{})
+	if e := d.OnPositionPnL("BTCUSDT", "long", -15, now); e == nil {
+		t.Error("a new position beyond the threshold should fire")
+	}
+}
+
+func TestEventBudget(t *testing.T) {
+	b := NewEventBudget(store.EventTriggerConfig{DebounceSeconds: 60, MaxCallsPerHour: 2})
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+
+	if ok, _ := b.Allow(now); !ok {
+		t.Fatal("first event should be allowed")
+	}
+	if ok, _ := b.Allow(now.Add(30 * time.Second)); ok {
+		t.Error("event within debounce should be rejected")
+	}
+	if ok, _ := b.Allow(now.Add(2 * time.Minute)); !ok {
+		t.Error("event after debounce should be allowed")
+	}
+	if ok, reason := b.Allow(now.Add(5 * time.Minute)); ok {
+		t.Error("hourly budget should be exhausted")
+	} else if reason == "" {
+		t.Error("expected a rejection reason")
+	}
+	if ok, _ := b.Allow(now.Add(61 * time.Minute)); !ok {
+		t.Error("budget should refill after an hour")
+	}
+
+	b.MarkCycle(now.Add(70 * time.Minute))
+	if ok, _ := b.Allow(now.Add(70*time.Minute + 10*time.Second)); ok {
+		t.Error("event right after a scheduled cycle should be debounced")
+	}
+}
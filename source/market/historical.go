@@ -14,8 +14,8 @@ const (
 )
 
 // GetKlinesRange fetches K-line series within specified time range (closed interval), returns data sorted by time in ascending order.
+// The provider is chosen by the symbol's asset class (see KlineRegistry), crypto uses Binance first.
 func GetKlinesRange(symbol string, timeframe string, start, end time.Time) ([]Kline, error) {
-	symbol = Normalize(symbol)
 	normTF, err := NormalizeTimeframe(timeframe)
 	if err != nil {
 		return nil, err
@@ -24,6 +24,11 @@ func GetKlinesRange(symbol string, timeframe string, start, end time.Time) ([]Kl
 		return nil, fmt.Errorf("end time must be after start time")
 	}
 
+	return defaultKlineRegistry.GetKlinesRange(symbol, normTF, start, end)
+}
+
+// getKlinesRangeFromBinance fetches K-lines within a time range from Binance futures, paging by binanceMaxKlineLimit
+func getKlinesRangeFromBinance(symbol string, normTF string, start, end time.Time) ([]Kline, error) {
 	startMs := start.UnixMilli()
 	endMs := end.UnixMilli()
 
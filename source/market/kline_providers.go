@@ -0,0 +1,225 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"nofx/provider/alpaca"
+	"nofx/provider/hyperliquid"
+	"nofx/provider/twelvedata"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ============================================================================
+// Built-in kline providers
+// ============================================================================
+
+const providerRequestTimeout = 30 * time.Second
+
+// coinankKlineProvider CoinAnk free/open kline API (Binance futures data, latest klines only)
+type coinankKlineProvider struct{}
+
+func (p *coinankKlineProvider) Name() string { return "coinank" }
+
+func (p *coinankKlineProvider) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	return getKlinesFromCoinAnk(symbol, interval, limit)
+}
+
+func (p *coinankKlineProvider) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	return nil, ErrRangeNotSupported
+}
+
+// binanceKlineProvider Binance USDT-M futures public kline API
+type binanceKlineProvider struct{}
+
+func (p *binanceKlineProvider) Name() string { return "binance" }
+
+func (p *binanceKlineProvider) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	return NewAPIClient().GetKlines(symbol, interval, limit)
+}
+
+func (p *binanceKlineProvider) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	return getKlinesRangeFromBinance(symbol, interval, start, end)
+}
+
+// hyperliquidKlineProvider Hyperliquid candleSnapshot API (main dex crypto and xyz dex assets)
+type hyperliquidKlineProvider struct{}
+
+func (p *hyperliquidKlineProvider) Name() string { return "hyperliquid" }
+
+func (p *hyperliquidKlineProvider) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	return getKlinesFromHyperliquid(symbol, interval, limit)
+}
+
+// hyperliquidMaxCandles most candles a single candleSnapshot request returns
+const hyperliquidMaxCandles = 5000
+
+func (p *hyperliquidKlineProvider) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	client := hyperliquid.NewClient()
+	coin := hyperliquid.NormalizeCoinBase(symbol)
+	hlInterval := hyperliquid.MapTimeframe(interval)
+
+	return pageKlinesRange(start.UnixMilli(), end.UnixMilli(), hyperliquidMaxCandles, func(startMs, endMs int64) ([]Kline, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+		defer cancel()
+
+		candles, err := client.GetCandlesRange(ctx, coin, hlInterval, startMs, endMs)
+		if err != nil {
+			return nil, fmt.Errorf("Hyperliquid API error: %w", err)
+		}
+		return convertHyperliquidCandles(candles), nil
+	})
+}
+
+// pageKlinesRange fetches [startMs, endMs] from an API capped at pageLimit klines per request, paging
+// forward from the open time of the last kline received until endMs is reached or the API runs out
+func pageKlinesRange(startMs, endMs int64, pageLimit int, fetch func(startMs, endMs int64) ([]Kline, error)) ([]Kline, error) {
+	var all []Kline
+	cursor := startMs
+	for cursor <= endMs {
+		batch, err := fetch(cursor, endMs)
+		if err != nil {
+			return nil, err
+		}
+		received := len(all)
+		for _, k := range batch {
+			// Skip candles already received, pages may overlap
+			if len(all) == 0 || k.OpenTime > all[len(all)-1].OpenTime {
+				all = append(all, k)
+			}
+		}
+		// A short page is the last one; a page without new candles would repeat forever
+		if len(batch) < pageLimit || len(all) == received {
+			break
+		}
+		cursor = all[len(all)-1].OpenTime + 1
+	}
+	return all, nil
+}
+
+// alpacaKlineProvider Alpaca market data API (US stocks, IEX feed)
+type alpacaKlineProvider struct{}
+
+func (p *alpacaKlineProvider) Name() string { return "alpaca" }
+
+func (p *alpacaKlineProvider) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+	defer cancel()
+
+	bars, err := alpaca.NewClient().GetBars(ctx, symbolTicker(symbol), alpaca.MapTimeframe(interval), limit)
+	if err != nil {
+		return nil, err
+	}
+	return convertAlpacaBars(bars, interval), nil
+}
+
+func (p *alpacaKlineProvider) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+	defer cancel()
+
+	bars, err := alpaca.NewClient().GetBarsRange(ctx, symbolTicker(symbol), alpaca.MapTimeframe(interval), start, end)
+	if err != nil {
+		return nil, err
+	}
+	return convertAlpacaBars(bars, interval), nil
+}
+
+// twelveDataKlineProvider Twelve Data time series API (US stocks and forex)
+type twelveDataKlineProvider struct{}
+
+func (p *twelveDataKlineProvider) Name() string { return "twelvedata" }
+
+func (p *twelveDataKlineProvider) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+	defer cancel()
+
+	resp, err := twelvedata.NewClient().GetTimeSeries(ctx, symbolTicker(symbol), twelvedata.MapTimeframe(interval), limit)
+	if err != nil {
+		return nil, err
+	}
+	return convertTwelveDataBars(resp, interval)
+}
+
+func (p *twelveDataKlineProvider) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerRequestTimeout)
+	defer cancel()
+
+	resp, err := twelvedata.NewClient().GetTimeSeriesRange(ctx, symbolTicker(symbol), twelvedata.MapTimeframe(interval), start, end)
+	if err != nil {
+		return nil, err
+	}
+	return convertTwelveDataBars(resp, interval)
+}
+
+// intervalMillis kline duration in milliseconds, used to derive CloseTime for providers that only return open time
+func intervalMillis(interval string) int64 {
+	return int64(parseTimeframeToMinutes(interval)) * 60 * 1000
+}
+
+func convertHyperliquidCandles(candles []hyperliquid.Candle) []Kline {
+	klines := make([]Kline, len(candles))
+	for i, c := range candles {
+		open, _ := strconv.ParseFloat(c.Open, 64)
+		high, _ := strconv.ParseFloat(c.High, 64)
+		low, _ := strconv.ParseFloat(c.Low, 64)
+		closePrice, _ := strconv.ParseFloat(c.Close, 64)
+		volume, _ := strconv.ParseFloat(c.Volume, 64)
+
+		klines[i] = Kline{
+			OpenTime:  c.OpenTime,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     closePrice,
+			Volume:    volume,
+			CloseTime: c.CloseTime,
+		}
+	}
+	return klines
+}
+
+func convertAlpacaBars(bars []alpaca.Bar, interval string) []Kline {
+	duration := intervalMillis(interval)
+	klines := make([]Kline, len(bars))
+	for i, bar := range bars {
+		openTime := bar.Timestamp.UnixMilli()
+		klines[i] = Kline{
+			OpenTime:    openTime,
+			Open:        bar.Open,
+			High:        bar.High,
+			Low:         bar.Low,
+			Close:       bar.Close,
+			Volume:      float64(bar.Volume),
+			QuoteVolume: float64(bar.Volume) * bar.VWAP,
+			Trades:      int(bar.TradeCount),
+			CloseTime:   openTime + duration - 1,
+		}
+	}
+	return klines
+}
+
+// convertTwelveDataBars converts Twelve Data bars (requested in UTC) to ascending klines
+func convertTwelveDataBars(resp *twelvedata.TimeSeriesResponse, interval string) ([]Kline, error) {
+	duration := intervalMillis(interval)
+	klines := make([]Kline, 0, len(resp.Values))
+	for _, bar := range resp.Values {
+		open, high, low, closePrice, volume, openTime, err := twelvedata.ParseBar(bar)
+		if err != nil {
+			return nil, err
+		}
+
+		klines = append(klines, Kline{
+			OpenTime:  openTime,
+			Open:      open,
+			High:      high,
+			Low:       low,
+			Close:     closePrice,
+			Volume:    volume,
+			CloseTime: openTime + duration - 1,
+		})
+	}
+
+	sort.Slice(klines, func(i, j int) bool { return klines[i].OpenTime < klines[j].OpenTime })
+	return klines, nil
+}
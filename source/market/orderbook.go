@@ -0,0 +1,294 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"nofx/provider/hyperliquid"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OrderBookLevel single price level of an order book
+type OrderBookLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// OrderBook order book snapshot (bids sorted descending, asks ascending)
+type OrderBook struct {
+	Symbol    string           `json:"symbol"`
+	Source    string           `json:"source"` // "binance" | "hyperliquid"
+	Bids      []OrderBookLevel `json:"bids"`
+	Asks      []OrderBookLevel `json:"asks"`
+	Timestamp time.Time        `json:"timestamp"`
+}
+
+// OrderBookFeatures microstructure features derived from an order book
+type OrderBookFeatures struct {
+	Symbol       string           `json:"symbol"`
+	BestBid      float64          `json:"best_bid"`
+	BestAsk      float64          `json:"best_ask"`
+	MidPrice     float64          `json:"mid_price"`
+	SpreadBps    float64          `json:"spread_bps"`     // (ask-bid)/mid in basis points
+	BidDepth1Pct float64          `json:"bid_depth_1pct"` // bid notional (USD) within 1% below mid
+	AskDepth1Pct float64          `json:"ask_depth_1pct"` // ask notional (USD) within 1% above mid
+	Imbalance    float64          `json:"imbalance"`      // (bid-ask)/(bid+ask) within ±1%, range -1..1
+	TopBids      []OrderBookLevel `json:"top_bids"`       // best N bid levels
+	TopAsks      []OrderBookLevel `json:"top_asks"`       // best N ask levels
+	Timestamp    time.Time        `json:"timestamp"`
+}
+
+// SlippageEstimate result of walking the book for a market order
+type SlippageEstimate struct {
+	AvgPrice    float64 `json:"avg_price"`    // volume weighted fill price
+	SlippagePct float64 `json:"slippage_pct"` // avg price deviation from mid (%)
+	FilledUSD   float64 `json:"filled_usd"`   // notional the visible book can absorb
+	Complete    bool    `json:"complete"`     // false when the order exceeds visible depth
+}
+
+// OrderBookCache short-lived order book cache, so prompt building and order sizing share snapshots
+type OrderBookCache struct {
+	Book      *OrderBook
+	UpdatedAt time.Time
+}
+
+var (
+	orderBookMap      sync.Map // map[string]*OrderBookCache
+	orderBookCacheTTL = 5 * time.Second
+)
+
+const defaultOrderBookDepth = 100
+
+// GetOrderBook fetches the order book for a symbol
+// Crypto uses Binance futures public depth, xyz dex assets use Hyperliquid l2Book
+func GetOrderBook(symbol string) (*OrderBook, error) {
+	symbol = Normalize(symbol)
+
+	if cached, ok := orderBookMap.Load(symbol); ok {
+		entry := cached.(*OrderBookCache)
+		if time.Since(entry.UpdatedAt) < orderBookCacheTTL {
+			return entry.Book, nil
+		}
+	}
+	return fetchOrderBook(symbol)
+}
+
+// GetFreshOrderBook fetches the order book bypassing the cache, for checks that must see the book as it
+// is now (e.g. between child orders); the result refreshes the cache
+func GetFreshOrderBook(symbol string) (*OrderBook, error) {
+	return fetchOrderBook(Normalize(symbol))
+}
+
+// fetchOrderBook fetches a normalized symbol's order book from its source and caches it
+func fetchOrderBook(symbol string) (*OrderBook, error) {
+	var book *OrderBook
+	var err error
+	switch ClassifySymbol(symbol) {
+	case AssetCrypto:
+		book, err = getOrderBookFromBinance(symbol)
+	case AssetXyzDex:
+		book, err = getOrderBookFromHyperliquid(symbol)
+	default:
+		return nil, fmt.Errorf("%s has no order book source", symbol)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return nil, fmt.Errorf("%s order book is empty", symbol)
+	}
+
+	orderBookMap.Store(symbol, &OrderBookCache{Book: book, UpdatedAt: time.Now()})
+	return book, nil
+}
+
+func getOrderBookFromBinance(symbol string) (*OrderBook, error) {
+	depth, err := NewAPIClient().GetDepth(symbol, defaultOrderBookDepth)
+	if err != nil {
+		return nil, fmt.Errorf("Binance depth API error: %w", err)
+	}
+
+	book := &OrderBook{
+		Symbol:    symbol,
+		Source:    "binance",
+		Bids:      parseDepthLevels(depth.Bids),
+		Asks:      parseDepthLevels(depth.Asks),
+		Timestamp: time.Now(),
+	}
+	if depth.EventTime > 0 {
+		book.Timestamp = time.UnixMilli(depth.EventTime)
+	}
+	book.sortLevels()
+	return book, nil
+}
+
+func getOrderBookFromHyperliquid(symbol string) (*OrderBook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	l2, err := hyperliquid.NewClient().GetL2Book(ctx, strings.TrimPrefix(symbol, "xyz:"))
+	if err != nil {
+		return nil, fmt.Errorf("Hyperliquid l2Book API error: %w", err)
+	}
+
+	book := &OrderBook{
+		Symbol:    symbol,
+		Source:    "hyperliquid",
+		Timestamp: time.UnixMilli(l2.Time),
+	}
+	for side, levels := range l2.Levels {
+		for _, lvl := range levels {
+			price, _ := strconv.ParseFloat(lvl.Price, 64)
+			qty, _ := strconv.ParseFloat(lvl.Size, 64)
+			if price <= 0 || qty <= 0 {
+				continue
+			}
+			if side == 0 {
+				book.Bids = append(book.Bids, OrderBookLevel{Price: price, Quantity: qty})
+			} else {
+				book.Asks = append(book.Asks, OrderBookLevel{Price: price, Quantity: qty})
+			}
+		}
+	}
+	book.sortLevels()
+	return book, nil
+}
+
+func parseDepthLevels(raw [][2]string) []OrderBookLevel {
+	levels := make([]OrderBookLevel, 0, len(raw))
+	for _, lvl := range raw {
+		price, err1 := strconv.ParseFloat(lvl[0], 64)
+		qty, err2 := strconv.ParseFloat(lvl[1], 64)
+		if err1 != nil || err2 != nil || price <= 0 || qty <= 0 {
+			continue
+		}
+		levels = append(levels, OrderBookLevel{Price: price, Quantity: qty})
+	}
+	return levels
+}
+
+func (b *OrderBook) sortLevels() {
+	sort.Slice(b.Bids, func(i, j int) bool { return b.Bids[i].Price > b.Bids[j].Price })
+	sort.Slice(b.Asks, func(i, j int) bool { return b.Asks[i].Price < b.Asks[j].Price })
+}
+
+// MidPrice returns (best bid + best ask) / 2, or 0 for an empty book
+func (b *OrderBook) MidPrice() float64 {
+	if b == nil || len(b.Bids) == 0 || len(b.Asks) == 0 {
+		return 0
+	}
+	return (b.Bids[0].Price + b.Asks[0].Price) / 2
+}
+
+// Features computes spread, ±1% depth, imbalance and the top N levels of the book
+func (b *OrderBook) Features(levels int) *OrderBookFeatures {
+	mid := b.MidPrice()
+	if mid <= 0 {
+		return nil
+	}
+	if levels <= 0 {
+		levels = 5
+	}
+
+	f := &OrderBookFeatures{
+		Symbol:    b.Symbol,
+		BestBid:   b.Bids[0].Price,
+		BestAsk:   b.Asks[0].Price,
+		MidPrice:  mid,
+		SpreadBps: (b.Asks[0].Price - b.Bids[0].Price) / mid * 10000,
+		TopBids:   b.Bids[:min(levels, len(b.Bids))],
+		TopAsks:   b.Asks[:min(levels, len(b.Asks))],
+		Timestamp: b.Timestamp,
+	}
+
+	for _, lvl := range b.Bids {
+		if lvl.Price < mid*0.99 {
+			break
+		}
+		f.BidDepth1Pct += lvl.Price * lvl.Quantity
+	}
+	for _, lvl := range b.Asks {
+		if lvl.Price > mid*1.01 {
+			break
+		}
+		f.AskDepth1Pct += lvl.Price * lvl.Quantity
+	}
+	if total := f.BidDepth1Pct + f.AskDepth1Pct; total > 0 {
+		f.Imbalance = (f.BidDepth1Pct - f.AskDepth1Pct) / total
+	}
+
+	return f
+}
+
+// bookSide returns the levels a market order of the given side consumes ("long"/"buy" takes asks)
+func (b *OrderBook) bookSide(side string) []OrderBookLevel {
+	switch strings.ToLower(side) {
+	case "long", "buy":
+		return b.Asks
+	default:
+		return b.Bids
+	}
+}
+
+// EstimateSlippage walks the book to estimate the fill of a market order of notionalUSD
+func (b *OrderBook) EstimateSlippage(side string, notionalUSD float64) SlippageEstimate {
+	mid := b.MidPrice()
+	if mid <= 0 || notionalUSD <= 0 {
+		return SlippageEstimate{}
+	}
+
+	remaining := notionalUSD
+	var filledQty, filledUSD float64
+	for _, lvl := range b.bookSide(side) {
+		levelUSD := lvl.Price * lvl.Quantity
+		take := math.Min(levelUSD, remaining)
+		filledUSD += take
+		filledQty += take / lvl.Price
+		remaining -= take
+		if remaining <= 0 {
+			break
+		}
+	}
+	if filledQty == 0 {
+		return SlippageEstimate{}
+	}
+
+	avgPrice := filledUSD / filledQty
+	return SlippageEstimate{
+		AvgPrice:    avgPrice,
+		SlippagePct: math.Abs(avgPrice-mid) / mid * 100,
+		FilledUSD:   filledUSD,
+		Complete:    remaining <= 0,
+	}
+}
+
+// MaxNotionalWithinSlippage returns the largest market order notional whose estimated
+// slippage stays within maxSlippagePct (limited to the visible depth)
+func (b *OrderBook) MaxNotionalWithinSlippage(side string, maxSlippagePct float64) float64 {
+	var depthUSD float64
+	for _, lvl := range b.bookSide(side) {
+		depthUSD += lvl.Price * lvl.Quantity
+	}
+	if depthUSD <= 0 {
+		return 0
+	}
+	if est := b.EstimateSlippage(side, depthUSD); est.SlippagePct <= maxSlippagePct {
+		return depthUSD
+	}
+
+	// Average fill price grows monotonically with size, so bisect on notional
+	lo, hi := 0.0, depthUSD
+	for i := 0; i < 40; i++ {
+		mid := (lo + hi) / 2
+		if b.EstimateSlippage(side, mid).SlippagePct <= maxSlippagePct {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
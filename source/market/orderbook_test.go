@@ -0,0 +1,88 @@
+package market
+
+import (
+	"math"
+	"testing"
+)
+
+// newTestOrderBook builds a book around mid 100 with 10 levels per side, 10 units each, 0.1 apart
+func newTestOrderBook() *OrderBook {
+	book := &OrderBook{Symbol: "TESTUSDT"}
+	for i := 0; i < 10; i++ {
+		book.Bids = append(book.Bids, OrderBookLevel{Price: 99.95 - float64(i)*0.1, Quantity: 10})
+		book.Asks = append(book.Asks, OrderBookLevel{Price: 100.05 + float64(i)*0.1, Quantity: 10})
+	}
+	return book
+}
+
+// TestOrderBookFeatures tests spread, depth and imbalance calculation
+func TestOrderBookFeatures(t *testing.T) {
+	book := newTestOrderBook()
+	book.Bids[0].Quantity = 30 // make bids heavier
+
+	f := book.Features(3)
+	if f == nil {
+		t.Fatal("expected features for non-empty book")
+	}
+	if math.Abs(f.MidPrice-100) > 1e-9 {
+		t.Errorf("mid price = %.4f, want 100", f.MidPrice)
+	}
+	if math.Abs(f.SpreadBps-10) > 1e-6 {
+		t.Errorf("spread = %.4f bps, want 10", f.SpreadBps)
+	}
+	if len(f.TopBids) != 3 || len(f.TopAsks) != 3 {
+		t.Errorf("expected 3 top levels per side, got %d/%d", len(f.TopBids), len(f.TopAsks))
+	}
+	if f.Imbalance <= 0 {
+		t.Errorf("imbalance = %.4f, want positive for bid heavy book", f.Imbalance)
+	}
+
+	if (&OrderBook{}).Features(5) != nil {
+		t.Error("expected nil features for empty book")
+	}
+}
+
+// TestEstimateSlippage tests walking the book for market orders
+func TestEstimateSlippage(t *testing.T) {
+	book := newTestOrderBook()
+
+	small := book.EstimateSlippage("long", 500)
+	if !small.Complete || math.Abs(small.AvgPrice-100.05) > 1e-9 {
+		t.Errorf("small order should fill at best ask, got %+v", small)
+	}
+
+	large := book.EstimateSlippage("long", 5000)
+	if !large.Complete || large.SlippagePct <= small.SlippagePct {
+		t.Errorf("large order should slip more than small order, got %+v vs %+v", large, small)
+	}
+
+	sell := book.EstimateSlippage("short", 500)
+	if math.Abs(sell.AvgPrice-99.95) > 1e-9 {
+		t.Errorf("sell order should fill at best bid, got %+v", sell)
+	}
+
+	if huge := book.EstimateSlippage("long", 1e6); huge.Complete {
+		t.Errorf("order larger than visible depth should be incomplete, got %+v", huge)
+	}
+}
+
+// TestMaxNotionalWithinSlippage tests the largest order that stays within the limit
+func TestMaxNotionalWithinSlippage(t *testing.T) {
+	book := newTestOrderBook()
+
+	limit := 0.2
+	maxNotional := book.MaxNotionalWithinSlippage("long", limit)
+	if maxNotional <= 0 {
+		t.Fatal("expected positive max notional")
+	}
+	if est := book.EstimateSlippage("long", maxNotional); est.SlippagePct > limit+1e-9 {
+		t.Errorf("max notional %.2f slips %.4f%%, over limit %.2f%%", maxNotional, est.SlippagePct, limit)
+	}
+	if est := book.EstimateSlippage("long", maxNotional*1.05); est.SlippagePct <= limit {
+		t.Errorf("5%% larger order should exceed limit, got %.4f%%", est.SlippagePct)
+	}
+
+	if all := book.MaxNotionalWithinSlippage("long", 50); all < 10000 {
+		t.Errorf("loose limit should allow the full visible depth, got %.2f", all)
+	}
+}
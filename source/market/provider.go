@@ -0,0 +1,281 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"nofx/logger"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AssetClass routes a symbol to the kline providers that can serve it
+type AssetClass string
+
+const (
+	AssetCrypto AssetClass = "crypto" // USDT perpetuals, e.g. BTCUSDT
+	AssetXyzDex AssetClass = "xyz"    // Hyperliquid xyz dex perps, e.g. xyz:TSLA
+	AssetStock  AssetClass = "stock"  // US stocks, e.g. stock:AAPL
+	AssetForex  AssetClass = "forex"  // Forex pairs, e.g. fx:EUR/USD
+)
+
+// HasPerpetualData reports whether open interest and funding rate exist for the asset class
+func (c AssetClass) HasPerpetualData() bool {
+	return c == AssetCrypto || c == AssetXyzDex
+}
+
+const (
+	stockSymbolPrefix = "stock:"
+	forexSymbolPrefix = "fx:"
+)
+
+// ErrRangeNotSupported returned by providers that can only serve the latest klines
+var ErrRangeNotSupported = errors.New("kline range queries not supported")
+
+// KlineProvider source of OHLCV data
+// Symbols are passed in normalized form (see Normalize), providers convert them to their own format
+type KlineProvider interface {
+	// Name unique provider name used in routes and health reports
+	Name() string
+	// GetKlines returns the latest limit klines sorted by time in ascending order
+	GetKlines(symbol, interval string, limit int) ([]Kline, error)
+	// GetKlinesRange returns klines within [start, end] sorted by time in ascending order
+	GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error)
+}
+
+// ProviderHealth health status of a kline provider
+type ProviderHealth struct {
+	Name                string    `json:"name"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	TotalRequests       int64     `json:"total_requests"`
+	TotalFailures       int64     `json:"total_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+	LastFailure         time.Time `json:"last_failure,omitempty"`
+	CooldownUntil       time.Time `json:"cooldown_until,omitempty"`
+}
+
+const (
+	providerFailureThreshold = 3               // consecutive failures before a provider is put on cooldown
+	providerCooldown         = 1 * time.Minute // providers on cooldown are tried last
+)
+
+// KlineRegistry routes symbols to kline providers by asset class with ordered fallbacks
+type KlineRegistry struct {
+	mu        sync.RWMutex
+	providers map[string]KlineProvider
+	routes    map[AssetClass][]string
+	health    map[string]*ProviderHealth
+}
+
+// NewKlineRegistry creates an empty registry
+func NewKlineRegistry() *KlineRegistry {
+	return &KlineRegistry{
+		providers: make(map[string]KlineProvider),
+		routes:    make(map[AssetClass][]string),
+		health:    make(map[string]*ProviderHealth),
+	}
+}
+
+var defaultKlineRegistry = newDefaultKlineRegistry()
+
+// newDefaultKlineRegistry CoinAnk → Binance → Hyperliquid for crypto, Alpaca → TwelveData for stocks
+func newDefaultKlineRegistry() *KlineRegistry {
+	r := NewKlineRegistry()
+	r.Register(&coinankKlineProvider{})
+	r.Register(&binanceKlineProvider{})
+	r.Register(&hyperliquidKlineProvider{})
+	r.Register(&alpacaKlineProvider{})
+	r.Register(&twelveDataKlineProvider{})
+
+	r.SetRoute(AssetCrypto, "coinank", "binance", "hyperliquid")
+	r.SetRoute(AssetXyzDex, "hyperliquid")
+	r.SetRoute(AssetStock, "alpaca", "twelvedata")
+	r.SetRoute(AssetForex, "twelvedata")
+	return r
+}
+
+// DefaultKlineRegistry returns the registry used by Get, GetWithTimeframes and GetKlinesRange
+func DefaultKlineRegistry() *KlineRegistry {
+	return defaultKlineRegistry
+}
+
+// Register adds or replaces a provider
+func (r *KlineRegistry) Register(p KlineProvider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name()] = p
+	if _, ok := r.health[p.Name()]; !ok {
+		r.health[p.Name()] = &ProviderHealth{Name: p.Name(), Healthy: true}
+	}
+}
+
+// SetRoute sets the ordered provider list for an asset class
+func (r *KlineRegistry) SetRoute(class AssetClass, names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routes[class] = append([]string(nil), names...)
+}
+
+// Route returns the providers for an asset class, healthy providers first (order otherwise preserved)
+func (r *KlineRegistry) Route(class AssetClass) []KlineProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	now := time.Now()
+	var healthy, cooling []KlineProvider
+	for _, name := range r.routes[class] {
+		p, ok := r.providers[name]
+		if !ok {
+			continue
+		}
+		if h := r.health[name]; h != nil && now.Before(h.CooldownUntil) {
+			cooling = append(cooling, p)
+		} else {
+			healthy = append(healthy, p)
+		}
+	}
+	return append(healthy, cooling...)
+}
+
+// Health returns a snapshot of all provider health records sorted by name
+func (r *KlineRegistry) Health() []ProviderHealth {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]ProviderHealth, 0, len(r.health))
+	for _, h := range r.health {
+		result = append(result, *h)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (r *KlineRegistry) recordResult(name string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.health[name]
+	if !ok {
+		h = &ProviderHealth{Name: name}
+		r.health[name] = h
+	}
+	h.TotalRequests++
+
+	if err == nil {
+		h.Healthy = true
+		h.ConsecutiveFailures = 0
+		h.LastSuccess = time.Now()
+		h.CooldownUntil = time.Time{}
+		return
+	}
+
+	h.TotalFailures++
+	h.ConsecutiveFailures++
+	h.LastError = err.Error()
+	h.LastFailure = time.Now()
+	if h.ConsecutiveFailures >= providerFailureThreshold {
+		if h.Healthy {
+			logger.Warnf("⚠️ Kline provider %s marked unhealthy after %d consecutive failures: %v", name, h.ConsecutiveFailures, err)
+		}
+		h.Healthy = false
+		h.CooldownUntil = h.LastFailure.Add(providerCooldown)
+	}
+}
+
+// GetKlines fetches the latest klines, falling back through the providers of the symbol's asset class
+func (r *KlineRegistry) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	symbol = Normalize(symbol)
+	return r.fetch(symbol, func(p KlineProvider) ([]Kline, error) {
+		return p.GetKlines(symbol, interval, limit)
+	})
+}
+
+// GetKlinesRange fetches klines within [start, end], falling back through the providers of the symbol's asset class
+func (r *KlineRegistry) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	symbol = Normalize(symbol)
+	return r.fetch(symbol, func(p KlineProvider) ([]Kline, error) {
+		return p.GetKlinesRange(symbol, interval, start, end)
+	})
+}
+
+func (r *KlineRegistry) fetch(symbol string, call func(p KlineProvider) ([]Kline, error)) ([]Kline, error) {
+	class := ClassifySymbol(symbol)
+	providers := r.Route(class)
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("no kline provider configured for %s (%s)", symbol, class)
+	}
+
+	var errs []string
+	for _, p := range providers {
+		klines, err := call(p)
+		if errors.Is(err, ErrRangeNotSupported) {
+			continue
+		}
+		if err == nil && len(klines) == 0 {
+			err = fmt.Errorf("empty kline data")
+		}
+		r.recordResult(p.Name(), err)
+		if err == nil {
+			return klines, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", p.Name(), err))
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no provider for %s (%s) supports this query", symbol, class)
+	}
+	return nil, fmt.Errorf("all kline providers failed for %s: %s", symbol, strings.Join(errs, "; "))
+}
+
+// GetKlines fetches the latest klines for a symbol through the default registry
+func GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	return defaultKlineRegistry.GetKlines(symbol, interval, limit)
+}
+
+// KlineProviderHealth returns the health of the default registry's providers
+func KlineProviderHealth() []ProviderHealth {
+	return defaultKlineRegistry.Health()
+}
+
+// ClassifySymbol returns the asset class of a symbol
+func ClassifySymbol(symbol string) AssetClass {
+	lower := strings.ToLower(symbol)
+	switch {
+	case strings.HasPrefix(lower, stockSymbolPrefix):
+		return AssetStock
+	case strings.HasPrefix(lower, forexSymbolPrefix):
+		return AssetForex
+	case IsXyzDexAsset(symbol):
+		return AssetXyzDex
+	default:
+		return AssetCrypto
+	}
+}
+
+// normalizePrefixedSymbol normalizes stock:/fx: symbols ("fx:eurusd" → "fx:EUR/USD"), ok is false for other symbols
+func normalizePrefixedSymbol(symbol string) (string, bool) {
+	lower := strings.ToLower(symbol)
+	switch {
+	case strings.HasPrefix(lower, stockSymbolPrefix):
+		return stockSymbolPrefix + strings.ToUpper(symbol[len(stockSymbolPrefix):]), true
+	case strings.HasPrefix(lower, forexSymbolPrefix):
+		pair := strings.ToUpper(symbol[len(forexSymbolPrefix):])
+		pair = strings.NewReplacer("/", "", "-", "", "_", "").Replace(pair)
+		if len(pair) == 6 {
+			pair = pair[:3] + "/" + pair[3:]
+		}
+		return forexSymbolPrefix + pair, true
+	}
+	return "", false
+}
+
+// symbolTicker strips the asset class prefix ("stock:AAPL" → "AAPL", "fx:EUR/USD" → "EUR/USD")
+func symbolTicker(symbol string) string {
+	if idx := strings.Index(symbol, ":"); idx >= 0 {
+		return symbol[idx+1:]
+	}
+	return symbol
+}
@@ -0,0 +1,141 @@
+package market
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeKlineProvider test provider returning a fixed result
+type fakeKlineProvider struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (p *fakeKlineProvider) Name() string { return p.name }
+
+func (p *fakeKlineProvider) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []Kline{{OpenTime: 1, Close: 100}}, nil
+}
+
+func (p *fakeKlineProvider) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	return nil, ErrRangeNotSupported
+}
+
+// TestKlineRegistryFailover tests ordered fallback and health tracking
+func TestKlineRegistryFailover(t *testing.T) {
+	primary := &fakeKlineProvider{name: "primary", err: errors.New("timeout")}
+	backup := &fakeKlineProvider{name: "backup"}
+
+	r := NewKlineRegistry()
+	r.Register(primary)
+	r.Register(backup)
+	r.SetRoute(AssetCrypto, "primary", "backup")
+
+	for i := 0; i < providerFailureThreshold; i++ {
+		klines, err := r.GetKlines("BTC", "1h", 10)
+		if err != nil || len(klines) != 1 {
+			t.Fatalf("expected fallback to backup, got %v, %v", klines, err)
+		}
+	}
+	if primary.calls != providerFailureThreshold {
+		t.Errorf("primary should be tried %d times, got %d", providerFailureThreshold, primary.calls)
+	}
+
+	// Primary is on cooldown now, backup is tried first
+	if route := r.Route(AssetCrypto); route[0].Name() != "backup" {
+		t.Errorf("expected backup first while primary cools down, got %s", route[0].Name())
+	}
+	if _, err := r.GetKlines("BTC", "1h", 10); err != nil {
+		t.Fatal(err)
+	}
+	if primary.calls != providerFailureThreshold {
+		t.Errorf("primary should be skipped while cooling down, got %d calls", primary.calls)
+	}
+
+	for _, h := range r.Health() {
+		if h.Name == "primary" && (h.Healthy || h.ConsecutiveFailures != providerFailureThreshold) {
+			t.Errorf("primary should be unhealthy, got %+v", h)
+		}
+		if h.Name == "backup" && (!h.Healthy || h.TotalRequests != providerFailureThreshold+1) {
+			t.Errorf("backup should be healthy, got %+v", h)
+		}
+	}
+
+	// Range queries skip providers without range support
+	if _, err := r.GetKlinesRange("BTC", "1h", time.Now().Add(-time.Hour), time.Now()); err == nil ||
+		!strings.Contains(err.Error(), "supports this query") {
+		t.Errorf("expected unsupported range error, got %v", err)
+	}
+
+	// No route for asset class
+	if _, err := r.GetKlines("stock:AAPL", "1h", 10); err == nil {
+		t.Error("expected error for asset class without providers")
+	}
+}
+
+// TestClassifyAndNormalizeSymbol tests asset class routing and symbol normalization
+func TestClassifyAndNormalizeSymbol(t *testing.T) {
+	tests := []struct {
+		input      string
+		normalized string
+		class      AssetClass
+	}{
+		{"btc", "BTCUSDT", AssetCrypto},
+		{"ETHUSDT", "ETHUSDT", AssetCrypto},
+		{"TSLA", "xyz:TSLA", AssetXyzDex},
+		{"stock:aapl", "stock:AAPL", AssetStock},
+		{"STOCK:msft", "stock:MSFT", AssetStock},
+		{"fx:eurusd", "fx:EUR/USD", AssetForex},
+		{"fx:gbp/jpy", "fx:GBP/JPY", AssetForex},
+	}
+
+	for _, tt := range tests {
+		normalized := Normalize(tt.input)
+		if normalized != tt.normalized {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, normalized, tt.normalized)
+		}
+		if class := ClassifySymbol(normalized); class != tt.class {
+			t.Errorf("ClassifySymbol(%q) = %s, want %s", normalized, class, tt.class)
+		}
+	}
+
+	if got := symbolTicker("fx:EUR/USD"); got != "EUR/USD" {
+		t.Errorf("symbolTicker = %q, want EUR/USD", got)
+	}
+}
+
+// TestPageKlinesRange tests that capped range APIs are paged forward until the end of the range
+func TestPageKlinesRange(t *testing.T) {
+	const step, total, limit = 60_000, 12, 5
+	var requests int
+	fetch := func(startMs, endMs int64) ([]Kline, error) {
+		requests++
+		var batch []Kline
+		for open := startMs - startMs%step; open <= endMs && len(batch) < limit; open += step {
+			if open/step < total {
+				batch = append(batch, Kline{OpenTime: open, Close: float64(open / step)})
+			}
+		}
+		return batch, nil
+	}
+
+	klines, err := pageKlinesRange(0, 20*step, limit, fetch)
+	if err != nil {
+		t.Fatalf("pageKlinesRange failed: %v", err)
+	}
+	if len(klines) != total || requests != 3 {
+		t.Fatalf("expected %d klines in 3 requests, got %d in %d", total, len(klines), requests)
+	}
+	for i, k := range klines {
+		if k.OpenTime != int64(i)*step {
+			t.Fatalf("kline %d opens at %d, want %d", i, k.OpenTime, int64(i)*step)
+		}
+	}
+}
@@ -107,6 +107,30 @@ type Kline struct {
 
 type KlineResponse []interface{}
 
+// DepthResponse Binance order book response, levels are [price, quantity] strings
+type DepthResponse struct {
+	LastUpdateID int64       `json:"lastUpdateId"`
+	EventTime    int64       `json:"E"`
+	Bids         [][2]string `json:"bids"`
+	Asks         [][2]string `json:"asks"`
+}
+
+// FundingRateResponse Binance funding rate history entry
+type FundingRateResponse struct {
+	Symbol      string `json:"symbol"`
+	FundingRate string `json:"fundingRate"`
+	FundingTime int64  `json:"fundingTime"`
+	MarkPrice   string `json:"markPrice"`
+}
+
+// FundingRate settled funding rate of a perpetual
+type FundingRate struct {
+	Symbol      string  `json:"symbol"`
+	FundingRate float64 `json:"funding_rate"`
+	FundingTime int64   `json:"funding_time"` // Unix milliseconds
+	MarkPrice   float64 `json:"mark_price,omitempty"`
+}
+
 type PriceTicker struct {
 	Symbol string `json:"symbol"`
 	Price  string `json:"price"`
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"nofx/logger"
+	"nofx/metrics"
 	"nofx/provider/coinank"
 	"nofx/provider/coinank/coinank_api"
 	"nofx/provider/coinank/coinank_enum"
@@ -124,6 +125,7 @@ func (m *WebSocketPriceMonitor) connect() error {
 // reconnect 重新连接WebSocket
 func (m *WebSocketPriceMonitor) reconnect() error {
 	logger.Warnf("🔄 尝试重新连接WebSocket (延迟: %v)", m.reconnectDelay)
+	metrics.WebsocketReconnects.Inc("market", "coinank")
 	time.Sleep(m.reconnectDelay)
 
 	if m.ws != nil {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -93,11 +94,122 @@ func (c *ClaudeClient) buildMCPRequestBody(systemPrompt, userPrompt string) map[
 }
 
 // parseMCPResponse Claude has different response format
+// The input of a tool_use block is returned when there is one (structured output), the text otherwise
 func (c *ClaudeClient) parseMCPResponse(body []byte) (string, error) {
+	resp, err := c.parseToolResponse(body)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.ToolCalls) > 0 {
+		return resp.ToolCalls[0].Function.Arguments, nil
+	}
+	if resp.Content == "" {
+		return "", fmt.Errorf("no text content in Claude response, body: %s", string(body))
+	}
+	return resp.Content, nil
+}
+
+// buildRequestBodyFromRequest Claude takes the system prompt separately, tool calls as tool_use blocks and
+// tool results as tool_result blocks of a user message
+func (c *ClaudeClient) buildRequestBodyFromRequest(req *Request) map[string]any {
+	var system []string
+	messages := make([]map[string]any, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		switch {
+		case msg.Role == "system":
+			system = append(system, msg.Content)
+		case msg.Role == "tool":
+			block := map[string]any{"type": "tool_result", "tool_use_id": msg.ToolCallID, "content": msg.Content}
+			// Results of one assistant turn go back in a single user message
+			if n := len(messages); n > 0 && messages[n-1]["role"] == "user" {
+				if blocks, ok := messages[n-1]["content"].([]map[string]any); ok {
+					messages[n-1]["content"] = append(blocks, block)
+					continue
+				}
+			}
+			messages = append(messages, map[string]any{"role": "user", "content": []map[string]any{block}})
+		case len(msg.ToolCalls) > 0:
+			blocks := make([]map[string]any, 0, len(msg.ToolCalls)+1)
+			if msg.Content != "" {
+				blocks = append(blocks, map[string]any{"type": "text", "text": msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if !json.Valid(input) {
+					input = json.RawMessage("{}")
+				}
+				blocks = append(blocks, map[string]any{"type": "tool_use", "id": call.ID, "name": call.Function.Name, "input": input})
+			}
+			messages = append(messages, map[string]any{"role": "assistant", "content": blocks})
+		default:
+			messages = append(messages, map[string]any{"role": msg.Role, "content": msg.Content})
+		}
+	}
+
+	requestBody := map[string]any{
+		"model":      req.Model,
+		"max_tokens": c.MaxTokens,
+		"messages":   messages,
+	}
+	if len(system) > 0 {
+		requestBody["system"] = strings.Join(system, "\n\n")
+	}
+	if req.MaxTokens != nil {
+		requestBody["max_tokens"] = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		requestBody["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		requestBody["top_p"] = *req.TopP
+	}
+	if len(req.Stop) > 0 {
+		requestBody["stop_sequences"] = req.Stop
+	}
+	if len(req.Tools) > 0 {
+		tools := make([]map[string]any, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			schema := tool.Function.Parameters
+			if schema == nil {
+				schema = map[string]any{"type": "object", "properties": map[string]any{}}
+			}
+			tools = append(tools, map[string]any{
+				"name":         tool.Function.Name,
+				"description":  tool.Function.Description,
+				"input_schema": schema,
+			})
+		}
+		requestBody["tools"] = tools
+	}
+	switch req.ToolChoice {
+	case "auto", "none":
+		requestBody["tool_choice"] = map[string]any{"type": req.ToolChoice}
+	case "required":
+		requestBody["tool_choice"] = map[string]any{"type": "any"}
+	}
+
+	// Structured output: force a tool whose input is the answer (not combined with other tools)
+	if rs := req.ResponseSchema; rs != nil && len(req.Tools) == 0 {
+		requestBody["tools"] = []map[string]any{{
+			"name":         rs.Name,
+			"description":  rs.Description,
+			"input_schema": rs.Schema,
+		}}
+		requestBody["tool_choice"] = map[string]any{"type": "tool", "name": rs.Name}
+	}
+
+	return requestBody
+}
+
+// parseToolResponse Claude returns tool calls as tool_use content blocks
+func (c *ClaudeClient) parseToolResponse(body []byte) (*ToolResponse, error) {
 	var response struct {
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			ID    string          `json:"id"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
 		} `json:"content"`
 		Usage struct {
 			InputTokens  int `json:"input_tokens"`
@@ -110,15 +222,11 @@ func (c *ClaudeClient) parseMCPResponse(body []byte) (string, error) {
 	}
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return "", fmt.Errorf("failed to parse Claude response: %w, body: %s", err, string(body))
+		return nil, fmt.Errorf("failed to parse Claude response: %w, body: %s", err, string(body))
 	}
 
 	if response.Error != nil {
-		return "", fmt.Errorf("Claude API error: %s - %s", response.Error.Type, response.Error.Message)
-	}
-
-	if len(response.Content) == 0 {
-		return "", fmt.Errorf("Claude returned empty content, body: %s", string(body))
+		return nil, fmt.Errorf("Claude API error: %s - %s", response.Error.Type, response.Error.Message)
 	}
 
 	// Report token usage if callback is set
@@ -133,12 +241,20 @@ func (c *ClaudeClient) parseMCPResponse(body []byte) (string, error) {
 		})
 	}
 
-	// Find text content
+	resp := &ToolResponse{}
+	var text []string
 	for _, content := range response.Content {
-		if content.Type == "text" {
-			return content.Text, nil
+		switch content.Type {
+		case "text":
+			text = append(text, content.Text)
+		case "tool_use":
+			resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+				ID:       content.ID,
+				Type:     "function",
+				Function: ToolCallFunction{Name: content.Name, Arguments: toolArguments(content.Input)},
+			})
 		}
 	}
-
-	return "", fmt.Errorf("no text content in Claude response")
+	resp.Content = strings.Join(text, "")
+	return resp, nil
 }
@@ -34,6 +34,9 @@ var (
 
 	// TokenUsageCallback is called after each AI request with token usage info
 	TokenUsageCallback func(usage TokenUsage)
+
+	// RequestCallback is called after each single AI request attempt with its latency and error
+	RequestCallback func(provider string, latency time.Duration, err error)
 )
 
 // TokenUsage represents token usage from AI API response
@@ -148,7 +151,7 @@ func (client *Client) SetTimeout(timeout time.Duration) {
 
 // CallWithMessages template method - fixed retry flow (cannot be overridden)
 func (client *Client) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
-	if client.APIKey == "" {
+	if client.APIKey == "" && client.hooks.requiresAPIKey() {
 		return "", fmt.Errorf("AI API key not set, please call SetAPIKey first")
 	}
 
@@ -162,7 +165,9 @@ func (client *Client) CallWithMessages(systemPrompt, userPrompt string) (string,
 		}
 
 		// Call the fixed single-call flow
+		start := time.Now()
 		result, err := client.hooks.call(systemPrompt, userPrompt)
+		client.reportRequest(time.Since(start), err)
 		if err == nil {
 			if attempt > 1 {
 				client.logger.Infof("✓ AI API retry succeeded")
@@ -171,6 +176,12 @@ func (client *Client) CallWithMessages(systemPrompt, userPrompt string) (string,
 		}
 
 		lastErr = err
+		if limited, retry := client.rateLimitRetry(err, attempt); limited {
+			if retry {
+				continue
+			}
+			return "", err
+		}
 		// Check if error is retryable via hooks (supports custom retry strategy in subclass)
 		if !client.hooks.isRetryableError(err) {
 			return "", err
@@ -233,39 +244,11 @@ func (client *Client) marshalRequestBody(requestBody map[string]any) ([]byte, er
 }
 
 func (client *Client) parseMCPResponse(body []byte) (string, error) {
-	var result struct {
-		Choices []struct {
-			Message struct {
-				Content string `json:"content"`
-			} `json:"message"`
-		} `json:"choices"`
-		Usage struct {
-			PromptTokens     int `json:"prompt_tokens"`
-			CompletionTokens int `json:"completion_tokens"`
-			TotalTokens      int `json:"total_tokens"`
-		} `json:"usage"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if len(result.Choices) == 0 {
-		return "", fmt.Errorf("API returned empty response")
-	}
-
-	// Report token usage if callback is set
-	if TokenUsageCallback != nil && result.Usage.TotalTokens > 0 {
-		TokenUsageCallback(TokenUsage{
-			Provider:         client.Provider,
-			Model:            client.Model,
-			PromptTokens:     result.Usage.PromptTokens,
-			CompletionTokens: result.Usage.CompletionTokens,
-			TotalTokens:      result.Usage.TotalTokens,
-		})
+	turn, err := client.parseToolResponse(body)
+	if err != nil {
+		return "", err
 	}
-
-	return result.Choices[0].Message.Content, nil
+	return turn.Content, nil
 }
 
 func (client *Client) buildUrl() string {
@@ -318,7 +301,9 @@ func (client *Client) call(systemPrompt, userPrompt string) (string, error) {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Step 5: Send HTTP request (fixed logic)
+	// Step 5: Send HTTP request within the API key's concurrency limit (fixed logic)
+	release := client.acquireSlot()
+	defer release()
 	resp, err := client.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
@@ -333,7 +318,7 @@ func (client *Client) call(systemPrompt, userPrompt string) (string, error) {
 
 	// Step 7: Check HTTP status code (fixed logic)
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned error (status %d): %s", resp.StatusCode, string(body))
+		return "", client.statusError(resp, body)
 	}
 
 	// Step 8: Parse response (via hooks for dynamic dispatch)
@@ -350,6 +335,13 @@ func (client *Client) String() string {
 		client.Provider, client.Model)
 }
 
+// reportRequest reports one request attempt to RequestCallback
+func (client *Client) reportRequest(latency time.Duration, err error) {
+	if RequestCallback != nil {
+		RequestCallback(client.Provider, latency, err)
+	}
+}
+
 // isRetryableError determines if error is retryable (network errors, timeouts, etc.)
 func (client *Client) isRetryableError(err error) bool {
 	errStr := err.Error()
@@ -362,6 +354,11 @@ func (client *Client) isRetryableError(err error) bool {
 	return false
 }
 
+// requiresAPIKey reports whether calls need an API key (hosted providers do, local servers do not)
+func (client *Client) requiresAPIKey() bool {
+	return true
+}
+
 // ============================================================
 // Builder Pattern API (Advanced Features)
 // ============================================================
@@ -383,7 +380,7 @@ func (client *Client) isRetryableError(err error) bool {
 //	    Build()
 //	result, err := client.CallWithRequest(request)
 func (client *Client) CallWithRequest(req *Request) (string, error) {
-	if client.APIKey == "" {
+	if client.APIKey == "" && client.hooks.requiresAPIKey() {
 		return "", fmt.Errorf("AI API key not set, please call SetAPIKey first")
 	}
 
@@ -402,7 +399,9 @@ func (client *Client) CallWithRequest(req *Request) (string, error) {
 		}
 
 		// Call single request
+		start := time.Now()
 		result, err := client.callWithRequest(req)
+		client.reportRequest(time.Since(start), err)
 		if err == nil {
 			if attempt > 1 {
 				client.logger.Infof("✓ AI API retry succeeded")
@@ -411,6 +410,12 @@ func (client *Client) CallWithRequest(req *Request) (string, error) {
 		}
 
 		lastErr = err
+		if limited, retry := client.rateLimitRetry(err, attempt); limited {
+			if retry {
+				continue
+			}
+			return "", err
+		}
 		// Check if error is retryable
 		if !client.hooks.isRetryableError(err) {
 			return "", err
@@ -433,13 +438,27 @@ func (client *Client) callWithRequest(req *Request) (string, error) {
 	client.logger.Infof("📡 [%s] Request AI Server with Builder: BaseURL: %s", client.String(), client.BaseURL)
 	client.logger.Debugf("[%s] Messages count: %d", client.String(), len(req.Messages))
 
-	// Build request body (from Request object)
-	requestBody := client.buildRequestBodyFromRequest(req)
+	// Build request body from Request object (via hooks for dynamic dispatch)
+	body, err := client.send(client.hooks.buildRequestBodyFromRequest(req))
+	if err != nil {
+		return "", err
+	}
+
+	// Parse response
+	result, err := client.hooks.parseMCPResponse(body)
+	if err != nil {
+		return "", fmt.Errorf("fail to parse AI server response: %w", err)
+	}
+
+	return result, nil
+}
 
+// send serializes a request body, posts it and returns the body of a successful response
+func (client *Client) send(requestBody map[string]any) ([]byte, error) {
 	// Serialize request body
 	jsonData, err := client.hooks.marshalRequestBody(requestBody)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Build URL
@@ -449,45 +468,48 @@ func (client *Client) callWithRequest(req *Request) (string, error) {
 	// Create HTTP request
 	httpReq, err := client.hooks.buildRequest(url, jsonData)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Send HTTP request
+	// Send HTTP request within the API key's concurrency limit
+	release := client.acquireSlot()
+	defer release()
 	resp, err := client.httpClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Check HTTP status code
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API returned error (status %d): %s", resp.StatusCode, string(body))
+		return nil, client.statusError(resp, body)
 	}
 
-	// Parse response
-	result, err := client.hooks.parseMCPResponse(body)
-	if err != nil {
-		return "", fmt.Errorf("fail to parse AI server response: %w", err)
-	}
-
-	return result, nil
+	return body, nil
 }
 
 // buildRequestBodyFromRequest builds request body from Request object
 func (client *Client) buildRequestBodyFromRequest(req *Request) map[string]any {
 	// Convert Message to API format
-	messages := make([]map[string]string, 0, len(req.Messages))
+	messages := make([]map[string]any, 0, len(req.Messages))
 	for _, msg := range req.Messages {
-		messages = append(messages, map[string]string{
+		message := map[string]any{
 			"role":    msg.Role,
 			"content": msg.Content,
-		})
+		}
+		if len(msg.ToolCalls) > 0 {
+			message["tool_calls"] = msg.ToolCalls
+		}
+		if msg.ToolCallID != "" {
+			message["tool_call_id"] = msg.ToolCallID
+		}
+		messages = append(messages, message)
 	}
 
 	// Build basic request body
@@ -540,6 +562,10 @@ func (client *Client) buildRequestBodyFromRequest(req *Request) map[string]any {
 		requestBody["tool_choice"] = req.ToolChoice
 	}
 
+	if req.ResponseSchema != nil && client.SupportsStructuredOutput() {
+		requestBody["response_format"] = client.responseFormat(req.ResponseSchema)
+	}
+
 	if req.Stream {
 		requestBody["stream"] = true
 	}
@@ -30,6 +30,9 @@ type Config struct {
 	// Timeout configuration
 	Timeout time.Duration
 
+	// In-flight requests per API key, shared by all clients using the key (0 = unlimited)
+	MaxConcurrentPerKey int
+
 	// Dependency injection
 	Logger     Logger
 	HTTPClient *http.Client
@@ -45,6 +48,7 @@ func DefaultConfig() *Config {
 		RetryWaitBase:  2 * time.Second,
 		Timeout:        DefaultTimeout,
 		RetryableErrors: retryableErrors,
+		MaxConcurrentPerKey: getEnvInt("AI_MAX_CONCURRENT_PER_KEY", 4),
 
 		// Default dependencies (use global logger)
 		Logger:     logger.NewMCPLogger(),
@@ -0,0 +1,350 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	breakerFailureThreshold = 3                // Consecutive failed calls that open a provider's circuit
+	breakerCooldown         = 60 * time.Second // How long an open circuit skips the provider
+)
+
+// ModelIdentifier clients that can tell which model answered their latest call
+type ModelIdentifier interface {
+	AnsweredBy() string
+}
+
+// CallScoper clients that hand out a scope for one caller's calls, so that what a scope reports
+// (e.g. AnsweredBy) is never another caller's answer
+type CallScoper interface {
+	NewCall() AIClient
+}
+
+// AnsweredBy provider and model of the client, e.g. "deepseek/deepseek-chat"
+func (client *Client) AnsweredBy() string {
+	return client.Provider + "/" + client.Model
+}
+
+// baseClient the Client a provider client is built on
+func (client *Client) baseClient() *Client {
+	return client
+}
+
+// NewProviderClient creates the client of a provider by name; unknown names get the generic
+// OpenAI-compatible client
+func NewProviderClient(provider string) AIClient {
+	switch provider {
+	case ProviderDeepSeek:
+		return NewDeepSeekClient()
+	case ProviderQwen:
+		return NewQwenClient()
+	case ProviderOpenAI:
+		return NewOpenAIClient()
+	case ProviderClaude:
+		return NewClaudeClient()
+	case ProviderGemini:
+		return NewGeminiClient()
+	case ProviderGrok:
+		return NewGrokClient()
+	case ProviderKimi:
+		return NewKimiClient()
+	case ProviderOllama:
+		return NewOllamaClient()
+	case ProviderLocal:
+		return NewLocalClient()
+	}
+	return New()
+}
+
+// ============================================================
+// Circuit Breakers
+// ============================================================
+
+// circuitBreaker skips a provider endpoint after repeated failures or a long rate limit. Breakers are
+// shared by every failover chain in the process, so one trader's failures spare the others the wait.
+// After the cooldown the circuit is half-open: a single probe call decides whether it closes or opens again.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time // Zero while the circuit is closed
+	probing   bool      // A half-open probe is in flight
+}
+
+var breakers = struct {
+	sync.Mutex
+	m map[string]*circuitBreaker
+}{m: make(map[string]*circuitBreaker)}
+
+// breakerFor the breaker of a provider endpoint and API key (rate limits and quotas are per key)
+func breakerFor(client AIClient) *circuitBreaker {
+	key := fmt.Sprintf("%T", client)
+	if b, ok := client.(interface{ baseClient() *Client }); ok {
+		base := b.baseClient()
+		key = base.Provider + " " + base.BaseURL + " " + keyHash(base.APIKey)
+	}
+
+	breakers.Lock()
+	defer breakers.Unlock()
+	breaker, ok := breakers.m[key]
+	if !ok {
+		breaker = &circuitBreaker{}
+		breakers.m[key] = breaker
+	}
+	return breaker
+}
+
+// allow reports whether the endpoint may be called: the circuit is closed, or its cooldown is over and
+// no other probe is in flight (the caller's call is the probe, closing the circuit again on success)
+func (b *circuitBreaker) allow(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openUntil.IsZero() {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+	b.probing = false
+}
+
+// failure counts a failed call; a rate limit opens the circuit until its Retry-After right away.
+// Only provider-side failures count, a rejected request (4xx) says nothing about the endpoint's health.
+// A failed probe opens the circuit for another cooldown.
+func (b *circuitBreaker) failure(err error, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := b.probing
+	b.probing = false
+	if !breakerCounts(err) {
+		return
+	}
+	b.failures++
+
+	var rl *RateLimitError
+	if errors.As(err, &rl) {
+		wait := rl.RetryAfter
+		if wait == 0 {
+			wait = breakerCooldown
+		}
+		b.openUntil = now.Add(wait)
+		return
+	}
+	if probe || b.failures >= breakerFailureThreshold {
+		b.openUntil = now.Add(breakerCooldown)
+	}
+}
+
+// breakerCounts reports whether a failed call counts against the endpoint: 429, 5xx and timeouts
+func breakerCounts(err error) bool {
+	var rl *RateLimitError
+	if errors.As(err, &rl) {
+		return true
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// ============================================================
+// Failover Client
+// ============================================================
+
+// FailoverClient ordered chain of AI models: each call goes to the first model whose circuit is closed
+// and moves down the chain when it fails
+type FailoverClient struct {
+	clients []AIClient
+	logger  Logger
+}
+
+// NewFailoverClient creates a failover chain; the first client is the primary model
+func NewFailoverClient(clients ...AIClient) *FailoverClient {
+	return &FailoverClient{
+		clients: clients,
+		logger:  DefaultConfig().Logger,
+	}
+}
+
+// SetAPIKey configures the primary model
+func (f *FailoverClient) SetAPIKey(apiKey string, customURL string, customModel string) {
+	if len(f.clients) > 0 {
+		f.clients[0].SetAPIKey(apiKey, customURL, customModel)
+	}
+}
+
+func (f *FailoverClient) SetTimeout(timeout time.Duration) {
+	for _, client := range f.clients {
+		client.SetTimeout(timeout)
+	}
+}
+
+func (f *FailoverClient) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	result, _, err := f.callWithMessages(systemPrompt, userPrompt)
+	return result, err
+}
+
+func (f *FailoverClient) CallWithRequest(req *Request) (string, error) {
+	result, _, err := f.callWithRequest(req)
+	return result, err
+}
+
+// CallWithTools only models that support tool calling are tried
+func (f *FailoverClient) CallWithTools(req *Request, execute ToolExecutor, budget int) (string, error) {
+	result, _, err := f.callWithTools(req, execute, budget)
+	return result, err
+}
+
+func (f *FailoverClient) callWithMessages(systemPrompt, userPrompt string) (string, AIClient, error) {
+	return f.call(func(client AIClient) (string, error) {
+		return client.CallWithMessages(systemPrompt, userPrompt)
+	})
+}
+
+func (f *FailoverClient) callWithRequest(req *Request) (string, AIClient, error) {
+	return f.call(func(client AIClient) (string, error) {
+		// Clients fill in their own model, each one gets a fresh copy
+		attempt := *req
+		return client.CallWithRequest(&attempt)
+	})
+}
+
+// callWithTools the budget is the call's, not each model's: a fallback gets what the failed attempts left
+func (f *FailoverClient) callWithTools(req *Request, execute ToolExecutor, budget int) (string, AIClient, error) {
+	used := 0
+	shared := func(call ToolCall) (string, error) {
+		if used >= budget {
+			return "", fmt.Errorf("tool budget of %d calls exhausted", budget)
+		}
+		used++
+		return execute(call)
+	}
+	return f.call(func(client AIClient) (string, error) {
+		toolCaller, ok := client.(ToolCaller)
+		if !ok {
+			return "", fmt.Errorf("%s does not support tool calling", answeredBy(client))
+		}
+		attempt := *req
+		return toolCaller.CallWithTools(&attempt, shared, budget-used)
+	})
+}
+
+// SupportsStructuredOutput reports whether any model of the chain enforces a response schema; the others
+// ignore it and are parsed as text
+func (f *FailoverClient) SupportsStructuredOutput() bool {
+	for _, client := range f.clients {
+		if sc, ok := client.(StructuredOutputCaller); ok && sc.SupportsStructuredOutput() {
+			return true
+		}
+	}
+	return false
+}
+
+// NewCall a scope of the chain for one caller, e.g. one trading cycle; it reports the model that answered
+// its own calls. The chain is shared between goroutines, a scope is not.
+func (f *FailoverClient) NewCall() AIClient {
+	return &FailoverCall{FailoverClient: f}
+}
+
+// call tries the chain in order and returns the answer with the model that gave it; when
+// every other circuit is open the last model is tried anyway so that a call never fails without reaching
+// a model
+func (f *FailoverClient) call(do func(client AIClient) (string, error)) (string, AIClient, error) {
+	var errs []string
+	tried := 0
+	for i, client := range f.clients {
+		name := answeredBy(client)
+		breaker := breakerFor(client)
+		if !breaker.allow(time.Now()) && !(tried == 0 && i == len(f.clients)-1) {
+			f.logger.Infof("⏭️  [Failover] Skipping %s: circuit open", name)
+			errs = append(errs, name+": circuit open")
+			continue
+		}
+
+		tried++
+		result, err := do(client)
+		if err == nil {
+			breaker.success()
+			if i > 0 {
+				f.logger.Infof("🔀 [Failover] Answered by fallback model %s", name)
+			}
+			return result, client, nil
+		}
+
+		breaker.failure(err, time.Now())
+		f.logger.Warnf("⚠️  [Failover] %s failed: %v", name, err)
+		errs = append(errs, name+": "+err.Error())
+	}
+	return "", nil, fmt.Errorf("all AI models failed: %s", strings.Join(errs, "; "))
+}
+
+// FailoverCall one caller's scope of a FailoverClient, see NewCall
+type FailoverCall struct {
+	*FailoverClient
+	answered AIClient // Model that answered the scope's latest successful call
+}
+
+func (c *FailoverCall) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return c.record(c.callWithMessages(systemPrompt, userPrompt))
+}
+
+func (c *FailoverCall) CallWithRequest(req *Request) (string, error) {
+	return c.record(c.callWithRequest(req))
+}
+
+func (c *FailoverCall) CallWithTools(req *Request, execute ToolExecutor, budget int) (string, error) {
+	return c.record(c.callWithTools(req, execute, budget))
+}
+
+func (c *FailoverCall) record(result string, client AIClient, err error) (string, error) {
+	if err == nil {
+		c.answered = client
+	}
+	return result, err
+}
+
+// latest the model that answered the scope's latest successful call (the primary model before any)
+func (c *FailoverCall) latest() AIClient {
+	if c.answered == nil && len(c.clients) > 0 {
+		return c.clients[0]
+	}
+	return c.answered
+}
+
+// AnsweredBy the model that answered the scope's latest successful call (the primary model before any)
+func (c *FailoverCall) AnsweredBy() string {
+	if client := c.latest(); client != nil {
+		return answeredBy(client)
+	}
+	return ""
+}
+
+// EnforcesSchema whether the model that answered the scope's latest call enforced req's schema
+func (c *FailoverCall) EnforcesSchema(req *Request) bool {
+	se, ok := c.latest().(SchemaEnforcer)
+	return ok && se.EnforcesSchema(req)
+}
+
+// answeredBy name of a client's model for logs and decision records
+func answeredBy(client AIClient) string {
+	if id, ok := client.(ModelIdentifier); ok {
+		return id.AnsweredBy()
+	}
+	return fmt.Sprintf("%T", client)
+}
@@ -0,0 +1,273 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// ============================================================
+// Test Failover and Rate Limits
+// ============================================================
+
+func newFailoverTestClient(baseURL string, status int, header http.Header, calls *int) *Client {
+	mockHTTP := NewMockHTTPClient()
+	mockHTTP.ResponseFunc = func(req *http.Request) (*http.Response, error) {
+		*calls++
+		body := `{"choices":[{"message":{"content":"answer from ` + baseURL + `"}}]}`
+		if status != http.StatusOK {
+			body = `{"error":"slow down"}`
+		}
+		if header == nil {
+			header = make(http.Header)
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(bytes.NewBufferString(body)),
+			Header:     header,
+		}, nil
+	}
+	return NewClient(
+		WithHTTPClient(mockHTTP.ToHTTPClient()),
+		WithLogger(NewMockLogger()),
+		WithProvider(ProviderCustom),
+		WithAPIKey("key-"+baseURL),
+		WithBaseURL(baseURL),
+		WithModel("model-"+baseURL),
+		WithRetryWaitBase(time.Millisecond),
+	).(*Client)
+}
+
+func TestFailoverClient_RateLimitedPrimary(t *testing.T) {
+	var primaryCalls, fallbackCalls int
+	primary := newFailoverTestClient("https://primary.test", http.StatusTooManyRequests, http.Header{"Retry-After": []string{"120"}}, &primaryCalls)
+	fallback := newFailoverTestClient("https://fallback.test", http.StatusOK, nil, &fallbackCalls)
+	chain := NewFailoverClient(primary, fallback)
+	chain.logger = NewMockLogger()
+
+	call := chain.NewCall().(*FailoverCall)
+	if call.AnsweredBy() != primary.AnsweredBy() {
+		t.Errorf("a scope without answers should report the primary, got %s", call.AnsweredBy())
+	}
+	result, err := call.CallWithMessages("system", "user")
+	if err != nil {
+		t.Fatalf("fallback should answer: %v", err)
+	}
+	if result != "answer from https://fallback.test" || call.AnsweredBy() != fallback.AnsweredBy() {
+		t.Errorf("unexpected answer %q by %s", result, call.AnsweredBy())
+	}
+	if primaryCalls != 1 {
+		t.Errorf("a long Retry-After should not be waited out, got %d primary calls", primaryCalls)
+	}
+
+	// The primary's circuit stays open until its Retry-After
+	if _, err := chain.CallWithRequest(NewRequestBuilder().WithUserPrompt("again").MustBuild()); err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+	if primaryCalls != 1 || fallbackCalls != 2 {
+		t.Errorf("open circuit should be skipped: primary %d, fallback %d calls", primaryCalls, fallbackCalls)
+	}
+}
+
+func TestFailoverClient_AllFailed(t *testing.T) {
+	var calls int
+	only := newFailoverTestClient("https://only.test", http.StatusInternalServerError, nil, &calls)
+	chain := NewFailoverClient(only)
+	chain.logger = NewMockLogger()
+
+	for i := 0; i < breakerFailureThreshold+1; i++ {
+		if _, err := chain.CallWithMessages("system", "user"); err == nil {
+			t.Fatal("should fail")
+		}
+	}
+	if calls != breakerFailureThreshold+1 {
+		t.Errorf("the last model of a chain is tried even with an open circuit, got %d calls", calls)
+	}
+}
+
+func TestFailoverClient_ClientErrorsKeepCircuitClosed(t *testing.T) {
+	var calls int
+	rejecting := newFailoverTestClient("https://rejecting.test", http.StatusBadRequest, nil, &calls)
+	chain := NewFailoverClient(rejecting, newFailoverTestClient("https://spare.test", http.StatusOK, nil, new(int)))
+	chain.logger = NewMockLogger()
+
+	for i := 0; i < breakerFailureThreshold+1; i++ {
+		if _, err := chain.CallWithMessages("system", "user"); err != nil {
+			t.Fatalf("fallback should answer: %v", err)
+		}
+	}
+	if calls != breakerFailureThreshold+1 {
+		t.Errorf("4xx responses should not open the circuit, got %d calls", calls)
+	}
+}
+
+func TestBreakerCounts(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limit", &RateLimitError{}, true},
+		{"server error", fmt.Errorf("still failed after 3 retries: %w", &StatusError{StatusCode: 503}), true},
+		{"bad request", &StatusError{StatusCode: 400}, false},
+		{"unauthorized", &StatusError{StatusCode: 401}, false},
+		{"timeout", fmt.Errorf("failed to send request: %w", context.DeadlineExceeded), true},
+		{"parse error", errors.New("fail to parse AI server response"), false},
+	}
+	for _, tt := range tests {
+		if got := breakerCounts(tt.err); got != tt.want {
+			t.Errorf("%s: breakerCounts() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBreakerFor_PerAPIKey(t *testing.T) {
+	a := newFailoverTestClient("https://shared.test", http.StatusOK, nil, new(int))
+	b := newFailoverTestClient("https://shared.test", http.StatusOK, nil, new(int))
+	b.APIKey = "another-key"
+	if breakerFor(a) == breakerFor(b) {
+		t.Error("keys on the same endpoint should not share a circuit")
+	}
+	c := newFailoverTestClient("https://shared.test", http.StatusOK, nil, new(int))
+	if breakerFor(a) != breakerFor(c) {
+		t.Error("clients with the same key should share a circuit")
+	}
+}
+
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	b := &circuitBreaker{}
+	now := time.Now()
+	serverErr := &StatusError{StatusCode: 503}
+	for i := 0; i < breakerFailureThreshold; i++ {
+		b.failure(serverErr, now)
+	}
+	if b.allow(now) {
+		t.Fatal("the circuit should be open")
+	}
+
+	// After the cooldown only one probe goes through
+	now = now.Add(breakerCooldown)
+	if !b.allow(now) {
+		t.Fatal("a probe should be allowed after the cooldown")
+	}
+	if b.allow(now) {
+		t.Error("only one probe should be in flight")
+	}
+
+	// A failed probe opens the circuit for another cooldown
+	b.failure(serverErr, now)
+	if b.allow(now.Add(breakerCooldown - time.Second)) {
+		t.Error("a failed probe should open the circuit again")
+	}
+
+	// A successful probe closes it
+	now = now.Add(breakerCooldown)
+	if !b.allow(now) {
+		t.Fatal("a probe should be allowed after the second cooldown")
+	}
+	b.success()
+	if !b.allow(now) || !b.allow(now) {
+		t.Error("a successful probe should close the circuit")
+	}
+}
+
+func TestClient_RateLimitError(t *testing.T) {
+	var calls int
+	client := newFailoverTestClient("https://limited.test", http.StatusTooManyRequests, http.Header{"Retry-After": []string{"0"}}, &calls)
+
+	_, err := client.CallWithMessages("system", "user")
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		t.Fatalf("expected a RateLimitError, got %v", err)
+	}
+	if calls != client.config.MaxRetries {
+		t.Errorf("short rate limits should be retried on the same endpoint, got %d calls", calls)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d := parseRetryAfter("30", now); d != 30*time.Second {
+		t.Errorf("expected 30s, got %v", d)
+	}
+	if d := parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now); d != time.Minute {
+		t.Errorf("expected 1m, got %v", d)
+	}
+}
+
+func TestClient_AcquireSlot(t *testing.T) {
+	a := NewClient(WithLogger(NewMockLogger()), WithAPIKey("shared-key"), WithMaxConcurrentPerKey(1)).(*Client)
+	b := NewClient(WithLogger(NewMockLogger()), WithAPIKey("shared-key"), WithMaxConcurrentPerKey(1)).(*Client)
+
+	release := a.acquireSlot()
+	acquired := make(chan struct{})
+	go func() {
+		defer b.acquireSlot()()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("clients sharing an API key should share its slots")
+	case <-time.After(50 * time.Millisecond):
+	}
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("slot should be acquired once released")
+	}
+}
+
+// budgetToolCaller tool-calling model that executes toolCalls tools, then answers or fails with err
+type budgetToolCaller struct {
+	toolCalls int
+	err       error
+	budget    int // Budget it was called with
+	executed  int
+}
+
+func (c *budgetToolCaller) SetAPIKey(apiKey, customURL, customModel string) {}
+func (c *budgetToolCaller) SetTimeout(timeout time.Duration)                {}
+func (c *budgetToolCaller) CallWithMessages(systemPrompt, userPrompt string) (string, error) {
+	return "", c.err
+}
+func (c *budgetToolCaller) CallWithRequest(req *Request) (string, error) { return "", c.err }
+
+func (c *budgetToolCaller) CallWithTools(req *Request, execute ToolExecutor, budget int) (string, error) {
+	c.budget = budget
+	for i := 0; i < c.toolCalls; i++ {
+		if _, err := execute(ToolCall{ID: fmt.Sprint(i)}); err == nil {
+			c.executed++
+		}
+	}
+	if c.err != nil {
+		return "", c.err
+	}
+	return "answer", nil
+}
+
+func TestFailoverClient_SharedToolBudget(t *testing.T) {
+	primary := &budgetToolCaller{toolCalls: 2, err: &StatusError{StatusCode: 503}}
+	fallback := &budgetToolCaller{toolCalls: 2}
+	chain := NewFailoverClient(primary, fallback)
+	chain.logger = NewMockLogger()
+
+	executed := 0
+	result, err := chain.CallWithTools(NewRequestBuilder().WithUserPrompt("decide").MustBuild(), func(ToolCall) (string, error) {
+		executed++
+		return "{}", nil
+	}, 3)
+	if err != nil || result != "answer" {
+		t.Fatalf("fallback should answer: %q, %v", result, err)
+	}
+	if fallback.budget != 1 {
+		t.Errorf("the fallback should get the budget left by the primary, got %d", fallback.budget)
+	}
+	if executed != 3 || fallback.executed != 1 {
+		t.Errorf("expected 3 tool calls in total (1 by the fallback), got %d (%d)", executed, fallback.executed)
+	}
+}
@@ -21,10 +21,13 @@ type clientHooks interface {
 	call(systemPrompt, userPrompt string) (string, error)
 
 	buildMCPRequestBody(systemPrompt, userPrompt string) map[string]any
+	buildRequestBodyFromRequest(req *Request) map[string]any
 	buildUrl() string
 	buildRequest(url string, jsonData []byte) (*http.Request, error)
 	setAuthHeader(reqHeaders http.Header)
 	marshalRequestBody(requestBody map[string]any) ([]byte, error)
 	parseMCPResponse(body []byte) (string, error)
+	parseToolResponse(body []byte) (*ToolResponse, error)
 	isRetryableError(err error) bool
+	requiresAPIKey() bool
 }
@@ -0,0 +1,593 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ProviderOllama       = "ollama"
+	DefaultOllamaBaseURL = "http://localhost:11434"
+	DefaultOllamaModel   = "llama3.1:8b"
+
+	// ProviderLocal OpenAI-compatible local server (llama.cpp server, vLLM, LM Studio), no API key needed
+	ProviderLocal       = "local"
+	DefaultLocalBaseURL = "http://localhost:8000/v1"
+
+	// DefaultLocalTimeout local models on consumer hardware can take minutes to answer a long prompt
+	DefaultLocalTimeout = 10 * time.Minute
+	// DefaultLocalContextLength used when the server does not report the model's context length
+	DefaultLocalContextLength = 8192
+
+	maxOllamaContextLength = 32768            // Upper bound on num_ctx: larger contexts need a lot of memory
+	contextReserveTokens   = 256              // Margin for chat template tokens and estimation error
+	localInfoTimeout       = 10 * time.Second // Model listing and context length detection
+	truncationMarker       = "\n...[truncated to fit the model's context window]"
+)
+
+// LocalModel a model served by a local server
+type LocalModel struct {
+	ID            string `json:"id"`
+	Size          int64  `json:"size,omitempty"` // Bytes on disk (Ollama)
+	Family        string `json:"family,omitempty"`
+	ParameterSize string `json:"parameter_size,omitempty"`
+	Quantization  string `json:"quantization,omitempty"`
+	ContextLength int    `json:"context_length,omitempty"` // 0 when the server does not report it
+}
+
+// LocalClient self-hosted model server: Ollama's native API, or an OpenAI-compatible local server.
+// No API key is needed (one is still sent when set), requests may run for minutes, and prompts are
+// truncated to fit the model's context window instead of being cut silently by the server.
+type LocalClient struct {
+	*Client
+
+	// ContextLength context window in tokens, detected from the server when 0
+	ContextLength int
+
+	native          bool // Ollama native API (/api/chat) instead of /chat/completions
+	mu              sync.Mutex
+	prepared        bool
+	detectedContext int
+}
+
+// NewOllamaClient creates Ollama client (native API)
+func NewOllamaClient() AIClient {
+	return NewOllamaClientWithOptions()
+}
+
+// NewOllamaClientWithOptions creates Ollama client (supports options pattern)
+//
+// Usage example:
+//
+//	client := mcp.NewOllamaClientWithOptions(
+//	    mcp.WithBaseURL("http://gpu-box:11434"),
+//	    mcp.WithModel("qwen2.5:14b"),
+//	)
+func NewOllamaClientWithOptions(opts ...ClientOption) AIClient {
+	ollamaOpts := []ClientOption{
+		WithProvider(ProviderOllama),
+		WithModel(DefaultOllamaModel),
+		WithBaseURL(DefaultOllamaBaseURL),
+	}
+	return newLocalClient(true, append(ollamaOpts, opts...))
+}
+
+// NewLocalClient creates client for an OpenAI-compatible local server
+func NewLocalClient() AIClient {
+	return NewLocalClientWithOptions()
+}
+
+// NewLocalClientWithOptions creates client for an OpenAI-compatible local server (supports options pattern).
+// When no model is set, the first model listed by the server is used.
+func NewLocalClientWithOptions(opts ...ClientOption) AIClient {
+	localOpts := []ClientOption{
+		WithProvider(ProviderLocal),
+		WithBaseURL(DefaultLocalBaseURL),
+	}
+	return newLocalClient(false, append(localOpts, opts...))
+}
+
+func newLocalClient(native bool, opts []ClientOption) *LocalClient {
+	// 1. Long timeout first so that user options can still override it
+	allOpts := append([]ClientOption{WithTimeout(DefaultLocalTimeout)}, opts...)
+
+	// 2. Create base client
+	baseClient := NewClient(allOpts...).(*Client)
+
+	// 3. Create local client
+	localClient := &LocalClient{
+		Client: baseClient,
+		native: native,
+	}
+
+	// 4. Set hooks to point to LocalClient (implement dynamic dispatch)
+	baseClient.hooks = localClient
+
+	return localClient
+}
+
+// ProviderRequiresAPIKey reports whether a provider needs an API key (local servers do not)
+func ProviderRequiresAPIKey(provider string) bool {
+	return provider != ProviderOllama && provider != ProviderLocal
+}
+
+func (c *LocalClient) SetAPIKey(apiKey string, customURL string, customModel string) {
+	c.APIKey = apiKey
+
+	if len(apiKey) > 8 {
+		c.logger.Infof("🔧 [MCP] %s API Key configured (length: %d)", c.Provider, len(apiKey))
+	}
+	if customURL != "" {
+		c.BaseURL = strings.TrimSuffix(customURL, "/")
+		c.logger.Infof("🔧 [MCP] %s using custom BaseURL: %s", c.Provider, c.BaseURL)
+	} else {
+		c.logger.Infof("🔧 [MCP] %s using default BaseURL: %s", c.Provider, c.BaseURL)
+	}
+	if customModel != "" {
+		c.Model = customModel
+		c.logger.Infof("🔧 [MCP] %s using custom Model: %s", c.Provider, customModel)
+	} else {
+		c.logger.Infof("🔧 [MCP] %s using default Model: %s", c.Provider, c.Model)
+	}
+
+	c.mu.Lock()
+	c.prepared = false
+	c.detectedContext = 0
+	c.mu.Unlock()
+}
+
+// SupportsStructuredOutput Ollama takes the schema as format, OpenAI-compatible servers as response_format
+func (c *LocalClient) SupportsStructuredOutput() bool {
+	return true
+}
+
+// EnforcesSchema Ollama's native API takes either tools or a format
+func (c *LocalClient) EnforcesSchema(req *Request) bool {
+	if c.native && len(req.Tools) > 0 {
+		return false
+	}
+	return req.ResponseSchema != nil
+}
+
+func (c *LocalClient) requiresAPIKey() bool {
+	return false
+}
+
+func (c *LocalClient) setAuthHeader(reqHeaders http.Header) {
+	if c.APIKey != "" {
+		c.Client.setAuthHeader(reqHeaders)
+	}
+}
+
+// isRetryableError a generation that hit the timeout would hit it again, only retry connection errors
+func (c *LocalClient) isRetryableError(err error) bool {
+	if strings.Contains(err.Error(), "Client.Timeout") || strings.Contains(err.Error(), "context deadline exceeded") {
+		return false
+	}
+	return c.Client.isRetryableError(err)
+}
+
+func (c *LocalClient) buildUrl() string {
+	if c.native {
+		return c.BaseURL + "/api/chat"
+	}
+	return c.Client.buildUrl()
+}
+
+func (c *LocalClient) buildMCPRequestBody(systemPrompt, userPrompt string) map[string]any {
+	var messages []Message
+	if systemPrompt != "" {
+		messages = append(messages, NewSystemMessage(systemPrompt))
+	}
+	messages = append(messages, NewUserMessage(userPrompt))
+	return c.buildRequestBodyFromRequest(&Request{Messages: messages})
+}
+
+// buildRequestBodyFromRequest fits the messages into the context window, then builds the native or
+// OpenAI-compatible body
+func (c *LocalClient) buildRequestBodyFromRequest(req *Request) map[string]any {
+	c.prepare()
+
+	fitted := *req
+	if fitted.Model == "" {
+		fitted.Model = c.Model
+	}
+	maxTokens := c.MaxTokens
+	if req.MaxTokens != nil {
+		maxTokens = *req.MaxTokens
+	}
+	messages, cut := fitMessages(req.Messages, c.promptBudget(maxTokens))
+	if cut > 0 {
+		c.logger.Warnf("⚠️  [%s] Prompt exceeds the %d-token context window, truncated ~%d tokens", c.String(), c.contextLength(), cut)
+	}
+	fitted.Messages = messages
+
+	if !c.native {
+		return c.Client.buildRequestBodyFromRequest(&fitted)
+	}
+	return c.buildOllamaRequestBody(&fitted, maxTokens)
+}
+
+// buildOllamaRequestBody Ollama /api/chat body; num_ctx is always sent because Ollama's default window
+// is much smaller than a trading prompt and it drops the overflow silently
+func (c *LocalClient) buildOllamaRequestBody(req *Request, maxTokens int) map[string]any {
+	messages := make([]map[string]any, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		message := map[string]any{
+			"role":    msg.Role,
+			"content": msg.Content,
+		}
+		if len(msg.ToolCalls) > 0 {
+			calls := make([]map[string]any, 0, len(msg.ToolCalls))
+			for _, call := range msg.ToolCalls {
+				var args map[string]any
+				if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+					args = map[string]any{}
+				}
+				calls = append(calls, map[string]any{
+					"function": map[string]any{"name": call.Function.Name, "arguments": args},
+				})
+			}
+			message["tool_calls"] = calls
+		}
+		messages = append(messages, message)
+	}
+
+	options := map[string]any{
+		"num_ctx":     c.contextLength(),
+		"num_predict": maxTokens,
+		"temperature": c.config.Temperature,
+	}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	if req.TopP != nil {
+		options["top_p"] = *req.TopP
+	}
+	if req.FrequencyPenalty != nil {
+		options["frequency_penalty"] = *req.FrequencyPenalty
+	}
+	if req.PresencePenalty != nil {
+		options["presence_penalty"] = *req.PresencePenalty
+	}
+	if len(req.Stop) > 0 {
+		options["stop"] = req.Stop
+	}
+
+	requestBody := map[string]any{
+		"model":    req.Model,
+		"messages": messages,
+		"stream":   false,
+		"options":  options,
+	}
+	// Ollama has no tool_choice: leaving the tools out is how calls are refused
+	if len(req.Tools) > 0 && req.ToolChoice != "none" {
+		requestBody["tools"] = req.Tools
+	} else if req.ResponseSchema != nil {
+		requestBody["format"] = req.ResponseSchema.Schema
+	}
+	return requestBody
+}
+
+func (c *LocalClient) parseMCPResponse(body []byte) (string, error) {
+	resp, err := c.parseToolResponse(body)
+	if err != nil {
+		return "", err
+	}
+	return resp.Content, nil
+}
+
+func (c *LocalClient) parseToolResponse(body []byte) (*ToolResponse, error) {
+	if !c.native {
+		return c.Client.parseToolResponse(body)
+	}
+
+	var response struct {
+		Message struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Function struct {
+					Name      string          `json:"name"`
+					Arguments json.RawMessage `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"message"`
+		PromptEvalCount int    `json:"prompt_eval_count"`
+		EvalCount       int    `json:"eval_count"`
+		Error           string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse Ollama response: %w", err)
+	}
+	if response.Error != "" {
+		return nil, fmt.Errorf("Ollama error: %s", response.Error)
+	}
+
+	// Report token usage if callback is set
+	totalTokens := response.PromptEvalCount + response.EvalCount
+	if TokenUsageCallback != nil && totalTokens > 0 {
+		TokenUsageCallback(TokenUsage{
+			Provider:         c.Provider,
+			Model:            c.Model,
+			PromptTokens:     response.PromptEvalCount,
+			CompletionTokens: response.EvalCount,
+			TotalTokens:      totalTokens,
+		})
+	}
+
+	// Ollama tool calls have no ID, number them within the turn
+	resp := &ToolResponse{Content: response.Message.Content}
+	for i, call := range response.Message.ToolCalls {
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+			ID:       fmt.Sprintf("call_%d", i),
+			Type:     "function",
+			Function: ToolCallFunction{Name: call.Function.Name, Arguments: toolArguments(call.Function.Arguments)},
+		})
+	}
+	return resp, nil
+}
+
+// ============================================================
+// Model Listing and Context Length
+// ============================================================
+
+// ListModels models available on the server
+func (c *LocalClient) ListModels() ([]LocalModel, error) {
+	if c.native {
+		var tags struct {
+			Models []struct {
+				Name    string `json:"name"`
+				Size    int64  `json:"size"`
+				Details struct {
+					Family            string `json:"family"`
+					ParameterSize     string `json:"parameter_size"`
+					QuantizationLevel string `json:"quantization_level"`
+				} `json:"details"`
+			} `json:"models"`
+		}
+		if err := c.localRequest(http.MethodGet, "/api/tags", nil, &tags); err != nil {
+			return nil, err
+		}
+		models := make([]LocalModel, 0, len(tags.Models))
+		for _, m := range tags.Models {
+			models = append(models, LocalModel{
+				ID:            m.Name,
+				Size:          m.Size,
+				Family:        m.Details.Family,
+				ParameterSize: m.Details.ParameterSize,
+				Quantization:  m.Details.QuantizationLevel,
+			})
+		}
+		return models, nil
+	}
+
+	var list struct {
+		Data []struct {
+			ID          string `json:"id"`
+			OwnedBy     string `json:"owned_by"`
+			MaxModelLen int    `json:"max_model_len"` // vLLM
+			Meta        struct {
+				NCtxTrain int `json:"n_ctx_train"` // llama.cpp
+			} `json:"meta"`
+		} `json:"data"`
+	}
+	if err := c.localRequest(http.MethodGet, "/models", nil, &list); err != nil {
+		return nil, err
+	}
+	models := make([]LocalModel, 0, len(list.Data))
+	for _, m := range list.Data {
+		contextLength := m.MaxModelLen
+		if contextLength == 0 {
+			contextLength = m.Meta.NCtxTrain
+		}
+		models = append(models, LocalModel{ID: m.ID, Family: m.OwnedBy, ContextLength: contextLength})
+	}
+	return models, nil
+}
+
+// prepare resolves the model and context length from the server once; on failure the defaults are
+// used and detection is tried again on the next request
+func (c *LocalClient) prepare() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.prepared {
+		return
+	}
+
+	var models []LocalModel
+	var err error
+	if c.Model == "" || (!c.native && c.ContextLength == 0) {
+		if models, err = c.ListModels(); err != nil {
+			c.logger.Warnf("⚠️  [%s] Failed to list models: %v", c.String(), err)
+			return
+		}
+	}
+	if c.Model == "" {
+		if len(models) == 0 {
+			c.logger.Warnf("⚠️  [%s] Server has no models loaded", c.String())
+			return
+		}
+		c.Model = models[0].ID
+		c.logger.Infof("🔧 [MCP] %s using the server's first model: %s", c.Provider, c.Model)
+	}
+
+	if c.ContextLength == 0 {
+		contextLength, err := c.detectContextLength(models)
+		if err != nil {
+			c.logger.Warnf("⚠️  [%s] Failed to detect context length: %v", c.String(), err)
+			return
+		}
+		if contextLength > 0 {
+			c.detectedContext = contextLength
+			c.logger.Infof("🔧 [MCP] %s context window: %d tokens", c.Provider, contextLength)
+		}
+	}
+	c.prepared = true
+}
+
+// detectContextLength context length of the configured model, 0 when the server does not report it
+func (c *LocalClient) detectContextLength(models []LocalModel) (int, error) {
+	if !c.native {
+		for _, m := range models {
+			if m.ID == c.Model {
+				return m.ContextLength, nil
+			}
+		}
+		return 0, nil
+	}
+
+	var show struct {
+		ModelInfo map[string]any `json:"model_info"`
+	}
+	if err := c.localRequest(http.MethodPost, "/api/show", map[string]any{"model": c.Model}, &show); err != nil {
+		return 0, err
+	}
+	for key, value := range show.ModelInfo {
+		if n, ok := value.(float64); ok && strings.HasSuffix(key, ".context_length") {
+			return min(int(n), maxOllamaContextLength), nil
+		}
+	}
+	return 0, nil
+}
+
+// contextLength context window used for truncation (and Ollama's num_ctx)
+func (c *LocalClient) contextLength() int {
+	if c.ContextLength > 0 {
+		return c.ContextLength
+	}
+	if c.detectedContext > 0 {
+		return c.detectedContext
+	}
+	return DefaultLocalContextLength
+}
+
+// promptBudget tokens left for the prompt once the answer and a margin are reserved
+func (c *LocalClient) promptBudget(maxTokens int) int {
+	budget := c.contextLength() - maxTokens - contextReserveTokens
+	if floor := c.contextLength() / 4; budget < floor {
+		budget = floor
+	}
+	return budget
+}
+
+// localRequest small JSON request to the server's info endpoints, with a short timeout
+func (c *LocalClient) localRequest(method, path string, payload any, out any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), localInfoTimeout)
+	defer cancel()
+
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	c.setAuthHeader(req.Header)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", c.BaseURL, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %w", path, &StatusError{StatusCode: resp.StatusCode, Body: string(data)})
+	}
+	return json.Unmarshal(data, out)
+}
+
+// ============================================================
+// Prompt Truncation
+// ============================================================
+
+// estimateTokens rough token count: about 4 ASCII characters per token, one per other character (CJK, emoji)
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return (ascii+3)/4 + other
+}
+
+// fitMessages cuts the tail of the longest messages until the conversation fits the budget, keeping
+// system prompts for last. Returns the messages (a copy when cut) and the number of tokens cut.
+func fitMessages(messages []Message, budget int) ([]Message, int) {
+	total := 0
+	for _, msg := range messages {
+		total += estimateTokens(msg.Content)
+	}
+	if total <= budget {
+		return messages, 0
+	}
+
+	out := append([]Message{}, messages...)
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sysA, sysB := out[order[a]].Role == "system", out[order[b]].Role == "system"
+		if sysA != sysB {
+			return !sysA
+		}
+		return len(out[order[a]].Content) > len(out[order[b]].Content)
+	})
+
+	excess := total - budget
+	for _, i := range order {
+		if excess <= 0 {
+			break
+		}
+		tokens := estimateTokens(out[i].Content)
+		keep := max(tokens-excess, 0)
+		out[i].Content = truncateToTokens(out[i].Content, keep)
+		excess -= tokens - keep
+	}
+	return out, total - budget - excess
+}
+
+// truncateToTokens keeps the head of s within the token count, cut at a line break when one is near
+func truncateToTokens(s string, tokens int) string {
+	asciiChars := 0
+	cost := 0
+	for i, r := range s {
+		if r < utf8.RuneSelf {
+			asciiChars++
+			if asciiChars%4 == 1 {
+				cost++
+			}
+		} else {
+			cost++
+		}
+		if cost > tokens {
+			head := s[:i]
+			if nl := strings.LastIndex(head, "\n"); nl > len(head)*3/4 {
+				head = head[:nl]
+			}
+			return head + truncationMarker
+		}
+	}
+	return s
+}
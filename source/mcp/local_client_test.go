@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// ============================================================
+// Test Local Providers
+// ============================================================
+
+// newLocalTestServer mock server answering the given paths with JSON, recording each request body by path
+func newLocalTestServer(responses map[string]string, bodies map[string]map[string]any, headers map[string]http.Header) *MockHTTPClient {
+	mockHTTP := NewMockHTTPClient()
+	mockHTTP.ResponseFunc = func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			var body map[string]any
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, &body)
+			bodies[req.URL.Path] = body
+		}
+		headers[req.URL.Path] = req.Header
+		status := http.StatusOK
+		response, ok := responses[req.URL.Path]
+		if !ok {
+			status, response = http.StatusNotFound, `{"error":"not found"}`
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(bytes.NewBufferString(response)),
+			Header:     make(http.Header),
+		}, nil
+	}
+	return mockHTTP
+}
+
+func TestOllamaClient_NativeChat(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	headers := map[string]http.Header{}
+	mockHTTP := newLocalTestServer(map[string]string{
+		"/api/show": `{"model_info":{"general.architecture":"llama","llama.context_length":131072}}`,
+		"/api/chat": `{"message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"get_klines","arguments":{"symbol":"BTCUSDT"}}}]},"prompt_eval_count":10,"eval_count":5,"done":true}`,
+	}, bodies, headers)
+
+	client := NewOllamaClientWithOptions(
+		WithHTTPClient(mockHTTP.ToHTTPClient()),
+		WithLogger(NewMockLogger()),
+	).(*LocalClient)
+
+	request := NewRequestBuilder().
+		WithSystemPrompt("system").
+		WithUserPrompt("decide").
+		AddFunction("get_klines", "klines", nil).
+		MustBuild()
+	resp, err := client.CallWithTools(request, func(call ToolCall) (string, error) { return "[]", nil }, 0)
+	if err == nil || !strings.Contains(err.Error(), "tool") {
+		t.Fatalf("a model that only calls tools past the budget should fail, got %q, %v", resp, err)
+	}
+
+	body := bodies["/api/chat"]
+	if body["stream"] != false || body["model"] != DefaultOllamaModel {
+		t.Errorf("unexpected body: %v", body)
+	}
+	if numCtx := body["options"].(map[string]any)["num_ctx"]; numCtx != float64(maxOllamaContextLength) {
+		t.Errorf("num_ctx should be the detected length capped at %d, got %v", maxOllamaContextLength, numCtx)
+	}
+	if _, ok := body["tools"]; ok {
+		t.Error("tools should be left out once the budget is used")
+	}
+	if headers["/api/chat"].Get("Authorization") != "" {
+		t.Error("no Authorization header without an API key")
+	}
+
+	parsed, err := client.parseToolResponse([]byte(`{"message":{"content":"","tool_calls":[{"function":{"name":"get_klines","arguments":{"symbol":"ETHUSDT"}}}]}}`))
+	if err != nil || len(parsed.ToolCalls) != 1 || parsed.ToolCalls[0].ID != "call_0" || parsed.ToolCalls[0].Function.Arguments != `{"symbol":"ETHUSDT"}` {
+		t.Errorf("unexpected tool calls: %+v, %v", parsed, err)
+	}
+	if _, err := client.parseToolResponse([]byte(`{"error":"model not found"}`)); err == nil {
+		t.Error("Ollama errors should be returned")
+	}
+}
+
+func TestLocalClient_OpenAICompatible(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	headers := map[string]http.Header{}
+	mockHTTP := newLocalTestServer(map[string]string{
+		"/v1/models":           `{"data":[{"id":"qwen2.5-7b-instruct","owned_by":"vllm","max_model_len":4096}]}`,
+		"/v1/chat/completions": `{"choices":[{"message":{"content":"ok"}}]}`,
+	}, bodies, headers)
+
+	client := NewLocalClientWithOptions(
+		WithHTTPClient(mockHTTP.ToHTTPClient()),
+		WithLogger(NewMockLogger()),
+		WithMaxTokens(1000),
+	).(*LocalClient)
+	client.SetAPIKey("", "http://localhost:8000/v1/", "")
+
+	models, err := client.ListModels()
+	if err != nil || len(models) != 1 || models[0].ContextLength != 4096 {
+		t.Fatalf("unexpected models: %+v, %v", models, err)
+	}
+
+	result, err := client.CallWithMessages("rules", strings.Repeat("candidate data line\n", 2000))
+	if err != nil || result != "ok" {
+		t.Fatalf("call should succeed without an API key: %q, %v", result, err)
+	}
+	body := bodies["/v1/chat/completions"]
+	if body["model"] != "qwen2.5-7b-instruct" {
+		t.Errorf("the server's first model should be used, got %v", body["model"])
+	}
+	messages := body["messages"].([]any)
+	system := messages[0].(map[string]any)["content"].(string)
+	user := messages[1].(map[string]any)["content"].(string)
+	if system != "rules" || !strings.HasSuffix(user, truncationMarker) {
+		t.Errorf("the user prompt should be truncated and the system prompt kept")
+	}
+	if tokens := estimateTokens(system) + estimateTokens(user); tokens > 4096-1000 {
+		t.Errorf("prompt should fit the context window, got ~%d tokens", tokens)
+	}
+}
+
+func TestFitMessages(t *testing.T) {
+	messages := []Message{
+		NewSystemMessage(strings.Repeat("s", 400)),
+		NewUserMessage(strings.Repeat("u", 800)),
+		NewAssistantMessage(strings.Repeat("a", 40)),
+	}
+	fitted, cut := fitMessages(messages, 200)
+	if cut != 110 {
+		t.Errorf("expected 110 tokens cut, got %d", cut)
+	}
+	if fitted[0].Content != messages[0].Content || fitted[2].Content != messages[2].Content {
+		t.Error("only the longest non-system message needed cutting")
+	}
+	if messages[1].Content != strings.Repeat("u", 800) {
+		t.Error("the caller's messages should not be modified")
+	}
+	if unchanged, cut := fitMessages(messages, 1000); cut != 0 || len(unchanged) != 3 {
+		t.Error("messages within the budget should be kept as they are")
+	}
+	if estimateTokens("abcd") != 1 || estimateTokens("比特币") != 3 {
+		t.Error("unexpected token estimate")
+	}
+}
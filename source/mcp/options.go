@@ -68,6 +68,16 @@ func WithRetryWaitBase(waitTime time.Duration) ClientOption {
 	}
 }
 
+// WithMaxConcurrentPerKey sets the in-flight request limit shared by all clients using the same API key
+//
+// Usage example:
+//   client := mcp.NewClient(mcp.WithMaxConcurrentPerKey(2))
+func WithMaxConcurrentPerKey(limit int) ClientOption {
+	return func(c *Config) {
+		c.MaxConcurrentPerKey = limit
+	}
+}
+
 // ============================================================
 // AI Parameter Options
 // ============================================================
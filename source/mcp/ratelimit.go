@@ -0,0 +1,129 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxRateLimitWait longest Retry-After waited out on the same endpoint; longer limits fail the call so a
+// failover chain can move on to its next model
+const maxRateLimitWait = 10 * time.Second
+
+// RateLimitError the provider answered 429 Too Many Requests
+type RateLimitError struct {
+	Provider   string
+	RetryAfter time.Duration // 0 when the provider did not say
+	Body       string
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("API returned error (status %d): %s", http.StatusTooManyRequests, e.Body)
+}
+
+// StatusError the provider answered with a non-200 status other than 429
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API returned error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// statusError error for a non-200 response; 429 becomes a RateLimitError
+func (client *Client) statusError(resp *http.Response, body []byte) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{
+			Provider:   client.Provider,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Body:       string(body),
+		}
+	}
+	return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+}
+
+// rateLimitRetry handles a rate-limited attempt: a short Retry-After is waited out before retrying the same
+// endpoint, a long one (or the last attempt) fails the call so that a failover chain can move on.
+// limited reports whether err is a rate limit at all.
+func (client *Client) rateLimitRetry(err error, attempt int) (limited, retry bool) {
+	var rl *RateLimitError
+	if !errors.As(err, &rl) {
+		return false, false
+	}
+	wait := rl.RetryAfter
+	if wait == 0 {
+		wait = client.config.RetryWaitBase * time.Duration(attempt)
+	}
+	if attempt >= client.config.MaxRetries || wait > maxRateLimitWait {
+		return true, false
+	}
+	client.logger.Infof("⏳ [%s] Rate limited, waiting %v before retry...", client.String(), wait)
+	time.Sleep(wait)
+	return true, true
+}
+
+// parseRetryAfter Retry-After header in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
+// ============================================================
+// Concurrency Limits
+// ============================================================
+
+// keySlots in-flight request slots per API key, shared by every client (and so every trader) using the key
+var keySlots = struct {
+	sync.Mutex
+	m map[string]chan struct{}
+}{m: make(map[string]chan struct{})}
+
+// keyHash short hash of an API key, to key process-wide state by key without holding it in plain text
+func keyHash(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// acquireSlot waits for a request slot of the client's API key (its base URL for keyless local servers)
+// and returns the function releasing it
+func (client *Client) acquireSlot() func() {
+	limit := client.config.MaxConcurrentPerKey
+	if limit <= 0 {
+		return func() {}
+	}
+	owner := client.APIKey
+	if owner == "" {
+		owner = client.BaseURL
+	}
+	key := keyHash(owner)
+
+	keySlots.Lock()
+	slots, ok := keySlots.m[key]
+	if !ok {
+		slots = make(chan struct{}, limit)
+		keySlots.m[key] = slots
+	}
+	keySlots.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+	default:
+		client.logger.Infof("⏳ [%s] Waiting for a free request slot (%d in flight on this API key)", client.String(), limit)
+		slots <- struct{}{}
+	}
+	return func() { <-slots }
+}
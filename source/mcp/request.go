@@ -2,8 +2,23 @@ package mcp
 
 // Message represents a conversation message
 type Message struct {
-	Role    string `json:"role"`    // "system", "user", "assistant"
-	Content string `json:"content"` // Message content
+	Role       string     `json:"role"`                   // "system", "user", "assistant", "tool"
+	Content    string     `json:"content"`                // Message content
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Tools requested by an assistant message
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Tool call a "tool" message answers
+}
+
+// ToolCall a tool invocation requested by the model
+type ToolCall struct {
+	ID       string           `json:"id"`
+	Type     string           `json:"type"` // Usually "function"
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction function name and JSON-encoded arguments of a tool call
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // Tool represents a tool/function that AI can call
@@ -37,6 +52,16 @@ type Request struct {
 	// Advanced features
 	Tools      []Tool `json:"tools,omitempty"`       // Available tools list
 	ToolChoice string `json:"tool_choice,omitempty"` // Tool choice strategy ("auto", "none", {"type": "function", "function": {"name": "xxx"}})
+
+	// Structured output: JSON schema the answer must follow (nil = free text)
+	ResponseSchema *ResponseSchema `json:"response_schema,omitempty"`
+}
+
+// ResponseSchema JSON schema of a structured answer
+type ResponseSchema struct {
+	Name        string         `json:"name"`                  // Schema name (letters, digits, _ and -)
+	Description string         `json:"description,omitempty"` // What the answer is
+	Schema      map[string]any `json:"schema"`                // JSON Schema of the answer, an object at the top level
 }
 
 // NewMessage creates a message
@@ -70,3 +95,12 @@ func NewAssistantMessage(content string) Message {
 		Content: content,
 	}
 }
+
+// NewToolMessage creates a message carrying the result of a tool call
+func NewToolMessage(toolCallID, content string) Message {
+	return Message{
+		Role:       "tool",
+		Content:    content,
+		ToolCallID: toolCallID,
+	}
+}
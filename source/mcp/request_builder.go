@@ -17,6 +17,7 @@ type RequestBuilder struct {
 	stop             []string
 	tools            []Tool
 	toolChoice       string
+	responseSchema   *ResponseSchema
 }
 
 // NewRequestBuilder creates request builder
@@ -217,6 +218,17 @@ func (b *RequestBuilder) WithToolChoice(choice string) *RequestBuilder {
 	return b
 }
 
+// ============================================================
+// Structured Output Related
+// ============================================================
+
+// WithResponseSchema requires the answer to be a JSON value matching schema
+// Enforced by providers with native structured output (see SupportsStructuredOutput), ignored by others
+func (b *RequestBuilder) WithResponseSchema(name, description string, schema map[string]any) *RequestBuilder {
+	b.responseSchema = &ResponseSchema{Name: name, Description: description, Schema: schema}
+	return b
+}
+
 // ============================================================
 // Build Methods
 // ============================================================
@@ -236,6 +248,8 @@ func (b *RequestBuilder) Build() (*Request, error) {
 		Stop:       b.stop,
 		Tools:      b.tools,
 		ToolChoice: b.toolChoice,
+
+		ResponseSchema: b.responseSchema,
 	}
 
 	// Only set non-nil optional parameters (avoid sending 0 values that override server defaults)
@@ -0,0 +1,83 @@
+package mcp
+
+// StructuredOutputCaller clients that can tell whether their provider enforces Request.ResponseSchema
+type StructuredOutputCaller interface {
+	SupportsStructuredOutput() bool
+}
+
+// SchemaEnforcer clients that can tell whether the answer to req was held to req.ResponseSchema: some
+// providers only enforce a schema on requests without tools
+type SchemaEnforcer interface {
+	EnforcesSchema(req *Request) bool
+}
+
+// SupportsStructuredOutput reports whether the provider enforces a response JSON schema natively
+// (OpenAI-compatible response_format json_schema, also accepted by local vLLM/llama.cpp servers). Other
+// providers get the request without it, so the caller must still ask for the format in the prompt and
+// parse the text.
+func (client *Client) SupportsStructuredOutput() bool {
+	switch client.Provider {
+	case ProviderOpenAI, ProviderGemini, ProviderGrok, ProviderLocal:
+		return true
+	}
+	return false
+}
+
+// EnforcesSchema response_format is sent with or without tools
+func (client *Client) EnforcesSchema(req *Request) bool {
+	return req.ResponseSchema != nil && client.SupportsStructuredOutput()
+}
+
+// SupportsStructuredOutput Claude enforces the schema by forcing a tool whose input is the answer
+func (c *ClaudeClient) SupportsStructuredOutput() bool {
+	return true
+}
+
+// EnforcesSchema the answer tool is only forced on requests without other tools
+func (c *ClaudeClient) EnforcesSchema(req *Request) bool {
+	return req.ResponseSchema != nil && len(req.Tools) == 0
+}
+
+// responseFormat OpenAI-compatible response_format for a response schema
+func (client *Client) responseFormat(rs *ResponseSchema) map[string]any {
+	jsonSchema := map[string]any{
+		"name":   rs.Name,
+		"schema": rs.Schema,
+		"strict": true,
+	}
+	if rs.Description != "" {
+		jsonSchema["description"] = rs.Description
+	}
+	// Gemini's schema subset rejects additionalProperties and strict mode
+	if client.Provider == ProviderGemini {
+		jsonSchema["schema"] = withoutAdditionalProperties(rs.Schema)
+		delete(jsonSchema, "strict")
+	}
+	return map[string]any{"type": "json_schema", "json_schema": jsonSchema}
+}
+
+// withoutAdditionalProperties copy of a JSON schema with every additionalProperties keyword removed
+func withoutAdditionalProperties(schema map[string]any) map[string]any {
+	out := make(map[string]any, len(schema))
+	for k, v := range schema {
+		if k == "additionalProperties" {
+			continue
+		}
+		out[k] = stripAdditionalProperties(v)
+	}
+	return out
+}
+
+func stripAdditionalProperties(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		return withoutAdditionalProperties(val)
+	case []any:
+		out := make([]any, len(val))
+		for i, item := range val {
+			out[i] = stripAdditionalProperties(item)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ============================================================
+// Test Structured Output
+// ============================================================
+
+func structuredTestRequest() *Request {
+	return NewRequestBuilder().
+		WithSystemPrompt("system").
+		WithUserPrompt("decide").
+		WithResponseSchema("answer", "the answer", map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{"items": map[string]any{"type": "array", "items": map[string]any{"type": "object", "additionalProperties": false}}},
+			"additionalProperties": false,
+		}).
+		MustBuild()
+}
+
+func TestClient_ResponseFormat(t *testing.T) {
+	client := NewClient(WithLogger(NewMockLogger())).(*Client)
+	client.Provider = ProviderOpenAI
+	body := client.buildRequestBodyFromRequest(structuredTestRequest())
+	format, ok := body["response_format"].(map[string]any)
+	if !ok || format["type"] != "json_schema" {
+		t.Fatalf("expected a json_schema response_format, got %v", body["response_format"])
+	}
+	jsonSchema := format["json_schema"].(map[string]any)
+	if jsonSchema["name"] != "answer" || jsonSchema["strict"] != true {
+		t.Errorf("unexpected json_schema: %v", jsonSchema)
+	}
+
+	client.Provider = ProviderGemini
+	jsonSchema = client.buildRequestBodyFromRequest(structuredTestRequest())["response_format"].(map[string]any)["json_schema"].(map[string]any)
+	schema := jsonSchema["schema"].(map[string]any)
+	items := schema["properties"].(map[string]any)["items"].(map[string]any)["items"].(map[string]any)
+	if _, ok := jsonSchema["strict"]; ok || schema["additionalProperties"] != nil || items["additionalProperties"] != nil {
+		t.Errorf("gemini schema should not use strict or additionalProperties: %v", jsonSchema)
+	}
+
+	client.Provider = ProviderDeepSeek
+	if _, ok := client.buildRequestBodyFromRequest(structuredTestRequest())["response_format"]; ok {
+		t.Error("providers without json_schema support should not get response_format")
+	}
+}
+
+func TestClaudeClient_StructuredOutput(t *testing.T) {
+	client := NewClaudeClientWithOptions(WithLogger(NewMockLogger())).(*ClaudeClient)
+	body := client.buildRequestBodyFromRequest(structuredTestRequest())
+	tools := body["tools"].([]map[string]any)
+	if len(tools) != 1 || tools[0]["name"] != "answer" || tools[0]["input_schema"] == nil {
+		t.Fatalf("the schema should be sent as a forced tool, got %v", tools)
+	}
+	if choice := body["tool_choice"].(map[string]any); choice["type"] != "tool" || choice["name"] != "answer" {
+		t.Errorf("unexpected tool_choice: %v", choice)
+	}
+
+	result, err := client.parseMCPResponse([]byte(`{"content":[{"type":"tool_use","id":"a","name":"answer","input":{"items":[]}}]}`))
+	if err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+	if result != `{"items":[]}` {
+		t.Errorf("the tool input should be returned as the answer, got %s", result)
+	}
+}
+
+func TestEnforcesSchema(t *testing.T) {
+	withTools := structuredTestRequest()
+	withTools.Tools = []Tool{{Type: "function", Function: FunctionDef{Name: "get_klines"}}}
+
+	openai := NewClient(WithLogger(NewMockLogger())).(*Client)
+	openai.Provider = ProviderOpenAI
+	claude := NewClaudeClientWithOptions(WithLogger(NewMockLogger())).(*ClaudeClient)
+	ollama := NewOllamaClientWithOptions(WithLogger(NewMockLogger())).(*LocalClient)
+	tests := []struct {
+		name   string
+		client SchemaEnforcer
+		req    *Request
+		want   bool
+	}{
+		{"openai", openai, structuredTestRequest(), true},
+		{"openai with tools", openai, withTools, true},
+		{"claude", claude, structuredTestRequest(), true},
+		{"claude with tools", claude, withTools, false},
+		{"ollama", ollama, structuredTestRequest(), true},
+		{"ollama with tools", ollama, withTools, false},
+	}
+	for _, tt := range tests {
+		if got := tt.client.EnforcesSchema(tt.req); got != tt.want {
+			t.Errorf("%s: EnforcesSchema() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFailoverCall_EnforcesSchema(t *testing.T) {
+	primary := newFailoverTestClient("https://schema-primary.test", http.StatusInternalServerError, nil, new(int))
+	fallback := newFailoverTestClient("https://schema-fallback.test", http.StatusOK, nil, new(int))
+	fallback.Provider = ProviderOpenAI
+	chain := NewFailoverClient(primary, fallback)
+	chain.logger = NewMockLogger()
+
+	call := chain.NewCall().(*FailoverCall)
+	if call.EnforcesSchema(structuredTestRequest()) {
+		t.Error("a scope without answers should report the primary, which does not enforce schemas")
+	}
+	if _, err := call.CallWithRequest(structuredTestRequest()); err != nil {
+		t.Fatalf("fallback should answer: %v", err)
+	}
+	if !call.EnforcesSchema(structuredTestRequest()) {
+		t.Error("the answering fallback enforces schemas")
+	}
+}
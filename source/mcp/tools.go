@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// toolBudgetExhausted result returned for tool calls made after the budget ran out
+const toolBudgetExhausted = `{"error":"tool budget exhausted, decide with the data you already have"}`
+
+// ToolExecutor runs one tool call and returns its result (usually JSON) for the model
+type ToolExecutor func(call ToolCall) (string, error)
+
+// ToolCaller clients that can run a tool-calling conversation
+type ToolCaller interface {
+	// CallWithTools sends req, executes the tools the model asks for and feeds the results back until the
+	// model answers with text. At most budget tool calls are executed; after that the model is asked to
+	// answer without tools.
+	CallWithTools(req *Request, execute ToolExecutor, budget int) (string, error)
+}
+
+// ToolResponse one model turn: text content and the tools it asked to call
+type ToolResponse struct {
+	Content   string
+	ToolCalls []ToolCall
+}
+
+// CallWithTools runs the tool-calling loop (see ToolCaller)
+func (client *Client) CallWithTools(req *Request, execute ToolExecutor, budget int) (string, error) {
+	if client.APIKey == "" && client.hooks.requiresAPIKey() {
+		return "", fmt.Errorf("AI API key not set, please call SetAPIKey first")
+	}
+	if req.Model == "" {
+		req.Model = client.Model
+	}
+
+	turn := *req
+	turn.Messages = append([]Message{}, req.Messages...)
+	used := 0
+	for {
+		if used >= budget && len(turn.Tools) > 0 {
+			turn.ToolChoice = "none"
+		}
+		resp, err := client.callToolTurn(&turn)
+		if err != nil {
+			return "", err
+		}
+		if len(resp.ToolCalls) == 0 {
+			return resp.Content, nil
+		}
+		if used >= budget {
+			if resp.Content == "" {
+				return "", fmt.Errorf("model kept calling tools after the budget of %d calls was exhausted", budget)
+			}
+			return resp.Content, nil
+		}
+
+		turn.Messages = append(turn.Messages, Message{Role: "assistant", Content: resp.Content, ToolCalls: resp.ToolCalls})
+		for _, call := range resp.ToolCalls {
+			result := toolBudgetExhausted
+			if used < budget {
+				used++
+				client.logger.Infof("🔧 [%s] Tool call %d/%d: %s(%s)", client.String(), used, budget, call.Function.Name, call.Function.Arguments)
+				if result, err = execute(call); err != nil {
+					errJSON, _ := json.Marshal(map[string]string{"error": err.Error()})
+					result = string(errJSON)
+				}
+			}
+			turn.Messages = append(turn.Messages, NewToolMessage(call.ID, result))
+		}
+	}
+}
+
+// callToolTurn sends one turn of a tool-calling conversation with the usual retry flow
+func (client *Client) callToolTurn(req *Request) (*ToolResponse, error) {
+	var lastErr error
+	maxRetries := client.config.MaxRetries
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if attempt > 1 {
+			client.logger.Warnf("⚠️  AI API call failed, retrying (%d/%d)...", attempt, maxRetries)
+		}
+
+		start := time.Now()
+		body, err := client.send(client.hooks.buildRequestBodyFromRequest(req))
+		var resp *ToolResponse
+		if err == nil {
+			if resp, err = client.hooks.parseToolResponse(body); err != nil {
+				err = fmt.Errorf("fail to parse AI server response: %w", err)
+			}
+		}
+		client.reportRequest(time.Since(start), err)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		if limited, retry := client.rateLimitRetry(err, attempt); limited {
+			if retry {
+				continue
+			}
+			return nil, err
+		}
+		if !client.hooks.isRetryableError(err) {
+			return nil, err
+		}
+		if attempt < maxRetries {
+			waitTime := client.config.RetryWaitBase * time.Duration(attempt)
+			client.logger.Infof("⏳ Waiting %v before retry...", waitTime)
+			time.Sleep(waitTime)
+		}
+	}
+
+	return nil, fmt.Errorf("still failed after %d retries: %w", maxRetries, lastErr)
+}
+
+// parseToolResponse parses an OpenAI-compatible chat completion into text and tool calls
+func (client *Client) parseToolResponse(body []byte) (*ToolResponse, error) {
+	var result struct {
+		Choices []struct {
+			Message struct {
+				Content   string `json:"content"`
+				ToolCalls []struct {
+					ID       string `json:"id"`
+					Type     string `json:"type"`
+					Function struct {
+						Name      string          `json:"name"`
+						Arguments json.RawMessage `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+			TotalTokens      int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if len(result.Choices) == 0 {
+		return nil, fmt.Errorf("API returned empty response")
+	}
+
+	// Report token usage if callback is set
+	if TokenUsageCallback != nil && result.Usage.TotalTokens > 0 {
+		TokenUsageCallback(TokenUsage{
+			Provider:         client.Provider,
+			Model:            client.Model,
+			PromptTokens:     result.Usage.PromptTokens,
+			CompletionTokens: result.Usage.CompletionTokens,
+			TotalTokens:      result.Usage.TotalTokens,
+		})
+	}
+
+	message := result.Choices[0].Message
+	resp := &ToolResponse{Content: message.Content}
+	for i, call := range message.ToolCalls {
+		if call.ID == "" {
+			call.ID = fmt.Sprintf("call_%d", i)
+		}
+		resp.ToolCalls = append(resp.ToolCalls, ToolCall{
+			ID:       call.ID,
+			Type:     "function",
+			Function: ToolCallFunction{Name: call.Function.Name, Arguments: toolArguments(call.Function.Arguments)},
+		})
+	}
+	return resp, nil
+}
+
+// toolArguments arguments as a JSON string; most providers send an encoded string, a few send the object
+func toolArguments(raw json.RawMessage) string {
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err == nil {
+		return encoded
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return "{}"
+	}
+	return string(raw)
+}
@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// ============================================================
+// Test Tool Calling Loop
+// ============================================================
+
+func TestClient_CallWithTools_Loop(t *testing.T) {
+	mockHTTP := NewMockHTTPClient()
+	var bodies []map[string]any
+	mockHTTP.ResponseFunc = func(req *http.Request) (*http.Response, error) {
+		var body map[string]any
+		data, _ := io.ReadAll(req.Body)
+		json.Unmarshal(data, &body)
+		bodies = append(bodies, body)
+
+		response := `{"choices":[{"message":{"content":"","tool_calls":[` +
+			`{"id":"a","type":"function","function":{"name":"get_klines","arguments":"{\"symbol\":\"BTCUSDT\"}"}},` +
+			`{"id":"b","type":"function","function":{"name":"get_orderbook","arguments":{"symbol":"BTCUSDT"}}}]}}]}`
+		if len(bodies) == 2 {
+			response = `{"choices":[{"message":{"content":"final decision"}}]}`
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(bytes.NewBufferString(response)),
+			Header:     make(http.Header),
+		}, nil
+	}
+
+	client := NewClient(
+		WithHTTPClient(mockHTTP.ToHTTPClient()),
+		WithLogger(NewMockLogger()),
+		WithAPIKey("test-key"),
+		WithBaseURL("https://api.test.com"),
+	).(*Client)
+
+	var executed []ToolCall
+	request := NewRequestBuilder().
+		WithSystemPrompt("system").
+		WithUserPrompt("decide").
+		AddFunction("get_klines", "klines", nil).
+		AddFunction("get_orderbook", "order book", nil).
+		MustBuild()
+	result, err := client.CallWithTools(request, func(call ToolCall) (string, error) {
+		executed = append(executed, call)
+		return `{"ok":true}`, nil
+	}, 1)
+	if err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+	if result != "final decision" {
+		t.Errorf("expected final decision, got %q", result)
+	}
+
+	// Budget of 1: the second call is answered without being executed
+	if len(executed) != 1 || executed[0].Function.Arguments != `{"symbol":"BTCUSDT"}` {
+		t.Fatalf("expected one executed call with decoded arguments, got %+v", executed)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(bodies))
+	}
+	if bodies[1]["tool_choice"] != "none" {
+		t.Errorf("tool_choice should be none once the budget is used, got %v", bodies[1]["tool_choice"])
+	}
+	messages := bodies[1]["messages"].([]any)
+	if len(messages) != 5 {
+		t.Fatalf("expected system, user, assistant and 2 tool messages, got %d", len(messages))
+	}
+	exhausted := messages[4].(map[string]any)
+	if exhausted["role"] != "tool" || exhausted["tool_call_id"] != "b" || exhausted["content"] != toolBudgetExhausted {
+		t.Errorf("unexpected tool message: %v", exhausted)
+	}
+	if len(request.Messages) != 2 {
+		t.Error("the caller's request should not be modified")
+	}
+}
+
+func TestClaudeClient_BuildRequestBodyFromRequest_Tools(t *testing.T) {
+	client := NewClaudeClientWithOptions(WithLogger(NewMockLogger())).(*ClaudeClient)
+	request := NewRequestBuilder().
+		WithSystemPrompt("system").
+		WithUserPrompt("decide").
+		AddFunction("get_klines", "klines", nil).
+		WithToolChoice("required").
+		AddMessages(
+			Message{Role: "assistant", ToolCalls: []ToolCall{
+				{ID: "a", Type: "function", Function: ToolCallFunction{Name: "get_klines", Arguments: `{"symbol":"BTCUSDT"}`}},
+				{ID: "b", Type: "function", Function: ToolCallFunction{Name: "get_klines", Arguments: `{"symbol":"ETHUSDT"}`}},
+			}},
+			NewToolMessage("a", "btc"),
+			NewToolMessage("b", "eth"),
+		).
+		MustBuild()
+
+	body := client.buildRequestBodyFromRequest(request)
+	if body["system"] != "system" {
+		t.Errorf("system prompt should be sent separately, got %v", body["system"])
+	}
+	messages := body["messages"].([]map[string]any)
+	if len(messages) != 3 {
+		t.Fatalf("expected user, assistant and one merged tool result message, got %d", len(messages))
+	}
+	if results := messages[2]["content"].([]map[string]any); messages[2]["role"] != "user" || len(results) != 2 || results[1]["tool_use_id"] != "b" {
+		t.Errorf("unexpected tool results: %v", messages[2])
+	}
+	if choice := body["tool_choice"].(map[string]any); choice["type"] != "any" {
+		t.Errorf("required should map to any, got %v", choice)
+	}
+	if tools := body["tools"].([]map[string]any); tools[0]["input_schema"] == nil {
+		t.Error("tools need an input_schema")
+	}
+
+	resp, err := client.parseToolResponse([]byte(`{"content":[{"type":"text","text":"checking"},{"type":"tool_use","id":"c","name":"get_klines","input":{"symbol":"SOLUSDT"}}]}`))
+	if err != nil {
+		t.Fatalf("should not error: %v", err)
+	}
+	if resp.Content != "checking" || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Arguments != `{"symbol":"SOLUSDT"}` {
+		t.Errorf("unexpected parsed response: %+v", resp)
+	}
+}
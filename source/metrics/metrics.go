@@ -0,0 +1,118 @@
+// Package metrics exposes trading and system metrics in the Prometheus/OpenMetrics text format.
+package metrics
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// Default is the registry served on /metrics
+var Default = NewRegistry()
+
+// Label names
+const (
+	LabelTraderID = "trader_id"
+	LabelExchange = "exchange"
+	LabelProvider = "provider"
+	LabelAction   = "action"
+	LabelStream   = "stream"
+	LabelRunID    = "run_id"
+	LabelType     = "type"
+)
+
+var startTime = time.Now()
+
+// Trading metrics
+var (
+	DecisionCycles = Default.NewCounterVec("nofx_decision_cycles_total",
+		"AI decision cycles run by a trader", LabelTraderID, LabelExchange)
+	DecisionCycleFailures = Default.NewCounterVec("nofx_decision_cycle_failures_total",
+		"Decision cycles that ended with an error", LabelTraderID, LabelExchange)
+	OrdersPlaced = Default.NewCounterVec("nofx_orders_placed_total",
+		"Orders accepted by the exchange", LabelTraderID, LabelExchange, LabelAction)
+	OrdersRejected = Default.NewCounterVec("nofx_orders_rejected_total",
+		"Orders the exchange rejected or failed to accept", LabelTraderID, LabelExchange, LabelAction)
+	TraderErrors = Default.NewCounterVec("nofx_trader_errors_total",
+		"Errors recorded by a trader's error tracker", LabelTraderID, LabelType)
+
+	Equity = Default.NewGaugeVec("nofx_equity_usdt",
+		"Total account equity in USDT", LabelTraderID, LabelExchange)
+	UnrealizedPnL = Default.NewGaugeVec("nofx_unrealized_pnl_usdt",
+		"Unrealized profit and loss of open positions in USDT", LabelTraderID, LabelExchange)
+	MarginUsage = Default.NewGaugeVec("nofx_margin_usage_ratio",
+		"Used margin divided by equity (0-1)", LabelTraderID, LabelExchange)
+	OpenPositions = Default.NewGaugeVec("nofx_open_positions",
+		"Number of open positions", LabelTraderID, LabelExchange)
+	TraderRunning = Default.NewGaugeVec("nofx_trader_running",
+		"1 while the trader's main loop is running", LabelTraderID, LabelExchange)
+)
+
+// AI metrics
+var (
+	AIRequestDuration = Default.NewHistogramVec("nofx_ai_request_duration_seconds",
+		"Latency of single AI API requests", nil, LabelProvider)
+	AIRequestFailures = Default.NewCounterVec("nofx_ai_request_failures_total",
+		"AI API requests that returned an error", LabelProvider)
+	AITokens = Default.NewCounterVec("nofx_ai_tokens_total",
+		"AI tokens used, type is prompt or completion", LabelProvider, LabelType)
+)
+
+// Connectivity metrics
+var (
+	WebsocketReconnects = Default.NewCounterVec("nofx_websocket_reconnects_total",
+		"Websocket reconnect attempts", LabelStream, LabelExchange)
+)
+
+// Backtest metrics
+var (
+	BacktestProgress = Default.NewGaugeVec("nofx_backtest_progress_ratio",
+		"Completed fraction of a backtest run (0-1)", LabelRunID)
+	BacktestEquity = Default.NewGaugeVec("nofx_backtest_equity_usdt",
+		"Simulated equity of a backtest run", LabelRunID)
+)
+
+func init() {
+	Default.NewGaugeFunc("nofx_start_time_seconds", "Process start time in unix seconds", func() float64 {
+		return float64(startTime.Unix())
+	})
+	Default.NewGaugeFunc("nofx_goroutines", "Number of goroutines", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	Default.NewGaugeFunc("nofx_memory_alloc_bytes", "Bytes of allocated heap objects", func() float64 {
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return float64(m.Alloc)
+	})
+}
+
+// Handler serves the default registry
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// ObserveAIRequest records the latency and outcome of one AI API request
+func ObserveAIRequest(provider string, d time.Duration, err error) {
+	AIRequestDuration.Observe(d.Seconds(), provider)
+	if err != nil {
+		AIRequestFailures.Inc(provider)
+	}
+}
+
+// SetAccount updates the account gauges of a trader
+func SetAccount(traderID, exchange string, equity, unrealizedPnL, marginUsage float64, positions int) {
+	Equity.Set(equity, traderID, exchange)
+	UnrealizedPnL.Set(unrealizedPnL, traderID, exchange)
+	MarginUsage.Set(marginUsage, traderID, exchange)
+	OpenPositions.Set(float64(positions), traderID, exchange)
+}
+
+// RemoveTrader drops every series of a deleted trader
+func RemoveTrader(traderID string) {
+	for _, g := range []*GaugeVec{Equity, UnrealizedPnL, MarginUsage, OpenPositions, TraderRunning} {
+		g.DeleteMatching(LabelTraderID, traderID)
+	}
+	for _, c := range []*CounterVec{DecisionCycles, DecisionCycleFailures, OrdersPlaced, OrdersRejected, TraderErrors} {
+		c.DeleteMatching(LabelTraderID, traderID)
+	}
+}
@@ -0,0 +1,378 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Content types of the two supported exposition formats
+const (
+	ContentTypeText        = "text/plain; version=0.0.4; charset=utf-8"
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// DefaultBuckets latency buckets in seconds, sized for exchange and AI API calls
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120}
+
+// family is one metric name with all its labelled series
+type family interface {
+	name() string
+	write(w io.Writer, openMetrics bool)
+}
+
+// Registry holds metric families and renders them in the Prometheus text format
+type Registry struct {
+	mu       sync.RWMutex
+	families []family
+	names    map[string]bool
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+func (r *Registry) register(f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[f.name()] {
+		panic("metrics: duplicate metric " + f.name())
+	}
+	r.names[f.name()] = true
+	r.families = append(r.families, f)
+}
+
+// Write renders every family; openMetrics selects the OpenMetrics 1.0 format
+func (r *Registry) Write(w io.Writer, openMetrics bool) {
+	r.mu.RLock()
+	families := append([]family(nil), r.families...)
+	r.mu.RUnlock()
+
+	sort.Slice(families, func(i, j int) bool { return families[i].name() < families[j].name() })
+	for _, f := range families {
+		f.write(w, openMetrics)
+	}
+	if openMetrics {
+		io.WriteString(w, "# EOF\n")
+	}
+}
+
+// Handler serves the registry, negotiating OpenMetrics via the Accept header
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		openMetrics := strings.Contains(req.Header.Get("Accept"), "application/openmetrics-text")
+		if openMetrics {
+			w.Header().Set("Content-Type", ContentTypeOpenMetrics)
+		} else {
+			w.Header().Set("Content-Type", ContentTypeText)
+		}
+		r.Write(w, openMetrics)
+	})
+}
+
+// ============================================================================
+// Labelled series
+// ============================================================================
+
+// vec stores one value per label combination
+type vec[T any] struct {
+	fname  string
+	help   string
+	typ    string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series[T]
+}
+
+type series[T any] struct {
+	values []string
+	v      T
+}
+
+func newVec[T any](name, help, typ string, labels []string) *vec[T] {
+	return &vec[T]{fname: name, help: help, typ: typ, labels: labels, series: make(map[string]*series[T])}
+}
+
+func (v *vec[T]) name() string { return v.fname }
+
+// with runs fn on the series for values, creating it if needed
+func (v *vec[T]) with(values []string, init func() T, fn func(*T)) {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s expects %d label values, got %d", v.fname, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = &series[T]{values: append([]string(nil), values...), v: init()}
+		v.series[key] = s
+	}
+	fn(&s.v)
+}
+
+// delete removes the series for values
+func (v *vec[T]) delete(values []string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.series, strings.Join(values, "\xff"))
+}
+
+// deleteMatching removes every series whose label equals value
+func (v *vec[T]) deleteMatching(label, value string) {
+	idx := -1
+	for i, l := range v.labels {
+		if l == label {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for k, s := range v.series {
+		if s.values[idx] == value {
+			delete(v.series, k)
+		}
+	}
+}
+
+// sorted returns a snapshot of the series ordered by label values
+func (v *vec[T]) sorted() []series[T] {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := make([]series[T], len(keys))
+	for i, k := range keys {
+		out[i] = *v.series[k]
+		if h, ok := any(out[i].v).(histogramValue); ok {
+			// Copy bucket counts so rendering doesn't race with Observe
+			h.counts = append([]uint64(nil), h.counts...)
+			out[i].v = any(h).(T)
+		}
+	}
+	return out
+}
+
+func (v *vec[T]) writeHeader(w io.Writer, openMetrics bool) {
+	name := v.fname
+	if openMetrics && v.typ == "counter" {
+		name = strings.TrimSuffix(name, "_total")
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(v.help), name, v.typ)
+}
+
+// ============================================================================
+// Counter / Gauge / Histogram
+// ============================================================================
+
+// CounterVec monotonically increasing values; names should end in _total
+type CounterVec struct{ *vec[float64] }
+
+// NewCounterVec registers a counter family
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec[float64](name, help, "counter", labels)}
+	r.register(c)
+	return c
+}
+
+// Inc adds 1
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta (negative deltas are ignored)
+func (c *CounterVec) Add(delta float64, values ...string) {
+	if delta < 0 {
+		return
+	}
+	c.with(values, func() float64 { return 0 }, func(v *float64) { *v += delta })
+}
+
+// DeleteMatching drops every series with label=value
+func (c *CounterVec) DeleteMatching(label, value string) {
+	c.deleteMatching(label, value)
+}
+
+func (c *CounterVec) write(w io.Writer, openMetrics bool) {
+	c.writeHeader(w, openMetrics)
+	for _, s := range c.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", c.fname, formatLabels(c.labels, s.values, "", ""), formatValue(s.v))
+	}
+}
+
+// GaugeVec values that go up and down
+type GaugeVec struct{ *vec[float64] }
+
+// NewGaugeVec registers a gauge family
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{newVec[float64](name, help, "gauge", labels)}
+	r.register(g)
+	return g
+}
+
+// Set sets the value
+func (g *GaugeVec) Set(value float64, values ...string) {
+	g.with(values, func() float64 { return 0 }, func(v *float64) { *v = value })
+}
+
+// Add adds delta to the value
+func (g *GaugeVec) Add(delta float64, values ...string) {
+	g.with(values, func() float64 { return 0 }, func(v *float64) { *v += delta })
+}
+
+// Delete drops one series
+func (g *GaugeVec) Delete(values ...string) {
+	g.delete(values)
+}
+
+// DeleteMatching drops every series with label=value
+func (g *GaugeVec) DeleteMatching(label, value string) {
+	g.deleteMatching(label, value)
+}
+
+func (g *GaugeVec) write(w io.Writer, openMetrics bool) {
+	g.writeHeader(w, openMetrics)
+	for _, s := range g.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", g.fname, formatLabels(g.labels, s.values, "", ""), formatValue(s.v))
+	}
+}
+
+// gaugeFunc is a label-less gauge evaluated at scrape time
+type gaugeFunc struct {
+	fname string
+	help  string
+	fn    func() float64
+}
+
+// NewGaugeFunc registers a gauge whose value is read from fn on every scrape
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&gaugeFunc{fname: name, help: help, fn: fn})
+}
+
+func (g *gaugeFunc) name() string { return g.fname }
+
+func (g *gaugeFunc) write(w io.Writer, _ bool) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n%s %s\n", g.fname, escapeHelp(g.help), g.fname, g.fname, formatValue(g.fn()))
+}
+
+// HistogramVec distributions of observed values
+type HistogramVec struct {
+	*vec[histogramValue]
+	buckets []float64
+}
+
+type histogramValue struct {
+	counts []uint64 // per bucket, non-cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram family; nil buckets uses DefaultBuckets
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefaultBuckets
+	}
+	h := &HistogramVec{newVec[histogramValue](name, help, "histogram", labels), append([]float64(nil), buckets...)}
+	sort.Float64s(h.buckets)
+	r.register(h)
+	return h
+}
+
+// Observe records one value
+func (h *HistogramVec) Observe(value float64, values ...string) {
+	h.with(values, func() histogramValue {
+		return histogramValue{counts: make([]uint64, len(h.buckets))}
+	}, func(v *histogramValue) {
+		for i, b := range h.buckets {
+			if value <= b {
+				v.counts[i]++
+				break
+			}
+		}
+		v.count++
+		v.sum += value
+	})
+}
+
+func (h *HistogramVec) write(w io.Writer, openMetrics bool) {
+	h.writeHeader(w, openMetrics)
+	for _, s := range h.sorted() {
+		var cumulative uint64
+		for i, b := range h.buckets {
+			cumulative += s.v.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.fname, formatLabels(h.labels, s.values, "le", formatValue(b)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.fname, formatLabels(h.labels, s.values, "le", "+Inf"), s.v.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.fname, formatLabels(h.labels, s.values, "", ""), formatValue(s.v.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.fname, formatLabels(h.labels, s.values, "", ""), s.v.count)
+	}
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(n)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(values[i]))
+		b.WriteByte('"')
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(extraName)
+		b.WriteString(`="`)
+		b.WriteString(extraValue)
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpEscaper.Replace(s)
+}
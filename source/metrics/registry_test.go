@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestRegistryWrite Test text and OpenMetrics rendering of counters, gauges and histograms
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("test_orders_total", "Orders", "trader_id")
+	g := r.NewGaugeVec("test_equity", "Equity", "trader_id")
+	h := r.NewHistogramVec("test_latency_seconds", "Latency", []float64{1, 5}, "provider")
+
+	c.Inc("t1")
+	c.Add(2, "t1")
+	c.Add(-1, "t1")
+	g.Set(1234.5, `a"b`)
+	h.Observe(0.5, "deepseek")
+	h.Observe(3, "deepseek")
+	h.Observe(10, "deepseek")
+
+	var buf bytes.Buffer
+	r.Write(&buf, false)
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE test_orders_total counter\n",
+		`test_orders_total{trader_id="t1"} 3`,
+		`test_equity{trader_id="a\"b"} 1234.5`,
+		`test_latency_seconds_bucket{provider="deepseek",le="1"} 1`,
+		`test_latency_seconds_bucket{provider="deepseek",le="5"} 2`,
+		`test_latency_seconds_bucket{provider="deepseek",le="+Inf"} 3`,
+		`test_latency_seconds_count{provider="deepseek"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("text output missing %q:\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	r.Write(&buf, true)
+	out = buf.String()
+	if !strings.Contains(out, "# TYPE test_orders counter\n") || !strings.HasSuffix(out, "# EOF\n") {
+		t.Errorf("unexpected OpenMetrics output:\n%s", out)
+	}
+
+	c.DeleteMatching("trader_id", "t1")
+	buf.Reset()
+	r.Write(&buf, false)
+	if strings.Contains(buf.String(), `test_orders_total{`) {
+		t.Errorf("series not deleted:\n%s", buf.String())
+	}
+}
@@ -12,7 +12,8 @@ import (
 )
 
 const (
-	DataAPIURL = "https://data.alpaca.markets/v2"
+	DataAPIURL     = "https://data.alpaca.markets/v2"
+	maxBarsPerPage = 10000
 )
 
 // Bar represents a single OHLCV bar from Alpaca
@@ -67,6 +68,48 @@ func NewClientWithKeys(apiKey, secretKey string) *Client {
 // GetBars fetches historical bars for a symbol
 // timeframe: 1Min, 5Min, 15Min, 30Min, 1Hour, 4Hour, 1Day, 1Week, 1Month
 func (c *Client) GetBars(ctx context.Context, symbol string, timeframe string, limit int) ([]Bar, error) {
+	// Set time range: last 30 days for intraday, last 2 years for daily
+	now := time.Now()
+	var start time.Time
+	switch timeframe {
+	case "1Day", "1Week", "1Month":
+		start = now.AddDate(-2, 0, 0) // 2 years back
+	default:
+		start = now.AddDate(0, 0, -30) // 30 days back for intraday
+	}
+
+	// Newest first, so that limit keeps the latest bars; returned oldest first
+	result, err := c.getBarsPage(ctx, symbol, timeframe, start, now, limit, "desc", "")
+	if err != nil {
+		return nil, err
+	}
+	bars := result.Bars
+	for i, j := 0, len(bars)-1; i < j; i, j = i+1, j-1 {
+		bars[i], bars[j] = bars[j], bars[i]
+	}
+	return bars, nil
+}
+
+// GetBarsRange fetches all bars between start and end, following pagination
+func (c *Client) GetBarsRange(ctx context.Context, symbol string, timeframe string, start, end time.Time) ([]Bar, error) {
+	var all []Bar
+	pageToken := ""
+	for {
+		result, err := c.getBarsPage(ctx, symbol, timeframe, start, end, maxBarsPerPage, "asc", pageToken)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, result.Bars...)
+		if result.NextPageToken == "" {
+			break
+		}
+		pageToken = result.NextPageToken
+	}
+	return all, nil
+}
+
+// getBarsPage fetches a single page of bars; sort is "asc" or "desc" by timestamp
+func (c *Client) getBarsPage(ctx context.Context, symbol, timeframe string, start, end time.Time, limit int, sort, pageToken string) (*BarsResponse, error) {
 	if c.apiKey == "" || c.secretKey == "" {
 		return nil, fmt.Errorf("alpaca API keys not configured")
 	}
@@ -78,18 +121,12 @@ func (c *Client) GetBars(ctx context.Context, symbol string, timeframe string, l
 	params.Set("limit", fmt.Sprintf("%d", limit))
 	params.Set("adjustment", "raw")
 	params.Set("feed", "iex") // Use IEX feed (free tier)
-
-	// Set time range: last 30 days for intraday, last 2 years for daily
-	now := time.Now()
-	var start time.Time
-	switch timeframe {
-	case "1Day", "1Week", "1Month":
-		start = now.AddDate(-2, 0, 0) // 2 years back
-	default:
-		start = now.AddDate(0, 0, -30) // 30 days back for intraday
-	}
 	params.Set("start", start.Format(time.RFC3339))
-	params.Set("end", now.Format(time.RFC3339))
+	params.Set("end", end.Format(time.RFC3339))
+	params.Set("sort", sort)
+	if pageToken != "" {
+		params.Set("page_token", pageToken)
+	}
 
 	fullURL := endpoint + "?" + params.Encode()
 
@@ -127,7 +164,7 @@ func (c *Client) GetBars(ctx context.Context, symbol string, timeframe string, l
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	return result.Bars, nil
+	return &result, nil
 }
 
 // MapTimeframe maps common timeframe strings to Alpaca format
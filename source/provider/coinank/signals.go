@@ -0,0 +1,286 @@
+package coinank
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Language represents the language for formatting output
+type Language string
+
+const (
+	LangChinese Language = "zh-CN"
+	LangEnglish Language = "en-US"
+)
+
+// LiquidationCluster large liquidation orders grouped into one price band
+type LiquidationCluster struct {
+	PriceLow      float64 `json:"price_low"`
+	PriceHigh     float64 `json:"price_high"`
+	LongTurnover  float64 `json:"long_turnover"`  // liquidated long value (USD)
+	ShortTurnover float64 `json:"short_turnover"` // liquidated short value (USD)
+	Count         int     `json:"count"`
+}
+
+// Total total liquidated value of the cluster
+func (c LiquidationCluster) Total() float64 {
+	return c.LongTurnover + c.ShortTurnover
+}
+
+// LiquidationSummary recent liquidation statistics of a single coin
+type LiquidationSummary struct {
+	Interval           string               `json:"interval"` // bar interval of the history
+	Bars               int                  `json:"bars"`     // number of bars aggregated in Total*
+	LastLongTurnover   float64              `json:"last_long_turnover"`
+	LastShortTurnover  float64              `json:"last_short_turnover"`
+	TotalLongTurnover  float64              `json:"total_long_turnover"`
+	TotalShortTurnover float64              `json:"total_short_turnover"`
+	Clusters           []LiquidationCluster `json:"clusters,omitempty"`
+}
+
+// LongShortSummary top-trader long/short ratio and net positions of a single coin
+type LongShortSummary struct {
+	Ratio       float64 `json:"ratio"`  // long/short account ratio
+	Chg1H       float64 `json:"chg_1h"` // ratio change (%)
+	Chg4H       float64 `json:"chg_4h"`
+	HasNetPos   bool    `json:"has_net_pos"`
+	NetLongs    int     `json:"net_longs"`
+	NetShorts   int     `json:"net_shorts"`
+	NetLongsChg int     `json:"net_longs_chg"` // change vs previous bar
+	NetShortChg int     `json:"net_shorts_chg"`
+}
+
+// OIVsMarketCapSummary open interest / market cap ratios of a single coin
+type OIVsMarketCapSummary struct {
+	OIVsMarketCap   float64 `json:"oi_vs_market_cap"`
+	VolVsMarketCap  float64 `json:"vol_vs_market_cap"`
+	OIVsVolume      float64 `json:"oi_vs_volume"`
+	OIVsMarketCapCh float64 `json:"oi_vs_market_cap_change"` // change over the fetched window (%)
+}
+
+// SymbolSignals derivatives positioning data of a single coin for AI consumption
+type SymbolSignals struct {
+	Symbol      string                `json:"symbol"`
+	BaseCoin    string                `json:"base_coin"`
+	Liquidation *LiquidationSummary   `json:"liquidation,omitempty"`
+	LongShort   *LongShortSummary     `json:"long_short,omitempty"`
+	OIVsMC      *OIVsMarketCapSummary `json:"oi_vs_mc,omitempty"`
+	FetchedAt   time.Time             `json:"fetched_at"`
+}
+
+// IsEmpty reports whether no section has data
+func (s *SymbolSignals) IsEmpty() bool {
+	return s == nil || (s.Liquidation == nil && s.LongShort == nil && s.OIVsMC == nil)
+}
+
+// BuildLiquidationSummary aggregates liquidation history bars (oldest → latest) and large orders
+func BuildLiquidationSummary(interval string, history []LiquidationSymbol, orders []LiquidationOrdersResponse,
+	bucketPct float64, maxClusters int) *LiquidationSummary {
+	if len(history) == 0 && len(orders) == 0 {
+		return nil
+	}
+
+	sorted := make([]LiquidationSymbol, len(history))
+	copy(sorted, history)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Ts < sorted[j].Ts })
+
+	summary := &LiquidationSummary{
+		Interval: interval,
+		Bars:     len(sorted),
+	}
+	for _, bar := range sorted {
+		summary.TotalLongTurnover += bar.LongTurnover
+		summary.TotalShortTurnover += bar.ShortTurnover
+	}
+	if len(sorted) > 0 {
+		last := sorted[len(sorted)-1]
+		summary.LastLongTurnover = last.LongTurnover
+		summary.LastShortTurnover = last.ShortTurnover
+	}
+	summary.Clusters = BuildLiquidationClusters(orders, bucketPct, maxClusters)
+
+	return summary
+}
+
+// BuildLiquidationClusters groups liquidation orders into price bands of bucketPct percent
+// (relative to the average order price) and returns the largest bands by turnover
+func BuildLiquidationClusters(orders []LiquidationOrdersResponse, bucketPct float64, maxClusters int) []LiquidationCluster {
+	if len(orders) == 0 {
+		return nil
+	}
+	if bucketPct <= 0 {
+		bucketPct = 0.5
+	}
+	if maxClusters <= 0 {
+		maxClusters = 3
+	}
+
+	var priceSum float64
+	var priced int
+	for _, o := range orders {
+		if p := orderPrice(o); p > 0 {
+			priceSum += p
+			priced++
+		}
+	}
+	if priced == 0 {
+		return nil
+	}
+	width := priceSum / float64(priced) * bucketPct / 100
+
+	buckets := make(map[int64]*LiquidationCluster)
+	for _, o := range orders {
+		price := orderPrice(o)
+		if price <= 0 {
+			continue
+		}
+		idx := int64(math.Floor(price / width))
+		cluster, ok := buckets[idx]
+		if !ok {
+			cluster = &LiquidationCluster{
+				PriceLow:  float64(idx) * width,
+				PriceHigh: float64(idx+1) * width,
+			}
+			buckets[idx] = cluster
+		}
+		if strings.EqualFold(o.PosSide, "short") {
+			cluster.ShortTurnover += o.TradeTurnover
+		} else {
+			cluster.LongTurnover += o.TradeTurnover
+		}
+		cluster.Count++
+	}
+
+	clusters := make([]LiquidationCluster, 0, len(buckets))
+	for _, c := range buckets {
+		clusters = append(clusters, *c)
+	}
+	sort.Slice(clusters, func(i, j int) bool { return clusters[i].Total() > clusters[j].Total() })
+	if len(clusters) > maxClusters {
+		clusters = clusters[:maxClusters]
+	}
+	return clusters
+}
+
+func orderPrice(o LiquidationOrdersResponse) float64 {
+	if o.AvgPrice > 0 {
+		return o.AvgPrice
+	}
+	return o.Price
+}
+
+// FormatSignalsForAI formats derivatives positioning data of a single coin for AI consumption
+func FormatSignalsForAI(data *SymbolSignals, lang Language) string {
+	if data.IsEmpty() {
+		return ""
+	}
+
+	if lang == LangChinese {
+		return formatSignalsZH(data)
+	}
+	return formatSignalsEN(data)
+}
+
+func formatSignalsZH(data *SymbolSignals) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🧨 %s 衍生品持仓数据:\n", data.Symbol))
+
+	if liq := data.Liquidation; liq != nil {
+		if liq.Bars > 0 {
+			sb.WriteString(fmt.Sprintf("爆仓: 最近%s 多单 %s / 空单 %s | 近%d根合计 多单 %s / 空单 %s\n",
+				liq.Interval, formatUSD(liq.LastLongTurnover), formatUSD(liq.LastShortTurnover),
+				liq.Bars, formatUSD(liq.TotalLongTurnover), formatUSD(liq.TotalShortTurnover)))
+		}
+		if len(liq.Clusters) > 0 {
+			sb.WriteString("大额爆仓价格密集区:\n")
+			for _, c := range liq.Clusters {
+				sb.WriteString(fmt.Sprintf("  %s-%s: 多单 %s / 空单 %s (%d笔)\n",
+					formatPrice(c.PriceLow), formatPrice(c.PriceHigh),
+					formatUSD(c.LongTurnover), formatUSD(c.ShortTurnover), c.Count))
+			}
+		}
+	}
+
+	if ls := data.LongShort; ls != nil {
+		sb.WriteString(fmt.Sprintf("大户多空比: %.2f (1h: %+.2f%%, 4h: %+.2f%%)", ls.Ratio, ls.Chg1H, ls.Chg4H))
+		if ls.HasNetPos {
+			sb.WriteString(fmt.Sprintf(" | 净多头: %d (%+d) 净空头: %d (%+d)",
+				ls.NetLongs, ls.NetLongsChg, ls.NetShorts, ls.NetShortChg))
+		}
+		sb.WriteString("\n")
+	}
+
+	if oi := data.OIVsMC; oi != nil {
+		sb.WriteString(fmt.Sprintf("持仓/市值: %.4f (变化 %+.2f%%) | 成交/市值: %.4f | 持仓/成交: %.4f\n",
+			oi.OIVsMarketCap, oi.OIVsMarketCapCh, oi.VolVsMarketCap, oi.OIVsVolume))
+	}
+
+	sb.WriteString("**解读**: 单边大额爆仓常见于局部极值 | 多空比极端偏离易被反向挤压 | 持仓/市值偏高=杠杆拥挤\n")
+	return sb.String()
+}
+
+func formatSignalsEN(data *SymbolSignals) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🧨 %s Derivatives Positioning:\n", data.Symbol))
+
+	if liq := data.Liquidation; liq != nil {
+		if liq.Bars > 0 {
+			sb.WriteString(fmt.Sprintf("Liquidations: last %s longs %s / shorts %s | last %d bars longs %s / shorts %s\n",
+				liq.Interval, formatUSD(liq.LastLongTurnover), formatUSD(liq.LastShortTurnover),
+				liq.Bars, formatUSD(liq.TotalLongTurnover), formatUSD(liq.TotalShortTurnover)))
+		}
+		if len(liq.Clusters) > 0 {
+			sb.WriteString("Large liquidation clusters:\n")
+			for _, c := range liq.Clusters {
+				sb.WriteString(fmt.Sprintf("  %s-%s: longs %s / shorts %s (%d orders)\n",
+					formatPrice(c.PriceLow), formatPrice(c.PriceHigh),
+					formatUSD(c.LongTurnover), formatUSD(c.ShortTurnover), c.Count))
+			}
+		}
+	}
+
+	if ls := data.LongShort; ls != nil {
+		sb.WriteString(fmt.Sprintf("Top-trader L/S ratio: %.2f (1h: %+.2f%%, 4h: %+.2f%%)", ls.Ratio, ls.Chg1H, ls.Chg4H))
+		if ls.HasNetPos {
+			sb.WriteString(fmt.Sprintf(" | Net longs: %d (%+d) Net shorts: %d (%+d)",
+				ls.NetLongs, ls.NetLongsChg, ls.NetShorts, ls.NetShortChg))
+		}
+		sb.WriteString("\n")
+	}
+
+	if oi := data.OIVsMC; oi != nil {
+		sb.WriteString(fmt.Sprintf("OI/MarketCap: %.4f (change %+.2f%%) | Vol/MarketCap: %.4f | OI/Vol: %.4f\n",
+			oi.OIVsMarketCap, oi.OIVsMarketCapCh, oi.VolVsMarketCap, oi.OIVsVolume))
+	}
+
+	sb.WriteString("**Key**: One-sided liquidation spikes often mark local extremes | Extreme L/S ratio = squeeze risk | High OI/MarketCap = crowded leverage\n")
+	return sb.String()
+}
+
+// formatUSD formats a USD amount with K/M/B suffix
+func formatUSD(v float64) string {
+	absV := math.Abs(v)
+	switch {
+	case absV >= 1e9:
+		return fmt.Sprintf("$%.2fB", v/1e9)
+	case absV >= 1e6:
+		return fmt.Sprintf("$%.2fM", v/1e6)
+	case absV >= 1e3:
+		return fmt.Sprintf("$%.2fK", v/1e3)
+	}
+	return fmt.Sprintf("$%.2f", v)
+}
+
+// formatPrice formats a price with precision depending on magnitude
+func formatPrice(p float64) string {
+	switch {
+	case p >= 1000:
+		return fmt.Sprintf("%.0f", p)
+	case p >= 1:
+		return fmt.Sprintf("%.2f", p)
+	}
+	return fmt.Sprintf("%.6f", p)
+}
@@ -0,0 +1,31 @@
+package coinank
+
+import "testing"
+
+// TestBuildLiquidationClusters 测试爆仓价格密集区聚合
+func TestBuildLiquidationClusters(t *testing.T) {
+	orders := []LiquidationOrdersResponse{
+		{PosSide: "long", AvgPrice: 100.0, TradeTurnover: 50000},
+		{PosSide: "long", AvgPrice: 100.2, TradeTurnover: 30000},
+		{PosSide: "short", AvgPrice: 110.0, TradeTurnover: 20000},
+		{PosSide: "short", Price: 110.1, TradeTurnover: 5000},
+	}
+
+	clusters := BuildLiquidationClusters(orders, 0.5, 3)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+	if clusters[0].LongTurnover != 80000 || clusters[0].Count != 2 {
+		t.Errorf("largest cluster should hold both long orders, got %+v", clusters[0])
+	}
+	if clusters[1].ShortTurnover != 25000 {
+		t.Errorf("second cluster should hold short orders, got %+v", clusters[1])
+	}
+	if clusters[0].PriceLow > 100.0 || clusters[0].PriceHigh < 100.2 {
+		t.Errorf("cluster band [%.2f, %.2f] should contain its orders", clusters[0].PriceLow, clusters[0].PriceHigh)
+	}
+
+	if got := BuildLiquidationClusters(nil, 0.5, 3); got != nil {
+		t.Errorf("expected nil clusters for no orders, got %v", got)
+	}
+}
@@ -75,9 +75,6 @@ func NewTestnetClient() *Client {
 // interval: "1m", "5m", "15m", "1h", "4h", "1d"
 // limit: number of candles to fetch (max 5000)
 func (c *Client) GetCandles(ctx context.Context, coin string, interval string, limit int) ([]Candle, error) {
-	// Format coin name for API (stock perps need xyz: prefix)
-	coin = FormatCoinForAPI(coin)
-
 	// Calculate time range based on interval and limit
 	now := time.Now()
 	endTime := now.UnixMilli()
@@ -86,6 +83,15 @@ func (c *Client) GetCandles(ctx context.Context, coin string, interval string, l
 	intervalDuration := getIntervalDuration(interval)
 	startTime := now.Add(-intervalDuration * time.Duration(limit)).UnixMilli()
 
+	return c.GetCandlesRange(ctx, coin, interval, startTime, endTime)
+}
+
+// GetCandlesRange fetches candlestick data between startTime and endTime (Unix ms)
+// The API returns at most 5000 candles per request
+func (c *Client) GetCandlesRange(ctx context.Context, coin string, interval string, startTime, endTime int64) ([]Candle, error) {
+	// Format coin name for API (stock perps need xyz: prefix)
+	coin = FormatCoinForAPI(coin)
+
 	// Build request
 	reqBody := CandleRequest{
 		Type: "candleSnapshot",
@@ -0,0 +1,64 @@
+package hyperliquid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// L2Level represents a single aggregated order book level
+type L2Level struct {
+	Price  string `json:"px"` // Price
+	Size   string `json:"sz"` // Size in base unit
+	Orders int    `json:"n"`  // Number of orders at this level
+}
+
+// L2Book represents an order book snapshot (Levels[0] = bids, Levels[1] = asks)
+type L2Book struct {
+	Coin   string       `json:"coin"`
+	Time   int64        `json:"time"`
+	Levels [2][]L2Level `json:"levels"`
+}
+
+// GetL2Book fetches the order book snapshot for a symbol (up to 20 levels per side)
+func (c *Client) GetL2Book(ctx context.Context, coin string) (*L2Book, error) {
+	reqBody := map[string]string{
+		"type": "l2Book",
+		"coin": FormatCoinForAPI(coin),
+	}
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("hyperliquid API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var book L2Book
+	if err := json.Unmarshal(body, &book); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &book, nil
+}
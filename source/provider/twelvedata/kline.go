@@ -13,7 +13,8 @@ import (
 )
 
 const (
-	BaseURL = "https://api.twelvedata.com"
+	BaseURL       = "https://api.twelvedata.com"
+	MaxOutputSize = 5000
 )
 
 // Bar represents a single OHLCV bar from Twelve Data
@@ -94,19 +95,36 @@ func NewClientWithKey(apiKey string) *Client {
 	}
 }
 
-// GetTimeSeries fetches historical bars for a symbol
+// GetTimeSeries fetches the latest bars for a symbol (UTC datetimes, newest first)
 // interval: 1min, 5min, 15min, 30min, 45min, 1h, 2h, 4h, 1day, 1week, 1month
 func (c *Client) GetTimeSeries(ctx context.Context, symbol string, interval string, limit int) (*TimeSeriesResponse, error) {
+	params := url.Values{}
+	params.Set("outputsize", fmt.Sprintf("%d", limit))
+	params.Set("timezone", "UTC")
+	return c.getTimeSeries(ctx, symbol, interval, params)
+}
+
+// GetTimeSeriesRange fetches bars between start and end (up to 5000 bars, the API maximum)
+func (c *Client) GetTimeSeriesRange(ctx context.Context, symbol string, interval string, start, end time.Time) (*TimeSeriesResponse, error) {
+	params := url.Values{}
+	params.Set("start_date", start.UTC().Format("2006-01-02 15:04:05"))
+	params.Set("end_date", end.UTC().Format("2006-01-02 15:04:05"))
+	params.Set("timezone", "UTC")
+	params.Set("order", "ASC")
+	params.Set("outputsize", fmt.Sprintf("%d", MaxOutputSize))
+	return c.getTimeSeries(ctx, symbol, interval, params)
+}
+
+// getTimeSeries calls the time_series endpoint with extra query parameters
+func (c *Client) getTimeSeries(ctx context.Context, symbol string, interval string, params url.Values) (*TimeSeriesResponse, error) {
 	if c.apiKey == "" {
 		return nil, fmt.Errorf("twelve data API key not configured")
 	}
 
 	// Build URL
 	endpoint := fmt.Sprintf("%s/time_series", BaseURL)
-	params := url.Values{}
 	params.Set("symbol", symbol)
 	params.Set("interval", interval)
-	params.Set("outputsize", fmt.Sprintf("%d", limit))
 	params.Set("apikey", c.apiKey)
 
 	fullURL := endpoint + "?" + params.Encode()
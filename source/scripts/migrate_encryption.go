@@ -72,14 +72,14 @@ func main() {
 func migrateExchanges(db *sql.DB, cs *crypto.CryptoService) error {
 	log.Println("🔄 Migrating exchange configurations...")
 
-	// Query all unencrypted records (encrypted data starts with ENC:v1:)
+	// Query all unencrypted records (encrypted data starts with ENC:)
 	rows, err := db.Query(`
 		SELECT user_id, id, api_key, secret_key,
 		       COALESCE(hyperliquid_private_key, ''),
 		       COALESCE(aster_private_key, '')
 		FROM exchanges
-		WHERE (api_key != '' AND api_key NOT LIKE 'ENC:v1:%')
-		   OR (secret_key != '' AND secret_key NOT LIKE 'ENC:v1:%')
+		WHERE (api_key != '' AND api_key NOT LIKE 'ENC:%')
+		   OR (secret_key != '' AND secret_key NOT LIKE 'ENC:%')
 	`)
 	if err != nil {
 		return err
@@ -157,7 +157,7 @@ func migrateAIModels(db *sql.DB, cs *crypto.CryptoService) error {
 	rows, err := db.Query(`
 		SELECT user_id, id, api_key
 		FROM ai_models
-		WHERE api_key != '' AND api_key NOT LIKE 'ENC:v1:%'
+		WHERE api_key != '' AND api_key NOT LIKE 'ENC:%'
 	`)
 	if err != nil {
 		return err
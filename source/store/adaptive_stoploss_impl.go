@@ -94,16 +94,3 @@ func (a *AdaptiveStopLossImpl) DeleteClosedRecords(traderID string) error {
 	return a.db.Where("trader_id = ? AND status = ?", traderID, "CLOSED").
 		Delete(&AdaptiveStopLossRecord{}).Error
 }
-
-// InitSchema 初始化表结构
-func (a *AdaptiveStopLossImpl) InitSchema() error {
-	if err := a.db.AutoMigrate(&AdaptiveStopLossRecord{}); err != nil {
-		return fmt.Errorf("failed to migrate adaptive_stoploss_records table: %w", err)
-	}
-
-	// 创建索引
-	_ = a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_adaptive_trader_symbol ON adaptive_stoploss_records(trader_id, symbol)`)
-	_ = a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_adaptive_status ON adaptive_stoploss_records(status)`)
-
-	return nil
-}
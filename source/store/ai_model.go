@@ -37,18 +37,6 @@ func NewAIModelStore(db *gorm.DB) *AIModelStore {
 	return &AIModelStore{db: db}
 }
 
-func (s *AIModelStore) initTables() error {
-	// For PostgreSQL with existing table, skip AutoMigrate
-	if s.db.Dialector.Name() == "postgres" {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'ai_models'`).Scan(&tableExists)
-		if tableExists > 0 {
-			return nil
-		}
-	}
-	return s.db.AutoMigrate(&AIModel{})
-}
-
 func (s *AIModelStore) initDefaultData() error {
 	// No longer pre-populate AI models - create on demand when user configures
 	return nil
@@ -325,25 +325,6 @@ func (a *AnalysisImpl) GetTradeHistoryInPeriod(traderID string, startTime, endTi
 	return trades, err
 }
 
-// InitSchema 初始化表结构
-func (a *AnalysisImpl) InitSchema() error {
-	if err := a.db.AutoMigrate(
-		&AnalysisRecord{},
-		&PendingOrder{},
-		&TradeHistoryRecord{},
-	); err != nil {
-		return fmt.Errorf("failed to migrate tables: %w", err)
-	}
-
-	// 创建索引（如果不存在）
-	// 这些索引是在 struct tags 中定义的，但如果已存在则忽略
-	_ = a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_trader_symbol_time ON ai_analysis(trader_id, symbol, created_at DESC)`)
-	_ = a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_trader_status ON ai_analysis(trader_id, status)`)
-	_ = a.db.Exec(`CREATE INDEX IF NOT EXISTS idx_symbol_time ON ai_analysis(symbol, created_at DESC)`)
-
-	return nil
-}
-
 // TryMarkAsExecuting 尝试原子地标记订单为正在执行
 // 返回 true 如果成功标记，false 如果已被其他进程标记
 func (a *AnalysisImpl) TryMarkAsExecuting(orderID string) bool {
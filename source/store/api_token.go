@@ -0,0 +1,102 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APITokenStore personal API token storage
+type APITokenStore struct {
+	db *gorm.DB
+}
+
+// APIToken long-lived personal API token, only the SHA-256 hash of the token is stored
+type APIToken struct {
+	ID                 string     `gorm:"primaryKey" json:"id"`
+	UserID             string     `gorm:"column:user_id;not null;index:idx_api_tokens_user" json:"user_id"`
+	Name               string     `gorm:"not null" json:"name"`
+	TokenHash          string     `gorm:"column:token_hash;not null;uniqueIndex:idx_api_tokens_hash" json:"-"`
+	TokenPrefix        string     `gorm:"column:token_prefix" json:"token_prefix"` // first characters, to recognize a token in the UI
+	Scopes             string     `gorm:"not null" json:"-"`                       // comma-separated
+	RateLimitPerMinute int        `gorm:"column:rate_limit_per_minute;default:0" json:"rate_limit_per_minute"`
+	ExpiresAt          *time.Time `gorm:"column:expires_at" json:"expires_at,omitempty"`
+	LastUsedAt         *time.Time `gorm:"column:last_used_at" json:"last_used_at,omitempty"`
+	LastUsedIP         string     `gorm:"column:last_used_ip" json:"last_used_ip,omitempty"`
+	RevokedAt          *time.Time `gorm:"column:revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+func (APIToken) TableName() string { return "api_tokens" }
+
+// ScopeList returns the scopes of the token
+func (t *APIToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return nil
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// Active reports whether the token is neither revoked nor expired
+func (t *APIToken) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// NewAPITokenStore creates a new APITokenStore
+func NewAPITokenStore(db *gorm.DB) *APITokenStore {
+	return &APITokenStore{db: db}
+}
+
+// Create creates an API token
+func (s *APITokenStore) Create(token *APIToken) error {
+	if err := s.db.Create(token).Error; err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+	return nil
+}
+
+// GetByHash gets an API token by its hash
+func (s *APITokenStore) GetByHash(tokenHash string) (*APIToken, error) {
+	var token APIToken
+	if err := s.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// List lists the API tokens of a user, newest first
+func (s *APITokenStore) List(userID string) ([]*APIToken, error) {
+	var tokens []*APIToken
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query API tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke revokes an API token of a user
+func (s *APITokenStore) Revoke(userID, id string) error {
+	result := s.db.Model(&APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed records the last use of an API token
+func (s *APITokenStore) TouchLastUsed(id, ip string, at time.Time) error {
+	return s.db.Model(&APIToken{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_used_at": at.UTC(),
+		"last_used_ip": ip,
+	}).Error
+}
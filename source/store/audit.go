@@ -0,0 +1,221 @@
+package store
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Audit actor types
+const (
+	AuditActorUser     = "user"      // Logged-in user
+	AuditActorAPIToken = "api_token" // User acting through a personal API token
+	AuditActorSystem   = "system"    // Automated component (trader, risk control, reflection)
+)
+
+// AuditStore append-only audit log storage
+// Entries are hash-chained: each hash covers the entry and the hash of the previous entry,
+// so editing or deleting an entry breaks the chain from that point on
+type AuditStore struct {
+	db *gorm.DB
+	mu sync.Mutex // serializes appends so the chain has a single head
+}
+
+// AuditEntry a state-changing action
+type AuditEntry struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	OwnerID    string    `gorm:"column:owner_id;not null;index:idx_audit_logs_owner" json:"owner_id"` // user or workspace whose resources were changed
+	Actor      string    `gorm:"not null" json:"actor"`                                               // user ID, or component name for system actors
+	ActorType  string    `gorm:"column:actor_type;not null" json:"actor_type"`
+	APITokenID string    `gorm:"column:api_token_id" json:"api_token_id,omitempty"`
+	Action     string    `gorm:"not null;index:idx_audit_logs_action" json:"action"`
+	TargetType string    `gorm:"column:target_type" json:"target_type"`
+	TargetID   string    `gorm:"column:target_id;index:idx_audit_logs_target" json:"target_id"`
+	Before     string    `gorm:"type:text" json:"before,omitempty"` // JSON summary of the state before the change
+	After      string    `gorm:"type:text" json:"after,omitempty"`  // JSON summary of the state after the change
+	IP         string    `gorm:"column:ip" json:"ip,omitempty"`
+	Status     int       `gorm:"default:0" json:"status"` // HTTP status for API actions, 0 for automated actions
+	CreatedAt  time.Time `gorm:"index:idx_audit_logs_created" json:"created_at"`
+	PrevHash   string    `gorm:"column:prev_hash" json:"prev_hash"`
+	Hash       string    `gorm:"not null;uniqueIndex:idx_audit_logs_hash" json:"hash"`
+}
+
+func (AuditEntry) TableName() string { return "audit_logs" }
+
+// AuditQuery audit log filter, empty fields are ignored
+type AuditQuery struct {
+	OwnerID    string
+	Actor      string
+	Action     string
+	TargetType string
+	TargetID   string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditVerifyResult result of a hash chain verification
+type AuditVerifyResult struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	HeadHash string `json:"head_hash"` // record it externally to also detect truncation of the newest entries
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// AuditSummary encodes a before/after state for an audit entry, nil gives an empty summary
+func AuditSummary(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// ComputeAuditHash computes the chain hash of an entry from its content and the previous hash
+func ComputeAuditHash(prevHash string, e *AuditEntry) string {
+	// A JSON array keeps field boundaries unambiguous
+	content, _ := json.Marshal([]interface{}{
+		prevHash, e.OwnerID, e.Actor, e.ActorType, e.APITokenID, e.Action,
+		e.TargetType, e.TargetID, e.Before, e.After, e.IP, e.Status, e.CreatedAt.UTC().UnixMicro(),
+	})
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain checks the links and hashes of consecutive entries (ordered by ID)
+// prevHash is the hash of the entry preceding the first one, empty at the start of the log
+func VerifyAuditChain(prevHash string, entries []*AuditEntry) (brokenAt int64, reason string) {
+	for _, e := range entries {
+		if e.PrevHash != prevHash {
+			return e.ID, "previous hash does not match, an entry was removed or reordered"
+		}
+		if ComputeAuditHash(prevHash, e) != e.Hash {
+			return e.ID, "hash does not match the entry content, the entry was modified"
+		}
+		prevHash = e.Hash
+	}
+	return 0, ""
+}
+
+// NewAuditStore creates a new AuditStore
+func NewAuditStore(db *gorm.DB) *AuditStore {
+	return &AuditStore{db: db}
+}
+
+// Append appends an entry to the log, filling in timestamp and chain hashes
+func (s *AuditStore) Append(e *AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var last AuditEntry
+		err := tx.Select("hash").Order("id DESC").Limit(1).Find(&last).Error
+		if err != nil {
+			return fmt.Errorf("failed to read audit log head: %w", err)
+		}
+
+		e.ID = 0
+		// Timestamps are stored with microsecond precision on all databases
+		e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+		e.PrevHash = last.Hash
+		e.Hash = ComputeAuditHash(e.PrevHash, e)
+		if err := tx.Create(e).Error; err != nil {
+			return fmt.Errorf("failed to append audit entry: %w", err)
+		}
+		return nil
+	})
+}
+
+// Record appends an entry from an automated actor
+func (s *AuditStore) Record(ownerID, actor, action, targetType, targetID string, before, after interface{}) error {
+	return s.Append(&AuditEntry{
+		OwnerID:    ownerID,
+		Actor:      actor,
+		ActorType:  AuditActorSystem,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     AuditSummary(before),
+		After:      AuditSummary(after),
+	})
+}
+
+// List lists entries matching the query, newest first
+func (s *AuditStore) List(q AuditQuery) ([]*AuditEntry, error) {
+	db := s.db.Model(&AuditEntry{})
+	if q.OwnerID != "" {
+		db = db.Where("owner_id = ?", q.OwnerID)
+	}
+	if q.Actor != "" {
+		db = db.Where("actor = ?", q.Actor)
+	}
+	if q.Action != "" {
+		db = db.Where("action = ?", q.Action)
+	}
+	if q.TargetType != "" {
+		db = db.Where("target_type = ?", q.TargetType)
+	}
+	if q.TargetID != "" {
+		db = db.Where("target_id = ?", q.TargetID)
+	}
+	if q.Since != nil {
+		db = db.Where("created_at >= ?", q.Since.UTC())
+	}
+	if q.Until != nil {
+		db = db.Where("created_at < ?", q.Until.UTC())
+	}
+	if q.Limit > 0 {
+		db = db.Limit(q.Limit)
+	}
+	if q.Offset > 0 {
+		db = db.Offset(q.Offset)
+	}
+
+	var entries []*AuditEntry
+	if err := db.Order("id DESC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Verify walks the whole log in ID order and checks the hash chain
+func (s *AuditStore) Verify() (*AuditVerifyResult, error) {
+	const batchSize = 1000
+
+	result := &AuditVerifyResult{Valid: true}
+	var lastID int64
+	prevHash := ""
+	for {
+		var batch []*AuditEntry
+		if err := s.db.Where("id > ?", lastID).Order("id ASC").Limit(batchSize).Find(&batch).Error; err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		if len(batch) == 0 {
+			break
+		}
+		if brokenAt, reason := VerifyAuditChain(prevHash, batch); brokenAt != 0 {
+			result.Valid = false
+			result.BrokenAt = brokenAt
+			result.Reason = reason
+			return result, nil
+		}
+		result.Checked += len(batch)
+		lastID = batch[len(batch)-1].ID
+		prevHash = batch[len(batch)-1].Hash
+	}
+	result.HeadHash = prevHash
+	return result, nil
+}
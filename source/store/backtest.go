@@ -2,7 +2,6 @@ package store
 
 import (
 	"encoding/json"
-	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -209,40 +208,6 @@ func (BacktestDecision) TableName() string {
 	return "backtest_decisions"
 }
 
-// initTables initializes backtest related tables
-func (s *BacktestStore) initTables() error {
-	// For PostgreSQL with existing tables, skip AutoMigrate to avoid type conflicts
-	if s.db.Dialector.Name() == "postgres" {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'backtest_runs'`).Scan(&tableExists)
-
-		if tableExists > 0 {
-			// Tables exist - fix column types and ensure indexes exist
-			// Fix ts column type from INTEGER to BIGINT (timestamps in milliseconds exceed int4 max)
-			s.db.Exec(`ALTER TABLE backtest_equity ALTER COLUMN ts TYPE BIGINT`)
-			s.db.Exec(`ALTER TABLE backtest_trades ALTER COLUMN ts TYPE BIGINT`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_backtest_equity_run_ts ON backtest_equity(run_id, ts)`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_backtest_trades_run_ts ON backtest_trades(run_id, ts)`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_backtest_decisions_run_cycle ON backtest_decisions(run_id, cycle)`)
-			return nil
-		}
-	}
-
-	// AutoMigrate all backtest tables
-	if err := s.db.AutoMigrate(
-		&BacktestRun{},
-		&BacktestCheckpoint{},
-		&BacktestEquity{},
-		&BacktestTrade{},
-		&BacktestMetrics{},
-		&BacktestDecision{},
-	); err != nil {
-		return fmt.Errorf("failed to migrate backtest tables: %w", err)
-	}
-
-	return nil
-}
-
 // SaveCheckpoint saves checkpoint
 func (s *BacktestStore) SaveCheckpoint(runID string, payload []byte) error {
 	checkpoint := BacktestCheckpoint{
@@ -0,0 +1,668 @@
+package store
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"nofx/crypto"
+	"nofx/logger"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Backup archive layout: a gzip-compressed tar holding metadata.json followed by one
+// <table>.jsonl per table (one JSON object per row). Column values are copied raw, so
+// encrypted secrets stay encrypted and the archive can be restored into SQLite or PostgreSQL
+const (
+	BackupFormatVersion = 1
+	BackupFilePrefix    = "nofx-backup-"
+	BackupFileSuffix    = ".tar.gz"
+
+	backupMetadataFile = "metadata.json"
+	backupBatchSize    = 500
+)
+
+// errRestoreDryRun rolls back a dry-run restore
+var errRestoreDryRun = errors.New("restore dry run")
+
+// systemConfigRow describes the system_config table for backups
+type systemConfigRow struct {
+	Key   string `gorm:"column:key;primaryKey"`
+	Value string `gorm:"column:value"`
+}
+
+func (systemConfigRow) TableName() string { return "system_config" }
+
+// backupModels tables included in backups, in restore order. These are the current models rather than
+// the frozen migration structs, so columns added by later migrations are typed too (booleans and times
+// are normalized from their field type). Tables created by later migrations are appended here.
+var backupModels = []interface{}{
+	&systemConfigRow{},
+	&User{},
+	&AIModel{},
+	&Exchange{},
+	&Trader{},
+	&DecisionRecordDB{},
+	&BacktestRun{},
+	&BacktestCheckpoint{},
+	&BacktestEquity{},
+	&BacktestTrade{},
+	&BacktestMetrics{},
+	&BacktestDecision{},
+	&TraderPosition{},
+	&Strategy{},
+	&EquitySnapshot{},
+	&APIToken{},
+	&Workspace{},
+	&WorkspaceMember{},
+	&AuditEntry{},
+	&TraderOrder{},
+	&TraderFill{},
+	&TPSLRecord{},
+	&AnalysisRecord{},
+	&PendingOrder{},
+	&TradeHistoryRecord{},
+	&ReflectionRecord{},
+	&SystemAdjustment{},
+	&AILearningMemory{},
+	&AdaptiveStopLossRecord{},
+	&DebateSessionDB{},
+	&DebateParticipant{},
+	&DebateMessage{},
+	&DebateVote{},
+	&CustomPersonality{},
+	&DebateOutcome{},
+	&PromptTemplate{},
+	&PromptTemplateVersion{},
+	&Experiment{},
+	&ExperimentVariant{},
+	&ExperimentDecision{},
+	&ExperimentEquityPoint{},
+}
+
+// BackupTable table entry in the archive metadata
+type BackupTable struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+}
+
+// BackupMetadata describes a backup archive
+type BackupMetadata struct {
+	FormatVersion int           `json:"format_version"`
+	CreatedAt     time.Time     `json:"created_at"`
+	SourceDB      DBType        `json:"source_db"`
+	SchemaVersion int           `json:"schema_version"`
+	DataKeyIDs    []string      `json:"data_key_ids"` // data keys that encrypted the secrets in this archive
+	Tables        []BackupTable `json:"tables"`
+}
+
+// RestoreOptions controls ImportArchive
+type RestoreOptions struct {
+	// Replace deletes existing rows first; without it the restore only runs into an empty database
+	Replace bool
+	// DryRun performs the restore inside a transaction and rolls it back
+	DryRun bool
+}
+
+// BackupFile a backup archive on disk
+type BackupFile struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// backupTable table name and column schema of a backup model
+type backupTable struct {
+	name   string
+	fields map[string]*schema.Field
+	pk     *schema.Field
+}
+
+// backupTables parses backupModels
+func (s *Store) backupTables() ([]backupTable, error) {
+	tables := make([]backupTable, 0, len(backupModels))
+	for _, model := range backupModels {
+		stmt := &gorm.Statement{DB: s.gdb}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse %T: %w", model, err)
+		}
+		fields := make(map[string]*schema.Field, len(stmt.Schema.Fields))
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName != "" {
+				fields[f.DBName] = f
+			}
+		}
+		tables = append(tables, backupTable{
+			name:   stmt.Schema.Table,
+			fields: fields,
+			pk:     stmt.Schema.PrioritizedPrimaryField,
+		})
+	}
+	return tables, nil
+}
+
+// ExportArchive writes every table to a backup archive from one consistent read transaction
+// On SQLite the transaction holds the only connection, so writers wait until the export finishes
+func (s *Store) ExportArchive(w io.Writer) (*BackupMetadata, error) {
+	tables, err := s.backupTables()
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	meta := &BackupMetadata{
+		FormatVersion: BackupFormatVersion,
+		CreatedAt:     time.Now().UTC(),
+		SourceDB:      s.DBType(),
+		SchemaVersion: version,
+	}
+
+	var txOpts *sql.TxOptions
+	if isPostgres(s.gdb) {
+		txOpts = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	}
+	keyIDs := make(map[string]bool)
+	err = s.gdb.Transaction(func(tx *gorm.DB) error {
+		for _, t := range tables {
+			if !tx.Migrator().HasTable(t.name) {
+				continue
+			}
+			// Rows are buffered per table because tar entries need their size up front
+			var buf bytes.Buffer
+			n, err := exportTable(tx, t, &buf, keyIDs)
+			if err != nil {
+				return fmt.Errorf("failed to export %s: %w", t.name, err)
+			}
+			if err := writeTarFile(tw, t.name+".jsonl", buf.Bytes(), meta.CreatedAt); err != nil {
+				return err
+			}
+			meta.Tables = append(meta.Tables, BackupTable{Name: t.name, Rows: n})
+		}
+		return nil
+	}, txOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	for kid := range keyIDs {
+		meta.DataKeyIDs = append(meta.DataKeyIDs, kid)
+	}
+	sort.Strings(meta.DataKeyIDs)
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	// metadata.json is the last entry so it can carry the final row counts
+	if err := writeTarFile(tw, backupMetadataFile, data, meta.CreatedAt); err != nil {
+		return nil, err
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// exportTable writes the rows of one table as JSON lines, ordered by primary key
+func exportTable(tx *gorm.DB, t backupTable, w io.Writer, keyIDs map[string]bool) (int64, error) {
+	q := tx.Table(t.name)
+	if t.pk != nil {
+		q = q.Order(t.pk.DBName)
+	}
+	rows, err := q.Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	enc := json.NewEncoder(w)
+	var count int64
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return count, err
+		}
+		row := make(map[string]interface{}, len(columns))
+		for i, col := range columns {
+			v := exportValue(t.fields[col], values[i])
+			if str, ok := v.(string); ok {
+				if kid := crypto.StorageKeyID(str); kid != "" {
+					keyIDs[kid] = true
+				}
+			}
+			row[col] = v
+		}
+		if err := enc.Encode(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
+
+// exportValue normalizes driver values so both backends produce the same JSON
+// (SQLite returns booleans as integers and may return times as text)
+func exportValue(field *schema.Field, v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	if v == nil || field == nil {
+		return v
+	}
+	switch field.DataType {
+	case schema.Bool:
+		switch x := v.(type) {
+		case int64:
+			return x != 0
+		case string:
+			b, err := strconv.ParseBool(x)
+			if err == nil {
+				return b
+			}
+		}
+	case schema.Time:
+		switch x := v.(type) {
+		case time.Time:
+			return x.UTC().Format(time.RFC3339Nano)
+		case string:
+			if t, err := parseBackupTime(x); err == nil {
+				return t.UTC().Format(time.RFC3339Nano)
+			}
+		}
+	}
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+// ImportArchive restores a backup archive into this database, which must already be migrated
+// All tables are written in one transaction, so a failed restore leaves the database unchanged
+func (s *Store) ImportArchive(r io.Reader, opts RestoreOptions) (*BackupMetadata, error) {
+	tables, err := s.backupTables()
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]backupTable, len(tables))
+	for _, t := range tables {
+		byName[t.name] = t
+	}
+
+	// Table files come before metadata.json, so they are staged in a temporary directory
+	staging, err := os.MkdirTemp("", "nofx-restore-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(staging)
+	meta, err := extractArchive(r, staging)
+	if err != nil {
+		return nil, err
+	}
+	if meta.FormatVersion > BackupFormatVersion {
+		return nil, fmt.Errorf("backup format %d is newer than this binary supports (%d)", meta.FormatVersion, BackupFormatVersion)
+	}
+	if meta.SchemaVersion > LatestMigrationVersion() {
+		return nil, fmt.Errorf("backup schema version %d is newer than this binary supports (%d), upgrade nofx first", meta.SchemaVersion, LatestMigrationVersion())
+	}
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+	if current < meta.SchemaVersion {
+		return nil, fmt.Errorf("database schema version %d is older than the backup (%d), run migrations first", current, meta.SchemaVersion)
+	}
+
+	err = s.gdb.Transaction(func(tx *gorm.DB) error {
+		if !opts.Replace {
+			for _, t := range tables {
+				var count int64
+				if err := tx.Table(t.name).Count(&count).Error; err != nil {
+					return err
+				}
+				if count > 0 {
+					return fmt.Errorf("table %s is not empty, restore with replace to overwrite existing data", t.name)
+				}
+			}
+		}
+
+		// audit_logs is append-only; its triggers are recreated after the rows are copied
+		if err := dropAuditTriggers(tx); err != nil {
+			return err
+		}
+		for i := len(tables) - 1; i >= 0; i-- {
+			if err := tx.Exec(fmt.Sprintf("DELETE FROM %s", tables[i].name)).Error; err != nil {
+				return fmt.Errorf("failed to clear %s: %w", tables[i].name, err)
+			}
+		}
+
+		for _, bt := range meta.Tables {
+			t, ok := byName[bt.Name]
+			if !ok {
+				logger.Warnf("⚠️ Skipping unknown table %s in backup", bt.Name)
+				continue
+			}
+			n, err := importTable(tx, t, filepath.Join(staging, bt.Name+".jsonl"))
+			if err != nil {
+				return fmt.Errorf("failed to restore %s: %w", t.name, err)
+			}
+			if n != bt.Rows {
+				return fmt.Errorf("table %s: archive lists %d rows but contains %d", t.name, bt.Rows, n)
+			}
+			if isPostgres(tx) && t.pk != nil && t.pk.AutoIncrement {
+				if err := tx.Exec(fmt.Sprintf(
+					`SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)`,
+					t.name, t.pk.DBName, t.pk.DBName, t.name)).Error; err != nil {
+					return fmt.Errorf("failed to reset sequence of %s: %w", t.name, err)
+				}
+			}
+		}
+
+		if err := createAuditTriggers(tx); err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return errRestoreDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRestoreDryRun) {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// importTable inserts the rows of one JSONL file in batches, returns the row count
+func importTable(tx *gorm.DB, t backupTable, path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	columnTypes, err := tx.Migrator().ColumnTypes(t.name)
+	if err != nil {
+		return 0, err
+	}
+	columns := make(map[string]bool, len(columnTypes))
+	for _, ct := range columnTypes {
+		columns[ct.Name()] = true
+	}
+
+	var count int64
+	batch := make([]map[string]interface{}, 0, backupBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := tx.Table(t.name).Create(&batch).Error; err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			dec := json.NewDecoder(bytes.NewReader(line))
+			dec.UseNumber()
+			var raw map[string]interface{}
+			if err := dec.Decode(&raw); err != nil {
+				return count, fmt.Errorf("row %d: %w", count+1, err)
+			}
+			row := make(map[string]interface{}, len(raw))
+			for col, v := range raw {
+				// Columns dropped since the backup was taken are ignored
+				if !columns[col] {
+					continue
+				}
+				value, err := importValue(t.fields[col], v)
+				if err != nil {
+					return count, fmt.Errorf("row %d column %s: %w", count+1, col, err)
+				}
+				row[col] = value
+			}
+			batch = append(batch, row)
+			count++
+			if len(batch) >= backupBatchSize {
+				if err := flush(); err != nil {
+					return count, err
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+	}
+	return count, flush()
+}
+
+// importValue converts a decoded JSON value to the Go type of the target column
+func importValue(field *schema.Field, v interface{}) (interface{}, error) {
+	num, isNum := v.(json.Number)
+	if v == nil || field == nil {
+		if isNum {
+			if i, err := num.Int64(); err == nil {
+				return i, nil
+			}
+			return num.Float64()
+		}
+		return v, nil
+	}
+
+	switch field.DataType {
+	case schema.Bool:
+		if isNum {
+			i, err := num.Int64()
+			return i != 0, err
+		}
+	case schema.Int, schema.Uint:
+		if isNum {
+			return num.Int64()
+		}
+	case schema.Float:
+		if isNum {
+			return num.Float64()
+		}
+	case schema.Time:
+		if str, ok := v.(string); ok {
+			return parseBackupTime(str)
+		}
+	}
+	if isNum {
+		return num.String(), nil
+	}
+	return v, nil
+}
+
+// parseBackupTime parses timestamps written by either backend
+func parseBackupTime(s string) (time.Time, error) {
+	for _, layout := range []string{
+		time.RFC3339Nano,
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02T15:04:05.999999999",
+		"2006-01-02",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// writeTarFile adds one file to the archive
+func writeTarFile(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// extractArchive unpacks table files into dir and returns the archive metadata
+func extractArchive(r io.Reader, dir string) (*BackupMetadata, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	var meta *BackupMetadata
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup archive: %w", err)
+		}
+		name := hdr.Name
+		if name != filepath.Base(name) {
+			return nil, fmt.Errorf("unexpected path %q in backup archive", name)
+		}
+
+		if name == backupMetadataFile {
+			meta = &BackupMetadata{}
+			if err := json.NewDecoder(tr).Decode(meta); err != nil {
+				return nil, fmt.Errorf("invalid backup metadata: %w", err)
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, ".jsonl") {
+			continue
+		}
+		out, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("backup archive has no %s", backupMetadataFile)
+	}
+	return meta, nil
+}
+
+// WriteBackupFile exports a timestamped archive into dir
+func (s *Store) WriteBackupFile(dir string) (string, *BackupMetadata, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", nil, err
+	}
+	name := BackupFilePrefix + time.Now().UTC().Format("20060102T150405Z") + BackupFileSuffix
+	path := filepath.Join(dir, name)
+
+	// Write to a temporary name first so a crash never leaves a truncated archive behind
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", nil, err
+	}
+	meta, err := s.ExportArchive(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", nil, err
+	}
+	return path, meta, nil
+}
+
+// IsBackupFileName reports whether name is a backup archive written by WriteBackupFile
+func IsBackupFileName(name string) bool {
+	return name == filepath.Base(name) &&
+		strings.HasPrefix(name, BackupFilePrefix) &&
+		strings.HasSuffix(name, BackupFileSuffix)
+}
+
+// ListBackupFiles lists backup archives in dir, newest first
+func ListBackupFiles(dir string) ([]BackupFile, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var files []BackupFile
+	for _, e := range entries {
+		if e.IsDir() || !IsBackupFileName(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, BackupFile{Name: e.Name(), Size: info.Size(), CreatedAt: info.ModTime().UTC()})
+	}
+	// Names embed the UTC creation time, so they sort chronologically
+	sort.Slice(files, func(i, j int) bool { return files[i].Name > files[j].Name })
+	return files, nil
+}
+
+// PruneBackupFiles deletes all but the newest keep archives in dir
+func PruneBackupFiles(dir string, keep int) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	files, err := ListBackupFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for i := keep; i < len(files); i++ {
+		if err := os.Remove(filepath.Join(dir, files[i].Name)); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"nofx/logger"
+)
+
+// BackupScheduler writes point-in-time backup archives at a fixed interval and prunes old ones
+type BackupScheduler struct {
+	store    *Store
+	dir      string
+	interval time.Duration
+	keep     int
+
+	mu       sync.Mutex // serializes backups (scheduled and on demand)
+	lastRun  time.Time
+	lastFile string
+	lastErr  string
+
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// BackupStatus state of the backup scheduler
+type BackupStatus struct {
+	Dir      string    `json:"dir"`
+	Interval string    `json:"interval"` // empty when scheduled backups are disabled
+	Keep     int       `json:"keep"`
+	LastRun  time.Time `json:"last_run,omitempty"`
+	LastFile string    `json:"last_file,omitempty"`
+	LastErr  string    `json:"last_error,omitempty"`
+}
+
+// NewBackupScheduler creates a backup scheduler; interval <= 0 disables scheduled backups,
+// keep <= 0 keeps every archive
+func NewBackupScheduler(st *Store, dir string, interval time.Duration, keep int) *BackupScheduler {
+	return &BackupScheduler{
+		store:    st,
+		dir:      dir,
+		interval: interval,
+		keep:     keep,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Dir returns the backup directory
+func (bs *BackupScheduler) Dir() string {
+	return bs.dir
+}
+
+// Start starts the scheduler loop
+func (bs *BackupScheduler) Start() {
+	if bs.interval <= 0 {
+		logger.Infof("🛑 Scheduled backups disabled (set BACKUP_INTERVAL to enable)")
+		return
+	}
+	logger.Infof("💾 Backup scheduler started: every %s into %s, keeping %d", bs.interval, bs.dir, bs.keep)
+	bs.wg.Add(1)
+	go bs.loop()
+}
+
+// Stop stops the scheduler and waits for a running backup to finish
+func (bs *BackupScheduler) Stop() {
+	close(bs.stopCh)
+	bs.wg.Wait()
+}
+
+func (bs *BackupScheduler) loop() {
+	defer bs.wg.Done()
+	ticker := time.NewTicker(bs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-bs.stopCh:
+			return
+		case <-ticker.C:
+			if _, _, err := bs.RunNow(); err != nil {
+				logger.Errorf("❌ Scheduled backup failed: %v", err)
+			}
+		}
+	}
+}
+
+// RunNow writes a backup archive immediately and prunes old archives
+func (bs *BackupScheduler) RunNow() (string, *BackupMetadata, error) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	start := time.Now()
+	path, meta, err := bs.store.WriteBackupFile(bs.dir)
+	bs.lastRun = start.UTC()
+	if err != nil {
+		bs.lastErr = err.Error()
+		return "", nil, err
+	}
+	bs.lastFile, bs.lastErr = path, ""
+
+	var rows int64
+	for _, t := range meta.Tables {
+		rows += t.Rows
+	}
+	logger.Infof("💾 Backup written: %s (%d tables, %d rows, %s)", path, len(meta.Tables), rows, time.Since(start).Round(time.Millisecond))
+
+	if removed, err := PruneBackupFiles(bs.dir, bs.keep); err != nil {
+		logger.Warnf("⚠️ Failed to prune old backups: %v", err)
+	} else if removed > 0 {
+		logger.Infof("🧹 Removed %d old backup(s)", removed)
+	}
+	return path, meta, nil
+}
+
+// Status returns the scheduler configuration and the outcome of the last backup
+func (bs *BackupScheduler) Status() BackupStatus {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	status := BackupStatus{
+		Dir:      bs.dir,
+		Keep:     bs.keep,
+		LastRun:  bs.lastRun,
+		LastFile: bs.lastFile,
+		LastErr:  bs.lastErr,
+	}
+	if bs.interval > 0 {
+		status.Interval = bs.interval.String()
+	}
+	return status
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openBackupTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := New(filepath.Join(t.TempDir(), "nofx.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// archiveRows decodes the rows of one table file of a backup archive
+func archiveRows(t *testing.T, archive []byte, table string) []map[string]interface{} {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			t.Fatalf("%s not in archive", table)
+		}
+		if err != nil {
+			t.Fatalf("read archive: %v", err)
+		}
+		if hdr.Name != table+".jsonl" {
+			continue
+		}
+		var rows []map[string]interface{}
+		scanner := bufio.NewScanner(tr)
+		scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+		for scanner.Scan() {
+			var row map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+				t.Fatalf("decode %s row: %v", table, err)
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+}
+
+// TestBackupRoundTrip Test that an exported archive restores into another database, with the columns
+// added by later migrations exported by their type
+func TestBackupRoundTrip(t *testing.T) {
+	src := openBackupTestStore(t)
+	session := &DebateSession{UserID: "u1", Name: "d", StrategyID: "s1", Symbol: "BTCUSDT", WeightByAccuracy: true}
+	if err := src.Debate().CreateSession(session); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	due := time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC)
+	outcome := &DebateOutcome{ID: "o1", SessionID: session.ID, UserID: "u1", AIModelID: "m1", AIModelName: "m",
+		Personality: PersonalityBull, Symbol: "BTCUSDT", Action: "open_long", EntryPrice: 100, DueAt: due, Status: OutcomePending}
+	if err := src.Debate().AddOutcomes([]*DebateOutcome{outcome}); err != nil {
+		t.Fatalf("add outcome: %v", err)
+	}
+
+	var archive bytes.Buffer
+	if _, err := src.ExportArchive(&archive); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	sessions := archiveRows(t, archive.Bytes(), "debate_sessions")
+	if len(sessions) != 1 || sessions[0]["weight_by_accuracy"] != true {
+		t.Errorf("weight_by_accuracy should be exported as a boolean, got %v", sessions)
+	}
+	outcomes := archiveRows(t, archive.Bytes(), "debate_outcomes")
+	if len(outcomes) != 1 || outcomes[0]["due_at"] != due.Format(time.RFC3339Nano) {
+		t.Errorf("due_at should be exported as RFC 3339, got %v", outcomes)
+	}
+
+	// A dry run leaves the target unchanged
+	dst := openBackupTestStore(t)
+	if _, err := dst.ImportArchive(bytes.NewReader(archive.Bytes()), RestoreOptions{Replace: true, DryRun: true}); err != nil {
+		t.Fatalf("dry-run import: %v", err)
+	}
+	if _, err := dst.Debate().GetSession(session.ID); err == nil {
+		t.Error("a dry run should not restore rows")
+	}
+
+	if _, err := dst.ImportArchive(bytes.NewReader(archive.Bytes()), RestoreOptions{Replace: true}); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	restored, err := dst.Debate().GetSession(session.ID)
+	if err != nil || !restored.WeightByAccuracy || restored.Symbol != "BTCUSDT" {
+		t.Errorf("session not restored: %+v (%v)", restored, err)
+	}
+	due1, err := dst.Debate().GetDueOutcomes(due.Add(time.Minute), 10)
+	if err != nil || len(due1) != 1 || !due1[0].DueAt.Equal(due) || due1[0].EntryPrice != 100 {
+		t.Errorf("outcome not restored: %+v (%v)", due1, err)
+	}
+
+	// Without replace, a restore only runs into an empty database
+	if _, err := dst.ImportArchive(bytes.NewReader(archive.Bytes()), RestoreOptions{}); err == nil {
+		t.Error("restoring over existing data without replace should fail")
+	}
+}
+
+// TestBackupModelsCoverSchema Test that every migrated table and column is part of backups
+func TestBackupModelsCoverSchema(t *testing.T) {
+	s := openBackupTestStore(t)
+	tables, err := s.backupTables()
+	if err != nil {
+		t.Fatalf("parse backup models: %v", err)
+	}
+	byName := make(map[string]backupTable, len(tables))
+	for _, bt := range tables {
+		byName[bt.name] = bt
+	}
+
+	names, err := s.gdb.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("list tables: %v", err)
+	}
+	for _, name := range names {
+		if name == "schema_migrations" || name == "sqlite_sequence" {
+			continue
+		}
+		bt, ok := byName[name]
+		if !ok {
+			t.Errorf("table %s is not backed up", name)
+			continue
+		}
+		columns, err := s.gdb.Migrator().ColumnTypes(name)
+		if err != nil {
+			t.Fatalf("columns of %s: %v", name, err)
+		}
+		for _, col := range columns {
+			if bt.fields[col.Name()] == nil {
+				t.Errorf("column %s.%s has no field type in the backup models", name, col.Name())
+			}
+		}
+	}
+}
@@ -17,6 +17,7 @@ const (
 	DebateStatusVoting    DebateStatus = "voting"
 	DebateStatusCompleted DebateStatus = "completed"
 	DebateStatusCancelled DebateStatus = "cancelled"
+	DebateStatusFailed    DebateStatus = "failed" // No participant voted
 )
 
 // DebatePersonality represents AI personality types
@@ -28,6 +29,23 @@ const (
 	PersonalityAnalyst     DebatePersonality = "analyst"      // Data Analyst - pure technical analysis
 	PersonalityContrarian  DebatePersonality = "contrarian"   // Contrarian - challenges majority opinion
 	PersonalityRiskManager DebatePersonality = "risk_manager" // Risk Manager - focuses on position sizing
+	PersonalityModerator   DebatePersonality = "moderator"    // Moderator - summarises rounds and gives the verdict, never a debater
+)
+
+// CustomPersonalityPrefix prefixes the ID of user-defined personalities so they never clash with built-in ones
+const CustomPersonalityPrefix = "custom_"
+
+// Risk bias of a custom personality
+const (
+	RiskBiasAggressive   = "aggressive"
+	RiskBiasNeutral      = "neutral"
+	RiskBiasConservative = "conservative"
+)
+
+// Consensus methods of a debate session
+const (
+	ConsensusVote      = "vote"      // Participants vote, the weighted majority per coin wins
+	ConsensusModerator = "moderator" // The moderator weighs the arguments and decides
 )
 
 // PersonalityColors maps personalities to colors for UI
@@ -46,6 +64,13 @@ var PersonalityEmojis = map[DebatePersonality]string{
 	PersonalityAnalyst:     "📊",
 	PersonalityContrarian:  "🔄",
 	PersonalityRiskManager: "🛡️",
+	PersonalityModerator:   "⚖️",
+}
+
+// IsBuiltinPersonality reports whether p is one of the built-in debater personalities
+func IsBuiltinPersonality(p DebatePersonality) bool {
+	_, ok := PersonalityColors[p]
+	return ok
 }
 
 // DebateDecision represents a trading decision from the debate
@@ -74,15 +99,19 @@ type DebateSession struct {
 	Name            string            `json:"name"`
 	StrategyID      string            `json:"strategy_id"`
 	Status          DebateStatus      `json:"status"`
-	Symbol          string            `json:"symbol"`           // Primary symbol (for backward compat, may be empty for multi-coin)
+	Symbol          string            `json:"symbol"` // Primary symbol (for backward compat, may be empty for multi-coin)
 	MaxRounds       int               `json:"max_rounds"`
 	CurrentRound    int               `json:"current_round"`
-	IntervalMinutes int               `json:"interval_minutes"` // Debate interval (5, 15, 30, 60 minutes)
-	PromptVariant   string            `json:"prompt_variant"`   // balanced/aggressive/conservative/scalping
+	IntervalMinutes int               `json:"interval_minutes"`          // Debate interval (5, 15, 30, 60 minutes)
+	PromptVariant   string            `json:"prompt_variant"`            // balanced/aggressive/conservative/scalping
 	FinalDecision   *DebateDecision   `json:"final_decision,omitempty"`  // Single decision (backward compat)
 	FinalDecisions  []*DebateDecision `json:"final_decisions,omitempty"` // Multi-coin decisions
 	AutoExecute     bool              `json:"auto_execute"`
 	TraderID        string            `json:"trader_id,omitempty"` // Trader to use for auto-execute
+	// Consensus options
+	ConsensusMethod    string `json:"consensus_method"`                // vote (default) or moderator
+	ModeratorAIModelID string `json:"moderator_ai_model_id,omitempty"` // AI model of the moderator, required for the moderator method
+	WeightByAccuracy   bool   `json:"weight_by_accuracy"`              // Scale each vote by its participant's historical accuracy
 	// OI Ranking data options
 	EnableOIRanking bool      `json:"enable_oi_ranking"` // Whether to include OI ranking data
 	OIRankingLimit  int       `json:"oi_ranking_limit"`  // Number of OI ranking entries (default 10)
@@ -93,24 +122,27 @@ type DebateSession struct {
 
 // DebateSessionDB is the GORM model for debate_sessions
 type DebateSessionDB struct {
-	ID              string       `gorm:"column:id;primaryKey"`
-	UserID          string       `gorm:"column:user_id;not null;index"`
-	Name            string       `gorm:"column:name;not null"`
-	StrategyID      string       `gorm:"column:strategy_id;not null"`
-	Status          DebateStatus `gorm:"column:status;not null;default:pending;index"`
-	Symbol          string       `gorm:"column:symbol;not null"`
-	MaxRounds       int          `gorm:"column:max_rounds;default:3"`
-	CurrentRound    int          `gorm:"column:current_round;default:0"`
-	IntervalMinutes int          `gorm:"column:interval_minutes;default:5"`
-	PromptVariant   string       `gorm:"column:prompt_variant;default:balanced"`
-	FinalDecision   string       `gorm:"column:final_decision"` // JSON string
-	AutoExecute     bool         `gorm:"column:auto_execute;default:false"`
-	TraderID        string       `gorm:"column:trader_id"`
-	EnableOIRanking bool         `gorm:"column:enable_oi_ranking;default:false"`
-	OIRankingLimit  int          `gorm:"column:oi_ranking_limit;default:10"`
-	OIDuration      string       `gorm:"column:oi_duration;default:1h"`
-	CreatedAt       time.Time    `gorm:"column:created_at;autoCreateTime"`
-	UpdatedAt       time.Time    `gorm:"column:updated_at;autoUpdateTime"`
+	ID                 string       `gorm:"column:id;primaryKey"`
+	UserID             string       `gorm:"column:user_id;not null;index"`
+	Name               string       `gorm:"column:name;not null"`
+	StrategyID         string       `gorm:"column:strategy_id;not null"`
+	Status             DebateStatus `gorm:"column:status;not null;default:pending;index"`
+	Symbol             string       `gorm:"column:symbol;not null"`
+	MaxRounds          int          `gorm:"column:max_rounds;default:3"`
+	CurrentRound       int          `gorm:"column:current_round;default:0"`
+	IntervalMinutes    int          `gorm:"column:interval_minutes;default:5"`
+	PromptVariant      string       `gorm:"column:prompt_variant;default:balanced"`
+	FinalDecision      string       `gorm:"column:final_decision"` // JSON string
+	AutoExecute        bool         `gorm:"column:auto_execute;default:false"`
+	TraderID           string       `gorm:"column:trader_id"`
+	ConsensusMethod    string       `gorm:"column:consensus_method;default:vote"`
+	ModeratorAIModelID string       `gorm:"column:moderator_ai_model_id;default:''"`
+	WeightByAccuracy   bool         `gorm:"column:weight_by_accuracy;default:false"`
+	EnableOIRanking    bool         `gorm:"column:enable_oi_ranking;default:false"`
+	OIRankingLimit     int          `gorm:"column:oi_ranking_limit;default:10"`
+	OIDuration         string       `gorm:"column:oi_duration;default:1h"`
+	CreatedAt          time.Time    `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt          time.Time    `gorm:"column:updated_at;autoUpdateTime"`
 }
 
 func (DebateSessionDB) TableName() string {
@@ -119,23 +151,26 @@ func (DebateSessionDB) TableName() string {
 
 func (db *DebateSessionDB) toSession() *DebateSession {
 	s := &DebateSession{
-		ID:              db.ID,
-		UserID:          db.UserID,
-		Name:            db.Name,
-		StrategyID:      db.StrategyID,
-		Status:          db.Status,
-		Symbol:          db.Symbol,
-		MaxRounds:       db.MaxRounds,
-		CurrentRound:    db.CurrentRound,
-		IntervalMinutes: db.IntervalMinutes,
-		PromptVariant:   db.PromptVariant,
-		AutoExecute:     db.AutoExecute,
-		TraderID:        db.TraderID,
-		EnableOIRanking: db.EnableOIRanking,
-		OIRankingLimit:  db.OIRankingLimit,
-		OIDuration:      db.OIDuration,
-		CreatedAt:       db.CreatedAt,
-		UpdatedAt:       db.UpdatedAt,
+		ID:                 db.ID,
+		UserID:             db.UserID,
+		Name:               db.Name,
+		StrategyID:         db.StrategyID,
+		Status:             db.Status,
+		Symbol:             db.Symbol,
+		MaxRounds:          db.MaxRounds,
+		CurrentRound:       db.CurrentRound,
+		IntervalMinutes:    db.IntervalMinutes,
+		PromptVariant:      db.PromptVariant,
+		AutoExecute:        db.AutoExecute,
+		TraderID:           db.TraderID,
+		EnableOIRanking:    db.EnableOIRanking,
+		ConsensusMethod:    db.ConsensusMethod,
+		ModeratorAIModelID: db.ModeratorAIModelID,
+		WeightByAccuracy:   db.WeightByAccuracy,
+		OIRankingLimit:     db.OIRankingLimit,
+		OIDuration:         db.OIDuration,
+		CreatedAt:          db.CreatedAt,
+		UpdatedAt:          db.UpdatedAt,
 	}
 
 	// Set defaults
@@ -151,6 +186,9 @@ func (db *DebateSessionDB) toSession() *DebateSession {
 	if s.OIDuration == "" {
 		s.OIDuration = "1h"
 	}
+	if s.ConsensusMethod == "" {
+		s.ConsensusMethod = ConsensusVote
+	}
 
 	// Parse final decision
 	if db.FinalDecision != "" {
@@ -173,7 +211,22 @@ type DebateParticipant struct {
 	Personality DebatePersonality `gorm:"column:personality;not null" json:"personality"`
 	Color       string            `gorm:"column:color;not null" json:"color"`
 	SpeakOrder  int               `gorm:"column:speak_order;default:0" json:"speak_order"`
-	CreatedAt   time.Time         `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	// Snapshot of a custom personality, taken when the participant joins so later edits don't change past debates
+	PersonalityName string    `gorm:"column:personality_name;default:''" json:"personality_name,omitempty"`
+	CustomPrompt    string    `gorm:"column:custom_prompt;type:text" json:"custom_prompt,omitempty"`
+	RiskBias        string    `gorm:"column:risk_bias;default:''" json:"risk_bias,omitempty"`
+	Emoji           string    `gorm:"column:emoji;default:''" json:"emoji,omitempty"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+// ApplyCustomPersonality snapshots a custom personality onto the participant
+func (p *DebateParticipant) ApplyCustomPersonality(cp *CustomPersonality) {
+	p.Personality = DebatePersonality(cp.ID)
+	p.PersonalityName = cp.Name
+	p.CustomPrompt = cp.Prompt
+	p.RiskBias = cp.RiskBias
+	p.Emoji = cp.Emoji
+	p.Color = cp.Color
 }
 
 func (DebateParticipant) TableName() string {
@@ -191,9 +244,9 @@ type DebateMessage struct {
 	Personality DebatePersonality `gorm:"column:personality;not null" json:"personality"`
 	MessageType string            `gorm:"column:message_type;not null" json:"message_type"` // analysis/rebuttal/final/vote
 	Content     string            `gorm:"column:content;not null" json:"content"`
-	DecisionRaw string            `gorm:"column:decision" json:"-"`                       // JSON string in DB
-	Decision    *DebateDecision   `gorm:"-" json:"decision,omitempty"`                    // Parsed for API
-	Decisions   []*DebateDecision `gorm:"-" json:"decisions,omitempty"`                   // Multi-coin decisions
+	DecisionRaw string            `gorm:"column:decision" json:"-"`     // JSON string in DB
+	Decision    *DebateDecision   `gorm:"-" json:"decision,omitempty"`  // Parsed for API
+	Decisions   []*DebateDecision `gorm:"-" json:"decisions,omitempty"` // Multi-coin decisions
 	Confidence  int               `gorm:"column:confidence;default:0" json:"confidence"`
 	CreatedAt   time.Time         `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 }
@@ -208,8 +261,10 @@ type DebateVote struct {
 	SessionID     string            `gorm:"column:session_id;not null;index" json:"session_id"`
 	AIModelID     string            `gorm:"column:ai_model_id;not null" json:"ai_model_id"`
 	AIModelName   string            `gorm:"column:ai_model_name;not null" json:"ai_model_name"`
-	Action        string            `gorm:"column:action;not null" json:"action"`   // Primary action (backward compat)
-	Symbol        string            `gorm:"column:symbol;not null" json:"symbol"`   // Primary symbol (backward compat)
+	ParticipantID string            `gorm:"column:participant_id;default:''" json:"participant_id,omitempty"` // Empty for the moderator's verdict and offline debates
+	Personality   DebatePersonality `gorm:"column:personality;default:''" json:"personality,omitempty"`
+	Action        string            `gorm:"column:action;not null" json:"action"` // Primary action (backward compat)
+	Symbol        string            `gorm:"column:symbol;not null" json:"symbol"` // Primary symbol (backward compat)
 	Confidence    int               `gorm:"column:confidence;default:0" json:"confidence"`
 	Leverage      int               `gorm:"column:leverage;default:5" json:"leverage"`
 	PositionPct   float64           `gorm:"column:position_pct;default:0.2" json:"position_pct"`
@@ -234,16 +289,6 @@ func NewDebateStore(db *gorm.DB) *DebateStore {
 	return &DebateStore{db: db}
 }
 
-// InitSchema creates the debate tables using GORM AutoMigrate
-func (s *DebateStore) InitSchema() error {
-	return s.db.AutoMigrate(
-		&DebateSessionDB{},
-		&DebateParticipant{},
-		&DebateMessage{},
-		&DebateVote{},
-	)
-}
-
 // CreateSession creates a new debate session
 func (s *DebateStore) CreateSession(session *DebateSession) error {
 	if session.ID == "" {
@@ -263,23 +308,29 @@ func (s *DebateStore) CreateSession(session *DebateSession) error {
 	if session.OIDuration == "" {
 		session.OIDuration = "1h"
 	}
+	if session.ConsensusMethod == "" {
+		session.ConsensusMethod = ConsensusVote
+	}
 
 	db := &DebateSessionDB{
-		ID:              session.ID,
-		UserID:          session.UserID,
-		Name:            session.Name,
-		StrategyID:      session.StrategyID,
-		Status:          session.Status,
-		Symbol:          session.Symbol,
-		MaxRounds:       session.MaxRounds,
-		CurrentRound:    session.CurrentRound,
-		IntervalMinutes: session.IntervalMinutes,
-		PromptVariant:   session.PromptVariant,
-		AutoExecute:     session.AutoExecute,
-		TraderID:        session.TraderID,
-		EnableOIRanking: session.EnableOIRanking,
-		OIRankingLimit:  session.OIRankingLimit,
-		OIDuration:      session.OIDuration,
+		ID:                 session.ID,
+		UserID:             session.UserID,
+		Name:               session.Name,
+		StrategyID:         session.StrategyID,
+		Status:             session.Status,
+		Symbol:             session.Symbol,
+		MaxRounds:          session.MaxRounds,
+		CurrentRound:       session.CurrentRound,
+		IntervalMinutes:    session.IntervalMinutes,
+		PromptVariant:      session.PromptVariant,
+		AutoExecute:        session.AutoExecute,
+		TraderID:           session.TraderID,
+		ConsensusMethod:    session.ConsensusMethod,
+		ModeratorAIModelID: session.ModeratorAIModelID,
+		WeightByAccuracy:   session.WeightByAccuracy,
+		EnableOIRanking:    session.EnableOIRanking,
+		OIRankingLimit:     session.OIRankingLimit,
+		OIDuration:         session.OIDuration,
 	}
 
 	return s.db.Create(db).Error
@@ -362,6 +413,7 @@ func (s *DebateStore) DeleteSession(id string) error {
 	s.db.Where("session_id = ?", id).Delete(&DebateParticipant{})
 	s.db.Where("session_id = ?", id).Delete(&DebateMessage{})
 	s.db.Where("session_id = ?", id).Delete(&DebateVote{})
+	s.db.Where("session_id = ?", id).Delete(&DebateOutcome{})
 	return s.db.Where("id = ?", id).Delete(&DebateSessionDB{}).Error
 }
 
@@ -494,3 +546,71 @@ func (s *DebateStore) GetSessionWithDetails(id string) (*DebateSessionWithDetail
 		Votes:         votes,
 	}, nil
 }
+
+// CustomPersonality a user-defined debate personality
+type CustomPersonality struct {
+	ID          string    `gorm:"column:id;primaryKey" json:"id"` // CustomPersonalityPrefix + random suffix, used as the participant personality
+	UserID      string    `gorm:"column:user_id;not null;index" json:"user_id"`
+	Name        string    `gorm:"column:name;not null" json:"name"`
+	Description string    `gorm:"column:description" json:"description"`
+	Prompt      string    `gorm:"column:prompt;type:text;not null" json:"prompt"`    // Role fragment injected into the debate system prompt
+	RiskBias    string    `gorm:"column:risk_bias;default:neutral" json:"risk_bias"` // aggressive/neutral/conservative
+	Color       string    `gorm:"column:color;default:'#6B7280'" json:"color"`
+	Emoji       string    `gorm:"column:emoji;default:'🎭'" json:"emoji"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (CustomPersonality) TableName() string {
+	return "debate_custom_personalities"
+}
+
+// CreateCustomPersonality creates a custom personality
+func (s *DebateStore) CreateCustomPersonality(cp *CustomPersonality) error {
+	if cp.ID == "" {
+		cp.ID = CustomPersonalityPrefix + uuid.New().String()[:8]
+	}
+	if cp.RiskBias == "" {
+		cp.RiskBias = RiskBiasNeutral
+	}
+	if cp.Color == "" {
+		cp.Color = "#6B7280"
+	}
+	if cp.Emoji == "" {
+		cp.Emoji = "🎭"
+	}
+	return s.db.Create(cp).Error
+}
+
+// GetCustomPersonality gets a user's custom personality
+func (s *DebateStore) GetCustomPersonality(userID, id string) (*CustomPersonality, error) {
+	var cp CustomPersonality
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&cp).Error; err != nil {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// ListCustomPersonalities lists a user's custom personalities
+func (s *DebateStore) ListCustomPersonalities(userID string) ([]*CustomPersonality, error) {
+	var list []*CustomPersonality
+	err := s.db.Where("user_id = ?", userID).Order("created_at").Find(&list).Error
+	return list, err
+}
+
+// UpdateCustomPersonality updates a user's custom personality; debates already created keep their snapshot
+func (s *DebateStore) UpdateCustomPersonality(cp *CustomPersonality) error {
+	return s.db.Model(&CustomPersonality{}).Where("id = ? AND user_id = ?", cp.ID, cp.UserID).Updates(map[string]interface{}{
+		"name":        cp.Name,
+		"description": cp.Description,
+		"prompt":      cp.Prompt,
+		"risk_bias":   cp.RiskBias,
+		"color":       cp.Color,
+		"emoji":       cp.Emoji,
+	}).Error
+}
+
+// DeleteCustomPersonality deletes a user's custom personality
+func (s *DebateStore) DeleteCustomPersonality(userID, id string) error {
+	return s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&CustomPersonality{}).Error
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Debate outcome status
+const (
+	OutcomePending = "pending" // Waiting for the scoring horizon to pass
+	OutcomeScored  = "scored"  // Scored against the price at the horizon
+	OutcomeSkipped = "skipped" // Could not be scored (no price available)
+)
+
+// DebateOutcome one participant's recommended action for one symbol, scored against what the market did next
+type DebateOutcome struct {
+	ID              string            `gorm:"column:id;primaryKey" json:"id"`
+	SessionID       string            `gorm:"column:session_id;not null;index" json:"session_id"`
+	UserID          string            `gorm:"column:user_id;not null;index" json:"user_id"`
+	AIModelID       string            `gorm:"column:ai_model_id;not null;index" json:"ai_model_id"`
+	AIModelName     string            `gorm:"column:ai_model_name;not null" json:"ai_model_name"`
+	Personality     DebatePersonality `gorm:"column:personality;not null;index" json:"personality"`
+	PersonalityName string            `gorm:"column:personality_name;default:''" json:"personality_name,omitempty"`
+	Symbol          string            `gorm:"column:symbol;not null" json:"symbol"`
+	Action          string            `gorm:"column:action;not null" json:"action"`
+	Confidence      int               `gorm:"column:confidence;default:0" json:"confidence"`
+	// Price move scoring
+	EntryPrice float64   `gorm:"column:entry_price;default:0" json:"entry_price"`
+	DueAt      time.Time `gorm:"column:due_at;index" json:"due_at"` // When the outcome is scored
+	Status     string    `gorm:"column:status;default:pending;index" json:"status"`
+	ExitPrice  float64   `gorm:"column:exit_price;default:0" json:"exit_price"`
+	ReturnPct  float64   `gorm:"column:return_pct;default:0" json:"return_pct"` // Price move in the recommended direction, in percent
+	Correct    bool      `gorm:"column:correct;default:false" json:"correct"`
+	ScoredAt   time.Time `gorm:"column:scored_at" json:"scored_at,omitempty"`
+	// Executed trade attribution (set when ExecuteConsensus ran for the session)
+	TraderID        string    `gorm:"column:trader_id;default:''" json:"trader_id,omitempty"`
+	ExecutedAction  string    `gorm:"column:executed_action;default:''" json:"executed_action,omitempty"`
+	ExecutedAt      time.Time `gorm:"column:executed_at" json:"executed_at,omitempty"`
+	PnLSettled      bool      `gorm:"column:pnl_settled;default:false" json:"pnl_settled"`
+	PnLContribution float64   `gorm:"column:pnl_contribution;default:0" json:"pnl_contribution"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (DebateOutcome) TableName() string {
+	return "debate_outcomes"
+}
+
+// AddOutcomes records the outcomes of a session's votes
+func (s *DebateStore) AddOutcomes(outcomes []*DebateOutcome) error {
+	if len(outcomes) == 0 {
+		return nil
+	}
+	for _, o := range outcomes {
+		if o.ID == "" {
+			o.ID = uuid.New().String()
+		}
+		if o.Status == "" {
+			o.Status = OutcomePending
+		}
+	}
+	return s.db.Create(&outcomes).Error
+}
+
+// GetDueOutcomes gets pending outcomes whose scoring horizon has passed, oldest first
+func (s *DebateStore) GetDueOutcomes(now time.Time, limit int) ([]*DebateOutcome, error) {
+	var outcomes []*DebateOutcome
+	err := s.db.Where("status = ? AND due_at <= ?", OutcomePending, now).
+		Order("due_at").Limit(limit).Find(&outcomes).Error
+	return outcomes, err
+}
+
+// GetUnsettledExecutedOutcomes gets outcomes of executed sessions whose trade PnL is not attributed yet
+func (s *DebateStore) GetUnsettledExecutedOutcomes() ([]*DebateOutcome, error) {
+	var outcomes []*DebateOutcome
+	err := s.db.Where("executed_action <> '' AND pnl_settled = ?", false).
+		Order("session_id, created_at").Find(&outcomes).Error
+	return outcomes, err
+}
+
+// GetScoredOutcomes gets a user's scored outcomes since the given time (zero time for all)
+func (s *DebateStore) GetScoredOutcomes(userID string, since time.Time) ([]*DebateOutcome, error) {
+	var outcomes []*DebateOutcome
+	query := s.db.Where("user_id = ? AND status = ?", userID, OutcomeScored)
+	if !since.IsZero() {
+		query = query.Where("created_at >= ?", since)
+	}
+	err := query.Order("created_at").Find(&outcomes).Error
+	return outcomes, err
+}
+
+// UpdateOutcome saves an outcome's scoring and attribution fields
+func (s *DebateStore) UpdateOutcome(o *DebateOutcome) error {
+	return s.db.Model(&DebateOutcome{}).Where("id = ?", o.ID).Updates(map[string]interface{}{
+		"status":           o.Status,
+		"exit_price":       o.ExitPrice,
+		"return_pct":       o.ReturnPct,
+		"correct":          o.Correct,
+		"scored_at":        o.ScoredAt,
+		"pnl_settled":      o.PnLSettled,
+		"pnl_contribution": o.PnLContribution,
+	}).Error
+}
+
+// MarkOutcomesExecuted records that a session's consensus for a symbol was traded on a trader
+func (s *DebateStore) MarkOutcomesExecuted(sessionID, symbol, traderID, action string, executedAt time.Time) error {
+	return s.db.Model(&DebateOutcome{}).
+		Where("session_id = ? AND symbol = ?", sessionID, symbol).
+		Updates(map[string]interface{}{
+			"trader_id":       traderID,
+			"executed_action": action,
+			"executed_at":     executedAt,
+		}).Error
+}
+
+// FindClosedDebatePosition finds the first position of a trader on symbol/side opened at or after sinceMs
+// and already closed, i.e. the trade opened by an executed debate consensus
+func (s *DebateStore) FindClosedDebatePosition(traderID, symbol, side string, sinceMs int64) (*TraderPosition, error) {
+	var positions []*TraderPosition
+	err := s.db.Where("trader_id = ? AND symbol = ? AND side = ? AND entry_time >= ?", traderID, symbol, side, sinceMs).
+		Order("entry_time").Limit(1).Find(&positions).Error
+	if err != nil || len(positions) == 0 || positions[0].Status != "CLOSED" {
+		return nil, err
+	}
+	return positions[0], nil
+}
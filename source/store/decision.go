@@ -30,6 +30,9 @@ type DecisionRecordDB struct {
 	Success             bool      `gorm:"default:false"`
 	ErrorMessage        string    `gorm:"column:error_message;default:''"`
 	AIRequestDurationMs int64     `gorm:"column:ai_request_duration_ms;default:0"`
+	TriggerEvent        string    `gorm:"column:trigger_event;default:''"`
+	DebateSessionID     string    `gorm:"column:debate_session_id;default:''"`
+	AIModel             string    `gorm:"column:ai_model;default:''"`
 	CreatedAt           time.Time `json:"created_at"`
 }
 
@@ -54,6 +57,21 @@ type DecisionRecord struct {
 	AccountState        AccountSnapshot    `json:"account_state"`
 	Positions           []PositionSnapshot `json:"positions"`
 	Decisions           []DecisionAction   `json:"decisions"`
+	TriggerEvent        *MarketEvent       `json:"trigger_event,omitempty"`     // Set when the cycle was launched by a market event
+	DebateSessionID     string             `json:"debate_session_id,omitempty"` // Debate session holding the transcript when a panel decided the cycle
+	AIModel             string             `json:"ai_model,omitempty"`          // Provider/model that produced the decision
+	DebateVotes         []*DebateVote      `json:"debate_votes,omitempty"`      // Final votes of an in-memory (backtest) debate
+	DebateConsensus     []*DebateDecision  `json:"debate_consensus,omitempty"`  // Consensus of an in-memory (backtest) debate
+}
+
+// MarketEvent market condition that launched an out-of-band decision cycle
+type MarketEvent struct {
+	Type       string    `json:"type"` // price_move | atr_spike | funding_flip | oi_surge | liquidation_cascade | position_pnl
+	Symbol     string    `json:"symbol"`
+	Message    string    `json:"message"`
+	Value      float64   `json:"value"`
+	Threshold  float64   `json:"threshold"`
+	DetectedAt time.Time `json:"detected_at"`
 }
 
 // AccountSnapshot account state snapshot
@@ -109,19 +127,6 @@ func NewDecisionStore(db *gorm.DB) *DecisionStore {
 	return &DecisionStore{db: db}
 }
 
-// initTables initializes AI decision log tables
-func (s *DecisionStore) initTables() error {
-	// For PostgreSQL with existing table, skip AutoMigrate
-	if s.db.Dialector.Name() == "postgres" {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'decision_records'`).Scan(&tableExists)
-		if tableExists > 0 {
-			return nil
-		}
-	}
-	return s.db.AutoMigrate(&DecisionRecordDB{})
-}
-
 // toRecord converts DB model to API struct
 func (db *DecisionRecordDB) toRecord() *DecisionRecord {
 	record := &DecisionRecord{
@@ -137,10 +142,18 @@ func (db *DecisionRecordDB) toRecord() *DecisionRecord {
 		Success:             db.Success,
 		ErrorMessage:        db.ErrorMessage,
 		AIRequestDurationMs: db.AIRequestDurationMs,
+		DebateSessionID:     db.DebateSessionID,
+		AIModel:             db.AIModel,
 	}
 	json.Unmarshal([]byte(db.CandidateCoins), &record.CandidateCoins)
 	json.Unmarshal([]byte(db.ExecutionLog), &record.ExecutionLog)
 	json.Unmarshal([]byte(db.Decisions), &record.Decisions)
+	if db.TriggerEvent != "" {
+		record.TriggerEvent = &MarketEvent{}
+		if err := json.Unmarshal([]byte(db.TriggerEvent), record.TriggerEvent); err != nil {
+			record.TriggerEvent = nil
+		}
+	}
 	return record
 }
 
@@ -156,6 +169,12 @@ func (s *DecisionStore) LogDecision(record *DecisionRecord) error {
 	candidateCoinsJSON, _ := json.Marshal(record.CandidateCoins)
 	executionLogJSON, _ := json.Marshal(record.ExecutionLog)
 	decisionsJSON, _ := json.Marshal(record.Decisions)
+	triggerEventJSON := ""
+	if record.TriggerEvent != nil {
+		if data, err := json.Marshal(record.TriggerEvent); err == nil {
+			triggerEventJSON = string(data)
+		}
+	}
 
 	dbRecord := &DecisionRecordDB{
 		TraderID:            record.TraderID,
@@ -172,6 +191,9 @@ func (s *DecisionStore) LogDecision(record *DecisionRecord) error {
 		Success:             record.Success,
 		ErrorMessage:        record.ErrorMessage,
 		AIRequestDurationMs: record.AIRequestDurationMs,
+		TriggerEvent:        triggerEventJSON,
+		DebateSessionID:     record.DebateSessionID,
+		AIModel:             record.AIModel,
 	}
 
 	if err := s.db.Create(dbRecord).Error; err != nil {
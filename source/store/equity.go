@@ -32,19 +32,6 @@ func NewEquityStore(db *gorm.DB) *EquityStore {
 	return &EquityStore{db: db}
 }
 
-// initTables initializes equity tables
-func (s *EquityStore) initTables() error {
-	// For PostgreSQL with existing table, skip AutoMigrate
-	if s.db.Dialector.Name() == "postgres" {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'trader_equity_snapshots'`).Scan(&tableExists)
-		if tableExists > 0 {
-			return nil
-		}
-	}
-	return s.db.AutoMigrate(&EquitySnapshot{})
-}
-
 // Save saves equity snapshot
 func (s *EquityStore) Save(snapshot *EquitySnapshot) error {
 	if snapshot.Timestamp.IsZero() {
@@ -143,13 +130,8 @@ func (s *EquityStore) MigrateFromDecision() (int64, error) {
 		return 0, nil // Already has data, skip migration
 	}
 
-	// Check if old table exists (SQLite specific check, but works for migration)
-	var tableName string
-	err := s.db.Raw(`
-		SELECT name FROM sqlite_master
-		WHERE type='table' AND name='decision_account_snapshots'
-	`).Scan(&tableName).Error
-	if err != nil || tableName == "" {
+	// Check if old table exists
+	if !s.db.Migrator().HasTable("decision_account_snapshots") {
 		return 0, nil // Old table doesn't exist, skip
 	}
 
@@ -47,90 +47,6 @@ func NewExchangeStore(db *gorm.DB) *ExchangeStore {
 	return &ExchangeStore{db: db}
 }
 
-func (s *ExchangeStore) initTables() error {
-	// For PostgreSQL with existing table, skip AutoMigrate
-	if s.db.Dialector.Name() == "postgres" {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'exchanges'`).Scan(&tableExists)
-		if tableExists > 0 {
-			// Still run data migrations
-			s.migrateToMultiAccount()
-			s.db.Model(&Exchange{}).Where("account_name = '' OR account_name IS NULL").Update("account_name", "Default")
-			return nil
-		}
-	}
-
-	if err := s.db.AutoMigrate(&Exchange{}); err != nil {
-		return err
-	}
-
-	// Run migration to multi-account if needed
-	if err := s.migrateToMultiAccount(); err != nil {
-		logger.Warnf("Multi-account migration warning: %v", err)
-	}
-
-	// Fix empty account_name for existing records
-	s.db.Model(&Exchange{}).Where("account_name = '' OR account_name IS NULL").Update("account_name", "Default")
-
-	return nil
-}
-
-// migrateToMultiAccount migrates old schema (id=exchange_type) to new schema (id=UUID)
-func (s *ExchangeStore) migrateToMultiAccount() error {
-	// Check if migration is needed by looking for old-style IDs (non-UUID)
-	var count int64
-	err := s.db.Model(&Exchange{}).
-		Where("exchange_type = '' AND id IN ?", []string{"binance", "bybit", "okx", "bitget", "hyperliquid", "aster", "lighter"}).
-		Count(&count).Error
-	if err != nil {
-		return err
-	}
-
-	if count == 0 {
-		return nil
-	}
-
-	logger.Infof("🔄 Migrating %d exchange records to multi-account schema...", count)
-
-	// Get all old records
-	var records []Exchange
-	err = s.db.Where("exchange_type = '' AND id IN ?", []string{"binance", "bybit", "okx", "bitget", "hyperliquid", "aster", "lighter"}).
-		Find(&records).Error
-	if err != nil {
-		return err
-	}
-
-	// Begin transaction
-	return s.db.Transaction(func(tx *gorm.DB) error {
-		for _, r := range records {
-			newID := uuid.New().String()
-			oldID := r.ID // This is the exchange type (e.g., "binance")
-
-			// Update traders table to use new UUID
-			if err := tx.Exec("UPDATE traders SET exchange_id = ? WHERE exchange_id = ? AND user_id = ?",
-				newID, oldID, r.UserID).Error; err != nil {
-				logger.Errorf("Failed to update traders for exchange %s: %v", oldID, err)
-				return err
-			}
-
-			// Update the exchange record
-			if err := tx.Model(&Exchange{}).
-				Where("id = ? AND user_id = ?", oldID, r.UserID).
-				Updates(map[string]interface{}{
-					"id":            newID,
-					"exchange_type": oldID,
-					"account_name":  "Default",
-				}).Error; err != nil {
-				logger.Errorf("Failed to migrate exchange %s: %v", oldID, err)
-				return err
-			}
-
-			logger.Infof("✅ Migrated exchange %s -> UUID %s for user %s", oldID, newID, r.UserID)
-		}
-		return nil
-	})
-}
-
 func (s *ExchangeStore) initDefaultData() error {
 	// No longer pre-populate exchanges - create on demand when user configures
 	return nil
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Experiment status
+const (
+	ExperimentRunning = "running"
+	ExperimentStopped = "stopped"
+)
+
+// Experiment a prompt/strategy A/B experiment on a live trader. Each decision cycle the trader executes
+// its own strategy (the live variant) while the shadow variants decide on the same market context and are
+// only recorded; every variant's decisions are tracked in a hypothetical paper account.
+type Experiment struct {
+	ID            string     `gorm:"column:id;primaryKey" json:"id"`
+	UserID        string     `gorm:"column:user_id;not null;index" json:"user_id"`
+	TraderID      string     `gorm:"column:trader_id;not null;index" json:"trader_id"`
+	Name          string     `gorm:"column:name;not null" json:"name"`
+	Description   string     `gorm:"column:description;default:''" json:"description"`
+	Status        string     `gorm:"column:status;default:running;index" json:"status"`
+	FeeBps        float64    `gorm:"column:fee_bps;default:5" json:"fee_bps"`               // Fee charged on hypothetical fills
+	InitialEquity float64    `gorm:"column:initial_equity;default:0" json:"initial_equity"` // Trader equity at the first cycle, every paper account starts with it
+	Cycles        int        `gorm:"column:cycles;default:0" json:"cycles"`                 // Decision cycles recorded so far
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+	StoppedAt     *time.Time `gorm:"column:stopped_at" json:"stopped_at,omitempty"`
+}
+
+func (Experiment) TableName() string {
+	return "experiments"
+}
+
+// ExperimentVariant one arm of an experiment
+type ExperimentVariant struct {
+	ID            string    `gorm:"column:id;primaryKey" json:"id"`
+	ExperimentID  string    `gorm:"column:experiment_id;not null;index" json:"experiment_id"`
+	Name          string    `gorm:"column:name;not null" json:"name"`
+	IsLive        bool      `gorm:"column:is_live;default:false" json:"is_live"`            // Executes: decisions come from the trader's own cycle
+	StrategyID    string    `gorm:"column:strategy_id;default:''" json:"strategy_id"`       // Strategy the config was copied from, if any
+	PromptVariant string    `gorm:"column:prompt_variant;default:''" json:"prompt_variant"` // balanced/aggressive/conservative/scalping
+	Config        string    `gorm:"column:config;type:text" json:"-"`                       // StrategyConfig snapshot (shadow variants)
+	Book          string    `gorm:"column:book;type:text" json:"-"`                         // Hypothetical paper account state
+	Equity        float64   `gorm:"column:equity;default:0" json:"equity"`                  // Latest hypothetical equity
+	SortOrder     int       `gorm:"column:sort_order;default:0" json:"sort_order"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (ExperimentVariant) TableName() string {
+	return "experiment_variants"
+}
+
+// ExperimentDecision one variant's decision for one symbol in one cycle
+type ExperimentDecision struct {
+	ID              int64     `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ExperimentID    string    `gorm:"column:experiment_id;not null;index:idx_experiment_decision_cycle" json:"experiment_id"`
+	VariantID       string    `gorm:"column:variant_id;not null;index" json:"variant_id"`
+	Cycle           int       `gorm:"column:cycle;not null;index:idx_experiment_decision_cycle" json:"cycle"`
+	Symbol          string    `gorm:"column:symbol;not null" json:"symbol"`
+	Action          string    `gorm:"column:action;not null" json:"action"`
+	Confidence      int       `gorm:"column:confidence;default:0" json:"confidence"`
+	Leverage        int       `gorm:"column:leverage;default:0" json:"leverage"`
+	PositionSizeUSD float64   `gorm:"column:position_size_usd;default:0" json:"position_size_usd"`
+	StopLoss        float64   `gorm:"column:stop_loss;default:0" json:"stop_loss"`
+	TakeProfit      float64   `gorm:"column:take_profit;default:0" json:"take_profit"`
+	Price           float64   `gorm:"column:price;default:0" json:"price"` // Market price when the decision was made
+	Reasoning       string    `gorm:"column:reasoning;type:text" json:"reasoning,omitempty"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (ExperimentDecision) TableName() string {
+	return "experiment_decisions"
+}
+
+// ExperimentEquityPoint a variant's hypothetical equity after a cycle
+type ExperimentEquityPoint struct {
+	ID           int64     `gorm:"column:id;primaryKey;autoIncrement" json:"-"`
+	ExperimentID string    `gorm:"column:experiment_id;not null;index" json:"-"`
+	VariantID    string    `gorm:"column:variant_id;not null;index" json:"variant_id"`
+	Cycle        int       `gorm:"column:cycle;not null" json:"cycle"`
+	Equity       float64   `gorm:"column:equity;not null" json:"equity"`
+	Failed       bool      `gorm:"column:failed;default:false" json:"failed,omitempty"` // The variant's AI call failed this cycle
+	Timestamp    time.Time `gorm:"column:timestamp;not null" json:"timestamp"`
+}
+
+func (ExperimentEquityPoint) TableName() string {
+	return "experiment_equity_points"
+}
+
+// ExperimentCycle everything recorded for an experiment in one cycle
+type ExperimentCycle struct {
+	Cycle         int
+	InitialEquity float64
+	Variants      []*ExperimentVariant // Variants with their updated book and equity
+	Decisions     []*ExperimentDecision
+	Points        []*ExperimentEquityPoint
+}
+
+// ExperimentStore experiment storage
+type ExperimentStore struct {
+	db *gorm.DB
+}
+
+// NewExperimentStore creates experiment storage
+func NewExperimentStore(db *gorm.DB) *ExperimentStore {
+	return &ExperimentStore{db: db}
+}
+
+// Create creates an experiment with its variants
+func (s *ExperimentStore) Create(e *Experiment, variants []*ExperimentVariant) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	if e.Status == "" {
+		e.Status = ExperimentRunning
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(e).Error; err != nil {
+			return err
+		}
+		for i, v := range variants {
+			if v.ID == "" {
+				v.ID = uuid.New().String()
+			}
+			v.ExperimentID = e.ID
+			v.SortOrder = i
+			if err := tx.Create(v).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get gets an experiment owned by the user
+func (s *ExperimentStore) Get(userID, id string) (*Experiment, error) {
+	var e Experiment
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&e).Error; err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List gets the user's experiments, newest first
+func (s *ExperimentStore) List(userID string) ([]*Experiment, error) {
+	var experiments []*Experiment
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&experiments).Error
+	return experiments, err
+}
+
+// GetRunningForTrader gets the trader's running experiment, nil if there is none
+func (s *ExperimentStore) GetRunningForTrader(traderID string) (*Experiment, error) {
+	var experiments []*Experiment
+	err := s.db.Where("trader_id = ? AND status = ?", traderID, ExperimentRunning).
+		Order("created_at DESC").Limit(1).Find(&experiments).Error
+	if err != nil || len(experiments) == 0 {
+		return nil, err
+	}
+	return experiments[0], nil
+}
+
+// Stop stops a running experiment; its results stay available
+func (s *ExperimentStore) Stop(userID, id string) error {
+	now := time.Now().UTC()
+	res := s.db.Model(&Experiment{}).Where("id = ? AND user_id = ? AND status = ?", id, userID, ExperimentRunning).
+		Updates(map[string]interface{}{"status": ExperimentStopped, "stopped_at": now})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Delete deletes an experiment and everything it recorded
+func (s *ExperimentStore) Delete(userID, id string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&Experiment{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		for _, model := range []interface{}{&ExperimentVariant{}, &ExperimentDecision{}, &ExperimentEquityPoint{}} {
+			if err := tx.Where("experiment_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetVariants gets an experiment's variants, live variant first
+func (s *ExperimentStore) GetVariants(experimentID string) ([]*ExperimentVariant, error) {
+	var variants []*ExperimentVariant
+	err := s.db.Where("experiment_id = ?", experimentID).Order("sort_order ASC").Find(&variants).Error
+	return variants, err
+}
+
+// GetDecisions gets every decision recorded for an experiment in cycle order
+func (s *ExperimentStore) GetDecisions(experimentID string) ([]*ExperimentDecision, error) {
+	var decisions []*ExperimentDecision
+	err := s.db.Where("experiment_id = ?", experimentID).Order("cycle ASC, id ASC").Find(&decisions).Error
+	return decisions, err
+}
+
+// GetEquityPoints gets every equity point recorded for an experiment in cycle order
+func (s *ExperimentStore) GetEquityPoints(experimentID string) ([]*ExperimentEquityPoint, error) {
+	var points []*ExperimentEquityPoint
+	err := s.db.Where("experiment_id = ?", experimentID).Order("cycle ASC, id ASC").Find(&points).Error
+	return points, err
+}
+
+// RecordCycle saves one cycle of an experiment: decisions, equity points and the variants' paper accounts
+func (s *ExperimentStore) RecordCycle(experimentID string, cycle *ExperimentCycle) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if len(cycle.Decisions) > 0 {
+			if err := tx.Create(cycle.Decisions).Error; err != nil {
+				return err
+			}
+		}
+		if len(cycle.Points) > 0 {
+			if err := tx.Create(cycle.Points).Error; err != nil {
+				return err
+			}
+		}
+		for _, v := range cycle.Variants {
+			if err := tx.Model(&ExperimentVariant{}).Where("id = ?", v.ID).
+				Updates(map[string]interface{}{"book": v.Book, "equity": v.Equity}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&Experiment{}).Where("id = ?", experimentID).
+			Updates(map[string]interface{}{
+				"cycles":         cycle.Cycle,
+				"initial_equity": cycle.InitialEquity,
+				"updated_at":     time.Now().UTC(),
+			}).Error
+	})
+}
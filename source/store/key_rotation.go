@@ -0,0 +1,154 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"nofx/crypto"
+
+	"gorm.io/gorm"
+)
+
+// DefaultKeyRotationBatchSize rows re-encrypted per transaction
+const DefaultKeyRotationBatchSize = 200
+
+// keyRotationAttempts times a value changed by a concurrent write is re-read and re-encrypted
+const keyRotationAttempts = 3
+
+// encryptedColumns tables and columns holding crypto.EncryptedString values
+var encryptedColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"exchanges", []string{"api_key", "secret_key", "passphrase", "aster_private_key", "lighter_private_key", "lighter_api_key_private_key"}},
+	{"ai_models", []string{"api_key"}},
+}
+
+// KeyRotationResult result of re-encrypting stored secrets with the active data key
+type KeyRotationResult struct {
+	ActiveKeyID string   `json:"active_key_id"`
+	Scanned     int      `json:"scanned"`     // rows checked
+	Reencrypted int      `json:"reencrypted"` // values rewritten with the active key
+	Failed      int      `json:"failed"`      // values no loaded key can decrypt, left unchanged
+	Errors      []string `json:"errors,omitempty"`
+}
+
+// RotateEncryptionKeys re-encrypts every encrypted column with the active data key, in batches
+// Values are read raw (bypassing EncryptedString) and only rewritten when they were written with another
+// key or are still plaintext, so the rotation can run while the system is online and can be resumed
+func (s *Store) RotateEncryptionKeys(cs *crypto.CryptoService, batchSize int) (*KeyRotationResult, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultKeyRotationBatchSize
+	}
+	result := &KeyRotationResult{ActiveKeyID: cs.ActiveKeyID()}
+
+	for _, tc := range encryptedColumns {
+		lastID := ""
+		for {
+			n, next, err := s.rotateBatch(cs, tc.table, tc.columns, lastID, batchSize, result)
+			if err != nil {
+				return result, fmt.Errorf("failed to rotate %s: %w", tc.table, err)
+			}
+			if n < batchSize {
+				break
+			}
+			lastID = next
+		}
+	}
+	return result, nil
+}
+
+// rotateBatch re-encrypts one batch of rows with id > afterID, returns the row count and the last ID
+func (s *Store) rotateBatch(cs *crypto.CryptoService, table string, columns []string, afterID string, batchSize int, result *KeyRotationResult) (int, string, error) {
+	count, lastID := 0, afterID
+	err := s.gdb.Transaction(func(tx *gorm.DB) error {
+		rows, err := tx.Table(table).
+			Select("id, "+strings.Join(columns, ", ")).
+			Where("id > ?", afterID).
+			Order("id").
+			Limit(batchSize).
+			Rows()
+		if err != nil {
+			return err
+		}
+
+		type pendingValue struct {
+			id, column, value string
+		}
+		var pending []pendingValue
+		for rows.Next() {
+			var id string
+			values := make([]sql.NullString, len(columns))
+			dest := []interface{}{&id}
+			for i := range values {
+				dest = append(dest, &values[i])
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return err
+			}
+			count++
+			lastID = id
+
+			for i, v := range values {
+				if v.Valid && cs.NeedsReencryption(v.String) {
+					pending = append(pending, pendingValue{id: id, column: columns[i], value: v.String})
+				}
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, p := range pending {
+			rotated, err := rotateValue(tx, cs, table, p.id, p.column, p.value, result)
+			if err != nil {
+				return err
+			}
+			if rotated {
+				result.Reencrypted++
+			}
+		}
+		result.Scanned += count
+		return nil
+	})
+	return count, lastID, err
+}
+
+// rotateValue rewrites one value with the active key. The update only applies while the column still holds
+// the value that was re-encrypted, so a credential changed in the meantime is never overwritten with the old
+// one; the new value is read again and rotated if it still needs it.
+func rotateValue(tx *gorm.DB, cs *crypto.CryptoService, table, id, column, value string, result *KeyRotationResult) (bool, error) {
+	for attempt := 0; attempt < keyRotationAttempts; attempt++ {
+		rotated, err := cs.ReencryptForStorage(value)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s %s.%s: %v", table, id, column, err))
+			return false, nil
+		}
+		update := tx.Table(table).Where("id = ? AND "+column+" = ?", id, value).Update(column, rotated)
+		if update.Error != nil {
+			return false, update.Error
+		}
+		if update.RowsAffected > 0 {
+			return true, nil
+		}
+
+		// Changed by a concurrent write
+		var current sql.NullString
+		if err := tx.Table(table).Select(column).Where("id = ?", id).Row().Scan(&current); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return false, nil
+			}
+			return false, err
+		}
+		if !current.Valid || !cs.NeedsReencryption(current.String) {
+			return false, nil
+		}
+		value = current.String
+	}
+	return false, fmt.Errorf("%s %s.%s kept changing during rotation", table, id, column)
+}
@@ -0,0 +1,344 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"nofx/logger"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockID PostgreSQL advisory lock key serializing migrations across instances
+const migrationLockID = 7250431
+
+// Migration a numbered schema change
+// Up must be safe to run on databases created by any earlier release; Down is nil when the
+// change cannot be reverted (data migrations, baseline schema)
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// SchemaMigration applied migration record
+type SchemaMigration struct {
+	Version   int    `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string `gorm:"not null" json:"name"`
+	AppliedAt int64  `gorm:"not null" json:"applied_at"` // Unix milliseconds UTC
+}
+
+func (SchemaMigration) TableName() string { return "schema_migrations" }
+
+// MigrationState a registered migration and whether it has been applied
+type MigrationState struct {
+	Version    int    `json:"version"`
+	Name       string `json:"name"`
+	Applied    bool   `json:"applied"`
+	AppliedAt  int64  `json:"applied_at,omitempty"`
+	Reversible bool   `json:"reversible"`
+}
+
+// MigrationStep a migration run (or planned, in dry-run mode) in one direction
+type MigrationStep struct {
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Direction string `json:"direction"` // up or down
+}
+
+// MigrateOptions controls Migrate and Rollback
+type MigrateOptions struct {
+	// Target version: Migrate applies up to and including it (0 = latest),
+	// Rollback reverts every applied migration above it
+	Target int
+	// DryRun only reports the steps that would run
+	DryRun bool
+}
+
+// Migrations returns the registered migrations ordered by version
+func Migrations() []Migration {
+	list := make([]Migration, len(migrations))
+	copy(list, migrations)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+// LatestMigrationVersion returns the highest registered migration version
+func LatestMigrationVersion() int {
+	list := Migrations()
+	if len(list) == 0 {
+		return 0
+	}
+	return list[len(list)-1].Version
+}
+
+// validateMigrations checks that versions are positive and unique
+func validateMigrations(list []Migration) error {
+	seen := make(map[int]string)
+	for _, m := range list {
+		if m.Version <= 0 {
+			return fmt.Errorf("migration %q has invalid version %d", m.Name, m.Version)
+		}
+		if m.Up == nil {
+			return fmt.Errorf("migration %d %s has no up step", m.Version, m.Name)
+		}
+		if prev, ok := seen[m.Version]; ok {
+			return fmt.Errorf("migrations %s and %s share version %d", prev, m.Name, m.Version)
+		}
+		seen[m.Version] = m.Name
+	}
+	return nil
+}
+
+// ensureMigrationTable creates schema_migrations if needed
+func (s *Store) ensureMigrationTable() error {
+	return s.gdb.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at BIGINT NOT NULL
+		)
+	`).Error
+}
+
+// appliedMigrations returns applied migrations keyed by version
+func (s *Store) appliedMigrations() (map[int]SchemaMigration, error) {
+	if err := s.ensureMigrationTable(); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	var rows []SchemaMigration
+	if err := s.gdb.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]SchemaMigration, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+// MigrationStatus lists registered migrations with their applied state
+func (s *Store) MigrationStatus() ([]MigrationState, error) {
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	var states []MigrationState
+	for _, m := range Migrations() {
+		r, ok := applied[m.Version]
+		states = append(states, MigrationState{
+			Version:    m.Version,
+			Name:       m.Name,
+			Applied:    ok,
+			AppliedAt:  r.AppliedAt,
+			Reversible: m.Down != nil,
+		})
+	}
+	return states, nil
+}
+
+// SchemaVersion returns the highest applied migration version (0 for an empty database)
+func (s *Store) SchemaVersion() (int, error) {
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return 0, err
+	}
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// Migrate applies pending migrations in version order, each in its own transaction
+func (s *Store) Migrate(opts MigrateOptions) ([]MigrationStep, error) {
+	list := Migrations()
+	if err := validateMigrations(list); err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+	for v := range applied {
+		if v > LatestMigrationVersion() {
+			return nil, fmt.Errorf("database schema version %d is newer than this binary supports (%d), upgrade nofx", v, LatestMigrationVersion())
+		}
+	}
+
+	var steps []MigrationStep
+	for _, m := range list {
+		if opts.Target > 0 && m.Version > opts.Target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		step := MigrationStep{Version: m.Version, Name: m.Name, Direction: "up"}
+		if opts.DryRun {
+			steps = append(steps, step)
+			continue
+		}
+
+		ran, err := s.runMigration(m, true)
+		if err != nil {
+			return steps, fmt.Errorf("migration %d %s failed: %w", m.Version, m.Name, err)
+		}
+		if ran {
+			logger.Infof("🗄️ Applied migration %d %s", m.Version, m.Name)
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+// Rollback reverts applied migrations above opts.Target, newest first
+func (s *Store) Rollback(opts MigrateOptions) ([]MigrationStep, error) {
+	if opts.Target < 0 {
+		return nil, fmt.Errorf("invalid target version %d", opts.Target)
+	}
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	list := Migrations()
+	byVersion := make(map[int]Migration, len(list))
+	for _, m := range list {
+		byVersion[m.Version] = m
+	}
+
+	var versions []int
+	for v := range applied {
+		if v > opts.Target {
+			versions = append(versions, v)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	// Check the whole range first so a rollback never stops half way at an irreversible step
+	for _, v := range versions {
+		m, ok := byVersion[v]
+		if !ok {
+			return nil, fmt.Errorf("migration %d is not known to this binary", v)
+		}
+		if m.Down == nil {
+			return nil, fmt.Errorf("migration %d %s is irreversible", m.Version, m.Name)
+		}
+	}
+
+	var steps []MigrationStep
+	for _, v := range versions {
+		m := byVersion[v]
+		step := MigrationStep{Version: m.Version, Name: m.Name, Direction: "down"}
+		if opts.DryRun {
+			steps = append(steps, step)
+			continue
+		}
+		ran, err := s.runMigration(m, false)
+		if err != nil {
+			return steps, fmt.Errorf("rollback of migration %d %s failed: %w", m.Version, m.Name, err)
+		}
+		if ran {
+			logger.Infof("🗄️ Reverted migration %d %s", m.Version, m.Name)
+			steps = append(steps, step)
+		}
+	}
+	return steps, nil
+}
+
+// runMigration runs one step and updates schema_migrations in the same transaction
+// Returns false when another instance already did the work
+func (s *Store) runMigration(m Migration, up bool) (bool, error) {
+	ran := false
+	err := s.gdb.Transaction(func(tx *gorm.DB) error {
+		if isPostgres(tx) {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+		}
+
+		var count int64
+		if err := tx.Model(&SchemaMigration{}).Where("version = ?", m.Version).Count(&count).Error; err != nil {
+			return err
+		}
+		if (count > 0) == up {
+			return nil
+		}
+
+		if up {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			ran = true
+			return tx.Create(&SchemaMigration{
+				Version:   m.Version,
+				Name:      m.Name,
+				AppliedAt: time.Now().UTC().UnixMilli(),
+			}).Error
+		}
+
+		if err := m.Down(tx); err != nil {
+			return err
+		}
+		ran = true
+		return tx.Where("version = ?", m.Version).Delete(&SchemaMigration{}).Error
+	})
+	return ran, err
+}
+
+// isPostgres reports whether the connection uses PostgreSQL
+func isPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// ensureTables creates missing tables and adds missing columns to existing ones
+// Unlike AutoMigrate it never alters column types or indexes of existing tables, which differ between
+// databases created by older releases; such changes belong in their own numbered migration
+func ensureTables(tx *gorm.DB, models ...interface{}) error {
+	m := tx.Migrator()
+	for _, model := range models {
+		if !m.HasTable(model) {
+			if err := m.CreateTable(model); err != nil {
+				return fmt.Errorf("failed to create table for %T: %w", model, err)
+			}
+			continue
+		}
+
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(model); err != nil {
+			return fmt.Errorf("failed to parse %T: %w", model, err)
+		}
+		for _, name := range stmt.Schema.DBNames {
+			if m.HasColumn(model, name) {
+				continue
+			}
+			if err := m.AddColumn(model, name); err != nil {
+				return fmt.Errorf("failed to add column %s.%s: %w", stmt.Schema.Table, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// columnType returns the information_schema data type of a PostgreSQL column ("" if missing)
+func columnType(tx *gorm.DB, table, column string) (string, error) {
+	var dataType string
+	err := tx.Raw(`SELECT data_type FROM information_schema.columns WHERE table_name = ? AND column_name = ?`,
+		table, column).Scan(&dataType).Error
+	return dataType, err
+}
+
+// execAll runs statements in order, stopping at the first error
+func execAll(tx *gorm.DB, stmts ...string) error {
+	for _, stmt := range stmts {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("%w (%s)", err, stmt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,433 @@
+package store
+
+import (
+	"fmt"
+
+	"nofx/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// migrations registered schema migrations
+// Append new migrations with the next version number; never edit or renumber an applied one
+var migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "baseline_schema",
+		Up:      migrateBaselineSchema,
+	},
+	{
+		Version: 2,
+		Name:    "users_email_unique_index",
+		Up:      migrateUsersEmailIndex,
+	},
+	{
+		Version: 3,
+		Name:    "backtest_bigint_timestamps",
+		Up:      migrateBacktestTimestamps,
+	},
+	{
+		Version: 4,
+		Name:    "order_fill_column_types",
+		Up:      migrateOrderColumnTypes,
+	},
+	{
+		Version: 5,
+		Name:    "order_fill_exchange_unique_indexes",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				`CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_exchange_unique ON trader_orders(exchange_id, exchange_order_id)`,
+				`CREATE UNIQUE INDEX IF NOT EXISTS idx_fills_exchange_unique ON trader_fills(exchange_id, exchange_trade_id)`,
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx,
+				`DROP INDEX IF EXISTS idx_orders_exchange_unique`,
+				`DROP INDEX IF EXISTS idx_fills_exchange_unique`,
+			)
+		},
+	},
+	{
+		Version: 6,
+		Name:    "position_ms_timestamps",
+		Up:      migratePositionTimestamps,
+	},
+	{
+		Version: 7,
+		Name:    "position_exchange_unique_index",
+		Up:      createPositionUniqueIndex,
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx, `DROP INDEX IF EXISTS idx_positions_exchange_pos_unique`)
+		},
+	},
+	{
+		Version: 8,
+		Name:    "analysis_query_indexes",
+		Up: func(tx *gorm.DB) error {
+			return execAll(tx,
+				`CREATE INDEX IF NOT EXISTS idx_trader_symbol_time ON ai_analysis(trader_id, symbol, created_at DESC)`,
+				`CREATE INDEX IF NOT EXISTS idx_trader_status ON ai_analysis(trader_id, status)`,
+				`CREATE INDEX IF NOT EXISTS idx_symbol_time ON ai_analysis(symbol, created_at DESC)`,
+				`CREATE INDEX IF NOT EXISTS idx_reflection_period ON reflections(trader_id, reflection_time DESC)`,
+				`CREATE INDEX IF NOT EXISTS idx_adjustment_status ON system_adjustments(trader_id, status)`,
+				`CREATE INDEX IF NOT EXISTS idx_memory_active ON ai_learning_memory(trader_id, expires_at DESC)`,
+				`CREATE INDEX IF NOT EXISTS idx_adaptive_trader_symbol ON adaptive_stoploss_records(trader_id, symbol)`,
+				`CREATE INDEX IF NOT EXISTS idx_adaptive_status ON adaptive_stoploss_records(status)`,
+			)
+		},
+		Down: func(tx *gorm.DB) error {
+			return execAll(tx,
+				`DROP INDEX IF EXISTS idx_trader_symbol_time`,
+				`DROP INDEX IF EXISTS idx_trader_status`,
+				`DROP INDEX IF EXISTS idx_symbol_time`,
+				`DROP INDEX IF EXISTS idx_reflection_period`,
+				`DROP INDEX IF EXISTS idx_adjustment_status`,
+				`DROP INDEX IF EXISTS idx_memory_active`,
+				`DROP INDEX IF EXISTS idx_adaptive_trader_symbol`,
+				`DROP INDEX IF EXISTS idx_adaptive_status`,
+			)
+		},
+	},
+	{
+		Version: 9,
+		Name:    "exchange_multi_account",
+		Up:      migrateExchangesToMultiAccount,
+	},
+	{
+		Version: 10,
+		Name:    "equity_from_decision_snapshots",
+		Up: func(tx *gorm.DB) error {
+			migrated, err := NewEquityStore(tx).MigrateFromDecision()
+			if migrated > 0 {
+				logger.Infof("✅ Migrated %d equity records to new table", migrated)
+			}
+			return err
+		},
+	},
+	{
+		Version: 11,
+		Name:    "audit_log_append_only",
+		Up:      createAuditTriggers,
+		Down:    dropAuditTriggers,
+	},
+	{
+		Version: 12,
+		Name:    "trader_debate_panels",
+		Up: func(tx *gorm.DB) error {
+			return ensureTables(tx, &v12Trader{}, &v12DecisionRecord{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&v12DecisionRecord{}, "debate_session_id"); err != nil {
+				return err
+			}
+			return m.DropColumn(&v12Trader{}, "debate_panel_id")
+		},
+	},
+	{
+		Version: 13,
+		Name:    "debate_custom_personalities_and_moderator",
+		Up: func(tx *gorm.DB) error {
+			return ensureTables(tx, &v13CustomPersonality{}, &v13DebateSession{}, &v13DebateParticipant{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			for _, col := range []string{"personality_name", "custom_prompt", "risk_bias", "emoji"} {
+				if err := m.DropColumn(&v13DebateParticipant{}, col); err != nil {
+					return err
+				}
+			}
+			for _, col := range []string{"consensus_method", "moderator_ai_model_id"} {
+				if err := m.DropColumn(&v13DebateSession{}, col); err != nil {
+					return err
+				}
+			}
+			return m.DropTable(&v13CustomPersonality{})
+		},
+	},
+	{
+		Version: 14,
+		Name:    "debate_outcome_scorecards",
+		Up: func(tx *gorm.DB) error {
+			return ensureTables(tx, &v14DebateOutcome{}, &v14DebateSession{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&v14DebateSession{}, "weight_by_accuracy"); err != nil {
+				return err
+			}
+			return m.DropTable(&v14DebateOutcome{})
+		},
+	},
+	{
+		Version: 15,
+		Name:    "prompt_template_library",
+		Up: func(tx *gorm.DB) error {
+			return ensureTables(tx, &v15PromptTemplate{}, &v15PromptTemplateVersion{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v15PromptTemplateVersion{}, &v15PromptTemplate{})
+		},
+	},
+	{
+		Version: 16,
+		Name:    "prompt_experiments",
+		Up: func(tx *gorm.DB) error {
+			return ensureTables(tx, &v16Experiment{}, &v16ExperimentVariant{}, &v16ExperimentDecision{}, &v16ExperimentEquityPoint{})
+		},
+		Down: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&v16ExperimentEquityPoint{}, &v16ExperimentDecision{}, &v16ExperimentVariant{}, &v16Experiment{})
+		},
+	},
+	{
+		Version: 17,
+		Name:    "ai_model_failover",
+		Up: func(tx *gorm.DB) error {
+			return ensureTables(tx, &v17Trader{}, &v17DecisionRecord{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&v17DecisionRecord{}, "ai_model"); err != nil {
+				return err
+			}
+			return m.DropColumn(&v17Trader{}, "fallback_ai_model_ids")
+		},
+	},
+	{
+		Version: 18,
+		Name:    "debate_vote_participants",
+		Up: func(tx *gorm.DB) error {
+			return ensureTables(tx, &v18DebateVote{})
+		},
+		Down: func(tx *gorm.DB) error {
+			m := tx.Migrator()
+			if err := m.DropColumn(&v18DebateVote{}, "personality"); err != nil {
+				return err
+			}
+			return m.DropColumn(&v18DebateVote{}, "participant_id")
+		},
+	},
+}
+
+// migrateBaselineSchema creates every table known before versioned migrations were introduced
+// Databases created by older releases keep their tables; only missing columns are added
+func migrateBaselineSchema(tx *gorm.DB) error {
+	if err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS system_config (
+			key TEXT PRIMARY KEY,
+			value TEXT NOT NULL
+		)
+	`).Error; err != nil {
+		return fmt.Errorf("failed to create system_config table: %w", err)
+	}
+
+	return ensureTables(tx, baselineModels...)
+}
+
+// migrateUsersEmailIndex ensures a unique index on users.email
+// Older PostgreSQL databases may carry it under another name, so any unique index on email counts
+func migrateUsersEmailIndex(tx *gorm.DB) error {
+	var count int64
+	var err error
+	if isPostgres(tx) {
+		err = tx.Raw(`
+			SELECT COUNT(*) FROM pg_indexes
+			WHERE tablename = 'users' AND indexdef LIKE '%email%' AND indexdef LIKE '%UNIQUE%'
+		`).Scan(&count).Error
+	} else {
+		err = tx.Raw(`
+			SELECT COUNT(*) FROM sqlite_master
+			WHERE type = 'index' AND tbl_name = 'users' AND sql LIKE '%email%' AND sql LIKE '%UNIQUE%'
+		`).Scan(&count).Error
+	}
+	if err != nil || count > 0 {
+		return err
+	}
+	return execAll(tx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users(email)`)
+}
+
+// migrateBacktestTimestamps widens PostgreSQL ts columns created as INTEGER
+// (millisecond timestamps exceed int4), SQLite integers are already 64-bit
+func migrateBacktestTimestamps(tx *gorm.DB) error {
+	if isPostgres(tx) {
+		for _, table := range []string{"backtest_equity", "backtest_trades"} {
+			dataType, err := columnType(tx, table, "ts")
+			if err != nil {
+				return err
+			}
+			if dataType == "integer" {
+				if err := execAll(tx, fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN ts TYPE BIGINT`, table)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return execAll(tx,
+		`CREATE INDEX IF NOT EXISTS idx_backtest_equity_run_ts ON backtest_equity(run_id, ts)`,
+		`CREATE INDEX IF NOT EXISTS idx_backtest_trades_run_ts ON backtest_trades(run_id, ts)`,
+		`CREATE INDEX IF NOT EXISTS idx_backtest_decisions_run_cycle ON backtest_decisions(run_id, cycle)`,
+	)
+}
+
+// migrateOrderColumnTypes converts PostgreSQL order/fill flags stored as INTEGER to BOOLEAN and
+// timestamp columns to Unix milliseconds (BIGINT), then ensures the lookup indexes exist
+func migrateOrderColumnTypes(tx *gorm.DB) error {
+	if isPostgres(tx) {
+		boolColumns := []struct{ table, col string }{
+			{"trader_orders", "reduce_only"},
+			{"trader_orders", "close_position"},
+			{"trader_orders", "price_protect"},
+			{"trader_fills", "is_maker"},
+		}
+		for _, c := range boolColumns {
+			dataType, err := columnType(tx, c.table, c.col)
+			if err != nil {
+				return err
+			}
+			if dataType != "integer" && dataType != "smallint" && dataType != "bigint" {
+				continue
+			}
+			// Need to: drop default -> change type -> set new default
+			if err := execAll(tx,
+				fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT", c.table, c.col),
+				fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE BOOLEAN USING %s::int::boolean", c.table, c.col, c.col),
+				fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT false", c.table, c.col),
+			); err != nil {
+				return err
+			}
+		}
+
+		timestampColumns := []struct{ table, col string }{
+			{"trader_orders", "created_at"},
+			{"trader_orders", "updated_at"},
+			{"trader_orders", "filled_at"},
+			{"trader_fills", "created_at"},
+		}
+		for _, c := range timestampColumns {
+			if err := timestampToMillis(tx, c.table, c.col); err != nil {
+				return err
+			}
+		}
+	}
+
+	return execAll(tx,
+		`CREATE INDEX IF NOT EXISTS idx_orders_trader_id ON trader_orders(trader_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_orders_symbol ON trader_orders(symbol)`,
+		`CREATE INDEX IF NOT EXISTS idx_orders_status ON trader_orders(status)`,
+		`CREATE INDEX IF NOT EXISTS idx_fills_trader_id ON trader_fills(trader_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_fills_order_id ON trader_fills(order_id)`,
+	)
+}
+
+// migratePositionTimestamps converts PostgreSQL position timestamp columns to Unix milliseconds
+func migratePositionTimestamps(tx *gorm.DB) error {
+	if !isPostgres(tx) {
+		return nil
+	}
+	for _, col := range []string{"entry_time", "exit_time", "created_at", "updated_at"} {
+		if err := timestampToMillis(tx, "trader_positions", col); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timestampToMillis converts a PostgreSQL timestamp column to BIGINT Unix milliseconds, if it still is one
+func timestampToMillis(tx *gorm.DB, table, col string) error {
+	dataType, err := columnType(tx, table, col)
+	if err != nil {
+		return err
+	}
+	if dataType != "timestamp with time zone" && dataType != "timestamp without time zone" {
+		return nil
+	}
+	return execAll(tx, fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE BIGINT USING EXTRACT(EPOCH FROM %s) * 1000`, table, col, col))
+}
+
+// createPositionUniqueIndex creates the partial unique index used for exchange position deduplication
+func createPositionUniqueIndex(tx *gorm.DB) error {
+	return execAll(tx, `CREATE UNIQUE INDEX IF NOT EXISTS idx_positions_exchange_pos_unique ON trader_positions(exchange_id, exchange_position_id) WHERE exchange_position_id != ''`)
+}
+
+// migrateExchangesToMultiAccount migrates old exchange rows (id=exchange_type) to UUID ids with an account name
+func migrateExchangesToMultiAccount(tx *gorm.DB) error {
+	legacyIDs := []string{"binance", "bybit", "okx", "bitget", "hyperliquid", "aster", "lighter"}
+
+	var records []v1Exchange
+	if err := tx.Where("exchange_type = '' AND id IN ?", legacyIDs).Find(&records).Error; err != nil {
+		return err
+	}
+	if len(records) > 0 {
+		logger.Infof("🔄 Migrating %d exchange records to multi-account schema...", len(records))
+	}
+
+	for _, r := range records {
+		newID := uuid.New().String()
+		oldID := r.ID // This is the exchange type (e.g., "binance")
+
+		// Update traders table to use new UUID
+		if err := tx.Exec("UPDATE traders SET exchange_id = ? WHERE exchange_id = ? AND user_id = ?",
+			newID, oldID, r.UserID).Error; err != nil {
+			return fmt.Errorf("failed to update traders for exchange %s: %w", oldID, err)
+		}
+
+		if err := tx.Model(&v1Exchange{}).
+			Where("id = ? AND user_id = ?", oldID, r.UserID).
+			Updates(map[string]interface{}{
+				"id":            newID,
+				"exchange_type": oldID,
+				"account_name":  "Default",
+			}).Error; err != nil {
+			return fmt.Errorf("failed to migrate exchange %s: %w", oldID, err)
+		}
+
+		logger.Infof("✅ Migrated exchange %s -> UUID %s for user %s", oldID, newID, r.UserID)
+	}
+
+	// Fix empty account_name for existing records
+	return tx.Model(&v1Exchange{}).
+		Where("account_name = '' OR account_name IS NULL").
+		Update("account_name", "Default").Error
+}
+
+// createAuditTriggers makes audit_logs append-only at the database level
+func createAuditTriggers(tx *gorm.DB) error {
+	if isPostgres(tx) {
+		return execAll(tx,
+			`CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+			BEGIN
+				RAISE EXCEPTION 'audit_logs is append-only';
+			END;
+			$$ LANGUAGE plpgsql`,
+			`DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs`,
+			`CREATE TRIGGER audit_logs_no_modify BEFORE UPDATE OR DELETE ON audit_logs
+				FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only()`,
+		)
+	}
+	for _, op := range []string{"UPDATE", "DELETE"} {
+		if err := execAll(tx, fmt.Sprintf(`
+			CREATE TRIGGER IF NOT EXISTS audit_logs_no_%s BEFORE %s ON audit_logs
+			BEGIN
+				SELECT RAISE(ABORT, 'audit_logs is append-only');
+			END
+		`, op, op)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dropAuditTriggers removes the append-only triggers from audit_logs
+func dropAuditTriggers(tx *gorm.DB) error {
+	if isPostgres(tx) {
+		return execAll(tx,
+			`DROP TRIGGER IF EXISTS audit_logs_no_modify ON audit_logs`,
+			`DROP FUNCTION IF EXISTS audit_logs_append_only()`,
+		)
+	}
+	return execAll(tx,
+		`DROP TRIGGER IF EXISTS audit_logs_no_update`,
+		`DROP TRIGGER IF EXISTS audit_logs_no_delete`,
+	)
+}
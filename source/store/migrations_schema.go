@@ -0,0 +1,883 @@
+package store
+
+import (
+	"time"
+
+	"nofx/crypto"
+)
+
+// Frozen table definitions used by the migrations
+// Each struct describes a table, or the columns a migration adds to one, exactly as the migration
+// created it. They must never follow later model changes: a schema change is a new migration with
+// its own structs, so every version produces the same schema regardless of the binary
+
+// baselineModels tables that existed before versioned migrations were introduced
+var baselineModels = []interface{}{
+	&v1User{},
+	&v1AIModel{},
+	&v1Exchange{},
+	&v1Trader{},
+	&v1DecisionRecord{},
+	&v1BacktestRun{},
+	&v1BacktestCheckpoint{},
+	&v1BacktestEquity{},
+	&v1BacktestTrade{},
+	&v1BacktestMetrics{},
+	&v1BacktestDecision{},
+	&v1TraderPosition{},
+	&v1Strategy{},
+	&v1EquitySnapshot{},
+	&v1APIToken{},
+	&v1Workspace{},
+	&v1WorkspaceMember{},
+	&v1AuditEntry{},
+	&v1TraderOrder{},
+	&v1TraderFill{},
+	&v1TPSLRecord{},
+	&v1AnalysisRecord{},
+	&v1PendingOrder{},
+	&v1TradeHistoryRecord{},
+	&v1ReflectionRecord{},
+	&v1SystemAdjustment{},
+	&v1AILearningMemory{},
+	&v1AdaptiveStopLossRecord{},
+	&v1DebateSession{},
+	&v1DebateParticipant{},
+	&v1DebateMessage{},
+	&v1DebateVote{},
+}
+
+// ============================================================================
+// v1 baseline_schema
+// ============================================================================
+
+type v1User struct {
+	ID           string `gorm:"primaryKey"`
+	Email        string `gorm:"uniqueIndex:idx_users_email;not null"`
+	PasswordHash string `gorm:"column:password_hash;not null"`
+	OTPSecret    string `gorm:"column:otp_secret"`
+	OTPVerified  bool   `gorm:"column:otp_verified;default:false"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (v1User) TableName() string { return "users" }
+
+type v1AIModel struct {
+	ID              string                 `gorm:"primaryKey"`
+	UserID          string                 `gorm:"column:user_id;not null;default:default;index"`
+	Name            string                 `gorm:"not null"`
+	Provider        string                 `gorm:"not null"`
+	Enabled         bool                   `gorm:"default:false"`
+	APIKey          crypto.EncryptedString `gorm:"column:api_key;default:''"`
+	CustomAPIURL    string                 `gorm:"column:custom_api_url;default:''"`
+	CustomModelName string                 `gorm:"column:custom_model_name;default:''"`
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (v1AIModel) TableName() string { return "ai_models" }
+
+type v1Exchange struct {
+	ID                      string                 `gorm:"primaryKey"`
+	ExchangeType            string                 `gorm:"column:exchange_type;not null;default:''"`
+	AccountName             string                 `gorm:"column:account_name;not null;default:''"`
+	UserID                  string                 `gorm:"column:user_id;not null;default:default;index"`
+	Name                    string                 `gorm:"not null"`
+	Type                    string                 `gorm:"not null"` // "cex" or "dex"
+	Enabled                 bool                   `gorm:"default:false"`
+	APIKey                  crypto.EncryptedString `gorm:"column:api_key;default:''"`
+	SecretKey               crypto.EncryptedString `gorm:"column:secret_key;default:''"`
+	Passphrase              crypto.EncryptedString `gorm:"column:passphrase;default:''"`
+	Testnet                 bool                   `gorm:"default:false"`
+	HyperliquidWalletAddr   string                 `gorm:"column:hyperliquid_wallet_addr;default:''"`
+	AsterUser               string                 `gorm:"column:aster_user;default:''"`
+	AsterSigner             string                 `gorm:"column:aster_signer;default:''"`
+	AsterPrivateKey         crypto.EncryptedString `gorm:"column:aster_private_key;default:''"`
+	LighterWalletAddr       string                 `gorm:"column:lighter_wallet_addr;default:''"`
+	LighterPrivateKey       crypto.EncryptedString `gorm:"column:lighter_private_key;default:''"`
+	LighterAPIKeyPrivateKey crypto.EncryptedString `gorm:"column:lighter_api_key_private_key;default:''"`
+	LighterAPIKeyIndex      int                    `gorm:"column:lighter_api_key_index;default:0"`
+	CreatedAt               time.Time
+	UpdatedAt               time.Time
+}
+
+func (v1Exchange) TableName() string { return "exchanges" }
+
+type v1Trader struct {
+	ID                  string    `gorm:"primaryKey"`
+	UserID              string    `gorm:"column:user_id;not null;default:default;index"`
+	Name                string    `gorm:"column:name;not null"`
+	AIModelID           string    `gorm:"column:ai_model_id;not null"`
+	ExchangeID          string    `gorm:"column:exchange_id;not null"`
+	StrategyID          string    `gorm:"column:strategy_id;default:''"`
+	InitialBalance      float64   `gorm:"column:initial_balance;not null"`
+	ScanIntervalMinutes int       `gorm:"column:scan_interval_minutes;default:3"`
+	IsRunning           bool      `gorm:"column:is_running;default:false"`
+	IsCrossMargin       bool      `gorm:"column:is_cross_margin;default:true"`
+	ShowInCompetition   bool      `gorm:"column:show_in_competition;default:true"`
+	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"column:updated_at;autoUpdateTime"`
+
+	// Following fields are deprecated, kept for backward compatibility, new traders should use StrategyID
+	BTCETHLeverage       int    `gorm:"column:btc_eth_leverage;default:5"`
+	AltcoinLeverage      int    `gorm:"column:altcoin_leverage;default:5"`
+	TradingSymbols       string `gorm:"column:trading_symbols;default:''"`
+	UseAI500             bool   `gorm:"column:use_coin_pool;default:false"`
+	UseOITop             bool   `gorm:"column:use_oi_top;default:false"`
+	CustomPrompt         string `gorm:"column:custom_prompt;default:''"`
+	OverrideBasePrompt   bool   `gorm:"column:override_base_prompt;default:false"`
+	SystemPromptTemplate string `gorm:"column:system_prompt_template;default:default"`
+}
+
+func (v1Trader) TableName() string { return "traders" }
+
+type v1DecisionRecord struct {
+	ID                  int64     `gorm:"primaryKey;autoIncrement"`
+	TraderID            string    `gorm:"column:trader_id;not null;index:idx_decision_records_trader_time"`
+	CycleNumber         int       `gorm:"column:cycle_number;not null"`
+	Timestamp           time.Time `gorm:"not null;index:idx_decision_records_trader_time,sort:desc;index:idx_decision_records_timestamp,sort:desc"`
+	SystemPrompt        string    `gorm:"column:system_prompt;default:''"`
+	InputPrompt         string    `gorm:"column:input_prompt;default:''"`
+	CoTTrace            string    `gorm:"column:cot_trace;default:''"`
+	DecisionJSON        string    `gorm:"column:decision_json;default:''"`
+	RawResponse         string    `gorm:"column:raw_response;default:''"`
+	CandidateCoins      string    `gorm:"column:candidate_coins;default:''"`
+	ExecutionLog        string    `gorm:"column:execution_log;default:''"`
+	Decisions           string    `gorm:"column:decisions;default:'[]'"`
+	Success             bool      `gorm:"default:false"`
+	ErrorMessage        string    `gorm:"column:error_message;default:''"`
+	AIRequestDurationMs int64     `gorm:"column:ai_request_duration_ms;default:0"`
+	TriggerEvent        string    `gorm:"column:trigger_event;default:''"`
+	CreatedAt           time.Time
+}
+
+func (v1DecisionRecord) TableName() string { return "decision_records" }
+
+type v1BacktestRun struct {
+	RunID           string    `gorm:"column:run_id;primaryKey"`
+	UserID          string    `gorm:"column:user_id;not null;default:''"`
+	ConfigJSON      []byte    `gorm:"column:config_json"`
+	State           string    `gorm:"column:state;not null;default:created"`
+	Label           string    `gorm:"column:label;default:''"`
+	SymbolCount     int       `gorm:"column:symbol_count;default:0"`
+	DecisionTF      string    `gorm:"column:decision_tf;default:''"`
+	ProcessedBars   int       `gorm:"column:processed_bars;default:0"`
+	ProgressPct     float64   `gorm:"column:progress_pct;default:0"`
+	EquityLast      float64   `gorm:"column:equity_last;default:0"`
+	MaxDrawdownPct  float64   `gorm:"column:max_drawdown_pct;default:0"`
+	Liquidated      bool      `gorm:"column:liquidated;default:false"`
+	LiquidationNote string    `gorm:"column:liquidation_note;default:''"`
+	PromptTemplate  string    `gorm:"column:prompt_template;default:''"`
+	CustomPrompt    string    `gorm:"column:custom_prompt;default:''"`
+	OverridePrompt  bool      `gorm:"column:override_prompt;default:false"`
+	AIProvider      string    `gorm:"column:ai_provider;default:''"`
+	AIModel         string    `gorm:"column:ai_model;default:''"`
+	LastError       string    `gorm:"column:last_error;default:''"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (v1BacktestRun) TableName() string { return "backtest_runs" }
+
+type v1BacktestCheckpoint struct {
+	RunID     string    `gorm:"column:run_id;primaryKey"`
+	Payload   []byte    `gorm:"column:payload;not null"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (v1BacktestCheckpoint) TableName() string { return "backtest_checkpoints" }
+
+type v1BacktestEquity struct {
+	ID        int64   `gorm:"primaryKey;autoIncrement"`
+	RunID     string  `gorm:"column:run_id;not null;index:idx_backtest_equity_run_ts"`
+	TS        int64   `gorm:"column:ts;type:bigint;not null;index:idx_backtest_equity_run_ts"`
+	Equity    float64 `gorm:"column:equity;not null"`
+	Available float64 `gorm:"column:available;not null"`
+	PnL       float64 `gorm:"column:pnl;not null"`
+	PnLPct    float64 `gorm:"column:pnl_pct;not null"`
+	DDPct     float64 `gorm:"column:dd_pct;not null"`
+	Cycle     int     `gorm:"column:cycle;not null"`
+}
+
+func (v1BacktestEquity) TableName() string { return "backtest_equity" }
+
+type v1BacktestTrade struct {
+	ID            int64   `gorm:"primaryKey;autoIncrement"`
+	RunID         string  `gorm:"column:run_id;not null;index:idx_backtest_trades_run_ts"`
+	TS            int64   `gorm:"column:ts;type:bigint;not null;index:idx_backtest_trades_run_ts"`
+	Symbol        string  `gorm:"column:symbol;not null"`
+	Action        string  `gorm:"column:action;not null"`
+	Side          string  `gorm:"column:side;default:''"`
+	Qty           float64 `gorm:"column:qty;default:0"`
+	Price         float64 `gorm:"column:price;default:0"`
+	Fee           float64 `gorm:"column:fee;default:0"`
+	Slippage      float64 `gorm:"column:slippage;default:0"`
+	OrderValue    float64 `gorm:"column:order_value;default:0"`
+	RealizedPnL   float64 `gorm:"column:realized_pnl;default:0"`
+	Leverage      int     `gorm:"column:leverage;default:0"`
+	Cycle         int     `gorm:"column:cycle;default:0"`
+	PositionAfter float64 `gorm:"column:position_after;default:0"`
+	Liquidation   bool    `gorm:"column:liquidation;default:false"`
+	Note          string  `gorm:"column:note;default:''"`
+}
+
+func (v1BacktestTrade) TableName() string { return "backtest_trades" }
+
+type v1BacktestMetrics struct {
+	RunID     string    `gorm:"column:run_id;primaryKey"`
+	Payload   []byte    `gorm:"column:payload;not null"`
+	UpdatedAt time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (v1BacktestMetrics) TableName() string { return "backtest_metrics" }
+
+type v1BacktestDecision struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	RunID     string    `gorm:"column:run_id;not null;index:idx_backtest_decisions_run_cycle"`
+	Cycle     int       `gorm:"column:cycle;not null;index:idx_backtest_decisions_run_cycle"`
+	Payload   []byte    `gorm:"column:payload;not null"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v1BacktestDecision) TableName() string { return "backtest_decisions" }
+
+type v1TraderPosition struct {
+	ID                 int64   `gorm:"primaryKey;autoIncrement"`
+	TraderID           string  `gorm:"column:trader_id;not null;index:idx_positions_trader"`
+	ExchangeID         string  `gorm:"column:exchange_id;not null;default:'';index:idx_positions_exchange"`
+	ExchangeType       string  `gorm:"column:exchange_type;not null;default:''"`
+	ExchangePositionID string  `gorm:"column:exchange_position_id;not null;default:''"`
+	Symbol             string  `gorm:"column:symbol;not null"`
+	Side               string  `gorm:"column:side;not null"`
+	EntryQuantity      float64 `gorm:"column:entry_quantity;default:0"`
+	Quantity           float64 `gorm:"column:quantity;not null"`
+	EntryPrice         float64 `gorm:"column:entry_price;not null"`
+	EntryOrderID       string  `gorm:"column:entry_order_id;default:''"`
+	EntryTime          int64   `gorm:"column:entry_time;not null;index:idx_positions_entry"` // Unix milliseconds UTC
+	ExitPrice          float64 `gorm:"column:exit_price;default:0"`
+	ExitOrderID        string  `gorm:"column:exit_order_id;default:''"`
+	ExitTime           int64   `gorm:"column:exit_time;index:idx_positions_exit"` // Unix milliseconds UTC, 0 means not set
+	RealizedPnL        float64 `gorm:"column:realized_pnl;default:0"`
+	Fee                float64 `gorm:"column:fee;default:0"`
+	Leverage           int     `gorm:"column:leverage;default:1"`
+	Status             string  `gorm:"column:status;default:OPEN;index:idx_positions_status"`
+	CloseReason        string  `gorm:"column:close_reason;default:''"`
+	Source             string  `gorm:"column:source;default:system"`
+	CreatedAt          int64   `gorm:"column:created_at"` // Unix milliseconds UTC
+	UpdatedAt          int64   `gorm:"column:updated_at"` // Unix milliseconds UTC
+}
+
+func (v1TraderPosition) TableName() string { return "trader_positions" }
+
+type v1Strategy struct {
+	ID            string `gorm:"primaryKey"`
+	UserID        string `gorm:"column:user_id;not null;default:'';index"`
+	Name          string `gorm:"not null"`
+	Description   string `gorm:"default:''"`
+	IsActive      bool   `gorm:"column:is_active;default:false;index"`
+	IsDefault     bool   `gorm:"column:is_default;default:false"`
+	IsPublic      bool   `gorm:"column:is_public;default:false;index"` // whether visible in strategy market
+	ConfigVisible bool   `gorm:"column:config_visible;default:true"`   // whether config details are visible
+	Config        string `gorm:"not null;default:'{}'"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (v1Strategy) TableName() string { return "strategies" }
+
+type v1EquitySnapshot struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	TraderID      string    `gorm:"column:trader_id;not null;index:idx_equity_trader_time"`
+	Timestamp     time.Time `gorm:"not null;index:idx_equity_trader_time,sort:desc;index:idx_equity_timestamp,sort:desc"`
+	TotalEquity   float64   `gorm:"column:total_equity;not null;default:0"`
+	Balance       float64   `gorm:"not null;default:0"`
+	UnrealizedPnL float64   `gorm:"column:unrealized_pnl;not null;default:0"`
+	PositionCount int       `gorm:"column:position_count;default:0"`
+	MarginUsedPct float64   `gorm:"column:margin_used_pct;default:0"`
+	CreatedAt     time.Time
+}
+
+func (v1EquitySnapshot) TableName() string { return "trader_equity_snapshots" }
+
+type v1APIToken struct {
+	ID                 string     `gorm:"primaryKey"`
+	UserID             string     `gorm:"column:user_id;not null;index:idx_api_tokens_user"`
+	Name               string     `gorm:"not null"`
+	TokenHash          string     `gorm:"column:token_hash;not null;uniqueIndex:idx_api_tokens_hash"`
+	TokenPrefix        string     `gorm:"column:token_prefix"` // first characters, to recognize a token in the UI
+	Scopes             string     `gorm:"not null"`            // comma-separated
+	RateLimitPerMinute int        `gorm:"column:rate_limit_per_minute;default:0"`
+	ExpiresAt          *time.Time `gorm:"column:expires_at"`
+	LastUsedAt         *time.Time `gorm:"column:last_used_at"`
+	LastUsedIP         string     `gorm:"column:last_used_ip"`
+	RevokedAt          *time.Time `gorm:"column:revoked_at"`
+	CreatedAt          time.Time
+}
+
+func (v1APIToken) TableName() string { return "api_tokens" }
+
+type v1Workspace struct {
+	ID        string `gorm:"primaryKey"`
+	Name      string `gorm:"not null"`
+	CreatedBy string `gorm:"column:created_by;not null"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (v1Workspace) TableName() string { return "workspaces" }
+
+type v1WorkspaceMember struct {
+	ID          int64  `gorm:"primaryKey;autoIncrement"`
+	WorkspaceID string `gorm:"column:workspace_id;not null;uniqueIndex:idx_workspace_members_unique"`
+	UserID      string `gorm:"column:user_id;not null;uniqueIndex:idx_workspace_members_unique;index:idx_workspace_members_user"`
+	Role        string `gorm:"not null"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (v1WorkspaceMember) TableName() string { return "workspace_members" }
+
+type v1AuditEntry struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement"`
+	OwnerID    string    `gorm:"column:owner_id;not null;index:idx_audit_logs_owner"` // user or workspace whose resources were changed
+	Actor      string    `gorm:"not null"`                                            // user ID, or component name for system actors
+	ActorType  string    `gorm:"column:actor_type;not null"`
+	APITokenID string    `gorm:"column:api_token_id"`
+	Action     string    `gorm:"not null;index:idx_audit_logs_action"`
+	TargetType string    `gorm:"column:target_type"`
+	TargetID   string    `gorm:"column:target_id;index:idx_audit_logs_target"`
+	Before     string    `gorm:"type:text"` // JSON summary of the state before the change
+	After      string    `gorm:"type:text"` // JSON summary of the state after the change
+	IP         string    `gorm:"column:ip"`
+	Status     int       `gorm:"default:0"` // HTTP status for API actions, 0 for automated actions
+	CreatedAt  time.Time `gorm:"index:idx_audit_logs_created"`
+	PrevHash   string    `gorm:"column:prev_hash"`
+	Hash       string    `gorm:"not null;uniqueIndex:idx_audit_logs_hash"`
+}
+
+func (v1AuditEntry) TableName() string { return "audit_logs" }
+
+type v1TraderOrder struct {
+	ID                int64   `gorm:"primaryKey;autoIncrement"`
+	TraderID          string  `gorm:"column:trader_id;not null;index:idx_orders_trader_id"`
+	ExchangeID        string  `gorm:"column:exchange_id;not null;default:''"`
+	ExchangeType      string  `gorm:"column:exchange_type;not null;default:''"`
+	ExchangeOrderID   string  `gorm:"column:exchange_order_id;not null;uniqueIndex:idx_orders_exchange_unique,priority:2"`
+	ClientOrderID     string  `gorm:"column:client_order_id;default:''"`
+	Symbol            string  `gorm:"column:symbol;not null;index:idx_orders_symbol"`
+	Side              string  `gorm:"column:side;not null"`
+	PositionSide      string  `gorm:"column:position_side;default:''"`
+	Type              string  `gorm:"column:type;not null"`
+	TimeInForce       string  `gorm:"column:time_in_force;default:GTC"`
+	Quantity          float64 `gorm:"column:quantity;not null"`
+	Price             float64 `gorm:"column:price;default:0"`
+	StopPrice         float64 `gorm:"column:stop_price;default:0"`
+	Status            string  `gorm:"column:status;not null;default:NEW;index:idx_orders_status"`
+	FilledQuantity    float64 `gorm:"column:filled_quantity;default:0"`
+	AvgFillPrice      float64 `gorm:"column:avg_fill_price;default:0"`
+	Commission        float64 `gorm:"column:commission;default:0"`
+	CommissionAsset   string  `gorm:"column:commission_asset;default:USDT"`
+	Leverage          int     `gorm:"column:leverage;default:1"`
+	ReduceOnly        bool    `gorm:"column:reduce_only;default:false"`
+	ClosePosition     bool    `gorm:"column:close_position;default:false"`
+	WorkingType       string  `gorm:"column:working_type;default:CONTRACT_PRICE"`
+	PriceProtect      bool    `gorm:"column:price_protect;default:false"`
+	OrderAction       string  `gorm:"column:order_action;default:''"`
+	RelatedPositionID int64   `gorm:"column:related_position_id;default:0"`
+	CreatedAt         int64   `gorm:"column:created_at"` // Unix milliseconds UTC
+	UpdatedAt         int64   `gorm:"column:updated_at"` // Unix milliseconds UTC
+	FilledAt          int64   `gorm:"column:filled_at"`  // Unix milliseconds UTC
+}
+
+func (v1TraderOrder) TableName() string { return "trader_orders" }
+
+type v1TraderFill struct {
+	ID              int64   `gorm:"primaryKey;autoIncrement"`
+	TraderID        string  `gorm:"column:trader_id;not null;index:idx_fills_trader_id"`
+	ExchangeID      string  `gorm:"column:exchange_id;not null;default:''"`
+	ExchangeType    string  `gorm:"column:exchange_type;not null;default:''"`
+	OrderID         int64   `gorm:"column:order_id;not null;index:idx_fills_order_id"`
+	ExchangeOrderID string  `gorm:"column:exchange_order_id;not null"`
+	ExchangeTradeID string  `gorm:"column:exchange_trade_id;not null;uniqueIndex:idx_fills_exchange_unique,priority:2"`
+	Symbol          string  `gorm:"column:symbol;not null"`
+	Side            string  `gorm:"column:side;not null"`
+	Price           float64 `gorm:"column:price;not null"`
+	Quantity        float64 `gorm:"column:quantity;not null"`
+	QuoteQuantity   float64 `gorm:"column:quote_quantity;not null"`
+	Commission      float64 `gorm:"column:commission;not null"`
+	CommissionAsset string  `gorm:"column:commission_asset;not null"`
+	RealizedPnL     float64 `gorm:"column:realized_pnl;default:0"`
+	IsMaker         bool    `gorm:"column:is_maker;default:false"`
+	CreatedAt       int64   `gorm:"column:created_at"` // Unix milliseconds UTC
+}
+
+func (v1TraderFill) TableName() string { return "trader_fills" }
+
+type v1TPSLRecord struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement"`
+	TraderID       string     `gorm:"column:trader_id;not null;index:idx_tpsl_trader"`
+	PositionID     int64      `gorm:"column:position_id;not null;index:idx_tpsl_position"`
+	Symbol         string     `gorm:"column:symbol;not null;index:idx_tpsl_symbol"`
+	Side           string     `gorm:"column:side;not null"` // LONG or SHORT
+	CurrentTP      float64    `gorm:"column:current_tp"`
+	CurrentSL      float64    `gorm:"column:current_sl"`
+	OriginalTP     float64    `gorm:"column:original_tp"`
+	OriginalSL     float64    `gorm:"column:original_sl"`
+	EntryPrice     float64    `gorm:"column:entry_price"`
+	EntryQuantity  float64    `gorm:"column:entry_quantity"`
+	TPTriggered    bool       `gorm:"column:tp_triggered;default:false"`
+	SLTriggered    bool       `gorm:"column:sl_triggered;default:false"`
+	TPTriggeredAt  *time.Time `gorm:"column:tp_triggered_at"`
+	SLTriggeredAt  *time.Time `gorm:"column:sl_triggered_at"`
+	ModifiedCount  int        `gorm:"column:modified_count;default:0"`
+	LastModifiedAt *time.Time `gorm:"column:last_modified_at"`
+	Status         string     `gorm:"column:status;default:ACTIVE"` // ACTIVE, TRIGGERED, CLOSED
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at"`
+}
+
+func (v1TPSLRecord) TableName() string { return "tpsl_records" }
+
+type v1AnalysisRecord struct {
+	ID              string `gorm:"primaryKey"`
+	TraderID        string
+	Symbol          string
+	TargetPrice     float64
+	SupportLevels   string `gorm:"type:json"`
+	ResistanceLevel float64
+	Confidence      float64 // 0.0 - 1.0
+	AnalysisReason  string  // "RSI超卖，资金流入..."
+	AnalysisPrompt  string  // 原始提示词
+	AIResponse      string  // AI 原始响应
+	AnalysisTime    time.Time
+	CreatedAt       time.Time
+	ExpiresAt       time.Time // 分析有效期（4小时后过期）
+	Status          string    // ACTIVE / EXPIRED / REPLACED
+}
+
+func (v1AnalysisRecord) TableName() string { return "ai_analysis" }
+
+type v1PendingOrder struct {
+	ID               string `gorm:"primaryKey"`
+	TraderID         string
+	Symbol           string
+	AnalysisID       string  // 关联的分析记录
+	TargetPrice      float64 // AI 目标价
+	TriggerPrice     float64 // 实际触发价格
+	PositionSize     float64 // USDT 单位
+	Leverage         int
+	StopLoss         float64 // SL 价格
+	TakeProfit       float64 // TP 价格
+	Confidence       float64 // 置信度
+	Status           string  // PENDING / TRIGGERED / FILLED / CANCELLED / EXPIRED
+	TriggeredPrice   float64 // 实际触发的价格
+	TriggeredAt      *time.Time
+	FilledAt         *time.Time
+	ExecutedAt       *time.Time // 执行完成时间
+	IsExecuting      bool       `gorm:"column:is_executing;default:false"`  // 是否正在执行（防止重复）
+	ExecutionVersion int64      `gorm:"column:execution_version;default:0"` // 执行版本（原子操作）
+	CreatedAt        time.Time
+	ExpiresAt        time.Time // 订单有效期（1天）
+	CancelReason     string    // 取消原因
+	OrderID          int64     // 关联的交易所订单 ID
+}
+
+func (v1PendingOrder) TableName() string { return "pending_orders" }
+
+type v1TradeHistoryRecord struct {
+	ID             string `gorm:"primaryKey"`
+	TraderID       string
+	Symbol         string
+	AnalysisID     string // 关联的分析记录
+	PendingOrderID string // 关联的待执行订单
+	EntryPrice     float64
+	ExitPrice      float64
+	Quantity       float64
+	Leverage       int
+	RealizedPnL    float64 // 实际盈亏（USDT）
+	PnL            float64 // 盈亏金额
+	PnLPct         float64 // 盈亏百分比
+	Confidence     float64 // 原始分析的信心度 (0-1)
+	EntryTime      time.Time
+	ExitTime       time.Time
+	HoldDuration   int64 // 持仓时长（分钟）
+	CreatedAt      time.Time
+}
+
+func (v1TradeHistoryRecord) TableName() string { return "trade_history" }
+
+type v1ReflectionRecord struct {
+	ID                 string    `gorm:"primaryKey;column:id"`
+	TraderID           string    `gorm:"index:idx_trader_time;column:trader_id"`
+	ReflectionTime     time.Time `gorm:"index:idx_trader_time;column:reflection_time"`
+	PeriodStartTime    time.Time
+	PeriodEndTime      time.Time
+	TotalTrades        int
+	SuccessfulTrades   int
+	FailedTrades       int
+	SuccessRate        float64
+	AveragePnL         float64
+	MaxProfit          float64
+	MaxLoss            float64
+	TotalPnL           float64
+	PnLPercentage      float64
+	SharpeRatio        float64
+	MaxDrawdown        float64
+	WinLossRatio       float64
+	ConfidenceAccuracy string `gorm:"type:json;column:confidence_accuracy"` // 信心度准确率
+	SymbolPerformance  string `gorm:"type:json;column:symbol_performance"`  // 按交易对的表现
+	AIReflection       string `gorm:"type:text;column:ai_reflection"`       // AI 反思内容
+	Recommendations    string `gorm:"type:json;column:recommendations"`     // 改进建议
+	TradeSystemAdvice  string `gorm:"type:json;column:trade_system_advice"` // 交易系统建议
+	AILearningAdvice   string `gorm:"type:json;column:ai_learning_advice"`  // AI 学习建议
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+func (v1ReflectionRecord) TableName() string { return "reflections" }
+
+type v1SystemAdjustment struct {
+	ID               string `gorm:"primaryKey;column:id"`
+	TraderID         string `gorm:"index;column:trader_id"`
+	ReflectionID     string `gorm:"index;column:reflection_id"` // 关联的反思记录
+	AdjustmentTime   time.Time
+	ConfidenceLevel  float64    // 信心度阈值
+	BTCETHLeverage   int        // BTC/ETH 杠杆
+	AltcoinLeverage  int        // 山寨币杠杆
+	MaxPositionSize  float64    // 单笔最大仓位
+	MaxDailyLoss     float64    // 日最大亏损
+	StopLossPct      float64    // 止损比例
+	TakeProfitPct    float64    // 止盈比例
+	AdjustmentReason string     `gorm:"type:text;column:adjustment_reason"` // 调整原因
+	AppliedAt        *time.Time // 应用时间
+	Status           string     // "PENDING", "APPLIED", "REVERTED"
+	CreatedAt        time.Time
+}
+
+func (v1SystemAdjustment) TableName() string { return "system_adjustments" }
+
+type v1AILearningMemory struct {
+	ID              string `gorm:"primaryKey;column:id"`
+	TraderID        string `gorm:"index;column:trader_id"`
+	ReflectionID    string `gorm:"index;column:reflection_id"`
+	CreatedAt       time.Time
+	ExpiresAt       time.Time  // 记忆过期时间
+	MemoryType      string     // "bias", "pattern", "lesson", "warning"
+	Symbol          string     // 相关交易对（可选）
+	Content         string     `gorm:"type:text;column:content"` // 记忆内容
+	Confidence      float64    // 这个记忆的可信度
+	UsageCount      int        // 被使用次数
+	LastUsedAt      *time.Time // 最后使用时间
+	PromptInjection string     `gorm:"type:text;column:prompt_injection"` // AI prompt 注入内容
+	UpdatedAt       time.Time
+}
+
+func (v1AILearningMemory) TableName() string { return "ai_learning_memory" }
+
+type v1AdaptiveStopLossRecord struct {
+	ID              string `gorm:"primaryKey"`
+	TraderID        string
+	Symbol          string
+	PositionID      string  // 关联的持仓ID
+	EntryPrice      float64 // 入场价格
+	CurrentStopLoss float64 // 当前止损价格
+	InitialStopLoss float64 // 初始止损价格
+	TakeProfit      float64 // 止盈价格（保持不变）
+	CurrentPrice    float64 // 当前价格
+	IsInProfit      bool    // 是否盈利
+	ProfitDistance  float64 // 盈利距离
+	TimeProgression float64 // 时间进度 (0-1)
+	ElapsedSeconds  int     // 已过秒数
+	Status          string  // ACTIVE / CLOSED
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (v1AdaptiveStopLossRecord) TableName() string { return "adaptive_stoploss_records" }
+
+type v1DebateSession struct {
+	ID              string    `gorm:"column:id;primaryKey"`
+	UserID          string    `gorm:"column:user_id;not null;index"`
+	Name            string    `gorm:"column:name;not null"`
+	StrategyID      string    `gorm:"column:strategy_id;not null"`
+	Status          string    `gorm:"column:status;not null;default:pending;index"`
+	Symbol          string    `gorm:"column:symbol;not null"`
+	MaxRounds       int       `gorm:"column:max_rounds;default:3"`
+	CurrentRound    int       `gorm:"column:current_round;default:0"`
+	IntervalMinutes int       `gorm:"column:interval_minutes;default:5"`
+	PromptVariant   string    `gorm:"column:prompt_variant;default:balanced"`
+	FinalDecision   string    `gorm:"column:final_decision"` // JSON string
+	AutoExecute     bool      `gorm:"column:auto_execute;default:false"`
+	TraderID        string    `gorm:"column:trader_id"`
+	EnableOIRanking bool      `gorm:"column:enable_oi_ranking;default:false"`
+	OIRankingLimit  int       `gorm:"column:oi_ranking_limit;default:10"`
+	OIDuration      string    `gorm:"column:oi_duration;default:1h"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (v1DebateSession) TableName() string { return "debate_sessions" }
+
+type v1DebateParticipant struct {
+	ID          string    `gorm:"column:id;primaryKey"`
+	SessionID   string    `gorm:"column:session_id;not null;index"`
+	AIModelID   string    `gorm:"column:ai_model_id;not null"`
+	AIModelName string    `gorm:"column:ai_model_name;not null"`
+	Provider    string    `gorm:"column:provider;not null"`
+	Personality string    `gorm:"column:personality;not null"`
+	Color       string    `gorm:"column:color;not null"`
+	SpeakOrder  int       `gorm:"column:speak_order;default:0"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v1DebateParticipant) TableName() string { return "debate_participants" }
+
+type v1DebateMessage struct {
+	ID          string    `gorm:"column:id;primaryKey"`
+	SessionID   string    `gorm:"column:session_id;not null;index"`
+	Round       int       `gorm:"column:round;not null"`
+	AIModelID   string    `gorm:"column:ai_model_id;not null"`
+	AIModelName string    `gorm:"column:ai_model_name;not null"`
+	Provider    string    `gorm:"column:provider;not null"`
+	Personality string    `gorm:"column:personality;not null"`
+	MessageType string    `gorm:"column:message_type;not null"` // analysis/rebuttal/final/vote
+	Content     string    `gorm:"column:content;not null"`
+	DecisionRaw string    `gorm:"column:decision"` // JSON string in DB
+	Confidence  int       `gorm:"column:confidence;default:0"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v1DebateMessage) TableName() string { return "debate_messages" }
+
+type v1DebateVote struct {
+	ID            string    `gorm:"column:id;primaryKey"`
+	SessionID     string    `gorm:"column:session_id;not null;index"`
+	AIModelID     string    `gorm:"column:ai_model_id;not null"`
+	AIModelName   string    `gorm:"column:ai_model_name;not null"`
+	Action        string    `gorm:"column:action;not null"` // Primary action (backward compat)
+	Symbol        string    `gorm:"column:symbol;not null"` // Primary symbol (backward compat)
+	Confidence    int       `gorm:"column:confidence;default:0"`
+	Leverage      int       `gorm:"column:leverage;default:5"`
+	PositionPct   float64   `gorm:"column:position_pct;default:0.2"`
+	StopLossPct   float64   `gorm:"column:stop_loss_pct;default:0.03"`
+	TakeProfitPct float64   `gorm:"column:take_profit_pct;default:0.06"`
+	Reasoning     string    `gorm:"column:reasoning"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v1DebateVote) TableName() string { return "debate_votes" }
+
+// ============================================================================
+// v12 trader_debate_panels
+// ============================================================================
+
+type v12Trader struct {
+	DebatePanelID string `gorm:"column:debate_panel_id;default:''"`
+}
+
+func (v12Trader) TableName() string { return "traders" }
+
+type v12DecisionRecord struct {
+	DebateSessionID string `gorm:"column:debate_session_id;default:''"`
+}
+
+func (v12DecisionRecord) TableName() string { return "decision_records" }
+
+// ============================================================================
+// v13 debate_custom_personalities_and_moderator
+// ============================================================================
+
+type v13CustomPersonality struct {
+	ID          string    `gorm:"column:id;primaryKey"`
+	UserID      string    `gorm:"column:user_id;not null;index"`
+	Name        string    `gorm:"column:name;not null"`
+	Description string    `gorm:"column:description"`
+	Prompt      string    `gorm:"column:prompt;type:text;not null"`
+	RiskBias    string    `gorm:"column:risk_bias;default:neutral"`
+	Color       string    `gorm:"column:color;default:'#6B7280'"`
+	Emoji       string    `gorm:"column:emoji;default:'🎭'"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (v13CustomPersonality) TableName() string { return "debate_custom_personalities" }
+
+type v13DebateSession struct {
+	ConsensusMethod    string `gorm:"column:consensus_method;default:vote"`
+	ModeratorAIModelID string `gorm:"column:moderator_ai_model_id;default:''"`
+}
+
+func (v13DebateSession) TableName() string { return "debate_sessions" }
+
+type v13DebateParticipant struct {
+	PersonalityName string `gorm:"column:personality_name;default:''"`
+	CustomPrompt    string `gorm:"column:custom_prompt;type:text"`
+	RiskBias        string `gorm:"column:risk_bias;default:''"`
+	Emoji           string `gorm:"column:emoji;default:''"`
+}
+
+func (v13DebateParticipant) TableName() string { return "debate_participants" }
+
+// ============================================================================
+// v14 debate_outcome_scorecards
+// ============================================================================
+
+type v14DebateOutcome struct {
+	ID              string    `gorm:"column:id;primaryKey"`
+	SessionID       string    `gorm:"column:session_id;not null;index"`
+	UserID          string    `gorm:"column:user_id;not null;index"`
+	AIModelID       string    `gorm:"column:ai_model_id;not null;index"`
+	AIModelName     string    `gorm:"column:ai_model_name;not null"`
+	Personality     string    `gorm:"column:personality;not null;index"`
+	PersonalityName string    `gorm:"column:personality_name;default:''"`
+	Symbol          string    `gorm:"column:symbol;not null"`
+	Action          string    `gorm:"column:action;not null"`
+	Confidence      int       `gorm:"column:confidence;default:0"`
+	EntryPrice      float64   `gorm:"column:entry_price;default:0"`
+	DueAt           time.Time `gorm:"column:due_at;index"`
+	Status          string    `gorm:"column:status;default:pending;index"`
+	ExitPrice       float64   `gorm:"column:exit_price;default:0"`
+	ReturnPct       float64   `gorm:"column:return_pct;default:0"`
+	Correct         bool      `gorm:"column:correct;default:false"`
+	ScoredAt        time.Time `gorm:"column:scored_at"`
+	TraderID        string    `gorm:"column:trader_id;default:''"`
+	ExecutedAction  string    `gorm:"column:executed_action;default:''"`
+	ExecutedAt      time.Time `gorm:"column:executed_at"`
+	PnLSettled      bool      `gorm:"column:pnl_settled;default:false"`
+	PnLContribution float64   `gorm:"column:pnl_contribution;default:0"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v14DebateOutcome) TableName() string { return "debate_outcomes" }
+
+type v14DebateSession struct {
+	WeightByAccuracy bool `gorm:"column:weight_by_accuracy;default:false"`
+}
+
+func (v14DebateSession) TableName() string { return "debate_sessions" }
+
+// ============================================================================
+// v15 prompt_template_library
+// ============================================================================
+
+type v15PromptTemplate struct {
+	ID          string    `gorm:"column:id;primaryKey"`
+	UserID      string    `gorm:"column:user_id;not null;index"`
+	Name        string    `gorm:"column:name;not null"`
+	Description string    `gorm:"column:description;default:''"`
+	System      string    `gorm:"column:system_template;type:text"`
+	User        string    `gorm:"column:user_template;type:text"`
+	Version     int       `gorm:"column:version;not null;default:1"`
+	IsPublic    bool      `gorm:"column:is_public;default:false;index"`
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime"`
+}
+
+func (v15PromptTemplate) TableName() string { return "prompt_templates" }
+
+type v15PromptTemplateVersion struct {
+	ID         string    `gorm:"column:id;primaryKey"`
+	TemplateID string    `gorm:"column:template_id;not null;uniqueIndex:idx_prompt_template_version"`
+	Version    int       `gorm:"column:version;not null;uniqueIndex:idx_prompt_template_version"`
+	System     string    `gorm:"column:system_template;type:text"`
+	User       string    `gorm:"column:user_template;type:text"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v15PromptTemplateVersion) TableName() string { return "prompt_template_versions" }
+
+// ============================================================================
+// v16 prompt_experiments
+// ============================================================================
+
+type v16Experiment struct {
+	ID            string     `gorm:"column:id;primaryKey"`
+	UserID        string     `gorm:"column:user_id;not null;index"`
+	TraderID      string     `gorm:"column:trader_id;not null;index"`
+	Name          string     `gorm:"column:name;not null"`
+	Description   string     `gorm:"column:description;default:''"`
+	Status        string     `gorm:"column:status;default:running;index"`
+	FeeBps        float64    `gorm:"column:fee_bps;default:5"`
+	InitialEquity float64    `gorm:"column:initial_equity;default:0"`
+	Cycles        int        `gorm:"column:cycles;default:0"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoUpdateTime"`
+	StoppedAt     *time.Time `gorm:"column:stopped_at"`
+}
+
+func (v16Experiment) TableName() string { return "experiments" }
+
+type v16ExperimentVariant struct {
+	ID            string    `gorm:"column:id;primaryKey"`
+	ExperimentID  string    `gorm:"column:experiment_id;not null;index"`
+	Name          string    `gorm:"column:name;not null"`
+	IsLive        bool      `gorm:"column:is_live;default:false"`
+	StrategyID    string    `gorm:"column:strategy_id;default:''"`
+	PromptVariant string    `gorm:"column:prompt_variant;default:''"`
+	Config        string    `gorm:"column:config;type:text"`
+	Book          string    `gorm:"column:book;type:text"`
+	Equity        float64   `gorm:"column:equity;default:0"`
+	SortOrder     int       `gorm:"column:sort_order;default:0"`
+	CreatedAt     time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v16ExperimentVariant) TableName() string { return "experiment_variants" }
+
+type v16ExperimentDecision struct {
+	ID              int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	ExperimentID    string    `gorm:"column:experiment_id;not null;index:idx_experiment_decision_cycle"`
+	VariantID       string    `gorm:"column:variant_id;not null;index"`
+	Cycle           int       `gorm:"column:cycle;not null;index:idx_experiment_decision_cycle"`
+	Symbol          string    `gorm:"column:symbol;not null"`
+	Action          string    `gorm:"column:action;not null"`
+	Confidence      int       `gorm:"column:confidence;default:0"`
+	Leverage        int       `gorm:"column:leverage;default:0"`
+	PositionSizeUSD float64   `gorm:"column:position_size_usd;default:0"`
+	StopLoss        float64   `gorm:"column:stop_loss;default:0"`
+	TakeProfit      float64   `gorm:"column:take_profit;default:0"`
+	Price           float64   `gorm:"column:price;default:0"`
+	Reasoning       string    `gorm:"column:reasoning;type:text"`
+	CreatedAt       time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (v16ExperimentDecision) TableName() string { return "experiment_decisions" }
+
+type v16ExperimentEquityPoint struct {
+	ID           int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	ExperimentID string    `gorm:"column:experiment_id;not null;index"`
+	VariantID    string    `gorm:"column:variant_id;not null;index"`
+	Cycle        int       `gorm:"column:cycle;not null"`
+	Equity       float64   `gorm:"column:equity;not null"`
+	Failed       bool      `gorm:"column:failed;default:false"`
+	Timestamp    time.Time `gorm:"column:timestamp;not null"`
+}
+
+func (v16ExperimentEquityPoint) TableName() string { return "experiment_equity_points" }
+
+// ============================================================================
+// v17 ai_model_failover
+// ============================================================================
+
+type v17Trader struct {
+	FallbackAIModelIDs string `gorm:"column:fallback_ai_model_ids;default:''"`
+}
+
+func (v17Trader) TableName() string { return "traders" }
+
+type v17DecisionRecord struct {
+	AIModel string `gorm:"column:ai_model;default:''"`
+}
+
+func (v17DecisionRecord) TableName() string { return "decision_records" }
+
+// ============================================================================
+// v18 debate_vote_participants
+// ============================================================================
+
+type v18DebateVote struct {
+	ParticipantID string `gorm:"column:participant_id;default:''"`
+	Personality   string `gorm:"column:personality;default:''"`
+}
+
+func (v18DebateVote) TableName() string { return "debate_votes" }
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func openMigrationTestStore(t *testing.T) *Store {
+	t.Helper()
+	gdb, err := InitGorm(filepath.Join(t.TempDir(), "nofx.db"))
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	s, err := NewFromGorm(gdb)
+	if err != nil {
+		t.Fatalf("create store: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// migrationColumns columns each migration adds, checked right after the migration is applied
+var migrationColumns = map[int]map[string][]string{
+	1: {
+		"users":            {"id", "email", "password_hash"},
+		"traders":          {"id", "ai_model_id", "exchange_id", "strategy_id"},
+		"debate_votes":     {"session_id", "ai_model_id", "action"},
+		"system_config":    {"key", "value"},
+		"backtest_runs":    {"run_id", "config_json"},
+		"trader_orders":    {"exchange_order_id", "reduce_only"},
+		"audit_logs":       {"id"},
+		"decision_records": {"trader_id", "cycle_number"},
+	},
+	12: {
+		"traders":          {"debate_panel_id"},
+		"decision_records": {"debate_session_id"},
+	},
+	13: {
+		"debate_custom_personalities": {"id", "user_id", "prompt", "risk_bias"},
+		"debate_sessions":             {"consensus_method", "moderator_ai_model_id"},
+		"debate_participants":         {"personality_name", "custom_prompt", "risk_bias", "emoji"},
+	},
+	14: {
+		"debate_outcomes": {"session_id", "ai_model_id", "due_at", "pnl_contribution"},
+		"debate_sessions": {"weight_by_accuracy"},
+	},
+	15: {
+		"prompt_templates":         {"system_template", "user_template", "version"},
+		"prompt_template_versions": {"template_id", "version"},
+	},
+	16: {
+		"experiments":              {"trader_id", "status"},
+		"experiment_variants":      {"experiment_id", "config", "book"},
+		"experiment_decisions":     {"variant_id", "cycle"},
+		"experiment_equity_points": {"variant_id", "equity"},
+	},
+	17: {
+		"traders":          {"fallback_ai_model_ids"},
+		"decision_records": {"ai_model"},
+	},
+	18: {
+		"debate_votes": {"participant_id", "personality"},
+	},
+}
+
+func TestMigrateStepByStep(t *testing.T) {
+	s := openMigrationTestStore(t)
+	m := s.gdb.Migrator()
+
+	for _, mig := range Migrations() {
+		for table := range migrationColumns[mig.Version] {
+			if mig.Version > 1 && !m.HasTable(table) {
+				continue
+			}
+			for _, col := range migrationColumns[mig.Version][table] {
+				if mig.Version > 1 && m.HasColumn(table, col) {
+					t.Fatalf("column %s.%s exists before migration %d", table, col, mig.Version)
+				}
+			}
+		}
+
+		steps, err := s.Migrate(MigrateOptions{Target: mig.Version})
+		if err != nil {
+			t.Fatalf("migration %d %s: %v", mig.Version, mig.Name, err)
+		}
+		if len(steps) != 1 || steps[0].Version != mig.Version {
+			t.Fatalf("migration %d: got steps %+v", mig.Version, steps)
+		}
+		if v, err := s.SchemaVersion(); err != nil || v != mig.Version {
+			t.Fatalf("schema version = %d (%v), want %d", v, err, mig.Version)
+		}
+
+		for table, cols := range migrationColumns[mig.Version] {
+			if !m.HasTable(table) {
+				t.Fatalf("table %s missing after migration %d", table, mig.Version)
+			}
+			for _, col := range cols {
+				if !m.HasColumn(table, col) {
+					t.Fatalf("column %s.%s missing after migration %d", table, col, mig.Version)
+				}
+			}
+		}
+	}
+
+	// Migrating again is a no-op
+	steps, err := s.Migrate(MigrateOptions{})
+	if err != nil || len(steps) != 0 {
+		t.Fatalf("second migrate: steps %+v, err %v", steps, err)
+	}
+}
+
+// TestMigratedSchemaCoversModels every column of the current models must come from a migration
+func TestMigratedSchemaCoversModels(t *testing.T) {
+	s := openMigrationTestStore(t)
+	if _, err := s.Migrate(MigrateOptions{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	models := []interface{}{
+		&User{}, &AIModel{}, &Exchange{}, &Trader{}, &DecisionRecordDB{},
+		&BacktestRun{}, &BacktestCheckpoint{}, &BacktestEquity{}, &BacktestTrade{}, &BacktestMetrics{}, &BacktestDecision{},
+		&TraderPosition{}, &Strategy{}, &EquitySnapshot{}, &APIToken{}, &Workspace{}, &WorkspaceMember{}, &AuditEntry{},
+		&TraderOrder{}, &TraderFill{}, &TPSLRecord{}, &AnalysisRecord{}, &PendingOrder{}, &TradeHistoryRecord{},
+		&ReflectionRecord{}, &SystemAdjustment{}, &AILearningMemory{}, &AdaptiveStopLossRecord{},
+		&DebateSessionDB{}, &DebateParticipant{}, &DebateMessage{}, &DebateVote{}, &CustomPersonality{}, &DebateOutcome{},
+		&PromptTemplate{}, &PromptTemplateVersion{},
+		&Experiment{}, &ExperimentVariant{}, &ExperimentDecision{}, &ExperimentEquityPoint{},
+	}
+	m := s.gdb.Migrator()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: s.gdb}
+		if err := stmt.Parse(model); err != nil {
+			t.Fatalf("parse %T: %v", model, err)
+		}
+		for _, col := range stmt.Schema.DBNames {
+			if !m.HasColumn(model, col) {
+				t.Errorf("column %s.%s of %T is not created by any migration", stmt.Schema.Table, col, model)
+			}
+		}
+	}
+}
+
+func TestRollbackReversibleMigrations(t *testing.T) {
+	s := openMigrationTestStore(t)
+	if _, err := s.Migrate(MigrateOptions{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	if _, err := s.Rollback(MigrateOptions{Target: 11}); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if v, _ := s.SchemaVersion(); v != 11 {
+		t.Fatalf("schema version after rollback = %d, want 11", v)
+	}
+	m := s.gdb.Migrator()
+	if m.HasColumn("traders", "debate_panel_id") || m.HasTable("debate_outcomes") {
+		t.Fatal("rollback left columns or tables of reverted migrations")
+	}
+
+	if _, err := s.Migrate(MigrateOptions{}); err != nil {
+		t.Fatalf("migrate after rollback: %v", err)
+	}
+	if v, _ := s.SchemaVersion(); v != LatestMigrationVersion() {
+		t.Fatalf("schema version = %d, want %d", v, LatestMigrationVersion())
+	}
+}
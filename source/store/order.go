@@ -84,70 +84,6 @@ func NewOrderStore(db *gorm.DB) *OrderStore {
 	return &OrderStore{db: db}
 }
 
-// InitTables initializes order tables
-func (s *OrderStore) InitTables() error {
-	// For PostgreSQL, check if tables exist to avoid AutoMigrate index conflicts
-	if s.db.Dialector.Name() == "postgres" {
-		var ordersExist, fillsExist int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'trader_orders'`).Scan(&ordersExist)
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'trader_fills'`).Scan(&fillsExist)
-
-		if ordersExist > 0 && fillsExist > 0 {
-			// Tables exist - fix INTEGER columns to BOOLEAN (from earlier migrations)
-			// Need to: drop default -> change type -> set new default
-			boolColumns := []struct{ table, col string }{
-				{"trader_orders", "reduce_only"},
-				{"trader_orders", "close_position"},
-				{"trader_orders", "price_protect"},
-				{"trader_fills", "is_maker"},
-			}
-			for _, c := range boolColumns {
-				s.db.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP DEFAULT", c.table, c.col))
-				s.db.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE BOOLEAN USING %s::int::boolean", c.table, c.col, c.col))
-				s.db.Exec(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT false", c.table, c.col))
-			}
-
-			// Migrate timestamp columns to bigint (Unix milliseconds UTC)
-			// Check if column is still timestamp type before migrating
-			timestampColumns := []struct{ table, col string }{
-				{"trader_orders", "created_at"},
-				{"trader_orders", "updated_at"},
-				{"trader_orders", "filled_at"},
-				{"trader_fills", "created_at"},
-			}
-			for _, c := range timestampColumns {
-				var dataType string
-				s.db.Raw(`SELECT data_type FROM information_schema.columns WHERE table_name = ? AND column_name = ?`, c.table, c.col).Scan(&dataType)
-				if dataType == "timestamp with time zone" || dataType == "timestamp without time zone" {
-					// Convert timestamp to Unix milliseconds (bigint)
-					s.db.Exec(fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s TYPE BIGINT USING EXTRACT(EPOCH FROM %s) * 1000`, c.table, c.col, c.col))
-				}
-			}
-
-			// Ensure indexes exist
-			s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_exchange_unique ON trader_orders(exchange_id, exchange_order_id)`)
-			s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_fills_exchange_unique ON trader_fills(exchange_id, exchange_trade_id)`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_trader_id ON trader_orders(trader_id)`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_symbol ON trader_orders(symbol)`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_orders_status ON trader_orders(status)`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_fills_trader_id ON trader_fills(trader_id)`)
-			s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_fills_order_id ON trader_fills(order_id)`)
-			return nil
-		}
-	}
-
-	if err := s.db.AutoMigrate(&TraderOrder{}, &TraderFill{}); err != nil {
-		return fmt.Errorf("failed to migrate order tables: %w", err)
-	}
-
-	// Create unique composite index for exchange_id + exchange_order_id
-	s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_exchange_unique ON trader_orders(exchange_id, exchange_order_id)`)
-	// Create unique composite index for exchange_id + exchange_trade_id
-	s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_fills_exchange_unique ON trader_fills(exchange_id, exchange_trade_id)`)
-
-	return nil
-}
-
 // CreateOrder creates order record
 func (s *OrderStore) CreateOrder(order *TraderOrder) error {
 	// Check if order already exists
@@ -224,6 +160,20 @@ func (s *OrderStore) GetOrderByExchangeID(exchangeID, exchangeOrderID string) (*
 	return &order, nil
 }
 
+// GetOrdersByExchangeOrderID gets the records of an exchange order: the order itself when it was recorded
+// under its order ID, or the per-trade records created by order sync, found through their fills
+func (s *OrderStore) GetOrdersByExchangeOrderID(exchangeID, exchangeOrderID string) ([]*TraderOrder, error) {
+	var orders []*TraderOrder
+	fillOrderIDs := s.db.Model(&TraderFill{}).Select("order_id").
+		Where("exchange_id = ? AND exchange_order_id = ?", exchangeID, exchangeOrderID)
+	err := s.db.Where("exchange_id = ? AND (exchange_order_id = ? OR id IN (?))", exchangeID, exchangeOrderID, fillOrderIDs).
+		Find(&orders).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	return orders, nil
+}
+
 // GetTraderOrders gets trader's order list
 func (s *OrderStore) GetTraderOrders(traderID string, limit int) ([]*TraderOrder, error) {
 	var orders []*TraderOrder
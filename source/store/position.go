@@ -72,49 +72,12 @@ func (s *PositionStore) isPostgres() bool {
 	return s.db.Dialector.Name() == "postgres"
 }
 
-// InitTables initializes position tables
+// InitTables creates the position table outside the migration runner (standalone stores, tests)
 func (s *PositionStore) InitTables() error {
-	// For PostgreSQL with existing table, skip AutoMigrate
-	if s.isPostgres() {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'trader_positions'`).Scan(&tableExists)
-		if tableExists > 0 {
-			// Migrate timestamp columns to bigint (Unix milliseconds UTC)
-			// Check if column is still timestamp type before migrating
-			timestampColumns := []string{"entry_time", "exit_time", "created_at", "updated_at"}
-			for _, col := range timestampColumns {
-				var dataType string
-				s.db.Raw(`SELECT data_type FROM information_schema.columns WHERE table_name = 'trader_positions' AND column_name = ?`, col).Scan(&dataType)
-				if dataType == "timestamp with time zone" || dataType == "timestamp without time zone" {
-					// Convert timestamp to Unix milliseconds (bigint)
-					s.db.Exec(fmt.Sprintf(`ALTER TABLE trader_positions ALTER COLUMN %s TYPE BIGINT USING EXTRACT(EPOCH FROM %s) * 1000`, col, col))
-				}
-			}
-
-			// Just ensure index exists
-			s.db.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_positions_exchange_pos_unique ON trader_positions(exchange_id, exchange_position_id) WHERE exchange_position_id != ''`)
-			return nil
-		}
-	}
-
-	if err := s.db.AutoMigrate(&TraderPosition{}); err != nil {
+	if err := ensureTables(s.db, &TraderPosition{}); err != nil {
 		return fmt.Errorf("failed to migrate trader_positions table: %w", err)
 	}
-
-	// Create unique partial index for exchange position deduplication
-	var indexSQL string
-	if s.isPostgres() {
-		indexSQL = `CREATE UNIQUE INDEX IF NOT EXISTS idx_positions_exchange_pos_unique ON trader_positions(exchange_id, exchange_position_id) WHERE exchange_position_id != ''`
-	} else {
-		indexSQL = `CREATE UNIQUE INDEX IF NOT EXISTS idx_positions_exchange_pos_unique ON trader_positions(exchange_id, exchange_position_id) WHERE exchange_position_id != ''`
-	}
-	if err := s.db.Exec(indexSQL).Error; err != nil {
-		if !strings.Contains(err.Error(), "already exists") && !strings.Contains(err.Error(), "UNIQUE constraint failed") {
-			return fmt.Errorf("failed to create unique index: %w", err)
-		}
-	}
-
-	return nil
+	return createPositionUniqueIndex(s.db)
 }
 
 // Create creates position record
@@ -453,6 +416,25 @@ func (s *PositionStore) GetRecentTrades(traderID string, limit int) ([]RecentTra
 		return nil, fmt.Errorf("failed to query recent trades: %w", err)
 	}
 
+	return toRecentTrades(positions), nil
+}
+
+// GetRecentTradesBySymbol gets recent closed trades of one symbol
+func (s *PositionStore) GetRecentTradesBySymbol(traderID, symbol string, limit int) ([]RecentTrade, error) {
+	var positions []TraderPosition
+	err := s.db.Where("trader_id = ? AND symbol = ? AND status = ?", traderID, symbol, "CLOSED").
+		Order("exit_time DESC").
+		Limit(limit).
+		Find(&positions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recent trades: %w", err)
+	}
+
+	return toRecentTrades(positions), nil
+}
+
+// toRecentTrades converts closed positions to trade records
+func toRecentTrades(positions []TraderPosition) []RecentTrade {
 	var trades []RecentTrade
 	for _, pos := range positions {
 		t := RecentTrade{
@@ -481,7 +463,7 @@ func (s *PositionStore) GetRecentTrades(traderID string, limit int) ([]RecentTra
 		trades = append(trades, t)
 	}
 
-	return trades, nil
+	return trades
 }
 
 // formatDuration formats a duration
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PromptTemplate a user-defined prompt template: Go templates rendered over the strategy engine's prompt
+// sections. Every content change creates a new version; older versions stay available.
+type PromptTemplate struct {
+	ID          string    `gorm:"column:id;primaryKey" json:"id"`
+	UserID      string    `gorm:"column:user_id;not null;index" json:"user_id"`
+	Name        string    `gorm:"column:name;not null" json:"name"`
+	Description string    `gorm:"column:description;default:''" json:"description"`
+	System      string    `gorm:"column:system_template;type:text" json:"system"` // System prompt template, empty keeps the built-in system prompt
+	User        string    `gorm:"column:user_template;type:text" json:"user"`     // User prompt template, empty keeps the built-in user prompt
+	Version     int       `gorm:"column:version;not null;default:1" json:"version"`
+	IsPublic    bool      `gorm:"column:is_public;default:false;index" json:"is_public"` // whether visible in strategy market
+	CreatedAt   time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
+}
+
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
+
+// PromptTemplateVersion content of one version of a prompt template
+type PromptTemplateVersion struct {
+	ID         string    `gorm:"column:id;primaryKey" json:"id"`
+	TemplateID string    `gorm:"column:template_id;not null;uniqueIndex:idx_prompt_template_version" json:"template_id"`
+	Version    int       `gorm:"column:version;not null;uniqueIndex:idx_prompt_template_version" json:"version"`
+	System     string    `gorm:"column:system_template;type:text" json:"system"`
+	User       string    `gorm:"column:user_template;type:text" json:"user"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
+}
+
+func (PromptTemplateVersion) TableName() string {
+	return "prompt_template_versions"
+}
+
+// PromptTemplateRef a prompt template selected by a strategy or backtest. The content of the selected
+// version is snapshotted so the strategy keeps working (and stays shareable) when the template changes.
+type PromptTemplateRef struct {
+	ID      string `json:"id"`
+	Name    string `json:"name,omitempty"`
+	Version int    `json:"version,omitempty"` // 0 selects the latest version
+	System  string `json:"system,omitempty"`
+	User    string `json:"user,omitempty"`
+}
+
+// PromptTemplateStore prompt template storage
+type PromptTemplateStore struct {
+	db *gorm.DB
+}
+
+// NewPromptTemplateStore creates prompt template storage
+func NewPromptTemplateStore(db *gorm.DB) *PromptTemplateStore {
+	return &PromptTemplateStore{db: db}
+}
+
+// Create creates a template as version 1
+func (s *PromptTemplateStore) Create(t *PromptTemplate) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	t.Version = 1
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(t).Error; err != nil {
+			return err
+		}
+		return tx.Create(&PromptTemplateVersion{
+			ID:         uuid.New().String(),
+			TemplateID: t.ID,
+			Version:    1,
+			System:     t.System,
+			User:       t.User,
+		}).Error
+	})
+}
+
+// Update saves a template owned by t.UserID; a changed system or user template becomes a new version
+func (s *PromptTemplateStore) Update(t *PromptTemplate) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var current PromptTemplate
+		if err := tx.Where("id = ? AND user_id = ?", t.ID, t.UserID).First(&current).Error; err != nil {
+			return err
+		}
+		t.Version = current.Version
+		if t.System != current.System || t.User != current.User {
+			t.Version++
+			if err := tx.Create(&PromptTemplateVersion{
+				ID:         uuid.New().String(),
+				TemplateID: t.ID,
+				Version:    t.Version,
+				System:     t.System,
+				User:       t.User,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&PromptTemplate{}).Where("id = ?", t.ID).Updates(map[string]interface{}{
+			"name":            t.Name,
+			"description":     t.Description,
+			"system_template": t.System,
+			"user_template":   t.User,
+			"version":         t.Version,
+			"is_public":       t.IsPublic,
+			"updated_at":      time.Now().UTC(),
+		}).Error
+	})
+}
+
+// Delete deletes a template and its versions; strategies keep their snapshot
+func (s *PromptTemplateStore) Delete(userID, id string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&PromptTemplate{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Where("template_id = ?", id).Delete(&PromptTemplateVersion{}).Error
+	})
+}
+
+// Get gets a template owned by the user or shared to the strategy market
+func (s *PromptTemplateStore) Get(userID, id string) (*PromptTemplate, error) {
+	var t PromptTemplate
+	err := s.db.Where("id = ? AND (user_id = ? OR is_public = ?)", id, userID, true).First(&t).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// List gets the user's templates
+func (s *PromptTemplateStore) List(userID string) ([]*PromptTemplate, error) {
+	var templates []*PromptTemplate
+	err := s.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&templates).Error
+	return templates, err
+}
+
+// ListPublic gets the templates shared to the strategy market
+func (s *PromptTemplateStore) ListPublic() ([]*PromptTemplate, error) {
+	var templates []*PromptTemplate
+	err := s.db.Where("is_public = ?", true).Order("updated_at DESC").Find(&templates).Error
+	return templates, err
+}
+
+// ListVersions gets every version of a template, newest first
+func (s *PromptTemplateStore) ListVersions(templateID string) ([]*PromptTemplateVersion, error) {
+	var versions []*PromptTemplateVersion
+	err := s.db.Where("template_id = ?", templateID).Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+// Resolve fills ref with the name and content of the selected version of a template the user can access
+func (s *PromptTemplateStore) Resolve(userID string, ref *PromptTemplateRef) error {
+	t, err := s.Get(userID, ref.ID)
+	if err != nil {
+		return fmt.Errorf("prompt template %s not found", ref.ID)
+	}
+	ref.Name = t.Name
+	if ref.Version <= 0 || ref.Version == t.Version {
+		ref.Version, ref.System, ref.User = t.Version, t.System, t.User
+		return nil
+	}
+
+	var v PromptTemplateVersion
+	if err := s.db.Where("template_id = ? AND version = ?", t.ID, ref.Version).First(&v).Error; err != nil {
+		return fmt.Errorf("prompt template %s has no version %d", t.Name, ref.Version)
+	}
+	ref.System, ref.User = v.System, v.User
+	return nil
+}
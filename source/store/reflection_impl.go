@@ -1,7 +1,6 @@
 package store
 
 import (
-	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,45 +16,6 @@ func NewReflectionImpl(db *gorm.DB) ReflectionStore {
 	return &ReflectionImpl{db: db}
 }
 
-// InitSchema initializes reflection tables
-func (r *ReflectionImpl) InitSchema() error {
-	tables := []interface{}{
-		&ReflectionRecord{},
-		&SystemAdjustment{},
-		&AILearningMemory{},
-	}
-
-	for _, table := range tables {
-		if err := r.db.AutoMigrate(table); err != nil {
-			return fmt.Errorf("failed to migrate %T: %w", table, err)
-		}
-	}
-
-	// Create indices
-	if err := r.db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_reflection_period 
-		ON reflections(trader_id, reflection_time DESC);
-	`).Error; err != nil {
-		return fmt.Errorf("failed to create reflection indices: %w", err)
-	}
-
-	if err := r.db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_adjustment_status 
-		ON system_adjustments(trader_id, status);
-	`).Error; err != nil {
-		return fmt.Errorf("failed to create adjustment indices: %w", err)
-	}
-
-	if err := r.db.Exec(`
-		CREATE INDEX IF NOT EXISTS idx_memory_active 
-		ON ai_learning_memory(trader_id, expires_at DESC);
-	`).Error; err != nil {
-		return fmt.Errorf("failed to create memory indices: %w", err)
-	}
-
-	return nil
-}
-
 // ============================================================================
 // Reflection Management
 // ============================================================================
@@ -32,6 +32,12 @@ type Store struct {
 	analysis         AnalysisStore
 	reflection       ReflectionStore
 	adaptiveStopLoss AdaptiveStopLossStore
+	apiToken         *APITokenStore
+	workspace        *WorkspaceStore
+	audit            *AuditStore
+	debate           *DebateStore
+	promptTemplate   *PromptTemplateStore
+	experiment       *ExperimentStore
 	mu               sync.RWMutex
 }
 
@@ -101,6 +107,15 @@ func NewWithConfig(cfg DBConfig) (*Store, error) {
 	return s, nil
 }
 
+// OpenWithConfig connects without applying migrations or default data (used by the migrate command)
+func OpenWithConfig(cfg DBConfig) (*Store, error) {
+	gdb, err := InitGormWithConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return NewFromGorm(gdb)
+}
+
 // NewFromGorm creates Store from existing GORM connection
 func NewFromGorm(gdb *gorm.DB) (*Store, error) {
 	sqlDB, err := gdb.DB()
@@ -116,78 +131,10 @@ func NewFromDB(db *sql.DB) *Store {
 	return &Store{db: db}
 }
 
-// initTables initializes all database tables using GORM AutoMigrate
+// initTables brings the schema up to date by applying pending migrations (see migrations.go)
 func (s *Store) initTables() error {
-	// Create system_config table (GORM handles this via raw SQL for simplicity)
-	if err := s.gdb.Exec(`
-		CREATE TABLE IF NOT EXISTS system_config (
-			key TEXT PRIMARY KEY,
-			value TEXT NOT NULL
-		)
-	`).Error; err != nil {
-		return fmt.Errorf("failed to create system_config table: %w", err)
-	}
-
-	// Initialize sub-store tables
-	if err := s.User().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize user tables: %w", err)
-	}
-	if err := s.AIModel().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize AI model tables: %w", err)
-	}
-	if err := s.Exchange().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize exchange tables: %w", err)
-	}
-	if err := s.Trader().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize trader tables: %w", err)
-	}
-	if err := s.Decision().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize decision log tables: %w", err)
-	}
-	if err := s.Backtest().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize backtest tables: %w", err)
-	}
-	if err := s.Position().InitTables(); err != nil {
-		return fmt.Errorf("failed to initialize position tables: %w", err)
-	}
-	if err := s.Strategy().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize strategy tables: %w", err)
-	}
-	if err := s.Equity().initTables(); err != nil {
-		return fmt.Errorf("failed to initialize equity tables: %w", err)
-	}
-	if err := s.Order().InitTables(); err != nil {
-		return fmt.Errorf("failed to initialize order tables: %w", err)
-	}
-	if err := s.TPSL().InitTables(); err != nil {
-		return fmt.Errorf("failed to initialize TP/SL tables: %w", err)
-	}
-
-	// Initialize analysis tables
-	analysisStore := NewAnalysisImpl(s.gdb)
-	if analysisImpl, ok := analysisStore.(*AnalysisImpl); ok {
-		if err := analysisImpl.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize analysis tables: %w", err)
-		}
-	}
-
-	// Initialize reflection tables
-	reflectionStore := NewReflectionImpl(s.gdb)
-	if reflectionImpl, ok := reflectionStore.(*ReflectionImpl); ok {
-		if err := reflectionImpl.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize reflection tables: %w", err)
-		}
-	}
-
-	// Initialize adaptive stop loss tables
-	adaptiveStopLossStore := NewAdaptiveStopLossImpl(s.gdb)
-	if adaptiveStopLossImpl, ok := adaptiveStopLossStore.(*AdaptiveStopLossImpl); ok {
-		if err := adaptiveStopLossImpl.InitSchema(); err != nil {
-			return fmt.Errorf("failed to initialize adaptive stop loss tables: %w", err)
-		}
-	}
-
-	return nil
+	_, err := s.Migrate(MigrateOptions{})
+	return err
 }
 
 // initDefaultData initializes default data
@@ -201,12 +148,6 @@ func (s *Store) initDefaultData() error {
 	if err := s.Strategy().initDefaultData(); err != nil {
 		return err
 	}
-	// Migrate old decision_account_snapshots data to new trader_equity_snapshots table
-	if migrated, err := s.Equity().MigrateFromDecision(); err != nil {
-		logger.Warnf("failed to migrate equity data: %v", err)
-	} else if migrated > 0 {
-		logger.Infof("✅ Migrated %d equity records to new table", migrated)
-	}
 	return nil
 }
 
@@ -350,6 +291,66 @@ func (s *Store) AdaptiveStopLoss() AdaptiveStopLossStore {
 	return s.adaptiveStopLoss
 }
 
+// APIToken gets personal API token storage
+func (s *Store) APIToken() *APITokenStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.apiToken == nil {
+		s.apiToken = NewAPITokenStore(s.gdb)
+	}
+	return s.apiToken
+}
+
+// Workspace gets workspace and membership storage
+func (s *Store) Workspace() *WorkspaceStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.workspace == nil {
+		s.workspace = NewWorkspaceStore(s.gdb)
+	}
+	return s.workspace
+}
+
+// Audit gets append-only audit log storage
+func (s *Store) Audit() *AuditStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.audit == nil {
+		s.audit = NewAuditStore(s.gdb)
+	}
+	return s.audit
+}
+
+// Debate gets debate session storage
+func (s *Store) Debate() *DebateStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.debate == nil {
+		s.debate = NewDebateStore(s.gdb)
+	}
+	return s.debate
+}
+
+// PromptTemplate gets prompt template library storage
+func (s *Store) PromptTemplate() *PromptTemplateStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.promptTemplate == nil {
+		s.promptTemplate = NewPromptTemplateStore(s.gdb)
+	}
+	return s.promptTemplate
+}
+
+// Experiment gets prompt/strategy experiment storage
+func (s *Store) Experiment() *ExperimentStore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.experiment == nil {
+		s.experiment = NewExperimentStore(s.gdb)
+	}
+	return s.experiment
+}
+
 // Close closes database connection
 func (s *Store) Close() error {
 	if s.driver != nil {
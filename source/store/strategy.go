@@ -47,6 +47,58 @@ type StrategyConfig struct {
 	TriggerPriceConfig *TriggerPriceStrategy `json:"trigger_price_config,omitempty"`
 	// editable sections of System Prompt
 	PromptSections PromptSectionsConfig `json:"prompt_sections,omitempty"`
+	// prompt template from the template library, rendered over the prompt sections (nil = built-in prompts)
+	PromptTemplate *PromptTemplateRef `json:"prompt_template,omitempty"`
+	// market events that launch a decision cycle between scheduled scans
+	EventTriggers EventTriggerConfig `json:"event_triggers,omitempty"`
+	// tools the AI can call during a decision to fetch extra data (nil = disabled)
+	ToolCalling *ToolCallingConfig `json:"tool_calling,omitempty"`
+	// how the AI returns its decisions (nil = tagged free text)
+	DecisionOutput *DecisionOutputConfig `json:"decision_output,omitempty"`
+}
+
+// DecisionOutputConfig decision output format and repair
+type DecisionOutputConfig struct {
+	// ask for a JSON object following the decision schema, enforced natively by providers with structured
+	// output (OpenAI, Gemini, Grok, Claude) and parsed from the text for the others
+	Structured bool `json:"structured"`
+	// when decisions fail validation, send the errors back and ask once per cycle for corrected decisions
+	RepairOnInvalid bool `json:"repair_on_invalid"`
+}
+
+// ToolCallingConfig data tools the AI can call during a decision cycle
+type ToolCallingConfig struct {
+	Enabled bool `json:"enabled"`
+	// max tool calls per decision cycle (default: 8)
+	MaxCalls int `json:"max_calls,omitempty"`
+	// send one summary line per candidate coin instead of full market data, the AI pulls detail with tools
+	CompactPrompt bool `json:"compact_prompt,omitempty"`
+	// enabled tools: get_klines, get_orderbook, get_funding_history, get_position_history (empty = all)
+	Tools []string `json:"tools,omitempty"`
+}
+
+// EventTriggerConfig market events that launch an out-of-band decision cycle
+// Thresholds set to 0 disable the corresponding event
+type EventTriggerConfig struct {
+	Enabled bool `json:"enabled"`
+	// detection window in minutes for price moves, OI surges and liquidations (default: 15)
+	WindowMinutes int `json:"window_minutes,omitempty"`
+	// price move within the window, in percent
+	PriceMovePct float64 `json:"price_move_pct,omitempty"`
+	// current 1m range vs average 1m range of the previous 14 minutes
+	ATRSpikeMultiplier float64 `json:"atr_spike_multiplier,omitempty"`
+	// funding rate changes sign
+	FundingFlip bool `json:"funding_flip,omitempty"`
+	// open interest change within the window, in percent
+	OISurgePct float64 `json:"oi_surge_pct,omitempty"`
+	// liquidated value in the latest liquidation bar, in USD (requires liquidation data)
+	LiquidationCascadeUSD float64 `json:"liquidation_cascade_usd,omitempty"`
+	// position unrealized PnL crossing ±this percent of margin
+	PositionPnLPct float64 `json:"position_pnl_pct,omitempty"`
+	// minimum seconds between event-triggered cycles (default: 300)
+	DebounceSeconds int `json:"debounce_seconds,omitempty"`
+	// max event-triggered cycles per hour (default: 4)
+	MaxCallsPerHour int `json:"max_calls_per_hour,omitempty"`
 }
 
 // PromptSectionsConfig editable sections of System Prompt
@@ -77,6 +129,8 @@ type CoinSourceConfig struct {
 	UseOITop bool `json:"use_oi_top"`
 	// OI Top maximum count
 	OITopLimit int `json:"oi_top_limit,omitempty"`
+	// skip stock/forex/commodity candidates whose underlying market is closed (otherwise they are only flagged)
+	SkipClosedMarkets bool `json:"skip_closed_markets,omitempty"`
 	// Note: API URLs are now built automatically using NofxOSAPIKey from IndicatorConfig
 }
 
@@ -129,6 +183,21 @@ type IndicatorConfig struct {
 	EnablePriceRanking   bool   `json:"enable_price_ranking"`             // whether to enable price ranking data
 	PriceRankingDuration string `json:"price_ranking_duration,omitempty"` // durations: "1h" or "1h,4h,24h"
 	PriceRankingLimit    int    `json:"price_ranking_limit,omitempty"`    // number of entries per ranking (default 10)
+
+	// ========== CoinAnk Derivatives Data (per candidate coin) ==========
+	// CoinAnk OpenAPI key (required for the sections below)
+	CoinankAPIKey string `json:"coinank_api_key,omitempty"`
+	// recent liquidations (long/short totals + large-order price clusters)
+	EnableLiquidationData bool   `json:"enable_liquidation_data"`
+	LiquidationInterval   string `json:"liquidation_interval,omitempty"` // bar interval for history: 1h, 4h (default 1h)
+	// top-trader long/short ratio + net long/short positions
+	EnableLongShortRatio bool `json:"enable_long_short_ratio"`
+	// open interest vs market cap ratio
+	EnableOIVsMarketCap bool `json:"enable_oi_vs_market_cap"`
+
+	// order book depth snapshot (spread, ±1% depth, bid/ask imbalance, top levels)
+	EnableOrderBook bool `json:"enable_order_book"`
+	OrderBookLevels int  `json:"order_book_levels,omitempty"` // top levels shown per side (default 5)
 }
 
 // KlineConfig K-line configuration
@@ -182,6 +251,26 @@ type RiskControlConfig struct {
 	MinRiskRewardRatio float64 `json:"min_risk_reward_ratio"`
 	// Min AI confidence to open position (AI guided)
 	MinConfidence int `json:"min_confidence"`
+
+	// Max estimated market order slippage in percent, larger orders are split or reduced (CODE ENFORCED, 0 = disabled)
+	MaxSlippagePct float64 `json:"max_slippage_pct,omitempty"`
+	// Max number of child orders an oversized market order may be split into (CODE ENFORCED, default: 3)
+	MaxOrderSplits int `json:"max_order_splits,omitempty"`
+	// Allow opening stock/forex/commodity perps while the underlying market is closed (CODE ENFORCED, default: false)
+	AllowClosedMarketEntry bool `json:"allow_closed_market_entry,omitempty"`
+
+	// Max |long notional - short notional| = equity × this ratio (CODE ENFORCED, 0 = disabled)
+	MaxNetExposureRatio float64 `json:"max_net_exposure_ratio,omitempty"`
+	// Max long notional + short notional = equity × this ratio (CODE ENFORCED, 0 = disabled)
+	MaxGrossExposureRatio float64 `json:"max_gross_exposure_ratio,omitempty"`
+	// Max net exposure of one correlation cluster = equity × this ratio (CODE ENFORCED, 0 = disabled)
+	MaxClusterExposureRatio float64 `json:"max_cluster_exposure_ratio,omitempty"`
+	// Return correlation at which two symbols belong to the same cluster (CODE ENFORCED, default: 0.8)
+	CorrelationThreshold float64 `json:"correlation_threshold,omitempty"`
+	// Number of 1h bars used for rolling return correlations (CODE ENFORCED, default: 72)
+	CorrelationLookback int `json:"correlation_lookback,omitempty"`
+	// Max positions open in the same direction (CODE ENFORCED, 0 = disabled)
+	MaxSameDirectionPositions int `json:"max_same_direction_positions,omitempty"`
 }
 
 // TriggerPriceStrategy 触发价格策略配置 (简化版本，匹配前端格式)
@@ -247,11 +336,6 @@ func NewStrategyStore(db *gorm.DB) *StrategyStore {
 	return &StrategyStore{db: db}
 }
 
-func (s *StrategyStore) initTables() error {
-	// AutoMigrate will add missing columns without dropping existing data
-	return s.db.AutoMigrate(&Strategy{})
-}
-
 func (s *StrategyStore) initDefaultData() error {
 	// No longer pre-populate strategies - create on demand when user configures
 	return nil
@@ -325,8 +409,27 @@ func GetDefaultStrategyConfig(lang string) StrategyConfig {
 			MinPositionSize:              12,  // Min 12 USDT per position (CODE ENFORCED)
 			MinRiskRewardRatio:           3.0, // Min 3:1 profit/loss ratio (AI guided)
 			MinConfidence:                75,  // Min 75% confidence (AI guided)
+			MaxSlippagePct:               0.5, // Max 0.5% estimated slippage per market order (CODE ENFORCED)
+			MaxOrderSplits:               3,   // Split oversized orders into at most 3 child orders (CODE ENFORCED)
+			MaxNetExposureRatio:          3.0, // Net directional exposure ≤ 3x equity (CODE ENFORCED)
+			MaxGrossExposureRatio:        5.0, // Gross exposure ≤ 5x equity (CODE ENFORCED)
+			MaxClusterExposureRatio:      1.5, // Net exposure per correlation cluster ≤ 1.5x equity (CODE ENFORCED)
+			CorrelationThreshold:         0.8, // Symbols with 1h return correlation ≥ 0.8 share a cluster (CODE ENFORCED)
+			CorrelationLookback:          72,  // 72 × 1h bars for rolling correlations (CODE ENFORCED)
+			MaxSameDirectionPositions:    2,   // Max 2 longs or 2 shorts at the same time (CODE ENFORCED)
 		},
 		TriggerPriceConfig: GetDefaultTriggerPriceConfig("swing"),
+		EventTriggers: EventTriggerConfig{
+			Enabled:            false,
+			WindowMinutes:      15,
+			PriceMovePct:       3.0, // 3% move within 15 minutes
+			ATRSpikeMultiplier: 3.0, // 1m range 3x the recent average
+			FundingFlip:        true,
+			OISurgePct:         5.0,  // OI +/-5% within 15 minutes
+			PositionPnLPct:     10.0, // position PnL beyond ±10% of margin
+			DebounceSeconds:    300,
+			MaxCallsPerHour:    4,
+		},
 	}
 
 	if lang == "zh" {
@@ -45,11 +45,6 @@ func NewTPSLStore(db *gorm.DB) *TPSLStore {
 	return &TPSLStore{db: db}
 }
 
-// InitTables 初始化表
-func (s *TPSLStore) InitTables() error {
-	return s.db.AutoMigrate(&TPSLRecord{})
-}
-
 // SaveTPSLRecord 保存 TP/SL 记录
 func (s *TPSLStore) SaveTPSLRecord(record *TPSLRecord) error {
 	if record.CreatedAt.IsZero() {
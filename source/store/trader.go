@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -30,6 +31,8 @@ type Trader struct {
 	IsRunning           bool      `gorm:"column:is_running;default:false" json:"is_running"`
 	IsCrossMargin       bool      `gorm:"column:is_cross_margin;default:true" json:"is_cross_margin"`
 	ShowInCompetition   bool      `gorm:"column:show_in_competition;default:true" json:"show_in_competition"`
+	DebatePanelID       string    `gorm:"column:debate_panel_id;default:''" json:"debate_panel_id"`             // Debate session whose panel decides each cycle (empty = single AI model)
+	FallbackAIModelIDs  string    `gorm:"column:fallback_ai_model_ids;default:''" json:"fallback_ai_model_ids"` // Comma-separated AI models tried in order when the primary fails
 	CreatedAt           time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 	UpdatedAt           time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 
@@ -41,7 +44,7 @@ type Trader struct {
 	UseOITop             bool   `gorm:"column:use_oi_top;default:false" json:"use_oi_top,omitempty"`
 	CustomPrompt         string `gorm:"column:custom_prompt;default:''" json:"custom_prompt,omitempty"`
 	OverrideBasePrompt   bool   `gorm:"column:override_base_prompt;default:false" json:"override_base_prompt,omitempty"`
-	SystemPromptTemplate string `gorm:"column:system_prompt_template;default:default" json:"system_prompt_template,omitempty"`
+	SystemPromptTemplate string `gorm:"column:system_prompt_template;default:default" json:"system_prompt_template,omitempty"` // "default" or a prompt template library ID
 }
 
 // TableName returns the table name for Trader
@@ -49,6 +52,17 @@ func (Trader) TableName() string {
 	return "traders"
 }
 
+// FallbackModelIDs the fallback AI models in failover order
+func (t *Trader) FallbackModelIDs() []string {
+	var ids []string
+	for _, id := range strings.Split(t.FallbackAIModelIDs, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 // TraderFullConfig trader full configuration (includes AI model, exchange and strategy)
 type TraderFullConfig struct {
 	Trader   *Trader
@@ -57,22 +71,6 @@ type TraderFullConfig struct {
 	Strategy *Strategy
 }
 
-func (s *TraderStore) initTables() error {
-	// For PostgreSQL with existing table, skip AutoMigrate
-	if s.db.Dialector.Name() == "postgres" {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'traders'`).Scan(&tableExists)
-		if tableExists > 0 {
-			return nil
-		}
-	}
-	// Use GORM AutoMigrate
-	if err := s.db.AutoMigrate(&Trader{}); err != nil {
-		return fmt.Errorf("failed to migrate traders table: %w", err)
-	}
-	return nil
-}
-
 // Create creates trader
 func (s *TraderStore) Create(trader *Trader) error {
 	return s.db.Create(trader).Error
@@ -110,12 +108,14 @@ func (s *TraderStore) Update(trader *Trader) error {
 		trader.ID, trader.Name, trader.AIModelID, trader.StrategyID)
 
 	updates := map[string]interface{}{
-		"name":           trader.Name,
-		"ai_model_id":    trader.AIModelID,
-		"exchange_id":    trader.ExchangeID,
-		"strategy_id":    trader.StrategyID,
-		"is_cross_margin": trader.IsCrossMargin,
-		"show_in_competition": trader.ShowInCompetition,
+		"name":                  trader.Name,
+		"ai_model_id":           trader.AIModelID,
+		"exchange_id":           trader.ExchangeID,
+		"strategy_id":           trader.StrategyID,
+		"is_cross_margin":       trader.IsCrossMargin,
+		"show_in_competition":   trader.ShowInCompetition,
+		"debate_panel_id":       trader.DebatePanelID,
+		"fallback_ai_model_ids": trader.FallbackAIModelIDs,
 	}
 
 	// Only update these if > 0
@@ -41,40 +41,6 @@ func NewUserStore(db *gorm.DB) *UserStore {
 	return &UserStore{db: db}
 }
 
-func (s *UserStore) initTables() error {
-	// For PostgreSQL with existing table, skip AutoMigrate to avoid index conflicts
-	if s.db.Dialector.Name() == "postgres" {
-		var tableExists int64
-		s.db.Raw(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = 'users'`).Scan(&tableExists)
-
-		if tableExists > 0 {
-			// Table exists - manually ensure all columns exist
-			// Core columns (should already exist)
-			s.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS email TEXT NOT NULL DEFAULT ''`)
-			s.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS password_hash TEXT NOT NULL DEFAULT ''`)
-			s.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP`)
-			s.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP`)
-			// OTP columns (added later)
-			s.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS otp_secret TEXT DEFAULT ''`)
-			s.db.Exec(`ALTER TABLE users ADD COLUMN IF NOT EXISTS otp_verified BOOLEAN DEFAULT FALSE`)
-
-			// Ensure unique index exists on email (don't care about the name)
-			var indexExists int64
-			s.db.Raw(`
-				SELECT COUNT(*) FROM pg_indexes
-				WHERE tablename = 'users' AND indexdef LIKE '%email%' AND indexdef LIKE '%UNIQUE%'
-			`).Scan(&indexExists)
-
-			if indexExists == 0 {
-				s.db.Exec("CREATE UNIQUE INDEX idx_users_email ON users(email)")
-			}
-
-			return nil
-		}
-	}
-	return s.db.AutoMigrate(&User{})
-}
-
 // Create creates user
 func (s *UserStore) Create(user *User) error {
 	return s.db.Create(user).Error
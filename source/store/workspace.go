@@ -0,0 +1,190 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Workspace roles
+const (
+	WorkspaceRoleOwner   = "owner"   // Full control, including credentials and membership
+	WorkspaceRoleTrader  = "trader"  // Manage and run traders, strategies, backtests and debates
+	WorkspaceRoleAnalyst = "analyst" // Read-only access, can run backtests
+)
+
+// WorkspaceRoles all valid workspace roles
+var WorkspaceRoles = []string{WorkspaceRoleOwner, WorkspaceRoleTrader, WorkspaceRoleAnalyst}
+
+// IsValidWorkspaceRole checks if role is a valid workspace role
+func IsValidWorkspaceRole(role string) bool {
+	for _, r := range WorkspaceRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkspaceStore workspace and membership storage
+// A workspace owns its traders, strategies, exchange accounts, models, backtests and debates:
+// they are stored with the workspace ID in their user_id column, so all existing per-user
+// queries work unchanged for shared resources
+type WorkspaceStore struct {
+	db *gorm.DB
+}
+
+// Workspace shared team workspace
+type Workspace struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedBy string    `gorm:"column:created_by;not null" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (Workspace) TableName() string { return "workspaces" }
+
+// WorkspaceMember membership of a user in a workspace
+type WorkspaceMember struct {
+	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	WorkspaceID string    `gorm:"column:workspace_id;not null;uniqueIndex:idx_workspace_members_unique" json:"workspace_id"`
+	UserID      string    `gorm:"column:user_id;not null;uniqueIndex:idx_workspace_members_unique;index:idx_workspace_members_user" json:"user_id"`
+	Role        string    `gorm:"not null" json:"role"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (WorkspaceMember) TableName() string { return "workspace_members" }
+
+// WorkspaceWithRole workspace together with the role of the requesting user
+type WorkspaceWithRole struct {
+	Workspace
+	Role string `json:"role"`
+}
+
+// WorkspaceMemberInfo member with user email
+type WorkspaceMemberInfo struct {
+	WorkspaceMember
+	Email string `json:"email"`
+}
+
+// NewWorkspaceStore creates a new WorkspaceStore
+func NewWorkspaceStore(db *gorm.DB) *WorkspaceStore {
+	return &WorkspaceStore{db: db}
+}
+
+// Create creates a workspace with its creator as owner
+func (s *WorkspaceStore) Create(ws *Workspace) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ws).Error; err != nil {
+			return fmt.Errorf("failed to create workspace: %w", err)
+		}
+		member := &WorkspaceMember{WorkspaceID: ws.ID, UserID: ws.CreatedBy, Role: WorkspaceRoleOwner}
+		if err := tx.Create(member).Error; err != nil {
+			return fmt.Errorf("failed to add workspace owner: %w", err)
+		}
+		return nil
+	})
+}
+
+// Get gets a workspace by ID
+func (s *WorkspaceStore) Get(id string) (*Workspace, error) {
+	var ws Workspace
+	if err := s.db.Where("id = ?", id).First(&ws).Error; err != nil {
+		return nil, err
+	}
+	return &ws, nil
+}
+
+// ListIDs gets all workspace IDs (resource owners besides users)
+func (s *WorkspaceStore) ListIDs() ([]string, error) {
+	var ids []string
+	err := s.db.Model(&Workspace{}).Order("id").Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ListForUser lists the workspaces a user is member of
+func (s *WorkspaceStore) ListForUser(userID string) ([]*WorkspaceWithRole, error) {
+	var result []*WorkspaceWithRole
+	err := s.db.Table("workspaces").
+		Select("workspaces.*, workspace_members.role").
+		Joins("JOIN workspace_members ON workspace_members.workspace_id = workspaces.id").
+		Where("workspace_members.user_id = ?", userID).
+		Order("workspaces.created_at").
+		Scan(&result).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspaces: %w", err)
+	}
+	return result, nil
+}
+
+// GetMember gets the membership of a user in a workspace
+func (s *WorkspaceStore) GetMember(workspaceID, userID string) (*WorkspaceMember, error) {
+	var member WorkspaceMember
+	err := s.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// ListMembers lists the members of a workspace with their emails
+func (s *WorkspaceStore) ListMembers(workspaceID string) ([]*WorkspaceMemberInfo, error) {
+	var result []*WorkspaceMemberInfo
+	err := s.db.Table("workspace_members").
+		Select("workspace_members.*, users.email").
+		Joins("LEFT JOIN users ON users.id = workspace_members.user_id").
+		Where("workspace_members.workspace_id = ?", workspaceID).
+		Order("workspace_members.created_at").
+		Scan(&result).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query workspace members: %w", err)
+	}
+	return result, nil
+}
+
+// AddMember adds a user to a workspace
+func (s *WorkspaceStore) AddMember(workspaceID, userID, role string) error {
+	member := &WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: role}
+	if err := s.db.Create(member).Error; err != nil {
+		return fmt.Errorf("failed to add workspace member: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemberRole changes the role of a member
+func (s *WorkspaceStore) UpdateMemberRole(workspaceID, userID, role string) error {
+	result := s.db.Model(&WorkspaceMember{}).
+		Where("workspace_id = ? AND user_id = ?", workspaceID, userID).
+		Updates(map[string]interface{}{"role": role, "updated_at": time.Now().UTC()})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update workspace member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// RemoveMember removes a user from a workspace
+func (s *WorkspaceStore) RemoveMember(workspaceID, userID string) error {
+	result := s.db.Where("workspace_id = ? AND user_id = ?", workspaceID, userID).Delete(&WorkspaceMember{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove workspace member: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountOwners counts the owners of a workspace
+func (s *WorkspaceStore) CountOwners(workspaceID string) (int, error) {
+	var count int64
+	err := s.db.Model(&WorkspaceMember{}).
+		Where("workspace_id = ? AND role = ?", workspaceID, WorkspaceRoleOwner).
+		Count(&count).Error
+	return int(count), err
+}
@@ -0,0 +1,529 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/metrics"
+	"nofx/store"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	accountPollInterval       = 30 * time.Second // REST polling interval while the stream is down
+	accountStreamMinBackoff   = 2 * time.Second
+	accountStreamMaxBackoff   = 5 * time.Minute
+	accountPositionCheckDelay = 5 * time.Second // fills of a position change usually arrive within this delay
+)
+
+// AccountFill trade execution pushed by an account stream
+type AccountFill struct {
+	Symbol       string
+	Side         string // BUY or SELL
+	PositionSide string // LONG, SHORT or BOTH (one-way mode)
+	OrderAction  string // open_long, open_short, close_long, close_short
+	OrderType    string
+	OrderID      string
+	TradeID      string
+	Price        float64
+	Quantity     float64 // base asset quantity
+	Fee          float64
+	FeeAsset     string
+	RealizedPnL  float64
+	IsMaker      bool
+	Time         time.Time
+}
+
+// AccountOrderUpdate order status change pushed by an account stream
+type AccountOrderUpdate struct {
+	Symbol     string
+	OrderID    string
+	Status     string // NEW, PARTIALLY_FILLED, FILLED, CANCELED, REJECTED, EXPIRED
+	FilledQty  float64
+	AvgPrice   float64
+	Commission float64
+}
+
+// AccountPositionUpdate position snapshot pushed by an account stream
+type AccountPositionUpdate struct {
+	Symbol     string
+	Side       string  // LONG, SHORT, or empty when a one-way position is flat
+	Quantity   float64 // absolute base asset quantity, 0 = flat
+	EntryPrice float64
+}
+
+// AccountEvent a single message of an account stream
+// Connected is sent once the stream is authenticated and subscribed
+type AccountEvent struct {
+	Connected bool
+	Fill      *AccountFill
+	Order     *AccountOrderUpdate
+	Position  *AccountPositionUpdate
+}
+
+// AccountStream real-time user-data stream of an exchange account
+type AccountStream interface {
+	// Name exchange name for logging
+	Name() string
+	// Run connects, authenticates and pushes events until ctx is done or the connection fails
+	Run(ctx context.Context, events chan<- AccountEvent) error
+}
+
+// sendAccountEvent pushes an event unless the stream is being stopped
+func sendAccountEvent(ctx context.Context, events chan<- AccountEvent, event AccountEvent) bool {
+	select {
+	case events <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// normalizeOrderStatus maps exchange order states to the OrderStore status values
+func normalizeOrderStatus(status string) string {
+	switch strings.ToLower(strings.ReplaceAll(status, "_", "")) {
+	case "new", "live", "open", "untriggered", "triggered":
+		return "NEW"
+	case "partiallyfilled":
+		return "PARTIALLY_FILLED"
+	case "filled":
+		return "FILLED"
+	case "canceled", "cancelled", "margincanceled", "deactivated", "partiallyfilledcanceled", "mmpcanceled":
+		return "CANCELED"
+	case "rejected":
+		return "REJECTED"
+	case "expired":
+		return "EXPIRED"
+	}
+	return strings.ToUpper(status)
+}
+
+// jsonString returns a string field of a decoded JSON object
+func jsonString(item map[string]interface{}, key string) string {
+	s, _ := item[key].(string)
+	return s
+}
+
+// jsonFloat returns a numeric string field of a decoded JSON object
+func jsonFloat(item map[string]interface{}, key string) float64 {
+	f, _ := strconv.ParseFloat(jsonString(item, key), 64)
+	return f
+}
+
+// dialAccountStream opens a websocket connection that is closed when ctx is done
+func dialAccountStream(ctx context.Context, url string) (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(url, "http://localhost")
+	if err != nil {
+		return nil, err
+	}
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect %s: %w", url, err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	return conn, nil
+}
+
+// readAccountStream sends ping every interval and hands every received message to handle until an error occurs
+func readAccountStream(ctx context.Context, conn *websocket.Conn, ping []byte, interval time.Duration, handle func([]byte) error) error {
+	pingCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := websocket.Message.Send(conn, string(ping)); err != nil {
+					conn.Close()
+					return
+				}
+			case <-pingCtx.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		var msg []byte
+		if err := websocket.Message.Receive(conn, &msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("connection lost: %w", err)
+		}
+		if err := handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// accountSync persists account stream events and falls back to REST polling while the stream is down
+type accountSync struct {
+	traderID     string
+	exchangeID   string
+	exchangeType string
+	st           *store.Store
+	stream       AccountStream
+	poll         func() error // REST order sync, used as fallback and for reconciliation
+
+	connected    bool
+	drift        map[string]AccountPositionUpdate // symbol_side -> exchange position that differs from the local one
+	missingFills map[string]bool                  // exchange order IDs reported filled without recorded fills
+}
+
+// startAccountSync streams fills, order updates and position changes of the account into the store,
+// exchanges without a stream keep their own REST order sync
+func (at *AutoTrader) startAccountSync() {
+	if at.store == nil {
+		return
+	}
+
+	var stream AccountStream
+	var poll func() error
+	switch t := at.trader.(type) {
+	case *FuturesTrader:
+		stream = newBinanceAccountStream(t)
+		poll = func() error { return t.SyncOrdersFromBinance(at.id, at.exchangeID, at.exchange, at.store) }
+	case *BybitTrader:
+		stream = newBybitAccountStream(t)
+		poll = func() error { return t.SyncOrdersFromBybit(at.id, at.exchangeID, at.exchange, at.store) }
+	case *OKXTrader:
+		stream = newOKXAccountStream(t)
+		poll = func() error { return t.SyncOrdersFromOKX(at.id, at.exchangeID, at.exchange, at.store) }
+	case *HyperliquidTrader:
+		stream = newHyperliquidAccountStream(t)
+		poll = func() error { return t.SyncOrdersFromHyperliquid(at.id, at.exchangeID, at.exchange, at.store) }
+	default:
+		return
+	}
+
+	s := &accountSync{
+		traderID:     at.id,
+		exchangeID:   at.exchangeID,
+		exchangeType: at.exchange,
+		st:           at.store,
+		stream:       stream,
+		poll:         poll,
+		drift:        make(map[string]AccountPositionUpdate),
+		missingFills: make(map[string]bool),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+		defer cancel()
+		go func() {
+			<-at.stopMonitorCh
+			cancel()
+		}()
+		s.run(ctx)
+	}()
+	at.log().Infof("🔄 [%s] %s account stream enabled (REST polling every %v while disconnected)", at.name, stream.Name(), accountPollInterval)
+}
+
+// run keeps the stream connected, reconciles via REST after every (re)connect and polls while disconnected
+func (s *accountSync) run(ctx context.Context) {
+	name := s.stream.Name()
+	events := make(chan AccountEvent, 256)
+	errCh := make(chan error, 1)
+	connect := func() {
+		go func() { errCh <- s.stream.Run(ctx, events) }()
+	}
+
+	pollTicker := time.NewTicker(accountPollInterval)
+	defer pollTicker.Stop()
+	var retry, positionCheck <-chan time.Time
+	backoff := accountStreamMinBackoff
+
+	s.reconcile("initial sync")
+	connect()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Infof("⏹ Stopped %s account stream", name)
+			return
+
+		case event := <-events:
+			if event.Connected {
+				s.connected = true
+				backoff = accountStreamMinBackoff
+				logger.Infof("🔌 %s account stream connected", name)
+				// Fills may have been missed while disconnected
+				s.reconcile("reconnect")
+				continue
+			}
+			if s.apply(event) && positionCheck == nil {
+				positionCheck = time.After(accountPositionCheckDelay)
+			}
+
+		case err := <-errCh:
+			if ctx.Err() != nil {
+				return
+			}
+			s.connected = false
+			logger.Warnf("⚠️ %s account stream disconnected: %v, falling back to polling, reconnecting in %v", name, err, backoff)
+			retry = time.After(backoff)
+			backoff = min(backoff*2, accountStreamMaxBackoff)
+
+		case <-retry:
+			retry = nil
+			metrics.WebsocketReconnects.Inc("account", s.exchangeType)
+			connect()
+
+		case <-positionCheck:
+			positionCheck = nil
+			if s.positionsDrifted() {
+				s.reconcile("position drift")
+			} else if s.fillsMissing() {
+				s.reconcile("missing fills")
+			}
+
+		case <-pollTicker.C:
+			if !s.connected {
+				s.reconcile("polling")
+			}
+		}
+	}
+}
+
+// reconcile runs the REST order sync
+func (s *accountSync) reconcile(reason string) {
+	if err := s.poll(); err != nil {
+		logger.Infof("⚠️  %s order sync (%s) failed: %v", s.stream.Name(), reason, err)
+	}
+}
+
+// apply persists a stream event, returns true when a position or an order differs from the local records
+func (s *accountSync) apply(event AccountEvent) bool {
+	if event.Fill != nil {
+		s.persistFill(event.Fill)
+	}
+	differs := false
+	if event.Order != nil {
+		differs = s.updateOrder(event.Order)
+	}
+	if event.Position != nil {
+		differs = s.checkPosition(event.Position) || differs
+	}
+	return differs
+}
+
+// persistFill records a streamed fill the same way the REST order sync does,
+// trade IDs are shared so a fill is never stored twice by either path
+func (s *accountSync) persistFill(fill *AccountFill) {
+	orderStore := s.st.Order()
+	existing, err := orderStore.GetOrderByExchangeID(s.exchangeID, fill.TradeID)
+	if err == nil && existing != nil {
+		return
+	}
+
+	symbol := market.Normalize(fill.Symbol)
+	side := strings.ToUpper(fill.Side)
+	positionSide := "LONG"
+	if strings.Contains(fill.OrderAction, "short") {
+		positionSide = "SHORT"
+	}
+	orderPositionSide := fill.PositionSide
+	if orderPositionSide == "" {
+		orderPositionSide = positionSide
+	}
+	// Hyperliquid fills carry no order type, they are recorded as MARKET like the REST order sync does
+	orderType := strings.ToUpper(fill.OrderType)
+	if orderType == "" {
+		orderType = "MARKET"
+	}
+	feeAsset := fill.FeeAsset
+	if feeAsset == "" {
+		feeAsset = "USDT"
+	}
+
+	tradeTimeMs := fill.Time.UTC().UnixMilli()
+	orderRecord := &store.TraderOrder{
+		TraderID:        s.traderID,
+		ExchangeID:      s.exchangeID,
+		ExchangeType:    s.exchangeType,
+		ExchangeOrderID: fill.TradeID,
+		Symbol:          symbol,
+		Side:            side,
+		PositionSide:    orderPositionSide,
+		Type:            orderType,
+		OrderAction:     fill.OrderAction,
+		Quantity:        fill.Quantity,
+		Price:           fill.Price,
+		Status:          "FILLED",
+		FilledQuantity:  fill.Quantity,
+		AvgFillPrice:    fill.Price,
+		Commission:      fill.Fee,
+		FilledAt:        tradeTimeMs,
+		CreatedAt:       tradeTimeMs,
+		UpdatedAt:       tradeTimeMs,
+	}
+	if err := orderStore.CreateOrder(orderRecord); err != nil {
+		logger.Infof("  ⚠️ Failed to record streamed trade %s: %v", fill.TradeID, err)
+		return
+	}
+
+	fillRecord := &store.TraderFill{
+		TraderID:        s.traderID,
+		ExchangeID:      s.exchangeID,
+		ExchangeType:    s.exchangeType,
+		OrderID:         orderRecord.ID,
+		ExchangeOrderID: fill.OrderID,
+		ExchangeTradeID: fill.TradeID,
+		Symbol:          symbol,
+		Side:            side,
+		Price:           fill.Price,
+		Quantity:        fill.Quantity,
+		QuoteQuantity:   fill.Price * fill.Quantity,
+		Commission:      fill.Fee,
+		CommissionAsset: feeAsset,
+		RealizedPnL:     fill.RealizedPnL,
+		IsMaker:         fill.IsMaker,
+		CreatedAt:       tradeTimeMs,
+	}
+	if err := orderStore.CreateFill(fillRecord); err != nil {
+		logger.Infof("  ⚠️ Failed to record streamed fill %s: %v", fill.TradeID, err)
+	}
+
+	posBuilder := store.NewPositionBuilder(s.st.Position())
+	if err := posBuilder.ProcessTrade(
+		s.traderID, s.exchangeID, s.exchangeType,
+		symbol, positionSide, fill.OrderAction,
+		fill.Quantity, fill.Price, fill.Fee, fill.RealizedPnL,
+		tradeTimeMs, fill.TradeID,
+	); err != nil {
+		logger.Infof("  ⚠️ Failed to update position for streamed trade %s: %v", fill.TradeID, err)
+	}
+
+	logger.Infof("  ⚡ Streamed trade: %s %s %s qty=%.6f price=%.6f pnl=%.2f fee=%.6f action=%s",
+		fill.TradeID, symbol, side, fill.Quantity, fill.Price, fill.RealizedPnL, fill.Fee, fill.OrderAction)
+}
+
+// updateOrder updates the local records of an exchange order, keyed by its exchange order ID.
+// Orders placed through recordAndConfirmOrder are recorded under that ID and take the streamed status;
+// order sync records one order per trade (keyed by trade ID), those are found through their fills and
+// are already final. Returns true when the order has filled but none of its fills is recorded yet.
+func (s *accountSync) updateOrder(update *AccountOrderUpdate) bool {
+	if update.OrderID == "" {
+		return false
+	}
+	orders, err := s.st.Order().GetOrdersByExchangeOrderID(s.exchangeID, update.OrderID)
+	if err != nil {
+		logger.Infof("  ⚠️ Failed to look up order %s: %v", update.OrderID, err)
+		return false
+	}
+
+	filled := update.Status == "FILLED" || update.Status == "PARTIALLY_FILLED"
+	if len(orders) == 0 {
+		if filled {
+			s.missingFills[update.OrderID] = true
+		}
+		return filled
+	}
+
+	for _, existing := range orders {
+		if existing.ExchangeOrderID == update.OrderID && existing.Status != update.Status {
+			s.applyOrderStatus(existing, update)
+		}
+	}
+	return false
+}
+
+// applyOrderStatus stores a streamed status of an order recorded under its exchange order ID
+func (s *accountSync) applyOrderStatus(existing *store.TraderOrder, update *AccountOrderUpdate) {
+	// Streams do not always carry fill totals, keep what is already recorded
+	filledQty, avgPrice, commission := update.FilledQty, update.AvgPrice, update.Commission
+	if filledQty == 0 {
+		filledQty = existing.FilledQuantity
+	}
+	if avgPrice == 0 {
+		avgPrice = existing.AvgFillPrice
+	}
+	if commission == 0 {
+		commission = existing.Commission
+	}
+	if err := s.st.Order().UpdateOrderStatus(existing.ID, update.Status, filledQty, avgPrice, commission); err != nil {
+		logger.Infof("  ⚠️ Failed to update order %s status: %v", update.OrderID, err)
+	}
+}
+
+// fillsMissing re-checks filled orders without recorded fills after the fills had time to arrive
+func (s *accountSync) fillsMissing() bool {
+	missing := false
+	for orderID := range s.missingFills {
+		orders, err := s.st.Order().GetOrdersByExchangeOrderID(s.exchangeID, orderID)
+		if err == nil && len(orders) == 0 {
+			missing = true
+		}
+		delete(s.missingFills, orderID)
+	}
+	return missing
+}
+
+// checkPosition compares a streamed position with the local open position, differences are kept
+// until the fills have had time to arrive
+func (s *accountSync) checkPosition(update *AccountPositionUpdate) bool {
+	symbol := market.Normalize(update.Symbol)
+	sides := []string{update.Side}
+	if update.Side == "" {
+		sides = []string{"LONG", "SHORT"}
+	}
+
+	drifted := false
+	for _, side := range sides {
+		key := symbol + "_" + side
+		exchangeQty := 0.0
+		if side == update.Side {
+			exchangeQty = update.Quantity
+		}
+		if quantitiesMatch(s.localQuantity(symbol, side), exchangeQty) {
+			delete(s.drift, key)
+			continue
+		}
+		s.drift[key] = AccountPositionUpdate{Symbol: symbol, Side: side, Quantity: exchangeQty, EntryPrice: update.EntryPrice}
+		drifted = true
+	}
+	return drifted
+}
+
+// positionsDrifted re-checks pending position differences after fills had time to arrive
+func (s *accountSync) positionsDrifted() bool {
+	drifted := false
+	for key, pos := range s.drift {
+		local := s.localQuantity(pos.Symbol, pos.Side)
+		if quantitiesMatch(local, pos.Quantity) {
+			delete(s.drift, key)
+			continue
+		}
+		logger.Warnf("⚠️ %s position %s %s differs from local record (exchange %.6f, local %.6f), reconciling",
+			s.stream.Name(), pos.Symbol, pos.Side, pos.Quantity, local)
+		delete(s.drift, key)
+		drifted = true
+	}
+	return drifted
+}
+
+// localQuantity returns the quantity of the local open position, 0 if none
+func (s *accountSync) localQuantity(symbol, side string) float64 {
+	pos, err := s.st.Position().GetOpenPositionBySymbol(s.traderID, symbol, side)
+	if err != nil || pos == nil {
+		return 0
+	}
+	return pos.Quantity
+}
+
+// quantitiesMatch compares position quantities with a relative tolerance for rounding
+func quantitiesMatch(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9+1e-4*math.Max(math.Abs(a), math.Abs(b))
+}
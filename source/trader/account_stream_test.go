@@ -0,0 +1,140 @@
+package trader
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"nofx/store"
+)
+
+func TestParseBybitPrivateMessage(t *testing.T) {
+	msg := []byte(`{"topic":"execution","data":[
+		{"category":"linear","symbol":"BTCUSDT","execId":"e1","orderId":"o1","side":"Sell","execPrice":"65000","execQty":"0.01","execFee":"0.39","execTime":"1767225600000","execType":"Trade","closedSize":"0.01","execPnl":"12.5","isMaker":false,"orderType":"Market"},
+		{"category":"linear","symbol":"BTCUSDT","execId":"e2","execType":"Funding","execQty":"0.01"}
+	]}`)
+	events, err := parseBybitPrivateMessage(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Fill == nil {
+		t.Fatalf("expected one fill, got %+v", events)
+	}
+	fill := events[0].Fill
+	if fill.TradeID != "e1" || fill.OrderAction != "close_long" || fill.RealizedPnL != 12.5 || fill.Quantity != 0.01 {
+		t.Errorf("unexpected fill %+v", fill)
+	}
+
+	events, err = parseBybitPrivateMessage([]byte(`{"topic":"position","data":[{"category":"linear","symbol":"BTCUSDT","side":"","size":"0","entryPrice":"0"}]}`))
+	if err != nil || len(events) != 1 || events[0].Position == nil || events[0].Position.Side != "" || events[0].Position.Quantity != 0 {
+		t.Errorf("expected flat position, got %+v %v", events, err)
+	}
+}
+
+func TestParseOKXPrivateMessage(t *testing.T) {
+	s := &okxAccountStream{trader: &OKXTrader{}, contractValue: func(string) float64 { return 0.01 }}
+	msg := []byte(`{"arg":{"channel":"orders","instType":"SWAP"},"data":[
+		{"instId":"BTC-USDT-SWAP","ordId":"o1","tradeId":"t1","side":"buy","posSide":"short","state":"filled","fillPx":"65000","fillSz":"3","accFillSz":"3","avgPx":"65000","fillFee":"-0.1","fee":"-0.1","fillFeeCcy":"USDT","fillTime":"1767225600000","execType":"T","ordType":"market","attachAlgoOrds":[]}
+	]}`)
+	events, err := s.parse(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Order == nil || events[1].Fill == nil {
+		t.Fatalf("expected order update and fill, got %+v", events)
+	}
+	if events[0].Order.Status != "FILLED" {
+		t.Errorf("expected FILLED, got %s", events[0].Order.Status)
+	}
+	fill := events[1].Fill
+	if fill.Symbol != "BTCUSDT" || fill.OrderAction != "close_short" || fill.Quantity != 0.03 || fill.Fee != 0.1 {
+		t.Errorf("unexpected fill %+v", fill)
+	}
+}
+
+func TestParseHyperliquidMessage(t *testing.T) {
+	data := json.RawMessage(`{"isSnapshot":false,"user":"0x1","fills":[
+		{"coin":"ETH","px":"3000","sz":"0.5","side":"A","time":1767225600000,"startPosition":"0.5","dir":"Close Long","closedPnl":"20","oid":7,"crossed":true,"fee":"0.6","tid":42}
+	]}`)
+	events, err := parseHyperliquidMessage("userFills", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[0].Fill == nil || events[1].Position == nil {
+		t.Fatalf("expected fill and position, got %+v", events)
+	}
+	if fill := events[0].Fill; fill.TradeID != "42" || fill.Side != "SELL" || fill.OrderAction != "close_long" {
+		t.Errorf("unexpected fill %+v", fill)
+	}
+	if pos := events[1].Position; pos.Side != "" || pos.Quantity != 0 {
+		t.Errorf("position should be flat after closing, got %+v", pos)
+	}
+
+	// Snapshots are left to the REST reconciliation
+	events, _ = parseHyperliquidMessage("userFills", json.RawMessage(`{"isSnapshot":true,"fills":[{"coin":"ETH","tid":1}]}`))
+	if len(events) != 0 {
+		t.Errorf("snapshot should be ignored, got %+v", events)
+	}
+}
+
+func TestNormalizeOrderStatus(t *testing.T) {
+	cases := map[string]string{
+		"PartiallyFilled":  "PARTIALLY_FILLED",
+		"partially_filled": "PARTIALLY_FILLED",
+		"Cancelled":        "CANCELED",
+		"marginCanceled":   "CANCELED",
+		"live":             "NEW",
+		"FILLED":           "FILLED",
+	}
+	for in, want := range cases {
+		if got := normalizeOrderStatus(in); got != want {
+			t.Errorf("normalizeOrderStatus(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestAccountSyncUpdateOrder(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "orders.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	orders := st.Order()
+
+	// Order placed by the trader, recorded under its exchange order ID
+	placed := &store.TraderOrder{TraderID: "t1", ExchangeID: "ex1", ExchangeOrderID: "o1", Symbol: "BTCUSDT", Side: "BUY", Type: "MARKET", Quantity: 0.1, Status: "NEW"}
+	// Trade synced from the exchange, recorded under its trade ID and linked to the order by its fill
+	synced := &store.TraderOrder{TraderID: "t1", ExchangeID: "ex1", ExchangeOrderID: "trade-9", Symbol: "ETHUSDT", Side: "SELL", Type: "MARKET", Quantity: 1, Status: "FILLED"}
+	for _, order := range []*store.TraderOrder{placed, synced} {
+		if err := orders.CreateOrder(order); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := orders.CreateFill(&store.TraderFill{TraderID: "t1", ExchangeID: "ex1", OrderID: synced.ID, ExchangeOrderID: "o2",
+		ExchangeTradeID: "trade-9", Symbol: "ETHUSDT", Side: "SELL", Price: 3000, Quantity: 1, CommissionAsset: "USDT"}); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &accountSync{traderID: "t1", exchangeID: "ex1", st: st, missingFills: make(map[string]bool)}
+
+	if s.updateOrder(&AccountOrderUpdate{OrderID: "o1", Status: "FILLED", FilledQty: 0.1, AvgPrice: 65000}) {
+		t.Error("placed order should not be reported as missing fills")
+	}
+	if got, _ := orders.GetOrderByExchangeID("ex1", "o1"); got == nil || got.Status != "FILLED" || got.AvgFillPrice != 65000 {
+		t.Errorf("placed order should take the streamed status, got %+v", got)
+	}
+
+	if found, _ := orders.GetOrdersByExchangeOrderID("ex1", "o2"); len(found) != 1 || found[0].ID != synced.ID {
+		t.Fatalf("synced trade should be found by its exchange order ID, got %+v", found)
+	}
+	if s.updateOrder(&AccountOrderUpdate{OrderID: "o2", Status: "FILLED"}) {
+		t.Error("order with recorded fills should not be reported as missing fills")
+	}
+
+	if !s.updateOrder(&AccountOrderUpdate{OrderID: "o3", Status: "FILLED"}) || !s.fillsMissing() {
+		t.Error("filled order without recorded fills should trigger reconciliation")
+	}
+	if s.fillsMissing() {
+		t.Error("missing fills should only be reported once")
+	}
+}
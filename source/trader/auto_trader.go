@@ -4,15 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"nofx/debate"
 	"nofx/experience"
+	"nofx/experiment"
 	"nofx/kernel"
 	"nofx/logger"
 	"nofx/market"
 	"nofx/mcp"
+	"nofx/metrics"
 	"nofx/store"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/sirupsen/logrus"
 )
 
 // AutoTraderConfig auto trading configuration (simplified version - AI makes all decisions)
@@ -71,6 +77,9 @@ type AutoTraderConfig struct {
 	CustomAPIKey    string
 	CustomModelName string
 
+	// Fallback AI models tried in order when the primary model fails or is rate limited
+	FallbackAIModels []FallbackAIModel
+
 	// Scan configuration
 	ScanInterval time.Duration // Scan interval (recommended 3 minutes)
 
@@ -90,6 +99,17 @@ type AutoTraderConfig struct {
 
 	// Strategy configuration (use complete strategy config)
 	StrategyConfig *store.StrategyConfig // Strategy configuration (includes coin sources, indicators, risk control, prompts, etc.)
+
+	// Debate mode: a debate panel replaces the single AI model as the decision engine (empty = disabled)
+	DebatePanelID string
+}
+
+// FallbackAIModel one fallback model of a trader's AI failover chain
+type FallbackAIModel struct {
+	Provider string
+	APIKey   string
+	BaseURL  string
+	Model    string
 }
 
 // AutoTrader automatic trader
@@ -138,6 +158,19 @@ type AutoTrader struct {
 
 	// Error tracking
 	errorTracker *ErrorTracker // Error monitoring and statistics
+
+	// Event-driven decision cycles
+	cycleMu sync.Mutex   // Serializes scheduled and event-triggered cycles
+	events  *eventCycles // nil when event triggers are disabled
+
+	// Structured logging
+	logEntry atomic.Pointer[logrus.Entry] // Entry tagged with trader_id and the current cycle
+
+	// Debate-driven decisions
+	debateEngine *debate.DebateEngine // nil when the trader uses its single AI model
+
+	// Prompt/strategy A/B experiments
+	experiments *experiment.Runner // nil without a store
 }
 
 // NewAutoTrader creates an automatic trader
@@ -158,6 +191,8 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		}
 	}
 
+	log := logger.ForTrader(config.ID)
+
 	// Initialize AI client based on provider
 	var mcpClient mcp.AIClient
 	aiModel := config.AIModel
@@ -169,27 +204,27 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 	case "claude":
 		mcpClient = mcp.NewClaudeClient()
 		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using Claude AI", config.Name)
+		log.Infof("🤖 [%s] Using Claude AI", config.Name)
 
 	case "kimi":
 		mcpClient = mcp.NewKimiClient()
 		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using Kimi (Moonshot) AI", config.Name)
+		log.Infof("🤖 [%s] Using Kimi (Moonshot) AI", config.Name)
 
 	case "gemini":
 		mcpClient = mcp.NewGeminiClient()
 		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using Google Gemini AI", config.Name)
+		log.Infof("🤖 [%s] Using Google Gemini AI", config.Name)
 
 	case "grok":
 		mcpClient = mcp.NewGrokClient()
 		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using xAI Grok AI", config.Name)
+		log.Infof("🤖 [%s] Using xAI Grok AI", config.Name)
 
 	case "openai":
 		mcpClient = mcp.NewOpenAIClient()
 		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using OpenAI", config.Name)
+		log.Infof("🤖 [%s] Using OpenAI", config.Name)
 
 	case "qwen":
 		mcpClient = mcp.NewQwenClient()
@@ -198,12 +233,22 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 			apiKey = config.CustomAPIKey
 		}
 		mcpClient.SetAPIKey(apiKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using Alibaba Cloud Qwen AI", config.Name)
+		log.Infof("🤖 [%s] Using Alibaba Cloud Qwen AI", config.Name)
+
+	case "ollama":
+		mcpClient = mcp.NewOllamaClient()
+		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
+		log.Infof("🤖 [%s] Using local Ollama model", config.Name)
+
+	case "local":
+		mcpClient = mcp.NewLocalClient()
+		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
+		log.Infof("🤖 [%s] Using local OpenAI-compatible server: %s", config.Name, config.CustomAPIURL)
 
 	case "custom":
 		mcpClient = mcp.New()
 		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using custom AI API: %s (model: %s)", config.Name, config.CustomAPIURL, config.CustomModelName)
+		log.Infof("🤖 [%s] Using custom AI API: %s (model: %s)", config.Name, config.CustomAPIURL, config.CustomModelName)
 
 	default: // deepseek or empty
 		mcpClient = mcp.NewDeepSeekClient()
@@ -212,11 +257,23 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 			apiKey = config.CustomAPIKey
 		}
 		mcpClient.SetAPIKey(apiKey, config.CustomAPIURL, config.CustomModelName)
-		logger.Infof("🤖 [%s] Using DeepSeek AI", config.Name)
+		log.Infof("🤖 [%s] Using DeepSeek AI", config.Name)
 	}
 
 	if config.CustomAPIURL != "" || config.CustomModelName != "" {
-		logger.Infof("🔧 [%s] Custom config - URL: %s, Model: %s", config.Name, config.CustomAPIURL, config.CustomModelName)
+		log.Infof("🔧 [%s] Custom config - URL: %s, Model: %s", config.Name, config.CustomAPIURL, config.CustomModelName)
+	}
+
+	// Failover chain: the primary model first, then the fallbacks in order
+	if len(config.FallbackAIModels) > 0 {
+		chain := []mcp.AIClient{mcpClient}
+		for _, fallback := range config.FallbackAIModels {
+			client := mcp.NewProviderClient(fallback.Provider)
+			client.SetAPIKey(fallback.APIKey, fallback.BaseURL, fallback.Model)
+			chain = append(chain, client)
+		}
+		mcpClient = mcp.NewFailoverClient(chain...)
+		log.Infof("🔀 [%s] AI failover chain: %d fallback model(s)", config.Name, len(config.FallbackAIModels))
 	}
 
 	// Set default trading platform
@@ -233,35 +290,35 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 	if !config.IsCrossMargin {
 		marginModeStr = "Isolated Margin"
 	}
-	logger.Infof("📊 [%s] Position mode: %s", config.Name, marginModeStr)
+	log.Infof("📊 [%s] Position mode: %s", config.Name, marginModeStr)
 
 	switch config.Exchange {
 	case "binance":
-		logger.Infof("🏦 [%s] Using Binance Futures trading", config.Name)
+		log.Infof("🏦 [%s] Using Binance Futures trading", config.Name)
 		trader = NewFuturesTrader(config.BinanceAPIKey, config.BinanceSecretKey, userID)
 	case "bybit":
-		logger.Infof("🏦 [%s] Using Bybit Futures trading", config.Name)
+		log.Infof("🏦 [%s] Using Bybit Futures trading", config.Name)
 		trader = NewBybitTrader(config.BybitAPIKey, config.BybitSecretKey)
 	case "okx":
-		logger.Infof("🏦 [%s] Using OKX Futures trading", config.Name)
+		log.Infof("🏦 [%s] Using OKX Futures trading", config.Name)
 		trader = NewOKXTrader(config.OKXAPIKey, config.OKXSecretKey, config.OKXPassphrase)
 	case "bitget":
-		logger.Infof("🏦 [%s] Using Bitget Futures trading", config.Name)
+		log.Infof("🏦 [%s] Using Bitget Futures trading", config.Name)
 		trader = NewBitgetTrader(config.BitgetAPIKey, config.BitgetSecretKey, config.BitgetPassphrase)
 	case "hyperliquid":
-		logger.Infof("🏦 [%s] Using Hyperliquid trading", config.Name)
+		log.Infof("🏦 [%s] Using Hyperliquid trading", config.Name)
 		trader, err = NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize Hyperliquid trader: %w", err)
 		}
 	case "aster":
-		logger.Infof("🏦 [%s] Using Aster trading", config.Name)
+		log.Infof("🏦 [%s] Using Aster trading", config.Name)
 		trader, err = NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize Aster trader: %w", err)
 		}
 	case "lighter":
-		logger.Infof("🏦 [%s] Using LIGHTER trading", config.Name)
+		log.Infof("🏦 [%s] Using LIGHTER trading", config.Name)
 
 		if config.LighterWalletAddr == "" || config.LighterAPIKeyPrivateKey == "" {
 			return nil, fmt.Errorf("Lighter requires wallet address and API Key private key")
@@ -277,14 +334,14 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize LIGHTER trader: %w", err)
 		}
-		logger.Infof("✓ LIGHTER trader initialized successfully")
+		log.Infof("✓ LIGHTER trader initialized successfully")
 	default:
 		return nil, fmt.Errorf("unsupported trading platform: %s", config.Exchange)
 	}
 
 	// Validate initial balance configuration, auto-fetch from exchange if 0
 	if config.InitialBalance <= 0 {
-		logger.Infof("📊 [%s] Initial balance not set, attempting to fetch current balance from exchange...", config.Name)
+		log.Infof("📊 [%s] Initial balance not set, attempting to fetch current balance from exchange...", config.Name)
 		account, err := trader.GetBalance()
 		if err != nil {
 			return nil, fmt.Errorf("initial balance not set and unable to fetch balance from exchange: %w", err)
@@ -300,13 +357,13 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		}
 		if foundBalance > 0 {
 			config.InitialBalance = foundBalance
-			logger.Infof("✓ [%s] Auto-fetched initial balance: %.2f USDT", config.Name, foundBalance)
+			log.Infof("✓ [%s] Auto-fetched initial balance: %.2f USDT", config.Name, foundBalance)
 			// Save to database so it persists across restarts
 			if st != nil {
 				if err := st.Trader().UpdateInitialBalance(userID, config.ID, foundBalance); err != nil {
-					logger.Infof("⚠️  [%s] Failed to save initial balance to database: %v", config.Name, err)
+					log.Infof("⚠️  [%s] Failed to save initial balance to database: %v", config.Name, err)
 				} else {
-					logger.Infof("✓ [%s] Initial balance saved to database", config.Name)
+					log.Infof("✓ [%s] Initial balance saved to database", config.Name)
 				}
 			}
 		} else {
@@ -318,7 +375,7 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 	var cycleNumber int
 	if st != nil {
 		cycleNumber, _ = st.Decision().GetLastCycleNumber(config.ID)
-		logger.Infof("📊 [%s] Decision records will be stored to database", config.Name)
+		log.Infof("📊 [%s] Decision records will be stored to database", config.Name)
 	}
 
 	// Create strategy engine (must have strategy config)
@@ -326,25 +383,45 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		return nil, fmt.Errorf("[%s] strategy not configured", config.Name)
 	}
 	strategyEngine := kernel.NewStrategyEngine(config.StrategyConfig)
-	logger.Infof("✓ [%s] Using strategy engine (strategy configuration loaded)", config.Name)
+	log.Infof("✓ [%s] Using strategy engine (strategy configuration loaded)", config.Name)
 
 	// Option B: Initialize enhanced trading modules
 	var enhancedSetup *EnhancedAutoTraderSetup
 	if st != nil {
 		enhancedSetup = InitializeEnhancedModules(config.ID, config.InitialBalance, *st)
-		logger.Infof("✓ [%s] Enhanced trading modules initialized (Option B)", config.Name)
+		log.Infof("✓ [%s] Enhanced trading modules initialized (Option B)", config.Name)
 	}
 
 	// Initialize order deduplication manager
 	var orderDedupManager *OrderDeduplicationManager
 	if st != nil {
 		orderDedupManager = NewOrderDeduplicationManager(config.ID, st)
-		logger.Infof("✓ [%s] Order deduplication manager initialized", config.Name)
+		log.Infof("✓ [%s] Order deduplication manager initialized", config.Name)
+	}
+
+	// Debate mode: the panel decides every cycle instead of the single AI model
+	var debateEngine *debate.DebateEngine
+	if config.DebatePanelID != "" {
+		if st == nil {
+			return nil, fmt.Errorf("[%s] debate mode requires a store", config.Name)
+		}
+		if err := debate.ValidatePanel(st.Debate(), config.DebatePanelID, userID); err != nil {
+			return nil, fmt.Errorf("[%s] %w", config.Name, err)
+		}
+		debateEngine = debate.NewTraderDebateEngine(st)
+		log.Infof("🗣️ [%s] Debate mode enabled (panel %s)", config.Name, config.DebatePanelID)
+	}
+
+	// Shadow variants of a running experiment are recorded alongside each cycle
+	var experiments *experiment.Runner
+	if st != nil {
+		experiments = experiment.NewRunner(st.Experiment())
 	}
 
 	// Initialize error tracker
 	errorTracker := NewErrorTracker(100) // Keep last 100 errors
-	logger.Infof("✓ [%s] Error tracker initialized", config.Name)
+	errorTracker.SetTraderID(config.ID)
+	log.Infof("✓ [%s] Error tracker initialized", config.Name)
 
 	return &AutoTrader{
 		id:                         config.ID,
@@ -361,6 +438,8 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 		enhancedSetup:              enhancedSetup,
 		orderDedupManager:          orderDedupManager,
 		errorTracker:               errorTracker,
+		debateEngine:               debateEngine,
+		experiments:                experiments,
 		cycleNumber:                cycleNumber,
 		initialBalance:             config.InitialBalance,
 		lastResetTime:              time.Now(),
@@ -379,6 +458,15 @@ func NewAutoTrader(config AutoTraderConfig, st *store.Store, userID string) (*Au
 	}, nil
 }
 
+// log returns the structured log entry of this trader (trader_id, plus the cycle once one has run);
+// lines logged through it also land in the trader's own log file
+func (at *AutoTrader) log() *logrus.Entry {
+	if e := at.logEntry.Load(); e != nil {
+		return e
+	}
+	return logger.ForTrader(at.id)
+}
+
 // Run runs the automatic trading main loop
 func (at *AutoTrader) Run() error {
 	at.isRunningMutex.Lock()
@@ -388,45 +476,27 @@ func (at *AutoTrader) Run() error {
 	at.stopMonitorCh = make(chan struct{})
 	at.startTime = time.Now()
 
-	logger.Info("🚀 AI-driven automatic trading system started")
-	logger.Infof("💰 Initial balance: %.2f USDT", at.initialBalance)
-	logger.Infof("⚙️  Scan interval: %v", at.config.ScanInterval)
-	logger.Info("🤖 AI will make full decisions on leverage, position size, stop loss/take profit, etc.")
+	metrics.TraderRunning.Set(1, at.id, at.exchange)
+	defer metrics.TraderRunning.Set(0, at.id, at.exchange)
+
+	at.log().Info("🚀 AI-driven automatic trading system started")
+	at.log().Infof("💰 Initial balance: %.2f USDT", at.initialBalance)
+	at.log().Infof("⚙️  Scan interval: %v", at.config.ScanInterval)
+	at.log().Info("🤖 AI will make full decisions on leverage, position size, stop loss/take profit, etc.")
 	at.monitorWg.Add(1)
 	defer at.monitorWg.Done()
 
 	// Start drawdown monitoring
 	at.startDrawdownMonitor()
 
+	// Start real-time account stream (Binance, Bybit, OKX, Hyperliquid), falls back to REST polling while disconnected
+	at.startAccountSync()
+
 	// Start Lighter order sync if using Lighter exchange
 	if at.exchange == "lighter" {
 		if lighterTrader, ok := at.trader.(*LighterTraderV2); ok && at.store != nil {
 			lighterTrader.StartOrderSync(at.id, at.exchangeID, at.exchange, at.store, 30*time.Second)
-			logger.Infof("🔄 [%s] Lighter order+position sync enabled (every 30s)", at.name)
-		}
-	}
-
-	// Start Hyperliquid order sync if using Hyperliquid exchange
-	if at.exchange == "hyperliquid" {
-		if hyperliquidTrader, ok := at.trader.(*HyperliquidTrader); ok && at.store != nil {
-			hyperliquidTrader.StartOrderSync(at.id, at.exchangeID, at.exchange, at.store, 30*time.Second)
-			logger.Infof("🔄 [%s] Hyperliquid order+position sync enabled (every 30s)", at.name)
-		}
-	}
-
-	// Start Bybit order sync if using Bybit exchange
-	if at.exchange == "bybit" {
-		if bybitTrader, ok := at.trader.(*BybitTrader); ok && at.store != nil {
-			bybitTrader.StartOrderSync(at.id, at.exchangeID, at.exchange, at.store, 30*time.Second)
-			logger.Infof("🔄 [%s] Bybit order+position sync enabled (every 30s)", at.name)
-		}
-	}
-
-	// Start OKX order sync if using OKX exchange
-	if at.exchange == "okx" {
-		if okxTrader, ok := at.trader.(*OKXTrader); ok && at.store != nil {
-			okxTrader.StartOrderSync(at.id, at.exchangeID, at.exchange, at.store, 30*time.Second)
-			logger.Infof("🔄 [%s] OKX order+position sync enabled (every 30s)", at.name)
+			at.log().Infof("🔄 [%s] Lighter order+position sync enabled (every 30s)", at.name)
 		}
 	}
 
@@ -434,7 +504,7 @@ func (at *AutoTrader) Run() error {
 	if at.exchange == "bitget" {
 		if bitgetTrader, ok := at.trader.(*BitgetTrader); ok && at.store != nil {
 			bitgetTrader.StartOrderSync(at.id, at.exchangeID, at.exchange, at.store, 30*time.Second)
-			logger.Infof("🔄 [%s] Bitget order+position sync enabled (every 30s)", at.name)
+			at.log().Infof("🔄 [%s] Bitget order+position sync enabled (every 30s)", at.name)
 		}
 	}
 
@@ -442,15 +512,7 @@ func (at *AutoTrader) Run() error {
 	if at.exchange == "aster" {
 		if asterTrader, ok := at.trader.(*AsterTrader); ok && at.store != nil {
 			asterTrader.StartOrderSync(at.id, at.exchangeID, at.exchange, at.store, 30*time.Second)
-			logger.Infof("🔄 [%s] Aster order+position sync enabled (every 30s)", at.name)
-		}
-	}
-
-	// Start Binance order sync if using Binance exchange
-	if at.exchange == "binance" {
-		if binanceTrader, ok := at.trader.(*FuturesTrader); ok && at.store != nil {
-			binanceTrader.StartOrderSync(at.id, at.exchangeID, at.exchange, at.store, 30*time.Second)
-			logger.Infof("🔄 [%s] Binance order+position sync enabled (every 30s)", at.name)
+			at.log().Infof("🔄 [%s] Aster order+position sync enabled (every 30s)", at.name)
 		}
 	}
 
@@ -464,38 +526,41 @@ func (at *AutoTrader) Run() error {
 
 		// 启动WebSocket监控器
 		if err := wsMonitor.Start(); err != nil {
-			logger.Errorf("❌ Failed to start WebSocket monitor: %v", err)
+			at.log().Errorf("❌ Failed to start WebSocket monitor: %v", err)
 			return
 		}
 
+		// Market events launch out-of-band decision cycles between scans
+		at.startEventTriggers(wsMonitor)
+
 		// 注册触发回调 - 当订单触发时执行交易
 		wsMonitor.RegisterTriggerCallback("global", func(order *store.PendingOrder, currentPrice float64) {
-			logger.Infof("🎯 WebSocket触发订单: %s @ %.2f (触发价: %.2f)",
+			at.log().Infof("🎯 WebSocket触发订单: %s @ %.2f (触发价: %.2f)",
 				order.Symbol, currentPrice, order.TriggerPrice)
 
 			// 原子地尝试标记为执行中（防止重复执行）
 			if !at.store.Analysis().TryMarkAsExecuting(order.ID) {
-				logger.Warnf("⚠️ Order already executing or completed: %s (ID: %d)", order.Symbol, order.ID)
+				at.log().Warnf("⚠️ Order already executing or completed: %s (ID: %s)", order.Symbol, order.ID)
 				return
 			}
 
 			// 执行订单
 			if err := at.executePendingOrder(order, currentPrice); err != nil {
-				logger.Errorf("❌ WebSocket订单执行失败: %v", err)
+				at.log().Errorf("❌ WebSocket订单执行失败: %v", err)
 				// 记录失败，增加重试计数
 				at.recordPendingOrderFailure(order.ID, err)
 				// 取消执行标记，允许重试
 				if cancelErr := at.store.Analysis().CancelExecution(order.ID); cancelErr != nil {
-					logger.Warnf("⚠️ Failed to cancel execution flag: %v", cancelErr)
+					at.log().Warnf("⚠️ Failed to cancel execution flag: %v", cancelErr)
 				}
 				return
 			}
 
 			// 标记为已执行
 			if err := at.store.Analysis().MarkAsExecuted(order.ID); err != nil {
-				logger.Warnf("⚠️ Failed to mark order as executed: %v", err)
+				at.log().Warnf("⚠️ Failed to mark order as executed: %v", err)
 			} else {
-				logger.Infof("✅ WebSocket订单执行成功: %s", order.Symbol)
+				at.log().Infof("✅ WebSocket订单执行成功: %s", order.Symbol)
 			}
 		})
 
@@ -507,19 +572,19 @@ func (at *AutoTrader) Run() error {
 		cleanupTicker := time.NewTicker(5 * time.Minute)
 		defer cleanupTicker.Stop()
 
-		logger.Info("🔄 WebSocket real-time monitoring started (毫秒级触发)")
+		at.log().Info("🔄 WebSocket real-time monitoring started (毫秒级触发)")
 
 		for {
 			select {
 			case <-subscriptionTicker.C:
 				// 更新WebSocket订阅以包含新订单
 				if err := wsMonitor.UpdatePendingOrdersWithWebSocket(); err != nil {
-					logger.Warnf("⚠️ Failed to update WebSocket subscriptions: %v", err)
+					at.log().Warnf("⚠️ Failed to update WebSocket subscriptions: %v", err)
 				}
 
 				// 显示订阅统计
 				stats := wsMonitor.GetSubscriptionStats()
-				logger.Infof("📊 WebSocket stats: %d subscriptions, %d callbacks",
+				at.log().Infof("📊 WebSocket stats: %d subscriptions, %d callbacks",
 					stats["total_subscriptions"], stats["total_callbacks"])
 
 			case <-cleanupTicker.C:
@@ -530,19 +595,19 @@ func (at *AutoTrader) Run() error {
 					duration := time.Since(startTime)
 
 					if err != nil {
-						logger.Warnf("⚠️ Auto cleanup failed: %v", err)
+						at.log().Warnf("⚠️ Auto cleanup failed: %v", err)
 					} else {
 						duplicatesCleaned := results["duplicates_cleaned"].(int)
 						expiredCleaned := results["expired_cleaned"].(int)
 						if duplicatesCleaned > 0 || expiredCleaned > 0 {
-							logger.Infof("🧹 Auto cleanup: %d duplicates, %d expired orders cleaned (duration: %v)",
+							at.log().Infof("🧹 Auto cleanup: %d duplicates, %d expired orders cleaned (duration: %v)",
 								duplicatesCleaned, expiredCleaned, duration)
 						}
 					}
 				}
 			case <-at.stopMonitorCh:
 				wsMonitor.Stop()
-				logger.Info("⏹ Stopped WebSocket real-time monitoring")
+				at.log().Info("⏹ Stopped WebSocket real-time monitoring")
 				return
 			}
 		}
@@ -555,17 +620,17 @@ func (at *AutoTrader) Run() error {
 		monitorTicker := time.NewTicker(30 * time.Second)
 		defer monitorTicker.Stop()
 
-		logger.Info("🔄 Legacy polling monitoring started (backup, 30 seconds)")
+		at.log().Info("🔄 Legacy polling monitoring started (backup, 30 seconds)")
 
 		for {
 			select {
 			case <-monitorTicker.C:
 				// 仅在WebSocket不可用时执行
 				if err := at.MonitorAndExecutePendingOrders(); err != nil {
-					logger.Warnf("⚠️ Error monitoring pending orders: %v", err)
+					at.log().Warnf("⚠️ Error monitoring pending orders: %v", err)
 				}
 			case <-at.stopMonitorCh:
-				logger.Info("⏹ Stopped legacy polling monitoring")
+				at.log().Info("⏹ Stopped legacy polling monitoring")
 				return
 			}
 		}
@@ -576,7 +641,7 @@ func (at *AutoTrader) Run() error {
 
 	// Execute immediately on first run
 	if err := at.runCycle(); err != nil {
-		logger.Infof("❌ Execution failed: %v", err)
+		at.log().Infof("❌ Execution failed: %v", err)
 	}
 
 	for {
@@ -591,10 +656,10 @@ func (at *AutoTrader) Run() error {
 		select {
 		case <-ticker.C:
 			if err := at.runCycle(); err != nil {
-				logger.Infof("❌ Execution failed: %v", err)
+				at.log().Infof("❌ Execution failed: %v", err)
 			}
 		case <-at.stopMonitorCh:
-			logger.Infof("[%s] ⏹ Stop signal received, exiting automatic trading main loop", at.name)
+			at.log().Infof("[%s] ⏹ Stop signal received, exiting automatic trading main loop", at.name)
 			return nil
 		}
 	}
@@ -614,23 +679,46 @@ func (at *AutoTrader) Stop() {
 
 	close(at.stopMonitorCh) // Notify monitoring goroutine to stop
 	at.monitorWg.Wait()     // Wait for monitoring goroutine to finish
-	logger.Info("⏹ Automatic trading system stopped")
+	at.log().Info("⏹ Automatic trading system stopped")
 }
 
 // runCycle runs one trading cycle (using AI full decision-making)
 func (at *AutoTrader) runCycle() error {
+	return at.runCycleWithEvent(nil)
+}
+
+// runCycleWithEvent runs one trading cycle, event is the market event that launched it (nil for scheduled cycles)
+func (at *AutoTrader) runCycleWithEvent(event *store.MarketEvent) (err error) {
+	at.cycleMu.Lock()
+	defer at.cycleMu.Unlock()
+
+	metrics.DecisionCycles.Inc(at.id, at.exchange)
+	defer func() {
+		if err != nil {
+			metrics.DecisionCycleFailures.Inc(at.id, at.exchange)
+		}
+	}()
+
+	if event == nil && at.events != nil {
+		at.events.budget.MarkCycle(time.Now())
+	}
+
 	at.callCount++
+	at.logEntry.Store(logger.ForTrader(at.id).WithField(logger.FieldCycle, at.callCount))
 
-	logger.Info("\n" + strings.Repeat("=", 70) + "\n")
-	logger.Infof("⏰ %s - AI decision cycle #%d", time.Now().Format("2006-01-02 15:04:05"), at.callCount)
-	logger.Info(strings.Repeat("=", 70))
+	at.log().Info("\n" + strings.Repeat("=", 70) + "\n")
+	at.log().Infof("⏰ %s - AI decision cycle #%d", time.Now().Format("2006-01-02 15:04:05"), at.callCount)
+	if event != nil {
+		at.log().Infof("⚡ Triggered by market event: %s", event.Message)
+	}
+	at.log().Info(strings.Repeat("=", 70))
 
 	// 0. Check if trader is stopped (early exit to prevent trades after Stop() is called)
 	at.isRunningMutex.RLock()
 	running := at.isRunning
 	at.isRunningMutex.RUnlock()
 	if !running {
-		logger.Infof("⏹ Trader is stopped, aborting cycle #%d", at.callCount)
+		at.log().Infof("⏹ Trader is stopped, aborting cycle #%d", at.callCount)
 		return nil
 	}
 
@@ -638,12 +726,13 @@ func (at *AutoTrader) runCycle() error {
 	record := &store.DecisionRecord{
 		ExecutionLog: []string{},
 		Success:      true,
+		TriggerEvent: event,
 	}
 
 	// 1. Check if trading needs to be stopped
 	if time.Now().Before(at.stopUntil) {
 		remaining := at.stopUntil.Sub(time.Now())
-		logger.Infof("⏸ Risk control: Trading paused, remaining %.0f minutes", remaining.Minutes())
+		at.log().Infof("⏸ Risk control: Trading paused, remaining %.0f minutes", remaining.Minutes())
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("Risk control paused, remaining %.0f minutes", remaining.Minutes())
 		at.saveDecision(record)
@@ -654,7 +743,7 @@ func (at *AutoTrader) runCycle() error {
 	if time.Since(at.lastResetTime) > 24*time.Hour {
 		at.dailyPnL = 0
 		at.lastResetTime = time.Now()
-		logger.Info("📅 Daily P&L reset")
+		at.log().Info("📅 Daily P&L reset")
 	}
 
 	// 4. Collect trading context
@@ -665,6 +754,7 @@ func (at *AutoTrader) runCycle() error {
 		at.saveDecision(record)
 		return fmt.Errorf("failed to build trading context: %w", err)
 	}
+	ctx.TriggerEvent = event
 
 	// Save equity snapshot independently (decoupled from AI decision, used for drawing profit curve)
 	at.saveEquitySnapshot(ctx)
@@ -674,31 +764,56 @@ func (at *AutoTrader) runCycle() error {
 	// ========================================
 	at.updateDynamicStopLoss(ctx)
 
-	logger.Info(strings.Repeat("=", 70))
+	at.log().Info(strings.Repeat("=", 70))
 	for _, coin := range ctx.CandidateCoins {
 		record.CandidateCoins = append(record.CandidateCoins, coin.Symbol)
 	}
 
-	logger.Infof("📊 Account equity: %.2f USDT | Available: %.2f USDT | Positions: %d",
+	at.log().Infof("📊 Account equity: %.2f USDT | Available: %.2f USDT | Positions: %d",
 		ctx.Account.TotalEquity, ctx.Account.AvailableBalance, ctx.Account.PositionCount)
 
-	// 5. Use strategy engine to call AI for decision
-	logger.Infof("🤖 Requesting AI analysis and decision... [Strategy Engine]")
-	aiDecision, err := kernel.GetFullDecisionWithStrategy(ctx, at.mcpClient, at.strategyEngine, "balanced")
+	// 5. Use strategy engine to call AI for decision (or let the debate panel decide)
+	var aiDecision *kernel.FullDecision
+	if at.debateEngine != nil {
+		at.log().Infof("🗣️ Requesting debate panel consensus... [Debate Mode]")
+		aiDecision, err = at.getDebateDecision(ctx, record)
+	} else {
+		at.log().Infof("🤖 Requesting AI analysis and decision... [Strategy Engine]")
+		aiDecision, err = kernel.GetFullDecisionWithStrategy(ctx, at.mcpClient, at.strategyEngine, "balanced")
+	}
 
 	if aiDecision != nil && aiDecision.AIRequestDurationMs > 0 {
 		record.AIRequestDurationMs = aiDecision.AIRequestDurationMs
-		logger.Infof("⏱️ AI call duration: %.2f seconds", float64(record.AIRequestDurationMs)/1000)
+		at.log().Infof("⏱️ AI call duration: %.2f seconds", float64(record.AIRequestDurationMs)/1000)
 		record.ExecutionLog = append(record.ExecutionLog,
 			fmt.Sprintf("AI call duration: %d ms", record.AIRequestDurationMs))
 	}
 
+	// Data tools the AI called and the repair re-ask, if any
+	if aiDecision != nil {
+		for _, call := range aiDecision.ToolCalls {
+			entry := fmt.Sprintf("AI tool call: %s(%s) %d ms", call.Name, call.Arguments, call.DurationMs)
+			if call.Error != "" {
+				entry += " failed: " + call.Error
+			}
+			record.ExecutionLog = append(record.ExecutionLog, entry)
+		}
+		if aiDecision.AIModel != "" && len(at.config.FallbackAIModels) > 0 {
+			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("AI model: %s", aiDecision.AIModel))
+		}
+		if aiDecision.RepairError != "" {
+			record.ExecutionLog = append(record.ExecutionLog,
+				fmt.Sprintf("AI decision repair requested: %s", aiDecision.RepairError))
+		}
+	}
+
 	// Save chain of thought, decisions, and input prompt even if there's an error (for debugging)
 	if aiDecision != nil {
 		record.SystemPrompt = aiDecision.SystemPrompt // Save system prompt
 		record.InputPrompt = aiDecision.UserPrompt
 		record.CoTTrace = aiDecision.CoTTrace
 		record.RawResponse = aiDecision.RawResponse // Save raw AI response for debugging
+		record.AIModel = aiDecision.AIModel
 		if len(aiDecision.Decisions) > 0 {
 			decisionJSON, _ := json.MarshalIndent(aiDecision.Decisions, "", "  ")
 			record.DecisionJSON = string(decisionJSON)
@@ -711,18 +826,18 @@ func (at *AutoTrader) runCycle() error {
 
 		// Print system prompt and AI chain of thought (output even with errors for debugging)
 		if aiDecision != nil {
-			logger.Info("\n" + strings.Repeat("=", 70) + "\n")
-			logger.Infof("📋 System prompt (error case)")
-			logger.Info(strings.Repeat("=", 70))
-			logger.Info(aiDecision.SystemPrompt)
-			logger.Info(strings.Repeat("=", 70))
+			at.log().Info("\n" + strings.Repeat("=", 70) + "\n")
+			at.log().Infof("📋 System prompt (error case)")
+			at.log().Info(strings.Repeat("=", 70))
+			at.log().Info(aiDecision.SystemPrompt)
+			at.log().Info(strings.Repeat("=", 70))
 
 			if aiDecision.CoTTrace != "" {
-				logger.Info("\n" + strings.Repeat("-", 70) + "\n")
-				logger.Info("💭 AI chain of thought analysis (error case):")
-				logger.Info(strings.Repeat("-", 70))
-				logger.Info(aiDecision.CoTTrace)
-				logger.Info(strings.Repeat("-", 70))
+				at.log().Info("\n" + strings.Repeat("-", 70) + "\n")
+				at.log().Info("💭 AI chain of thought analysis (error case):")
+				at.log().Info(strings.Repeat("-", 70))
+				at.log().Info(aiDecision.CoTTrace)
+				at.log().Info(strings.Repeat("-", 70))
 			}
 		}
 
@@ -731,38 +846,38 @@ func (at *AutoTrader) runCycle() error {
 	}
 
 	// // 5. Print system prompt
-	// logger.Infof("\n" + strings.Repeat("=", 70))
-	// logger.Infof("📋 System prompt [template: %s]", at.systemPromptTemplate)
-	// logger.Info(strings.Repeat("=", 70))
-	// logger.Info(decision.SystemPrompt)
-	// logger.Infof(strings.Repeat("=", 70) + "\n")
+	// at.log().Infof("\n" + strings.Repeat("=", 70))
+	// at.log().Infof("📋 System prompt [template: %s]", at.systemPromptTemplate)
+	// at.log().Info(strings.Repeat("=", 70))
+	// at.log().Info(decision.SystemPrompt)
+	// at.log().Infof(strings.Repeat("=", 70) + "\n")
 
 	// 6. Print AI chain of thought
-	// logger.Infof("\n" + strings.Repeat("-", 70))
-	// logger.Info("💭 AI chain of thought analysis:")
-	// logger.Info(strings.Repeat("-", 70))
-	// logger.Info(decision.CoTTrace)
-	// logger.Infof(strings.Repeat("-", 70) + "\n")
+	// at.log().Infof("\n" + strings.Repeat("-", 70))
+	// at.log().Info("💭 AI chain of thought analysis:")
+	// at.log().Info(strings.Repeat("-", 70))
+	// at.log().Info(decision.CoTTrace)
+	// at.log().Infof(strings.Repeat("-", 70) + "\n")
 
 	// 7. Print AI decisions
-	// logger.Infof("📋 AI decision list (%d items):\n", len(kernel.Decisions))
+	// at.log().Infof("📋 AI decision list (%d items):\n", len(kernel.Decisions))
 	// for i, d := range kernel.Decisions {
-	//     logger.Infof("  [%d] %s: %s - %s", i+1, d.Symbol, d.Action, d.Reasoning)
+	//     at.log().Infof("  [%d] %s: %s - %s", i+1, d.Symbol, d.Action, d.Reasoning)
 	//     if d.Action == "open_long" || d.Action == "open_short" {
-	//        logger.Infof("      Leverage: %dx | Position: %.2f USDT | Stop loss: %.4f | Take profit: %.4f",
+	//        at.log().Infof("      Leverage: %dx | Position: %.2f USDT | Stop loss: %.4f | Take profit: %.4f",
 	//           d.Leverage, d.PositionSizeUSD, d.StopLoss, d.TakeProfit)
 	//     }
 	// }
-	logger.Info()
-	logger.Info(strings.Repeat("-", 70))
+	at.log().Info()
+	at.log().Info(strings.Repeat("-", 70))
 	// 8. Sort decisions: ensure close positions first, then open positions (prevent position stacking overflow)
-	logger.Info(strings.Repeat("-", 70))
+	at.log().Info(strings.Repeat("-", 70))
 
 	// Option B: Apply parameter optimization and risk management
 	if at.enhancedSetup != nil {
 		// Validate risk limits first
 		if allowed, reason := at.enhancedSetup.ValidateRiskLimits(); !allowed {
-			logger.Warnf("⚠️ Risk control triggered: %s", reason)
+			at.log().Warnf("⚠️ Risk control triggered: %s", reason)
 			record.Success = false
 			record.ErrorMessage = fmt.Sprintf("Risk control: %s", reason)
 			at.saveDecision(record)
@@ -786,7 +901,7 @@ func (at *AutoTrader) runCycle() error {
 
 			d.Confidence = int(adjustedConfidence)
 
-			logger.Infof("🔧 [%s] Parameters optimized: confidence %d → %d",
+			at.log().Infof("🔧 [%s] Parameters optimized: confidence %d → %d",
 				d.Symbol, int(float64(d.Confidence)/0.9), d.Confidence)
 		}
 	}
@@ -794,28 +909,28 @@ func (at *AutoTrader) runCycle() error {
 	// 8. Sort decisions: ensure close positions first, then open positions (prevent position stacking overflow)
 	sortedDecisions := sortDecisionsByPriority(aiDecision.Decisions)
 
-	logger.Info("🔄 Execution order (optimized): Close positions first → Open positions later")
+	at.log().Info("🔄 Execution order (optimized): Close positions first → Open positions later")
 	for i, d := range sortedDecisions {
-		logger.Infof("  [%d] %s %s", i+1, d.Symbol, d.Action)
+		at.log().Infof("  [%d] %s %s", i+1, d.Symbol, d.Action)
 	}
-	logger.Info()
+	at.log().Info()
 
 	// Check if trader is stopped before executing any decisions (prevent trades after Stop())
 	at.isRunningMutex.RLock()
 	running = at.isRunning
 	at.isRunningMutex.RUnlock()
 	if !running {
-		logger.Infof("⏹ Trader stopped before decision execution, aborting cycle #%d", at.callCount)
+		at.log().Infof("⏹ Trader stopped before decision execution, aborting cycle #%d", at.callCount)
 		return nil
 	}
 
 	// NEW: Save AI analysis and create pending orders (延迟执行模式)
-	logger.Info("🔄 NEW WORKFLOW: Saving AI analysis → Waiting for price triggers → Auto-executing")
+	at.log().Info("🔄 NEW WORKFLOW: Saving AI analysis → Waiting for price triggers → Auto-executing")
 
 	// 检查是否已存在同币种的PENDING订单，避免重复创建
 	existingOrders, err := at.store.Analysis().GetPendingOrdersByTrader(at.id)
 	if err != nil {
-		logger.Warnf("⚠️ Failed to get existing pending orders: %v", err)
+		at.log().Warnf("⚠️ Failed to get existing pending orders: %v", err)
 	}
 
 	// 创建现有订单映射
@@ -836,11 +951,11 @@ func (at *AutoTrader) runCycle() error {
 				existingConfidence := existingOrder.Confidence * 100
 
 				if currentConfidence > existingConfidence {
-					logger.Infof("🔄 将替换同币种订单: %s (置信度 %.2f%% → %.2f%%)",
+					at.log().Infof("🔄 将替换同币种订单: %s (置信度 %.2f%% → %.2f%%)",
 						d.Symbol, existingConfidence, currentConfidence)
 					filteredDecisions = append(filteredDecisions, d)
 				} else {
-					logger.Infof("⏭️ 跳过 %s: 已有更优订单 (%.2f%% > %.2f%%)",
+					at.log().Infof("⏭️ 跳过 %s: 已有更优订单 (%.2f%% > %.2f%%)",
 						d.Symbol, existingConfidence, currentConfidence)
 				}
 			} else {
@@ -866,9 +981,9 @@ func (at *AutoTrader) runCycle() error {
 		}
 
 		if err := at.SaveAnalysisAndCreatePendingOrders(filteredAIDecision); err != nil {
-			logger.Warnf("⚠️ Failed to save analysis or create pending orders: %v", err)
+			at.log().Warnf("⚠️ Failed to save analysis or create pending orders: %v", err)
 		} else {
-			logger.Infof("✅ AI analysis saved and pending orders created (filtered: %d decisions)", len(filteredDecisions))
+			at.log().Infof("✅ AI analysis saved and pending orders created (filtered: %d decisions)", len(filteredDecisions))
 			// Record decision actions for audit
 			for _, d := range filteredDecisions {
 				actionRecord := store.DecisionAction{
@@ -886,12 +1001,15 @@ func (at *AutoTrader) runCycle() error {
 			}
 		}
 	} else {
-		logger.Info("⏭️ No new pending orders to create (all filtered by existing orders)")
+		at.log().Info("⏭️ No new pending orders to create (all filtered by existing orders)")
 	}
 
+	// Record the shadow variants of a running experiment on this cycle's context
+	at.runExperimentCycle(ctx, aiDecision.Decisions, record)
+
 	// 9. Save decision record
 	if err := at.saveDecision(record); err != nil {
-		logger.Infof("⚠ Failed to save decision record: %v", err)
+		at.log().Infof("⚠ Failed to save decision record: %v", err)
 	}
 
 	return nil
@@ -1048,7 +1166,7 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to get candidate coins: %w", err)
 	}
-	logger.Infof("📋 [%s] Strategy engine fetched candidate coins: %d", at.name, len(candidateCoins))
+	at.log().Infof("📋 [%s] Strategy engine fetched candidate coins: %d", at.name, len(candidateCoins))
 
 	// 4. Calculate total P&L
 	totalPnL := totalEquity - at.initialBalance
@@ -1066,7 +1184,7 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 	strategyConfig := at.strategyEngine.GetConfig()
 	btcEthLeverage := strategyConfig.RiskControl.BTCETHMaxLeverage
 	altcoinLeverage := strategyConfig.RiskControl.AltcoinMaxLeverage
-	logger.Infof("📋 [%s] Strategy leverage config: BTC/ETH=%dx, Altcoin=%dx", at.name, btcEthLeverage, altcoinLeverage)
+	at.log().Infof("📋 [%s] Strategy leverage config: BTC/ETH=%dx, Altcoin=%dx", at.name, btcEthLeverage, altcoinLeverage)
 
 	// 6. Build context
 	ctx := &kernel.Context{
@@ -1094,9 +1212,9 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 		// Get recent 10 closed trades for AI context
 		recentTrades, err := at.store.Position().GetRecentTrades(at.id, 10)
 		if err != nil {
-			logger.Infof("⚠️ [%s] Failed to get recent trades: %v", at.name, err)
+			at.log().Infof("⚠️ [%s] Failed to get recent trades: %v", at.name, err)
 		} else {
-			logger.Infof("📊 [%s] Found %d recent closed trades for AI context", at.name, len(recentTrades))
+			at.log().Infof("📊 [%s] Found %d recent closed trades for AI context", at.name, len(recentTrades))
 			for _, trade := range recentTrades {
 				// Convert Unix timestamps to formatted strings for AI readability
 				entryTimeStr := ""
@@ -1121,14 +1239,18 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 				})
 			}
 		}
+		// Closed trades per symbol, pulled by the AI with the get_position_history tool
+		ctx.PositionHistory = func(symbol string, limit int) ([]store.RecentTrade, error) {
+			return at.store.Position().GetRecentTradesBySymbol(at.id, symbol, limit)
+		}
 		// Get trading statistics for AI context
 		stats, err := at.store.Position().GetFullStats(at.id)
 		if err != nil {
-			logger.Infof("⚠️ [%s] Failed to get trading stats: %v", at.name, err)
+			at.log().Infof("⚠️ [%s] Failed to get trading stats: %v", at.name, err)
 		} else if stats == nil {
-			logger.Infof("⚠️ [%s] GetFullStats returned nil", at.name)
+			at.log().Infof("⚠️ [%s] GetFullStats returned nil", at.name)
 		} else if stats.TotalTrades == 0 {
-			logger.Infof("⚠️ [%s] GetFullStats returned 0 trades (traderID=%s)", at.name, at.id)
+			at.log().Infof("⚠️ [%s] GetFullStats returned 0 trades (traderID=%s)", at.name, at.id)
 		} else {
 			ctx.TradingStats = &kernel.TradingStats{
 				TotalTrades:    stats.TotalTrades,
@@ -1140,11 +1262,11 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 				AvgLoss:        stats.AvgLoss,
 				MaxDrawdownPct: stats.MaxDrawdownPct,
 			}
-			logger.Infof("📈 [%s] Trading stats: %d trades, %.1f%% win rate, PF=%.2f, Sharpe=%.2f, DD=%.1f%%",
+			at.log().Infof("📈 [%s] Trading stats: %d trades, %.1f%% win rate, PF=%.2f, Sharpe=%.2f, DD=%.1f%%",
 				at.name, stats.TotalTrades, stats.WinRate, stats.ProfitFactor, stats.SharpeRatio, stats.MaxDrawdownPct)
 		}
 	} else {
-		logger.Infof("⚠️ [%s] Store is nil, cannot get recent trades", at.name)
+		at.log().Infof("⚠️ [%s] Store is nil, cannot get recent trades", at.name)
 	}
 
 	// 8. Get quantitative data (if enabled in strategy config)
@@ -1163,67 +1285,133 @@ func (at *AutoTrader) buildTradingContext() (*kernel.Context, error) {
 			symbols = append(symbols, sym)
 		}
 
-		logger.Infof("📊 [%s] Fetching quantitative data for %d symbols...", at.name, len(symbols))
+		at.log().Infof("📊 [%s] Fetching quantitative data for %d symbols...", at.name, len(symbols))
 		ctx.QuantDataMap = at.strategyEngine.FetchQuantDataBatch(symbols)
-		logger.Infof("📊 [%s] Successfully fetched quantitative data for %d symbols", at.name, len(ctx.QuantDataMap))
+		at.log().Infof("📊 [%s] Successfully fetched quantitative data for %d symbols", at.name, len(ctx.QuantDataMap))
 	}
 
 	// 9. Get OI ranking data (market-wide position changes)
 	if strategyConfig.Indicators.EnableOIRanking {
-		logger.Infof("📊 [%s] Fetching OI ranking data...", at.name)
+		at.log().Infof("📊 [%s] Fetching OI ranking data...", at.name)
 		ctx.OIRankingData = at.strategyEngine.FetchOIRankingData()
 		if ctx.OIRankingData != nil {
-			logger.Infof("📊 [%s] OI ranking data ready: %d top, %d low positions",
+			at.log().Infof("📊 [%s] OI ranking data ready: %d top, %d low positions",
 				at.name, len(ctx.OIRankingData.TopPositions), len(ctx.OIRankingData.LowPositions))
 		}
 	}
 
 	// 10. Get NetFlow ranking data (market-wide fund flow)
 	if strategyConfig.Indicators.EnableNetFlowRanking {
-		logger.Infof("💰 [%s] Fetching NetFlow ranking data...", at.name)
+		at.log().Infof("💰 [%s] Fetching NetFlow ranking data...", at.name)
 		ctx.NetFlowRankingData = at.strategyEngine.FetchNetFlowRankingData()
 		if ctx.NetFlowRankingData != nil {
-			logger.Infof("💰 [%s] NetFlow ranking data ready: inst_in=%d, inst_out=%d",
+			at.log().Infof("💰 [%s] NetFlow ranking data ready: inst_in=%d, inst_out=%d",
 				at.name, len(ctx.NetFlowRankingData.InstitutionFutureTop), len(ctx.NetFlowRankingData.InstitutionFutureLow))
 		}
 	}
 
 	// 11. Get Price ranking data (market-wide gainers/losers)
 	if strategyConfig.Indicators.EnablePriceRanking {
-		logger.Infof("📈 [%s] Fetching Price ranking data...", at.name)
+		at.log().Infof("📈 [%s] Fetching Price ranking data...", at.name)
 		ctx.PriceRankingData = at.strategyEngine.FetchPriceRankingData()
 		if ctx.PriceRankingData != nil {
-			logger.Infof("📈 [%s] Price ranking data ready for %d durations",
+			at.log().Infof("📈 [%s] Price ranking data ready for %d durations",
 				at.name, len(ctx.PriceRankingData.Durations))
 		}
 	}
 
+	// 12. Get derivatives data (liquidations, long/short ratio, OI vs market cap)
+	if strategyConfig.Indicators.EnableLiquidationData || strategyConfig.Indicators.EnableLongShortRatio ||
+		strategyConfig.Indicators.EnableOIVsMarketCap {
+		symbols := make([]string, 0, len(candidateCoins)+len(positionInfos))
+		for _, coin := range candidateCoins {
+			symbols = append(symbols, coin.Symbol)
+		}
+		for _, pos := range positionInfos {
+			symbols = append(symbols, pos.Symbol)
+		}
+
+		at.log().Infof("🧨 [%s] Fetching derivatives data for %d symbols...", at.name, len(symbols))
+		ctx.DerivativesDataMap = at.strategyEngine.FetchDerivativesDataBatch(symbols)
+		at.log().Infof("🧨 [%s] Derivatives data ready for %d symbols", at.name, len(ctx.DerivativesDataMap))
+	}
+
+	// 13. Get order book depth (spread, ±1% depth, imbalance)
+	if strategyConfig.Indicators.EnableOrderBook {
+		symbols := make([]string, 0, len(candidateCoins)+len(positionInfos))
+		for _, coin := range candidateCoins {
+			symbols = append(symbols, coin.Symbol)
+		}
+		for _, pos := range positionInfos {
+			symbols = append(symbols, pos.Symbol)
+		}
+
+		ctx.OrderBookMap = at.strategyEngine.FetchOrderBookBatch(symbols)
+		at.log().Infof("📖 [%s] Order book data ready for %d symbols", at.name, len(ctx.OrderBookMap))
+	}
+
+	// 14. Trading sessions of stock/forex/commodity symbols (upcoming opens/closes)
+	sessionSymbols := make([]string, 0, len(candidateCoins)+len(positionInfos))
+	for _, coin := range candidateCoins {
+		sessionSymbols = append(sessionSymbols, coin.Symbol)
+	}
+	for _, pos := range positionInfos {
+		sessionSymbols = append(sessionSymbols, pos.Symbol)
+	}
+	ctx.MarketSessions = at.strategyEngine.BuildMarketSessions(sessionSymbols, time.Now())
+
 	return ctx, nil
 }
 
 // executeDecisionWithRecord executes AI decision and records detailed information
 func (at *AutoTrader) executeDecisionWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
+	var err error
 	switch decision.Action {
 	case "open_long":
-		return at.executeOpenLongWithRecord(decision, actionRecord)
+		err = at.executeOpenLongWithRecord(decision, actionRecord)
 	case "open_short":
-		return at.executeOpenShortWithRecord(decision, actionRecord)
+		err = at.executeOpenShortWithRecord(decision, actionRecord)
 	case "close_long":
-		return at.executeCloseLongWithRecord(decision, actionRecord)
+		err = at.executeCloseLongWithRecord(decision, actionRecord)
 	case "close_short":
-		return at.executeCloseShortWithRecord(decision, actionRecord)
+		err = at.executeCloseShortWithRecord(decision, actionRecord)
 	case "hold", "wait":
 		// No execution needed, just record
 		return nil
 	default:
 		return fmt.Errorf("unknown action: %s", decision.Action)
 	}
+
+	after := map[string]interface{}{
+		"symbol":      decision.Symbol,
+		"leverage":    decision.Leverage,
+		"quantity":    actionRecord.Quantity,
+		"price":       actionRecord.Price,
+		"order_id":    actionRecord.OrderID,
+		"stop_loss":   decision.StopLoss,
+		"take_profit": decision.TakeProfit,
+		"confidence":  decision.Confidence,
+	}
+	if err != nil {
+		after["error"] = err.Error()
+	}
+	at.recordAudit("trade."+decision.Action, nil, after)
+	return err
+}
+
+// recordOrderMetric counts an order sent to the exchange as placed or rejected
+func (at *AutoTrader) recordOrderMetric(action string, err error) {
+	if err != nil {
+		metrics.OrdersRejected.Inc(at.id, at.exchange, action)
+		return
+	}
+	metrics.OrdersPlaced.Inc(at.id, at.exchange, action)
 }
 
 // ExecuteDecision executes a trading decision from external sources (e.g., debate consensus)
 // This is a public method that can be called by other modules
 func (at *AutoTrader) ExecuteDecision(d *kernel.Decision) error {
-	logger.Infof("[%s] Executing external decision: %s %s", at.name, d.Action, d.Symbol)
+	at.log().Infof("[%s] Executing external decision: %s %s", at.name, d.Action, d.Symbol)
 
 	// Create a minimal action record for tracking
 	actionRecord := &store.DecisionAction{
@@ -1239,17 +1427,18 @@ func (at *AutoTrader) ExecuteDecision(d *kernel.Decision) error {
 	// Execute the decision
 	err := at.executeDecisionWithRecord(d, actionRecord)
 	if err != nil {
-		logger.Errorf("[%s] External decision execution failed: %v", at.name, err)
+		at.log().Errorf("[%s] External decision execution failed: %v", at.name, err)
 		return err
 	}
 
-	logger.Infof("[%s] External decision executed successfully: %s %s", at.name, d.Action, d.Symbol)
+	at.log().Infof("[%s] External decision executed successfully: %s %s", at.name, d.Action, d.Symbol)
 	return nil
 }
 
 // executeOpenLongWithRecord executes open long position and records detailed information
 func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
-	logger.Infof("  📈 Open long: %s", decision.Symbol)
+	log := at.log().WithField(logger.FieldSymbol, decision.Symbol)
+	log.Infof("  📈 Open long: %s", decision.Symbol)
 
 	// ⚠️ Get current positions for multiple checks
 	positions, err := at.trader.GetPositions()
@@ -1262,6 +1451,11 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 		return err
 	}
 
+	// [CODE ENFORCED] Underlying market must be open (stocks, forex, commodities)
+	if err := at.enforceMarketOpen(decision.Symbol); err != nil {
+		return err
+	}
+
 	// Check if there's already a position in the same symbol and direction
 	for _, pos := range positions {
 		if pos["symbol"] == decision.Symbol && pos["side"] == "long" {
@@ -1301,6 +1495,13 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 		decision.PositionSizeUSD = adjustedPositionSize
 	}
 
+	// [CODE ENFORCED] Portfolio exposure: net/gross, correlation clusters, same-direction count
+	exposureSize, err := at.enforcePortfolioExposure(positions, decision.Symbol, "long", decision.PositionSizeUSD, equity)
+	if err != nil {
+		return err
+	}
+	decision.PositionSizeUSD = exposureSize
+
 	// ⚠️ Auto-adjust position size if insufficient margin
 	// Formula: totalRequired = positionSize/leverage + positionSize*0.001 + positionSize/leverage*0.01
 	//        = positionSize * (1.01/leverage + 0.001)
@@ -1311,12 +1512,23 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 	if actualPositionSize > maxAffordablePositionSize {
 		// Use 98% of max to leave buffer for price fluctuation
 		adjustedSize := maxAffordablePositionSize * 0.98
-		logger.Infof("  ⚠️ Position size %.2f exceeds max affordable %.2f, auto-reducing to %.2f",
+		log.Infof("  ⚠️ Position size %.2f exceeds max affordable %.2f, auto-reducing to %.2f",
 			actualPositionSize, maxAffordablePositionSize, adjustedSize)
 		actualPositionSize = adjustedSize
 		decision.PositionSizeUSD = actualPositionSize
 	}
 
+	// [CODE ENFORCED] Slippage guard: split or reduce orders too large for the order book
+	orderSlices := at.enforceSlippageLimit(decision.Symbol, "long", actualPositionSize)
+	plannedSize := 0.0
+	for _, sliceUSD := range orderSlices {
+		plannedSize += sliceUSD
+	}
+	if plannedSize < actualPositionSize {
+		actualPositionSize = plannedSize
+		decision.PositionSizeUSD = actualPositionSize
+	}
+
 	// [CODE ENFORCED] Minimum position size check
 	if err := at.enforceMinPositionSize(decision.PositionSizeUSD); err != nil {
 		return err
@@ -1329,26 +1541,20 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 
 	// Set margin mode
 	if err := at.trader.SetMarginMode(decision.Symbol, at.config.IsCrossMargin); err != nil {
-		logger.Infof("  ⚠️ Failed to set margin mode: %v", err)
+		log.Infof("  ⚠️ Failed to set margin mode: %v", err)
 		// Continue execution, doesn't affect trading
 	}
 
-	// Open position
-	order, err := at.trader.OpenLong(decision.Symbol, quantity, decision.Leverage)
+	// Open position (one market order per slice), each order is recorded and confirmed
+	// 在 recordAndConfirmOrder 中会创建持仓，之后我们需要记录 TP/SL
+	quantity, err = at.openPositionInSlices(decision, "open_long", orderSlices, marketData.CurrentPrice, actionRecord)
 	if err != nil {
 		return err
 	}
+	actionRecord.Quantity = quantity
+	log = log.WithField(logger.FieldOrderID, actionRecord.OrderID)
 
-	// Record order ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
-
-	logger.Infof("  ✓ Position opened successfully, order ID: %v, quantity: %.4f", order["orderId"], quantity)
-
-	// Record order to database and poll for confirmation
-	// 在 recordAndConfirmOrder 中会创建持仓，之后我们需要记录 TP/SL
-	at.recordAndConfirmOrder(order, decision.Symbol, "open_long", quantity, marketData.CurrentPrice, decision.Leverage, 0, decision.TakeProfit, decision.StopLoss)
+	log.Infof("  ✓ Position opened successfully, order ID: %v, quantity: %.4f", actionRecord.OrderID, quantity)
 
 	// Record position opening time
 	posKey := decision.Symbol + "_long"
@@ -1362,29 +1568,29 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 	for i := 0; i < maxSLRetries; i++ {
 		slErr = at.trader.SetStopLoss(decision.Symbol, "LONG", quantity, decision.StopLoss)
 		if slErr == nil {
-			logger.Infof("  ✅ Stop loss set successfully for %s LONG at %.4f", decision.Symbol, decision.StopLoss)
+			log.Infof("  ✅ Stop loss set successfully for %s LONG at %.4f", decision.Symbol, decision.StopLoss)
 			break
 		}
 		if i < maxSLRetries-1 {
-			logger.Warnf("  ⚠️ Stop loss attempt %d/%d failed: %v, retrying...", i+1, maxSLRetries, slErr)
+			log.Warnf("  ⚠️ Stop loss attempt %d/%d failed: %v, retrying...", i+1, maxSLRetries, slErr)
 			time.Sleep(time.Duration(i+1) * time.Second) // Exponential backoff
 		}
 	}
 	if slErr != nil {
-		logger.Errorf("  🚨 Failed to set stop loss after %d retries: %v - Position without SL protection!", maxSLRetries, slErr)
+		log.Errorf("  🚨 Failed to set stop loss after %d retries: %v - Position without SL protection!", maxSLRetries, slErr)
 
 		// ✅ 改进的错误恢复机制
 		// 1. 对所有持仓执行紧急平仓（不仅限于低信心度）
-		logger.Warnf("  🚨 Initiating emergency close for unprotected position %s", decision.Symbol)
-		if closeErr := at.emergencyClosePosition(decision.Symbol, "long"); closeErr != nil {
-			logger.Errorf("  ❌ Emergency close failed: %v", closeErr)
+		log.Warnf("  🚨 Initiating emergency close for unprotected position %s", decision.Symbol)
+		if closeErr := at.emergencyClosePosition(decision.Symbol, "long", "stop loss could not be set"); closeErr != nil {
+			log.Errorf("  ❌ Emergency close failed: %v", closeErr)
 			// 2. 发送紧急警报
 			at.sendEmergencyAlert(decision.Symbol, "LONG", "止损设置失败且紧急平仓失败")
 		} else {
-			logger.Infof("  ✅ Emergency close succeeded for %s LONG", decision.Symbol)
+			log.Infof("  ✅ Emergency close succeeded for %s LONG", decision.Symbol)
 			// 3. 关闭关联的ASL记录
 			if err := at.store.AdaptiveStopLoss().CloseRecordBySymbol(at.id, decision.Symbol); err != nil {
-				logger.Warnf("  ⚠️ Failed to clean ASL record: %v", err)
+				log.Warnf("  ⚠️ Failed to clean ASL record: %v", err)
 			}
 		}
 		return fmt.Errorf("position closed due to SL setup failure and safety policy")
@@ -1396,21 +1602,21 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 	for i := 0; i < maxTPRetries; i++ {
 		tpErr = at.trader.SetTakeProfit(decision.Symbol, "LONG", quantity, decision.TakeProfit)
 		if tpErr == nil {
-			logger.Infof("  ✅ Take profit set successfully for %s LONG at %.4f", decision.Symbol, decision.TakeProfit)
+			log.Infof("  ✅ Take profit set successfully for %s LONG at %.4f", decision.Symbol, decision.TakeProfit)
 			break
 		}
 		if i < maxTPRetries-1 {
-			logger.Warnf("  ⚠️ Take profit attempt %d/%d failed: %v, retrying...", i+1, maxTPRetries, tpErr)
+			log.Warnf("  ⚠️ Take profit attempt %d/%d failed: %v, retrying...", i+1, maxTPRetries, tpErr)
 			time.Sleep(time.Duration(i+1) * time.Second) // Exponential backoff
 		}
 	}
 	if tpErr != nil {
-		logger.Warnf("  🚨 Failed to set take profit after %d retries: %v - Position opened without TP protection!", maxTPRetries, tpErr)
+		log.Warnf("  🚨 Failed to set take profit after %d retries: %v - Position opened without TP protection!", maxTPRetries, tpErr)
 		// Emergency close for low confidence positions without TP
 		if decision.Confidence < 60 {
-			logger.Warnf("  🚨 Low confidence (<%d%%) position without TP - executing emergency close!", 60)
-			if closeErr := at.emergencyClosePosition(decision.Symbol, "long"); closeErr != nil {
-				logger.Errorf("  ❌ Emergency close failed: %v", closeErr)
+			log.Warnf("  🚨 Low confidence (<%d%%) position without TP - executing emergency close!", 60)
+			if closeErr := at.emergencyClosePosition(decision.Symbol, "long", "low confidence position without take profit"); closeErr != nil {
+				log.Errorf("  ❌ Emergency close failed: %v", closeErr)
 			} else {
 				return fmt.Errorf("position closed due to TP setup failure")
 			}
@@ -1429,7 +1635,7 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 			decision.TakeProfit,
 			atrValue,
 		)
-		logger.Infof("  🛡️ Adaptive stop loss set for %s LONG (ATR: %.2f)", decision.Symbol, atrValue)
+		log.Infof("  🛡️ Adaptive stop loss set for %s LONG (ATR: %.2f)", decision.Symbol, atrValue)
 	}
 
 	return nil
@@ -1437,7 +1643,8 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *kernel.Decision, actio
 
 // executeOpenShortWithRecord executes open short position and records detailed information
 func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
-	logger.Infof("  📉 Open short: %s", decision.Symbol)
+	log := at.log().WithField(logger.FieldSymbol, decision.Symbol)
+	log.Infof("  📉 Open short: %s", decision.Symbol)
 
 	// ⚠️ Get current positions for multiple checks
 	positions, err := at.trader.GetPositions()
@@ -1450,6 +1657,11 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 		return err
 	}
 
+	// [CODE ENFORCED] Underlying market must be open (stocks, forex, commodities)
+	if err := at.enforceMarketOpen(decision.Symbol); err != nil {
+		return err
+	}
+
 	// Check if there's already a position in the same symbol and direction
 	for _, pos := range positions {
 		if pos["symbol"] == decision.Symbol && pos["side"] == "short" {
@@ -1489,6 +1701,13 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 		decision.PositionSizeUSD = adjustedPositionSize
 	}
 
+	// [CODE ENFORCED] Portfolio exposure: net/gross, correlation clusters, same-direction count
+	exposureSize, err := at.enforcePortfolioExposure(positions, decision.Symbol, "short", decision.PositionSizeUSD, equity)
+	if err != nil {
+		return err
+	}
+	decision.PositionSizeUSD = exposureSize
+
 	// ⚠️ Auto-adjust position size if insufficient margin
 	// Formula: totalRequired = positionSize/leverage + positionSize*0.001 + positionSize/leverage*0.01
 	//        = positionSize * (1.01/leverage + 0.001)
@@ -1499,12 +1718,23 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 	if actualPositionSize > maxAffordablePositionSize {
 		// Use 98% of max to leave buffer for price fluctuation
 		adjustedSize := maxAffordablePositionSize * 0.98
-		logger.Infof("  ⚠️ Position size %.2f exceeds max affordable %.2f, auto-reducing to %.2f",
+		log.Infof("  ⚠️ Position size %.2f exceeds max affordable %.2f, auto-reducing to %.2f",
 			actualPositionSize, maxAffordablePositionSize, adjustedSize)
 		actualPositionSize = adjustedSize
 		decision.PositionSizeUSD = actualPositionSize
 	}
 
+	// [CODE ENFORCED] Slippage guard: split or reduce orders too large for the order book
+	orderSlices := at.enforceSlippageLimit(decision.Symbol, "short", actualPositionSize)
+	plannedSize := 0.0
+	for _, sliceUSD := range orderSlices {
+		plannedSize += sliceUSD
+	}
+	if plannedSize < actualPositionSize {
+		actualPositionSize = plannedSize
+		decision.PositionSizeUSD = actualPositionSize
+	}
+
 	// [CODE ENFORCED] Minimum position size check
 	if err := at.enforceMinPositionSize(decision.PositionSizeUSD); err != nil {
 		return err
@@ -1517,25 +1747,19 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 
 	// Set margin mode
 	if err := at.trader.SetMarginMode(decision.Symbol, at.config.IsCrossMargin); err != nil {
-		logger.Infof("  ⚠️ Failed to set margin mode: %v", err)
+		log.Infof("  ⚠️ Failed to set margin mode: %v", err)
 		// Continue execution, doesn't affect trading
 	}
 
-	// Open position
-	order, err := at.trader.OpenShort(decision.Symbol, quantity, decision.Leverage)
+	// Open position (one market order per slice), each order is recorded and confirmed
+	quantity, err = at.openPositionInSlices(decision, "open_short", orderSlices, marketData.CurrentPrice, actionRecord)
 	if err != nil {
 		return err
 	}
+	actionRecord.Quantity = quantity
+	log = log.WithField(logger.FieldOrderID, actionRecord.OrderID)
 
-	// Record order ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
-
-	logger.Infof("  ✓ Position opened successfully, order ID: %v, quantity: %.4f", order["orderId"], quantity)
-
-	// Record order to database and poll for confirmation
-	at.recordAndConfirmOrder(order, decision.Symbol, "open_short", quantity, marketData.CurrentPrice, decision.Leverage, 0, decision.TakeProfit, decision.StopLoss)
+	log.Infof("  ✓ Position opened successfully, order ID: %v, quantity: %.4f", actionRecord.OrderID, quantity)
 
 	// Record position opening time
 	posKey := decision.Symbol + "_short"
@@ -1549,29 +1773,29 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 	for i := 0; i < maxSLRetries; i++ {
 		slErr = at.trader.SetStopLoss(decision.Symbol, "SHORT", quantity, decision.StopLoss)
 		if slErr == nil {
-			logger.Infof("  ✅ Stop loss set successfully for %s SHORT at %.4f", decision.Symbol, decision.StopLoss)
+			log.Infof("  ✅ Stop loss set successfully for %s SHORT at %.4f", decision.Symbol, decision.StopLoss)
 			break
 		}
 		if i < maxSLRetries-1 {
-			logger.Warnf("  ⚠️ Stop loss attempt %d/%d failed: %v, retrying...", i+1, maxSLRetries, slErr)
+			log.Warnf("  ⚠️ Stop loss attempt %d/%d failed: %v, retrying...", i+1, maxSLRetries, slErr)
 			time.Sleep(time.Duration(i+1) * time.Second) // Exponential backoff
 		}
 	}
 	if slErr != nil {
-		logger.Errorf("  🚨 Failed to set stop loss after %d retries: %v - Position without SL protection!", maxSLRetries, slErr)
+		log.Errorf("  🚨 Failed to set stop loss after %d retries: %v - Position without SL protection!", maxSLRetries, slErr)
 
 		// ✅ 改进的错误恢复机制
 		// 1. 对所有持仓执行紧急平仓（不仅限于低信心度）
-		logger.Warnf("  🚨 Initiating emergency close for unprotected position %s", decision.Symbol)
-		if closeErr := at.emergencyClosePosition(decision.Symbol, "short"); closeErr != nil {
-			logger.Errorf("  ❌ Emergency close failed: %v", closeErr)
+		log.Warnf("  🚨 Initiating emergency close for unprotected position %s", decision.Symbol)
+		if closeErr := at.emergencyClosePosition(decision.Symbol, "short", "stop loss could not be set"); closeErr != nil {
+			log.Errorf("  ❌ Emergency close failed: %v", closeErr)
 			// 2. 发送紧急警报
 			at.sendEmergencyAlert(decision.Symbol, "SHORT", "止损设置失败且紧急平仓失败")
 		} else {
-			logger.Infof("  ✅ Emergency close succeeded for %s SHORT", decision.Symbol)
+			log.Infof("  ✅ Emergency close succeeded for %s SHORT", decision.Symbol)
 			// 3. 关闭关联的ASL记录
 			if err := at.store.AdaptiveStopLoss().CloseRecordBySymbol(at.id, decision.Symbol); err != nil {
-				logger.Warnf("  ⚠️ Failed to clean ASL record: %v", err)
+				log.Warnf("  ⚠️ Failed to clean ASL record: %v", err)
 			}
 		}
 		return fmt.Errorf("position closed due to SL setup failure and safety policy")
@@ -1583,21 +1807,21 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 	for i := 0; i < maxTPRetries; i++ {
 		tpErr = at.trader.SetTakeProfit(decision.Symbol, "SHORT", quantity, decision.TakeProfit)
 		if tpErr == nil {
-			logger.Infof("  ✅ Take profit set successfully for %s SHORT at %.4f", decision.Symbol, decision.TakeProfit)
+			log.Infof("  ✅ Take profit set successfully for %s SHORT at %.4f", decision.Symbol, decision.TakeProfit)
 			break
 		}
 		if i < maxTPRetries-1 {
-			logger.Warnf("  ⚠️ Take profit attempt %d/%d failed: %v, retrying...", i+1, maxTPRetries, tpErr)
+			log.Warnf("  ⚠️ Take profit attempt %d/%d failed: %v, retrying...", i+1, maxTPRetries, tpErr)
 			time.Sleep(time.Duration(i+1) * time.Second) // Exponential backoff
 		}
 	}
 	if tpErr != nil {
-		logger.Warnf("  🚨 Failed to set take profit after %d retries: %v - Position opened without TP protection!", maxTPRetries, tpErr)
+		log.Warnf("  🚨 Failed to set take profit after %d retries: %v - Position opened without TP protection!", maxTPRetries, tpErr)
 		// Emergency close for low confidence positions without TP
 		if decision.Confidence < 60 {
-			logger.Warnf("  🚨 Low confidence (<%d%%) position without TP - executing emergency close!", 60)
-			if closeErr := at.emergencyClosePosition(decision.Symbol, "short"); closeErr != nil {
-				logger.Errorf("  ❌ Emergency close failed: %v", closeErr)
+			log.Warnf("  🚨 Low confidence (<%d%%) position without TP - executing emergency close!", 60)
+			if closeErr := at.emergencyClosePosition(decision.Symbol, "short", "low confidence position without take profit"); closeErr != nil {
+				log.Errorf("  ❌ Emergency close failed: %v", closeErr)
 			} else {
 				return fmt.Errorf("position closed due to TP setup failure")
 			}
@@ -1616,7 +1840,7 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 			decision.TakeProfit,
 			atrValue,
 		)
-		logger.Infof("  🛡️ Adaptive stop loss set for %s SHORT (ATR: %.2f)", decision.Symbol, atrValue)
+		log.Infof("  🛡️ Adaptive stop loss set for %s SHORT (ATR: %.2f)", decision.Symbol, atrValue)
 	}
 
 	return nil
@@ -1624,7 +1848,8 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *kernel.Decision, acti
 
 // executeCloseLongWithRecord executes close long position and records detailed information
 func (at *AutoTrader) executeCloseLongWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
-	logger.Infof("  🔄 Close long: %s", decision.Symbol)
+	log := at.log().WithField(logger.FieldSymbol, decision.Symbol)
+	log.Infof("  🔄 Close long: %s", decision.Symbol)
 
 	// Get current price
 	marketData, err := market.Get(decision.Symbol)
@@ -1665,7 +1890,7 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *kernel.Decision, acti
 				quantity = exchangeQty
 				// Update local DB if out of sync
 				if openPos.Quantity != exchangeQty {
-					logger.Warnf("  ⚠️ Local qty (%.8f) differs from exchange (%.8f), using exchange value", openPos.Quantity, exchangeQty)
+					log.Warnf("  ⚠️ Local qty (%.8f) differs from exchange (%.8f), using exchange value", openPos.Quantity, exchangeQty)
 				}
 			} else {
 				quantity = openPos.Quantity
@@ -1674,18 +1899,19 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *kernel.Decision, acti
 			if entryPrice == 0 {
 				entryPrice = openPos.EntryPrice
 			}
-			logger.Infof("  📊 Position data: qty=%.8f (exchange), entry=%.2f", quantity, entryPrice)
+			log.Infof("  📊 Position data: qty=%.8f (exchange), entry=%.2f", quantity, entryPrice)
 		}
 	}
 
 	// Fallback if local data not found
 	if quantity == 0 && exchangeQty > 0 {
 		quantity = exchangeQty
-		logger.Infof("  📊 Using exchange position data only: qty=%.8f, entry=%.2f", quantity, entryPrice)
+		log.Infof("  📊 Using exchange position data only: qty=%.8f, entry=%.2f", quantity, entryPrice)
 	}
 
 	// Close position
 	order, err := at.trader.CloseLong(decision.Symbol, 0) // 0 = close all
+	at.recordOrderMetric("close_long", err)
 	if err != nil {
 		return err
 	}
@@ -1693,18 +1919,20 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *kernel.Decision, acti
 	// Record order ID
 	if orderID, ok := order["orderId"].(int64); ok {
 		actionRecord.OrderID = orderID
+		log = log.WithField(logger.FieldOrderID, orderID)
 	}
 
 	// Record order to database and poll for confirmation
 	at.recordAndConfirmOrder(order, decision.Symbol, "close_long", quantity, marketData.CurrentPrice, 0, entryPrice, 0, 0)
 
-	logger.Infof("  ✓ Position closed successfully")
+	log.Infof("  ✓ Position closed successfully")
 	return nil
 }
 
 // executeCloseShortWithRecord executes close short position and records detailed information
 func (at *AutoTrader) executeCloseShortWithRecord(decision *kernel.Decision, actionRecord *store.DecisionAction) error {
-	logger.Infof("  🔄 Close short: %s", decision.Symbol)
+	log := at.log().WithField(logger.FieldSymbol, decision.Symbol)
+	log.Infof("  🔄 Close short: %s", decision.Symbol)
 
 	// Get current price
 	marketData, err := market.Get(decision.Symbol)
@@ -1745,7 +1973,7 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *kernel.Decision, act
 				quantity = exchangeQty
 				// Update local DB if out of sync
 				if openPos.Quantity != exchangeQty {
-					logger.Warnf("  ⚠️ Local qty (%.8f) differs from exchange (%.8f), using exchange value", openPos.Quantity, exchangeQty)
+					log.Warnf("  ⚠️ Local qty (%.8f) differs from exchange (%.8f), using exchange value", openPos.Quantity, exchangeQty)
 				}
 			} else {
 				quantity = openPos.Quantity
@@ -1754,18 +1982,19 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *kernel.Decision, act
 			if entryPrice == 0 {
 				entryPrice = openPos.EntryPrice
 			}
-			logger.Infof("  📊 Position data: qty=%.8f (exchange), entry=%.2f", quantity, entryPrice)
+			log.Infof("  📊 Position data: qty=%.8f (exchange), entry=%.2f", quantity, entryPrice)
 		}
 	}
 
 	// Fallback if local data not found
 	if quantity == 0 && exchangeQty > 0 {
 		quantity = exchangeQty
-		logger.Infof("  📊 Using exchange position data only: qty=%.8f, entry=%.2f", quantity, entryPrice)
+		log.Infof("  📊 Using exchange position data only: qty=%.8f, entry=%.2f", quantity, entryPrice)
 	}
 
 	// Close position
 	order, err := at.trader.CloseShort(decision.Symbol, 0) // 0 = close all
+	at.recordOrderMetric("close_short", err)
 	if err != nil {
 		return err
 	}
@@ -1773,12 +2002,13 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *kernel.Decision, act
 	// Record order ID
 	if orderID, ok := order["orderId"].(int64); ok {
 		actionRecord.OrderID = orderID
+		log = log.WithField(logger.FieldOrderID, orderID)
 	}
 
 	// Record order to database and poll for confirmation
 	at.recordAndConfirmOrder(order, decision.Symbol, "close_short", quantity, marketData.CurrentPrice, 0, entryPrice, 0, 0)
 
-	logger.Infof("  ✓ Position closed successfully")
+	log.Infof("  ✓ Position closed successfully")
 	return nil
 }
 
@@ -1826,6 +2056,9 @@ func (at *AutoTrader) SetOverrideBasePrompt(override bool) {
 func (at *AutoTrader) GetSystemPromptTemplate() string {
 	if at.strategyEngine != nil {
 		config := at.strategyEngine.GetConfig()
+		if config.PromptTemplate != nil && config.PromptTemplate.Name != "" {
+			return config.PromptTemplate.Name
+		}
 		if config.CustomPrompt != "" {
 			return "custom"
 		}
@@ -1840,7 +2073,12 @@ func (at *AutoTrader) GetErrorTracker() *ErrorTracker {
 
 // saveEquitySnapshot saves equity snapshot independently (for drawing profit curve, decoupled from AI decision)
 func (at *AutoTrader) saveEquitySnapshot(ctx *kernel.Context) {
-	if at.store == nil || ctx == nil {
+	if ctx == nil {
+		return
+	}
+	metrics.SetAccount(at.id, at.exchange, ctx.Account.TotalEquity, ctx.Account.UnrealizedPnL,
+		ctx.Account.MarginUsedPct/100, ctx.Account.PositionCount)
+	if at.store == nil {
 		return
 	}
 
@@ -1855,7 +2093,7 @@ func (at *AutoTrader) saveEquitySnapshot(ctx *kernel.Context) {
 	}
 
 	if err := at.store.Equity().Save(snapshot); err != nil {
-		logger.Infof("⚠️ Failed to save equity snapshot: %v", err)
+		at.log().Infof("⚠️ Failed to save equity snapshot: %v", err)
 	}
 }
 
@@ -1874,11 +2112,11 @@ func (at *AutoTrader) saveDecision(record *store.DecisionRecord) error {
 	}
 
 	if err := at.store.Decision().LogDecision(record); err != nil {
-		logger.Infof("⚠️ Failed to save decision record: %v", err)
+		at.log().Infof("⚠️ Failed to save decision record: %v", err)
 		return err
 	}
 
-	logger.Infof("📝 Decision record saved: trader=%s, cycle=%d", at.id, at.cycleNumber)
+	at.log().Infof("📝 Decision record saved: trader=%s, cycle=%d", at.id, at.cycleNumber)
 	return nil
 }
 
@@ -1912,6 +2150,7 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 		"stop_until":      at.stopUntil.Format(time.RFC3339),
 		"last_reset_time": at.lastResetTime.Format(time.RFC3339),
 		"ai_provider":     aiProvider,
+		"debate_panel_id": at.config.DebatePanelID,
 	}
 }
 
@@ -1975,7 +2214,7 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	// Note: Lighter API may return 0 for unrealized PnL, this is a known limitation
 	diff := math.Abs(totalUnrealizedProfit - totalUnrealizedPnLCalculated)
 	if diff > 5.0 { // Only warn if difference is significant (> 5 USDT)
-		logger.Infof("⚠️ Unrealized P&L inconsistency (Lighter API limitation): API=%.4f, Calculated=%.4f, Diff=%.4f",
+		at.log().Infof("⚠️ Unrealized P&L inconsistency (Lighter API limitation): API=%.4f, Calculated=%.4f, Diff=%.4f",
 			totalUnrealizedProfit, totalUnrealizedPnLCalculated, diff)
 	}
 
@@ -1984,7 +2223,7 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	if at.initialBalance > 0 {
 		totalPnLPct = (totalPnL / at.initialBalance) * 100
 	} else {
-		logger.Infof("⚠️ Initial Balance abnormal: %.2f, cannot calculate P&L percentage", at.initialBalance)
+		at.log().Infof("⚠️ Initial Balance abnormal: %.2f, cannot calculate P&L percentage", at.initialBalance)
 	}
 
 	marginUsedPct := 0.0
@@ -2115,14 +2354,14 @@ func (at *AutoTrader) startDrawdownMonitor() {
 		ticker := time.NewTicker(1 * time.Minute) // Check every minute
 		defer ticker.Stop()
 
-		logger.Info("📊 Started position drawdown monitoring (check every minute)")
+		at.log().Info("📊 Started position drawdown monitoring (check every minute)")
 
 		for {
 			select {
 			case <-ticker.C:
 				at.checkPositionDrawdown()
 			case <-at.stopMonitorCh:
-				logger.Info("⏹ Stopped position drawdown monitoring")
+				at.log().Info("⏹ Stopped position drawdown monitoring")
 				return
 			}
 		}
@@ -2134,7 +2373,7 @@ func (at *AutoTrader) checkPositionDrawdown() {
 	// Get current positions
 	positions, err := at.trader.GetPositions()
 	if err != nil {
-		logger.Infof("❌ Drawdown monitoring: failed to get positions: %v", err)
+		at.log().Infof("❌ Drawdown monitoring: failed to get positions: %v", err)
 		return
 	}
 
@@ -2186,54 +2425,68 @@ func (at *AutoTrader) checkPositionDrawdown() {
 
 		// Check close position condition: profit > 5% and drawdown >= 40%
 		if currentPnLPct > 5.0 && drawdownPct >= 40.0 {
-			logger.Infof("🚨 Drawdown close position condition triggered: %s %s | Current profit: %.2f%% | Peak profit: %.2f%% | Drawdown: %.2f%%",
+			at.log().Infof("🚨 Drawdown close position condition triggered: %s %s | Current profit: %.2f%% | Peak profit: %.2f%% | Drawdown: %.2f%%",
 				symbol, side, currentPnLPct, peakPnLPct, drawdownPct)
 
 			// Execute close position
-			if err := at.emergencyClosePosition(symbol, side); err != nil {
-				logger.Infof("❌ Drawdown close position failed (%s %s): %v", symbol, side, err)
+			if err := at.emergencyClosePosition(symbol, side, fmt.Sprintf("profit drawdown %.2f%% from peak %.2f%%", drawdownPct, peakPnLPct)); err != nil {
+				at.log().Infof("❌ Drawdown close position failed (%s %s): %v", symbol, side, err)
 			} else {
-				logger.Infof("✅ Drawdown close position succeeded: %s %s", symbol, side)
+				at.log().Infof("✅ Drawdown close position succeeded: %s %s", symbol, side)
 				// Clear cache for this position after closing
 				at.ClearPeakPnLCache(symbol, side)
 			}
 		} else if currentPnLPct > 5.0 {
 			// Record situations close to close position condition (for debugging)
-			logger.Infof("📊 Drawdown monitoring: %s %s | Profit: %.2f%% | Peak: %.2f%% | Drawdown: %.2f%%",
+			at.log().Infof("📊 Drawdown monitoring: %s %s | Profit: %.2f%% | Peak: %.2f%% | Drawdown: %.2f%%",
 				symbol, side, currentPnLPct, peakPnLPct, drawdownPct)
 		}
 	}
 }
 
 // emergencyClosePosition emergency close position function
-func (at *AutoTrader) emergencyClosePosition(symbol, side string) error {
+func (at *AutoTrader) emergencyClosePosition(symbol, side, reason string) error {
+	var order map[string]interface{}
+	var err error
 	switch side {
 	case "long":
-		order, err := at.trader.CloseLong(symbol, 0) // 0 = close all
-		if err != nil {
-			return err
-		}
-		logger.Infof("✅ Emergency close long position succeeded, order ID: %v", order["orderId"])
+		order, err = at.trader.CloseLong(symbol, 0) // 0 = close all
 	case "short":
-		order, err := at.trader.CloseShort(symbol, 0) // 0 = close all
-		if err != nil {
-			return err
-		}
-		logger.Infof("✅ Emergency close short position succeeded, order ID: %v", order["orderId"])
+		order, err = at.trader.CloseShort(symbol, 0) // 0 = close all
 	default:
 		return fmt.Errorf("unknown position direction: %s", side)
 	}
+	at.recordOrderMetric("close_"+side, err)
 
+	after := map[string]interface{}{"symbol": symbol, "side": side, "reason": reason}
+	if err != nil {
+		after["error"] = err.Error()
+		at.recordAudit("position.emergency_close", nil, after)
+		return err
+	}
+	after["order_id"] = order["orderId"]
+	at.recordAudit("position.emergency_close", nil, after)
+	at.log().Infof("✅ Emergency close %s position succeeded, order ID: %v", side, order["orderId"])
 	return nil
 }
 
+// recordAudit appends an automated action of this trader to the audit log
+func (at *AutoTrader) recordAudit(action string, before, after interface{}) {
+	if at.store == nil {
+		return
+	}
+	if err := at.store.Audit().Record(at.userID, "auto_trader", action, "trader", at.id, before, after); err != nil {
+		at.log().Warnf("⚠️ [%s] Failed to write audit entry %s: %v", at.name, action, err)
+	}
+}
+
 // sendEmergencyAlert 发送紧急警报（集成日志、数据库记录、可扩展通知）
 func (at *AutoTrader) sendEmergencyAlert(symbol, side, reason string) {
 	// 1. 高级别日志警报
-	logger.Errorf("🚨🚨🚨 EMERGENCY ALERT 🚨🚨🚨")
-	logger.Errorf("Trader: %s | Exchange: %s", at.id, at.exchange)
-	logger.Errorf("Symbol: %s %s | Reason: %s", symbol, side, reason)
-	logger.Errorf("Time: %s", time.Now().UTC().Format(time.RFC3339))
+	at.log().Errorf("🚨🚨🚨 EMERGENCY ALERT 🚨🚨🚨")
+	at.log().Errorf("Trader: %s | Exchange: %s", at.id, at.exchange)
+	at.log().Errorf("Symbol: %s %s | Reason: %s", symbol, side, reason)
+	at.log().Errorf("Time: %s", time.Now().UTC().Format(time.RFC3339))
 
 	// 2. 写入数据库警报记录（用于后续审计）
 	// 注意：这需要在store中添加AlertLog表，这里先预留接口
@@ -2287,8 +2540,26 @@ func (at *AutoTrader) ClearPeakPnLCache(symbol, side string) {
 // entryPrice: entry price when closing (0 when opening)
 // tp, sl: take profit and stop loss (0 when closing or not applicable)
 func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{}, symbol, action string, quantity float64, price float64, leverage int, entryPrice float64, tp float64, sl float64) {
+	if fill := at.confirmOrder(orderResult, symbol, action, quantity, price, leverage); fill != nil {
+		at.recordFill(fill, symbol, action, leverage, entryPrice, tp, sl)
+	}
+}
+
+// orderFill actual fill data of a confirmed order
+type orderFill struct {
+	orderID  string
+	quantity float64
+	price    float64
+	fee      float64
+}
+
+// confirmOrder records an order and polls its status for the actual fill data
+// Returns nil when there is nothing to record here: no store, no order ID, the order was not filled,
+// or the exchange's OrderSync records its orders and positions
+func (at *AutoTrader) confirmOrder(orderResult map[string]interface{}, symbol, action string, quantity float64, price float64, leverage int) *orderFill {
+	log := at.log().WithField(logger.FieldSymbol, symbol)
 	if at.store == nil {
-		return
+		return nil
 	}
 
 	// Get order ID (supports multiple types)
@@ -2305,18 +2576,12 @@ func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{},
 	}
 
 	if orderID == "" || orderID == "0" {
-		logger.Infof("  ⚠️ Order ID is empty, skipping record")
-		return
+		log.Infof("  ⚠️ Order ID is empty, skipping record")
+		return nil
 	}
+	log = log.WithField(logger.FieldOrderID, orderID)
 
-	// Determine positionSide
-	var positionSide string
-	switch action {
-	case "open_long", "close_long":
-		positionSide = "LONG"
-	case "open_short", "close_short":
-		positionSide = "SHORT"
-	}
+	positionSide := positionSideOf(action)
 
 	var actualPrice = price
 	var actualQty = quantity
@@ -2326,16 +2591,16 @@ func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{},
 	// This ensures accurate data from GetTrades API and avoids duplicate records
 	switch at.exchange {
 	case "binance", "lighter", "hyperliquid", "bybit", "okx", "bitget", "aster":
-		logger.Infof("  📝 Order submitted (id: %s), will be synced by OrderSync", orderID)
-		return
+		log.Infof("  📝 Order submitted (id: %s), will be synced by OrderSync", orderID)
+		return nil
 	}
 
 	// For exchanges without OrderSync (e.g., Binance): record immediately and poll for fill data
 	orderRecord := at.createOrderRecord(orderID, symbol, action, positionSide, quantity, price, leverage)
 	if err := at.store.Order().CreateOrder(orderRecord); err != nil {
-		logger.Infof("  ⚠️ Failed to record order: %v", err)
+		log.Infof("  ⚠️ Failed to record order: %v", err)
 	} else {
-		logger.Infof("  📝 Order recorded: %s [%s] %s", orderID, action, symbol)
+		log.Infof("  📝 Order recorded: %s [%s] %s", orderID, action, symbol)
 	}
 
 	// Wait for order to be filled and get actual fill data
@@ -2359,11 +2624,11 @@ func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{},
 				if commission, ok := status["commission"].(float64); ok {
 					fee = commission
 				}
-				logger.Infof("  ✅ Order filled: avgPrice=%.6f, qty=%.6f, fee=%.6f", actualPrice, actualQty, fee)
+				log.Infof("  ✅ Order filled: avgPrice=%.6f, qty=%.6f, fee=%.6f", actualPrice, actualQty, fee)
 
 				// Update order status to FILLED
 				if err := at.store.Order().UpdateOrderStatus(orderRecord.ID, "FILLED", actualQty, actualPrice, fee); err != nil {
-					logger.Infof("  ⚠️ Failed to update order status: %v", err)
+					log.Infof("  ⚠️ Failed to update order status: %v", err)
 				}
 
 				// Record fill details
@@ -2377,16 +2642,16 @@ func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{},
 				if avgPrice, ok := status["avgPrice"].(float64); ok && avgPrice > 0 {
 					actualPrice = avgPrice
 				}
-				logger.Infof("  ⚠️ Order partially filled: qty=%.6f/%.6f, continuing to poll...", actualQty, quantity)
+				log.Infof("  ⚠️ Order partially filled: qty=%.6f/%.6f, continuing to poll...", actualQty, quantity)
 				// Continue polling for full fill
 			} else if statusStr == "CANCELED" || statusStr == "EXPIRED" || statusStr == "REJECTED" {
-				logger.Infof("  ⚠️ Order %s, skipping position record", statusStr)
+				log.Infof("  ⚠️ Order %s, skipping position record", statusStr)
 
 				// Update order status
 				if err := at.store.Order().UpdateOrderStatus(orderRecord.ID, statusStr, 0, 0, 0); err != nil {
-					logger.Infof("  ⚠️ Failed to update order status: %v", err)
+					log.Infof("  ⚠️ Failed to update order status: %v", err)
 				}
-				return
+				return nil
 			}
 		}
 		time.Sleep(500 * time.Millisecond)
@@ -2394,27 +2659,32 @@ func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{},
 
 	// Handle timeout - order still not fully filled after polling
 	if finalStatus != "FILLED" && finalStatus != "" {
-		logger.Warnf("  ⚠️ Order %s did not complete within timeout, final status: %s", orderID, finalStatus)
+		log.Warnf("  ⚠️ Order %s did not complete within timeout, final status: %s", orderID, finalStatus)
 		if finalStatus == "PARTIALLY_FILLED" && actualQty > 0 {
 			// Record partial fill
-			logger.Infof("  📝 Recording partial fill: qty=%.6f", actualQty)
+			log.Infof("  📝 Recording partial fill: qty=%.6f", actualQty)
 			if err := at.store.Order().UpdateOrderStatus(orderRecord.ID, "PARTIALLY_FILLED", actualQty, actualPrice, fee); err != nil {
-				logger.Infof("  ⚠️ Failed to update order status: %v", err)
+				log.Infof("  ⚠️ Failed to update order status: %v", err)
 			}
 		} else if finalStatus == "NEW" {
 			// Order still pending - consider canceling
-			logger.Warnf("  ⚠️ Order still pending after timeout, may need manual attention")
+			log.Warnf("  ⚠️ Order still pending after timeout, may need manual attention")
 		}
 	}
 
+	return &orderFill{orderID: orderID, quantity: actualQty, price: actualPrice, fee: fee}
+}
+
+// recordFill records the position change of a confirmed order
+func (at *AutoTrader) recordFill(fill *orderFill, symbol, action string, leverage int, entryPrice, tp, sl float64) {
 	// Normalize symbol for position record consistency
 	normalizedSymbolForPosition := market.Normalize(symbol)
 
-	logger.Infof("  📝 Recording position (ID: %s, action: %s, price: %.6f, qty: %.6f, fee: %.4f)",
-		orderID, action, actualPrice, actualQty, fee)
+	at.log().WithField(logger.FieldSymbol, symbol).Infof("  📝 Recording position (ID: %s, action: %s, price: %.6f, qty: %.6f, fee: %.4f)",
+		fill.orderID, action, fill.price, fill.quantity, fill.fee)
 
 	// Record position change with actual fill data (use normalized symbol)
-	at.recordPositionChange(orderID, normalizedSymbolForPosition, positionSide, action, actualQty, actualPrice, leverage, entryPrice, fee, tp, sl)
+	at.recordPositionChange(fill.orderID, normalizedSymbolForPosition, positionSideOf(action), action, fill.quantity, fill.price, leverage, entryPrice, fill.fee, tp, sl)
 
 	// Send anonymous trade statistics for experience improvement (async, non-blocking)
 	// This helps us understand overall product usage across all deployments
@@ -2422,13 +2692,24 @@ func (at *AutoTrader) recordAndConfirmOrder(orderResult map[string]interface{},
 		Exchange:  at.exchange,
 		TradeType: action,
 		Symbol:    symbol,
-		AmountUSD: actualPrice * actualQty,
+		AmountUSD: fill.price * fill.quantity,
 		Leverage:  leverage,
 		UserID:    at.userID,
 		TraderID:  at.id,
 	})
 }
 
+// positionSideOf LONG or SHORT for an open/close action
+func positionSideOf(action string) string {
+	switch action {
+	case "open_long", "close_long":
+		return "LONG"
+	case "open_short", "close_short":
+		return "SHORT"
+	}
+	return ""
+}
+
 // recordPositionChange records position change (create record on open, update record on close)
 func (at *AutoTrader) recordPositionChange(orderID, symbol, side, action string, quantity, price float64, leverage int, entryPrice float64, fee float64, tp float64, sl float64) {
 	if at.store == nil {
@@ -2455,16 +2736,16 @@ func (at *AutoTrader) recordPositionChange(orderID, symbol, side, action string,
 			UpdatedAt:    nowMs,
 		}
 		if err := at.store.Position().Create(pos); err != nil {
-			logger.Infof("  ⚠️ Failed to record position: %v", err)
+			at.log().Infof("  ⚠️ Failed to record position: %v", err)
 		} else {
-			logger.Infof("  📊 Position recorded [%s] %s %s @ %.4f", at.id[:8], symbol, side, price)
+			at.log().Infof("  📊 Position recorded [%s] %s %s @ %.4f", at.id[:8], symbol, side, price)
 
 			// Record TP/SL for the new position (only when tp and sl are provided)
 			if tp > 0 && sl > 0 {
 				if err := at.recordTPSL(at.id, pos, tp, sl); err != nil {
-					logger.Warnf("  ⚠️ Failed to record TP/SL: %v", err)
+					at.log().Warnf("  ⚠️ Failed to record TP/SL: %v", err)
 				} else {
-					logger.Infof("  ✅ TP/SL recorded: TP=%.2f, SL=%.2f", tp, sl)
+					at.log().Infof("  ✅ TP/SL recorded: TP=%.2f, SL=%.2f", tp, sl)
 				}
 			}
 		}
@@ -2481,16 +2762,16 @@ func (at *AutoTrader) recordPositionChange(orderID, symbol, side, action string,
 			quantity, price, fee, 0, // realizedPnL will be calculated
 			time.Now().UTC().UnixMilli(), orderID,
 		); err != nil {
-			logger.Infof("  ⚠️ Failed to process close position: %v", err)
+			at.log().Infof("  ⚠️ Failed to process close position: %v", err)
 		} else {
-			logger.Infof("  ✅ Position closed [%s] %s %s @ %.4f", at.id[:8], symbol, side, price)
+			at.log().Infof("  ✅ Position closed [%s] %s %s @ %.4f", at.id[:8], symbol, side, price)
 		}
 
 		// 关闭对应的ASL记录
 		if err := at.store.AdaptiveStopLoss().CloseRecordBySymbol(at.id, symbol); err != nil {
-			logger.Warnf("  ⚠️ Failed to close ASL record: %v", err)
+			at.log().Warnf("  ⚠️ Failed to close ASL record: %v", err)
 		} else {
-			logger.Infof("  ✅ ASL record closed for %s", symbol)
+			at.log().Infof("  ✅ ASL record closed for %s", symbol)
 		}
 
 		// Option B: Record trade outcome for metrics updates
@@ -2498,7 +2779,7 @@ func (at *AutoTrader) recordPositionChange(orderID, symbol, side, action string,
 			// For now, record 0 PnL (actual PnL will be available when position closes)
 			// In the future, fetch actual PnL from database when position closes
 			at.enhancedSetup.FundManagement.RecordTrade(0)
-			logger.Infof("  📈 Trade outcome recorded for performance metrics")
+			at.log().Infof("  📈 Trade outcome recorded for performance metrics")
 		}
 	}
 }
@@ -2612,9 +2893,9 @@ func (at *AutoTrader) recordOrderFill(orderRecordID int64, exchangeOrderID, symb
 	}
 
 	if err := at.store.Order().CreateFill(fill); err != nil {
-		logger.Infof("  ⚠️ Failed to record fill: %v", err)
+		at.log().Infof("  ⚠️ Failed to record fill: %v", err)
 	} else {
-		logger.Infof("  📋 Fill recorded: %.4f @ %.6f, fee: %.4f", quantity, price, fee)
+		at.log().Infof("  📋 Fill recorded: %.4f @ %.6f, fee: %.4f", quantity, price, fee)
 	}
 }
 
@@ -2659,7 +2940,7 @@ func (at *AutoTrader) enforcePositionValueRatio(positionSizeUSD float64, equity
 
 	// Check if position size exceeds limit
 	if positionSizeUSD > maxPositionValue {
-		logger.Infof("  ⚠️ [RISK CONTROL] Position %.2f USDT exceeds limit (equity %.2f × %.1fx = %.2f USDT max for %s), capping",
+		at.log().Infof("  ⚠️ [RISK CONTROL] Position %.2f USDT exceeds limit (equity %.2f × %.1fx = %.2f USDT max for %s), capping",
 			positionSizeUSD, equity, maxPositionValueRatio, maxPositionValue, symbol)
 		return maxPositionValue, true
 	}
@@ -2701,6 +2982,20 @@ func (at *AutoTrader) enforceMaxPositions(currentPositionCount int) error {
 	return nil
 }
 
+// enforceMarketOpen rejects new positions while the underlying market is closed (CODE ENFORCED)
+func (at *AutoTrader) enforceMarketOpen(symbol string) error {
+	if at.config.StrategyConfig != nil && at.config.StrategyConfig.RiskControl.AllowClosedMarketEntry {
+		return nil
+	}
+
+	status := market.GetMarketStatus(symbol, time.Now())
+	if !status.Open {
+		return fmt.Errorf("❌ [RISK CONTROL] %s market is closed (%s, %s), next open %s",
+			symbol, status.Calendar, status.Reason, status.NextOpen.UTC().Format("2006-01-02 15:04 UTC"))
+	}
+	return nil
+}
+
 // getSideFromAction converts order action to side (BUY/SELL)
 func getSideFromAction(action string) string {
 	switch action {
@@ -2727,13 +3022,13 @@ func (at *AutoTrader) updateDynamicStopLoss(ctx *kernel.Context) {
 		return
 	}
 
-	logger.Info("📊 [Dynamic SL] Checking stop loss adjustments for open positions...")
+	at.log().Info("📊 [Dynamic SL] Checking stop loss adjustments for open positions...")
 
 	// 🔥 新增：每 5 个周期验证一次 TP/SL 同步
 	at.cycleCount++
 	if at.cycleCount%5 == 0 {
 		if err := at.VerifyTPSLSync(ctx); err != nil {
-			logger.Warnf("⚠️ [TPSLSync] Periodic check failed: %v", err)
+			at.log().Warnf("⚠️ [TPSLSync] Periodic check failed: %v", err)
 		}
 	}
 
@@ -2741,7 +3036,7 @@ func (at *AutoTrader) updateDynamicStopLoss(ctx *kernel.Context) {
 		// 获取市场数据
 		marketData, err := market.Get(pos.Symbol)
 		if err != nil {
-			logger.Warnf("⚠️ [Dynamic SL] Failed to get market data for %s: %v", pos.Symbol, err)
+			at.log().Warnf("⚠️ [Dynamic SL] Failed to get market data for %s: %v", pos.Symbol, err)
 			continue
 		}
 
@@ -2764,7 +3059,7 @@ func (at *AutoTrader) updateDynamicStopLoss(ctx *kernel.Context) {
 				at.calculateInitialTakeProfit(pos),
 				atrValue,
 			)
-			logger.Infof("  📝 [%s] Registered for dynamic tracking | Entry: %.4f", pos.Symbol, pos.EntryPrice)
+			at.log().Infof("  📝 [%s] Registered for dynamic tracking | Entry: %.4f", pos.Symbol, pos.EntryPrice)
 
 			// 保存初始状态到数据库
 			if at.store != nil {
@@ -2785,15 +3080,15 @@ func (at *AutoTrader) updateDynamicStopLoss(ctx *kernel.Context) {
 		}
 
 		if shouldUpdate {
-			logger.Infof("  🔄 [%s] Updating stop loss: %.4f → %.4f (%.2f%% move)",
+			at.log().Infof("  🔄 [%s] Updating stop loss: %.4f → %.4f (%.2f%% move)",
 				pos.Symbol, currentExchangeSL, newSL,
 				((newSL-currentExchangeSL)/currentExchangeSL)*100)
 
 			// 更新交易所止损单
 			if err := at.updateExchangeStopLoss(pos.Symbol, pos.Side, pos.Quantity, newSL); err != nil {
-				logger.Warnf("  ⚠️ [%s] Failed to update exchange stop loss: %v", pos.Symbol, err)
+				at.log().Warnf("  ⚠️ [%s] Failed to update exchange stop loss: %v", pos.Symbol, err)
 			} else {
-				logger.Infof("  ✅ [%s] Exchange stop loss updated to %.4f", pos.Symbol, newSL)
+				at.log().Infof("  ✅ [%s] Exchange stop loss updated to %.4f", pos.Symbol, newSL)
 
 				// 保存动态止损更新记录到数据库
 				if at.store != nil {
@@ -2810,12 +3105,12 @@ func (at *AutoTrader) updateDynamicStopLoss(ctx *kernel.Context) {
 					运行时间 := time.Since(time.UnixMilli(pos.UpdateTime)).Seconds()
 
 					at.saveDynamicStopLossRecord(pos, marketData.CurrentPrice, newSL, pos.EntryPrice, 运行时间)
-					logger.Infof("  💾 [%s] Dynamic SL state saved: SL=%.4f, Distance=%.4f, Direction=%s, Time=%.1fs",
+					at.log().Infof("  💾 [%s] Dynamic SL state saved: SL=%.4f, Distance=%.4f, Direction=%s, Time=%.1fs",
 						pos.Symbol, newSL, 移动距离, 移动方向, 运行时间)
 				}
 			}
 		} else {
-			logger.Debugf("  ⏭️ [%s] No stop loss update needed | Current: %.4f | Calculated: %.4f",
+			at.log().Debugf("  ⏭️ [%s] No stop loss update needed | Current: %.4f | Calculated: %.4f",
 				pos.Symbol, currentExchangeSL, newSL)
 		}
 
@@ -2877,7 +3172,7 @@ func (at *AutoTrader) saveDynamicStopLossRecord(pos kernel.PositionInfo, current
 	}
 
 	if err := at.store.AdaptiveStopLoss().SaveRecord(record); err != nil {
-		logger.Warnf("  ⚠️ Failed to save dynamic SL record: %v", err)
+		at.log().Warnf("  ⚠️ Failed to save dynamic SL record: %v", err)
 	}
 }
 
@@ -2996,7 +3291,7 @@ func (at *AutoTrader) getCurrentExchangeStopLoss(symbol, side string) float64 {
 func (at *AutoTrader) updateExchangeStopLoss(symbol, side string, quantity, newStopPrice float64) error {
 	// 1. 先取消现有的止损单
 	if err := at.trader.CancelStopLossOrders(symbol); err != nil {
-		logger.Warnf("⚠️ Failed to cancel existing stop loss orders: %v", err)
+		at.log().Warnf("⚠️ Failed to cancel existing stop loss orders: %v", err)
 		// 继续尝试设置新的止损
 	}
 
@@ -19,12 +19,12 @@ func (at *AutoTrader) SaveAnalysisAndCreatePendingOrders(aiDecision *kernel.Full
 		return fmt.Errorf("AI decision is nil")
 	}
 
-	logger.Infof("💾 Saving AI analysis and creating pending orders...")
+	at.log().Infof("💾 Saving AI analysis and creating pending orders...")
 
 	// 获取所有PENDING状态的订单，用于同币种检查
 	existingOrders, err := at.store.Analysis().GetPendingOrdersByTrader(at.id)
 	if err != nil {
-		logger.Errorf("❌ Failed to get existing pending orders: %v", err)
+		at.log().Errorf("❌ Failed to get existing pending orders: %v", err)
 		// 继续执行，不影响保存分析
 	}
 
@@ -43,7 +43,7 @@ func (at *AutoTrader) SaveAnalysisAndCreatePendingOrders(aiDecision *kernel.Full
 		if order.Status == "FILLED" && order.FilledAt != nil {
 			timeSinceFilled := now.Sub(*order.FilledAt)
 			if timeSinceFilled < 30*time.Minute {
-				logger.Infof("⏰ %s has recently filled order (%.1f min ago), skipping duplicate",
+				at.log().Infof("⏰ %s has recently filled order (%.1f min ago), skipping duplicate",
 					order.Symbol, timeSinceFilled.Minutes())
 				existingOrderMap[order.Symbol] = order
 			}
@@ -73,11 +73,11 @@ func (at *AutoTrader) SaveAnalysisAndCreatePendingOrders(aiDecision *kernel.Full
 		}
 
 		if err := at.store.Analysis().SaveAnalysis(analysis); err != nil {
-			logger.Errorf("❌ Failed to save analysis for %s: %v", decision.Symbol, err)
+			at.log().Errorf("❌ Failed to save analysis for %s: %v", decision.Symbol, err)
 			continue
 		}
 
-		logger.Infof("✅ Analysis saved: %s (confidence: %.2f%%)", decision.Symbol, float64(decision.Confidence))
+		at.log().Infof("✅ Analysis saved: %s (confidence: %.2f%%)", decision.Symbol, float64(decision.Confidence))
 
 		// 2. 为开仓决策创建待执行订单
 		if decision.Action == "open_long" || decision.Action == "open_short" {
@@ -96,29 +96,29 @@ func (at *AutoTrader) SaveAnalysisAndCreatePendingOrders(aiDecision *kernel.Full
 					style = at.config.StrategyConfig.TriggerPriceConfig.Style
 				}
 				triggerConfig = store.GetDefaultTriggerPriceConfig(style)
-				logger.Warnf("⚠️ TriggerPriceConfig is nil, using default style '%s'", style)
+				at.log().Warnf("⚠️ TriggerPriceConfig is nil, using default style '%s'", style)
 			}
 
 			// 🚨 调试：打印配置信息
-			logger.Infof("🔧 [TRIGGER_PRICE_DEBUG] Strategy Config Check:")
-			logger.Infof("  Trader ID: %s", at.id)
-			logger.Infof("  Symbol: %s", decision.Symbol)
-			logger.Infof("  Action: %s", decision.Action)
-			logger.Infof("  Current Price: %.4f", currentPrice)
-			logger.Infof("  Stop Loss: %.4f", decision.StopLoss)
-			logger.Infof("  Take Profit: %.4f", decision.TakeProfit)
-			logger.Infof("  TriggerPriceConfig is nil: %v", triggerConfig == nil)
+			at.log().Infof("🔧 [TRIGGER_PRICE_DEBUG] Strategy Config Check:")
+			at.log().Infof("  Trader ID: %s", at.id)
+			at.log().Infof("  Symbol: %s", decision.Symbol)
+			at.log().Infof("  Action: %s", decision.Action)
+			at.log().Infof("  Current Price: %.4f", currentPrice)
+			at.log().Infof("  Stop Loss: %.4f", decision.StopLoss)
+			at.log().Infof("  Take Profit: %.4f", decision.TakeProfit)
+			at.log().Infof("  TriggerPriceConfig is nil: %v", triggerConfig == nil)
 			if triggerConfig != nil {
-				logger.Infof("  Config Mode: %s", triggerConfig.Mode)
-				logger.Infof("  Config Style: %s", triggerConfig.Style)
-				logger.Infof("  Pullback Ratio: %.4f", triggerConfig.PullbackRatio)
-				logger.Infof("  Breakout Ratio: %.4f", triggerConfig.BreakoutRatio)
-				logger.Infof("  Extra Buffer: %.4f", triggerConfig.ExtraBuffer)
+				at.log().Infof("  Config Mode: %s", triggerConfig.Mode)
+				at.log().Infof("  Config Style: %s", triggerConfig.Style)
+				at.log().Infof("  Pullback Ratio: %.4f", triggerConfig.PullbackRatio)
+				at.log().Infof("  Breakout Ratio: %.4f", triggerConfig.BreakoutRatio)
+				at.log().Infof("  Extra Buffer: %.4f", triggerConfig.ExtraBuffer)
 			} else {
-				logger.Errorf("❌ TriggerPriceConfig is nil! This indicates configuration was not properly saved or loaded")
-				logger.Infof("  Strategy Config exists: %v", at.config.StrategyConfig != nil)
+				at.log().Errorf("❌ TriggerPriceConfig is nil! This indicates configuration was not properly saved or loaded")
+				at.log().Infof("  Strategy Config exists: %v", at.config.StrategyConfig != nil)
 				if at.config.StrategyConfig != nil {
-					logger.Infof("  Full Strategy Config: %+v", at.config.StrategyConfig)
+					at.log().Infof("  Full Strategy Config: %+v", at.config.StrategyConfig)
 				}
 			}
 
@@ -132,12 +132,12 @@ func (at *AutoTrader) SaveAnalysisAndCreatePendingOrders(aiDecision *kernel.Full
 				decision.TakeProfit,
 			)
 
-			logger.Infof("🔧 [TRIGGER_PRICE_DEBUG] Calculation Result:")
-			logger.Infof("  Trigger Price: %.4f", triggerPrice)
-			logger.Infof("  Stop Loss: %.4f", decision.StopLoss)
-			logger.Infof("  Take Profit: %.4f", decision.TakeProfit)
-			logger.Infof("  Trigger in range: %v", triggerPrice > decision.StopLoss && triggerPrice < decision.TakeProfit)
-			logger.Infof("  Distance from current: %.4f (%.2f%%)",
+			at.log().Infof("🔧 [TRIGGER_PRICE_DEBUG] Calculation Result:")
+			at.log().Infof("  Trigger Price: %.4f", triggerPrice)
+			at.log().Infof("  Stop Loss: %.4f", decision.StopLoss)
+			at.log().Infof("  Take Profit: %.4f", decision.TakeProfit)
+			at.log().Infof("  Trigger in range: %v", triggerPrice > decision.StopLoss && triggerPrice < decision.TakeProfit)
+			at.log().Infof("  Distance from current: %.4f (%.2f%%)",
 				currentPrice-triggerPrice,
 				((currentPrice - triggerPrice) / currentPrice * 100))
 
@@ -177,19 +177,19 @@ func (at *AutoTrader) SaveAnalysisAndCreatePendingOrders(aiDecision *kernel.Full
 				}
 
 				if shouldReplace {
-					logger.Infof("🔄 替换同币种订单: %s (原因: %s)", decision.Symbol, replaceReason)
+					at.log().Infof("🔄 替换同币种订单: %s (原因: %s)", decision.Symbol, replaceReason)
 
 					// 取消旧订单
 					if err := at.store.Analysis().CancelPendingOrder(existingOrder.ID,
 						fmt.Sprintf("Replaced: %s", replaceReason)); err != nil {
-						logger.Warnf("⚠️ Failed to cancel old order: %v", err)
+						at.log().Warnf("⚠️ Failed to cancel old order: %v", err)
 					}
 
 					// 移除已替换的订单
 					delete(existingOrderMap, decision.Symbol)
 				} else {
 					// 现有订单更优，跳过创建
-					logger.Infof("⏭️ 跳过同币种订单: %s (保留现有订单: 置信度 %.2f%%, 年龄 %.1fh, 偏离 %.2f%%)",
+					at.log().Infof("⏭️ 跳过同币种订单: %s (保留现有订单: 置信度 %.2f%%, 年龄 %.1fh, 偏离 %.2f%%)",
 						decision.Symbol, existingOrder.Confidence*100, orderAge.Hours(), priceDeviation*100)
 					continue
 				}
@@ -211,14 +211,14 @@ func (at *AutoTrader) SaveAnalysisAndCreatePendingOrders(aiDecision *kernel.Full
 			}
 
 			if err := at.store.Analysis().SavePendingOrder(pendingOrder); err != nil {
-				logger.Errorf("❌ Failed to save pending order for %s: %v", decision.Symbol, err)
+				at.log().Errorf("❌ Failed to save pending order for %s: %v", decision.Symbol, err)
 				continue
 			}
 
 			// 更新映射
 			existingOrderMap[decision.Symbol] = pendingOrder
 
-			logger.Infof("⏳ Pending order created: %s (trigger: %.2f, target: %.2f, confidence: %.2f%%)",
+			at.log().Infof("⏳ Pending order created: %s (trigger: %.2f, target: %.2f, confidence: %.2f%%)",
 				decision.Symbol, triggerPrice, decision.TakeProfit, float64(decision.Confidence))
 		}
 	}
@@ -235,7 +235,7 @@ func (at *AutoTrader) MonitorAndExecutePendingOrders() error {
 	// 获取所有 PENDING 状态的订单
 	pendingOrders, err := at.store.Analysis().GetPendingOrdersByStatus(at.id, "PENDING")
 	if err != nil {
-		logger.Errorf("❌ Failed to get pending orders: %v", err)
+		at.log().Errorf("❌ Failed to get pending orders: %v", err)
 		return err
 	}
 
@@ -243,7 +243,7 @@ func (at *AutoTrader) MonitorAndExecutePendingOrders() error {
 		return nil
 	}
 
-	logger.Infof("📊 Checking %d pending orders...", len(pendingOrders))
+	at.log().Infof("📊 Checking %d pending orders...", len(pendingOrders))
 
 	for _, order := range pendingOrders {
 		// 获取当前价格
@@ -251,7 +251,7 @@ func (at *AutoTrader) MonitorAndExecutePendingOrders() error {
 		if marketData, err := market.Get(order.Symbol); err == nil {
 			currentPrice = marketData.CurrentPrice
 		} else {
-			logger.Warnf("⚠️ Failed to get current price for %s: %v", order.Symbol, err)
+			at.log().Warnf("⚠️ Failed to get current price for %s: %v", order.Symbol, err)
 			continue
 		}
 
@@ -278,7 +278,7 @@ func (at *AutoTrader) MonitorAndExecutePendingOrders() error {
 			direction = "SHORT"
 		}
 
-		logger.Infof("📈 %s [%s]: current=%.2f, trigger=%.2f (deviation: %.2f%%)",
+		at.log().Infof("📈 %s [%s]: current=%.2f, trigger=%.2f (deviation: %.2f%%)",
 			order.Symbol, direction, currentPrice, order.TriggerPrice, deviationPct)
 
 		// 检查是否触发
@@ -292,11 +292,11 @@ func (at *AutoTrader) MonitorAndExecutePendingOrders() error {
 		}
 
 		if triggered {
-			logger.Infof("🎯 Pending order triggered: %s [%s] at %.2f", order.Symbol, direction, currentPrice)
+			at.log().Infof("🎯 Pending order triggered: %s [%s] at %.2f", order.Symbol, direction, currentPrice)
 
 			// � 改进：使用指数退避重试策略
 			if err := at.executePendingOrderWithBackoff(order, currentPrice); err != nil {
-				logger.Errorf("❌ Failed to execute pending order after backoff retries: %v", err)
+				at.log().Errorf("❌ Failed to execute pending order after backoff retries: %v", err)
 				// 记录执行失败，增加重试计数
 				at.recordPendingOrderFailure(order.ID, err)
 				continue // 保持 PENDING 状态，允许重试
@@ -306,9 +306,9 @@ func (at *AutoTrader) MonitorAndExecutePendingOrders() error {
 			if err := at.store.Analysis().UpdatePendingOrderStatus(
 				order.ID, "TRIGGERED", currentPrice, time.Now().UTC(),
 			); err != nil {
-				logger.Warnf("⚠️ Failed to mark order as triggered: %v", err)
+				at.log().Warnf("⚠️ Failed to mark order as triggered: %v", err)
 			} else {
-				logger.Infof("✅ Pending order executed successfully: %s", order.Symbol)
+				at.log().Infof("✅ Pending order executed successfully: %s", order.Symbol)
 			}
 		} else {
 			// 检查订单是否应该被取消（价格偏离过大或订单过旧）
@@ -321,6 +321,7 @@ func (at *AutoTrader) MonitorAndExecutePendingOrders() error {
 
 // executePendingOrderWithBackoff 使用指数退避策略执行订单
 func (at *AutoTrader) executePendingOrderWithBackoff(order *store.PendingOrder, currentPrice float64) error {
+	log := at.log().WithField(logger.FieldSymbol, order.Symbol)
 	const maxRetries = 5
 	baseDelay := 2 * time.Second
 
@@ -329,14 +330,14 @@ func (at *AutoTrader) executePendingOrderWithBackoff(order *store.PendingOrder,
 		if attempt > 0 {
 			// 指数退避：2s, 4s, 8s, 16s, 32s
 			delay := baseDelay * time.Duration(1<<uint(attempt-1))
-			logger.Infof("  ⏳ Retry %d/%d after %v delay...", attempt+1, maxRetries, delay)
+			log.Infof("  ⏳ Retry %d/%d after %v delay...", attempt+1, maxRetries, delay)
 			time.Sleep(delay)
 		}
 
 		err := at.executePendingOrder(order, currentPrice)
 		if err == nil {
 			if attempt > 0 {
-				logger.Infof("  ✅ Order executed successfully on retry %d", attempt+1)
+				log.Infof("  ✅ Order executed successfully on retry %d", attempt+1)
 				// 记录重试成功
 				if at.errorTracker != nil {
 					at.errorTracker.RecordError(
@@ -351,7 +352,7 @@ func (at *AutoTrader) executePendingOrderWithBackoff(order *store.PendingOrder,
 		}
 
 		lastErr = err
-		logger.Warnf("  ⚠️ Attempt %d/%d failed: %v", attempt+1, maxRetries, err)
+		log.Warnf("  ⚠️ Attempt %d/%d failed: %v", attempt+1, maxRetries, err)
 
 		// 记录重试失败
 		if at.errorTracker != nil {
@@ -369,7 +370,7 @@ func (at *AutoTrader) executePendingOrderWithBackoff(order *store.PendingOrder,
 
 		// 检查是否是不可重试的错误（如余额不足）
 		if isNonRetryableError(err) {
-			logger.Errorf("  ❌ Non-retryable error detected, stopping retries")
+			log.Errorf("  ❌ Non-retryable error detected, stopping retries")
 			// 记录不可重试错误
 			if at.errorTracker != nil {
 				at.errorTracker.RecordError(
@@ -451,15 +452,15 @@ func (at *AutoTrader) recordPendingOrderFailure(orderID string, execErr error) {
 	if retries >= maxCycleRetries {
 		reason := fmt.Sprintf("Execution failed %d times across cycles: %v", retries, execErr)
 		if err := at.store.Analysis().CancelPendingOrder(orderID, reason); err != nil {
-			logger.Warnf("⚠️ Failed to cancel failed order %s: %v", orderID, err)
+			at.log().Warnf("⚠️ Failed to cancel failed order %s: %v", orderID, err)
 		} else {
-			logger.Infof("🗑️ Cancelled order %s after %d cycle failures", orderID[:8], retries)
+			at.log().Infof("🗑️ Cancelled order %s after %d cycle failures", orderID[:8], retries)
 		}
 		at.mu.Lock()
 		delete(at.pendingOrderRetries, orderID)
 		at.mu.Unlock()
 	} else {
-		logger.Warnf("⚠️ Order %s failed in cycle (%d/%d cycle retries remaining)",
+		at.log().Warnf("⚠️ Order %s failed in cycle (%d/%d cycle retries remaining)",
 			orderID[:8], retries, maxCycleRetries)
 	}
 }
@@ -471,9 +472,9 @@ func (at *AutoTrader) checkAndCleanupOrder(order *store.PendingOrder, currentPri
 	if orderAge > 12*time.Hour {
 		reason := fmt.Sprintf("Order too old: %.1f hours", orderAge.Hours())
 		if err := at.store.Analysis().CancelPendingOrder(order.ID, reason); err != nil {
-			logger.Warnf("⚠️ Failed to cancel old order: %v", err)
+			at.log().Warnf("⚠️ Failed to cancel old order: %v", err)
 		} else {
-			logger.Infof("🗑️ Cancelled old order %s: %s (%.1fh old)", order.Symbol, order.ID[:8], orderAge.Hours())
+			at.log().Infof("🗑️ Cancelled old order %s: %s (%.1fh old)", order.Symbol, order.ID[:8], orderAge.Hours())
 		}
 		return
 	}
@@ -505,9 +506,9 @@ func (at *AutoTrader) checkAndCleanupOrder(order *store.PendingOrder, currentPri
 			reason := fmt.Sprintf("Price deviation too high [%s]: %.2f%% (current: %.4f, trigger: %.4f)",
 				direction, deviation*100, currentPrice, order.TriggerPrice)
 			if err := at.store.Analysis().CancelPendingOrder(order.ID, reason); err != nil {
-				logger.Warnf("⚠️ Failed to cancel deviated order: %v", err)
+				at.log().Warnf("⚠️ Failed to cancel deviated order: %v", err)
 			} else {
-				logger.Infof("🗑️ Cancelled deviated order %s [%s]: %s (%.2f%% deviation)",
+				at.log().Infof("🗑️ Cancelled deviated order %s [%s]: %s (%.2f%% deviation)",
 					order.Symbol, direction, order.ID[:8], deviation*100)
 			}
 		}
@@ -516,7 +517,8 @@ func (at *AutoTrader) checkAndCleanupOrder(order *store.PendingOrder, currentPri
 
 // executePendingOrder 执行待执行的订单
 func (at *AutoTrader) executePendingOrder(order *store.PendingOrder, currentPrice float64) error {
-	logger.Infof("  🚀 Executing pending order: %s", order.Symbol)
+	log := at.log().WithField(logger.FieldSymbol, order.Symbol)
+	log.Infof("  🚀 Executing pending order: %s", order.Symbol)
 
 	// 检查账户状态
 	balance, err := at.trader.GetBalance()
@@ -590,7 +592,7 @@ func (at *AutoTrader) executePendingOrder(order *store.PendingOrder, currentPric
 		}
 
 		if err := at.store.Analysis().SaveTradeHistory(tradeHistory); err != nil {
-			logger.Warnf("⚠️ Failed to save trade history: %v", err)
+			log.Warnf("⚠️ Failed to save trade history: %v", err)
 		}
 
 		// 直接更新为 FILLED 状态（包含触发价格和成交信息）
@@ -602,13 +604,13 @@ func (at *AutoTrader) executePendingOrder(order *store.PendingOrder, currentPric
 		if err := at.store.Analysis().UpdatePendingOrderFilledWithPrice(
 			order.ID, currentPrice, time.Now().UTC(), orderID,
 		); err != nil {
-			logger.Warnf("⚠️ Failed to mark order as filled: %v", err)
+			log.Warnf("⚠️ Failed to mark order as filled: %v", err)
 		} else {
-			logger.Infof("✅ Order status updated to FILLED: %s (trigger price: %.2f)", order.Symbol, currentPrice)
+			log.Infof("✅ Order status updated to FILLED: %s (trigger price: %.2f)", order.Symbol, currentPrice)
 		}
 	} else {
 		// 执行失败，订单保持 TRIGGERED 状态，下次重试
-		logger.Warnf("⚠️ Order execution unsuccessful, will retry: %s", order.Symbol)
+		log.Warnf("⚠️ Order execution unsuccessful, will retry: %s", order.Symbol)
 	}
 
 	return nil
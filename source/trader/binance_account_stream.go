@@ -0,0 +1,158 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// binanceListenKeyKeepalive listen keys expire after 60 minutes without keepalive
+const binanceListenKeyKeepalive = 30 * time.Minute
+
+// binanceAccountStream Binance Futures user data stream (listenKey)
+type binanceAccountStream struct {
+	trader *FuturesTrader
+}
+
+func newBinanceAccountStream(t *FuturesTrader) *binanceAccountStream {
+	return &binanceAccountStream{trader: t}
+}
+
+func (s *binanceAccountStream) Name() string { return "Binance" }
+
+// Run opens a listenKey user data stream and keeps the listenKey alive
+func (s *binanceAccountStream) Run(ctx context.Context, events chan<- AccountEvent) error {
+	client := s.trader.client
+	listenKey, err := client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create listenKey: %w", err)
+	}
+
+	streamErr := make(chan error, 1)
+	doneC, stopC, err := futures.WsUserDataServe(listenKey, func(event *futures.WsUserDataEvent) {
+		if event.Event == futures.UserDataEventTypeListenKeyExpired {
+			select {
+			case streamErr <- fmt.Errorf("listenKey expired"):
+			default:
+			}
+			return
+		}
+		for _, e := range binanceAccountEvents(s.trader, event) {
+			if !sendAccountEvent(ctx, events, e) {
+				return
+			}
+		}
+	}, func(err error) {
+		select {
+		case streamErr <- err:
+		default:
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect user data stream: %w", err)
+	}
+	defer close(stopC)
+
+	if !sendAccountEvent(ctx, events, AccountEvent{Connected: true}) {
+		return ctx.Err()
+	}
+
+	keepalive := time.NewTicker(binanceListenKeyKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-streamErr:
+			return err
+		case <-doneC:
+			return fmt.Errorf("user data stream closed")
+		case <-keepalive.C:
+			if err := client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+				return fmt.Errorf("listenKey keepalive failed: %w", err)
+			}
+		}
+	}
+}
+
+// binanceAccountEvents converts a user data event into account events
+func binanceAccountEvents(t *FuturesTrader, event *futures.WsUserDataEvent) []AccountEvent {
+	var result []AccountEvent
+	switch event.Event {
+	case futures.UserDataEventTypeOrderTradeUpdate:
+		o := event.OrderTradeUpdate
+		filledQty, _ := strconv.ParseFloat(o.AccumulatedFilledQty, 64)
+		avgPrice, _ := strconv.ParseFloat(o.AveragePrice, 64)
+		result = append(result, AccountEvent{Order: &AccountOrderUpdate{
+			Symbol:    o.Symbol,
+			OrderID:   strconv.FormatInt(o.ID, 10),
+			Status:    normalizeOrderStatus(string(o.Status)),
+			FilledQty: filledQty,
+			AvgPrice:  avgPrice,
+		}})
+
+		if o.ExecutionType != futures.OrderExecutionTypeTrade {
+			break
+		}
+		price, _ := strconv.ParseFloat(o.LastFilledPrice, 64)
+		qty, _ := strconv.ParseFloat(o.LastFilledQty, 64)
+		fee, _ := strconv.ParseFloat(o.Commission, 64)
+		pnl, _ := strconv.ParseFloat(o.RealizedPnL, 64)
+		if qty <= 0 {
+			break
+		}
+		orderAction := t.determineOrderAction(string(o.Side), string(o.PositionSide), pnl)
+		positionSide := string(o.PositionSide)
+		if positionSide == "BOTH" {
+			positionSide = ""
+		}
+		// Triggered stop/take-profit orders execute as MARKET, the original type tells them apart
+		orderType := string(o.OriginalType)
+		if orderType == "" {
+			orderType = string(o.Type)
+		}
+		result = append(result, AccountEvent{Fill: &AccountFill{
+			Symbol:       o.Symbol,
+			Side:         string(o.Side),
+			PositionSide: positionSide,
+			OrderAction:  orderAction,
+			OrderType:    orderType,
+			OrderID:      strconv.FormatInt(o.ID, 10),
+			TradeID:      strconv.FormatInt(o.TradeID, 10),
+			Price:        price,
+			Quantity:     qty,
+			Fee:          fee,
+			FeeAsset:     o.CommissionAsset,
+			RealizedPnL:  pnl,
+			IsMaker:      o.IsMaker,
+			Time:         time.UnixMilli(o.TradeTime).UTC(),
+		}})
+
+	case futures.UserDataEventTypeAccountUpdate:
+		for _, p := range event.AccountUpdate.Positions {
+			amount, _ := strconv.ParseFloat(p.Amount, 64)
+			entry, _ := strconv.ParseFloat(p.EntryPrice, 64)
+			side := string(p.Side)
+			if side == "BOTH" {
+				// One-way mode: the sign of the amount is the direction
+				side = ""
+				if amount > 0 {
+					side = "LONG"
+				} else if amount < 0 {
+					side = "SHORT"
+				}
+			}
+			result = append(result, AccountEvent{Position: &AccountPositionUpdate{
+				Symbol:     p.Symbol,
+				Side:       side,
+				Quantity:   math.Abs(amount),
+				EntryPrice: entry,
+			}})
+		}
+	}
+	return result
+}
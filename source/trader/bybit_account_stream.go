@@ -0,0 +1,173 @@
+package trader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	bybitPrivateWsURL = "wss://stream.bybit.com/v5/private"
+	bybitPingInterval = 20 * time.Second
+)
+
+// bybitAccountStream Bybit V5 private stream (execution, order, position topics)
+type bybitAccountStream struct {
+	trader *BybitTrader
+}
+
+func newBybitAccountStream(t *BybitTrader) *bybitAccountStream {
+	return &bybitAccountStream{trader: t}
+}
+
+func (s *bybitAccountStream) Name() string { return "Bybit" }
+
+// Run authenticates the private stream and subscribes to executions, orders and positions
+func (s *bybitAccountStream) Run(ctx context.Context, events chan<- AccountEvent) error {
+	conn, err := dialAccountStream(ctx, bybitPrivateWsURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Signature: HMAC-SHA256("GET/realtime" + expires)
+	expires := time.Now().Add(10 * time.Second).UnixMilli()
+	h := hmac.New(sha256.New, []byte(s.trader.secretKey))
+	h.Write([]byte(fmt.Sprintf("GET/realtime%d", expires)))
+	auth := map[string]interface{}{
+		"op":   "auth",
+		"args": []interface{}{s.trader.apiKey, expires, hex.EncodeToString(h.Sum(nil))},
+	}
+	if err := websocket.JSON.Send(conn, auth); err != nil {
+		return fmt.Errorf("failed to send auth: %w", err)
+	}
+
+	return readAccountStream(ctx, conn, []byte(`{"op":"ping"}`), bybitPingInterval, func(msg []byte) error {
+		var ack struct {
+			Op      string `json:"op"`
+			Success *bool  `json:"success"`
+			RetMsg  string `json:"ret_msg"`
+		}
+		if err := json.Unmarshal(msg, &ack); err == nil && ack.Success != nil {
+			if !*ack.Success {
+				return fmt.Errorf("%s failed: %s", ack.Op, ack.RetMsg)
+			}
+			switch ack.Op {
+			case "auth":
+				subscribe := map[string]interface{}{"op": "subscribe", "args": []string{"execution", "order", "position"}}
+				return websocket.JSON.Send(conn, subscribe)
+			case "subscribe":
+				if !sendAccountEvent(ctx, events, AccountEvent{Connected: true}) {
+					return ctx.Err()
+				}
+			}
+			return nil
+		}
+
+		parsed, err := parseBybitPrivateMessage(msg)
+		if err != nil {
+			return err
+		}
+		for _, e := range parsed {
+			if !sendAccountEvent(ctx, events, e) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+}
+
+// parseBybitPrivateMessage converts a private topic message into account events
+func parseBybitPrivateMessage(msg []byte) ([]AccountEvent, error) {
+	var envelope struct {
+		Topic string                   `json:"topic"`
+		Data  []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &envelope); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	var result []AccountEvent
+	switch envelope.Topic {
+	case "execution":
+		var executions []map[string]interface{}
+		for _, item := range envelope.Data {
+			if category := jsonString(item, "category"); category != "" && category != "linear" {
+				continue
+			}
+			// Funding and delivery executions are not trades
+			if execType := jsonString(item, "execType"); execType != "" && execType != "Trade" {
+				continue
+			}
+			// The stream reports realized PnL as execPnl
+			if _, ok := item["closedPnl"]; !ok {
+				item["closedPnl"] = item["execPnl"]
+			}
+			executions = append(executions, item)
+		}
+		trades, err := (&BybitTrader{}).parseTradesResult(executions)
+		if err != nil {
+			return nil, err
+		}
+		for _, trade := range trades {
+			result = append(result, AccountEvent{Fill: &AccountFill{
+				Symbol:       trade.Symbol,
+				Side:         trade.Side,
+				PositionSide: "BOTH", // Bybit uses one-way position mode
+				OrderAction:  trade.OrderAction,
+				OrderType:    trade.OrderType,
+				OrderID:      trade.OrderID,
+				TradeID:      trade.ExecID,
+				Price:        trade.ExecPrice,
+				Quantity:     trade.ExecQty,
+				Fee:          trade.ExecFee,
+				RealizedPnL:  trade.ClosedPnL,
+				IsMaker:      trade.IsMaker,
+				Time:         trade.ExecTime,
+			}})
+		}
+
+	case "order":
+		for _, item := range envelope.Data {
+			if category := jsonString(item, "category"); category != "" && category != "linear" {
+				continue
+			}
+			result = append(result, AccountEvent{Order: &AccountOrderUpdate{
+				Symbol:     jsonString(item, "symbol"),
+				OrderID:    jsonString(item, "orderId"),
+				Status:     normalizeOrderStatus(jsonString(item, "orderStatus")),
+				FilledQty:  jsonFloat(item, "cumExecQty"),
+				AvgPrice:   jsonFloat(item, "avgPrice"),
+				Commission: jsonFloat(item, "cumExecFee"),
+			}})
+		}
+
+	case "position":
+		for _, item := range envelope.Data {
+			if category := jsonString(item, "category"); category != "" && category != "linear" {
+				continue
+			}
+			side := ""
+			switch strings.ToLower(jsonString(item, "side")) {
+			case "buy":
+				side = "LONG"
+			case "sell":
+				side = "SHORT"
+			}
+			result = append(result, AccountEvent{Position: &AccountPositionUpdate{
+				Symbol:     jsonString(item, "symbol"),
+				Side:       side,
+				Quantity:   jsonFloat(item, "size"),
+				EntryPrice: jsonFloat(item, "entryPrice"),
+			}})
+		}
+	}
+	return result, nil
+}
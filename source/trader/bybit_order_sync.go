@@ -296,16 +296,3 @@ func (t *BybitTrader) SyncOrdersFromBybit(traderID string, exchangeID string, ex
 	logger.Infof("✅ Bybit order sync completed: %d new trades synced", syncedCount)
 	return nil
 }
-
-// StartOrderSync starts background order sync task for Bybit
-func (t *BybitTrader) StartOrderSync(traderID string, exchangeID string, exchangeType string, st *store.Store, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		for range ticker.C {
-			if err := t.SyncOrdersFromBybit(traderID, exchangeID, exchangeType, st); err != nil {
-				logger.Infof("⚠️  Bybit order sync failed: %v", err)
-			}
-		}
-	}()
-	logger.Infof("🔄 Bybit order sync started (interval: %v)", interval)
-}
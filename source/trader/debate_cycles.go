@@ -0,0 +1,45 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"nofx/debate"
+	"nofx/kernel"
+	"nofx/store"
+)
+
+// getDebateDecision lets the trader's debate panel decide the cycle over the trader's own context.
+// The consensus goes through the same strategy risk validation as a single-model decision, and the
+// debate session holding the transcript is linked to the decision record.
+func (at *AutoTrader) getDebateDecision(ctx *kernel.Context, record *store.DecisionRecord) (*kernel.FullDecision, error) {
+	result, err := at.debateEngine.RunTraderCycle(debate.CycleRequest{
+		PanelID:  at.config.DebatePanelID,
+		TraderID: at.id,
+		UserID:   at.userID,
+		Strategy: at.strategyEngine,
+		Context:  ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("debate failed: %w", err)
+	}
+
+	record.DebateSessionID = result.SessionID
+	record.ExecutionLog = append(record.ExecutionLog,
+		fmt.Sprintf("Debate session %s: %d consensus decisions", result.SessionID, len(result.Consensus)))
+	at.log().Infof("🗣️ Debate session %s reached %d consensus decisions", result.SessionID, len(result.Consensus))
+
+	consensusJSON, _ := json.MarshalIndent(result.Consensus, "", "  ")
+	decision := &kernel.FullDecision{
+		SystemPrompt:        result.SystemPrompt,
+		UserPrompt:          result.UserPrompt,
+		CoTTrace:            result.Transcript,
+		Decisions:           result.Decisions,
+		RawResponse:         string(consensusJSON),
+		AIRequestDurationMs: result.DurationMs,
+	}
+
+	if err := at.strategyEngine.ValidateDecisions(decision.Decisions, ctx.Account.TotalEquity); err != nil {
+		return decision, fmt.Errorf("debate consensus validation failed: %w", err)
+	}
+	return decision, nil
+}
@@ -3,6 +3,7 @@ package trader
 import (
 	"fmt"
 	"nofx/logger"
+	"nofx/metrics"
 	"sync"
 	"time"
 )
@@ -13,6 +14,7 @@ type ErrorTracker struct {
 	errors       map[string]*ErrorStats // key: error type
 	recentErrors []ErrorRecord
 	maxRecent    int
+	traderID     string // 指标标签（为空时不上报）
 }
 
 // ErrorStats 错误统计
@@ -42,11 +44,22 @@ func NewErrorTracker(maxRecent int) *ErrorTracker {
 	}
 }
 
+// SetTraderID 设置上报 nofx_trader_errors_total 指标时使用的交易员 ID
+func (et *ErrorTracker) SetTraderID(traderID string) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	et.traderID = traderID
+}
+
 // RecordError 记录错误
 func (et *ErrorTracker) RecordError(errorType, symbol, message, severity string) {
 	et.mu.Lock()
 	defer et.mu.Unlock()
 
+	if et.traderID != "" {
+		metrics.TraderErrors.Inc(et.traderID, errorType)
+	}
+
 	now := time.Now()
 
 	// 更新统计
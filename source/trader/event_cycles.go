@@ -0,0 +1,203 @@
+package trader
+
+import (
+	"nofx/market"
+	"nofx/provider/coinank/coinank_enum"
+	"nofx/store"
+	"sync"
+	"time"
+)
+
+// eventPollInterval how often funding, open interest, liquidations and positions are refreshed for event triggers
+const eventPollInterval = 60 * time.Second
+
+// eventPosition open position tracked for PnL crossing events
+type eventPosition struct {
+	Side       string
+	EntryPrice float64
+	Leverage   float64
+}
+
+// eventCycles state of event-driven decision cycles
+type eventCycles struct {
+	cfg      store.EventTriggerConfig
+	detector *market.EventDetector
+	budget   *market.EventBudget
+
+	mu         sync.RWMutex
+	subscribed map[string]bool
+	positions  map[string][]eventPosition // symbol -> open positions
+}
+
+// startEventTriggers subscribes candidate and held symbols to the price feed and launches
+// out-of-band decision cycles when configured market events fire
+func (at *AutoTrader) startEventTriggers(ws *market.WebSocketPriceMonitor) {
+	if at.config.StrategyConfig == nil || !at.config.StrategyConfig.EventTriggers.Enabled {
+		return
+	}
+
+	cfg := at.config.StrategyConfig.EventTriggers
+	at.events = &eventCycles{
+		cfg:        cfg,
+		detector:   market.NewEventDetector(cfg),
+		budget:     market.NewEventBudget(cfg),
+		subscribed: make(map[string]bool),
+		positions:  make(map[string][]eventPosition),
+	}
+	at.log().Infof("⚡ [%s] Event-driven cycles enabled (debounce %ds, max %d/h)", at.name, cfg.DebounceSeconds, cfg.MaxCallsPerHour)
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+		ticker := time.NewTicker(eventPollInterval)
+		defer ticker.Stop()
+
+		at.pollEventSources(ws)
+		for {
+			select {
+			case <-ticker.C:
+				at.pollEventSources(ws)
+			case <-at.stopMonitorCh:
+				at.log().Info("⏹ Stopped event trigger monitoring")
+				return
+			}
+		}
+	}()
+}
+
+// pollEventSources refreshes subscriptions and positions, then feeds polled derivatives data to the detector
+func (at *AutoTrader) pollEventSources(ws *market.WebSocketPriceMonitor) {
+	ev := at.events
+	symbols := make(map[string]bool)
+
+	if coins, err := at.strategyEngine.GetCandidateCoins(); err == nil {
+		for _, coin := range coins {
+			symbols[market.Normalize(coin.Symbol)] = true
+		}
+	} else {
+		at.log().Infof("⚠️ [%s] Event triggers: failed to get candidate coins: %v", at.name, err)
+	}
+
+	openKeys := make(map[string]bool)
+	if positions, err := at.trader.GetPositions(); err == nil {
+		held := make(map[string][]eventPosition)
+		for _, pos := range positions {
+			symbol, _ := pos["symbol"].(string)
+			side, _ := pos["side"].(string)
+			amt, _ := pos["positionAmt"].(float64)
+			entry, _ := pos["entryPrice"].(float64)
+			if symbol == "" || amt == 0 || entry <= 0 {
+				continue
+			}
+			leverage := 10.0
+			if lev, ok := pos["leverage"].(float64); ok && lev > 0 {
+				leverage = lev
+			}
+			held[symbol] = append(held[symbol], eventPosition{Side: side, EntryPrice: entry, Leverage: leverage})
+			symbols[symbol] = true
+			openKeys[symbol+"_"+side] = true
+		}
+		ev.mu.Lock()
+		ev.positions = held
+		ev.mu.Unlock()
+		ev.detector.PrunePositions(openKeys)
+	}
+
+	for symbol := range symbols {
+		// The price feed only carries crypto perpetuals
+		if market.ClassifySymbol(symbol) != market.AssetCrypto {
+			continue
+		}
+		at.subscribeEventSymbol(ws, symbol)
+
+		if ev.cfg.FundingFlip || ev.cfg.OISurgePct > 0 || ev.cfg.LiquidationCascadeUSD > 0 {
+			at.pollDerivativesEvents(symbol)
+		}
+	}
+}
+
+// subscribeEventSymbol subscribes a symbol to the price feed once and routes its prices to the detector
+func (at *AutoTrader) subscribeEventSymbol(ws *market.WebSocketPriceMonitor, symbol string) {
+	ev := at.events
+	ev.mu.Lock()
+	if ev.subscribed[symbol] {
+		ev.mu.Unlock()
+		return
+	}
+	ev.subscribed[symbol] = true
+	ev.mu.Unlock()
+
+	if err := ws.Subscribe(symbol, coinank_enum.Okex, coinank_enum.Minute1); err != nil {
+		at.log().Warnf("⚠️ [%s] Event triggers: failed to subscribe %s: %v", at.name, symbol, err)
+		ev.mu.Lock()
+		delete(ev.subscribed, symbol)
+		ev.mu.Unlock()
+		return
+	}
+	ws.RegisterPriceCallback(symbol, func(symbol string, price float64, _ time.Time) {
+		at.onEventPrice(symbol, price, time.Now())
+	})
+}
+
+// onEventPrice evaluates price and position PnL events for a streamed price
+func (at *AutoTrader) onEventPrice(symbol string, price float64, now time.Time) {
+	ev := at.events
+	for _, event := range ev.detector.OnPrice(symbol, price, now) {
+		at.onMarketEvent(event)
+	}
+
+	ev.mu.RLock()
+	positions := ev.positions[symbol]
+	ev.mu.RUnlock()
+	for _, pos := range positions {
+		pnlPct := (price - pos.EntryPrice) / pos.EntryPrice * pos.Leverage * 100
+		if pos.Side == "short" {
+			pnlPct = -pnlPct
+		}
+		if event := ev.detector.OnPositionPnL(symbol, pos.Side, pnlPct, now); event != nil {
+			at.onMarketEvent(*event)
+		}
+	}
+}
+
+// pollDerivativesEvents feeds funding rate, open interest and latest liquidations of a symbol to the detector
+func (at *AutoTrader) pollDerivativesEvents(symbol string) {
+	ev := at.events
+	fundingRate, openInterest, err := market.GetPerpetualStats(symbol)
+	if err != nil {
+		at.log().Infof("⚠️ [%s] Event triggers: failed to get %s funding/OI: %v", at.name, symbol, err)
+	}
+
+	liquidationUSD := 0.0
+	if ev.cfg.LiquidationCascadeUSD > 0 {
+		if signals := at.strategyEngine.FetchDerivativesData(symbol); signals != nil && signals.Liquidation != nil {
+			liquidationUSD = signals.Liquidation.LastLongTurnover + signals.Liquidation.LastShortTurnover
+		}
+	}
+
+	for _, event := range ev.detector.OnDerivatives(symbol, fundingRate, openInterest, liquidationUSD, time.Now()) {
+		at.onMarketEvent(event)
+	}
+}
+
+// onMarketEvent launches an out-of-band decision cycle for an event within the debounce and hourly budget
+func (at *AutoTrader) onMarketEvent(event store.MarketEvent) {
+	at.isRunningMutex.RLock()
+	running := at.isRunning
+	at.isRunningMutex.RUnlock()
+	if !running {
+		return
+	}
+
+	if ok, reason := at.events.budget.Allow(time.Now()); !ok {
+		at.log().Infof("⚡ [%s] Market event ignored (%s): %s", at.name, reason, event.Message)
+		return
+	}
+
+	at.log().Infof("⚡ [%s] Market event: %s, launching out-of-band cycle", at.name, event.Message)
+	go func() {
+		if err := at.runCycleWithEvent(&event); err != nil {
+			at.log().Infof("❌ Event-triggered cycle failed: %v", err)
+		}
+	}()
+}
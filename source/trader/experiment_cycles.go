@@ -0,0 +1,31 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/kernel"
+	"nofx/store"
+)
+
+// runExperimentCycle records the trader's running experiment, if any, on this cycle's context. Shadow
+// variants are asked with the trader's AI client; their decisions are only recorded, never executed.
+func (at *AutoTrader) runExperimentCycle(ctx *kernel.Context, decisions []kernel.Decision, record *store.DecisionRecord) {
+	if at.experiments == nil || at.mcpClient == nil {
+		return
+	}
+	exp, err := at.store.Experiment().GetRunningForTrader(at.id)
+	if err != nil {
+		at.log().Warnf("⚠️ Failed to load running experiment: %v", err)
+		return
+	}
+	if exp == nil {
+		return
+	}
+
+	if err := at.experiments.RunCycle(exp, at.mcpClient, ctx, decisions); err != nil {
+		at.log().Warnf("⚠️ Experiment %s: %v", exp.Name, err)
+		return
+	}
+	record.ExecutionLog = append(record.ExecutionLog,
+		fmt.Sprintf("Experiment %s: cycle %d recorded", exp.Name, exp.Cycles))
+	at.log().Infof("🧪 Experiment %s: cycle %d recorded", exp.Name, exp.Cycles)
+}
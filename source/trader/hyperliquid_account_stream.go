@@ -0,0 +1,191 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	hyperliquidWsURL        = "wss://api.hyperliquid.xyz/ws"
+	hyperliquidTestnetWsURL = "wss://api.hyperliquid-testnet.xyz/ws"
+	hyperliquidPingInterval = 50 * time.Second
+)
+
+// hyperliquidAccountStream Hyperliquid userFills and orderUpdates subscriptions of the wallet
+type hyperliquidAccountStream struct {
+	trader *HyperliquidTrader
+}
+
+func newHyperliquidAccountStream(t *HyperliquidTrader) *hyperliquidAccountStream {
+	return &hyperliquidAccountStream{trader: t}
+}
+
+func (s *hyperliquidAccountStream) Name() string { return "Hyperliquid" }
+
+// Run subscribes to the fills and order updates of the wallet (public data, no signature needed)
+func (s *hyperliquidAccountStream) Run(ctx context.Context, events chan<- AccountEvent) error {
+	if s.trader.walletAddr == "" {
+		return fmt.Errorf("wallet address not configured")
+	}
+	url := hyperliquidWsURL
+	if s.trader.isTestnet {
+		url = hyperliquidTestnetWsURL
+	}
+	conn, err := dialAccountStream(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for _, subscription := range []string{"userFills", "orderUpdates"} {
+		msg := map[string]interface{}{
+			"method":       "subscribe",
+			"subscription": map[string]string{"type": subscription, "user": s.trader.walletAddr},
+		}
+		if err := websocket.JSON.Send(conn, msg); err != nil {
+			return fmt.Errorf("failed to subscribe %s: %w", subscription, err)
+		}
+	}
+
+	subscribed := 0
+	return readAccountStream(ctx, conn, []byte(`{"method":"ping"}`), hyperliquidPingInterval, func(msg []byte) error {
+		var envelope struct {
+			Channel string          `json:"channel"`
+			Data    json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(msg, &envelope); err != nil {
+			return fmt.Errorf("failed to parse message: %w", err)
+		}
+		switch envelope.Channel {
+		case "error":
+			return fmt.Errorf("Hyperliquid error: %s", string(envelope.Data))
+		case "subscriptionResponse":
+			if subscribed++; subscribed == 2 {
+				if !sendAccountEvent(ctx, events, AccountEvent{Connected: true}) {
+					return ctx.Err()
+				}
+			}
+			return nil
+		}
+
+		parsed, err := parseHyperliquidMessage(envelope.Channel, envelope.Data)
+		if err != nil {
+			return err
+		}
+		for _, e := range parsed {
+			if !sendAccountEvent(ctx, events, e) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+}
+
+// hyperliquidWsFill fill pushed by the userFills subscription
+type hyperliquidWsFill struct {
+	Coin          string `json:"coin"`
+	Px            string `json:"px"`
+	Sz            string `json:"sz"`
+	Side          string `json:"side"` // B = Buy, A = Sell
+	Time          int64  `json:"time"`
+	StartPosition string `json:"startPosition"`
+	Dir           string `json:"dir"`
+	ClosedPnl     string `json:"closedPnl"`
+	Oid           int64  `json:"oid"`
+	Crossed       bool   `json:"crossed"`
+	Fee           string `json:"fee"`
+	Tid           int64  `json:"tid"`
+}
+
+// parseHyperliquidMessage converts a userFills or orderUpdates push into account events
+// The position after each fill is derived from its start position
+func parseHyperliquidMessage(channel string, data json.RawMessage) ([]AccountEvent, error) {
+	var result []AccountEvent
+	switch channel {
+	case "userFills":
+		var payload struct {
+			IsSnapshot bool                `json:"isSnapshot"`
+			Fills      []hyperliquidWsFill `json:"fills"`
+		}
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return nil, fmt.Errorf("failed to parse fills: %w", err)
+		}
+		// The snapshot repeats history already covered by the REST reconciliation
+		if payload.IsSnapshot {
+			return nil, nil
+		}
+		for _, fill := range payload.Fills {
+			price, _ := strconv.ParseFloat(fill.Px, 64)
+			qty, _ := strconv.ParseFloat(fill.Sz, 64)
+			fee, _ := strconv.ParseFloat(fill.Fee, 64)
+			pnl, _ := strconv.ParseFloat(fill.ClosedPnl, 64)
+			startPosition, _ := strconv.ParseFloat(fill.StartPosition, 64)
+
+			side := "SELL"
+			signedQty := -qty
+			if fill.Side == "B" {
+				side = "BUY"
+				signedQty = qty
+			}
+			result = append(result, AccountEvent{Fill: &AccountFill{
+				Symbol:       fill.Coin,
+				Side:         side,
+				PositionSide: "BOTH", // Hyperliquid uses one-way position mode
+				OrderAction:  hyperliquidOrderAction(fill.Dir, side, pnl),
+				OrderID:      strconv.FormatInt(fill.Oid, 10),
+				TradeID:      strconv.FormatInt(fill.Tid, 10),
+				Price:        price,
+				Quantity:     qty,
+				Fee:          fee,
+				RealizedPnL:  pnl,
+				IsMaker:      !fill.Crossed,
+				Time:         time.UnixMilli(fill.Time).UTC(),
+			}})
+
+			position := startPosition + signedQty
+			posSide := ""
+			if position > 1e-12 {
+				posSide = "LONG"
+			} else if position < -1e-12 {
+				posSide = "SHORT"
+			}
+			result = append(result, AccountEvent{Position: &AccountPositionUpdate{
+				Symbol:   fill.Coin,
+				Side:     posSide,
+				Quantity: math.Abs(position),
+			}})
+		}
+
+	case "orderUpdates":
+		var updates []struct {
+			Order struct {
+				Coin    string `json:"coin"`
+				Oid     int64  `json:"oid"`
+				Sz      string `json:"sz"`
+				OrigSz  string `json:"origSz"`
+				LimitPx string `json:"limitPx"`
+			} `json:"order"`
+			Status string `json:"status"`
+		}
+		if err := json.Unmarshal(data, &updates); err != nil {
+			return nil, fmt.Errorf("failed to parse order updates: %w", err)
+		}
+		for _, u := range updates {
+			remaining, _ := strconv.ParseFloat(u.Order.Sz, 64)
+			original, _ := strconv.ParseFloat(u.Order.OrigSz, 64)
+			result = append(result, AccountEvent{Order: &AccountOrderUpdate{
+				Symbol:    u.Order.Coin,
+				OrderID:   strconv.FormatInt(u.Order.Oid, 10),
+				Status:    normalizeOrderStatus(u.Status),
+				FilledQty: original - remaining,
+			}})
+		}
+	}
+	return result, nil
+}
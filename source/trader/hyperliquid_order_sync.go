@@ -134,16 +134,3 @@ func (t *HyperliquidTrader) SyncOrdersFromHyperliquid(traderID string, exchangeI
 	logger.Infof("✅ Order sync completed: %d new trades synced", syncedCount)
 	return nil
 }
-
-// StartOrderSync starts background order sync task
-func (t *HyperliquidTrader) StartOrderSync(traderID string, exchangeID string, exchangeType string, st *store.Store, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		for range ticker.C {
-			if err := t.SyncOrdersFromHyperliquid(traderID, exchangeID, exchangeType, st); err != nil {
-				logger.Infof("⚠️  Hyperliquid order sync failed: %v", err)
-			}
-		}
-	}()
-	logger.Infof("🔄 Hyperliquid order sync started (interval: %v)", interval)
-}
@@ -2024,34 +2024,7 @@ func (t *HyperliquidTrader) GetTrades(startTime time.Time, limit int) ([]TradeRe
 			side = "SELL"
 		}
 
-		// Parse Dir field to get order action
-		// Hyperliquid Dir values: "Open Long", "Open Short", "Close Long", "Close Short"
-		var orderAction string
-		switch strings.ToLower(fill.Dir) {
-		case "open long":
-			orderAction = "open_long"
-		case "open short":
-			orderAction = "open_short"
-		case "close long":
-			orderAction = "close_long"
-		case "close short":
-			orderAction = "close_short"
-		default:
-			// Fallback: use RealizedPnL if Dir is missing/unknown
-			if pnl != 0 {
-				if side == "BUY" {
-					orderAction = "close_short"
-				} else {
-					orderAction = "close_long"
-				}
-			} else {
-				if side == "BUY" {
-					orderAction = "open_long"
-				} else {
-					orderAction = "open_short"
-				}
-			}
-		}
+		orderAction := hyperliquidOrderAction(fill.Dir, side, pnl)
 
 		// Hyperliquid uses one-way mode, so PositionSide is "BOTH"
 		trade := TradeRecord{
@@ -2072,6 +2045,32 @@ func (t *HyperliquidTrader) GetTrades(startTime time.Time, limit int) ([]TradeRe
 	return trades, nil
 }
 
+// hyperliquidOrderAction parses the fill Dir field to get order action
+// Hyperliquid Dir values: "Open Long", "Open Short", "Close Long", "Close Short"
+func hyperliquidOrderAction(dir, side string, pnl float64) string {
+	switch strings.ToLower(dir) {
+	case "open long":
+		return "open_long"
+	case "open short":
+		return "open_short"
+	case "close long":
+		return "close_long"
+	case "close short":
+		return "close_short"
+	}
+	// Fallback: use RealizedPnL if Dir is missing/unknown
+	if pnl != 0 {
+		if side == "BUY" {
+			return "close_short"
+		}
+		return "close_long"
+	}
+	if side == "BUY" {
+		return "open_long"
+	}
+	return "open_short"
+}
+
 // defaultBuilder is the builder info for order routing
 // Set to nil to avoid requiring builder fee approval
 //
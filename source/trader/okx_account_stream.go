@@ -0,0 +1,196 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	okxPrivateWsURL = "wss://ws.okx.com:8443/ws/v5/private"
+	okxPingInterval = 25 * time.Second
+)
+
+// okxAccountStream OKX private channels (orders with fills, positions) for SWAP instruments
+type okxAccountStream struct {
+	trader *OKXTrader
+	// contractValue returns the base asset quantity of one contract
+	contractValue func(symbol string) float64
+}
+
+func newOKXAccountStream(t *OKXTrader) *okxAccountStream {
+	return &okxAccountStream{
+		trader: t,
+		contractValue: func(symbol string) float64 {
+			if inst, err := t.getInstrument(symbol); err == nil && inst.CtVal > 0 {
+				return inst.CtVal
+			}
+			return 1
+		},
+	}
+}
+
+func (s *okxAccountStream) Name() string { return "OKX" }
+
+// Run logs in to the private channel and subscribes to orders and positions
+func (s *okxAccountStream) Run(ctx context.Context, events chan<- AccountEvent) error {
+	conn, err := dialAccountStream(ctx, okxPrivateWsURL)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	login := map[string]interface{}{
+		"op": "login",
+		"args": []map[string]string{{
+			"apiKey":     s.trader.apiKey,
+			"passphrase": s.trader.passphrase,
+			"timestamp":  timestamp,
+			"sign":       s.trader.sign(timestamp, "GET", "/users/self/verify", ""),
+		}},
+	}
+	if err := websocket.JSON.Send(conn, login); err != nil {
+		return fmt.Errorf("failed to send login: %w", err)
+	}
+
+	subscribed := false
+	return readAccountStream(ctx, conn, []byte("ping"), okxPingInterval, func(msg []byte) error {
+		if string(msg) == "pong" {
+			return nil
+		}
+
+		var ack struct {
+			Event string `json:"event"`
+			Code  string `json:"code"`
+			Msg   string `json:"msg"`
+		}
+		if err := json.Unmarshal(msg, &ack); err == nil && ack.Event != "" {
+			switch ack.Event {
+			case "error":
+				return fmt.Errorf("OKX error %s: %s", ack.Code, ack.Msg)
+			case "login":
+				if ack.Code != "0" {
+					return fmt.Errorf("login failed %s: %s", ack.Code, ack.Msg)
+				}
+				subscribe := map[string]interface{}{
+					"op": "subscribe",
+					"args": []map[string]string{
+						{"channel": "orders", "instType": "SWAP"},
+						{"channel": "positions", "instType": "SWAP"},
+					},
+				}
+				return websocket.JSON.Send(conn, subscribe)
+			case "subscribe":
+				if !subscribed {
+					subscribed = true
+					if !sendAccountEvent(ctx, events, AccountEvent{Connected: true}) {
+						return ctx.Err()
+					}
+				}
+			}
+			return nil
+		}
+
+		parsed, err := s.parse(msg)
+		if err != nil {
+			return err
+		}
+		for _, e := range parsed {
+			if !sendAccountEvent(ctx, events, e) {
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+}
+
+// parse converts a private channel push into account events, contract sizes are converted to base asset quantity
+func (s *okxAccountStream) parse(msg []byte) ([]AccountEvent, error) {
+	var push struct {
+		Arg struct {
+			Channel string `json:"channel"`
+		} `json:"arg"`
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &push); err != nil {
+		return nil, fmt.Errorf("failed to parse message: %w", err)
+	}
+
+	var result []AccountEvent
+	switch push.Arg.Channel {
+	case "orders":
+		for _, item := range push.Data {
+			symbol := s.trader.convertSymbolBack(jsonString(item, "instId"))
+			ctVal := s.contractValue(symbol)
+			result = append(result, AccountEvent{Order: &AccountOrderUpdate{
+				Symbol:     symbol,
+				OrderID:    jsonString(item, "ordId"),
+				Status:     normalizeOrderStatus(jsonString(item, "state")),
+				FilledQty:  jsonFloat(item, "accFillSz") * ctVal,
+				AvgPrice:   jsonFloat(item, "avgPx"),
+				Commission: -jsonFloat(item, "fee"), // OKX returns negative fee
+			}})
+
+			fillSz := jsonFloat(item, "fillSz")
+			if jsonString(item, "tradeId") == "" || fillSz <= 0 {
+				continue
+			}
+			fillTime, _ := strconv.ParseInt(jsonString(item, "fillTime"), 10, 64)
+			orderAction := okxOrderAction(jsonString(item, "side"), jsonString(item, "posSide"))
+			positionSide := "LONG"
+			if strings.Contains(orderAction, "short") {
+				positionSide = "SHORT"
+			}
+			result = append(result, AccountEvent{Fill: &AccountFill{
+				Symbol:       symbol,
+				Side:         jsonString(item, "side"),
+				PositionSide: positionSide,
+				OrderAction:  orderAction,
+				OrderType:    jsonString(item, "ordType"),
+				OrderID:      jsonString(item, "ordId"),
+				TradeID:      jsonString(item, "tradeId"),
+				Price:        jsonFloat(item, "fillPx"),
+				Quantity:     fillSz * ctVal,
+				Fee:          -jsonFloat(item, "fillFee"), // OKX returns negative fee
+				FeeAsset:     jsonString(item, "fillFeeCcy"),
+				RealizedPnL:  0, // Same as REST sync: PositionBuilder derives the PnL
+				IsMaker:      jsonString(item, "execType") == "M",
+				Time:         time.UnixMilli(fillTime).UTC(),
+			}})
+		}
+
+	case "positions":
+		for _, item := range push.Data {
+			symbol := s.trader.convertSymbolBack(jsonString(item, "instId"))
+			pos := jsonFloat(item, "pos")
+			side := ""
+			switch strings.ToLower(jsonString(item, "posSide")) {
+			case "long":
+				side = "LONG"
+			case "short":
+				side = "SHORT"
+			default:
+				// Net mode: the sign of pos is the direction
+				if pos > 0 {
+					side = "LONG"
+				} else if pos < 0 {
+					side = "SHORT"
+				}
+			}
+			result = append(result, AccountEvent{Position: &AccountPositionUpdate{
+				Symbol:     symbol,
+				Side:       side,
+				Quantity:   math.Abs(pos) * s.contractValue(symbol),
+				EntryPrice: jsonFloat(item, "avgPx"),
+			}})
+		}
+	}
+	return result, nil
+}
@@ -90,36 +90,7 @@ func (t *OKXTrader) GetTrades(startTime time.Time, limit int) ([]OKXTrade, error
 			fillQtyBase = fillSz * inst.CtVal
 		}
 
-		// Determine order action based on side and posSide
-		// OKX uses dual position mode:
-		// - buy + long = open long
-		// - sell + long = close long
-		// - sell + short = open short
-		// - buy + short = close short
-		orderAction := "open_long"
-		posSide := strings.ToLower(fill.PosSide)
-		side := strings.ToLower(fill.Side)
-
-		if posSide == "long" {
-			if side == "buy" {
-				orderAction = "open_long"
-			} else {
-				orderAction = "close_long"
-			}
-		} else if posSide == "short" {
-			if side == "sell" {
-				orderAction = "open_short"
-			} else {
-				orderAction = "close_short"
-			}
-		} else {
-			// One-way mode (net position)
-			if side == "buy" {
-				orderAction = "open_long"
-			} else {
-				orderAction = "open_short"
-			}
-		}
+		orderAction := okxOrderAction(fill.Side, fill.PosSide)
 
 		trade := OKXTrade{
 			InstID:      fill.InstID,
@@ -145,6 +116,34 @@ func (t *OKXTrader) GetTrades(startTime time.Time, limit int) ([]OKXTrade, error
 	return trades, nil
 }
 
+// okxOrderAction determines the order action based on side and posSide
+// OKX uses dual position mode:
+// - buy + long = open long
+// - sell + long = close long
+// - sell + short = open short
+// - buy + short = close short
+func okxOrderAction(side, posSide string) string {
+	posSide = strings.ToLower(posSide)
+	side = strings.ToLower(side)
+
+	if posSide == "long" {
+		if side == "buy" {
+			return "open_long"
+		}
+		return "close_long"
+	} else if posSide == "short" {
+		if side == "sell" {
+			return "open_short"
+		}
+		return "close_short"
+	}
+	// One-way mode (net position)
+	if side == "buy" {
+		return "open_long"
+	}
+	return "open_short"
+}
+
 // SyncOrdersFromOKX syncs OKX exchange order history to local database
 // Also creates/updates position records to ensure orders/fills/positions data consistency
 // exchangeID: Exchange account UUID (from exchanges.id)
@@ -476,16 +475,3 @@ func (t *OKXTrader) SyncOrdersFromOKX(traderID string, exchangeID string, exchan
 	logger.Infof("✅ OKX order sync completed: %d new trades synced", syncedCount)
 	return nil
 }
-
-// StartOrderSync starts background order sync task for OKX
-func (t *OKXTrader) StartOrderSync(traderID string, exchangeID string, exchangeType string, st *store.Store, interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	go func() {
-		for range ticker.C {
-			if err := t.SyncOrdersFromOKX(traderID, exchangeID, exchangeType, st); err != nil {
-				logger.Infof("⚠️  OKX order sync failed: %v", err)
-			}
-		}
-	}()
-	logger.Infof("🔄 OKX order sync started (interval: %v)", interval)
-}
@@ -0,0 +1,74 @@
+package trader
+
+import (
+	"math"
+	"nofx/kernel"
+	"nofx/market"
+)
+
+// exposurePositions converts exchange positions into the portfolio risk view
+func exposurePositions(positions []map[string]interface{}) []kernel.ExposurePosition {
+	result := make([]kernel.ExposurePosition, 0, len(positions))
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		amt, _ := pos["positionAmt"].(float64)
+		markPrice, _ := pos["markPrice"].(float64)
+		if symbol == "" || amt == 0 {
+			continue
+		}
+		result = append(result, kernel.ExposurePosition{
+			Symbol:   symbol,
+			Side:     side,
+			Notional: math.Abs(amt) * markPrice,
+		})
+	}
+	return result
+}
+
+// enforcePortfolioExposure caps or rejects a new position against net/gross/correlation-cluster exposure
+// and the same-direction position count (CODE ENFORCED)
+func (at *AutoTrader) enforcePortfolioExposure(positions []map[string]interface{}, symbol, side string, positionSizeUSD, equity float64) (float64, error) {
+	if at.config.StrategyConfig == nil {
+		return positionSizeUSD, nil
+	}
+	riskControl := at.config.StrategyConfig.RiskControl
+	held := exposurePositions(positions)
+
+	var klines map[string][]market.Kline
+	if kernel.NeedsCorrelationData(riskControl) && len(held) > 0 {
+		klines = at.correlationKlines(symbol, held, kernel.CorrelationLookback(riskControl)+1)
+	}
+
+	allowed, limit, err := kernel.CheckPortfolioExposure(riskControl, held, symbol, side, positionSizeUSD, equity, klines)
+	if err != nil {
+		return 0, err
+	}
+	if allowed < positionSizeUSD {
+		at.log().Infof("  ⚠️ [RISK CONTROL] %s %s %.2f USDT exceeds %s limit, capping to %.2f USDT",
+			symbol, side, positionSizeUSD, limit, allowed)
+	}
+	return allowed, nil
+}
+
+// correlationKlines fetches recent klines of the new symbol and the held symbols for correlation clustering
+// Symbols whose klines cannot be fetched are left out and treated as uncorrelated
+func (at *AutoTrader) correlationKlines(symbol string, held []kernel.ExposurePosition, limit int) map[string][]market.Kline {
+	result := make(map[string][]market.Kline, len(held)+1)
+	symbols := []string{symbol}
+	for _, p := range held {
+		symbols = append(symbols, p.Symbol)
+	}
+	for _, s := range symbols {
+		if _, ok := result[s]; ok {
+			continue
+		}
+		klines, err := market.GetKlines(s, kernel.CorrelationTimeframe, limit)
+		if err != nil {
+			at.log().Infof("  ⚠️ [RISK CONTROL] Failed to get %s klines for correlation check: %v", s, err)
+			continue
+		}
+		result[s] = klines
+	}
+	return result
+}
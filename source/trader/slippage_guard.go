@@ -0,0 +1,191 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/kernel"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// slippageSplitInterval pause between child orders so the book can refill
+const slippageSplitInterval = 500 * time.Millisecond
+
+// minSliceNotional smallest child order in USDT; exchanges reject orders below their minimum notional
+// (10 USDT on Binance, the strictest of the supported venues)
+const minSliceNotional = 10.0
+
+// planOrderSlices splits a market order into child orders whose estimated slippage stays within maxSlippagePct
+// Returns the notional of each child order; the total is smaller than positionSizeUSD when even
+// maxSplits child orders cannot absorb it within the limit. Child orders are never smaller than minNotional:
+// when equal slices would be, full-size slices are sent and the remainder is dropped, and no slice is
+// returned when the book cannot take minNotional within the limit.
+func planOrderSlices(book *market.OrderBook, side string, positionSizeUSD, maxSlippagePct, minNotional float64, maxSplits int) []float64 {
+	if book == nil || maxSlippagePct <= 0 || positionSizeUSD <= 0 {
+		return []float64{positionSizeUSD}
+	}
+	if maxSplits <= 0 {
+		maxSplits = 1
+	}
+
+	maxChunk := book.MaxNotionalWithinSlippage(side, maxSlippagePct)
+	if maxChunk <= 0 || positionSizeUSD <= maxChunk {
+		return []float64{positionSizeUSD}
+	}
+	if maxChunk < minNotional {
+		return nil
+	}
+
+	total := positionSizeUSD
+	count := int(math.Ceil(positionSizeUSD / maxChunk))
+	if count > maxSplits {
+		count = maxSplits
+		total = maxChunk * float64(maxSplits)
+	}
+
+	if total/float64(count) >= minNotional {
+		slices := make([]float64, count)
+		for i := range slices {
+			slices[i] = total / float64(count)
+		}
+		return slices
+	}
+
+	var slices []float64
+	for remaining := total; remaining >= minNotional && len(slices) < maxSplits; remaining -= maxChunk {
+		slices = append(slices, math.Min(maxChunk, remaining))
+	}
+	return slices
+}
+
+// enforceSlippageLimit plans the child orders of a market open order (CODE ENFORCED)
+// Falls back to a single order when the guard is disabled or the order book is unavailable
+func (at *AutoTrader) enforceSlippageLimit(symbol, side string, positionSizeUSD float64) []float64 {
+	if at.config.StrategyConfig == nil {
+		return []float64{positionSizeUSD}
+	}
+
+	riskControl := at.config.StrategyConfig.RiskControl
+	if riskControl.MaxSlippagePct <= 0 {
+		return []float64{positionSizeUSD}
+	}
+	maxSplits := riskControl.MaxOrderSplits
+	if maxSplits <= 0 {
+		maxSplits = 3 // Default: 3 child orders
+	}
+
+	book, err := market.GetOrderBook(symbol)
+	if err != nil {
+		at.log().Infof("  ⚠️ [RISK CONTROL] Order book unavailable for %s, skipping slippage check: %v", symbol, err)
+		return []float64{positionSizeUSD}
+	}
+
+	if est := book.EstimateSlippage(side, positionSizeUSD); est.Complete && est.SlippagePct <= riskControl.MaxSlippagePct {
+		return []float64{positionSizeUSD}
+	}
+
+	slices := planOrderSlices(book, side, positionSizeUSD, riskControl.MaxSlippagePct, minSliceNotional, maxSplits)
+	total := 0.0
+	for _, s := range slices {
+		total += s
+	}
+	if total < positionSizeUSD {
+		at.log().Infof("  ⚠️ [RISK CONTROL] %s book too thin for %.2f USDT within %.2f%% slippage, reducing to %.2f USDT in %d orders",
+			symbol, positionSizeUSD, riskControl.MaxSlippagePct, total, len(slices))
+	} else if len(slices) > 1 {
+		at.log().Infof("  ⚠️ [RISK CONTROL] %s order %.2f USDT exceeds %.2f%% slippage, splitting into %d orders",
+			symbol, positionSizeUSD, riskControl.MaxSlippagePct, len(slices))
+	}
+	return slices
+}
+
+// freshOrderBook fetches the book a child order is sized against; the cached book would still show the
+// depth earlier child orders consumed
+var freshOrderBook = market.GetFreshOrderBook
+
+// openPositionInSlices sends the planned child orders for open_long/open_short and records them as one
+// position (total filled quantity at the volume-weighted entry price)
+// Returns the total quantity opened; later child orders failing keeps the already filled part
+func (at *AutoTrader) openPositionInSlices(decision *kernel.Decision, action string, slices []float64, price float64, actionRecord *store.DecisionAction) (float64, error) {
+	log := at.log().WithField(logger.FieldSymbol, decision.Symbol)
+	side := strings.TrimPrefix(action, "open_")
+	totalQuantity := 0.0
+	var filled *orderFill // Aggregate fill of the child orders confirmed here
+	for i, sliceUSD := range slices {
+		if i > 0 {
+			time.Sleep(slippageSplitInterval)
+
+			// Earlier child orders consumed depth, size this one against the book as it is now
+			sliceUSD = at.resizeSlice(decision.Symbol, side, sliceUSD)
+			if sliceUSD < minSliceNotional {
+				log.Warnf("  ⚠️ [RISK CONTROL] Book has not refilled within the slippage limit, skipping the remaining %d child orders", len(slices)-i)
+				break
+			}
+		}
+
+		quantity := sliceUSD / price
+		var order map[string]interface{}
+		var err error
+		if action == "open_long" {
+			order, err = at.trader.OpenLong(decision.Symbol, quantity, decision.Leverage)
+		} else {
+			order, err = at.trader.OpenShort(decision.Symbol, quantity, decision.Leverage)
+		}
+		at.recordOrderMetric(action, err)
+		if err != nil {
+			if totalQuantity == 0 {
+				return 0, err
+			}
+			log.Warnf("  ⚠️ Child order %d/%d failed, keeping %.4f already opened: %v", i+1, len(slices), totalQuantity, err)
+			break
+		}
+
+		// Record order ID of the first child order
+		if orderID, ok := order["orderId"].(int64); ok && actionRecord.OrderID == 0 {
+			actionRecord.OrderID = orderID
+		}
+		if len(slices) > 1 {
+			log.WithField(logger.FieldOrderID, order["orderId"]).Infof("  ✓ Child order %d/%d filled, order ID: %v, quantity: %.4f", i+1, len(slices), order["orderId"], quantity)
+		}
+
+		// Record order to database and poll for confirmation
+		if fill := at.confirmOrder(order, decision.Symbol, action, quantity, price, decision.Leverage); fill != nil {
+			if filled == nil {
+				filled = &orderFill{orderID: fill.orderID}
+			}
+			filled.price = (filled.price*filled.quantity + fill.price*fill.quantity) / (filled.quantity + fill.quantity)
+			filled.quantity += fill.quantity
+			filled.fee += fill.fee
+		}
+		totalQuantity += quantity
+	}
+
+	if filled != nil {
+		at.recordFill(filled, decision.Symbol, action, decision.Leverage, 0, decision.TakeProfit, decision.StopLoss)
+	}
+
+	if totalQuantity == 0 {
+		return 0, fmt.Errorf("no order was placed for %s", decision.Symbol)
+	}
+	return totalQuantity, nil
+}
+
+// resizeSlice caps a child order at what a freshly fetched order book takes within the slippage limit
+// Keeps the planned size when the book is unavailable
+func (at *AutoTrader) resizeSlice(symbol, side string, sliceUSD float64) float64 {
+	book, err := freshOrderBook(symbol)
+	if err != nil {
+		at.log().Infof("  ⚠️ [RISK CONTROL] Order book unavailable for %s, keeping planned child order: %v", symbol, err)
+		return sliceUSD
+	}
+	maxChunk := book.MaxNotionalWithinSlippage(side, at.config.StrategyConfig.RiskControl.MaxSlippagePct)
+	if maxChunk < sliceUSD {
+		at.log().Infof("  ⚠️ [RISK CONTROL] %s child order reduced from %.2f to %.2f USDT to stay within slippage limit",
+			symbol, sliceUSD, maxChunk)
+		return maxChunk
+	}
+	return sliceUSD
+}
@@ -0,0 +1,106 @@
+package trader
+
+import (
+	"math"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"nofx/kernel"
+	"nofx/market"
+	"nofx/store"
+)
+
+// flatBook order book with a single ask level at the mid price, so up to depthUSD fills without slippage
+func flatBook(depthUSD float64) *market.OrderBook {
+	return &market.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []market.OrderBookLevel{{Price: 100, Quantity: 1000}},
+		Asks:   []market.OrderBookLevel{{Price: 100, Quantity: depthUSD / 100}},
+	}
+}
+
+func TestPlanOrderSlices(t *testing.T) {
+	tests := []struct {
+		name       string
+		book       *market.OrderBook
+		size       float64
+		maxSplits  int
+		wantSlices []float64
+	}{
+		{name: "no book", book: nil, size: 500, maxSplits: 3, wantSlices: []float64{500}},
+		{name: "fits in one order", book: flatBook(100), size: 50, maxSplits: 3, wantSlices: []float64{50}},
+		{name: "split evenly", book: flatBook(100), size: 250, maxSplits: 3, wantSlices: []float64{250.0 / 3, 250.0 / 3, 250.0 / 3}},
+		{name: "reduced to max splits", book: flatBook(100), size: 500, maxSplits: 3, wantSlices: []float64{100, 100, 100}},
+		{name: "even slices below minimum", book: flatBook(12), size: 25, maxSplits: 3, wantSlices: []float64{12, 12}},
+		{name: "book thinner than minimum", book: flatBook(5), size: 25, maxSplits: 3, wantSlices: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planOrderSlices(tt.book, "long", tt.size, 0.5, minSliceNotional, tt.maxSplits)
+			if len(got) != len(tt.wantSlices) {
+				t.Fatalf("got %d slices %v, want %v", len(got), got, tt.wantSlices)
+			}
+			for i := range got {
+				if math.Abs(got[i]-tt.wantSlices[i]) > 1e-6 {
+					t.Errorf("slice %d = %.4f, want %.4f", i, got[i], tt.wantSlices[i])
+				}
+				if tt.book != nil && got[i] < minSliceNotional {
+					t.Errorf("slice %d = %.4f is below the minimum notional", i, got[i])
+				}
+			}
+		})
+	}
+}
+
+// slicedOrderTrader fills every market order at the next price of fills
+type slicedOrderTrader struct {
+	Trader
+	fills  []float64
+	orders int
+}
+
+func (t *slicedOrderTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+	t.orders++
+	return map[string]interface{}{"orderId": int64(t.orders)}, nil
+}
+
+func (t *slicedOrderTrader) GetOrderStatus(symbol string, orderID string) (map[string]interface{}, error) {
+	id, _ := strconv.Atoi(orderID)
+	return map[string]interface{}{"status": "FILLED", "avgPrice": t.fills[id-1], "executedQty": 1.0, "commission": 0.1}, nil
+}
+
+// TestOpenPositionInSlicesRecordsOnePosition Test that the child orders of a sliced open become one position
+func TestOpenPositionInSlicesRecordsOnePosition(t *testing.T) {
+	st, err := store.New(filepath.Join(t.TempDir(), "nofx.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer st.Close()
+
+	original := freshOrderBook
+	freshOrderBook = func(string) (*market.OrderBook, error) { return flatBook(1e6), nil }
+	defer func() { freshOrderBook = original }()
+
+	cfg := store.GetDefaultStrategyConfig("en")
+	exchange := &slicedOrderTrader{fills: []float64{100, 101, 102}}
+	at := &AutoTrader{id: "slice-test-trader", exchange: "alpaca", trader: exchange, store: st, config: AutoTraderConfig{StrategyConfig: &cfg}}
+
+	decision := &kernel.Decision{Symbol: "BTCUSDT", Action: "open_long", Leverage: 2, TakeProfit: 110, StopLoss: 95}
+	quantity, err := at.openPositionInSlices(decision, "open_long", []float64{100, 100, 100}, 100, &store.DecisionAction{})
+	if err != nil || exchange.orders != 3 || math.Abs(quantity-3) > 1e-9 {
+		t.Fatalf("expected 3 child orders for quantity 3, got %d orders, %.4f (%v)", exchange.orders, quantity, err)
+	}
+
+	positions, err := st.Position().GetOpenPositions(at.id)
+	if err != nil || len(positions) != 1 {
+		t.Fatalf("expected one open position, got %d (%v)", len(positions), err)
+	}
+	if pos := positions[0]; math.Abs(pos.Quantity-3) > 1e-9 || math.Abs(pos.EntryPrice-101) > 1e-9 || pos.EntryOrderID != "1" {
+		t.Errorf("expected 3 @ 101 from order 1, got %.4f @ %.4f from order %s", pos.Quantity, pos.EntryPrice, pos.EntryOrderID)
+	}
+	if records, err := st.TPSL().GetTPSLBySymbolAndTrader(at.id, "BTCUSDT"); err != nil || len(records) != 1 {
+		t.Errorf("expected one TP/SL record, got %d (%v)", len(records), err)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 	"nofx/kernel"
-	"nofx/logger"
 )
 
 // VerifyTPSLSync 验证止损止盈与交易所的同步状态
@@ -19,7 +18,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 		return nil
 	}
 
-	logger.Debugf("🔍 [TPSLSync] Checking %d positions for TP/SL sync...", len(ctx.Positions))
+	at.log().Debugf("🔍 [TPSLSync] Checking %d positions for TP/SL sync...", len(ctx.Positions))
 
 	syncErrors := 0
 	syncSuccess := 0
@@ -28,7 +27,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 		// 从数据库获取TP/SL记录
 		tpslRecord, err := at.store.TPSL().GetTPSLBySymbolAndTrader(at.id, pos.Symbol)
 		if err != nil || len(tpslRecord) == 0 {
-			logger.Debugf("  No TP/SL record for %s in database", pos.Symbol)
+			at.log().Debugf("  No TP/SL record for %s in database", pos.Symbol)
 			continue
 		}
 
@@ -41,7 +40,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 		// 从交易所获取实际的TP/SL订单
 		orders, err := at.trader.GetOpenOrders(pos.Symbol)
 		if err != nil {
-			logger.Warnf("  ⚠️ Failed to get orders for %s: %v", pos.Symbol, err)
+			at.log().Warnf("  ⚠️ Failed to get orders for %s: %v", pos.Symbol, err)
 			if at.errorTracker != nil {
 				at.errorTracker.RecordError(
 					"SYNC_GET_ORDERS_FAILED",
@@ -70,7 +69,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 				// 比较价格（允许0.01%的误差）
 				priceDiff := math.Abs(orderPrice-record.CurrentSL) / record.CurrentSL
 				if priceDiff > 0.0001 { // 0.01%
-					logger.Warnf("  ⚠️ SL price mismatch for %s: DB=%.6f, Exchange=%.6f (%.2f%% diff)",
+					at.log().Warnf("  ⚠️ SL price mismatch for %s: DB=%.6f, Exchange=%.6f (%.2f%% diff)",
 						pos.Symbol, record.CurrentSL, orderPrice, priceDiff*100)
 					slNeedUpdate = true
 				}
@@ -82,7 +81,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 				// 比较价格
 				priceDiff := math.Abs(orderPrice-record.CurrentTP) / record.CurrentTP
 				if priceDiff > 0.0001 {
-					logger.Warnf("  ⚠️ TP price mismatch for %s: DB=%.6f, Exchange=%.6f (%.2f%% diff)",
+					at.log().Warnf("  ⚠️ TP price mismatch for %s: DB=%.6f, Exchange=%.6f (%.2f%% diff)",
 						pos.Symbol, record.CurrentTP, orderPrice, priceDiff*100)
 					tpNeedUpdate = true
 				}
@@ -91,7 +90,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 
 		// 如果找不到订单，可能需要重新设置
 		if !slOrderFound {
-			logger.Warnf("  ⚠️ Stop Loss order not found on exchange for %s", pos.Symbol)
+			at.log().Warnf("  ⚠️ Stop Loss order not found on exchange for %s", pos.Symbol)
 			if at.errorTracker != nil {
 				at.errorTracker.RecordError(
 					"SYNC_SL_MISSING",
@@ -104,7 +103,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 		}
 
 		if !tpOrderFound {
-			logger.Warnf("  ⚠️ Take Profit order not found on exchange for %s", pos.Symbol)
+			at.log().Warnf("  ⚠️ Take Profit order not found on exchange for %s", pos.Symbol)
 			if at.errorTracker != nil {
 				at.errorTracker.RecordError(
 					"SYNC_TP_MISSING",
@@ -118,7 +117,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 
 		// 尝试同步（如果需要）
 		if slNeedUpdate || tpNeedUpdate {
-			logger.Infof("  🔄 Attempting to sync TP/SL for %s...", pos.Symbol)
+			at.log().Infof("  🔄 Attempting to sync TP/SL for %s...", pos.Symbol)
 
 			// 重新设置止损
 			if slNeedUpdate && record.CurrentSL > 0 {
@@ -129,7 +128,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 
 				err := at.trader.SetStopLoss(pos.Symbol, side, pos.Quantity, record.CurrentSL)
 				if err != nil {
-					logger.Errorf("  ❌ Failed to sync SL for %s: %v", pos.Symbol, err)
+					at.log().Errorf("  ❌ Failed to sync SL for %s: %v", pos.Symbol, err)
 					if at.errorTracker != nil {
 						at.errorTracker.RecordError(
 							"SYNC_SL_UPDATE_FAILED",
@@ -140,7 +139,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 					}
 					syncErrors++
 				} else {
-					logger.Infof("  ✅ SL synced successfully for %s: %.6f", pos.Symbol, record.CurrentSL)
+					at.log().Infof("  ✅ SL synced successfully for %s: %.6f", pos.Symbol, record.CurrentSL)
 					if at.errorTracker != nil {
 						at.errorTracker.RecordError(
 							"SYNC_SL_SUCCESS",
@@ -162,7 +161,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 
 				err := at.trader.SetTakeProfit(pos.Symbol, side, pos.Quantity, record.CurrentTP)
 				if err != nil {
-					logger.Errorf("  ❌ Failed to sync TP for %s: %v", pos.Symbol, err)
+					at.log().Errorf("  ❌ Failed to sync TP for %s: %v", pos.Symbol, err)
 					if at.errorTracker != nil {
 						at.errorTracker.RecordError(
 							"SYNC_TP_UPDATE_FAILED",
@@ -173,7 +172,7 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 					}
 					syncErrors++
 				} else {
-					logger.Infof("  ✅ TP synced successfully for %s: %.6f", pos.Symbol, record.CurrentTP)
+					at.log().Infof("  ✅ TP synced successfully for %s: %.6f", pos.Symbol, record.CurrentTP)
 					if at.errorTracker != nil {
 						at.errorTracker.RecordError(
 							"SYNC_TP_SUCCESS",
@@ -189,11 +188,11 @@ func (at *AutoTrader) VerifyTPSLSync(ctx *kernel.Context) error {
 	}
 
 	if syncErrors > 0 {
-		logger.Warnf("⚠️ [TPSLSync] Completed with %d errors, %d successful syncs", syncErrors, syncSuccess)
+		at.log().Warnf("⚠️ [TPSLSync] Completed with %d errors, %d successful syncs", syncErrors, syncSuccess)
 	} else if syncSuccess > 0 {
-		logger.Infof("✅ [TPSLSync] All positions synced successfully (%d updates)", syncSuccess)
+		at.log().Infof("✅ [TPSLSync] All positions synced successfully (%d updates)", syncSuccess)
 	} else {
-		logger.Debugf("✅ [TPSLSync] All positions already in sync")
+		at.log().Debugf("✅ [TPSLSync] All positions already in sync")
 	}
 
 	return nil
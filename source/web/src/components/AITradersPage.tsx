@@ -99,8 +99,21 @@ const AI_PROVIDER_CONFIG: Record<
     apiUrl: 'https://platform.moonshot.ai/console/api-keys',
     apiName: 'Moonshot',
   },
+  ollama: {
+    defaultModel: 'llama3.1:8b',
+    apiUrl: 'https://ollama.com/download',
+    apiName: 'Ollama',
+  },
+  local: {
+    defaultModel: 'first model served',
+    apiUrl: 'https://github.com/ggml-org/llama.cpp/tree/master/tools/server',
+    apiName: 'llama.cpp server',
+  },
 }
 
+// Local providers run without an API key
+const LOCAL_AI_PROVIDERS = ['ollama', 'local']
+
 interface AITradersPageProps {
   onTraderSelect?: (traderId: string) => void
 }
@@ -1575,9 +1588,13 @@ function ModelConfigModal({
     }
   }, [editingModelId, selectedModel])
 
+  const apiKeyOptional = LOCAL_AI_PROVIDERS.includes(
+    selectedModel?.provider || ''
+  )
+
   const handleSubmit = (e: React.FormEvent) => {
     e.preventDefault()
-    if (!selectedModelId || !apiKey.trim()) return
+    if (!selectedModelId || (!apiKey.trim() && !apiKeyOptional)) return
 
     onSave(
       selectedModelId,
@@ -1843,7 +1860,9 @@ function ModelConfigModal({
             </button>
             <button
               type="submit"
-              disabled={!selectedModel || !apiKey.trim()}
+              disabled={
+                !selectedModel || (!apiKey.trim() && !apiKeyOptional)
+              }
               className="flex-1 px-4 py-2 rounded text-sm font-semibold disabled:opacity-50"
               style={{ background: '#F0B90B', color: '#000' }}
             >
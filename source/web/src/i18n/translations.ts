@@ -1176,6 +1176,7 @@ export const translations = {
         voting: 'Voting',
         completed: 'Completed',
         cancelled: 'Cancelled',
+        failed: 'Failed',
       },
       actions: {
         start: 'Start Debate',
@@ -2322,6 +2323,7 @@ export const translations = {
         voting: '投票中',
         completed: '已完成',
         cancelled: '已取消',
+        failed: '失败',
       },
       actions: {
         start: '开始辩论',
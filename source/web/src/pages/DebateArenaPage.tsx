@@ -141,6 +141,7 @@ const STATUS_COLOR: Record<string, string> = {
   voting: 'bg-yellow-500 animate-pulse',
   completed: 'bg-green-500',
   cancelled: 'bg-red-500',
+  failed: 'bg-red-500',
 }
 
 // AI Provider Avatar
@@ -79,6 +79,20 @@ export interface DecisionRecord {
   execution_log: string[]
   success: boolean
   error_message?: string
+  trigger_event?: MarketEvent // Set when the cycle was launched by a market event
+  debate_session_id?: string // Debate session holding the transcript when a panel decided the cycle
+  debate_votes?: DebateVote[] // Final votes of a backtest debate
+  debate_consensus?: DebateDecision[] // Consensus of a backtest debate
+  ai_model?: string // Provider/model that answered, e.g. "deepseek/deepseek-chat" (differs from the primary after a failover)
+}
+
+export interface MarketEvent {
+  type: 'price_move' | 'atr_spike' | 'funding_flip' | 'oi_surge' | 'liquidation_cascade' | 'position_pnl'
+  symbol: string
+  message: string
+  value: number
+  threshold: number
+  detected_at: string
 }
 
 export interface TPSLRecord {
@@ -122,6 +136,8 @@ export interface TraderInfo {
   show_in_competition?: boolean
   strategy_id?: string
   strategy_name?: string
+  debate_panel_id?: string
+  fallback_ai_model_ids?: string[]
   custom_prompt?: string
   use_ai500?: boolean
   use_oi_top?: boolean
@@ -138,6 +154,16 @@ export interface AIModel {
   customModelName?: string
 }
 
+// A model served by a local Ollama or OpenAI-compatible server (GET /api/models/local)
+export interface LocalModel {
+  id: string
+  size?: number // bytes on disk (Ollama)
+  family?: string
+  parameter_size?: string
+  quantization?: string
+  context_length?: number // 0/absent when the server does not report it
+}
+
 export interface Exchange {
   id: string // UUID (empty for supported exchange templates)
   exchange_type: string // "binance", "bybit", "okx", "hyperliquid", "aster", "lighter"
@@ -189,6 +215,8 @@ export interface CreateTraderRequest {
   scan_interval_minutes?: number
   is_cross_margin?: boolean
   show_in_competition?: boolean // 是否在竞技场显示
+  debate_panel_id?: string // 辩论模式：由该辩论会话的参与者共同决策（为空则使用单一 AI 模型）
+  fallback_ai_model_ids?: string[] // 备用 AI 模型：主模型失败或被限流时按顺序接替
   // 以下字段为向后兼容保留，新版使用策略配置
   btc_eth_leverage?: number
   altcoin_leverage?: number
@@ -261,6 +289,8 @@ export interface TraderConfigData {
   exchange_id: string
   strategy_id?: string // 策略ID
   strategy_name?: string // 策略名称
+  debate_panel_id?: string // 辩论模式面板
+  fallback_ai_model_ids?: string[] // 备用 AI 模型链
   is_cross_margin: boolean
   show_in_competition: boolean // 是否在竞技场显示
   scan_interval_minutes: number
@@ -391,6 +421,7 @@ export interface BacktestMetrics {
 export interface BacktestStartConfig {
   run_id?: string
   ai_model_id?: string
+  fallback_ai_model_ids?: string[] // Optional: AI models tried in order when the main model fails
   strategy_id?: string // Optional: use saved strategy from Strategy Studio
   symbols: string[]
   timeframes: string[]
@@ -404,6 +435,7 @@ export interface BacktestStartConfig {
   fill_policy: string
   prompt_variant?: string
   prompt_template?: string
+  prompt_template_ref?: PromptTemplateRef // Template from the prompt library, overrides the strategy's
   custom_prompt?: string
   override_prompt?: boolean
   cache_ai?: boolean
@@ -423,6 +455,15 @@ export interface BacktestStartConfig {
     btc_eth_leverage?: number
     altcoin_leverage?: number
   }
+  debate?: {
+    participants: {
+      ai_model_id: string
+      name?: string
+      personality: DebatePersonality
+    }[]
+    max_rounds?: number
+    weight_by_accuracy?: boolean // Weight votes by each participant's live debate accuracy
+  }
 }
 
 // Kline data for backtest chart
@@ -485,6 +526,38 @@ export interface PromptSectionsConfig {
   decision_process?: string
 }
 
+// Prompt template library: Go templates over the prompt sections, e.g. {{.RiskControl}} / {{.Positions}}
+export interface PromptTemplate {
+  id: string
+  user_id: string
+  name: string
+  description: string
+  system: string // empty keeps the built-in system prompt
+  user: string // empty keeps the built-in user prompt
+  version: number
+  is_public: boolean
+  created_at: string
+  updated_at: string
+}
+
+export interface PromptTemplateVersion {
+  id: string
+  template_id: string
+  version: number
+  system: string
+  user: string
+  created_at: string
+}
+
+// Template selected by a strategy or backtest; the selected version's content is snapshotted on save
+export interface PromptTemplateRef {
+  id: string
+  name?: string
+  version?: number // 0 or omitted = latest
+  system?: string
+  user?: string
+}
+
 export interface TriggerPriceStrategy {
   mode: 'current_price' | 'pullback' | 'breakout'
   style: 'long_term' | 'short_term' | 'swing' | 'scalp'
@@ -502,7 +575,39 @@ export interface StrategyConfig {
   custom_prompt?: string
   risk_control: RiskControlConfig
   prompt_sections?: PromptSectionsConfig
+  prompt_template?: PromptTemplateRef
   trigger_price_config?: TriggerPriceStrategy
+  event_triggers?: EventTriggerConfig
+  tool_calling?: ToolCallingConfig
+  decision_output?: DecisionOutputConfig
+}
+
+// Data tools the AI can call during a decision cycle (ignored in backtests)
+export interface ToolCallingConfig {
+  enabled: boolean
+  max_calls?: number // per cycle, default: 8
+  compact_prompt?: boolean // one summary line per candidate coin
+  tools?: Array<'get_klines' | 'get_orderbook' | 'get_funding_history' | 'get_position_history'> // empty = all
+}
+
+// How the AI answers with its decisions
+export interface DecisionOutputConfig {
+  structured: boolean // JSON object following the decision schema instead of tagged text
+  repair_on_invalid?: boolean // send invalid decisions back to the AI once per cycle
+}
+
+// Market events that launch a decision cycle between scheduled scans (0 = event disabled)
+export interface EventTriggerConfig {
+  enabled: boolean
+  window_minutes?: number // default: 15
+  price_move_pct?: number // price move within the window (%)
+  atr_spike_multiplier?: number // 1m range vs average 1m range
+  funding_flip?: boolean
+  oi_surge_pct?: number // OI change within the window (%)
+  liquidation_cascade_usd?: number // liquidated value in the latest bar (USD)
+  position_pnl_pct?: number // position PnL crossing ±% of margin
+  debounce_seconds?: number // default: 300
+  max_calls_per_hour?: number // default: 4
 }
 
 export interface CoinSourceConfig {
@@ -513,6 +618,7 @@ export interface CoinSourceConfig {
   ai500_limit?: number
   use_oi_top: boolean
   oi_top_limit?: number
+  skip_closed_markets?: boolean // 跳过休市中的股票/外汇/商品（否则仅标记）
   // Note: API URLs are now built automatically using nofxos_api_key from IndicatorConfig
 }
 
@@ -559,6 +665,17 @@ export interface IndicatorConfig {
   price_ranking_duration?: string // "1h", "4h", "24h" or "1h,4h,24h"
   price_ranking_limit?: number
 
+  // CoinAnk 衍生品数据（爆仓、多空比、OI/市值）
+  coinank_api_key?: string
+  enable_liquidation_data?: boolean
+  liquidation_interval?: string // "1h", "4h"
+  enable_long_short_ratio?: boolean
+  enable_oi_vs_market_cap?: boolean
+
+  // 订单簿深度（价差、±1%深度、买卖失衡）
+  enable_order_book?: boolean
+  order_book_levels?: number
+
   // Trigger Price Strategy - 根据交易员风格自动调整触发价格
   trigger_price_config?: TriggerPriceStrategy
 }
@@ -601,6 +718,19 @@ export interface RiskControlConfig {
   min_position_size: number // Min position size in USDT (CODE ENFORCED)
   min_risk_reward_ratio: number // Min take_profit / stop_loss ratio (AI guided)
   min_confidence: number // Min AI confidence to open position (AI guided)
+
+  // Execution Guard - market order slippage (CODE ENFORCED)
+  max_slippage_pct?: number // default: 0.5, 0 = disabled (oversized orders are split or reduced)
+  max_order_splits?: number // default: 3
+  allow_closed_market_entry?: boolean // Allow opening stock/forex perps while the underlying market is closed
+
+  // Portfolio Exposure - aggregate limits across open positions (CODE ENFORCED)
+  max_net_exposure_ratio?: number // default: 3, |long - short| notional / equity, 0 = disabled
+  max_gross_exposure_ratio?: number // default: 5, (long + short) notional / equity, 0 = disabled
+  max_cluster_exposure_ratio?: number // default: 1.5, net notional of a correlation cluster / equity, 0 = disabled
+  correlation_threshold?: number // default: 0.8, return correlation that puts two symbols in one cluster
+  correlation_lookback?: number // default: 72 (1h bars)
+  max_same_direction_positions?: number // default: 2, 0 = disabled
 }
 
 // Debate Arena Types
@@ -610,6 +740,7 @@ export type DebateStatus =
   | 'voting'
   | 'completed'
   | 'cancelled'
+  | 'failed'
 export type DebatePersonality =
   | 'bull'
   | 'bear'
@@ -649,6 +780,9 @@ export interface DebateSession {
   final_decision?: DebateDecision
   final_decisions?: DebateDecision[] // Multi-coin decisions
   auto_execute: boolean
+  consensus_method?: 'vote' | 'moderator'
+  moderator_ai_model_id?: string
+  weight_by_accuracy?: boolean
   created_at: string
   updated_at: string
 }
@@ -662,6 +796,11 @@ export interface DebateParticipant {
   personality: DebatePersonality
   color: string
   speak_order: number
+  // Snapshot of a custom personality
+  personality_name?: string
+  custom_prompt?: string
+  risk_bias?: DebateRiskBias
+  emoji?: string
   created_at: string
 }
 
@@ -686,6 +825,8 @@ export interface DebateVote {
   session_id: string
   ai_model_id: string
   ai_model_name: string
+  participant_id?: string
+  personality?: DebatePersonality
   action: string
   symbol: string
   confidence: number
@@ -720,14 +861,63 @@ export interface CreateDebateRequest {
     ai_model_id: string
     personality: DebatePersonality
   }[]
+  consensus_method?: 'vote' | 'moderator' // moderator: a moderator summarises rounds and decides
+  moderator_ai_model_id?: string // Required for the moderator method
+  weight_by_accuracy?: boolean // Weight votes by each participant's scored accuracy
 }
 
+export type DebateRiskBias = 'aggressive' | 'neutral' | 'conservative'
+
 export interface DebatePersonalityInfo {
   id: DebatePersonality
   name: string
   emoji: string
   color: string
   description: string
+  custom?: boolean // User-defined personality (id starts with custom_)
+  prompt?: string
+  risk_bias?: DebateRiskBias
+}
+
+export interface CustomPersonalityRequest {
+  name: string
+  description?: string
+  prompt: string // Role fragment injected into the debate system prompt
+  risk_bias?: DebateRiskBias
+  color?: string
+  emoji?: string
+}
+
+export type ScorecardGroupBy = 'personality' | 'model' | 'participant'
+
+export interface CalibrationBucket {
+  min_confidence: number
+  max_confidence: number
+  count: number
+  avg_confidence: number
+  hit_rate: number // Well calibrated when close to avg_confidence / 100
+}
+
+export interface ParticipantScorecard {
+  key: string
+  ai_model_id?: string
+  ai_model_name?: string
+  personality?: DebatePersonality
+  personality_name?: string
+  samples: number
+  correct: number
+  accuracy: number // 0-1
+  avg_confidence: number
+  brier_score: number // Lower is better
+  avg_return_pct: number // Average price move in the recommended direction
+  trades: number // Executed consensus trades the participant agreed with
+  pnl_contribution: number
+  calibration: CalibrationBucket[]
+}
+
+export interface DebateScorecardsResponse {
+  group_by: ScorecardGroupBy
+  scorecards: ParticipantScorecard[]
 }
 
 // Pending Orders Types (延迟执行架构)
@@ -853,3 +1043,224 @@ export interface PositionHistoryResponse {
   symbol_stats: SymbolStats[]
   direction_stats: DirectionStats[]
 }
+
+// Personal API tokens (Authorization: Bearer nofx_...)
+export type APITokenScope = 'read' | 'trade' | 'backtest' | 'admin'
+
+export interface APIToken {
+  id: string
+  user_id: string
+  name: string
+  token_prefix: string
+  scopes: APITokenScope[]
+  rate_limit_per_minute: number
+  expires_at?: string
+  last_used_at?: string
+  last_used_ip?: string
+  revoked_at?: string
+  created_at: string
+  active: boolean
+}
+
+export interface CreateAPITokenRequest {
+  name: string
+  scopes: APITokenScope[]
+  rate_limit_per_minute?: number
+  expires_in_days?: number
+}
+
+export interface CreateAPITokenResponse {
+  token: string
+  info: APIToken
+  message: string
+}
+
+// Team workspaces (requests act in a workspace via the X-Workspace-ID header)
+export type WorkspaceRole = 'owner' | 'trader' | 'analyst'
+
+export interface Workspace {
+  id: string
+  name: string
+  created_by: string
+  created_at: string
+  updated_at: string
+  role: WorkspaceRole
+}
+
+export interface WorkspaceMember {
+  id: number
+  workspace_id: string
+  user_id: string
+  email: string
+  role: WorkspaceRole
+  created_at: string
+  updated_at: string
+}
+
+// Audit log (append-only, hash-chained)
+export type AuditActorType = 'user' | 'api_token' | 'system'
+
+export interface AuditEntry {
+  id: number
+  owner_id: string
+  actor: string
+  actor_type: AuditActorType
+  api_token_id?: string
+  action: string
+  target_type: string
+  target_id: string
+  before?: string // JSON summary
+  after?: string // JSON summary
+  ip?: string
+  status: number
+  created_at: string
+  prev_hash: string
+  hash: string
+}
+
+export interface AuditVerifyResult {
+  valid: boolean
+  checked: number
+  head_hash: string
+  broken_at?: number
+  reason?: string
+}
+
+// Data encryption keys (instance administrators)
+export interface KeyRotationResult {
+  active_key_id: string
+  scanned: number
+  reencrypted: number
+  failed: number
+  errors?: string[]
+}
+
+export interface EncryptionKeysStatus {
+  provider: string
+  active_key_id: string
+  key_ids: string[]
+  rotation: {
+    running: boolean
+    started_at?: string
+    finished_at?: string
+    result?: KeyRotationResult
+    error?: string
+  }
+}
+
+// Backups (instance administrators)
+export interface BackupFile {
+  name: string
+  size: number
+  created_at: string
+}
+
+export interface BackupStatus {
+  dir: string
+  interval: string
+  keep: number
+  last_run?: string
+  last_file?: string
+  last_error?: string
+}
+
+// Per-trader structured logs
+export interface TraderLogLine {
+  time: string
+  level: string
+  message: string
+  caller?: string
+  cycle?: number
+  symbol?: string
+  order_id?: string
+  fields?: Record<string, unknown>
+}
+
+// Prompt/strategy A/B experiments: the trader's strategy executes, shadow variants are recorded
+export interface Experiment {
+  id: string
+  user_id: string
+  trader_id: string
+  name: string
+  description: string
+  status: 'running' | 'stopped'
+  fee_bps: number
+  initial_equity: number
+  cycles: number
+  created_at: string
+  updated_at: string
+  stopped_at?: string
+}
+
+export interface CreateExperimentRequest {
+  name: string
+  description?: string
+  trader_id: string
+  live_name?: string
+  fee_bps?: number
+  variants: {
+    name?: string
+    strategy_id?: string
+    config?: StrategyConfig // used when strategy_id is empty
+    prompt_variant?: 'balanced' | 'aggressive' | 'conservative' | 'scalping'
+  }[]
+}
+
+export interface ExperimentEquityPoint {
+  variant_id: string
+  cycle: number
+  equity: number
+  failed?: boolean // the variant's AI call failed this cycle
+  timestamp: string
+}
+
+export interface ExperimentDecision {
+  id: number
+  experiment_id: string
+  variant_id: string
+  cycle: number
+  symbol: string
+  action: string
+  confidence: number
+  leverage: number
+  position_size_usd: number
+  stop_loss: number
+  take_profit: number
+  price: number
+  reasoning?: string
+  created_at: string
+}
+
+export interface ExperimentVariantResult {
+  variant_id: string
+  name: string
+  is_live: boolean
+  strategy_id?: string
+  prompt_variant?: string
+  decisions: number
+  trades: number
+  failed_cycles: number
+  equity: number
+  return_pct: number
+  max_drawdown_pct: number
+  equity_curve: ExperimentEquityPoint[]
+  divergence?: {
+    cycles: number
+    divergent_cycles: number
+    decisions: number
+    divergent: number
+    rate: number
+  }
+  significance?: {
+    samples: number
+    mean_diff_pct: number // per-cycle return difference vs live, %
+    t_stat: number
+    p_value: number
+    significant: boolean
+  }
+}
+
+export interface ExperimentComparison {
+  experiment: Experiment
+  variants: ExperimentVariantResult[]
+}